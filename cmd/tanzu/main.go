@@ -4,16 +4,21 @@
 package main
 
 import (
+	"errors"
 	"os"
 	"os/exec"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/command"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/interrupt"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
 
 func main() {
 	if err := command.Execute(); err != nil {
-		if errStr, ok := err.(*exec.ExitError); ok {
+		if errors.Is(err, interrupt.ErrInterrupted) {
+			// The interruption was already reported and cleaned up after
+			os.Exit(interrupt.ExitCode)
+		} else if errStr, ok := err.(*exec.ExitError); ok {
 			// If a plugin exited with an error, we don't want to print its
 			// exit status as a string, but want to use it as our own exit code.
 			os.Exit(errStr.ExitCode())
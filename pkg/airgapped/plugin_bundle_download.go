@@ -15,6 +15,7 @@ import (
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper/sigverifier"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/essentials"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
@@ -70,7 +71,7 @@ func (o *DownloadPluginBundleOptions) DownloadPluginBundle() error {
 			return err
 		}
 
-		log.Info("Saving the list of images to download...")
+		clilog.Info("Saving the list of images to download...")
 		log.Outputf("%v", string(imagesBytes))
 		return nil
 	}
@@ -94,7 +95,7 @@ func (o *DownloadPluginBundleOptions) DownloadPluginBundle() error {
 	}
 
 	// Save entire plugin bundle as a single tar file which can be used with upload-bundle
-	log.Infof("saving plugin bundle at: %s", o.ToTar)
+	clilog.Infof("saving plugin bundle at: %s", o.ToTar)
 	err = tarinator.Tarinate([]string{tempPluginBundleDir}, o.ToTar)
 	if err != nil {
 		return errors.Wrap(err, "error while creating archive file")
@@ -117,7 +118,7 @@ func (o *DownloadPluginBundleOptions) getSelectedPluginInfo() ([]*plugininventor
 	}
 	defer os.RemoveAll(tempDBDir)
 
-	log.Infof("Getting selected plugin information...")
+	clilog.Infof("Getting selected plugin information...")
 
 	// Download the plugin inventory oci image to tempDBDir
 	inventoryFile := filepath.Join(tempDBDir, plugininventory.SQliteDBFileName)
@@ -142,9 +143,9 @@ func (o *DownloadPluginBundleOptions) getSelectedPluginInfo() ([]*plugininventor
 			return nil, nil, errors.Wrap(err, "unable to read all plugins from database")
 		}
 		if len(selectedPluginEntries) == 1 {
-			log.Infof("will be downloading the one plugin from: %s", o.PluginInventoryImage)
+			clilog.Infof("will be downloading the one plugin from: %s", o.PluginInventoryImage)
 		} else {
-			log.Infof("will be downloading the %d plugins from: %s", len(selectedPluginEntries), o.PluginInventoryImage)
+			clilog.Infof("will be downloading the %d plugins from: %s", len(selectedPluginEntries), o.PluginInventoryImage)
 		}
 	} else {
 		// If groups were provided as argument select only provided plugin groups and
@@ -230,9 +231,9 @@ func (o *DownloadPluginBundleOptions) getAllPluginGroupsAndPluginEntriesFromPlug
 		}
 		groupIDWithVersion := fmt.Sprintf("%s:%s", plugininventory.PluginGroupToID(pg), pg.RecommendedVersion)
 		if groupPluginsCount == 1 {
-			log.Infof("will be downloading the one plugin from group: %s", groupIDWithVersion)
+			clilog.Infof("will be downloading the one plugin from group: %s", groupIDWithVersion)
 		} else {
-			log.Infof("will be downloading the %d plugins from group: %s", groupPluginsCount, groupIDWithVersion)
+			clilog.Infof("will be downloading the %d plugins from group: %s", groupPluginsCount, groupIDWithVersion)
 		}
 	}
 	return pluginGroups, allPluginEntries, nil
@@ -252,7 +253,7 @@ func (o *DownloadPluginBundleOptions) downloadImagesAsTarFile(pluginEntries []*p
 
 	// Download plugin inventory database as tar file
 	pluginInventoryFileNameTar := "plugin-inventory-image.tar.gz"
-	log.Infof("downloading image %q", o.PluginInventoryImage)
+	clilog.Infof("downloading image %q", o.PluginInventoryImage)
 	err := o.ImageProcessor.CopyImageToTar(o.PluginInventoryImage, filepath.Join(downloadDir, pluginInventoryFileNameTar))
 	if err != nil {
 		return "", nil, err
@@ -269,8 +270,8 @@ func (o *DownloadPluginBundleOptions) downloadImagesAsTarFile(pluginEntries []*p
 	for _, pe := range pluginEntries {
 		for version, artifacts := range pe.Artifacts {
 			for _, a := range artifacts {
-				log.Infof("---------------------------")
-				log.Infof("downloading image %q", a.Image)
+				clilog.Infof("---------------------------")
+				clilog.Infof("downloading image %q", a.Image)
 				tarfileName := fmt.Sprintf("%s-%s-%s_%s-%s.tar.gz", pe.Name, pe.Target, a.OS, a.Arch, version)
 				err = o.ImageProcessor.CopyImageToTar(a.Image, filepath.Join(downloadDir, tarfileName))
 				if err != nil {
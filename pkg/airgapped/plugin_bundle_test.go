@@ -490,6 +490,92 @@ imagesToCopy:
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
+
+	var _ = Context("Tests for copying a plugin discovery source", func() {
+		var cpso *CopyPluginSourceOptions
+
+		// downloadImageAndSaveFilesToDirStub provides the plugin inventory or,
+		// for the metadata image of the destination repository, an empty metadata database
+		downloadImageAndSaveFilesToDirStub := func(image, path string) error {
+			if image == "fake.newfakerepo.abc/plugin/plugin-inventory-metadata:latest" {
+				return downloadInventoryMetadataImageWithNoExistingPlugins(image, path)
+			}
+			return downloadInventoryImageAndSaveFilesToDirStub(image, path)
+		}
+
+		BeforeEach(func() {
+			cpso = &CopyPluginSourceOptions{
+				PluginInventoryImage: dpbo.PluginInventoryImage,
+				DestinationRepo:      "fake.newfakerepo.abc/plugin",
+				ImageProcessor:       fakeImageOperations,
+			}
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadImageAndSaveFilesToDirStub)
+			fakeImageOperations.CopyImageToTarCalls(copyImageToTarStub)
+			fakeImageOperations.CopyImageFromTarCalls(func(_, _ string) error { return nil })
+			fakeImageOperations.PushImageReturns(nil)
+		})
+
+		var _ = It("when downloading plugin inventory image fail with error, it should return an error", func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirReturns(errors.New("fake error"))
+
+			results, err := cpso.CopyPluginSource()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("failed to download plugin inventory image"))
+			Expect(results).To(BeEmpty())
+		})
+
+		var _ = It("when everything works as expected, it should copy all images and publish the metadata image", func() {
+			pushCount := fakeImageOperations.PushImageCallCount()
+
+			results, err := cpso.CopyPluginSource()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(HaveLen(5))
+			Expect(results[0].SourceImage).To(Equal(cpso.PluginInventoryImage))
+			Expect(results[0].DestinationImage).To(Equal("fake.newfakerepo.abc/plugin/plugin-inventory"))
+			for _, r := range results {
+				Expect(r.Error).NotTo(HaveOccurred())
+			}
+
+			Expect(fakeImageOperations.PushImageCallCount()).To(Equal(pushCount + 1))
+			image, _ := fakeImageOperations.PushImageArgsForCall(pushCount)
+			Expect(image).To(Equal("fake.newfakerepo.abc/plugin/plugin-inventory-metadata:latest"))
+		})
+
+		var _ = It("when a group is specified, it should only copy the images of the plugins of the group", func() {
+			cpso.Groups = []string{"fakevendor-fakepublisher/default:v1.0.0"}
+
+			results, err := cpso.CopyPluginSource()
+			Expect(err).NotTo(HaveOccurred())
+			// The inventory image, the bar plugin of the group and the essential telemetry plugin
+			Expect(results).To(HaveLen(3))
+		})
+
+		var _ = It("when some images cannot be copied, it should copy the other images and not publish the metadata image", func() {
+			pushCount := fakeImageOperations.PushImageCallCount()
+			fakeImageOperations.CopyImageFromTarCalls(func(_, repo string) error {
+				if repo == "fake.newfakerepo.abc/plugin/path/linux/amd64/global/foo" {
+					return errors.New("fake error")
+				}
+				return nil
+			})
+
+			results, err := cpso.CopyPluginSource()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("1 of the 5 images could not be copied"))
+			Expect(results).To(HaveLen(5))
+
+			var failed []*ImageCopyResult
+			for _, r := range results {
+				if r.Error != nil {
+					failed = append(failed, r)
+				}
+			}
+			Expect(failed).To(HaveLen(1))
+			Expect(failed[0].SourceImage).To(Equal("fake.fakerepo.abc/plugin/path/linux/amd64/global/foo:v0.0.2"))
+			Expect(failed[0].Error.Error()).To(ContainSubstring("error while uploading image: fake error"))
+			Expect(fakeImageOperations.PushImageCallCount()).To(Equal(pushCount))
+		})
+	})
 })
 
 // Create incorrect plugin bundle tar file with empty content
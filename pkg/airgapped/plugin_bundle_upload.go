@@ -14,9 +14,9 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
-	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
 
 // UploadPluginBundleOptions defines options for uploading plugin bundle
@@ -37,7 +37,7 @@ func (o *UploadPluginBundleOptions) UploadPluginBundle() error {
 	defer os.RemoveAll(tempDir)
 
 	// Untar the specified plugin bundle to the temp directory
-	log.Infof("extracting %q for processing...", o.Tar)
+	clilog.Infof("extracting %q for processing...", o.Tar)
 	err = tarinator.UnTarinate(tempDir, o.Tar)
 	if err != nil {
 		return errors.Wrap(err, "unable to extract provided file")
@@ -62,18 +62,18 @@ func (o *UploadPluginBundleOptions) UploadPluginBundle() error {
 		if err != nil {
 			return errors.Wrap(err, "error while constructing the repo image path")
 		}
-		log.Infof("---------------------------")
-		log.Infof("uploading image %q", repoImagePath)
+		clilog.Infof("---------------------------")
+		clilog.Infof("uploading image %q", repoImagePath)
 		err = o.ImageProcessor.CopyImageFromTar(imageTar, repoImagePath)
 		if err != nil {
 			return errors.Wrap(err, "error while uploading image")
 		}
 	}
-	log.Infof("---------------------------")
-	log.Infof("---------------------------")
+	clilog.Infof("---------------------------")
+	clilog.Infof("---------------------------")
 
 	// Publish plugin inventory metadata image after merging inventory metadata
-	log.Infof("publishing plugin inventory metadata image...")
+	clilog.Infof("publishing plugin inventory metadata image...")
 	bundledPluginInventoryMetadataDBFilePath := filepath.Join(pluginBundleDir, manifest.InventoryMetadataImage.SourceFilePath)
 	pluginInventoryMetadataImageWithTag, err := utils.JoinURL(o.DestinationRepo, manifest.InventoryMetadataImage.RelativeImagePathWithTag)
 	if err != nil {
@@ -84,19 +84,19 @@ func (o *UploadPluginBundleOptions) UploadPluginBundle() error {
 		return errors.Wrap(err, "error while merging the plugin inventory metadata database before uploading metadata image")
 	}
 
-	log.Infof("uploading image %q", pluginInventoryMetadataImageWithTag)
+	clilog.Infof("uploading image %q", pluginInventoryMetadataImageWithTag)
 	err = o.ImageProcessor.PushImage(pluginInventoryMetadataImageWithTag, []string{bundledPluginInventoryMetadataDBFilePath})
 	if err != nil {
 		return errors.Wrap(err, "error while uploading image")
 	}
 
-	log.Infof("---------------------------")
+	clilog.Infof("---------------------------")
 
 	joinedURL, err := utils.JoinURL(o.DestinationRepo, manifest.RelativeInventoryImagePathWithTag)
 	if err != nil {
 		return errors.Wrap(err, "error while constructing the image URL")
 	}
-	log.Infof("successfully published all plugin images to %q", joinedURL)
+	clilog.Infof("successfully published all plugin images to %q", joinedURL)
 
 	return nil
 }
@@ -113,9 +113,9 @@ func (o *UploadPluginBundleOptions) mergePluginInventoryMetadata(pluginInventory
 		if err != nil {
 			return err
 		}
-		log.Infof("plugin inventory metadata image %q is present. Merging the plugin inventory metadata", pluginInventoryMetadataImageWithTag)
+		clilog.Infof("plugin inventory metadata image %q is present. Merging the plugin inventory metadata", pluginInventoryMetadataImageWithTag)
 	} else {
-		log.Infof("plugin inventory metadata image %q is not present. Skipping merging of the plugin inventory metadata", pluginInventoryMetadataImageWithTag)
+		clilog.Infof("plugin inventory metadata image %q is not present. Skipping merging of the plugin inventory metadata", pluginInventoryMetadataImageWithTag)
 	}
 	return nil
 }
@@ -0,0 +1,151 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package airgapped
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper/sigverifier"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+// CopyPluginSourceOptions defines options for copying a discovery source,
+// meaning its plugin inventory image and all the plugin images it references,
+// directly to another repository
+type CopyPluginSourceOptions struct {
+	PluginInventoryImage string
+	DestinationRepo      string
+	Groups               []string
+
+	ImageProcessor carvelhelpers.ImageOperationsImpl
+}
+
+// ImageCopyResult is the outcome of the copy of one image
+type ImageCopyResult struct {
+	SourceImage      string
+	DestinationImage string
+	Error            error
+}
+
+// CopyPluginSource copies the plugin inventory image and the plugin images it references
+// to the destination repository, one image at a time, preserving their relative paths
+// and digests.  A failure to copy an image does not prevent the other images from being
+// copied.  The plugin inventory metadata image of the destination repository is then
+// updated to include the copied plugins and plugin groups, as done by "upload-bundle",
+// unless some images could not be copied.  The outcome of the copy of each image
+// is returned.
+func (o *CopyPluginSourceOptions) CopyPluginSource() ([]*ImageCopyResult, error) {
+	// Verify the inventory image signature before downloading the plugin inventory database
+	if err := sigverifier.VerifyInventoryImageSignature(o.PluginInventoryImage); err != nil {
+		return nil, err
+	}
+
+	download := &DownloadPluginBundleOptions{
+		PluginInventoryImage: o.PluginInventoryImage,
+		Groups:               o.Groups,
+		ImageProcessor:       o.ImageProcessor,
+	}
+	selectedPluginEntries, selectedPluginGroups, err := download.getSelectedPluginInfo()
+	if err != nil {
+		return nil, errors.Wrap(err, "error while getting selected plugin and plugin group information")
+	}
+
+	tempDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create temp directory")
+	}
+	defer os.RemoveAll(tempDir)
+
+	images := o.getImagesToCopy(selectedPluginEntries)
+	results := make([]*ImageCopyResult, 0, len(images))
+	failures := 0
+	for i, image := range images {
+		result := &ImageCopyResult{SourceImage: image}
+		result.DestinationImage, result.Error = utils.JoinURL(o.DestinationRepo, GetImageRelativePath(image, path.Dir(o.PluginInventoryImage), false))
+		if result.Error == nil {
+			clilog.Infof("[%d/%d] copying image %q to %q", i+1, len(images), image, result.DestinationImage)
+			result.Error = o.copyImage(image, result.DestinationImage, filepath.Join(tempDir, "image.tar.gz"))
+		}
+		if result.Error != nil {
+			log.Warningf("unable to copy image %q: %v", image, result.Error)
+			failures++
+		}
+		results = append(results, result)
+	}
+	if failures > 0 {
+		return results, errors.Errorf("%d of the %d images could not be copied, the plugin inventory metadata image was not published", failures, len(images))
+	}
+
+	// Publish plugin inventory metadata image after merging inventory metadata
+	clilog.Infof("publishing plugin inventory metadata image...")
+	inventoryMetadataImageInfo, err := download.savePluginInventoryMetadata(selectedPluginGroups, selectedPluginEntries, tempDir)
+	if err != nil {
+		return results, errors.Wrap(err, "error while saving plugin inventory metadata")
+	}
+	pluginInventoryMetadataDBFilePath := filepath.Join(tempDir, inventoryMetadataImageInfo.SourceFilePath)
+	pluginInventoryMetadataImageWithTag, err := utils.JoinURL(o.DestinationRepo, inventoryMetadataImageInfo.RelativeImagePathWithTag)
+	if err != nil {
+		return results, errors.Wrap(err, "error while constructing the plugin inventory metadata image with tag")
+	}
+	upload := &UploadPluginBundleOptions{
+		DestinationRepo: o.DestinationRepo,
+		ImageProcessor:  o.ImageProcessor,
+	}
+	if err := upload.mergePluginInventoryMetadata(pluginInventoryMetadataImageWithTag, pluginInventoryMetadataDBFilePath, tempDir); err != nil {
+		return results, errors.Wrap(err, "error while merging the plugin inventory metadata database before uploading metadata image")
+	}
+	clilog.Infof("uploading image %q", pluginInventoryMetadataImageWithTag)
+	if err := o.ImageProcessor.PushImage(pluginInventoryMetadataImageWithTag, []string{pluginInventoryMetadataDBFilePath}); err != nil {
+		return results, errors.Wrap(err, "error while uploading image")
+	}
+
+	joinedURL, err := utils.JoinURL(o.DestinationRepo, GetImageRelativePath(o.PluginInventoryImage, path.Dir(o.PluginInventoryImage), true))
+	if err != nil {
+		return results, errors.Wrap(err, "error while constructing the image URL")
+	}
+	clilog.Infof("successfully copied all plugin images to %q", joinedURL)
+	return results, nil
+}
+
+// getImagesToCopy returns the plugin inventory image followed by the images
+// of all the artifacts of the plugin entries, in a deterministic order
+func (o *CopyPluginSourceOptions) getImagesToCopy(pluginEntries []*plugininventory.PluginInventoryEntry) []string {
+	images := []string{o.PluginInventoryImage}
+	for _, pe := range pluginEntries {
+		versions := make([]string, 0, len(pe.Artifacts))
+		for version := range pe.Artifacts {
+			versions = append(versions, version)
+		}
+		sort.Strings(versions)
+		for _, version := range versions {
+			for _, a := range pe.Artifacts[version] {
+				images = append(images, a.Image)
+			}
+		}
+	}
+	return images
+}
+
+// copyImage copies an image to the destination repository through a temporary
+// tar file, which is removed once the image has been copied
+func (o *CopyPluginSourceOptions) copyImage(sourceImage, destinationRepo, tarFile string) error {
+	defer os.Remove(tarFile)
+
+	if err := o.ImageProcessor.CopyImageToTar(sourceImage, tarFile); err != nil {
+		return errors.Wrap(err, "error while downloading image")
+	}
+	if err := o.ImageProcessor.CopyImageFromTar(tarFile, destinationRepo); err != nil {
+		return errors.Wrap(err, "error while uploading image")
+	}
+	return nil
+}
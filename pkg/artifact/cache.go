@@ -0,0 +1,134 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package artifact
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+)
+
+// cachedArtifact describes a plugin binary stored in the artifact cache
+type cachedArtifact struct {
+	path     string
+	size     int64
+	lastUsed time.Time
+}
+
+// getArtifactCacheDir returns the directory where the plugin binaries are cached
+func getArtifactCacheDir() string {
+	return filepath.Join(common.DefaultCacheDir, common.PluginArtifactDirName)
+}
+
+// GetCachedArtifact returns the cached plugin binary with the specified sha256 digest.
+// The content of the cached binary is verified against the digest before being returned;
+// a cached binary that does not match its digest is removed from the cache.
+// The returned boolean is false if the binary is not available from the cache.
+func GetCachedArtifact(digest string) ([]byte, bool) {
+	if digest == "" {
+		return nil, false
+	}
+	path := filepath.Join(getArtifactCacheDir(), digest)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	if fmt.Sprintf("%x", sha256.Sum256(b)) != digest {
+		_ = os.Remove(path)
+		return nil, false
+	}
+	// Record the use of the binary for the eviction of the least recently used binaries
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return b, true
+}
+
+// CacheArtifact stores a plugin binary in the artifact cache under its sha256 digest.
+// The binary is written to a temporary file first so that a partially written binary
+// is never found in the cache.
+func CacheArtifact(digest string, b []byte) error {
+	if digest == "" {
+		return nil
+	}
+	cacheDir := getArtifactCacheDir()
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return errors.Wrap(err, "unable to create the plugin artifact cache")
+	}
+
+	tmpFile, err := os.CreateTemp(cacheDir, digest+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "unable to cache the plugin binary")
+	}
+	_, err = tmpFile.Write(b)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), filepath.Join(cacheDir, digest))
+	}
+	if err != nil {
+		_ = os.Remove(tmpFile.Name())
+		return errors.Wrap(err, "unable to cache the plugin binary")
+	}
+	return nil
+}
+
+// PruneArtifactCache evicts the least recently used plugin binaries from the artifact
+// cache until their total size is at most maxSize bytes.  The digests of the evicted
+// binaries are returned.
+func PruneArtifactCache(maxSize int64) ([]string, error) {
+	cacheDir := getArtifactCacheDir()
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "unable to read the plugin artifact cache")
+	}
+
+	var artifacts []cachedArtifact
+	var totalSize int64
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		artifacts = append(artifacts, cachedArtifact{
+			path:     filepath.Join(cacheDir, entry.Name()),
+			size:     info.Size(),
+			lastUsed: info.ModTime(),
+		})
+		totalSize += info.Size()
+	}
+
+	sort.Slice(artifacts, func(i, j int) bool {
+		return artifacts[i].lastUsed.Before(artifacts[j].lastUsed)
+	})
+
+	var evicted []string
+	errorList := make([]error, 0)
+	for _, a := range artifacts {
+		if totalSize <= maxSize {
+			break
+		}
+		if err := os.Remove(a.path); err != nil {
+			errorList = append(errorList, errors.Wrapf(err, "unable to evict the cached plugin binary %q", filepath.Base(a.path)))
+			continue
+		}
+		totalSize -= a.size
+		evicted = append(evicted, filepath.Base(a.path))
+	}
+	return evicted, kerrors.NewAggregate(errorList)
+}
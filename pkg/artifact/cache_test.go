@@ -0,0 +1,108 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package artifact
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+)
+
+func setupArtifactCacheForTesting(t *testing.T) func() {
+	tmpDir, err := os.MkdirTemp("", "artifact-cache")
+	assert.NoError(t, err)
+	origCacheDir := common.DefaultCacheDir
+	common.DefaultCacheDir = tmpDir
+	return func() {
+		common.DefaultCacheDir = origCacheDir
+		os.RemoveAll(tmpDir)
+	}
+}
+
+func digestOf(b []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(b))
+}
+
+func TestArtifactCache(t *testing.T) {
+	assert := assert.New(t)
+	defer setupArtifactCacheForTesting(t)()
+
+	binary := []byte("plugin binary")
+	digest := digestOf(binary)
+
+	// Nothing is cached yet
+	_, found := GetCachedArtifact(digest)
+	assert.False(found)
+
+	assert.NoError(CacheArtifact(digest, binary))
+	b, found := GetCachedArtifact(digest)
+	assert.True(found)
+	assert.Equal(binary, b)
+
+	// No temporary file is left behind
+	entries, err := os.ReadDir(getArtifactCacheDir())
+	assert.NoError(err)
+	assert.Len(entries, 1)
+
+	// A binary without a digest is never cached
+	assert.NoError(CacheArtifact("", binary))
+	_, found = GetCachedArtifact("")
+	assert.False(found)
+}
+
+func TestArtifactCacheWithCorruptedBinary(t *testing.T) {
+	assert := assert.New(t)
+	defer setupArtifactCacheForTesting(t)()
+
+	binary := []byte("plugin binary")
+	digest := digestOf(binary)
+	assert.NoError(CacheArtifact(digest, binary))
+
+	cachedFile := filepath.Join(getArtifactCacheDir(), digest)
+	assert.NoError(os.WriteFile(cachedFile, []byte("corrupted"), 0644))
+
+	_, found := GetCachedArtifact(digest)
+	assert.False(found)
+	assert.NoFileExists(cachedFile)
+}
+
+func TestPruneArtifactCache(t *testing.T) {
+	assert := assert.New(t)
+	defer setupArtifactCacheForTesting(t)()
+
+	// The cache does not exist yet
+	evicted, err := PruneArtifactCache(0)
+	assert.NoError(err)
+	assert.Empty(evicted)
+
+	now := time.Now()
+	var digests []string
+	for i, age := range []time.Duration{3 * time.Hour, 2 * time.Hour, 1 * time.Hour} {
+		binary := []byte(fmt.Sprintf("plugin binary %d", i))
+		digest := digestOf(binary)
+		assert.NoError(CacheArtifact(digest, binary))
+		lastUsed := now.Add(-age)
+		assert.NoError(os.Chtimes(filepath.Join(getArtifactCacheDir(), digest), lastUsed, lastUsed))
+		digests = append(digests, digest)
+	}
+
+	// Each binary is 15 bytes; the least recently used ones are evicted first
+	evicted, err = PruneArtifactCache(48)
+	assert.NoError(err)
+	assert.Empty(evicted)
+
+	evicted, err = PruneArtifactCache(20)
+	assert.NoError(err)
+	assert.Equal(digests[:2], evicted)
+
+	_, found := GetCachedArtifact(digests[2])
+	assert.True(found)
+}
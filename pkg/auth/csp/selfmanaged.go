@@ -22,6 +22,8 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 )
 
 const (
@@ -66,7 +68,7 @@ func callbackHandler(w http.ResponseWriter, r *http.Request) {
 	if code == "" {
 		errMsg := fmt.Sprintf("[state] query params is required, URL %s did not have this query parameters", html.EscapeString(r.URL.String()))
 		http.Error(w, errMsg, http.StatusBadRequest)
-		log.Info(errMsg)
+		clilog.Info(errMsg)
 		return
 	}
 
@@ -75,7 +77,7 @@ func callbackHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		errMsg := fmt.Sprintf("failed to exchange auth code for oauth tokens, err=%v", err)
 		http.Error(w, errMsg, http.StatusInternalServerError)
-		log.Info(errMsg)
+		clilog.Info(errMsg)
 		return
 	}
 	fmt.Fprint(w, "You have successfully logged in! You can now safely close this window")
@@ -156,7 +158,7 @@ func GetAccessTokenFromSelfManagedIDP(refreshToken, issuerURL string) (*Token, e
 				TokenType:    "id_token",
 			}, nil
 		}
-		log.Infof("failed to refresh token, err %v", err)
+		clilog.Infof("failed to refresh token, err %v", err)
 		// proceed with login flow through the browser
 	}
 	// set the issuer package variable to be used in the callback handler
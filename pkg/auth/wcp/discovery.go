@@ -9,6 +9,8 @@ import (
 	"net/http"
 
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 )
 
 const (
@@ -26,7 +28,7 @@ func IsVSphereSupervisor(endpoint string, httpClient *http.Client) (bool, error)
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		log.Infof("Failed to test for vSphere supervisor: %+v", err)
+		clilog.Infof("Failed to test for vSphere supervisor: %+v", err)
 		return false, err
 	}
 	defer resp.Body.Close()
@@ -36,6 +38,6 @@ func IsVSphereSupervisor(endpoint string, httpClient *http.Client) (bool, error)
 		return true, nil
 	}
 
-	log.Infof("Could not get login banner from server, response code = %+v", resp.StatusCode)
+	clilog.Infof("Could not get login banner from server, response code = %+v", resp.StatusCode)
 	return false, nil
 }
@@ -0,0 +1,261 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+// credentialHelperPrefix is the prefix of the executables implementing
+// the docker credential helper protocol
+const credentialHelperPrefix = "docker-credential-"
+
+// ErrCredentialsNotFound is returned when a credential helper has no credentials for a registry
+var ErrCredentialsNotFound = errors.New("credentials not found")
+
+// RegistryCredentials are the credentials to use to access a registry.
+// If CredentialHelper is set, the credentials are obtained by invoking
+// the helper following the docker credential helper protocol.
+// When no credentials are specified, the registry is accessed anonymously.
+type RegistryCredentials struct {
+	// Username to authenticate with, along with the Password
+	Username string
+	// Password to authenticate with, along with the Username
+	Password string
+	// Token is a registry bearer token
+	Token string
+	// CredentialHelper is the name of a docker credential helper (e.g. "ecr-login"),
+	// or the path to the executable implementing the protocol
+	CredentialHelper string
+	// LoginCredentials uses, when no other credentials are specified, the credentials saved by
+	// 'tanzu plugin source login' for the registry being accessed.  The registries without saved
+	// credentials, or whose credential helper fails, are accessed anonymously.
+	LoginCredentials bool
+}
+
+// String never shows the secrets so that credentials can't be logged by mistake
+func (c RegistryCredentials) String() string {
+	if c.CredentialHelper != "" {
+		return fmt.Sprintf("credential helper %q", c.CredentialHelper)
+	}
+	if c.Username != "" {
+		return fmt.Sprintf("user %q", c.Username)
+	}
+	if c.Token != "" {
+		return "token"
+	}
+	if c.LoginCredentials {
+		return "login credentials"
+	}
+	return "anonymous"
+}
+
+// IsEmpty returns true if no credentials are specified
+func (c *RegistryCredentials) IsEmpty() bool {
+	return c == nil || (c.Username == "" && c.Password == "" && c.Token == "" && c.CredentialHelper == "" && !c.LoginCredentials)
+}
+
+// credentialHelperOutput is the response of a credential helper to a "get" request
+type credentialHelperOutput struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// resolve returns the username, password and token to use for the specified registry.
+// Empty values are returned if no credentials are available, in which case
+// the registry should be accessed anonymously.
+func (c *RegistryCredentials) resolve(registryHost string) (username, password, token string, err error) {
+	switch {
+	case c.IsEmpty():
+		return "", "", "", nil
+	case c.CredentialHelper != "":
+		username, password, err = getHelperCredentials(c.CredentialHelper, registryHost)
+		return username, password, "", err
+	case c.Username != "" || c.Password != "" || c.Token != "":
+		return c.Username, c.Password, c.Token, nil
+	}
+
+	// Only the credentials saved for this registry are used
+	helper := config.GetRegistryCredentialHelper(registryHost)
+	if helper == "" {
+		return "", "", "", nil
+	}
+	username, password, err = getHelperCredentials(helper, registryHost)
+	if err != nil {
+		log.Warningf("Unable to get the credentials saved for registry %q, accessing it anonymously: %v", registryHost, err)
+		return "", "", "", nil
+	}
+	return username, password, "", nil
+}
+
+// getHelperCredentials returns the username and the secret stored by the credential helper for the
+// registry.  Empty values are returned if the helper has no credentials for the registry.
+func getHelperCredentials(helper, registryHost string) (username, secret string, err error) {
+	helper = credentialHelperExecutable(helper)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(helper, "get")
+	cmd.Stdin = strings.NewReader(registryHost)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// A helper reports that it has no credentials for the registry with this message.
+		// Fallback to anonymous access in that case.
+		if strings.Contains(stdout.String()+stderr.String(), "credentials not found") {
+			return "", "", nil
+		}
+		// Don't include the output of the helper in the error, it could contain secrets
+		return "", "", errors.Wrapf(err, "credential helper %q failed for registry %q", helper, registryHost)
+	}
+
+	var output credentialHelperOutput
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return "", "", errors.Errorf("credential helper %q returned an invalid response for registry %q", helper, registryHost)
+	}
+	return output.Username, output.Secret, nil
+}
+
+// Keychain returns a keychain providing the credentials to the libraries accessing
+// the registries directly, such as cosign.  A nil keychain is returned if no
+// credentials are specified, in which case the registries are accessed anonymously.
+func (c *RegistryCredentials) Keychain() authn.Keychain {
+	if c.IsEmpty() {
+		return nil
+	}
+	return &credentialsKeychain{credentials: c}
+}
+
+// credentialsKeychain implements authn.Keychain with RegistryCredentials
+type credentialsKeychain struct {
+	credentials *RegistryCredentials
+}
+
+func (k *credentialsKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	username, password, token, err := k.credentials.resolve(target.RegistryStr())
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case token != "":
+		return &authn.Bearer{Token: token}, nil
+	case username != "":
+		return &authn.Basic{Username: username, Password: password}, nil
+	}
+	return authn.Anonymous, nil
+}
+
+// credentialHelperExecutable returns the executable implementing the specified credential helper
+func credentialHelperExecutable(helper string) string {
+	if !strings.ContainsRune(helper, filepath.Separator) && !strings.HasPrefix(helper, credentialHelperPrefix) {
+		return credentialHelperPrefix + helper
+	}
+	return helper
+}
+
+// DefaultCredentialHelper returns the credential helper storing the
+// credentials in the keychain of the operating system
+func DefaultCredentialHelper() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "osxkeychain"
+	case "windows":
+		return "wincred"
+	default:
+		return "secretservice"
+	}
+}
+
+// StoreCredentials stores the credentials of the registry with the specified credential helper
+func StoreCredentials(helper, registryHost, username, secret string) error {
+	input, err := json.Marshal(credentialHelperOutput{ServerURL: registryHost, Username: username, Secret: secret})
+	if err != nil {
+		return err
+	}
+	helper = credentialHelperExecutable(helper)
+	cmd := exec.Command(helper, "store")
+	cmd.Stdin = bytes.NewReader(input)
+	// Don't include the output of the helper in the error, it could contain secrets
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "credential helper %q failed to store the credentials of registry %q", helper, registryHost)
+	}
+	return nil
+}
+
+// EraseCredentials removes the credentials of the registry from the specified credential helper
+func EraseCredentials(helper, registryHost string) error {
+	helper = credentialHelperExecutable(helper)
+	var output bytes.Buffer
+	cmd := exec.Command(helper, "erase")
+	cmd.Stdin = strings.NewReader(registryHost)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		if strings.Contains(output.String(), "credentials not found") {
+			return errors.Wrapf(ErrCredentialsNotFound, "credential helper %q has no credentials for registry %q", helper, registryHost)
+		}
+		return errors.Wrapf(err, "credential helper %q failed to erase the credentials of registry %q", helper, registryHost)
+	}
+	return nil
+}
+
+// RegistryAuthError is returned when a registry refuses access to an image
+// because credentials are missing or were rejected.
+type RegistryAuthError struct {
+	// Registry is the host of the registry that refused the access
+	Registry string
+	// Anonymous is true if the registry was accessed without credentials
+	Anonymous bool
+	// Err is the error returned by the registry
+	Err error
+}
+
+func (e *RegistryAuthError) Error() string {
+	// The original error comes first as it includes the details of the request that failed
+	if e.Anonymous {
+		return fmt.Sprintf("%v (registry %q refused anonymous access: if the image is correct, credentials are required)", e.Err, e.Registry)
+	}
+	return fmt.Sprintf("%v (registry %q rejected the configured credentials)", e.Err, e.Registry)
+}
+
+func (e *RegistryAuthError) Unwrap() error {
+	return e.Err
+}
+
+// asRegistryAuthError converts err into a RegistryAuthError if it
+// indicates that the access to the registry was refused.
+func asRegistryAuthError(err error, registryHost string, anonymous bool) error {
+	if err == nil || !isAuthFailure(err) {
+		return err
+	}
+	return &RegistryAuthError{
+		Registry:  registryHost,
+		Anonymous: anonymous,
+		Err:       err,
+	}
+}
+
+func isAuthFailure(err error) bool {
+	var transportErr *transport.Error
+	if errors.As(err, &transportErr) {
+		return transportErr.StatusCode == http.StatusUnauthorized || transportErr.StatusCode == http.StatusForbidden
+	}
+	// Some errors from the registry libraries are not wrapped
+	msg := err.Error()
+	return strings.Contains(msg, string(transport.UnauthorizedErrorCode)) || strings.Contains(msg, string(transport.DeniedErrorCode))
+}
@@ -0,0 +1,207 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+)
+
+func TestRegistryCredentialsString(t *testing.T) {
+	assert := assert.New(t)
+
+	creds := RegistryCredentials{Username: "user", Password: "secret"}
+	assert.Equal(`user "user"`, creds.String())
+	assert.Equal(`user "user"`, fmt.Sprintf("%v", creds))
+
+	creds = RegistryCredentials{Token: "secret"}
+	assert.Equal("token", creds.String())
+
+	creds = RegistryCredentials{CredentialHelper: "ecr-login"}
+	assert.Equal(`credential helper "ecr-login"`, creds.String())
+
+	var nilCreds *RegistryCredentials
+	assert.True(nilCreds.IsEmpty())
+	assert.True((&RegistryCredentials{}).IsEmpty())
+}
+
+func TestRegistryCredentialsKeychain(t *testing.T) {
+	assert := assert.New(t)
+
+	var nilCreds *RegistryCredentials
+	assert.Nil(nilCreds.Keychain())
+
+	registry, err := name.NewRegistry("registry.example.com")
+	assert.Nil(err)
+
+	auth, err := (&RegistryCredentials{Username: "user", Password: "secret"}).Keychain().Resolve(registry)
+	assert.Nil(err)
+	config, err := auth.Authorization()
+	assert.Nil(err)
+	assert.Equal("user", config.Username)
+	assert.Equal("secret", config.Password)
+
+	auth, err = (&RegistryCredentials{Token: "secret"}).Keychain().Resolve(registry)
+	assert.Nil(err)
+	config, err = auth.Authorization()
+	assert.Nil(err)
+	assert.Equal("secret", config.RegistryToken)
+}
+
+func TestRegistryCredentialsResolve(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	helper := filepath.Join(dir, "docker-credential-test")
+	script := `#!/bin/sh
+read host
+if [ "$host" = "registry.example.com" ]; then
+  echo '{"ServerURL":"registry.example.com","Username":"user","Secret":"secret"}'
+else
+  echo "credentials not found in native keychain"
+  exit 1
+fi
+`
+	err := os.WriteFile(helper, []byte(script), 0o755)
+	assert.Nil(err)
+
+	creds := &RegistryCredentials{CredentialHelper: helper}
+	username, password, token, err := creds.resolve("registry.example.com")
+	assert.Nil(err)
+	assert.Equal("user", username)
+	assert.Equal("secret", password)
+	assert.Empty(token)
+
+	// Anonymous access is used when the helper has no credentials for the registry
+	username, password, token, err = creds.resolve("other.example.com")
+	assert.Nil(err)
+	assert.Empty(username)
+	assert.Empty(password)
+	assert.Empty(token)
+
+	creds = &RegistryCredentials{CredentialHelper: filepath.Join(dir, "missing")}
+	_, _, _, err = creds.resolve("registry.example.com")
+	assert.NotNil(err)
+
+	var nilCreds *RegistryCredentials
+	username, _, _, err = nilCreds.resolve("registry.example.com")
+	assert.Nil(err)
+	assert.Empty(username)
+}
+
+func TestRegistryCredentialsResolveLogin(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	helper := filepath.Join(dir, "docker-credential-test")
+	script := `#!/bin/sh
+read host
+echo '{"ServerURL":"'$host'","Username":"user","Secret":"secret"}'
+`
+	err := os.WriteFile(helper, []byte(script), 0o755)
+	assert.Nil(err)
+	t.Setenv(constants.ConfigVariableRegistryCredentialHelperPrefix+"REGISTRY_EXAMPLE_COM", helper)
+	t.Setenv(constants.ConfigVariableRegistryCredentialHelperPrefix+"BROKEN_EXAMPLE_COM", filepath.Join(dir, "missing"))
+
+	creds := &RegistryCredentials{LoginCredentials: true}
+	assert.False(creds.IsEmpty())
+	assert.Equal("login credentials", creds.String())
+
+	username, password, _, err := creds.resolve("registry.example.com")
+	assert.Nil(err)
+	assert.Equal("user", username)
+	assert.Equal("secret", password)
+
+	// The helper is only used for the registry it was saved for
+	username, _, _, err = creds.resolve("other.example.com")
+	assert.Nil(err)
+	assert.Empty(username)
+
+	// A failure of the helper falls back to anonymous access
+	username, _, _, err = creds.resolve("broken.example.com")
+	assert.Nil(err)
+	assert.Empty(username)
+}
+
+func TestStoreAndEraseCredentials(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	store := filepath.Join(dir, "store")
+	helper := filepath.Join(dir, "docker-credential-test")
+	script := `#!/bin/sh
+case "$1" in
+  store) cat > ` + store + ` ;;
+  erase)
+    if [ ! -f ` + store + ` ]; then
+      echo "credentials not found in native keychain"
+      exit 1
+    fi
+    rm ` + store + ` ;;
+esac
+`
+	err := os.WriteFile(helper, []byte(script), 0o755)
+	assert.Nil(err)
+
+	err = StoreCredentials(helper, "registry.example.com", "user", "secret")
+	assert.Nil(err)
+	stored, err := os.ReadFile(store)
+	assert.Nil(err)
+	assert.JSONEq(`{"ServerURL":"registry.example.com","Username":"user","Secret":"secret"}`, string(stored))
+
+	err = EraseCredentials(helper, "registry.example.com")
+	assert.Nil(err)
+	assert.NoFileExists(store)
+
+	err = EraseCredentials(helper, "registry.example.com")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), `has no credentials for registry "registry.example.com"`)
+	assert.ErrorIs(err, ErrCredentialsNotFound)
+
+	err = StoreCredentials(filepath.Join(dir, "missing"), "registry.example.com", "user", "secret")
+	assert.NotNil(err)
+	assert.NotContains(err.Error(), "secret")
+}
+
+func TestDefaultCredentialHelper(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Contains([]string{"osxkeychain", "wincred", "secretservice"}, DefaultCredentialHelper())
+	assert.Equal("docker-credential-pass", credentialHelperExecutable("pass"))
+	assert.Equal("docker-credential-pass", credentialHelperExecutable("docker-credential-pass"))
+}
+
+func TestAsRegistryAuthError(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(asRegistryAuthError(nil, "registry.example.com", true))
+
+	otherErr := errors.New("connection refused")
+	assert.Equal(otherErr, asRegistryAuthError(otherErr, "registry.example.com", true))
+
+	regErr := &transport.Error{StatusCode: http.StatusUnauthorized}
+	err := asRegistryAuthError(errors.Wrap(regErr, "error getting the image digest"), "registry.example.com", true)
+
+	var authErr *RegistryAuthError
+	assert.True(errors.As(err, &authErr))
+	assert.Equal("registry.example.com", authErr.Registry)
+	assert.True(authErr.Anonymous)
+	assert.Contains(err.Error(), "error getting the image digest")
+	assert.Contains(err.Error(), `registry "registry.example.com" refused anonymous access`)
+
+	err = asRegistryAuthError(errors.New("GET https://registry.example.com/v2/: DENIED: access denied"), "registry.example.com", false)
+	assert.True(errors.As(err, &authErr))
+	assert.False(authErr.Anonymous)
+	assert.Contains(err.Error(), `registry "registry.example.com" rejected the configured credentials`)
+}
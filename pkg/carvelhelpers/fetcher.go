@@ -30,12 +30,24 @@ func GetImageDigest(imageWithTag string) (string, string, error) {
 }
 
 // newRegistry returns a new registry object by also taking
-// into account for any custom registry provided by the user
-func newRegistry(registryHost string) (registry.Registry, error) {
+// into account for any custom registry provided by the user.
+// The registry is accessed anonymously unless credentials are provided.
+func newRegistry(registryHost string, credentials *RegistryCredentials) (registry.Registry, error) {
 	registryOpts := &ctlimg.Opts{
 		Anon: true,
 	}
 
+	username, password, token, err := credentials.resolve(registryHost)
+	if err != nil {
+		return nil, err
+	}
+	if username != "" || token != "" {
+		registryOpts.Anon = false
+		registryOpts.Username = username
+		registryOpts.Password = password
+		registryOpts.Token = token
+	}
+
 	regCertOptions, err := registry.GetRegistryCertOptions(registryHost)
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to get the registry certificate configuration")
@@ -43,5 +55,5 @@ func newRegistry(registryHost string) (registry.Registry, error) {
 	registryOpts.CACertPaths = regCertOptions.CACertPaths
 	registryOpts.VerifyCerts = !(regCertOptions.SkipCertVerify)
 	registryOpts.Insecure = regCertOptions.Insecure
-	return registry.New(registryOpts)
+	return registry.New(registryOpts, registry.WithClientCertificate(regCertOptions.ClientCertPath, regCertOptions.ClientKeyPath))
 }
@@ -14,11 +14,28 @@ import (
 )
 
 // ImageOperationOptions implements the ImageOperationsImpl interface by using `imgpkg` library
-type ImageOperationOptions struct{}
+type ImageOperationOptions struct {
+	// credentials used to access the registries, anonymous access is used if not set
+	credentials *RegistryCredentials
+}
+
+// ImageOperationOption customizes the image operations
+type ImageOperationOption func(*ImageOperationOptions)
+
+// WithRegistryCredentials uses the specified credentials to access the registries
+func WithRegistryCredentials(credentials *RegistryCredentials) ImageOperationOption {
+	return func(i *ImageOperationOptions) {
+		i.credentials = credentials
+	}
+}
 
 // NewImageOperationsImpl creates new ImgpkgWrapper instance
-func NewImageOperationsImpl() ImageOperationsImpl {
-	return &ImageOperationOptions{}
+func NewImageOperationsImpl(options ...ImageOperationOption) ImageOperationsImpl {
+	i := &ImageOperationOptions{}
+	for _, option := range options {
+		option(i)
+	}
+	return i
 }
 
 // CopyImageToTar downloads the image as tar file
@@ -28,7 +45,7 @@ func (i *ImageOperationOptions) CopyImageToTar(sourceImageName, destTarFile stri
 	if err != nil {
 		return err
 	}
-	reg, err := newRegistry(registryName)
+	reg, err := newRegistry(registryName, i.credentials)
 	if err != nil {
 		return errors.Wrapf(err, "unable to initialize registry")
 	}
@@ -42,7 +59,7 @@ func (i *ImageOperationOptions) CopyImageFromTar(sourceTarFile, destImageRepo st
 	if err != nil {
 		return err
 	}
-	reg, err := newRegistry(registryName)
+	reg, err := newRegistry(registryName, i.credentials)
 	if err != nil {
 		return errors.Wrapf(err, "unable to initialize registry")
 	}
@@ -52,17 +69,21 @@ func (i *ImageOperationOptions) CopyImageFromTar(sourceTarFile, destImageRepo st
 // DownloadImageAndSaveFilesToDir reads a plain OCI image and saves its
 // files to the specified location.
 func (i *ImageOperationOptions) DownloadImageAndSaveFilesToDir(imageWithTag, destinationDir string) error {
+	// The image can also be an OCI image layout directory or archive on disk
+	if registry.IsLocalImage(imageWithTag) {
+		return errors.Wrap(registry.DownloadLocalImage(imageWithTag, destinationDir), "error reading local image")
+	}
 	registryName, err := registry.GetRegistryName(imageWithTag)
 	if err != nil {
 		return err
 	}
-	reg, err := newRegistry(registryName)
+	reg, err := newRegistry(registryName, i.credentials)
 	if err != nil {
 		return errors.Wrapf(err, "unable to initialize registry")
 	}
 	err = reg.DownloadImage(imageWithTag, destinationDir)
 	if err != nil {
-		return errors.Wrap(err, "error downloading image")
+		return errors.Wrap(asRegistryAuthError(err, registryName, i.credentials.IsEmpty()), "error downloading image")
 	}
 	return nil
 }
@@ -75,27 +96,35 @@ func (i *ImageOperationOptions) GetFilesMapFromImage(imageWithTag string) (map[s
 	if err != nil {
 		return nil, err
 	}
-	reg, err := newRegistry(registryName)
+	reg, err := newRegistry(registryName, i.credentials)
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to initialize registry")
 	}
-	return reg.GetFiles(imageWithTag)
+	files, err := reg.GetFiles(imageWithTag)
+	return files, asRegistryAuthError(err, registryName, i.credentials.IsEmpty())
 }
 
 // GetImageDigest gets digest of the image
 func (i *ImageOperationOptions) GetImageDigest(imageWithTag string) (string, string, error) {
+	if registry.IsLocalImage(imageWithTag) {
+		hashAlgorithm, hashHexVal, err := registry.GetLocalImageDigest(imageWithTag)
+		if err != nil {
+			return "", "", errors.Wrap(err, "error getting the image digest")
+		}
+		return hashAlgorithm, hashHexVal, nil
+	}
 	registryName, err := registry.GetRegistryName(imageWithTag)
 	if err != nil {
 		return "", "", err
 	}
-	reg, err := newRegistry(registryName)
+	reg, err := newRegistry(registryName, i.credentials)
 	if err != nil {
 		return "", "", errors.Wrapf(err, "unable to initialize registry")
 	}
 
 	hashAlgorithm, hashHexVal, err := reg.GetImageDigest(imageWithTag)
 	if err != nil {
-		return "", "", errors.Wrap(err, "error getting the image digest")
+		return "", "", errors.Wrap(asRegistryAuthError(err, registryName, i.credentials.IsEmpty()), "error getting the image digest")
 	}
 
 	return hashAlgorithm, hashHexVal, nil
@@ -107,7 +136,7 @@ func (i *ImageOperationOptions) PushImage(imageWithTag string, filePaths []strin
 	if err != nil {
 		return err
 	}
-	reg, err := newRegistry(registryName)
+	reg, err := newRegistry(registryName, i.credentials)
 	if err != nil {
 		return errors.Wrapf(err, "unable to initialize registry")
 	}
@@ -120,7 +149,7 @@ func (i *ImageOperationOptions) ResolveImage(imageWithTag string) error {
 	if err != nil {
 		return err
 	}
-	reg, err := newRegistry(registryName)
+	reg, err := newRegistry(registryName, i.credentials)
 	if err != nil {
 		return errors.Wrapf(err, "unable to initialize registry")
 	}
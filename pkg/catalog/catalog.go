@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rogpeppe/go-internal/lockedfile"
@@ -162,6 +163,29 @@ func (c *ContextCatalog) Unlock() {
 	}
 }
 
+// IsInstallationPathRegistered returns true if the plugin binary at the specified path is used
+// by a plugin of the catalog of the standalone plugins or of the catalog of any context,
+// whether the context is active or not.
+func IsInstallationPathRegistered(installationPath string) (bool, error) {
+	c, _, err := getCatalogCache(false)
+	if err != nil {
+		return false, err
+	}
+
+	pluginAssociations := []PluginAssociation{c.StandAlonePlugins}
+	for _, spa := range c.ServerPlugins {
+		pluginAssociations = append(pluginAssociations, spa)
+	}
+	for i := range pluginAssociations {
+		for _, path := range pluginAssociations[i] {
+			if path == installationPath {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
 // getCatalogCacheDir returns the local directory in which tanzu state is stored.
 func getCatalogCacheDir() (path string) {
 	// NOTE: TEST_CUSTOM_CATALOG_CACHE_DIR is only for test purpose
@@ -291,6 +315,17 @@ func CleanCatalogCache() error {
 	return nil
 }
 
+// GetCatalogCacheModTime returns the last time the catalog was saved along with its size,
+// so that an update of the catalog can be detected without reading it.  The zero time
+// is returned if there is no catalog.
+func GetCatalogCacheModTime() (time.Time, int64) {
+	info, err := os.Stat(getCatalogCachePath())
+	if err != nil {
+		return time.Time{}, 0
+	}
+	return info.ModTime(), info.Size()
+}
+
 // getCatalogCachePath gets the catalog cache path
 func getCatalogCachePath() string {
 	return filepath.Join(getCatalogCacheDir(), catalogCacheFileName)
@@ -198,3 +198,62 @@ func Test_ContextCatalog_With_Context(t *testing.T) {
 	pd, exists = cc3.Get("fakeplugin1")
 	assert.False(exists)
 }
+
+func Test_IsInstallationPathRegistered(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := os.MkdirTemp("", "test-catalog")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	common.DefaultCacheDir = dir
+
+	registered, err := IsInstallationPathRegistered("/path/to/plugin/fakeplugin1")
+	assert.Nil(err)
+	assert.False(registered)
+
+	cc, err := NewContextCatalogUpdater("inactive-context")
+	assert.Nil(err)
+	pd := cli.PluginInfo{
+		Name:             "fakeplugin1",
+		InstallationPath: "/path/to/plugin/fakeplugin1",
+		Version:          "1.0.0",
+	}
+	assert.Nil(cc.Upsert(&pd))
+	cc.Unlock()
+
+	registered, err = IsInstallationPathRegistered("/path/to/plugin/fakeplugin1")
+	assert.Nil(err)
+	assert.True(registered)
+
+	// A deleted plugin remains in the index of the catalog but is no longer registered
+	cc, err = NewContextCatalogUpdater("inactive-context")
+	assert.Nil(err)
+	assert.Nil(cc.Delete(PluginNameTarget("fakeplugin1", "")))
+	cc.Unlock()
+
+	registered, err = IsInstallationPathRegistered("/path/to/plugin/fakeplugin1")
+	assert.Nil(err)
+	assert.False(registered)
+}
+
+func Test_GetCatalogCacheModTime(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := os.MkdirTemp("", "test-catalog")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	common.DefaultCacheDir = dir
+
+	modTime, size := GetCatalogCacheModTime()
+	assert.True(modTime.IsZero())
+	assert.Equal(int64(0), size)
+
+	cc, err := NewContextCatalogUpdater("")
+	assert.Nil(err)
+	assert.Nil(cc.Upsert(&cli.PluginInfo{Name: "fakeplugin1", InstallationPath: "/path/to/plugin/fakeplugin1", Version: "1.0.0"}))
+	cc.Unlock()
+
+	modTime, size = GetCatalogCacheModTime()
+	assert.False(modTime.IsZero())
+	assert.Greater(size, int64(0))
+}
@@ -33,6 +33,26 @@ func SetArch(a Arch) {
 	GOARCH = a.Arch()
 }
 
+// ParsePlatform returns the arch of a platform specified in the "<os>/<arch>"
+// format, e.g., "linux/arm64".  Only the OS/ARCH combinations for which a
+// plugin can be built are accepted.
+func ParsePlatform(platform string) (Arch, error) {
+	ele := strings.Split(platform, "/")
+	if len(ele) != 2 || ele[0] == "" || ele[1] == "" {
+		return "", fmt.Errorf("invalid platform %q, the platform must be specified as <os>/<arch>, e.g., linux/arm64", platform)
+	}
+
+	a := Arch(fmt.Sprintf("%s_%s", ele[0], ele[1]))
+	var supported []string
+	for _, known := range append([]Arch{Linux386, Win386}, AllOSArch...) {
+		if a == known {
+			return a, nil
+		}
+		supported = append(supported, fmt.Sprintf("%s/%s", known.OS(), known.Arch()))
+	}
+	return "", fmt.Errorf("unsupported platform %q, the supported platforms are: %s", platform, strings.Join(supported, ", "))
+}
+
 // IsWindows tells if an arch is windows.
 func (a Arch) IsWindows() bool {
 	if a == Win386 || a == WinAMD64 {
@@ -0,0 +1,37 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlatform(t *testing.T) {
+	for _, test := range []struct {
+		platform string
+		arch     Arch
+		err      string
+	}{
+		{platform: "linux/arm64", arch: LinuxARM64},
+		{platform: "darwin/amd64", arch: DarwinAMD64},
+		{platform: "windows/amd64", arch: WinAMD64},
+		{platform: "linux", err: `invalid platform "linux"`},
+		{platform: "linux/arm64/v8", err: `invalid platform "linux/arm64/v8"`},
+		{platform: "/arm64", err: `invalid platform "/arm64"`},
+		{platform: "linux/sparc", err: `unsupported platform "linux/sparc"`},
+		{platform: "windows/arm64", err: `unsupported platform "windows/arm64"`},
+	} {
+		t.Run(test.platform, func(t *testing.T) {
+			arch, err := ParsePlatform(test.platform)
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.arch, arch)
+		})
+	}
+}
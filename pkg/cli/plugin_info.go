@@ -68,6 +68,12 @@ type PluginInfo struct {
 	// Target specifies the target of the plugin
 	Target configtypes.Target `json:"target" yaml:"target"`
 
+	// Vendor is the name of the vendor of the plugin, as found in the discovery
+	Vendor string `json:"vendor,omitempty" yaml:"vendor,omitempty"`
+
+	// Publisher is the name of the publisher of the plugin, as found in the discovery
+	Publisher string `json:"publisher,omitempty" yaml:"publisher,omitempty"`
+
 	// PostInstallHook is function to be run post install of a plugin.
 	PostInstallHook plugin.Hook `json:"-" yaml:"-"`
 
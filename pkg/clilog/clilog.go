@@ -0,0 +1,60 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package clilog writes the informational and success messages of the CLI, which are
+// not written when the CLI is quiet.  The warnings and errors are always written and
+// are logged with the log package of the plugin runtime directly.
+package clilog
+
+import (
+	"sync/atomic"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+// quiet is true when the informational and success messages are not written
+var quiet atomic.Bool
+
+// SetQuiet sets whether the informational and success messages are written
+func SetQuiet(q bool) {
+	quiet.Store(q)
+}
+
+// IsQuiet returns true if the informational and success messages are not written
+func IsQuiet() bool {
+	return quiet.Load()
+}
+
+// logger returns the logger of the messages, which reports the caller of the
+// functions of this package instead of the functions themselves
+func logger() log.LoggerImpl {
+	return log.V(0).WithCallDepth(1)
+}
+
+// Info logs an informational message with the given key/value pairs as context
+func Info(msg string, kvs ...interface{}) {
+	if !IsQuiet() {
+		logger().Info(msg, kvs...)
+	}
+}
+
+// Infof logs an informational message with the given message format and arguments
+func Infof(format string, args ...interface{}) {
+	if !IsQuiet() {
+		logger().Infof(format, args...)
+	}
+}
+
+// Success logs a success message with the given key/value pairs as context
+func Success(msg string, kvs ...interface{}) {
+	if !IsQuiet() {
+		logger().Success(msg, kvs...)
+	}
+}
+
+// Successf logs a success message with the given message format and arguments
+func Successf(format string, args ...interface{}) {
+	if !IsQuiet() {
+		logger().Successf(format, args...)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package clilog
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+func TestQuiet(t *testing.T) {
+	assert := assert.New(t)
+
+	b := bytes.NewBufferString("")
+	log.SetStderr(b)
+	defer log.SetStderr(os.Stderr)
+	defer SetQuiet(false)
+
+	SetQuiet(true)
+	assert.True(IsQuiet())
+	Info("some information")
+	Infof("some %s", "information")
+	Success("some success")
+	Successf("some %s", "success")
+	log.Warning("some warning")
+	assert.Equal("[!] some warning\n", b.String())
+
+	b.Reset()
+	SetQuiet(false)
+	assert.False(IsQuiet())
+	Info("some information")
+	Successf("some %s", "success")
+	assert.Equal("[i] some information\n[ok] some success\n", b.String())
+}
@@ -16,9 +16,9 @@ import (
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/component"
 	configlib "github.com/vmware-tanzu/tanzu-plugin-runtime/config"
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
-	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
 )
 
@@ -177,7 +177,7 @@ func newAddCertCmd() *cobra.Command {
 				return err
 			}
 
-			log.Successf("successfully added certificate data for host %s", host)
+			clilog.Successf("successfully added certificate data for host %s", host)
 			return nil
 		},
 	}
@@ -231,7 +231,7 @@ func newUpdateCertCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			log.Successf("updated certificate data for host %s", aHost)
+			clilog.Successf("updated certificate data for host %s", aHost)
 			return nil
 		},
 	}
@@ -257,7 +257,7 @@ func newDeleteCertCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			log.Successf("deleted certificate data for host %s", aHost)
+			clilog.Successf("deleted certificate data for host %s", aHost)
 			return nil
 		},
 	}
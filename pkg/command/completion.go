@@ -4,15 +4,20 @@
 package command
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/lithammer/dedent"
 	"github.com/spf13/cobra"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
 
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/plugin"
 )
 
@@ -130,3 +135,76 @@ func runCompletion(out io.Writer, cmd *cobra.Command, args []string) error {
 		return errors.New("unrecognized shell type specified")
 	}
 }
+
+// getInstalledCompletionScripts returns the completion scripts saved, by shell, in the
+// files where the examples of the completion command install them for all new sessions.
+// The zsh script is not included as its location depends on the configuration of zsh.
+func getInstalledCompletionScripts() map[string]string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	scripts := make(map[string]string)
+	for shell, path := range map[string]string{
+		"bash": filepath.Join(home, ".config", "tanzu", "completion.bash.inc"),
+		"fish": filepath.Join(home, ".config", "fish", "completions", "tanzu.fish"),
+	} {
+		if _, err := os.Stat(path); err == nil {
+			scripts[shell] = path
+		}
+	}
+	return scripts
+}
+
+// refreshCompletionScripts generates again the installed completion scripts so that
+// they complete the commands of the installed plugins as the CLI now provides them.
+// Nothing is done if no completion script is installed.  Each script is written
+// next to its destination and then renamed so that a shell never reads a partial one.
+func refreshCompletionScripts(cmd *cobra.Command) error {
+	errorList := make([]error, 0)
+	for shell, path := range getInstalledCompletionScripts() {
+		var script bytes.Buffer
+		if err := runCompletion(&script, cmd, []string{shell}); err != nil {
+			errorList = append(errorList, err)
+			continue
+		}
+		if err := os.WriteFile(path+".tmp", script.Bytes(), 0644); err != nil {
+			_ = os.Remove(path + ".tmp")
+			errorList = append(errorList, err)
+			continue
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			_ = os.Remove(path + ".tmp")
+			errorList = append(errorList, err)
+			continue
+		}
+		log.V(4).Infof("Refreshed the %s completion script %q", shell, path)
+	}
+	return kerrors.NewAggregate(errorList)
+}
+
+// refreshCompletionOnPluginChanges watches the plugins installed, upgraded or uninstalled
+// by a command.  The returned function, to call once the command is done, refreshes the
+// installed completion scripts if any plugin changed, unless --skip-completion-refresh
+// is specified.  A failure to refresh the completion scripts is only reported.
+func refreshCompletionOnPluginChanges(cmd *cobra.Command) (done func()) {
+	if skipCompletionRefresh {
+		return func() {}
+	}
+	changed := false
+	unsubscribe := pluginmanager.Subscribe(func(e pluginmanager.Event) {
+		switch e.Type {
+		case pluginmanager.EventPluginInstalled, pluginmanager.EventPluginUpgraded, pluginmanager.EventPluginDeleted:
+			changed = true
+		}
+	})
+	return func() {
+		unsubscribe()
+		if !changed {
+			return
+		}
+		if err := refreshCompletionScripts(cmd.Root()); err != nil {
+			log.Warningf("Unable to refresh the shell completion scripts: %v", err)
+		}
+	}
+}
@@ -19,9 +19,12 @@ const (
 	compTMCContextType   = "tmc\tContext for a Tanzu Mission Control endpoint"
 
 	// Completion strings for the values of the --output flag
-	compTableOutput = "table\tOutput results in human-readable format"
-	compJSONOutput  = "json\tOutput results in JSON format"
-	compYAMLOutput  = "yaml\tOutput results in YAML format"
+	compTableOutput      = "table\tOutput results in human-readable format"
+	compJSONOutput       = "json\tOutput results in JSON format"
+	compYAMLOutput       = "yaml\tOutput results in YAML format"
+	compWideOutput       = "wide\tOutput results in human-readable format with additional columns"
+	compNameOutput       = "name\tOutput only the names of the plugins, one per line"
+	compNameTargetOutput = "name:target\tOutput only the names and targets of the plugins, one per line"
 )
 
 // TODO(khouzam): move this to tanzu-plugin-runtime to be usable by plugins
@@ -29,6 +32,18 @@ func completionGetOutputFormats(_ *cobra.Command, _ []string, _ string) ([]strin
 	return []string{compTableOutput, compJSONOutput, compYAMLOutput}, cobra.ShellCompDirectiveNoFileComp
 }
 
+func completionGetListOutputFormats(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return []string{compTableOutput, compWideOutput, compJSONOutput, compYAMLOutput, compNameOutput, compNameTargetOutput}, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completionGetObjectOutputFormats(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return []string{compJSONOutput, compYAMLOutput}, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completionGetPluginSortKeys(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return pluginSortKeys, cobra.ShellCompDirectiveNoFileComp
+}
+
 // noMoreCompletions can be used to disable file completion for commands that should
 // not trigger file completions.  It also provides some ActiveHelp to indicate no more
 // arguments are accepted
@@ -6,6 +6,7 @@ package command
 import (
 	"bytes"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -171,3 +172,31 @@ func TestCompletionCompletion(t *testing.T) {
 
 	os.Unsetenv("TANZU_ACTIVE_HELP")
 }
+
+func Test_refreshCompletionScripts(t *testing.T) {
+	assert := assert.New(t)
+
+	home, err := os.MkdirTemp("", "home")
+	assert.Nil(err)
+	defer os.RemoveAll(home)
+	origHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", origHome)
+
+	// Nothing is done when no completion script is installed
+	assert.Empty(getInstalledCompletionScripts())
+	assert.Nil(refreshCompletionScripts(completionCmd.Root()))
+	assert.NoDirExists(filepath.Join(home, ".config"))
+
+	bashScript := filepath.Join(home, ".config", "tanzu", "completion.bash.inc")
+	assert.Nil(os.MkdirAll(filepath.Dir(bashScript), 0755))
+	assert.Nil(os.WriteFile(bashScript, []byte("outdated"), 0644))
+	assert.Equal(map[string]string{"bash": bashScript}, getInstalledCompletionScripts())
+
+	assert.Nil(refreshCompletionScripts(completionCmd.Root()))
+	content, err := os.ReadFile(bashScript)
+	assert.Nil(err)
+	assert.Contains(string(content), "# bash completion V2")
+	assert.NoFileExists(bashScript + ".tmp")
+	assert.NoFileExists(filepath.Join(home, ".config", "fish", "completions", "tanzu.fish"))
+}
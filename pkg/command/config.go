@@ -18,9 +18,9 @@ import (
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/plugin"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
-	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
 
 // ConfigLiterals used with set/unset commands
@@ -254,7 +254,7 @@ var initConfigCmd = &cobra.Command{
 			return err
 		}
 
-		log.Success("successfully initialized the config")
+		clilog.Success("successfully initialized the config")
 		return nil
 	},
 }
@@ -308,7 +308,7 @@ var deleteServersCmd = &cobra.Command{
 		}
 
 		if isAborted == nil {
-			log.Infof("Deleting entry for cluster %s", args[0])
+			clilog.Infof("Deleting entry for cluster %s", args[0])
 			serverExists, err := configlib.ServerExists(args[0])
 			if err != nil {
 				return err
@@ -27,7 +27,6 @@ import (
 	clientauthv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
 	"k8s.io/client-go/tools/clientcmd"
 
-	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/component"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/config"
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
@@ -40,6 +39,7 @@ import (
 	kubecfg "github.com/vmware-tanzu/tanzu-cli/pkg/auth/utils/kubeconfig"
 	wcpauth "github.com/vmware-tanzu/tanzu-cli/pkg/auth/wcp"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
@@ -268,8 +268,8 @@ func createCtx(cmd *cobra.Command, args []string) (err error) {
 
 // syncContextPlugins syncs the plugins for the given context type
 // if listPlugins is true, it will list the plugins that will be installed for the given context type
-func syncContextPlugins(cmd *cobra.Command, contextType configtypes.ContextType, ctxName string, listPlugins bool) error {
-	plugins, err := pluginmanager.DiscoverPluginsForContextType(contextType)
+func syncContextPlugins(cmd *cobra.Command, contextType configtypes.ContextType, ctxName string, listPlugins bool, options ...pluginmanager.PluginManagerOptions) error {
+	plugins, err := pluginmanager.DiscoverPluginsForContextType(contextType, options...)
 	errList := make([]error, 0)
 	if err != nil {
 		errList = append(errList, err)
@@ -282,17 +282,17 @@ func syncContextPlugins(cmd *cobra.Command, contextType configtypes.ContextType,
 	if listPlugins {
 		pluginsNeedstoBeInstalled := 0
 		for idx := range plugins {
-			if plugins[idx].Status == common.PluginStatusNotInstalled || plugins[idx].Status == common.PluginStatusUpdateAvailable {
+			if !plugins[idx].IsUpToDate() {
 				pluginsNeedstoBeInstalled++
 			}
 		}
 		if pluginsNeedstoBeInstalled > 0 {
-			log.Infof("The following plugins will be installed for context '%s' of contextType '%s': ", ctxName, contextType)
+			clilog.Infof("The following plugins will be installed for context '%s' of contextType '%s': ", ctxName, contextType)
 			displayUninstalledPluginsContentAsTable(plugins, cmd.ErrOrStderr())
 		}
 	}
 
-	err = pluginmanager.InstallDiscoveredContextPlugins(plugins)
+	err = pluginmanager.InstallDiscoveredContextPlugins(plugins, options...)
 	if err != nil {
 		errList = append(errList, err)
 	}
@@ -307,7 +307,7 @@ func syncContextPlugins(cmd *cobra.Command, contextType configtypes.ContextType,
 func displayUninstalledPluginsContentAsTable(plugins []discovery.Discovered, writer io.Writer) {
 	outputUninstalledPlugins := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "Name", "Target", "Version")
 	for i := range plugins {
-		if plugins[i].Status == common.PluginStatusNotInstalled || plugins[i].Status == common.PluginStatusUpdateAvailable {
+		if !plugins[i].IsUpToDate() {
 			outputUninstalledPlugins.AddRow(plugins[i].Name, plugins[i].Target, plugins[i].RecommendedVersion)
 		}
 	}
@@ -586,7 +586,7 @@ func globalLogin(c *configtypes.Context) (err error) {
 
 	// format
 	fmt.Println()
-	log.Success("successfully created a TMC context")
+	clilog.Success("successfully created a TMC context")
 	return nil
 }
 
@@ -618,7 +618,7 @@ func globalTanzuLogin(c *configtypes.Context) error {
 
 	// format
 	fmt.Println()
-	log.Success("successfully created a tanzu context")
+	clilog.Success("successfully created a tanzu context")
 	return nil
 }
 
@@ -630,7 +630,7 @@ func doCSPAuthAndUpdateContext(c *configtypes.Context, endpointType string) (cla
 		issuer = csp.StgIssuer
 	}
 	if apiTokenExists {
-		log.Info("API token env var is set")
+		clilog.Info("API token env var is set")
 	} else {
 		apiTokenValue, err = promptAPIToken(endpointType)
 		if err != nil {
@@ -777,7 +777,7 @@ func promptAPIToken(endpointType string) (apiToken string, err error) {
 
 	// format
 	fmt.Println()
-	log.Infof(msg)
+	clilog.Infof(msg)
 
 	promptOpts := getPromptOpts()
 
@@ -814,7 +814,7 @@ func k8sLogin(c *configtypes.Context) error {
 			return errors.Wrap(err, "unable to update current kube context")
 		}
 
-		log.Successf("successfully created a kubernetes context using the kubeconfig %s", c.ClusterOpts.Path)
+		clilog.Successf("successfully created a kubernetes context using the kubeconfig %s", c.ClusterOpts.Path)
 		return nil
 	}
 
@@ -1016,8 +1016,8 @@ func deleteCtx(_ *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	installed, _, _, _ := getInstalledAndMissingContextPlugins() //nolint:dogsled
-	log.Infof("Deleting entry for context '%s'", name)
+	installed, _, _, _ := getInstalledAndMissingContextPlugins("") //nolint:dogsled
+	clilog.Infof("Deleting entry for context '%s'", name)
 	err = config.RemoveContext(name)
 	if err != nil {
 		return err
@@ -1033,7 +1033,7 @@ func deleteKubeconfigContext(ctx *configtypes.Context) {
 	// (Since the kubernetes context type can have kube context provided by the user, it may not be
 	// desired outcome for user if CLI deletes/cleanup kubeconfig provided by the user.)
 	if ctx.ContextType == configtypes.ContextTypeTanzu || isPinnipedEndpointContext(ctx) {
-		log.Infof("Deleting kubeconfig context '%s' from the file '%s'", ctx.ClusterOpts.Context, ctx.ClusterOpts.Path)
+		clilog.Infof("Deleting kubeconfig context '%s' from the file '%s'", ctx.ClusterOpts.Context, ctx.ClusterOpts.Path)
 		if err := kubecfg.DeleteContextFromKubeConfig(ctx.ClusterOpts.Path, ctx.ClusterOpts.Context); err != nil {
 			log.Warningf("Failed to delete the kubeconfig context '%s' from the file '%s'", ctx.ClusterOpts.Context, ctx.ClusterOpts.Path)
 		}
@@ -1090,7 +1090,7 @@ func useCtx(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	log.Infof("Successfully activated context '%s'", ctxName)
+	clilog.Infof("Successfully activated context '%s'", ctxName)
 
 	// Sync all required plugins
 	_ = syncContextPlugins(cmd, ctx.ContextType, ctxName, true)
@@ -1137,7 +1137,7 @@ func unsetCtx(_ *cobra.Command, args []string) error {
 
 func unsetGivenContext(name string, contextType configtypes.ContextType) error {
 	var unset bool
-	installed, _, _, _ := getInstalledAndMissingContextPlugins() //nolint:dogsled
+	installed, _, _, _ := getInstalledAndMissingContextPlugins("") //nolint:dogsled
 	currentCtxMap, err := config.GetAllActiveContextsMap()
 	if contextType != "" && name != "" {
 		ctx, ok := currentCtxMap[contextType]
@@ -15,6 +15,7 @@ import (
 
 	tkgauth "github.com/vmware-tanzu/tanzu-cli/pkg/auth/tkg"
 	wcpauth "github.com/vmware-tanzu/tanzu-cli/pkg/auth/wcp"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
@@ -125,7 +126,7 @@ func (tkfo *TKGKubeconfigFetcherOptions) getKubeconfigForPriorTKGVersion() (stri
 	}
 
 	if isVSphereSupervisor {
-		log.Info("Detected a vSphere Supervisor being used")
+		clilog.Info("Detected a vSphere Supervisor being used")
 		kubeCfg, kubeCtx, err = vSphereSupervisorLogin(endpoint)
 		if err != nil {
 			err := fmt.Errorf("error login into the vSphere Supervisor: %v", err)
@@ -4,15 +4,25 @@
 package command
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"os"
 	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
 
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper/sigverifier"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
 
 	"github.com/spf13/cobra"
@@ -20,14 +30,26 @@ import (
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/component"
 	configlib "github.com/vmware-tanzu/tanzu-plugin-runtime/config"
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
-
-	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
 
 var (
-	uri string
+	uri               string
+	priority          int
+	keepCustomSources bool
+	resetUnattended   bool
+	exportFile        string
+	importReplace     bool
+	importForce       bool
+
+	loginUsername         string
+	loginPasswordStdin    bool
+	loginCredentialHelper string
 )
 
+// validateRegistryCredentials checks credentials against a registry;
+// it is a variable so that tests can replace it
+var validateRegistryCredentials = registry.ValidateCredentials
+
 func newDiscoverySourceCmd() *cobra.Command {
 	var discoverySourceCmd = &cobra.Command{
 		Use:   "source",
@@ -39,8 +61,14 @@ func newDiscoverySourceCmd() *cobra.Command {
 	discoverySourceCmd.AddCommand(
 		newListDiscoverySourceCmd(),
 		newUpdateDiscoverySourceCmd(),
+		newRenameDiscoverySourceCmd(),
 		newDeleteDiscoverySourceCmd(),
 		newInitDiscoverySourceCmd(),
+		newResetDiscoverySourceCmd(),
+		newLoginDiscoverySourceCmd(),
+		newLogoutDiscoverySourceCmd(),
+		newExportDiscoverySourceCmd(),
+		newImportDiscoverySourceCmd(),
 	)
 
 	return discoverySourceCmd
@@ -52,17 +80,18 @@ func newListDiscoverySourceCmd() *cobra.Command {
 		Short:             "List available discovery sources",
 		ValidArgsFunction: noMoreCompletions,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			output := component.NewOutputWriterWithOptions(cmd.OutOrStdout(), outputFormat, []component.OutputWriterOption{}, "name", "image")
+			output := component.NewOutputWriterWithOptions(cmd.OutOrStdout(), outputFormat, []component.OutputWriterOption{}, "name", "image", "priority")
 			discoverySources, err := configlib.GetCLIDiscoverySources()
 			for _, ds := range discoverySources {
 				if ds.OCI != nil {
-					output.AddRow(ds.OCI.Name, ds.OCI.Image)
+					output.AddRow(ds.OCI.Name, ds.OCI.Image, config.GetPluginDiscoveryPriority(ds.OCI.Name))
 				}
 			}
+			// The test discoveries are always searched after the configured ones
 			testPluginSources := pluginmanager.GetAdditionalTestPluginDiscoveries()
 			for _, ds := range testPluginSources {
 				if ds.OCI != nil {
-					output.AddRow(ds.OCI.Name+" (test only)", ds.OCI.Image)
+					output.AddRow(ds.OCI.Name+" (test only)", ds.OCI.Image, "")
 				}
 			}
 			output.Render()
@@ -78,48 +107,100 @@ func newListDiscoverySourceCmd() *cobra.Command {
 
 func newUpdateDiscoverySourceCmd() *cobra.Command {
 	var updateDiscoverySourceCmd = &cobra.Command{
-		Use:   "update SOURCE_NAME --uri <URI>",
+		Use:   "update SOURCE_NAME [--uri <URI>] [--priority <PRIORITY>]",
 		Short: "Update a discovery source configuration",
-		// We already include the only flag in the use text,
+		Long: `Update a discovery source configuration.
+When a plugin is provided by multiple discovery sources, the versions and the recommended version
+of the discovery source with the highest priority are preferred.  Discovery sources with the same
+priority, which is 0 by default, are searched in the order in which they are configured.`,
+		// We already include the flags in the use text,
 		// we therefore don't show '[flags]' in the usage text.
 		DisableFlagsInUseLine: true,
 		Example: `
     # Update the discovery source for an air-gapped scenario. The URI must be an OCI image.
-    tanzu plugin source update default --uri registry.example.com/tanzu/plugin-inventory:latest`,
+    tanzu plugin source update default --uri registry.example.com/tanzu/plugin-inventory:latest
+
+    # Use an OCI image layout directory (or an archive of it) on disk, without any registry
+    tanzu plugin source update default --uri oci-layout:/path/to/plugin-inventory
+    tanzu plugin source update default --uri oci-archive:/path/to/plugin-inventory.tar
+
+    # Prefer the plugins of the discovery source named mirror over the ones of the other sources
+    tanzu plugin source update mirror --priority 10`,
 		Args:              cobra.ExactArgs(1),
 		ValidArgsFunction: completeUpdateDiscoverySource,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			discoveryName := args[0]
 
+			if !cmd.Flags().Changed("uri") && !cmd.Flags().Changed("priority") {
+				return errors.New("at least one of the --uri or --priority flags must be specified")
+			}
+
 			discoverySource, _ := configlib.GetCLIDiscoverySource(discoveryName)
 			if discoverySource == nil {
 				return fmt.Errorf("discovery %q does not exist", discoveryName)
 			}
 
-			newDiscoverySource, err := createDiscoverySource(discoveryName, uri)
-			if err != nil {
-				return err
+			if cmd.Flags().Changed("uri") {
+				newDiscoverySource, err := createDiscoverySource(discoveryName, uri)
+				if err != nil {
+					return err
+				}
+
+				err = configlib.SetCLIDiscoverySource(newDiscoverySource)
+				if err != nil {
+					return err
+				}
 			}
 
-			err = configlib.SetCLIDiscoverySource(newDiscoverySource)
-			if err != nil {
-				return err
+			if cmd.Flags().Changed("priority") {
+				if err := config.SetPluginDiscoveryPriority(discoveryName, priority); err != nil {
+					return err
+				}
 			}
 
-			log.Successf("updated discovery source %s", discoveryName)
+			clilog.Successf("updated discovery source %s", discoveryName)
 			return nil
 		},
 	}
 
-	updateDiscoverySourceCmd.Flags().StringVarP(&uri, "uri", "u", "", "URI for discovery source. The URI must be of an OCI image")
-	_ = updateDiscoverySourceCmd.MarkFlagRequired("uri")
+	updateDiscoverySourceCmd.Flags().StringVarP(&uri, "uri", "u", "", "URI for discovery source. The URI must be of an OCI image, or of an OCI image layout directory or archive prefixed with 'oci-layout:' or 'oci-archive:'")
 	utils.PanicOnErr(updateDiscoverySourceCmd.RegisterFlagCompletionFunc("uri", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return cobra.AppendActiveHelp(nil, "Please enter the uri of the OCI image for plugin discovery"), cobra.ShellCompDirectiveNoFileComp
 	}))
+	updateDiscoverySourceCmd.Flags().IntVar(&priority, "priority", 0, "priority of the discovery source when a plugin is provided by multiple sources; higher wins")
+	utils.PanicOnErr(updateDiscoverySourceCmd.RegisterFlagCompletionFunc("priority", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cobra.AppendActiveHelp(nil, "Please enter the priority of the discovery source as an integer"), cobra.ShellCompDirectiveNoFileComp
+	}))
 
 	return updateDiscoverySourceCmd
 }
 
+func newRenameDiscoverySourceCmd() *cobra.Command {
+	var renameDiscoverySourceCmd = &cobra.Command{
+		Use:   "rename SOURCE_NAME NEW_NAME",
+		Short: "Rename a discovery source",
+		Long: `Rename a discovery source.
+The discovery source keeps its image, its priority, its credentials and its cached plugin
+inventory, which is therefore not downloaded again.  The plugins installed from the discovery
+source, as well as the discovery profiles referring to it, use the new name.`,
+		// There are no flags
+		DisableFlagsInUseLine: true,
+		Example: `
+    # Rename the discovery source named mirror
+    tanzu plugin source rename mirror corp-mirror`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeRenameDiscoverySource,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := pluginmanager.RenameDiscoverySource(args[0], args[1]); err != nil {
+				return err
+			}
+			clilog.Successf("renamed discovery source %s to %s", args[0], args[1])
+			return nil
+		},
+	}
+	return renameDiscoverySourceCmd
+}
+
 func newDeleteDiscoverySourceCmd() *cobra.Command {
 	var deleteDiscoverySourceCmd = &cobra.Command{
 		Use:   "delete SOURCE_NAME",
@@ -144,7 +225,7 @@ func newDeleteDiscoverySourceCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			log.Successf("deleted discovery source %s", discoveryName)
+			clilog.Successf("deleted discovery source %s", discoveryName)
 			return nil
 		},
 	}
@@ -165,13 +246,524 @@ func newInitDiscoverySourceCmd() *cobra.Command {
 				return err
 			}
 
-			log.Successf("successfully initialized discovery source")
+			clilog.Successf("successfully initialized discovery source")
 			return nil
 		},
 	}
 	return initDiscoverySourceCmd
 }
 
+func newResetDiscoverySourceCmd() *cobra.Command {
+	var resetDiscoverySourceCmd = &cobra.Command{
+		Use:   "reset",
+		Short: "Restore the discovery sources the CLI ships with",
+		Long: `Restore the discovery sources the CLI ships with.
+The default discovery source is added back, or pointed back to its default image,
+and all other discovery sources are removed unless --keep-custom is specified.
+The changes are printed and must be confirmed unless --yes is specified.`,
+		Example: `
+    # Restore the default discovery source and remove all other discovery sources
+    tanzu plugin source reset
+
+    # Restore the default discovery source but keep the other discovery sources
+    tanzu plugin source reset --keep-custom
+
+    # Restore the default discovery sources without asking for confirmation
+    tanzu plugin source reset --yes`,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: noMoreCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			discoverySources, err := configlib.GetCLIDiscoverySources()
+			if err != nil {
+				return err
+			}
+			defaultDiscovery := config.GetDefaultCentralDiscovery()
+			toDelete, changes := getDiscoverySourcesResetChanges(discoverySources, defaultDiscovery, keepCustomSources)
+			if len(changes) == 0 {
+				clilog.Info("the discovery sources are already set to their defaults")
+				return nil
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "The following changes will be made to the discovery sources:")
+			for _, change := range changes {
+				fmt.Fprintf(cmd.OutOrStdout(), "  - %s\n", change)
+			}
+			if !resetUnattended {
+				if err := component.AskForConfirmation("Are you sure you want to reset the discovery sources?"); err != nil {
+					return err
+				}
+			}
+
+			for _, name := range toDelete {
+				if err := configlib.DeleteCLIDiscoverySource(name); err != nil {
+					return err
+				}
+			}
+			if err := configlib.SetCLIDiscoverySource(defaultDiscovery); err != nil {
+				return err
+			}
+			clilog.Successf("successfully reset the discovery sources")
+			return nil
+		},
+	}
+
+	resetDiscoverySourceCmd.Flags().BoolVar(&keepCustomSources, "keep-custom", false, "keep the discovery sources other than the default one")
+	resetDiscoverySourceCmd.Flags().BoolVarP(&resetUnattended, "yes", "y", false, "reset the discovery sources without asking for confirmation")
+
+	return resetDiscoverySourceCmd
+}
+
+func newLoginDiscoverySourceCmd() *cobra.Command {
+	var loginDiscoverySourceCmd = &cobra.Command{
+		Use:   "login REGISTRY",
+		Short: "Log in to the registry of discovery sources",
+		Long: `Log in to the registry of discovery sources.
+The credentials are validated against the registry and stored by a credential helper,
+which uses the keychain of the operating system by default. They are never stored in the
+configuration of the CLI. The discovery sources without credentials of their own use them.`,
+		Example: `
+    # Log in to a registry, prompting for the username and the password
+    tanzu plugin source login registry.example.com
+
+    # Log in to a registry with a password read from the standard input
+    cat password.txt | tanzu plugin source login registry.example.com --username myuser --password-stdin
+
+    # Log in to a registry, storing the credentials with the "pass" credential helper
+    tanzu plugin source login registry.example.com --credential-helper pass`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeRegistryHost,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registryHost := args[0]
+			if loginPasswordStdin && loginUsername == "" {
+				return errors.New("the --username flag must be specified with --password-stdin")
+			}
+
+			username := loginUsername
+			if username == "" {
+				if err := component.Prompt(&component.PromptConfig{Message: "Username"}, &username, getPromptOpts()...); err != nil {
+					return err
+				}
+			}
+			var password string
+			if loginPasswordStdin {
+				content, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return errors.Wrap(err, "unable to read the password from the standard input")
+				}
+				password = strings.TrimRight(string(content), "\r\n")
+			} else if err := component.Prompt(&component.PromptConfig{Message: "Password", Sensitive: true}, &password, getPromptOpts()...); err != nil {
+				return err
+			}
+			username = strings.TrimSpace(username)
+			if username == "" || password == "" {
+				return errors.New("the username and the password cannot be empty")
+			}
+
+			if err := validateRegistryCredentials(registryHost, username, password); err != nil {
+				return err
+			}
+
+			helper := loginCredentialHelper
+			if helper == "" {
+				helper = carvelhelpers.DefaultCredentialHelper()
+			}
+			if err := carvelhelpers.StoreCredentials(helper, registryHost, username, password); err != nil {
+				return errors.Wrap(err, "unable to store the credentials, install the credential helper or select another one with --credential-helper")
+			}
+			// Only the name of the credential helper is saved in the configuration
+			if err := config.SetRegistryCredentialHelper(registryHost, helper); err != nil {
+				return err
+			}
+
+			clilog.Successf("successfully logged in to registry %q as user %q", registryHost, username)
+			return nil
+		},
+	}
+
+	loginDiscoverySourceCmd.Flags().StringVarP(&loginUsername, "username", "u", "", "username for the registry, prompted for if not specified")
+	utils.PanicOnErr(loginDiscoverySourceCmd.RegisterFlagCompletionFunc("username", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cobra.AppendActiveHelp(nil, "Please enter the username for the registry"), cobra.ShellCompDirectiveNoFileComp
+	}))
+	loginDiscoverySourceCmd.Flags().BoolVar(&loginPasswordStdin, "password-stdin", false, "read the password from the standard input instead of prompting for it")
+	loginDiscoverySourceCmd.Flags().StringVar(&loginCredentialHelper, "credential-helper", "", "docker credential helper storing the credentials (e.g. pass), the keychain of the operating system is used by default")
+
+	return loginDiscoverySourceCmd
+}
+
+func newLogoutDiscoverySourceCmd() *cobra.Command {
+	var logoutDiscoverySourceCmd = &cobra.Command{
+		Use:   "logout REGISTRY",
+		Short: "Log out from the registry of discovery sources",
+		Long:  "Log out from the registry of discovery sources by removing the credentials stored by 'tanzu plugin source login'.",
+		Example: `
+    # Log out from a registry
+    tanzu plugin source logout registry.example.com`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeRegistryHost,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registryHost := args[0]
+
+			helper := loginCredentialHelper
+			if helper == "" {
+				helper = config.GetRegistryCredentialHelper(registryHost)
+			}
+			if helper == "" {
+				helper = carvelhelpers.DefaultCredentialHelper()
+			}
+			// The registry is no longer logged in if the helper has no credentials for it
+			eraseErr := carvelhelpers.EraseCredentials(helper, registryHost)
+			if eraseErr != nil && !errors.Is(eraseErr, carvelhelpers.ErrCredentialsNotFound) {
+				return eraseErr
+			}
+			if err := config.SetRegistryCredentialHelper(registryHost, ""); err != nil {
+				return err
+			}
+			if eraseErr != nil {
+				return eraseErr
+			}
+
+			clilog.Successf("successfully logged out from registry %q", registryHost)
+			return nil
+		},
+	}
+
+	logoutDiscoverySourceCmd.Flags().StringVar(&loginCredentialHelper, "credential-helper", "", "docker credential helper storing the credentials, the one used by 'tanzu plugin source login' by default")
+
+	return logoutDiscoverySourceCmd
+}
+
+func newExportDiscoverySourceCmd() *cobra.Command {
+	var exportDiscoverySourceCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export the discovery sources to share them",
+		Long: `Export the discovery sources to share them.
+The name, image, priority and signature verification policy of the OCI discovery sources
+are written as YAML, which 'tanzu plugin source import' can load on another machine.`,
+		Example: `
+    # Print the discovery sources
+    tanzu plugin source export
+
+    # Write the discovery sources to a file
+    tanzu plugin source export --file discovery-sources.yaml`,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: noMoreCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			exported, err := exportDiscoverySources()
+			if err != nil {
+				return err
+			}
+			b, err := yaml.Marshal(exported)
+			if err != nil {
+				return errors.Wrap(err, "unable to serialize the discovery sources")
+			}
+			if exportFile == "" {
+				_, err = cmd.OutOrStdout().Write(b)
+				return err
+			}
+			if err := os.WriteFile(exportFile, b, 0644); err != nil {
+				return errors.Wrapf(err, "unable to write the discovery sources to %q", exportFile)
+			}
+			clilog.Successf("exported %d discovery sources to %s", len(exported.Sources), exportFile)
+			return nil
+		},
+	}
+
+	// Shell completion for this flag is the default behavior of doing file completion
+	exportDiscoverySourceCmd.Flags().StringVar(&exportFile, "file", "", "write the discovery sources to the specified file instead of the standard output")
+
+	return exportDiscoverySourceCmd
+}
+
+func newImportDiscoverySourceCmd() *cobra.Command {
+	var importDiscoverySourceCmd = &cobra.Command{
+		Use:   "import FILE",
+		Short: "Import discovery sources exported by 'tanzu plugin source export'",
+		Long: `Import discovery sources exported by 'tanzu plugin source export'.
+The imported discovery sources are added to the configured ones, or replace them if --replace
+is specified.  The changes are printed and the ones modifying or removing a configured
+discovery source, or skipping the signature verification of an image, are only made if
+--force is specified.`,
+		Example: `
+    # Add the discovery sources of a file to the configured ones
+    tanzu plugin source import discovery-sources.yaml
+
+    # Overwrite the configured discovery sources of the same name
+    tanzu plugin source import discovery-sources.yaml --force
+
+    # Configure exactly the discovery sources of a file, removing the other ones
+    tanzu plugin source import discovery-sources.yaml --replace --force`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := os.ReadFile(args[0])
+			if err != nil {
+				return errors.Wrapf(err, "unable to read the discovery sources from %q", args[0])
+			}
+			exported, err := parseExportedDiscoverySources(b)
+			if err != nil {
+				return err
+			}
+
+			discoverySources, err := configlib.GetCLIDiscoverySources()
+			if err != nil {
+				return err
+			}
+			toDelete, changes, destructive := getDiscoverySourcesImportChanges(discoverySources, exported.Sources, importReplace)
+			if len(changes) == 0 {
+				clilog.Info("the discovery sources are already configured")
+				return nil
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "The following changes will be made to the discovery sources:")
+			for _, change := range changes {
+				fmt.Fprintf(cmd.OutOrStdout(), "  - %s\n", change)
+			}
+			if destructive && !importForce {
+				return errors.New("the import modifies or removes configured discovery sources or skips the verification of signatures, use --force to make the changes")
+			}
+
+			if err := importDiscoverySources(exported.Sources, toDelete); err != nil {
+				return err
+			}
+			clilog.Successf("successfully imported the discovery sources")
+			return nil
+		},
+	}
+
+	importDiscoverySourceCmd.Flags().BoolVar(&importReplace, "replace", false, "remove the configured discovery sources that are not imported")
+	importDiscoverySourceCmd.Flags().BoolVar(&importForce, "force", false, "make the changes modifying or removing configured discovery sources or skipping the verification of signatures")
+
+	return importDiscoverySourceCmd
+}
+
+// getDiscoverySourcesResetChanges returns the names of the discovery sources to delete
+// and the description of each change needed to restore the default discovery source
+func getDiscoverySourcesResetChanges(discoverySources []configtypes.PluginDiscovery, defaultDiscovery configtypes.PluginDiscovery, keepCustom bool) (toDelete, changes []string) {
+	defaultFound := false
+	for _, ds := range discoverySources {
+		name := discovery.GetDiscoveryName(ds)
+		if name == defaultDiscovery.OCI.Name {
+			defaultFound = true
+			if ds.OCI == nil {
+				// The default discovery source is replaced by an OCI one
+				toDelete = append(toDelete, name)
+			}
+			if ds.OCI == nil || ds.OCI.Image != defaultDiscovery.OCI.Image {
+				changes = append(changes, fmt.Sprintf("reset discovery source %s from %s to %s", name, getDiscoverySourceImage(ds), defaultDiscovery.OCI.Image))
+			}
+			continue
+		}
+		if !keepCustom {
+			toDelete = append(toDelete, name)
+			changes = append(changes, fmt.Sprintf("remove discovery source %s (%s)", name, getDiscoverySourceImage(ds)))
+		}
+	}
+	if !defaultFound {
+		changes = append(changes, fmt.Sprintf("add discovery source %s (%s)", defaultDiscovery.OCI.Name, defaultDiscovery.OCI.Image))
+	}
+	return toDelete, changes
+}
+
+// getDiscoverySourceImage returns the image of an OCI discovery source or
+// the type of any other discovery source
+func getDiscoverySourceImage(ds configtypes.PluginDiscovery) string {
+	if ds.OCI != nil {
+		return ds.OCI.Image
+	}
+	return "non-OCI discovery"
+}
+
+// exportedDiscoverySources is the portable representation of the discovery
+// sources written by 'plugin source export' and read by 'plugin source import'
+type exportedDiscoverySources struct {
+	Sources []exportedDiscoverySource `json:"sources" yaml:"sources"`
+}
+
+// exportedDiscoverySource is the portable representation of an OCI discovery source
+type exportedDiscoverySource struct {
+	Name                      string `json:"name" yaml:"name"`
+	Image                     string `json:"image" yaml:"image"`
+	Priority                  int    `json:"priority,omitempty" yaml:"priority,omitempty"`
+	SkipSignatureVerification bool   `json:"skipSignatureVerification,omitempty" yaml:"skipSignatureVerification,omitempty"`
+}
+
+// exportDiscoverySources returns the configured OCI discovery sources with their
+// priority and signature verification policy.  The other types of discovery
+// sources are not exported as they are specific to a machine.
+func exportDiscoverySources() (*exportedDiscoverySources, error) {
+	discoverySources, err := configlib.GetCLIDiscoverySources()
+	if err != nil {
+		return nil, err
+	}
+
+	exported := &exportedDiscoverySources{Sources: []exportedDiscoverySource{}}
+	for _, ds := range discoverySources {
+		if ds.OCI == nil {
+			continue
+		}
+		exported.Sources = append(exported.Sources, exportedDiscoverySource{
+			Name:                      ds.OCI.Name,
+			Image:                     ds.OCI.Image,
+			Priority:                  config.GetPluginDiscoveryPriority(ds.OCI.Name),
+			SkipSignatureVerification: sigverifier.IsSignatureVerificationSkipped(ds.OCI.Image),
+		})
+	}
+	return exported, nil
+}
+
+// parseExportedDiscoverySources parses and validates exported discovery sources.
+// Every discovery source must have a unique name and a valid image.
+func parseExportedDiscoverySources(data []byte) (*exportedDiscoverySources, error) {
+	var exported exportedDiscoverySources
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&exported); err != nil {
+		return nil, errors.Wrap(err, "invalid discovery sources")
+	}
+
+	names := make(map[string]bool, len(exported.Sources))
+	for _, source := range exported.Sources {
+		if source.Name == "" {
+			return nil, errors.New("discovery source name cannot be empty")
+		}
+		if names[source.Name] {
+			return nil, errors.Errorf("discovery source %q is defined more than once", source.Name)
+		}
+		names[source.Name] = true
+
+		// An OCI image layout directory or archive on disk is checked when accessing it
+		if !registry.IsLocalImage(source.Image) {
+			if _, err := registry.ParseImageReference(source.Image); err != nil {
+				return nil, errors.Wrapf(err, "invalid image for discovery source %q", source.Name)
+			}
+		}
+	}
+	return &exported, nil
+}
+
+// getDiscoverySourcesImportChanges returns the names of the discovery sources to delete and the
+// description of each change needed to import the specified discovery sources.  destructive
+// is true if any change modifies or removes a configured discovery source, or skips the
+// signature verification of an image.
+func getDiscoverySourcesImportChanges(discoverySources []configtypes.PluginDiscovery, imported []exportedDiscoverySource, replace bool) (toDelete, changes []string, destructive bool) {
+	configured := make(map[string]configtypes.PluginDiscovery, len(discoverySources))
+	for _, ds := range discoverySources {
+		configured[discovery.GetDiscoveryName(ds)] = ds
+	}
+
+	importedNames := make(map[string]bool, len(imported))
+	for _, source := range imported {
+		importedNames[source.Name] = true
+
+		ds, found := configured[source.Name]
+		if !found {
+			changes = append(changes, fmt.Sprintf("add discovery source %s (%s)", source.Name, source.Image))
+		} else if ds.OCI == nil || ds.OCI.Image != source.Image {
+			if ds.OCI == nil {
+				// The discovery source is replaced by an OCI one
+				toDelete = append(toDelete, source.Name)
+			}
+			changes = append(changes, fmt.Sprintf("update discovery source %s from %s to %s", source.Name, getDiscoverySourceImage(ds), source.Image))
+			destructive = true
+		}
+
+		if currentPriority := config.GetPluginDiscoveryPriority(source.Name); currentPriority != source.Priority {
+			changes = append(changes, fmt.Sprintf("set the priority of discovery source %s from %d to %d", source.Name, currentPriority, source.Priority))
+			destructive = destructive || found
+		}
+		if skipped := sigverifier.IsSignatureVerificationSkipped(source.Image); skipped != source.SkipSignatureVerification {
+			if source.SkipSignatureVerification {
+				// Trusting an image without verifying its signature must be explicit,
+				// including for a new discovery source
+				changes = append(changes, fmt.Sprintf("skip the signature verification of %s", source.Image))
+				destructive = true
+			} else {
+				changes = append(changes, fmt.Sprintf("verify the signature of %s", source.Image))
+				destructive = destructive || found
+			}
+		}
+	}
+
+	if replace {
+		for _, ds := range discoverySources {
+			name := discovery.GetDiscoveryName(ds)
+			if importedNames[name] {
+				continue
+			}
+			toDelete = append(toDelete, name)
+			changes = append(changes, fmt.Sprintf("remove discovery source %s (%s)", name, getDiscoverySourceImage(ds)))
+			destructive = true
+		}
+	}
+	return toDelete, changes, destructive
+}
+
+// importDiscoverySources deletes the specified discovery sources and then configures
+// the imported ones, with their priority and signature verification policy
+func importDiscoverySources(imported []exportedDiscoverySource, toDelete []string) error {
+	importedNames := make(map[string]bool, len(imported))
+	for _, source := range imported {
+		importedNames[source.Name] = true
+	}
+	for _, name := range toDelete {
+		if err := configlib.DeleteCLIDiscoverySource(name); err != nil {
+			return err
+		}
+		// The priority of a removed discovery source is no longer needed
+		if !importedNames[name] {
+			if err := config.SetPluginDiscoveryPriority(name, 0); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, source := range imported {
+		err := configlib.SetCLIDiscoverySource(configtypes.PluginDiscovery{
+			OCI: &configtypes.OCIDiscovery{Name: source.Name, Image: source.Image},
+		})
+		if err != nil {
+			return err
+		}
+		if err := config.SetPluginDiscoveryPriority(source.Name, source.Priority); err != nil {
+			return err
+		}
+		if err := setSignatureVerificationSkipped(source.Image, source.SkipSignatureVerification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setSignatureVerificationSkipped adds or removes the image from the list of discovery
+// images whose signature is not verified, and persists the list in the configuration
+func setSignatureVerificationSkipped(image string, skip bool) error {
+	if sigverifier.IsSignatureVerificationSkipped(image) == skip {
+		return nil
+	}
+
+	envVariable := constants.PluginDiscoveryImageSignatureVerificationSkipList
+	var images []string
+	for _, i := range strings.Split(os.Getenv(envVariable), ",") {
+		if i = strings.TrimSpace(i); i != "" && i != image {
+			images = append(images, i)
+		}
+	}
+	if skip {
+		images = append(images, image)
+	}
+
+	if len(images) == 0 {
+		os.Unsetenv(envVariable)
+		if _, err := configlib.GetEnv(envVariable); err != nil {
+			// The list is not set
+			return nil
+		}
+		return configlib.DeleteEnv(envVariable)
+	}
+	value := strings.Join(images, ",")
+	os.Setenv(envVariable, value)
+	return configlib.SetEnv(envVariable, value)
+}
+
 func createDiscoverySource(dsName, uri string) (configtypes.PluginDiscovery, error) {
 	pluginDiscoverySource := configtypes.PluginDiscovery{}
 
@@ -179,6 +771,14 @@ func createDiscoverySource(dsName, uri string) (configtypes.PluginDiscovery, err
 		return pluginDiscoverySource, errors.New("discovery source name cannot be empty")
 	}
 
+	// Reject a malformed image before trying to access it.
+	// An OCI image layout directory or archive on disk is checked when accessing it.
+	if !registry.IsLocalImage(uri) {
+		if _, err := registry.ParseImageReference(uri); err != nil {
+			return pluginDiscoverySource, err
+		}
+	}
+
 	pluginDiscoverySource = configtypes.PluginDiscovery{
 		OCI: &configtypes.OCIDiscovery{
 			Name:  dsName,
@@ -202,6 +802,13 @@ func checkDiscoverySource(source configtypes.PluginDiscovery) error {
 // ====================================
 // Shell completion functions
 // ====================================
+func completeRegistryHost(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return activeHelpNoMoreArgs(nil), cobra.ShellCompDirectiveNoFileComp
+	}
+	return cobra.AppendActiveHelp(nil, "Please enter the host of the registry, e.g. registry.example.com"), cobra.ShellCompDirectiveNoFileComp
+}
+
 func completeDiscoverySources(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	if len(args) > 0 {
 		return activeHelpNoMoreArgs(nil), cobra.ShellCompDirectiveNoFileComp
@@ -220,10 +827,24 @@ func completeDiscoverySources(_ *cobra.Command, args []string, toComplete string
 	return comps, cobra.ShellCompDirectiveNoFileComp
 }
 
+func completeRenameDiscoverySource(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return completeDiscoverySources(cmd, args, toComplete)
+	case 1:
+		return cobra.AppendActiveHelp(nil, "Please enter the new name of the discovery source"), cobra.ShellCompDirectiveNoFileComp
+	}
+	return activeHelpNoMoreArgs(nil), cobra.ShellCompDirectiveNoFileComp
+}
+
 func completeUpdateDiscoverySource(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if len(args) > 0 && uri == "" {
-		// The --uri flag is required, so completion will be provided for it
-		return nil, cobra.ShellCompDirectiveNoFileComp
+	if len(args) > 0 && uri == "" && !cmd.Flags().Changed("priority") {
+		// The --uri or --priority flag is required, so completion is provided for them
+		var comps []string
+		for _, name := range []string{"uri", "priority"} {
+			comps = append(comps, fmt.Sprintf("--%s\t%s", name, cmd.Flags().Lookup(name).Usage))
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
 	}
 
 	// The user has provided enough information
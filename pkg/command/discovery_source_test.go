@@ -7,13 +7,17 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
 	configlib "github.com/vmware-tanzu/tanzu-plugin-runtime/config"
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
@@ -27,6 +31,11 @@ func Test_createDiscoverySource(t *testing.T) {
 	assert.NotNil(err)
 	assert.Equal(err.Error(), "discovery source name cannot be empty")
 
+	// With a malformed image
+	_, err = createDiscoverySource("fake-oci-discovery-name", "test.registry.com/Test-Image:v1.0.0")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), `path component "Test-Image" is not valid`)
+
 	// With an invalid image
 	pd, err := createDiscoverySource("fake-oci-discovery-name", "test.registry.com/test-image:v1.0.0")
 	assert.NotNil(err)
@@ -216,6 +225,12 @@ func Test_updateDiscoverySources(t *testing.T) {
 			expectedFailure: true,
 			expected:        "accepts 1 arg(s), received 2",
 		},
+		{
+			test:            "update without uri nor priority error",
+			args:            []string{"plugin", "source", "update", "default"},
+			expectedFailure: true,
+			expected:        "at least one of the --uri or --priority flags must be specified",
+		},
 		{
 			test:            "update invalid source",
 			args:            []string{"plugin", "source", "update", "invalid", "-u", constants.TanzuCLIDefaultCentralPluginDiscoveryImage},
@@ -223,9 +238,15 @@ func Test_updateDiscoverySources(t *testing.T) {
 			expected:        `discovery "invalid" does not exist`,
 		},
 		{
-			test:            "update invalid uri error",
+			test:            "update malformed uri error",
 			args:            []string{"plugin", "source", "update", "default", "-u", "example.com"},
 			expectedFailure: true,
+			expected:        `invalid image reference "example.com": it is missing the registry or the path`,
+		},
+		{
+			test:            "update invalid uri error",
+			args:            []string{"plugin", "source", "update", "default", "-u", "example.com/plugins/inventory:v1"},
+			expectedFailure: true,
 			expected:        "unable to fetch the inventory of discovery",
 		},
 		{
@@ -302,6 +323,133 @@ func Test_updateDiscoverySources(t *testing.T) {
 	os.Unsetenv(constants.EULAPromptAnswer)
 }
 
+func Test_updateDiscoverySourcePriority(t *testing.T) {
+	assert := assert.New(t)
+
+	configFile, _ := os.CreateTemp("", "config")
+	os.Setenv(configlib.EnvConfigKey, configFile.Name())
+	defer os.RemoveAll(configFile.Name())
+
+	configFileNG, _ := os.CreateTemp("", "config_ng")
+	os.Setenv(configlib.EnvConfigNextGenKey, configFileNG.Name())
+	defer os.RemoveAll(configFileNG.Name())
+
+	os.Setenv(constants.CEIPOptInUserPromptAnswer, "No")
+	os.Setenv(constants.EULAPromptAnswer, "Yes")
+	priorityVariable := constants.ConfigVariablePluginDiscoveryPriorityPrefix + "DEFAULT"
+	defer os.Unsetenv(priorityVariable)
+
+	err := configlib.SetCLIDiscoverySource(configtypes.PluginDiscovery{
+		OCI: &configtypes.OCIDiscovery{
+			Name:  config.DefaultStandaloneDiscoveryName,
+			Image: "test/uri",
+		}})
+	assert.Nil(err)
+
+	// Only the priority is updated, the image is kept as is
+	rootCmd, err := NewRootCmd()
+	assert.Nil(err)
+	rootCmd.SetArgs([]string{"plugin", "source", "update", "default", "--priority", "10"})
+	err = rootCmd.Execute()
+	assert.Nil(err)
+
+	assert.Equal(10, config.GetPluginDiscoveryPriority(config.DefaultStandaloneDiscoveryName))
+	value, err := configlib.GetEnv(priorityVariable)
+	assert.Nil(err)
+	assert.Equal("10", value)
+	discoverySource, err := configlib.GetCLIDiscoverySource(config.DefaultStandaloneDiscoveryName)
+	assert.Nil(err)
+	assert.Equal("test/uri", discoverySource.OCI.Image)
+
+	rootCmd, err = NewRootCmd()
+	assert.Nil(err)
+	rootCmd.SetArgs([]string{"plugin", "source", "list"})
+	b := bytes.NewBufferString("")
+	rootCmd.SetOut(b)
+	err = rootCmd.Execute()
+	assert.Nil(err)
+	assert.Contains(strings.Join(strings.Fields(b.String()), " "), "default test/uri 10")
+
+	// Resetting the priority to its default removes the setting
+	rootCmd, err = NewRootCmd()
+	assert.Nil(err)
+	rootCmd.SetArgs([]string{"plugin", "source", "update", "default", "--priority", "0"})
+	err = rootCmd.Execute()
+	assert.Nil(err)
+	assert.Equal(0, config.GetPluginDiscoveryPriority(config.DefaultStandaloneDiscoveryName))
+	_, err = configlib.GetEnv(priorityVariable)
+	assert.NotNil(err)
+
+	os.Unsetenv(configlib.EnvConfigKey)
+	os.Unsetenv(configlib.EnvConfigNextGenKey)
+	os.Unsetenv(constants.CEIPOptInUserPromptAnswer)
+	os.Unsetenv(constants.EULAPromptAnswer)
+}
+
+func Test_renameDiscoverySource(t *testing.T) {
+	assert := assert.New(t)
+
+	configFile, _ := os.CreateTemp("", "config")
+	os.Setenv(configlib.EnvConfigKey, configFile.Name())
+	defer os.RemoveAll(configFile.Name())
+
+	configFileNG, _ := os.CreateTemp("", "config_ng")
+	os.Setenv(configlib.EnvConfigNextGenKey, configFileNG.Name())
+	defer os.RemoveAll(configFileNG.Name())
+
+	cacheDir, err := os.MkdirTemp("", "cache")
+	assert.Nil(err)
+	defer os.RemoveAll(cacheDir)
+	origCacheDir := common.DefaultCacheDir
+	common.DefaultCacheDir = cacheDir
+	defer func() { common.DefaultCacheDir = origCacheDir }()
+
+	os.Setenv(constants.CEIPOptInUserPromptAnswer, "No")
+	os.Setenv(constants.EULAPromptAnswer, "Yes")
+	defer os.Unsetenv(constants.ConfigVariablePluginDiscoveryPriorityPrefix + "MIRROR")
+	defer os.Unsetenv(constants.ConfigVariablePluginDiscoveryPriorityPrefix + "CORP_MIRROR")
+
+	for _, name := range []string{"default", "mirror", "other"} {
+		err = configlib.SetCLIDiscoverySource(configtypes.PluginDiscovery{
+			OCI: &configtypes.OCIDiscovery{Name: name, Image: "test/" + name}})
+		assert.Nil(err)
+	}
+	assert.Nil(config.SetPluginDiscoveryPriority("mirror", 10))
+	mirrorCacheDir := filepath.Join(cacheDir, common.PluginInventoryDirName, "mirror")
+	assert.Nil(os.MkdirAll(mirrorCacheDir, 0755))
+	assert.Nil(os.WriteFile(filepath.Join(mirrorCacheDir, "digest.identity.hash"), nil, 0644))
+
+	for _, args := range [][]string{{"mirror"}, {"invalid", "new"}, {"mirror", "other"}, {"mirror", "mirror"}} {
+		rootCmd, err := NewRootCmd()
+		assert.Nil(err)
+		rootCmd.SetArgs(append([]string{"plugin", "source", "rename"}, args...))
+		assert.NotNil(rootCmd.Execute(), args)
+	}
+
+	rootCmd, err := NewRootCmd()
+	assert.Nil(err)
+	rootCmd.SetArgs([]string{"plugin", "source", "rename", "mirror", "corp-mirror"})
+	assert.Nil(rootCmd.Execute())
+
+	// The renamed discovery source keeps its position, its priority and its cache
+	discoverySources, err := configlib.GetCLIDiscoverySources()
+	assert.Nil(err)
+	assert.Equal(3, len(discoverySources))
+	assert.Equal("default", discoverySources[0].OCI.Name)
+	assert.Equal("corp-mirror", discoverySources[1].OCI.Name)
+	assert.Equal("test/mirror", discoverySources[1].OCI.Image)
+	assert.Equal("other", discoverySources[2].OCI.Name)
+	assert.Equal(10, config.GetPluginDiscoveryPriority("corp-mirror"))
+	assert.Equal(0, config.GetPluginDiscoveryPriority("mirror"))
+	assert.NoDirExists(mirrorCacheDir)
+	assert.FileExists(filepath.Join(cacheDir, common.PluginInventoryDirName, "corp-mirror", "digest.identity.hash"))
+
+	os.Unsetenv(configlib.EnvConfigKey)
+	os.Unsetenv(configlib.EnvConfigNextGenKey)
+	os.Unsetenv(constants.CEIPOptInUserPromptAnswer)
+	os.Unsetenv(constants.EULAPromptAnswer)
+}
+
 func Test_deleteDiscoverySource(t *testing.T) {
 	tests := []struct {
 		test            string
@@ -394,6 +542,448 @@ func Test_deleteDiscoverySource(t *testing.T) {
 	os.Unsetenv(constants.EULAPromptAnswer)
 }
 
+func Test_resetDiscoverySources(t *testing.T) {
+	tests := []struct {
+		test            string
+		args            []string
+		expected        []string
+		expectedSources []string
+		expectedFailure bool
+	}{
+		{
+			test:            "reset with extra arg error",
+			args:            []string{"plugin", "source", "reset", "extra"},
+			expectedFailure: true,
+			expected:        []string{`unknown command "extra"`},
+		},
+		{
+			test: "reset removes the custom sources",
+			args: []string{"plugin", "source", "reset", "--yes"},
+			expected: []string{
+				"reset discovery source default from test/uri to " + constants.TanzuCLIDefaultCentralPluginDiscoveryImage,
+				"remove discovery source mirror (example.com/mirror:latest)",
+				"successfully reset the discovery sources",
+			},
+			expectedSources: []string{config.DefaultStandaloneDiscoveryName},
+		},
+		{
+			test: "reset keeps the custom sources",
+			args: []string{"plugin", "source", "reset", "--keep-custom", "-y"},
+			expected: []string{
+				"reset discovery source default from test/uri to " + constants.TanzuCLIDefaultCentralPluginDiscoveryImage,
+				"successfully reset the discovery sources",
+			},
+			expectedSources: []string{config.DefaultStandaloneDiscoveryName, "mirror"},
+		},
+	}
+
+	configFile, _ := os.CreateTemp("", "config")
+	os.Setenv(configlib.EnvConfigKey, configFile.Name())
+	defer os.RemoveAll(configFile.Name())
+
+	configFileNG, _ := os.CreateTemp("", "config_ng")
+	os.Setenv(configlib.EnvConfigNextGenKey, configFileNG.Name())
+	defer os.RemoveAll(configFileNG.Name())
+
+	os.Setenv(constants.CEIPOptInUserPromptAnswer, "No")
+	os.Setenv(constants.EULAPromptAnswer, "Yes")
+
+	for _, spec := range tests {
+		t.Run(spec.test, func(t *testing.T) {
+			assert := assert.New(t)
+
+			// Start with a modified default plugin source and a custom one
+			// so we can test the "plugin source reset" command
+			err := configlib.SetCLIDiscoverySource(configtypes.PluginDiscovery{
+				OCI: &configtypes.OCIDiscovery{
+					Name:  config.DefaultStandaloneDiscoveryName,
+					Image: "test/uri",
+				}})
+			assert.Nil(err)
+			err = configlib.SetCLIDiscoverySource(configtypes.PluginDiscovery{
+				OCI: &configtypes.OCIDiscovery{
+					Name:  "mirror",
+					Image: "example.com/mirror:latest",
+				}})
+			assert.Nil(err)
+
+			rootCmd, err := NewRootCmd()
+			assert.Nil(err)
+			rootCmd.SetArgs(spec.args)
+			b := bytes.NewBufferString("")
+			rootCmd.SetOut(b)
+			rootCmd.SetErr(b)
+			log.SetStdout(b)
+			log.SetStderr(b)
+
+			err = rootCmd.Execute()
+			assert.Equal(err != nil, spec.expectedFailure)
+
+			if spec.expectedFailure {
+				// Check we got the correct error
+				assert.Contains(err.Error(), spec.expected[0])
+				return
+			}
+			got, err := io.ReadAll(b)
+			assert.Nil(err)
+			for _, expected := range spec.expected {
+				assert.Contains(string(got), expected)
+			}
+
+			discoverySources, err := configlib.GetCLIDiscoverySources()
+			assert.Nil(err)
+			var names []string
+			for _, ds := range discoverySources {
+				assert.NotNil(ds.OCI)
+				names = append(names, ds.OCI.Name)
+				if ds.OCI.Name == config.DefaultStandaloneDiscoveryName {
+					assert.Equal(constants.TanzuCLIDefaultCentralPluginDiscoveryImage, ds.OCI.Image)
+				}
+			}
+			assert.ElementsMatch(spec.expectedSources, names)
+		})
+	}
+	os.Unsetenv(configlib.EnvConfigKey)
+	os.Unsetenv(configlib.EnvConfigNextGenKey)
+	os.Unsetenv(constants.CEIPOptInUserPromptAnswer)
+	os.Unsetenv(constants.EULAPromptAnswer)
+}
+
+func Test_loginLogoutDiscoverySource(t *testing.T) {
+	// The fake credential helper records the operations and their input in a file
+	dir := t.TempDir()
+	record := filepath.Join(dir, "record")
+	helper := filepath.Join(dir, "docker-credential-fake")
+	script := `#!/bin/sh
+echo "$1" >> ` + record + `
+cat >> ` + record + `
+echo >> ` + record + `
+if [ "$1" = "erase" ] && ! grep -q "^store" ` + record + `; then
+  echo "credentials not found in native keychain"
+  exit 1
+fi
+`
+	assert.Nil(t, os.WriteFile(helper, []byte(script), 0o755))
+
+	validateRegistryCredentials = func(registryHost, username, password string) error {
+		if password != "secret" {
+			return errors.Errorf("registry %q rejected the credentials of user %q", registryHost, username)
+		}
+		return nil
+	}
+	defer func() { validateRegistryCredentials = registry.ValidateCredentials }()
+
+	tests := []struct {
+		test            string
+		args            []string
+		stdin           string
+		expected        []string
+		expectedRecord  []string
+		expectedHelper  string
+		expectedFailure bool
+	}{
+		{
+			test:            "login without registry",
+			args:            []string{"plugin", "source", "login"},
+			expectedFailure: true,
+			expected:        []string{"accepts 1 arg(s), received 0"},
+		},
+		{
+			test:            "login with --password-stdin but without --username",
+			args:            []string{"plugin", "source", "login", "registry.example.com", "--password-stdin"},
+			expectedFailure: true,
+			expected:        []string{"the --username flag must be specified with --password-stdin"},
+		},
+		{
+			test:            "login with an empty password",
+			args:            []string{"plugin", "source", "login", "registry.example.com", "-u", "user", "--password-stdin", "--credential-helper", helper},
+			stdin:           "\n",
+			expectedFailure: true,
+			expected:        []string{"the username and the password cannot be empty"},
+		},
+		{
+			test:            "login with rejected credentials",
+			args:            []string{"plugin", "source", "login", "registry.example.com", "-u", "user", "--password-stdin", "--credential-helper", helper},
+			stdin:           "wrong\n",
+			expectedFailure: true,
+			expected:        []string{`registry "registry.example.com" rejected the credentials of user "user"`},
+		},
+		{
+			test:            "logout without stored credentials",
+			args:            []string{"plugin", "source", "logout", "registry.example.com", "--credential-helper", helper},
+			expectedFailure: true,
+			expected:        []string{`has no credentials for registry "registry.example.com"`},
+		},
+		{
+			test:           "login stores the credentials with the credential helper",
+			args:           []string{"plugin", "source", "login", "registry.example.com", "-u", "user", "--password-stdin", "--credential-helper", helper},
+			stdin:          "secret\n",
+			expected:       []string{`successfully logged in to registry "registry.example.com" as user "user"`},
+			expectedRecord: []string{"store", `{"ServerURL":"registry.example.com","Username":"user","Secret":"secret"}`},
+			expectedHelper: helper,
+		},
+		{
+			test:           "logout uses the credential helper of the login",
+			args:           []string{"plugin", "source", "logout", "registry.example.com"},
+			expected:       []string{`successfully logged out from registry "registry.example.com"`},
+			expectedRecord: []string{"erase", "registry.example.com"},
+		},
+	}
+
+	configFile, _ := os.CreateTemp("", "config")
+	os.Setenv(configlib.EnvConfigKey, configFile.Name())
+	defer os.RemoveAll(configFile.Name())
+
+	configFileNG, _ := os.CreateTemp("", "config_ng")
+	os.Setenv(configlib.EnvConfigNextGenKey, configFileNG.Name())
+	defer os.RemoveAll(configFileNG.Name())
+
+	os.Setenv(constants.CEIPOptInUserPromptAnswer, "No")
+	os.Setenv(constants.EULAPromptAnswer, "Yes")
+
+	for _, spec := range tests {
+		t.Run(spec.test, func(t *testing.T) {
+			assert := assert.New(t)
+
+			rootCmd, err := NewRootCmd()
+			assert.Nil(err)
+			rootCmd.SetArgs(spec.args)
+			rootCmd.SetIn(strings.NewReader(spec.stdin))
+			b := bytes.NewBufferString("")
+			rootCmd.SetOut(b)
+			rootCmd.SetErr(b)
+			log.SetStdout(b)
+			log.SetStderr(b)
+
+			err = rootCmd.Execute()
+			resetPluginCommandFlags()
+			assert.Equal(err != nil, spec.expectedFailure)
+			assert.Equal(spec.expectedHelper, config.GetRegistryCredentialHelper("registry.example.com"))
+
+			if spec.expectedFailure {
+				// Check we got the correct error
+				assert.Contains(err.Error(), spec.expected[0])
+				return
+			}
+			got, err := io.ReadAll(b)
+			assert.Nil(err)
+			for _, expected := range spec.expected {
+				assert.Contains(string(got), expected)
+			}
+
+			recorded, err := os.ReadFile(record)
+			assert.Nil(err)
+			assert.Contains(string(recorded), strings.Join(spec.expectedRecord, "\n"))
+		})
+	}
+
+	// Only the name of the credential helper was saved in the configuration, and the logout removed it
+	_, err := configlib.GetEnv(constants.ConfigVariableRegistryCredentialHelperPrefix + "REGISTRY_EXAMPLE_COM")
+	assert.NotNil(t, err)
+	for _, file := range []string{configFile.Name(), configFileNG.Name()} {
+		content, err := os.ReadFile(file)
+		assert.Nil(t, err)
+		assert.NotContains(t, string(content), "secret")
+	}
+
+	os.Unsetenv(configlib.EnvConfigKey)
+	os.Unsetenv(configlib.EnvConfigNextGenKey)
+	os.Unsetenv(constants.CEIPOptInUserPromptAnswer)
+	os.Unsetenv(constants.EULAPromptAnswer)
+}
+
+func Test_getDiscoverySourcesResetChanges(t *testing.T) {
+	assert := assert.New(t)
+
+	defaultDiscovery := config.GetDefaultCentralDiscovery()
+
+	// Nothing to do when only the default discovery source is configured
+	toDelete, changes := getDiscoverySourcesResetChanges([]configtypes.PluginDiscovery{defaultDiscovery}, defaultDiscovery, false)
+	assert.Empty(toDelete)
+	assert.Empty(changes)
+
+	// The default discovery source is added back
+	toDelete, changes = getDiscoverySourcesResetChanges(nil, defaultDiscovery, false)
+	assert.Empty(toDelete)
+	assert.Equal([]string{"add discovery source default (" + constants.TanzuCLIDefaultCentralPluginDiscoveryImage + ")"}, changes)
+
+	// A non-OCI discovery source named like the default one is replaced
+	local := configtypes.PluginDiscovery{Local: &configtypes.LocalDiscovery{Name: config.DefaultStandaloneDiscoveryName, Path: "/tmp"}}
+	toDelete, changes = getDiscoverySourcesResetChanges([]configtypes.PluginDiscovery{local}, defaultDiscovery, true)
+	assert.Equal([]string{config.DefaultStandaloneDiscoveryName}, toDelete)
+	assert.Equal([]string{"reset discovery source default from non-OCI discovery to " + constants.TanzuCLIDefaultCentralPluginDiscoveryImage}, changes)
+}
+
+func Test_exportImportDiscoverySources(t *testing.T) {
+	assert := assert.New(t)
+
+	configFile, _ := os.CreateTemp("", "config")
+	os.Setenv(configlib.EnvConfigKey, configFile.Name())
+	defer os.RemoveAll(configFile.Name())
+
+	configFileNG, _ := os.CreateTemp("", "config_ng")
+	os.Setenv(configlib.EnvConfigNextGenKey, configFileNG.Name())
+	defer os.RemoveAll(configFileNG.Name())
+
+	os.Setenv(constants.CEIPOptInUserPromptAnswer, "No")
+	os.Setenv(constants.EULAPromptAnswer, "Yes")
+	defer os.Unsetenv(constants.ConfigVariablePluginDiscoveryPriorityPrefix + "MIRROR")
+	defer os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+
+	// Configure the discovery sources of a team
+	for name, image := range map[string]string{config.DefaultStandaloneDiscoveryName: "example.com/default:latest", "mirror": "example.com/mirror:latest"} {
+		err := configlib.SetCLIDiscoverySource(configtypes.PluginDiscovery{OCI: &configtypes.OCIDiscovery{Name: name, Image: image}})
+		assert.Nil(err)
+	}
+	assert.Nil(config.SetPluginDiscoveryPriority("mirror", 10))
+	assert.Nil(setSignatureVerificationSkipped("example.com/mirror:latest", true))
+
+	exportFile := filepath.Join(t.TempDir(), "sources.yaml")
+	rootCmd, err := NewRootCmd()
+	assert.Nil(err)
+	rootCmd.SetArgs([]string{"plugin", "source", "export", "--file", exportFile})
+	assert.Nil(rootCmd.Execute())
+
+	b, err := os.ReadFile(exportFile)
+	assert.Nil(err)
+	exported, err := parseExportedDiscoverySources(b)
+	assert.Nil(err)
+	assert.ElementsMatch([]exportedDiscoverySource{
+		{Name: config.DefaultStandaloneDiscoveryName, Image: "example.com/default:latest"},
+		{Name: "mirror", Image: "example.com/mirror:latest", Priority: 10, SkipSignatureVerification: true},
+	}, exported.Sources)
+
+	// Onboard a machine with a different configuration
+	assert.Nil(configlib.DeleteCLIDiscoverySource("mirror"))
+	assert.Nil(config.SetPluginDiscoveryPriority("mirror", 0))
+	assert.Nil(setSignatureVerificationSkipped("example.com/mirror:latest", false))
+	err = configlib.SetCLIDiscoverySource(configtypes.PluginDiscovery{OCI: &configtypes.OCIDiscovery{Name: config.DefaultStandaloneDiscoveryName, Image: "example.com/other:latest"}})
+	assert.Nil(err)
+	err = configlib.SetCLIDiscoverySource(configtypes.PluginDiscovery{OCI: &configtypes.OCIDiscovery{Name: "local", Image: "example.com/local:latest"}})
+	assert.Nil(err)
+
+	// Overwriting a configured discovery source requires --force
+	rootCmd, err = NewRootCmd()
+	assert.Nil(err)
+	rootCmd.SetArgs([]string{"plugin", "source", "import", exportFile})
+	out := bytes.NewBufferString("")
+	rootCmd.SetOut(out)
+	err = rootCmd.Execute()
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "use --force to make the changes")
+	assert.Contains(out.String(), "update discovery source default from example.com/other:latest to example.com/default:latest")
+	discoverySource, err := configlib.GetCLIDiscoverySource(config.DefaultStandaloneDiscoveryName)
+	assert.Nil(err)
+	assert.Equal("example.com/other:latest", discoverySource.OCI.Image)
+
+	rootCmd, err = NewRootCmd()
+	assert.Nil(err)
+	rootCmd.SetArgs([]string{"plugin", "source", "import", exportFile, "--replace", "--force"})
+	out = bytes.NewBufferString("")
+	rootCmd.SetOut(out)
+	assert.Nil(rootCmd.Execute())
+	assert.Contains(out.String(), "remove discovery source local (example.com/local:latest)")
+
+	discoverySources, err := configlib.GetCLIDiscoverySources()
+	assert.Nil(err)
+	exported, err = exportDiscoverySources()
+	assert.Nil(err)
+	assert.Equal(2, len(discoverySources))
+	assert.ElementsMatch([]exportedDiscoverySource{
+		{Name: config.DefaultStandaloneDiscoveryName, Image: "example.com/default:latest"},
+		{Name: "mirror", Image: "example.com/mirror:latest", Priority: 10, SkipSignatureVerification: true},
+	}, exported.Sources)
+
+	// Importing the same discovery sources again changes nothing
+	rootCmd, err = NewRootCmd()
+	assert.Nil(err)
+	rootCmd.SetArgs([]string{"plugin", "source", "import", exportFile})
+	assert.Nil(rootCmd.Execute())
+
+	os.Unsetenv(configlib.EnvConfigKey)
+	os.Unsetenv(configlib.EnvConfigNextGenKey)
+	os.Unsetenv(constants.CEIPOptInUserPromptAnswer)
+	os.Unsetenv(constants.EULAPromptAnswer)
+}
+
+func Test_parseExportedDiscoverySources(t *testing.T) {
+	assert := assert.New(t)
+
+	exported, err := parseExportedDiscoverySources([]byte(`sources:
+- name: default
+  image: example.com/default:latest
+- name: local
+  image: oci-layout:/path/to/inventory
+  priority: 5
+`))
+	assert.Nil(err)
+	assert.Equal([]exportedDiscoverySource{
+		{Name: "default", Image: "example.com/default:latest"},
+		{Name: "local", Image: "oci-layout:/path/to/inventory", Priority: 5},
+	}, exported.Sources)
+
+	_, err = parseExportedDiscoverySources([]byte("sources:\n- name: default\n  image: example.com/Default:latest\n"))
+	assert.NotNil(err)
+	assert.Contains(err.Error(), `invalid image for discovery source "default"`)
+
+	_, err = parseExportedDiscoverySources([]byte("sources:\n- name: default\n  image: a:1\n- name: default\n  image: b:1\n"))
+	assert.NotNil(err)
+	assert.Contains(err.Error(), `discovery source "default" is defined more than once`)
+
+	_, err = parseExportedDiscoverySources([]byte("sources:\n- image: a:1\n"))
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "discovery source name cannot be empty")
+
+	_, err = parseExportedDiscoverySources([]byte("sources:\n- name: default\n  uri: a:1\n"))
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "invalid discovery sources")
+}
+
+func Test_getDiscoverySourcesImportChanges(t *testing.T) {
+	assert := assert.New(t)
+
+	configured := []configtypes.PluginDiscovery{
+		{OCI: &configtypes.OCIDiscovery{Name: "default", Image: "example.com/default:latest"}},
+		{Local: &configtypes.LocalDiscovery{Name: "local", Path: "/tmp"}},
+	}
+
+	// Adding discovery sources is not destructive
+	toDelete, changes, destructive := getDiscoverySourcesImportChanges(configured, []exportedDiscoverySource{
+		{Name: "default", Image: "example.com/default:latest"},
+		{Name: "mirror", Image: "example.com/mirror:latest"},
+	}, false)
+	assert.Empty(toDelete)
+	assert.Equal([]string{"add discovery source mirror (example.com/mirror:latest)"}, changes)
+	assert.False(destructive)
+
+	// Adding a discovery source whose signature is not verified must be forced
+	toDelete, changes, destructive = getDiscoverySourcesImportChanges(configured, []exportedDiscoverySource{
+		{Name: "mirror", Image: "example.com/mirror:latest", SkipSignatureVerification: true},
+	}, false)
+	assert.Empty(toDelete)
+	assert.Equal([]string{
+		"add discovery source mirror (example.com/mirror:latest)",
+		"skip the signature verification of example.com/mirror:latest",
+	}, changes)
+	assert.True(destructive)
+
+	// Replacing a non-OCI discovery source of the same name is destructive
+	toDelete, changes, destructive = getDiscoverySourcesImportChanges(configured, []exportedDiscoverySource{
+		{Name: "local", Image: "example.com/local:latest"},
+	}, false)
+	assert.Equal([]string{"local"}, toDelete)
+	assert.Equal([]string{"update discovery source local from non-OCI discovery to example.com/local:latest"}, changes)
+	assert.True(destructive)
+
+	// The discovery sources that are not imported are removed when replacing
+	toDelete, changes, destructive = getDiscoverySourcesImportChanges(configured, []exportedDiscoverySource{
+		{Name: "default", Image: "example.com/default:latest"},
+	}, true)
+	assert.Equal([]string{"local"}, toDelete)
+	assert.Equal([]string{"remove discovery source local (non-OCI discovery)"}, changes)
+	assert.True(destructive)
+}
+
 func TestCompletionPluginSource(t *testing.T) {
 	// This is global logic and needs not be tested for each
 	// command.  Let's deactivate it.
@@ -413,6 +1003,45 @@ func TestCompletionPluginSource(t *testing.T) {
 			// ":4" is the value of the ShellCompDirectiveNoFileComp
 			expected: "_activeHelp_ " + compNoMoreArgsMsg + "\n:4\n",
 		},
+		// =========================
+		// tanzu plugin source reset
+		// =========================
+		{
+			test: "no completion after the source reset command",
+			args: []string{"__complete", "plugin", "source", "reset", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ " + compNoMoreArgsMsg + "\n:4\n",
+		},
+		// =========================
+		// tanzu plugin source login
+		// =========================
+		{
+			test: "completion for the source login command",
+			args: []string{"__complete", "plugin", "source", "login", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the host of the registry, e.g. registry.example.com\n:4\n",
+		},
+		{
+			test: "no completion after the first arg of the source login command",
+			args: []string{"__complete", "plugin", "source", "login", "registry.example.com", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ " + compNoMoreArgsMsg + "\n:4\n",
+		},
+		{
+			test: "completion of the --username flag value for the source login command",
+			args: []string{"__complete", "plugin", "source", "login", "registry.example.com", "--username", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the username for the registry\n:4\n",
+		},
+		// ==========================
+		// tanzu plugin source logout
+		// ==========================
+		{
+			test: "completion for the source logout command",
+			args: []string{"__complete", "plugin", "source", "logout", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the host of the registry, e.g. registry.example.com\n:4\n",
+		},
 		// ========================
 		// tanzu plugin source list
 		// ========================
@@ -435,9 +1064,7 @@ func TestCompletionPluginSource(t *testing.T) {
 			test: "completion for the source update command",
 			args: []string{"__complete", "plugin", "source", "update", ""},
 			// ":4" is the value of the ShellCompDirectiveNoFileComp
-			expected: "--uri\tURI for discovery source. The URI must be of an OCI image\n" +
-				"-u\tURI for discovery source. The URI must be of an OCI image\n" +
-				"default\texample.com/tanzu_cli/plugins/plugin-inventory:latest\n" +
+			expected: "default\texample.com/tanzu_cli/plugins/plugin-inventory:latest\n" +
 				":4\n",
 		},
 		{
@@ -445,9 +1072,21 @@ func TestCompletionPluginSource(t *testing.T) {
 			args: []string{"__complete", "plugin", "source", "update", "default", ""},
 			// ":4" is the value of the ShellCompDirectiveNoFileComp
 			expected: "--uri\tURI for discovery source. The URI must be of an OCI image\n" +
-				"-u\tURI for discovery source. The URI must be of an OCI image\n" +
+				"--priority\tpriority of the discovery source when a plugin is provided by multiple sources; higher wins\n" +
 				":4\n",
 		},
+		{
+			test: "no completion after the first arg of the source update command with --priority",
+			args: []string{"__complete", "plugin", "source", "update", "default", "--priority", "10", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ " + compNoMoreArgsMsg + "\n:4\n",
+		},
+		{
+			test: "completion of the --priority flag value for the source update command",
+			args: []string{"__complete", "plugin", "source", "update", "default", "--priority", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the priority of the discovery source as an integer\n:4\n",
+		},
 		{
 			test: "no completion after the first arg of the source update command with --uri",
 			args: []string{"__complete", "plugin", "source", "update", "default", "--uri", "someURI", ""},
@@ -7,9 +7,9 @@ import (
 	"github.com/spf13/cobra"
 
 	configlib "github.com/vmware-tanzu/tanzu-plugin-runtime/config"
-	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
 )
 
@@ -56,7 +56,7 @@ func newAcceptEULACmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			log.Successf("Marking agreement as accepted.")
+			clilog.Successf("Marking agreement as accepted.")
 			return nil
 		},
 	}
@@ -9,7 +9,7 @@ import (
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/plugin"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
-	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 )
 
 func init() {
@@ -28,7 +28,7 @@ var initCmd = &cobra.Command{
 		// Currently nothing to initialize.
 		// We are keeping this command as it may become useful
 		// again in the future.
-		log.Success("successfully initialized CLI")
+		clilog.Success("successfully initialized CLI")
 		return nil
 	},
 }
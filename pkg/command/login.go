@@ -23,6 +23,7 @@ import (
 	tkgauth "github.com/vmware-tanzu/tanzu-cli/pkg/auth/tkg"
 	kubecfg "github.com/vmware-tanzu/tanzu-cli/pkg/auth/utils/kubeconfig"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
 )
 
@@ -365,7 +366,7 @@ func globalLoginUsingServer(s *configtypes.Server) (err error) { //nolint:static
 		issuer = csp.StgIssuer
 	}
 	if apiTokenExists {
-		log.Info("API token env var is set")
+		clilog.Info("API token env var is set")
 	} else {
 		apiTokenValue, err = promptAPIToken("TMC")
 		if err != nil {
@@ -403,7 +404,7 @@ func globalLoginUsingServer(s *configtypes.Server) (err error) { //nolint:static
 	}
 
 	fmt.Println()
-	log.Success("successfully logged into global control plane")
+	clilog.Success("successfully logged into global control plane")
 	return nil
 }
 
@@ -420,7 +421,7 @@ func managementClusterLogin(s *configtypes.Server) error { //nolint:staticcheck
 			return err
 		}
 
-		log.Successf("successfully logged in to management cluster using the kubeconfig %s", s.Name)
+		clilog.Successf("successfully logged in to management cluster using the kubeconfig %s", s.Name)
 		return nil
 	}
 
@@ -4,11 +4,14 @@
 package command
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/pkg/errors"
@@ -19,9 +22,13 @@ import (
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/plugin"
 
+	"github.com/vmware-tanzu/tanzu-cli/pkg/buildinfo"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/interrupt"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
@@ -31,19 +38,73 @@ import (
 )
 
 var (
-	local        string
-	version      string
-	forceDelete  bool
-	outputFormat string
-	targetStr    string
-	group        string
+	local         string
+	version       string
+	forceDelete   bool
+	outputFormat  string
+	targetStr     string
+	group         string
+	fromGroup     string
+	installDir    string
+	installDigest string
+	forceInstall  bool
+	dryRun        bool
+	showVersions  bool
+	syncSource    string
+	cleanSource   string
+	inventoryDB   string
+
+	syncConcurrency int
+	syncStrict      bool
+	syncPrune       bool
+
+	includePrerelease bool
+	binaryPath        string
+	sortBy            string
+	prefetchParallel  bool
+	describeVersion   string
+	reverseSort       bool
+	waitVerify        bool
+	discoveryProfile  string
+	maxCacheAge       time.Duration
+	registryCACert    string
+	forceRefresh      bool
+	pluginTimeout     time.Duration
+	reinstall         bool
+	skipPostInstall   bool
+	listColumns       string
+	standaloneOnly    bool
+	contextOnly       bool
+	listContext       string
+	showSignature     bool
+	checkAvailability bool
+	platform          string
+	listFailed        bool
+	listDuplicates    bool
+	listExplain       string
+	listJSONCompact   bool
+	listLimit         int
+	listOffset        int
+	upgradeAll        bool
+	groupExclude      []string
+	groupOnly         []string
+	pruneCache        bool
+
+	skipCompletionRefresh bool
 )
 
 const (
+	allTargetsKeyword               = "all"
 	invalidTargetMsg                = "invalid target specified. Please specify a correct value for the `--target` flag from '" + common.TargetList + "'"
 	errorWhileDiscoveringPlugins    = "there was an error while discovering plugins, error information: '%v'"
 	errorWhileGettingContextPlugins = "there was an error while getting installed context plugins, error information: '%v'"
 	pluginNameCaps                  = "PLUGIN_NAME"
+	// wideOutputFormat is the table format of 'plugin list' with additional columns
+	wideOutputFormat = "wide"
+	// nameOutputFormat lists only the names of the plugins, one per line, for scripting
+	nameOutputFormat = "name"
+	// nameTargetOutputFormat lists only the names and targets of the plugins, one per line
+	nameTargetOutputFormat = "name:target"
 )
 
 func newPluginCmd() *cobra.Command {
@@ -58,23 +119,98 @@ func newPluginCmd() *cobra.Command {
 
 	pluginCmd.SetUsageFunc(cli.SubCmdUsageFunc)
 
+	pluginCmd.PersistentFlags().DurationVar(&maxCacheAge, "max-cache-age", 0, "fail if the cached plugin inventories used without accessing the registry are older than this duration (e.g., 720h)")
+	pluginCmd.PersistentFlags().StringVar(&discoveryProfile, "profile", "", "name of the discovery profile whose images replace the configured discovery images for this command")
+	pluginCmd.PersistentFlags().StringVar(&registryCACert, "registry-ca-cert", "", "path to a file of PEM-encoded CA certificates to trust when accessing the registries")
+	pluginCmd.PersistentFlags().BoolVar(&forceRefresh, "refresh", false, "verify the signature of the plugin inventory images again even if it recently failed")
+	pluginCmd.PersistentFlags().DurationVar(&pluginTimeout, "timeout", 0, "abort the command if it does not complete within this duration (e.g., 2m), by default there is no timeout")
+
 	listPluginCmd := newListPluginCmd()
 	installPluginCmd := newInstallPluginCmd()
 	upgradePluginCmd := newUpgradePluginCmd()
+	reinstallPluginCmd := newReinstallPluginCmd()
 	describePluginCmd := newDescribePluginCmd()
 	deletePluginCmd := newDeletePluginCmd()
 	cleanPluginCmd := newCleanPluginCmd()
 	syncPluginCmd := newSyncPluginCmd()
 	discoverySourceCmd := newDiscoverySourceCmd()
 
-	listPluginCmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format (yaml|json|table)")
-	utils.PanicOnErr(listPluginCmd.RegisterFlagCompletionFunc("output", completionGetOutputFormats))
+	listPluginCmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format (yaml|json|table|wide|name|name:target)")
+	utils.PanicOnErr(listPluginCmd.RegisterFlagCompletionFunc("output", completionGetListOutputFormats))
+	listPluginCmd.Flags().StringVar(&sortBy, "sort-by", "", fmt.Sprintf("sort the plugins by the specified key (%s)", strings.Join(pluginSortKeys, "|")))
+	utils.PanicOnErr(listPluginCmd.RegisterFlagCompletionFunc("sort-by", completionGetPluginSortKeys))
+	listPluginCmd.Flags().BoolVar(&reverseSort, "reverse", false, "reverse the order in which the plugins are sorted")
+	// Shell completion for this flag is the default behavior of doing file completion
+	listPluginCmd.Flags().StringVar(&inventoryDB, "db", "", "list the plugins of the specified plugin inventory database file instead of the installed plugins")
+	listPluginCmd.Flags().BoolVar(&standaloneOnly, "standalone-only", false, "only list the standalone plugins")
+	listPluginCmd.Flags().BoolVar(&contextOnly, "context-only", false, "only list the plugins recommended by the active contexts")
+	listPluginCmd.Flags().StringVar(&listColumns, "columns", "", fmt.Sprintf("comma-separated list of the columns to show (%s)", strings.Join(pluginListColumns, "|")))
+	listPluginCmd.MarkFlagsMutuallyExclusive("db", "sort-by")
+	listPluginCmd.MarkFlagsMutuallyExclusive("db", "reverse")
+	listPluginCmd.MarkFlagsMutuallyExclusive("db", "standalone-only")
+	listPluginCmd.MarkFlagsMutuallyExclusive("db", "context-only")
+	listPluginCmd.MarkFlagsMutuallyExclusive("standalone-only", "context-only")
+	listPluginCmd.MarkFlagsMutuallyExclusive("db", "columns")
+	listPluginCmd.Flags().BoolVar(&listFailed, "failed", false, "only list the plugins whose last installation, by a plugin install, upgrade or sync, failed")
+	for _, flag := range []string{"db", "sort-by", "reverse", "standalone-only", "context-only", "columns"} {
+		listPluginCmd.MarkFlagsMutuallyExclusive("failed", flag)
+	}
+	listPluginCmd.Flags().BoolVar(&listDuplicates, "duplicates", false, "list the plugins provided by more than one discovery source, with the versions provided by each source")
+	for _, flag := range []string{"db", "failed", "sort-by", "reverse", "standalone-only", "context-only", "columns"} {
+		listPluginCmd.MarkFlagsMutuallyExclusive("duplicates", flag)
+	}
+	listPluginCmd.Flags().StringVar(&listContext, "context", "", "only list the standalone plugins and the plugins of the specified active context")
+	utils.PanicOnErr(listPluginCmd.RegisterFlagCompletionFunc("context", completeActiveContexts))
+	for _, flag := range []string{"db", "failed", "duplicates", "standalone-only"} {
+		listPluginCmd.MarkFlagsMutuallyExclusive("context", flag)
+	}
+	listPluginCmd.Flags().StringVar(&listExplain, "explain", "", "show why the specified plugin has its status: the discovery sources offering it, the recommended and the installed versions")
+	utils.PanicOnErr(listPluginCmd.RegisterFlagCompletionFunc("explain", completeInstalledPlugins))
+	for _, flag := range []string{"db", "failed", "duplicates", "sort-by", "reverse", "standalone-only", "context-only", "columns"} {
+		listPluginCmd.MarkFlagsMutuallyExclusive("explain", flag)
+	}
+	listPluginCmd.Flags().BoolVar(&listJSONCompact, "json-compact", false, "output the plugins as json on a single line, with the fields of each plugin sorted by name")
+	listPluginCmd.Flags().IntVar(&listLimit, "limit", 0, "maximum number of plugins to list (0 means no limit)")
+	listPluginCmd.Flags().IntVar(&listOffset, "offset", 0, "number of plugins to skip before the ones listed")
+	for _, flag := range []string{"failed", "duplicates", "explain"} {
+		listPluginCmd.MarkFlagsMutuallyExclusive("limit", flag)
+		listPluginCmd.MarkFlagsMutuallyExclusive("offset", flag)
+	}
 
 	describePluginCmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format (yaml|json|table)")
 	utils.PanicOnErr(describePluginCmd.RegisterFlagCompletionFunc("output", completionGetOutputFormats))
+	describePluginCmd.Flags().BoolVar(&showVersions, "versions", false, "show all the versions of the plugin available from the discovery sources, with their supported platforms")
+	describePluginCmd.Flags().StringVarP(&describeVersion, "version", "v", "", "describe the specified version of the plugin available from the discovery sources, even if it is not installed")
+	utils.PanicOnErr(describePluginCmd.RegisterFlagCompletionFunc("version", completePluginVersions))
+	describePluginCmd.Flags().BoolVar(&showSignature, "show-signature", false, "verify the signature of the discovery image the plugin was installed from and show the verification details")
+	describePluginCmd.MarkFlagsMutuallyExclusive("versions", "version")
+	describePluginCmd.MarkFlagsMutuallyExclusive("show-signature", "version")
+	describePluginCmd.MarkFlagsMutuallyExclusive("show-signature", "versions")
+	describePluginCmd.Flags().BoolVar(&checkAvailability, "check-availability", false, "check that the binary of the recommended version of the plugin, or of the version specified with --version, can be downloaded for the current platform")
+	describePluginCmd.MarkFlagsMutuallyExclusive("check-availability", "versions")
+	describePluginCmd.MarkFlagsMutuallyExclusive("check-availability", "show-signature")
 
 	installPluginCmd.Flags().StringVar(&group, "group", "", "install the plugins specified by a plugin-group version")
 	utils.PanicOnErr(installPluginCmd.RegisterFlagCompletionFunc("group", completeGroupsAndVersion))
+	installPluginCmd.Flags().StringSliceVar(&groupExclude, "exclude", nil, "do not install the specified members of the plugin group (comma-separated)")
+	installPluginCmd.Flags().StringSliceVar(&groupOnly, "only", nil, "only install the specified members of the plugin group (comma-separated)")
+	installPluginCmd.Flags().StringVar(&fromGroup, "from-group", "", "install the version of the plugin specified by a plugin-group version")
+	utils.PanicOnErr(installPluginCmd.RegisterFlagCompletionFunc("from-group", completeGroupsAndVersion))
+	installPluginCmd.MarkFlagsMutuallyExclusive("from-group", "group")
+	installPluginCmd.MarkFlagsMutuallyExclusive("from-group", "version")
+	installPluginCmd.MarkFlagsMutuallyExclusive("from-group", "binary")
+	installPluginCmd.MarkFlagsMutuallyExclusive("from-group", "local-source")
+	installPluginCmd.Flags().StringVar(&installDir, "install-dir", "", "install the plugin binary to the specified directory instead of the default plugin location")
+	installPluginCmd.MarkFlagsMutuallyExclusive("install-dir", "group")
+	installPluginCmd.MarkFlagsMutuallyExclusive("install-dir", "local-source")
+	installPluginCmd.MarkFlagsMutuallyExclusive("install-dir", "binary")
+	installPluginCmd.Flags().StringVar(&installDigest, "digest", "", "install the version of the plugin whose binary for the platform of the CLI has the specified digest (sha256:<hex>)")
+	installPluginCmd.MarkFlagsMutuallyExclusive("digest", "version")
+	installPluginCmd.MarkFlagsMutuallyExclusive("digest", "from-group")
+	installPluginCmd.MarkFlagsMutuallyExclusive("digest", "group")
+	installPluginCmd.MarkFlagsMutuallyExclusive("digest", "binary")
+	installPluginCmd.MarkFlagsMutuallyExclusive("digest", "local-source")
+	installPluginCmd.MarkFlagsMutuallyExclusive("digest", "dry-run")
 
 	// --local is renamed to --local-source
 	installPluginCmd.Flags().StringVarP(&local, "local", "", "", "path to local plugin source")
@@ -90,7 +226,25 @@ func newPluginCmd() *cobra.Command {
 	installPluginCmd.Flags().StringVarP(&version, "version", "v", cli.VersionLatest, "version of the plugin")
 	utils.PanicOnErr(installPluginCmd.RegisterFlagCompletionFunc("version", completePluginVersions))
 
+	installPluginCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the plugins that would be installed, including dependencies, without installing them")
+	installPluginCmd.Flags().BoolVar(&includePrerelease, "include-prerelease", false, "allow a pre-release version to be installed as the latest version of the plugin")
+	upgradePluginCmd.Flags().BoolVar(&includePrerelease, "include-prerelease", false, "allow a pre-release version to be installed as the latest version of the plugin")
+	installPluginCmd.Flags().StringVar(&binaryPath, "binary", "", "path to a pre-built plugin binary to install directly, without using the discovery sources")
+	installPluginCmd.Flags().BoolVar(&waitVerify, "wait-verify", false, "verify the signature of the plugin discovery images before installing and print the result")
+	installPluginCmd.Flags().BoolVar(&reinstall, "reinstall", false, "download and install the plugin again even if the same version is already installed")
+	installPluginCmd.Flags().BoolVar(&forceInstall, "force", false, "install the plugins even if they require a more recent version of the CLI")
+	installPluginCmd.Flags().BoolVar(&skipPostInstall, "skip-post-install", false, "do not run the post-install command of the installed plugins")
+	installPluginCmd.Flags().BoolVar(&skipCompletionRefresh, "skip-completion-refresh", false, "do not refresh the installed shell completion scripts once the plugins are installed")
+	upgradePluginCmd.Flags().BoolVar(&skipCompletionRefresh, "skip-completion-refresh", false, "do not refresh the installed shell completion scripts once the plugins are upgraded")
+	deletePluginCmd.Flags().BoolVar(&skipCompletionRefresh, "skip-completion-refresh", false, "do not refresh the installed shell completion scripts once the plugin is uninstalled")
+	installPluginCmd.Flags().StringVar(&platform, "platform", "", "install the plugin binaries built for the specified platform (<os>/<arch>, e.g., linux/arm64) instead of the platform of the CLI")
+	installPluginCmd.MarkFlagsMutuallyExclusive("platform", "binary")
+	installPluginCmd.MarkFlagsMutuallyExclusive("platform", "local-source")
+	installPluginCmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format of the description of the installed plugins, instead of the success message (yaml|json)")
+	utils.PanicOnErr(installPluginCmd.RegisterFlagCompletionFunc("output", completionGetObjectOutputFormats))
+
 	deletePluginCmd.Flags().BoolVarP(&forceDelete, "yes", "y", false, "uninstall the plugin without asking for confirmation")
+	deletePluginCmd.Flags().BoolVar(&pruneCache, "prune-cache", false, "also remove the cached plugin inventories no longer used by any installed plugin or configured discovery source")
 
 	targetFlagDesc := fmt.Sprintf("target of the plugin (%s)", common.TargetList)
 	installPluginCmd.Flags().StringVarP(&targetStr, "target", "t", "", targetFlagDesc)
@@ -98,31 +252,70 @@ func newPluginCmd() *cobra.Command {
 
 	upgradePluginCmd.Flags().StringVarP(&targetStr, "target", "t", "", targetFlagDesc)
 	utils.PanicOnErr(upgradePluginCmd.RegisterFlagCompletionFunc("target", completeTargetsForAllPlugins))
+	upgradePluginCmd.Flags().BoolVar(&upgradeAll, "all", false, "upgrade all the installed standalone plugins, or only those of the target specified with --target")
+	upgradePluginCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the installed version of the plugins and the version they would be upgraded to, without upgrading them")
+	upgradePluginCmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format of --dry-run (yaml|json|table)")
+	utils.PanicOnErr(upgradePluginCmd.RegisterFlagCompletionFunc("output", completionGetOutputFormats))
+
+	reinstallPluginCmd.Flags().StringVarP(&targetStr, "target", "t", "", targetFlagDesc)
+	utils.PanicOnErr(reinstallPluginCmd.RegisterFlagCompletionFunc("target", completeTargetsForAllPlugins))
+	reinstallPluginCmd.Flags().BoolVarP(&forceDelete, "yes", "y", false, "reinstall the plugin without asking for confirmation")
+	reinstallPluginCmd.Flags().BoolVar(&skipPostInstall, "skip-post-install", false, "do not run the post-install command of the reinstalled plugin")
+	reinstallPluginCmd.Flags().BoolVar(&skipCompletionRefresh, "skip-completion-refresh", false, "do not refresh the installed shell completion scripts once the plugin is reinstalled")
 
 	deletePluginCmd.Flags().StringVarP(&targetStr, "target", "t", "", targetFlagDesc)
 	utils.PanicOnErr(deletePluginCmd.RegisterFlagCompletionFunc("target", completeTargetsForInstalledPlugins))
 
-	describePluginCmd.Flags().StringVarP(&targetStr, "target", "t", "", targetFlagDesc)
+	describePluginCmd.Flags().StringVarP(&targetStr, "target", "t", "", fmt.Sprintf("target of the plugin (%s), or all to describe the plugin for each of its targets", common.TargetList))
 	utils.PanicOnErr(describePluginCmd.RegisterFlagCompletionFunc("target", completeTargetsForInstalledPlugins))
 
 	installPluginCmd.MarkFlagsMutuallyExclusive("group", "local")
 	installPluginCmd.MarkFlagsMutuallyExclusive("group", "local-source")
 	installPluginCmd.MarkFlagsMutuallyExclusive("group", "version")
 	installPluginCmd.MarkFlagsMutuallyExclusive("group", "target")
+	installPluginCmd.MarkFlagsMutuallyExclusive("dry-run", "group")
+	installPluginCmd.MarkFlagsMutuallyExclusive("dry-run", "local")
+	installPluginCmd.MarkFlagsMutuallyExclusive("dry-run", "local-source")
+	installPluginCmd.MarkFlagsMutuallyExclusive("include-prerelease", "group")
+	installPluginCmd.MarkFlagsMutuallyExclusive("wait-verify", "local")
+	installPluginCmd.MarkFlagsMutuallyExclusive("wait-verify", "local-source")
+	installPluginCmd.MarkFlagsMutuallyExclusive("binary", "group")
+	installPluginCmd.MarkFlagsMutuallyExclusive("binary", "local")
+	installPluginCmd.MarkFlagsMutuallyExclusive("binary", "local-source")
+	installPluginCmd.MarkFlagsMutuallyExclusive("binary", "version")
+	installPluginCmd.MarkFlagsMutuallyExclusive("binary", "dry-run")
+	installPluginCmd.MarkFlagsMutuallyExclusive("binary", "include-prerelease")
+	installPluginCmd.MarkFlagsMutuallyExclusive("binary", "wait-verify")
+	installPluginCmd.MarkFlagsMutuallyExclusive("reinstall", "group")
+	installPluginCmd.MarkFlagsMutuallyExclusive("reinstall", "local")
+	installPluginCmd.MarkFlagsMutuallyExclusive("reinstall", "local-source")
+	installPluginCmd.MarkFlagsMutuallyExclusive("reinstall", "binary")
+	installPluginCmd.MarkFlagsMutuallyExclusive("force", "binary")
+	installPluginCmd.MarkFlagsMutuallyExclusive("force", "local-source")
+	installPluginCmd.MarkFlagsMutuallyExclusive("reinstall", "dry-run")
+	installPluginCmd.MarkFlagsMutuallyExclusive("output", "dry-run")
+	installPluginCmd.MarkFlagsMutuallyExclusive("skip-post-install", "dry-run")
+	installPluginCmd.MarkFlagsMutuallyExclusive("exclude", "only")
 
 	pluginCmd.AddCommand(
 		listPluginCmd,
 		installPluginCmd,
 		upgradePluginCmd,
+		reinstallPluginCmd,
 		describePluginCmd,
 		deletePluginCmd,
 		cleanPluginCmd,
 		syncPluginCmd,
+		newPrefetchPluginCmd(),
+		newAuditPluginCmd(),
+		newDoctorPluginCmd(),
+		newPluginCacheCmd(),
 		discoverySourceCmd,
 		newSearchPluginCmd(),
 		newPluginGroupCmd(),
 		newDownloadBundlePluginCmd(),
 		newUploadBundlePluginCmd(),
+		newCopySourcePluginCmd(),
 	)
 
 	return pluginCmd
@@ -130,35 +323,211 @@ func newPluginCmd() *cobra.Command {
 
 func newListPluginCmd() *cobra.Command {
 	var listCmd = &cobra.Command{
-		Use:               "list",
-		Short:             "List installed plugins",
-		Long:              "List installed standalone plugins or plugins recommended by the contexts being used",
+		Use:   "list",
+		Short: "List installed plugins",
+		Long:  "List installed standalone plugins or plugins recommended by the contexts being used",
+		Example: `
+    # List the installed plugins
+    tanzu plugin list
+
+    # Also show the vendor and publisher of the plugins and the size of their binaries
+    tanzu plugin list -o wide
+
+    # List the plugins of a plugin inventory database file, e.g. before publishing it
+    tanzu plugin list --db ./plugin_inventory.db
+
+    # Only list the standalone plugins, or only the plugins of the active contexts
+    tanzu plugin list --standalone-only
+    tanzu plugin list --context-only
+
+    # Only list the standalone plugins and the plugins of the active context prod-cluster
+    tanzu plugin list --context prod-cluster
+
+    # Only show the name, version, target, discovery source and status of the plugins
+    tanzu plugin list --columns name,version,target,source,status
+
+    # List the plugins whose last installation failed, e.g. during a plugin sync
+    tanzu plugin list --failed
+
+    # List the plugins provided by more than one discovery source, e.g. to find overlapping mirrors
+    tanzu plugin list --duplicates
+
+    # Show why the login plugin is reported as outdated or with an update available
+    tanzu plugin list --explain login
+
+    # List the plugins as json on a single line, e.g. to compare the output of different CLI versions
+    tanzu plugin list --json-compact
+
+    # Only list the plugins 21 to 40, e.g. to page through a large plugin inventory database
+    tanzu plugin list --db ./plugin_inventory.db --limit 20 --offset 20
+
+    # Only print the names of the plugins, one per line, e.g. to reinstall the failed plugins
+    tanzu plugin list --failed -o name | xargs -n 1 tanzu plugin install`,
 		ValidArgsFunction: noMoreCompletions,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validatePluginSortKey(sortBy); err != nil {
+				return err
+			}
+			columns, err := parsePluginListColumns(listColumns)
+			if err != nil {
+				return err
+			}
+			if listLimit < 0 || listOffset < 0 {
+				return errors.New("the values of the --limit and --offset flags cannot be negative")
+			}
+			if listJSONCompact {
+				if outputFormat != "" && outputFormat != string(component.JSONOutputType) {
+					return errors.Errorf("the --json-compact flag cannot be used with the %q output format", outputFormat)
+				}
+				outputFormat = string(component.JSONOutputType)
+			}
+
+			if listFailed {
+				failed, err := pluginmanager.GetFailedPluginInstallations()
+				if err != nil {
+					return err
+				}
+				if isNameOutputFormat() {
+					names := make([]string, 0, len(failed))
+					for _, f := range failed {
+						names = append(names, pluginOutputName(f.Name, string(f.Target)))
+					}
+					return displayPluginNames(names, cmd.OutOrStdout())
+				}
+				if outputFormat == string(component.JSONOutputType) {
+					if failed == nil {
+						failed = []*pluginmanager.PluginInstallStatus{}
+					}
+					return renderJSON(cmd.OutOrStdout(), failed, listJSONCompact)
+				}
+				displayFailedPluginInstallations(failed, cmd.OutOrStdout())
+				return nil
+			}
+
+			if listDuplicates {
+				duplicates, err := pluginmanager.GetDuplicateStandalonePlugins()
+				if err != nil {
+					log.Warningf(errorWhileDiscoveringPlugins, err.Error())
+				}
+				if outputFormat == string(component.JSONOutputType) {
+					if duplicates == nil {
+						duplicates = []pluginmanager.DuplicatePlugin{}
+					}
+					return kerrors.NewAggregate([]error{err, renderJSON(cmd.OutOrStdout(), duplicates, listJSONCompact)})
+				}
+				displayDuplicatePlugins(duplicates, cmd.OutOrStdout())
+				return err
+			}
+
+			if listExplain != "" {
+				explanations, err := pluginmanager.ExplainPluginStatus(listExplain)
+				if explanations == nil {
+					return err
+				}
+				if err != nil {
+					log.Warningf(errorWhileDiscoveringPlugins, err.Error())
+				}
+				if outputFormat == string(component.JSONOutputType) {
+					return renderJSON(cmd.OutOrStdout(), explanations, listJSONCompact)
+				}
+				displayPluginStatusExplanations(explanations, cmd.OutOrStdout())
+				return nil
+			}
+
+			if listContext != "" {
+				if err := pluginmanager.ValidateActiveContext(listContext); err != nil {
+					return err
+				}
+			}
+
+			if inventoryDB != "" {
+				// The page of plugins is selected by the query of the inventory DB
+				criteria := &discovery.PluginDiscoveryCriteria{Limit: listLimit, Offset: listOffset}
+				plugins, err := pluginmanager.DiscoverPluginsFromInventoryDB(inventoryDB, discovery.WithPluginDiscoveryCriteria(criteria))
+				if err != nil {
+					return err
+				}
+				sort.Sort(discovery.DiscoveredSorter(plugins))
+				if isNameOutputFormat() {
+					names := make([]string, 0, len(plugins))
+					for i := range plugins {
+						names = append(names, pluginOutputName(plugins[i].Name, string(plugins[i].Target)))
+					}
+					return displayPluginNames(names, cmd.OutOrStdout())
+				}
+				if outputFormat == string(component.JSONOutputType) {
+					return renderJSON(cmd.OutOrStdout(), pluginsFoundJSONObjects(plugins), listJSONCompact)
+				}
+				displayPluginsFound(plugins, cmd.OutOrStdout())
+				if isPluginListPaged() {
+					total, err := pluginmanager.CountPluginsFromInventoryDB(inventoryDB)
+					if err != nil {
+						return err
+					}
+					displayPluginListPageFooter(len(plugins), total, cmd.OutOrStdout())
+				}
+				return nil
+			}
+
 			errorList := make([]error, 0)
 			// List installed standalone plugins
-			standalonePlugins, err := pluginsupplier.GetInstalledStandalonePlugins()
-			if err != nil {
-				errorList = append(errorList, err)
-				log.Warningf("there was an error while getting installed standalone plugins, error information: '%v'", err.Error())
+			var standalonePlugins []cli.PluginInfo
+			if !contextOnly {
+				var err error
+				standalonePlugins, err = pluginsupplier.GetInstalledStandalonePlugins()
+				if err != nil {
+					errorList = append(errorList, err)
+					log.Warningf("there was an error while getting installed standalone plugins, error information: '%v'", err.Error())
+				}
+				sortStandalonePlugins(standalonePlugins)
 			}
-			sort.Sort(cli.PluginInfoSorter(standalonePlugins))
 
 			// List installed context plugins and also missing context plugins.
 			// Showing missing ones guides the user to know some plugins are recommended for the
 			// active contexts, but are not installed.
-			installedContextPlugins, missingContextPlugins, pluginSyncRequired, err := getInstalledAndMissingContextPlugins()
-			if err != nil {
-				errorList = append(errorList, err)
-				log.Warningf(errorWhileGettingContextPlugins, err.Error())
+			var installedContextPlugins, missingContextPlugins []discovery.Discovered
+			var pluginSyncRequired bool
+			if !standaloneOnly {
+				var err error
+				installedContextPlugins, missingContextPlugins, pluginSyncRequired, err = getInstalledAndMissingContextPlugins(listContext)
+				if err != nil {
+					errorList = append(errorList, err)
+					log.Warningf(errorWhileGettingContextPlugins, err.Error())
+				}
+				sortContextPlugins(installedContextPlugins)
+				sortContextPlugins(missingContextPlugins)
+			}
+
+			deprecations := pluginmanager.GetPluginsDeprecation(standalonePlugins)
+			unavailable := pluginmanager.GetUnavailablePlugins(standalonePlugins)
+			// The sizes are only looked up when they are shown
+			// The page is selected once the plugins are merged and sorted, so
+			// that the total counts each listed plugin once
+			var total int
+			standalonePlugins, installedContextPlugins, missingContextPlugins, total = pagePluginList(standalonePlugins, installedContextPlugins, missingContextPlugins, listOffset, listLimit)
+
+			var sizes map[string]int64
+			if outputFormat == wideOutputFormat || utils.ContainsString(columns, "size") {
+				sizes = pluginmanager.GetPluginsBinarySize(standalonePlugins)
 			}
-			sort.Sort(discovery.DiscoveredSorter(installedContextPlugins))
-			sort.Sort(discovery.DiscoveredSorter(missingContextPlugins))
 
-			if outputFormat == "" || outputFormat == string(component.TableOutputType) {
-				displayInstalledAndMissingSplitView(standalonePlugins, installedContextPlugins, missingContextPlugins, pluginSyncRequired, cmd.OutOrStdout())
+			if outputFormat == "" || outputFormat == string(component.TableOutputType) || outputFormat == wideOutputFormat {
+				displayInstalledAndMissingSplitView(standalonePlugins, installedContextPlugins, missingContextPlugins, deprecations, unavailable, sizes, pluginSyncRequired, outputFormat == wideOutputFormat, columns, cmd.OutOrStdout())
+				if isPluginListPaged() {
+					displayPluginListPageFooter(len(standalonePlugins)+len(installedContextPlugins)+len(missingContextPlugins), total, cmd.OutOrStdout())
+				}
+			} else if isNameOutputFormat() {
+				rows := installedAndMissingListRows(standalonePlugins, installedContextPlugins, missingContextPlugins, deprecations, unavailable, sizes)
+				names := make([]string, 0, len(rows))
+				for _, row := range rows {
+					names = append(names, pluginOutputName(row.name, row.target))
+				}
+				errorList = append(errorList, displayPluginNames(names, cmd.OutOrStdout()))
+			} else if outputFormat == string(component.JSONOutputType) {
+				rows := installedAndMissingListRows(standalonePlugins, installedContextPlugins, missingContextPlugins, deprecations, unavailable, sizes)
+				errorList = append(errorList, renderJSON(cmd.OutOrStdout(), pluginListJSONObjects(rows, columns), listJSONCompact))
 			} else {
-				displayInstalledAndMissingListView(standalonePlugins, installedContextPlugins, missingContextPlugins, cmd.OutOrStdout())
+				displayInstalledAndMissingListView(standalonePlugins, installedContextPlugins, missingContextPlugins, deprecations, unavailable, sizes, columns, cmd.OutOrStdout())
 			}
 
 			return kerrors.NewAggregate(errorList)
@@ -175,21 +544,78 @@ func newDescribePluginCmd() *cobra.Command {
 		Long:              "Displays detailed information for a plugin",
 		ValidArgsFunction: completeInstalledPlugins,
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			output := component.NewOutputWriterWithOptions(cmd.OutOrStdout(), outputFormat, []component.OutputWriterOption{}, "name", "version", "status", "target", "description", "installationPath")
 			if len(args) != 1 {
 				return fmt.Errorf("must provide one plugin name as a positional argument")
 			}
 			pluginName := args[0]
 
-			if !configtypes.IsValidTarget(targetStr, true, true) {
+			allTargets := strings.EqualFold(targetStr, allTargetsKeyword)
+			if !allTargets && !configtypes.IsValidTarget(targetStr, true, true) {
 				return errors.New(invalidTargetMsg)
 			}
+			singleTargetFlags := describeVersion != "" || showVersions || showSignature || checkAvailability
+			if allTargets && singleTargetFlags {
+				return errors.New("the --version, --versions, --show-signature and --check-availability flags cannot be used with '--target all'")
+			}
+
+			// Without a target, a plugin installed for more than one target
+			// is described for each of its targets
+			if allTargets || (getTarget() == configtypes.TargetUnknown && !singleTargetFlags) {
+				pds, err := pluginmanager.DescribePluginForAllTargets(pluginName)
+				if err != nil {
+					return err
+				}
+				if allTargets || len(pds) > 1 {
+					displayPluginDescriptionsForAllTargets(pds, cmd.OutOrStdout())
+					return nil
+				}
+			}
+
+			if checkAvailability {
+				availability, err := pluginmanager.CheckPluginAvailability(pluginName, getTarget(), describeVersion)
+				if err != nil {
+					return err
+				}
+				displayPluginAvailability(availability, cmd.OutOrStdout())
+				return nil
+			}
+
+			if describeVersion != "" {
+				pvd, err := pluginmanager.DescribeAvailablePluginVersion(pluginName, getTarget(), describeVersion)
+				if err != nil {
+					return err
+				}
+				displayPluginVersionDescription(pvd, cmd.OutOrStdout())
+				return nil
+			}
 
 			pd, err := pluginmanager.DescribePlugin(pluginName, getTarget())
 			if err != nil {
 				return err
 			}
-			output.AddRow(pd.Name, pd.Version, pd.Status, pd.Target, pd.Description, pd.InstallationPath)
+
+			markDeprecatedPlugins([]*cli.PluginInfo{pd})
+
+			if showSignature {
+				displayPluginDescriptionWithSignature(pd, pluginmanager.VerifyPluginSourceSignature(pd), cmd.OutOrStdout())
+				return nil
+			}
+
+			if showVersions {
+				versions, err := pluginmanager.DescribePluginVersions(pd.Name, pd.Target)
+				if err != nil {
+					return err
+				}
+				displayPluginDescriptionWithVersions(pd, versions, cmd.OutOrStdout())
+				return nil
+			}
+
+			columns, values := withPluginLinks(
+				[]string{"name", "version", "status", "target", "description", "vendor", "publisher", "installationPath"},
+				[]interface{}{pd.Name, pd.Version, pd.Status, pd.Target, pd.Description, pd.Vendor, pd.Publisher, pd.InstallationPath},
+				pluginmanager.GetPluginLinks(pd))
+			output := component.NewOutputWriterWithOptions(cmd.OutOrStdout(), outputFormat, []component.OutputWriterOption{}, columns...)
+			output.AddRow(values...)
 			output.Render()
 			return nil
 		},
@@ -198,6 +624,211 @@ func newDescribePluginCmd() *cobra.Command {
 	return describeCmd
 }
 
+// withPluginLinks adds the URLs of the web pages about a plugin to the columns
+// and the values describing the plugin, omitting the URLs that are empty
+func withPluginLinks(columns []string, values []interface{}, links pluginmanager.PluginLinks) ([]string, []interface{}) {
+	for _, link := range []struct{ column, url string }{
+		{"homepage", links.Homepage},
+		{"documentation", links.Documentation},
+		{"issueTracker", links.IssueTracker},
+	} {
+		if link.url != "" {
+			columns = append(columns, link.column)
+			values = append(values, link.url)
+		}
+	}
+	return columns, values
+}
+
+// markDeprecatedPlugins adds the deprecation marker to the status of the
+// described plugins which are deprecated and warns about them
+func markDeprecatedPlugins(pds []*cli.PluginInfo) {
+	plugins := make([]cli.PluginInfo, 0, len(pds))
+	for _, pd := range pds {
+		plugins = append(plugins, *pd)
+	}
+	deprecations := pluginmanager.GetPluginsDeprecation(plugins)
+	for _, pd := range pds {
+		if message, deprecated := deprecations[catalog.PluginNameTarget(pd.Name, pd.Target)]; deprecated {
+			pd.Status = withDeprecationMarker(pd.Status)
+			if message != "" {
+				log.Warningf("Plugin '%v:%v' is deprecated: %v", pd.Name, pd.Version, message)
+			}
+		}
+	}
+}
+
+// displayPluginDescriptionsForAllTargets shows the description of a plugin for each
+// of its targets, in a section per target for the table format
+func displayPluginDescriptionsForAllTargets(pds []cli.PluginInfo, writer io.Writer) {
+	described := make([]*cli.PluginInfo, 0, len(pds))
+	for i := range pds {
+		described = append(described, &pds[i])
+	}
+	markDeprecatedPlugins(described)
+
+	if outputFormat != "" && outputFormat != string(component.TableOutputType) {
+		output := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "name", "version", "status", "target", "description", "vendor", "publisher", "installationPath")
+		for _, pd := range described {
+			output.AddRow(pd.Name, pd.Version, pd.Status, pd.Target, pd.Description, pd.Vendor, pd.Publisher, pd.InstallationPath)
+		}
+		output.Render()
+		return
+	}
+
+	for i, pd := range described {
+		if i > 0 {
+			fmt.Fprintln(writer)
+		}
+		fmt.Fprintf(writer, "Target: %s\n", pd.Target)
+		output := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "name", "version", "status", "description", "vendor", "publisher", "installationPath")
+		output.AddRow(pd.Name, pd.Version, pd.Status, pd.Description, pd.Vendor, pd.Publisher, pd.InstallationPath)
+		output.Render()
+	}
+}
+
+// displayPluginVersionDescription shows the description of a specific version of a plugin
+// as found in the discovery sources, along with its supported platforms
+func displayPluginVersionDescription(pvd *pluginmanager.PluginVersionDescription, writer io.Writer) {
+	if outputFormat != "" && outputFormat != string(component.TableOutputType) {
+		component.NewObjectWriter(writer, outputFormat, pvd).Render()
+		return
+	}
+
+	columns, values := withPluginLinks(
+		[]string{"name", "version", "status", "target", "description", "vendor", "publisher"},
+		[]interface{}{pvd.Name, pvd.Version, pvd.Status, pvd.Target, pvd.Description, pvd.Vendor, pvd.Publisher},
+		pvd.PluginLinks)
+	output := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, columns...)
+	output.AddRow(values...)
+	output.Render()
+	fmt.Fprintln(writer)
+
+	artifactsOutput := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "platform", "digest", "size")
+	for _, a := range pvd.Artifacts {
+		artifactsOutput.AddRow(fmt.Sprintf("%s/%s", a.OS, a.Arch), a.Digest, formatPluginSize(a.Size))
+	}
+	artifactsOutput.Render()
+}
+
+func displayPluginAvailability(availability *pluginmanager.PluginAvailability, writer io.Writer) {
+	if outputFormat != "" && outputFormat != string(component.TableOutputType) {
+		component.NewObjectWriter(writer, outputFormat, availability).Render()
+		return
+	}
+
+	output := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "name", "version", "target", "platform", "artifact", "availability")
+	output.AddRow(availability.Name, availability.Version, availability.Target, availability.Platform, availability.Artifact, availability.Status)
+	output.Render()
+	if availability.Reason != "" {
+		fmt.Fprintf(writer, "\nThe plugin cannot be downloaded: %s\n", availability.Reason)
+	}
+}
+
+func displayPluginDescriptionWithVersions(pd *cli.PluginInfo, versions []pluginmanager.PluginVersionInfo, writer io.Writer) {
+	// For the table format, the versions are shown in a second table
+	// with one row per platform
+	if outputFormat == "" || outputFormat == string(component.TableOutputType) {
+		output := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "name", "version", "status", "target", "description", "vendor", "publisher", "installationPath")
+		output.AddRow(pd.Name, pd.Version, pd.Status, pd.Target, pd.Description, pd.Vendor, pd.Publisher, pd.InstallationPath)
+		output.Render()
+		fmt.Fprintln(writer)
+
+		versionsOutput := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "version", "platform", "digest", "size")
+		for i := range versions {
+			if len(versions[i].Artifacts) == 0 {
+				versionsOutput.AddRow(versions[i].Version, "", "", "")
+			}
+			for j, a := range versions[i].Artifacts {
+				v := ""
+				if j == 0 {
+					v = versions[i].Version
+				}
+				versionsOutput.AddRow(v, fmt.Sprintf("%s/%s", a.OS, a.Arch), a.Digest, formatPluginSize(a.Size))
+			}
+		}
+		versionsOutput.Render()
+		return
+	}
+
+	// Create a specific object format so the versions are printed
+	// as a nested structure in yaml or json
+	type describedPlugin struct {
+		Name             string                            `json:"name" yaml:"name"`
+		Version          string                            `json:"version" yaml:"version"`
+		Status           string                            `json:"status" yaml:"status"`
+		Target           string                            `json:"target" yaml:"target"`
+		Description      string                            `json:"description" yaml:"description"`
+		Vendor           string                            `json:"vendor" yaml:"vendor"`
+		Publisher        string                            `json:"publisher" yaml:"publisher"`
+		InstallationPath string                            `json:"installationPath" yaml:"installationPath"`
+		Versions         []pluginmanager.PluginVersionInfo `json:"versions" yaml:"versions"`
+	}
+	details := describedPlugin{
+		Name:             pd.Name,
+		Version:          pd.Version,
+		Status:           pd.Status,
+		Target:           string(pd.Target),
+		Description:      pd.Description,
+		Vendor:           pd.Vendor,
+		Publisher:        pd.Publisher,
+		InstallationPath: pd.InstallationPath,
+		Versions:         versions,
+	}
+	component.NewObjectWriter(writer, outputFormat, details).Render()
+}
+
+// displayPluginDescriptionWithSignature shows the description of an installed plugin along
+// with the details of the signature verification of the discovery image it comes from
+func displayPluginDescriptionWithSignature(pd *cli.PluginInfo, verification *pluginmanager.PluginSignatureVerification, writer io.Writer) {
+	if outputFormat == "" || outputFormat == string(component.TableOutputType) {
+		output := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "name", "version", "status", "target", "description", "vendor", "publisher", "installationPath")
+		output.AddRow(pd.Name, pd.Version, pd.Status, pd.Target, pd.Description, pd.Vendor, pd.Publisher, pd.InstallationPath)
+		output.Render()
+		fmt.Fprintln(writer)
+
+		// The transparency log is ignored when verifying signatures so the
+		// inclusion of the signature in the log is never confirmed
+		transparencyLog := "no inclusion bundle"
+		if verification.TransparencyLogBundle {
+			transparencyLog = "inclusion bundle present, not verified"
+		}
+		signatureOutput := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "source", "image", "signature", "digest", "signer", "issuer", "transparencyLog")
+		signatureOutput.AddRow(verification.Source, verification.Image, verification.Status, verification.Digest, verification.Signer, verification.Issuer, transparencyLog)
+		signatureOutput.Render()
+		if verification.Reason != "" {
+			fmt.Fprintf(writer, "\nThe signature was not verified: %s\n", verification.Reason)
+		}
+		return
+	}
+
+	// Create a specific object format so the signature is printed
+	// as a nested structure in yaml or json
+	type describedPlugin struct {
+		Name             string                                     `json:"name" yaml:"name"`
+		Version          string                                     `json:"version" yaml:"version"`
+		Status           string                                     `json:"status" yaml:"status"`
+		Target           string                                     `json:"target" yaml:"target"`
+		Description      string                                     `json:"description" yaml:"description"`
+		Vendor           string                                     `json:"vendor" yaml:"vendor"`
+		Publisher        string                                     `json:"publisher" yaml:"publisher"`
+		InstallationPath string                                     `json:"installationPath" yaml:"installationPath"`
+		Signature        *pluginmanager.PluginSignatureVerification `json:"signature" yaml:"signature"`
+	}
+	details := describedPlugin{
+		Name:             pd.Name,
+		Version:          pd.Version,
+		Status:           pd.Status,
+		Target:           string(pd.Target),
+		Description:      pd.Description,
+		Vendor:           pd.Vendor,
+		Publisher:        pd.Publisher,
+		InstallationPath: pd.InstallationPath,
+		Signature:        verification,
+	}
+	component.NewObjectWriter(writer, outputFormat, details).Render()
+}
+
 func newInstallPluginCmd() *cobra.Command {
 	var installCmd = &cobra.Command{
 		Use:   "install [" + pluginNameCaps + "]",
@@ -216,10 +847,29 @@ func newInstallPluginCmd() *cobra.Command {
     # Install all plugins from the latest patch of the v1.2 version of the vmware-tkg/default plugin group
     tanzu plugin install --group vmware-tkg/default:v1.2
 
+    # Install all plugins of the vmware-tkg/default plugin group except plugins "plugin1" and "plugin2"
+    tanzu plugin install --group vmware-tkg/default --exclude plugin1,plugin2
+
+    # Install only plugins "plugin1" and "plugin2" of the vmware-tkg/default plugin group
+    tanzu plugin install --group vmware-tkg/default --only plugin1,plugin2
+
+    # Install the version of plugin "myPlugin" specified by the vmware-tkg/default plugin group version v2.1.0
+    tanzu plugin install myPlugin --from-group vmware-tkg/default:v2.1.0
+
+    # Install the version of plugin "myPlugin" whose binary has the specified digest
+    tanzu plugin install myPlugin --digest sha256:5b9e1bb6e3fa9ae9b6c4a3e4f4b4a5e1d2c3b4a5968778695a4b3c2d1e0f9a8b
+
+    # Install the latest version of plugin "myPlugin" to the directory /opt/tanzu/plugins
+    tanzu plugin install myPlugin --install-dir /opt/tanzu/plugins
+
     # Install the latest version of plugin "myPlugin"
     # If the plugin exists for more than one target, an error will be thrown
+    # Pre-release versions (e.g. v1.1.0-rc.1) are not considered the latest version
     tanzu plugin install myPlugin
 
+    # Install the latest version of plugin "myPlugin", including pre-release versions
+    tanzu plugin install myPlugin --include-prerelease
+
     # Install the latest version of plugin "myPlugin" for target kubernetes
     tanzu plugin install myPlugin --target k8s
 
@@ -230,10 +880,35 @@ func newInstallPluginCmd() *cobra.Command {
     tanzu plugin install myPlugin --version v1.0
 
     # Install latest minor and patch version of v1 of plugin "myPlugin"
-    tanzu plugin install myPlugin --version v1`,
+    tanzu plugin install myPlugin --version v1
+
+    # Show the plugins, including dependencies, that would be installed for plugin "myPlugin"
+    tanzu plugin install myPlugin --dry-run
+
+    # Install plugin "myPlugin" after confirming the signature of the plugin discovery images
+    tanzu plugin install myPlugin --wait-verify
+
+    # Install a pre-built plugin binary directly, without using the discovery sources
+    tanzu plugin install --binary ./bin/tanzu-plugin-myPlugin
+
+    # Download and install version v1.0.0 of plugin "myPlugin" again even if it is already installed
+    tanzu plugin install myPlugin --version v1.0.0 --reinstall
+
+    # Install plugin "myPlugin" and describe the installed plugin in JSON
+    tanzu plugin install myPlugin -o json
+
+    # Install plugin "myPlugin" without running its post-install command
+    tanzu plugin install myPlugin --skip-post-install
+
+    # Install the linux/arm64 binary of plugin "myPlugin", which must be able to run on this machine (e.g., through emulation)
+    tanzu plugin install myPlugin --platform linux/arm64`,
 		Args:              cobra.MaximumNArgs(1),
 		ValidArgsFunction: completeAllPluginsToInstall,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// Clean up a partial installation if the user interrupts the command
+			defer interrupt.HandleSignals()()
+			defer refreshCompletionOnPluginChanges(cmd)()
+
 			var err error
 			var pluginName string
 
@@ -241,9 +916,41 @@ func newInstallPluginCmd() *cobra.Command {
 				return errors.New(invalidTargetMsg)
 			}
 
+			if waitVerify {
+				if err = verifyDiscoverySourcesSignature(cmd.OutOrStdout()); err != nil {
+					return err
+				}
+			}
+
+			if err = validateInstallOutputFormat(); err != nil {
+				return err
+			}
+
+			if platform != "" {
+				arch, err := cli.ParsePlatform(platform)
+				if err != nil {
+					return err
+				}
+				// Select the plugin binaries of the specified platform
+				// for the duration of the installation
+				currentArch := cli.BuildArch()
+				cli.SetArch(arch)
+				defer cli.SetArch(currentArch)
+			}
+
 			if group != "" {
 				return installPluginsForPluginGroup(cmd, args)
 			}
+			if len(groupExclude) > 0 || len(groupOnly) > 0 {
+				return errors.New("the '--exclude' and '--only' flags can only be used with the '--group' flag")
+			}
+
+			if binaryPath != "" {
+				if len(args) != 0 {
+					return errors.New("the plugin name cannot be specified when using the '--binary' flag")
+				}
+				return installPluginFromBinary(cmd.OutOrStdout())
+			}
 
 			// Invoke install plugin from local source if local files are provided
 			if local != "" {
@@ -257,14 +964,18 @@ func newInstallPluginCmd() *cobra.Command {
 				if err != nil {
 					return err
 				}
-				err = pluginmanager.InstallPluginsFromLocalSource(pluginName, version, getTarget(), local, false)
+				results, err := pluginmanager.InstallPluginsFromLocalSourceWithResults(pluginName, version, getTarget(), local, false, pluginmanager.WithSkipPostInstall(skipPostInstall))
 				if err != nil {
 					return err
 				}
+				if outputFormat != "" {
+					component.NewObjectWriter(cmd.OutOrStdout(), outputFormat, results).Render()
+					return nil
+				}
 				if pluginName == cli.AllPlugins {
-					log.Success("successfully installed all plugins")
+					clilog.Success("successfully installed all plugins")
 				} else {
-					log.Successf("successfully installed '%s' plugin", pluginName)
+					clilog.Successf("successfully installed '%s' plugin", pluginName)
 				}
 				return nil
 			}
@@ -279,17 +990,89 @@ func newInstallPluginCmd() *cobra.Command {
 			}
 
 			pluginVersion := version
-			err = pluginmanager.InstallStandalonePlugin(pluginName, pluginVersion, getTarget())
+			target := getTarget()
+			if fromGroup != "" {
+				pluginVersion, target, err = pluginmanager.GetPluginVersionFromGroup(pluginName, target, fromGroup)
+				if err != nil {
+					return err
+				}
+				clilog.Infof("Installing version '%s' of plugin '%s' as specified by plugin group '%s'", pluginVersion, pluginName, fromGroup)
+			}
+			if dryRun {
+				return displayPluginsToInstall(cmd.OutOrStdout(), pluginName, pluginVersion, target)
+			}
+			result, err := pluginmanager.InstallStandalonePluginWithResult(pluginName, pluginVersion, target, pluginmanager.WithIncludePrerelease(includePrerelease), pluginmanager.WithReinstall(reinstall), pluginmanager.WithSkipPostInstall(skipPostInstall), pluginmanager.WithInstallDir(installDir), pluginmanager.WithForce(forceInstall), pluginmanager.WithDigest(installDigest))
 			if err != nil {
 				return err
 			}
-			log.Successf("successfully installed '%s' plugin", pluginName)
+			if outputFormat != "" {
+				component.NewObjectWriter(cmd.OutOrStdout(), outputFormat, result).Render()
+				return nil
+			}
+			if result.AlreadyInstalled && !reinstall {
+				clilog.Successf("plugin '%s' version '%s' is already installed", result.Name, result.Version)
+				return nil
+			}
+			clilog.Successf("successfully installed '%s' plugin version '%s'", result.Name, result.Version)
 			return nil
 		},
 	}
 	return installCmd
 }
 
+// validateInstallOutputFormat checks the --output flag of the install command, which
+// replaces the success messages with the description of the installed plugins
+func validateInstallOutputFormat() error {
+	switch outputFormat {
+	case "", string(component.JSONOutputType), string(component.YAMLOutputType):
+		return nil
+	}
+	return errors.Errorf("invalid output format '%s' for the installed plugins, valid formats are: json, yaml", outputFormat)
+}
+
+// installPluginFromBinary installs the plugin binary specified with the --binary flag.
+func installPluginFromBinary(writer io.Writer) error {
+	path, err := filepath.Abs(binaryPath)
+	if err != nil {
+		return err
+	}
+	result, err := pluginmanager.InstallPluginFromBinary(path, getTarget(), pluginmanager.WithSkipPostInstall(skipPostInstall))
+	if err != nil {
+		return err
+	}
+	if outputFormat != "" {
+		component.NewObjectWriter(writer, outputFormat, result).Render()
+		return nil
+	}
+	clilog.Successf("successfully installed '%s' plugin version '%s'", result.Name, result.Version)
+	return nil
+}
+
+// verifyDiscoverySourcesSignature verifies the signature of the plugin discovery images
+// and prints the result for each of them.  The result is printed even in quiet mode
+// as it serves as an audit record of the verification.
+func verifyDiscoverySourcesSignature(writer io.Writer) error {
+	verifications, err := pluginmanager.VerifyDiscoverySourcesSignature()
+	if err != nil {
+		return err
+	}
+	for _, v := range verifications {
+		if v.Result == nil {
+			fmt.Fprintf(writer, "signature verification skipped for %s\n", v.Image)
+			continue
+		}
+		msg := fmt.Sprintf("signature verified for %s", v.Image)
+		if v.Result.Digest != "" {
+			msg += fmt.Sprintf(" (digest %s)", v.Result.Digest)
+		}
+		if v.Result.Signer != "" {
+			msg += fmt.Sprintf(", signed by %s", v.Result.Signer)
+		}
+		fmt.Fprintln(writer, msg)
+	}
+	return nil
+}
+
 func installPluginsForPluginGroup(cmd *cobra.Command, args []string) error {
 	var pluginName string
 	// We are installing from a group
@@ -299,54 +1082,117 @@ func installPluginsForPluginGroup(cmd *cobra.Command, args []string) error {
 	} else {
 		pluginName = args[0]
 	}
+	if pluginName != cli.AllPlugins && (len(groupExclude) > 0 || len(groupOnly) > 0) {
+		return errors.New("the '--exclude' and '--only' flags can only be used when installing all the plugins of a group")
+	}
 
+	var results []*pluginmanager.InstallResult
 	if pluginName == cli.AllPlugins {
 		pg, err := pluginmanager.GetPluginGroup(group)
 		if err != nil {
 			return err
 		}
 		groupIDAndVersion := fmt.Sprintf("%s-%s/%s:%s", pg.Vendor, pg.Publisher, pg.Name, pg.RecommendedVersion)
-		log.Infof("The following plugins will be installed from plugin group '%s'", groupIDAndVersion)
+		clilog.Infof("The following plugins will be installed from plugin group '%s'", groupIDAndVersion)
 		// list plugins if we are installing all plugins from the plugin group
 		displayGroupContentAsTable(pg, pg.RecommendedVersion, "", false, false, cmd.ErrOrStderr())
-		groupWithVersion, err := pluginmanager.InstallPluginsFromGivenPluginGroup(pluginName, groupIDAndVersion, pg)
+		groupWithVersion, groupResults, err := pluginmanager.InstallPluginsFromGivenPluginGroupWithResults(pluginName, groupIDAndVersion, pg, pluginmanager.WithSkipPostInstall(skipPostInstall), pluginmanager.WithGroupMemberFilter(groupOnly, groupExclude), pluginmanager.WithForce(forceInstall))
 		if err != nil {
 			return err
 		}
-		log.Successf("successfully installed all plugins from group '%s'", groupWithVersion)
+		results = groupResults
+		if outputFormat == "" {
+			if len(groupExclude) > 0 || len(groupOnly) > 0 {
+				clilog.Successf("successfully installed the selected plugins from group '%s'", groupWithVersion)
+			} else {
+				clilog.Successf("successfully installed all plugins from group '%s'", groupWithVersion)
+			}
+		}
 	} else {
-		groupWithVersion, err := pluginmanager.InstallPluginsFromGroup(pluginName, group)
+		groupWithVersion, groupResults, err := pluginmanager.InstallPluginsFromGroupWithResults(pluginName, group, pluginmanager.WithSkipPostInstall(skipPostInstall), pluginmanager.WithForce(forceInstall))
 		if err != nil {
 			return err
 		}
-		log.Successf("successfully installed '%s' from group '%s'", pluginName, groupWithVersion)
+		results = groupResults
+		if outputFormat == "" {
+			clilog.Successf("successfully installed '%s' from group '%s'", pluginName, groupWithVersion)
+		}
 	}
+
+	if outputFormat != "" {
+		component.NewObjectWriter(cmd.OutOrStdout(), outputFormat, results).Render()
+	}
+	return nil
+}
+
+// displayPluginsToInstall shows the plugins, including any dependency, that
+// would be installed for the specified plugin.
+func displayPluginsToInstall(writer io.Writer, pluginName, pluginVersion string, target configtypes.Target) error {
+	plugins, err := pluginmanager.ResolvePluginDependencies(pluginName, pluginVersion, target, pluginmanager.WithIncludePrerelease(includePrerelease))
+	if err != nil {
+		return err
+	}
+
+	output := component.NewOutputWriterWithOptions(writer, "", []component.OutputWriterOption{}, "Name", "Target", "Version")
+	for _, p := range plugins {
+		output.AddRow(p.Name, string(p.Target), p.Version)
+	}
+	output.Render()
 	return nil
 }
 
 func newUpgradePluginCmd() *cobra.Command {
 	var upgradeCmd = &cobra.Command{
-		Use:               "upgrade " + pluginNameCaps,
-		Short:             "Upgrade a plugin",
-		Long:              "Installs the latest version available for the specified plugin",
+		Use:   "upgrade " + pluginNameCaps,
+		Short: "Upgrade a plugin",
+		Long:  "Installs the latest version available for the specified plugin. Pre-release versions are only considered when --include-prerelease is specified or when the plugin has no other version.",
+		Example: `
+    # Upgrade plugin "myPlugin" to its latest version
+    tanzu plugin upgrade myPlugin
+
+    # Show the installed version of plugin "myPlugin" and the version it would be upgraded to
+    tanzu plugin upgrade myPlugin --dry-run
+
+    # Show what upgrading all the installed standalone plugins would do, in JSON
+    tanzu plugin upgrade --all --dry-run -o json
+
+    # Upgrade all the installed standalone plugins of the kubernetes target
+    tanzu plugin upgrade --all --target k8s`,
 		ValidArgsFunction: completeAllPluginsToInstall,
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			if len(args) != 1 {
+			defer interrupt.HandleSignals()()
+			defer refreshCompletionOnPluginChanges(cmd)()
+
+			if upgradeAll {
+				if len(args) != 0 {
+					return errors.New("the --all flag cannot be used with a plugin name")
+				}
+			} else if len(args) != 1 {
 				return fmt.Errorf("must provide plugin name as positional argument")
 			}
-			pluginName := args[0]
 
 			if !configtypes.IsValidTarget(targetStr, true, true) {
 				return errors.New(invalidTargetMsg)
 			}
+			if outputFormat != "" && !dryRun {
+				return errors.New("the --output flag can only be used with --dry-run")
+			}
+
+			if dryRun {
+				return displayUpgradePlan(cmd.OutOrStdout(), args)
+			}
+			if upgradeAll {
+				return upgradeAllPlugins()
+			}
+			pluginName := args[0]
 
 			// With the Central Repository feature we can simply request to install
 			// the recommendedVersion.
-			err = pluginmanager.UpgradePlugin(pluginName, cli.VersionLatest, getTarget())
+			err = pluginmanager.UpgradePlugin(pluginName, cli.VersionLatest, getTarget(), pluginmanager.WithIncludePrerelease(includePrerelease))
 			if err != nil {
 				return err
 			}
-			log.Successf("successfully upgraded plugin '%s'", pluginName)
+			clilog.Successf("successfully upgraded plugin '%s'", pluginName)
 			return nil
 		},
 	}
@@ -354,15 +1200,121 @@ func newUpgradePluginCmd() *cobra.Command {
 	return upgradeCmd
 }
 
-func newDeletePluginCmd() *cobra.Command {
-	var deleteCmd = &cobra.Command{
-		Use:               "uninstall " + pluginNameCaps,
-		Aliases:           []string{"delete"},
-		Short:             "Uninstall a plugin",
-		Long:              "Uninstall the specified plugin or specify 'all' to uninstall all plugins of a target",
-		ValidArgsFunction: completeDeletePlugin,
-		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			if len(args) != 1 {
+// displayUpgradePlan shows, for the specified plugin or for all the installed standalone
+// plugins, the installed version and the version 'plugin upgrade' would install
+func displayUpgradePlan(writer io.Writer, args []string) error {
+	var plan []*pluginmanager.UpgradePlanEntry
+	var err error
+	if upgradeAll {
+		plan, err = pluginmanager.PlanAllPluginsUpgrade(getTarget(), pluginmanager.WithIncludePrerelease(includePrerelease))
+		if err != nil {
+			// Show the plan of the plugins that could be found
+			log.Warningf(errorWhileDiscoveringPlugins, err.Error())
+		}
+	} else {
+		var entry *pluginmanager.UpgradePlanEntry
+		entry, err = pluginmanager.PlanPluginUpgrade(args[0], getTarget(), pluginmanager.WithIncludePrerelease(includePrerelease))
+		if err != nil {
+			return err
+		}
+		plan = append(plan, entry)
+	}
+
+	if outputFormat != "" && outputFormat != string(component.TableOutputType) {
+		component.NewObjectWriter(writer, outputFormat, plan).Render()
+		return nil
+	}
+
+	output := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "Name", "Target", "Installed", "Version", "Action")
+	for _, entry := range plan {
+		output.AddRow(entry.Name, string(entry.Target), entry.InstalledVersion, entry.Version, entry.Action)
+	}
+	output.Render()
+	return nil
+}
+
+// upgradeAllPlugins upgrades the installed standalone plugins for which a newer version is available
+func upgradeAllPlugins() error {
+	plan, err := pluginmanager.PlanAllPluginsUpgrade(getTarget(), pluginmanager.WithIncludePrerelease(includePrerelease))
+	errorList := make([]error, 0)
+	if err != nil {
+		errorList = append(errorList, err)
+	}
+
+	upgraded := 0
+	for _, entry := range plan {
+		if entry.Action != pluginmanager.UpgradeActionUpgrade {
+			continue
+		}
+		if err := pluginmanager.UpgradePlugin(entry.Name, cli.VersionLatest, entry.Target, pluginmanager.WithIncludePrerelease(includePrerelease)); err != nil {
+			errorList = append(errorList, err)
+			continue
+		}
+		clilog.Successf("successfully upgraded plugin '%s' from version '%s' to version '%s'", entry.Name, entry.InstalledVersion, entry.Version)
+		upgraded++
+	}
+	if upgraded == 0 && len(errorList) == 0 {
+		clilog.Success("all plugins are already at their latest version")
+	}
+	return kerrors.NewAggregate(errorList)
+}
+
+func newReinstallPluginCmd() *cobra.Command {
+	var reinstallCmd = &cobra.Command{
+		Use:   "reinstall " + pluginNameCaps,
+		Short: "Reinstall a plugin",
+		Long: `Uninstalls the specified standalone plugin and installs the same version again,
+downloading its binary anew, e.g., to recover a corrupt plugin binary.
+The recommended version is installed if the plugin is not installed.
+If the installation fails, the previously installed plugin is restored.`,
+		Example: `
+    # Reinstall the installed version of plugin "myPlugin"
+    tanzu plugin reinstall myPlugin
+
+    # Reinstall plugin "myPlugin" of the kubernetes target without asking for confirmation
+    tanzu plugin reinstall myPlugin --target k8s --yes`,
+		ValidArgsFunction: completeAllPluginsToInstall,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			defer interrupt.HandleSignals()()
+			defer refreshCompletionOnPluginChanges(cmd)()
+
+			if len(args) != 1 {
+				return fmt.Errorf("must provide one plugin name as a positional argument")
+			}
+			pluginName := args[0]
+			if pluginName == cli.AllPlugins {
+				return fmt.Errorf("the '%s' argument cannot be used to reinstall plugins", cli.AllPlugins)
+			}
+			if !configtypes.IsValidTarget(targetStr, true, true) {
+				return errors.New(invalidTargetMsg)
+			}
+
+			result, err := pluginmanager.ReinstallPlugin(pluginmanager.ReinstallPluginOptions{
+				PluginName:     pluginName,
+				Target:         getTarget(),
+				ForceReinstall: forceDelete,
+			}, pluginmanager.WithSkipPostInstall(skipPostInstall))
+			if err != nil {
+				return err
+			}
+			clilog.Successf("successfully reinstalled version '%s' of plugin '%s'", result.Version, result.Name)
+			return nil
+		},
+	}
+	return reinstallCmd
+}
+
+func newDeletePluginCmd() *cobra.Command {
+	var deleteCmd = &cobra.Command{
+		Use:               "uninstall " + pluginNameCaps,
+		Aliases:           []string{"delete"},
+		Short:             "Uninstall a plugin",
+		Long:              "Uninstall the specified plugin or specify 'all' to uninstall all plugins of a target",
+		ValidArgsFunction: completeDeletePlugin,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			defer refreshCompletionOnPluginChanges(cmd)()
+
+			if len(args) != 1 {
 				return fmt.Errorf("must provide one plugin name as a positional argument")
 			}
 			pluginName := args[0]
@@ -382,6 +1334,7 @@ func newDeletePluginCmd() *cobra.Command {
 				PluginName:  pluginName,
 				Target:      target,
 				ForceDelete: forceDelete,
+				PruneCache:  pruneCache,
 			}
 
 			err = pluginmanager.DeletePlugin(deletePluginOptions)
@@ -390,9 +1343,9 @@ func newDeletePluginCmd() *cobra.Command {
 			}
 
 			if pluginName == cli.AllPlugins {
-				log.Successf("successfully uninstalled all plugins of target '%s'", target)
+				clilog.Successf("successfully uninstalled all plugins of target '%s'", target)
 			} else {
-				log.Successf("successfully uninstalled plugin '%s'", pluginName)
+				clilog.Successf("successfully uninstalled plugin '%s'", pluginName)
 			}
 			return nil
 		},
@@ -402,67 +1355,291 @@ func newDeletePluginCmd() *cobra.Command {
 
 func newCleanPluginCmd() *cobra.Command {
 	var cleanCmd = &cobra.Command{
-		Use:               "clean",
-		Short:             "Clean the plugins",
-		Long:              "Remove all installed plugins from the system",
+		Use:   "clean",
+		Short: "Clean the plugins",
+		Long: `Remove all installed plugins from the system.
+Use --target or --source to only remove the installed plugins of a target or
+installed from a discovery source, leaving the other plugins installed.
+Removing all installed plugins also removes the cached plugin inventories;
+otherwise, use --prune-cache to remove those no longer used.`,
+		Example: `
+    # Remove all installed plugins
+    tanzu plugin clean
+
+    # Remove the plugins of the kubernetes target without asking for confirmation
+    tanzu plugin clean --target k8s --yes
+
+    # Remove the plugins installed from the 'default' discovery source
+    tanzu plugin clean --source default
+
+    # Also remove the cached plugin inventories no longer used
+    tanzu plugin clean --source default --prune-cache`,
 		ValidArgsFunction: noMoreCompletions,
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			err = pluginmanager.Clean()
+			if targetStr == "" && cleanSource == "" {
+				err = pluginmanager.Clean()
+				if err != nil {
+					return err
+				}
+				clilog.Success("successfully cleaned up all plugins")
+				return nil
+			}
+
+			if !configtypes.IsValidTarget(targetStr, true, true) {
+				return errors.New(invalidTargetMsg)
+			}
+			target := getTarget()
+
+			if !forceDelete {
+				if err := component.AskForConfirmation(getCleanConfirmationMessage(target, cleanSource)); err != nil {
+					return err
+				}
+			}
+
+			err = pluginmanager.Clean(pluginmanager.WithTarget(target), pluginmanager.WithDiscoverySource(cleanSource), pluginmanager.WithPruneCache(pruneCache))
 			if err != nil {
 				return err
 			}
-			log.Success("successfully cleaned up all plugins")
+			clilog.Success("successfully cleaned up the matching plugins")
 			return nil
 		},
 	}
+
+	cleanCmd.Flags().StringVarP(&targetStr, "target", "t", "", fmt.Sprintf("only remove the installed plugins of this target (%s)", common.TargetList))
+	utils.PanicOnErr(cleanCmd.RegisterFlagCompletionFunc("target", completeTargetsForInstalledPlugins))
+	cleanCmd.Flags().StringVar(&cleanSource, "source", "", "only remove the plugins installed from this discovery source")
+	cleanCmd.Flags().BoolVarP(&forceDelete, "yes", "y", false, "remove the plugins without asking for confirmation")
+	cleanCmd.Flags().BoolVar(&pruneCache, "prune-cache", false, "with --target or --source, also remove the cached plugin inventories no longer used by any installed plugin or configured discovery source")
+
 	return cleanCmd
 }
 
+// getCleanConfirmationMessage returns the question asked before a clean
+// restricted to a target and/or a discovery source
+func getCleanConfirmationMessage(target configtypes.Target, source string) string {
+	switch {
+	case target != configtypes.TargetUnknown && source != "":
+		return fmt.Sprintf("All plugins for target '%s' installed from discovery source '%s' will be removed. Are you sure?", string(target), source)
+	case target != configtypes.TargetUnknown:
+		return fmt.Sprintf("All plugins for target '%s' will be removed. Are you sure?", string(target))
+	default:
+		return fmt.Sprintf("All plugins installed from discovery source '%s' will be removed. Are you sure?", source)
+	}
+}
+
 func newSyncPluginCmd() *cobra.Command {
 	var syncCmd = &cobra.Command{
 		Use:   "sync",
 		Short: "Installs all plugins recommended by the active contexts",
 		Long: `Installs all plugins recommended by the active contexts.
-Plugins installed with this command will only be available while the context remains active.`,
+Plugins installed with this command will only be available while the context remains active.
+Plugins pinned through the TANZU_CLI_PINNED_PLUGIN_VERSIONS variable, a comma separated
+list of "<pluginName>[:<target>]=<version>", are synced to their pinned version instead
+of the recommended version.`,
+		Example: `
+    # Install all plugins recommended by the active contexts
+    tanzu plugin sync
+
+    # Show the plugins that would be installed or upgraded, without installing them
+    tanzu plugin sync --dry-run
+
+    # Show the same information in JSON
+    tanzu plugin sync --dry-run -o json
+
+    # Install up to 4 plugins of a context at the same time
+    tanzu plugin sync --concurrency 4
+
+    # Fail without installing anything if any discovery source cannot be fetched
+    tanzu plugin sync --strict
+
+    # Also uninstall the standalone plugins no longer provided by any discovery source
+    tanzu plugin sync --prune
+
+    # Same as above but without asking for confirmation
+    tanzu plugin sync --prune --yes`,
 		ValidArgsFunction: noMoreCompletions,
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if forceDelete && !syncPrune {
+				return errors.New("the --yes flag can only be used with --prune")
+			}
+			if dryRun {
+				return displaySyncPlan(cmd.OutOrStdout())
+			}
+			defer interrupt.HandleSignals()()
+
+			if outputFormat != "" {
+				return errors.New("the --output flag can only be used with --dry-run")
+			}
+			if syncConcurrency < 1 {
+				return errors.New("the value of the --concurrency flag must be at least 1")
+			}
 			err = syncPlugins(cmd)
 			if err != nil {
 				return err
 			}
-			log.Success("Done")
+			if syncPrune {
+				if err := pruneOrphanedPlugins(); err != nil {
+					return err
+				}
+			}
+			clilog.Success("Done")
 			return nil
 		},
 	}
+	syncCmd.Flags().StringVar(&syncSource, "source", "", "only sync the plugins provided by the specified discovery source of the active contexts")
+	syncCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the plugins that would be added, upgraded or are no longer recommended, without installing them")
+	syncCmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format of --dry-run (yaml|json|table)")
+	utils.PanicOnErr(syncCmd.RegisterFlagCompletionFunc("output", completionGetOutputFormats))
+	syncCmd.Flags().IntVar(&syncConcurrency, "concurrency", 1, "maximum number of plugins of a context to install at the same time")
+	syncCmd.Flags().BoolVar(&syncStrict, "strict", false, "fail without installing any plugin if any discovery source cannot be fetched or verified")
+	syncCmd.MarkFlagsMutuallyExclusive("dry-run", "concurrency")
+	syncCmd.Flags().BoolVar(&syncPrune, "prune", false, "also uninstall the standalone plugins no longer provided by any discovery source")
+	syncCmd.Flags().BoolVarP(&forceDelete, "yes", "y", false, "uninstall the pruned plugins without asking for confirmation")
+	syncCmd.MarkFlagsMutuallyExclusive("dry-run", "strict")
 	return syncCmd
 }
 
-// syncPlugins installs all plugins recommended by the active contexts and lists the plugins it's going to install
+// displaySyncPlan shows what 'plugin sync' would do, comparing the installed plugins
+// with the plugins recommended by the active contexts
+func displaySyncPlan(writer io.Writer) error {
+	plan, err := pluginmanager.PlanSyncPlugins(pluginmanager.WithDiscoverySource(syncSource), pluginmanager.WithPrune(syncPrune))
+	if plan == nil {
+		return err
+	}
+	if err != nil {
+		// Show what could be discovered
+		log.Warningf(errorWhileDiscoveringPlugins, err.Error())
+	}
+
+	if outputFormat != "" && outputFormat != string(component.TableOutputType) {
+		component.NewObjectWriter(writer, outputFormat, plan).Render()
+		return nil
+	}
+
+	output := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "Action", "Name", "Target", "Context", "Installed", "Version")
+	for _, entry := range plan.Add {
+		output.AddRow("add", entry.Name, string(entry.Target), entry.Context, formatSyncPlanInstalledVersion(&entry), entry.Version)
+	}
+	for _, entry := range plan.Upgrade {
+		output.AddRow("upgrade", entry.Name, string(entry.Target), entry.Context, formatSyncPlanInstalledVersion(&entry), entry.Version)
+	}
+	for _, entry := range plan.Remove {
+		output.AddRow("unmanaged", entry.Name, string(entry.Target), entry.Context, formatSyncPlanInstalledVersion(&entry), "")
+	}
+	for _, entry := range plan.Prune {
+		output.AddRow("prune", entry.Name, string(entry.Target), entry.Context, entry.InstalledVersion, "")
+	}
+	output.Render()
+
+	if len(plan.Remove) > 0 {
+		fmt.Fprintln(writer, "")
+		fmt.Fprintln(writer, "Note: 'unmanaged' plugins are no longer recommended by their context. Plugin sync does not uninstall them.")
+	}
+	return nil
+}
+
+// formatSyncPlanInstalledVersion returns the installed version of a plugin of a sync plan,
+// marking the version of a standalone plugin which a context plugin would take precedence over
+func formatSyncPlanInstalledVersion(entry *pluginmanager.SyncPlanEntry) string {
+	if entry.InstalledScope == common.PluginScopeStandalone {
+		return fmt.Sprintf("%s (%s)", entry.InstalledVersion, strings.ToLower(common.PluginScopeStandalone))
+	}
+	return entry.InstalledVersion
+}
+
+func newPrefetchPluginCmd() *cobra.Command {
+	var prefetchCmd = &cobra.Command{
+		Use:   "prefetch",
+		Short: "Download the plugin inventories of the discovery sources into the cache",
+		Long: `Download the plugin inventories of the discovery sources into the cache.
+Running this command ahead of time, for example when building a container image,
+avoids downloading the inventories when other plugin commands are first used.`,
+		Example: `
+    # Download the plugin inventories of all discovery sources
+    tanzu plugin prefetch
+
+    # Download the plugin inventories of all discovery sources concurrently
+    tanzu plugin prefetch --parallel`,
+		ValidArgsFunction: noMoreCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			start := time.Now()
+			results, err := pluginmanager.PrefetchDiscoverySources(prefetchParallel)
+			if err != nil {
+				return err
+			}
+
+			errList := make([]error, 0)
+			output := component.NewOutputWriterWithOptions(cmd.OutOrStdout(), string(component.TableOutputType), []component.OutputWriterOption{}, "Source", "Image", "Result", "Duration")
+			for _, r := range results {
+				result := "prefetched"
+				if r.Err != nil {
+					result = "failed"
+					errList = append(errList, r.Err)
+				}
+				output.AddRow(r.Source, r.Image, result, r.Duration.Round(time.Millisecond).String())
+			}
+			output.Render()
+
+			elapsed := time.Since(start).Round(time.Millisecond)
+			if len(errList) > 0 {
+				clilog.Infof("prefetched %d of %d discovery sources in %v", len(results)-len(errList), len(results), elapsed)
+				return kerrors.NewAggregate(errList)
+			}
+			clilog.Successf("prefetched %d discovery sources in %v", len(results), elapsed)
+			return nil
+		},
+	}
+	prefetchCmd.Flags().BoolVar(&prefetchParallel, "parallel", false, "download the plugin inventories of the discovery sources concurrently")
+	return prefetchCmd
+}
+
+// syncPlugins installs all plugins recommended by the active contexts and lists the plugins it's going to install.
+// The contexts are synced one after the other, in the order of their names.
 func syncPlugins(cmd *cobra.Command) error {
 	contextMap, err := config.GetAllActiveContextsMap()
 	if err != nil {
 		return err
 	}
+	if len(contextMap) == 0 {
+		log.Warning("No active contexts available to perform plugin sync")
+		return nil
+	}
+
+	contextTypes := make([]configtypes.ContextType, 0, len(contextMap))
+	for contextType := range contextMap {
+		contextTypes = append(contextTypes, contextType)
+	}
+	sort.Slice(contextTypes, func(i, j int) bool {
+		return contextMap[contextTypes[i]].Name < contextMap[contextTypes[j]].Name
+	})
+
 	errList := make([]error, 0)
 	contextNames := ""
-	count := 0
-	for _, context := range contextMap {
+	for _, contextType := range contextTypes {
 		if contextNames != "" {
 			contextNames += ", "
 		}
-		contextNames += fmt.Sprintf("'%s'", context.Name)
-		count++
+		contextNames += fmt.Sprintf("'%s'", contextMap[contextType].Name)
 	}
-	if count == 0 {
-		log.Warning("No active contexts available to perform plugin sync")
-		return nil
-	} else if count == 1 {
-		log.Infof("Plugin sync will be performed for context: %s", contextNames)
-	} else if count > 1 {
-		log.Infof("Plugin sync will be performed for contexts: %s", contextNames)
+	if syncSource != "" {
+		if err := pluginmanager.ValidateServerDiscoverySource(syncSource); err != nil {
+			return err
+		}
+		clilog.Infof("Plugin sync is restricted to discovery source '%s'", syncSource)
+	}
+	if syncStrict {
+		if err := pluginmanager.CheckSyncDiscoverySources(pluginmanager.WithDiscoverySource(syncSource)); err != nil {
+			return errors.Wrap(err, "strict plugin sync aborted")
+		}
+	}
+	if len(contextTypes) == 1 {
+		clilog.Infof("Plugin sync will be performed for context: %s", contextNames)
+	} else {
+		clilog.Infof("Plugin sync will be performed for contexts: %s", contextNames)
 	}
-	for contextType, context := range contextMap {
-		err = syncContextPlugins(cmd, contextType, context.Name, true)
+	for _, contextType := range contextTypes {
+		err = syncContextPlugins(cmd, contextType, contextMap[contextType].Name, true,
+			pluginmanager.WithDiscoverySource(syncSource), pluginmanager.WithConcurrency(syncConcurrency))
 		if err != nil {
 			errList = append(errList, err)
 		}
@@ -470,8 +1647,39 @@ func syncPlugins(cmd *cobra.Command) error {
 	return kerrors.NewAggregate(errList)
 }
 
-// getInstalledAndMissingContextPlugins returns any context plugins that are not installed
-func getInstalledAndMissingContextPlugins() (installed, missing []discovery.Discovered, pluginSyncRequired bool, err error) {
+// pruneOrphanedPlugins uninstalls the standalone plugins no longer provided by any
+// discovery source, after asking for confirmation unless --yes was specified
+func pruneOrphanedPlugins() error {
+	orphaned, err := pluginmanager.GetOrphanedStandalonePlugins()
+	if err != nil {
+		return errors.Wrap(err, "plugin prune aborted")
+	}
+	if len(orphaned) == 0 {
+		clilog.Info("No standalone plugin needs to be pruned")
+		return nil
+	}
+
+	names := make([]string, 0, len(orphaned))
+	for i := range orphaned {
+		names = append(names, fmt.Sprintf("'%s' (%s)", orphaned[i].Name, string(orphaned[i].Target)))
+	}
+	if !forceDelete {
+		msg := fmt.Sprintf("The following standalone plugins are no longer provided by any discovery source and will be uninstalled: %s. Are you sure?", strings.Join(names, ", "))
+		if err := component.AskForConfirmation(msg); err != nil {
+			return err
+		}
+	}
+
+	if err := pluginmanager.DeleteStandalonePlugins(orphaned); err != nil {
+		return err
+	}
+	clilog.Successf("Pruned %d standalone plugins: %s", len(orphaned), strings.Join(names, ", "))
+	return nil
+}
+
+// getInstalledAndMissingContextPlugins returns any context plugins that are not installed.
+// Only the plugins of the specified context are returned, if any.
+func getInstalledAndMissingContextPlugins(contextName string) (installed, missing []discovery.Discovered, pluginSyncRequired bool, err error) {
 	errorList := make([]error, 0)
 	serverPlugins, err := pluginmanager.DiscoverServerPlugins()
 	if err != nil {
@@ -481,63 +1689,101 @@ func getInstalledAndMissingContextPlugins() (installed, missing []discovery.Disc
 
 	// Note that the plugins we get here don't know from which context they were installed.
 	// We need to cross-reference them with the discovered plugins.
-	installedPlugins, err := pluginsupplier.GetInstalledServerPlugins()
+	installedPlugins, err := pluginmanager.GetInstalledServerPlugins()
 	if err != nil {
 		errorList = append(errorList, err)
 		log.Warningf(errorWhileGettingContextPlugins, err.Error())
 	}
 
+	pluginmanager.ReconcilePluginsStatus(serverPlugins, installedPlugins)
 	for i := range serverPlugins {
-		found := false
-		for j := range installedPlugins {
-			if serverPlugins[i].Name != installedPlugins[j].Name || serverPlugins[i].Target != installedPlugins[j].Target {
-				continue
-			}
-
-			// Store the installed plugin, which includes the context from which it was installed
-			found = true
-			if serverPlugins[i].RecommendedVersion != installedPlugins[j].Version {
-				serverPlugins[i].Status = common.PluginStatusUpdateAvailable
-				pluginSyncRequired = true
-			} else {
-				serverPlugins[i].Status = common.PluginStatusInstalled
-			}
-			serverPlugins[i].InstalledVersion = installedPlugins[j].Version
-			installed = append(installed, serverPlugins[i])
-			break
+		if contextName != "" && serverPlugins[i].ContextName != contextName {
+			continue
 		}
-		if !found {
-			// We have a server plugin that is not installed, include it in the list
-			serverPlugins[i].Status = common.PluginStatusNotInstalled
+		if serverPlugins[i].IsInstalled() {
+			installed = append(installed, serverPlugins[i])
+		} else {
 			missing = append(missing, serverPlugins[i])
+		}
+		if !serverPlugins[i].IsUpToDate() {
 			pluginSyncRequired = true
 		}
 	}
 	return installed, missing, pluginSyncRequired, kerrors.NewAggregate(errorList)
 }
 
-func displayInstalledAndMissingSplitView(installedStandalonePlugins []cli.PluginInfo, installedContextPlugins, missingContextPlugins []discovery.Discovered, pluginSyncRequired bool, writer io.Writer) {
-	// List installed standalone plugins
+// withDeprecationMarker returns the status of a plugin marked as deprecated
+func withDeprecationMarker(status string) string {
+	return fmt.Sprintf("%s (%s)", status, common.PluginStatusDeprecated)
+}
+
+func withIncompatibleMarker(status string) string {
+	return fmt.Sprintf("%s (%s)", status, common.PluginStatusIncompatible)
+}
+
+func withUnavailableMarker(status string) string {
+	return fmt.Sprintf("%s (%s)", status, common.PluginStatusUnavailable)
+}
+
+// getStandalonePluginStatus returns the status to display for an installed standalone plugin
+func getStandalonePluginStatus(plugin *cli.PluginInfo, status string, deprecations map[string]string, unavailable map[string]bool) string {
+	id := catalog.PluginNameTarget(plugin.Name, plugin.Target)
+	if _, deprecated := deprecations[id]; deprecated {
+		return withDeprecationMarker(status)
+	}
+	if unavailable[id] {
+		return withUnavailableMarker(status)
+	}
+	return status
+}
+
+// getContextPluginStatus returns the status to display for the specified version of a context plugin
+func getContextPluginStatus(plugin *discovery.Discovered, version, status string) string {
+	if deprecated, _ := plugin.GetDeprecation(version); deprecated {
+		return withDeprecationMarker(status)
+	}
+	// The version requires a more recent version of the CLI
+	if compatible, _ := plugin.IsCompatibleWithCLI(version, buildinfo.Version); !compatible {
+		return withIncompatibleMarker(status)
+	}
+	// An outdated plugin already means that the installed version is no longer provided
+	if version == plugin.InstalledVersion && status != common.PluginStatusOutdated && !plugin.IsInstalledVersionAvailable() {
+		return withUnavailableMarker(status)
+	}
+	return status
+}
+
+func displayInstalledAndMissingSplitView(installedStandalonePlugins []cli.PluginInfo, installedContextPlugins, missingContextPlugins []discovery.Discovered, deprecations map[string]string, unavailable map[string]bool, sizes map[string]int64, pluginSyncRequired, wide bool, columns []string, writer io.Writer) {
+	// The wide format is a table with the vendor, publisher and binary size of the plugins as additional columns
+	format := outputFormat
+	if wide {
+		format = string(component.TableOutputType)
+	}
+	if len(columns) == 0 {
+		columns = []string{"name", "description", "target", "version", "status"}
+		if wide {
+			columns = append(columns, "vendor", "publisher", "size")
+		}
+	}
+
+	// List installed standalone plugins, unless only the context plugins were requested
 	cyanBold := color.New(color.FgCyan).Add(color.Bold)
-	_, _ = cyanBold.Println("Standalone Plugins")
+	if !contextOnly {
+		_, _ = cyanBold.Println("Standalone Plugins")
 
-	outputStandalone := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "Name", "Description", "Target", "Version", "Status")
-	for index := range installedStandalonePlugins {
-		outputStandalone.AddRow(
-			installedStandalonePlugins[index].Name,
-			installedStandalonePlugins[index].Description,
-			string(installedStandalonePlugins[index].Target),
-			installedStandalonePlugins[index].Version,
-			common.PluginStatusInstalled,
-		)
+		outputStandalone := newPluginListOutputWriter(writer, format, columns)
+		for index := range installedStandalonePlugins {
+			status := getStandalonePluginStatus(&installedStandalonePlugins[index], common.PluginStatusInstalled, deprecations, unavailable)
+			addPluginListRow(outputStandalone, columns, standalonePluginListRow(&installedStandalonePlugins[index], status, sizes))
+		}
+		outputStandalone.Render()
 	}
-	outputStandalone.Render()
 
 	// List installed and missing context plugins in one list.
 	// First group them by context.
 	contextPlugins := installedContextPlugins
 	contextPlugins = append(contextPlugins, missingContextPlugins...)
-	sort.Sort(discovery.DiscoveredSorter(contextPlugins))
+	sortContextPlugins(contextPlugins)
 
 	ctxPluginsByContext := make(map[string][]discovery.Discovered)
 	for index := range contextPlugins {
@@ -554,22 +1800,17 @@ func displayInstalledAndMissingSplitView(installedStandalonePlugins []cli.Plugin
 	}
 	sort.Strings(contexts)
 	for _, context := range contexts {
-		outputWriter := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "Name", "Description", "Target", "Version", "Status")
+		outputWriter := newPluginListOutputWriter(writer, format, columns)
 
 		fmt.Println("")
 		_, _ = cyanBold.Println("Plugins from Context: ", cyanBoldItalic.Sprintf(context))
 		for i := range ctxPluginsByContext[context] {
-			v := ctxPluginsByContext[context][i].InstalledVersion
-			if ctxPluginsByContext[context][i].Status == common.PluginStatusNotInstalled {
-				v = ctxPluginsByContext[context][i].RecommendedVersion
-			}
-			outputWriter.AddRow(
-				ctxPluginsByContext[context][i].Name,
-				ctxPluginsByContext[context][i].Description,
-				string(ctxPluginsByContext[context][i].Target),
-				v,
-				ctxPluginsByContext[context][i].Status,
-			)
+			p := &ctxPluginsByContext[context][i]
+			v := p.InstalledVersion
+			if p.Status == common.PluginStatusNotInstalled {
+				v = p.RecommendedVersion
+			}
+			addPluginListRow(outputWriter, columns, contextPluginListRow(p, v, getContextPluginStatus(p, v, p.Status)))
 		}
 		outputWriter.Render()
 	}
@@ -581,43 +1822,471 @@ func displayInstalledAndMissingSplitView(installedStandalonePlugins []cli.Plugin
 	}
 }
 
-func displayInstalledAndMissingListView(installedStandalonePlugins []cli.PluginInfo, installedContextPlugins, missingContextPlugins []discovery.Discovered, writer io.Writer) {
-	outputWriter := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "Name", "Description", "Target", "Version", "Status", "Context")
+func displayInstalledAndMissingListView(installedStandalonePlugins []cli.PluginInfo, installedContextPlugins, missingContextPlugins []discovery.Discovered, deprecations map[string]string, unavailable map[string]bool, sizes map[string]int64, columns []string, writer io.Writer) {
+	if len(columns) == 0 {
+		columns = defaultPluginListViewColumns
+	}
+	outputWriter := newPluginListOutputWriter(writer, outputFormat, columns)
+	for _, row := range installedAndMissingListRows(installedStandalonePlugins, installedContextPlugins, missingContextPlugins, deprecations, unavailable, sizes) {
+		addPluginListRow(outputWriter, columns, row)
+	}
+	outputWriter.Render()
+}
+
+// isPluginListPaged returns true if only a page of the plugins is listed
+func isPluginListPaged() bool {
+	return listLimit > 0 || listOffset > 0
+}
+
+// pagePluginList returns the page of the listed plugins starting at 'offset' and holding
+// at most 'limit' plugins, or all the remaining plugins if 'limit' is 0, along with the
+// total number of plugins.  The plugins are listed as standalone plugins first, then as
+// installed and missing context plugins, and the page may span all three.
+func pagePluginList(standalonePlugins []cli.PluginInfo, installedContextPlugins, missingContextPlugins []discovery.Discovered, offset, limit int) ([]cli.PluginInfo, []discovery.Discovered, []discovery.Discovered, int) {
+	total := len(standalonePlugins) + len(installedContextPlugins) + len(missingContextPlugins)
+	start := min(offset, total)
+	end := total
+	if limit > 0 {
+		end = min(start+limit, total)
+	}
+
+	// pageBounds converts the bounds of the page into bounds within a slice
+	// of 'n' plugins whose first plugin is at index 'base' of the whole list
+	pageBounds := func(base, n int) (int, int) {
+		return max(0, min(start-base, n)), max(0, min(end-base, n))
+	}
+	base := 0
+	lo, hi := pageBounds(base, len(standalonePlugins))
+	base += len(standalonePlugins)
+	standalonePlugins = standalonePlugins[lo:hi]
+	lo, hi = pageBounds(base, len(installedContextPlugins))
+	base += len(installedContextPlugins)
+	installedContextPlugins = installedContextPlugins[lo:hi]
+	lo, hi = pageBounds(base, len(missingContextPlugins))
+	missingContextPlugins = missingContextPlugins[lo:hi]
+	return standalonePlugins, installedContextPlugins, missingContextPlugins, total
+}
+
+// displayPluginListPageFooter shows how many plugins are listed out of all of them
+func displayPluginListPageFooter(listed, total int, writer io.Writer) {
+	fmt.Fprintf(writer, "\nShowing %d of %d plugins\n", listed, total)
+}
+
+// defaultPluginListViewColumns are the columns of the plugin list command
+// for the output formats other than the table formats
+var defaultPluginListViewColumns = []string{"name", "description", "target", "version", "status", "context"}
+
+// installedAndMissingListRows returns the rows of the installed standalone plugins followed by
+// the rows of the installed context plugins and of the context plugins that are not installed
+func installedAndMissingListRows(installedStandalonePlugins []cli.PluginInfo, installedContextPlugins, missingContextPlugins []discovery.Discovered, deprecations map[string]string, unavailable map[string]bool, sizes map[string]int64) []*pluginListRow {
+	rows := make([]*pluginListRow, 0, len(installedStandalonePlugins)+len(installedContextPlugins)+len(missingContextPlugins))
 	for index := range installedStandalonePlugins {
-		outputWriter.AddRow(
-			installedStandalonePlugins[index].Name,
-			installedStandalonePlugins[index].Description,
-			string(installedStandalonePlugins[index].Target),
-			installedStandalonePlugins[index].Version,
-			installedStandalonePlugins[index].Status,
-			"", // No context
-		)
+		status := getStandalonePluginStatus(&installedStandalonePlugins[index], installedStandalonePlugins[index].Status, deprecations, unavailable)
+		rows = append(rows, standalonePluginListRow(&installedStandalonePlugins[index], status, sizes))
 	}
 
 	// List context plugins that are installed.
 	for i := range installedContextPlugins {
-		outputWriter.AddRow(
-			installedContextPlugins[i].Name,
-			installedContextPlugins[i].Description,
-			string(installedContextPlugins[i].Target),
-			installedContextPlugins[i].InstalledVersion,
-			installedContextPlugins[i].Status,
-			installedContextPlugins[i].ContextName,
-		)
+		p := &installedContextPlugins[i]
+		rows = append(rows, contextPluginListRow(p, p.InstalledVersion, getContextPluginStatus(p, p.InstalledVersion, p.Status)))
 	}
 
 	// List context plugins that are not installed.
 	for i := range missingContextPlugins {
-		outputWriter.AddRow(
-			missingContextPlugins[i].Name,
-			missingContextPlugins[i].Description,
-			string(missingContextPlugins[i].Target),
-			missingContextPlugins[i].RecommendedVersion,
-			common.PluginStatusNotInstalled,
-			missingContextPlugins[i].ContextName,
-		)
+		p := &missingContextPlugins[i]
+		rows = append(rows, contextPluginListRow(p, p.RecommendedVersion, getContextPluginStatus(p, p.RecommendedVersion, common.PluginStatusNotInstalled)))
 	}
-	outputWriter.Render()
+	return rows
+}
+
+// pluginListJSONObjects converts the rows of the plugin list command into json objects
+// holding the values of the specified columns.  The fields of the objects are always
+// serialized sorted by name, so that the json output is stable across CLI versions.
+func pluginListJSONObjects(rows []*pluginListRow, columns []string) []map[string]interface{} {
+	if len(columns) == 0 {
+		columns = defaultPluginListViewColumns
+	}
+	objects := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		object := make(map[string]interface{}, len(columns))
+		for _, column := range columns {
+			object[column] = row.value(column)
+		}
+		objects = append(objects, object)
+	}
+	return objects
+}
+
+// pluginsFoundJSONObjects converts the plugins of an inventory into json objects
+// with the same fields as the table shown by displayPluginsFound
+func pluginsFoundJSONObjects(plugins []discovery.Discovered) []map[string]interface{} {
+	objects := make([]map[string]interface{}, 0, len(plugins))
+	for i := range plugins {
+		objects = append(objects, map[string]interface{}{
+			"name":        plugins[i].Name,
+			"description": plugins[i].Description,
+			"target":      string(plugins[i].Target),
+			"latest":      plugins[i].RecommendedVersion,
+		})
+	}
+	return objects
+}
+
+// renderJSON writes the value as json, indented or on a single line when compact.
+// The values keep their json types and the keys of maps are sorted, which makes
+// the output deterministic.
+func renderJSON(writer io.Writer, v interface{}, compact bool) error {
+	var b []byte
+	var err error
+	if compact {
+		b, err = json.Marshal(v)
+	} else {
+		b, err = json.MarshalIndent(v, "", "  ")
+	}
+	if err != nil {
+		return errors.Wrap(err, "unable to serialize the output as json")
+	}
+	_, err = fmt.Fprintln(writer, string(b))
+	return err
+}
+
+// isNameOutputFormat returns true if only the names of the plugins are to be listed
+func isNameOutputFormat() bool {
+	return outputFormat == nameOutputFormat || outputFormat == nameTargetOutputFormat
+}
+
+// pluginOutputName returns the plugin as listed by the name output formats:
+// its name, followed by its target for the name:target format
+func pluginOutputName(name, target string) string {
+	if outputFormat == nameTargetOutputFormat {
+		return name + ":" + target
+	}
+	return name
+}
+
+// displayPluginNames writes each name once, one per line and without any header,
+// so that the output can be piped to other commands, e.g., xargs
+func displayPluginNames(names []string, writer io.Writer) error {
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if _, err := fmt.Fprintln(writer, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// displayFailedPluginInstallations shows the plugins whose last installation failed
+func displayFailedPluginInstallations(failed []*pluginmanager.PluginInstallStatus, writer io.Writer) {
+	if outputFormat != "" && outputFormat != string(component.TableOutputType) && outputFormat != wideOutputFormat {
+		component.NewObjectWriter(writer, outputFormat, failed).Render()
+		return
+	}
+
+	output := component.NewOutputWriterWithOptions(writer, string(component.TableOutputType), []component.OutputWriterOption{}, "Name", "Target", "Version", "Status", "Time", "Reason")
+	for _, f := range failed {
+		output.AddRow(f.Name, string(f.Target), f.Version, f.Status, f.Time.Local().Format(time.RFC3339), f.Reason)
+	}
+	output.Render()
+	if len(failed) > 0 {
+		fmt.Fprintln(writer, "")
+		fmt.Fprintln(writer, "Note: To retry, install the plugins again with 'tanzu plugin install' or run 'tanzu plugin sync' for the plugins of the contexts.")
+	}
+}
+
+// displayDuplicatePlugins shows the plugins provided by more than one discovery source,
+// with a row for each source providing a plugin
+func displayDuplicatePlugins(duplicates []pluginmanager.DuplicatePlugin, writer io.Writer) {
+	if outputFormat != "" && outputFormat != string(component.TableOutputType) && outputFormat != wideOutputFormat {
+		component.NewObjectWriter(writer, outputFormat, duplicates).Render()
+		return
+	}
+
+	output := component.NewOutputWriterWithOptions(writer, string(component.TableOutputType), []component.OutputWriterOption{}, "Name", "Target", "Source", "Versions")
+	for i := range duplicates {
+		for _, source := range duplicates[i].Sources {
+			output.AddRow(duplicates[i].Name, string(duplicates[i].Target), source.Source, strings.Join(source.Versions, ", "))
+		}
+	}
+	output.Render()
+}
+
+// displayPluginStatusExplanations shows, for each target of a plugin, how its status was determined
+func displayPluginStatusExplanations(explanations []*pluginmanager.PluginStatusExplanation, writer io.Writer) {
+	if outputFormat != "" && outputFormat != string(component.TableOutputType) && outputFormat != wideOutputFormat {
+		component.NewObjectWriter(writer, outputFormat, explanations).Render()
+		return
+	}
+
+	valueOrNone := func(value string) string {
+		if value == "" {
+			return "none"
+		}
+		return value
+	}
+	for i, e := range explanations {
+		if i > 0 {
+			fmt.Fprintln(writer)
+		}
+		fmt.Fprintf(writer, "Plugin %s for target %s (%s)\n", e.Name, valueOrNone(string(e.Target)), e.Scope)
+		if len(e.Offers) == 0 {
+			fmt.Fprintln(writer, "  Offered by: no discovery source")
+		} else {
+			fmt.Fprintln(writer, "  Offered by:")
+			for _, o := range e.Offers {
+				source := o.Source
+				if o.ContextName != "" {
+					source = fmt.Sprintf("%s (context %s)", o.Source, o.ContextName)
+				}
+				fmt.Fprintf(writer, "    %s: recommends %s, provides %s\n", source, valueOrNone(o.RecommendedVersion), strings.Join(o.Versions, ", "))
+			}
+		}
+		recommended := valueOrNone(e.RecommendedVersion)
+		if e.RecommendedSource != "" {
+			recommended = fmt.Sprintf("%s from %s", e.RecommendedVersion, e.RecommendedSource)
+		}
+		fmt.Fprintf(writer, "  Recommended version: %s\n", recommended)
+		installed := valueOrNone(e.InstalledVersion)
+		if e.InstalledVersion != "" && e.InstalledFrom != "" {
+			installed = fmt.Sprintf("%s from %s", e.InstalledVersion, e.InstalledFrom)
+		}
+		if e.InstalledRecommendedVersion != "" {
+			installed = fmt.Sprintf("%s, when %s was recommended", installed, e.InstalledRecommendedVersion)
+		}
+		fmt.Fprintf(writer, "  Installed version: %s\n", installed)
+		fmt.Fprintf(writer, "  Status: %s, because %s\n", e.Status, e.Reason)
+	}
+}
+
+// pluginListColumns are the columns that can be selected with the --columns flag of the plugin list command
+var pluginListColumns = []string{"name", "description", "target", "version", "status", "context", "source", "vendor", "publisher", "size"}
+
+// pluginListRow holds the values of a plugin that can be shown by the plugin list command
+type pluginListRow struct {
+	name        string
+	description string
+	target      string
+	version     string
+	status      string
+	context     string
+	source      string
+	vendor      string
+	publisher   string
+	size        string
+}
+
+// value returns the value of the specified column
+func (r *pluginListRow) value(column string) string {
+	switch column {
+	case "name":
+		return r.name
+	case "description":
+		return r.description
+	case "target":
+		return r.target
+	case "version":
+		return r.version
+	case "status":
+		return r.status
+	case "context":
+		return r.context
+	case "source":
+		return r.source
+	case "vendor":
+		return r.vendor
+	case "publisher":
+		return r.publisher
+	case "size":
+		return r.size
+	}
+	return ""
+}
+
+// parsePluginListColumns parses the comma-separated list of columns of the --columns flag.
+// An empty list means that the default columns of the selected view are shown.
+func parsePluginListColumns(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	var columns []string
+	for _, column := range strings.Split(spec, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		valid := false
+		for _, c := range pluginListColumns {
+			if c == column {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, errors.Errorf("invalid column '%s', valid columns are: %s", column, strings.Join(pluginListColumns, ", "))
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// newPluginListOutputWriter returns an output writer whose headers are the specified columns
+func newPluginListOutputWriter(writer io.Writer, format string, columns []string) component.OutputWriter {
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = strings.ToUpper(column[:1]) + column[1:]
+	}
+	return component.NewOutputWriterWithOptions(writer, format, []component.OutputWriterOption{}, headers...)
+}
+
+// addPluginListRow adds the values of the specified columns of a plugin to the output writer
+func addPluginListRow(outputWriter component.OutputWriter, columns []string, row *pluginListRow) {
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		values[i] = row.value(column)
+	}
+	outputWriter.AddRow(values...)
+}
+
+// standalonePluginListRow returns the row of an installed standalone plugin, whose
+// binary size is looked up in the specified sizes indexed by catalog.PluginNameTarget()
+func standalonePluginListRow(p *cli.PluginInfo, status string, sizes map[string]int64) *pluginListRow {
+	return &pluginListRow{
+		name:        p.Name,
+		description: p.Description,
+		target:      string(p.Target),
+		version:     p.Version,
+		status:      status,
+		source:      p.Discovery,
+		vendor:      p.Vendor,
+		publisher:   p.Publisher,
+		size:        formatPluginSize(sizes[catalog.PluginNameTarget(p.Name, p.Target)]),
+	}
+}
+
+func contextPluginListRow(p *discovery.Discovered, version, status string) *pluginListRow {
+	return &pluginListRow{
+		name:        p.Name,
+		description: p.Description,
+		target:      string(p.Target),
+		version:     version,
+		status:      status,
+		context:     p.ContextName,
+		source:      p.Source,
+		vendor:      p.Vendor,
+		publisher:   p.Publisher,
+		size:        formatPluginSize(p.GetBinarySize(version, cli.GOOS, cli.GOARCH)),
+	}
+}
+
+// pluginSizeUnknown is shown instead of the size of a plugin binary
+// when the plugin inventory does not record it
+const pluginSizeUnknown = "unknown"
+
+// formatPluginSize returns the size in bytes of a plugin binary in a human readable
+// form such as "48.2Mi", or pluginSizeUnknown if the size is not known
+func formatPluginSize(size int64) string {
+	if size <= 0 {
+		return pluginSizeUnknown
+	}
+	const unit = 1024
+	if size < unit {
+		return strconv.FormatInt(size, 10)
+	}
+	value := float64(size) / unit
+	suffixes := []string{"Ki", "Mi", "Gi", "Ti"}
+	i := 0
+	for value >= unit && i < len(suffixes)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f%s", value, suffixes[i])
+}
+
+// pluginSortKeys are the keys that can be used with the --sort-by flag of the plugin list command
+var pluginSortKeys = []string{"name", "version", "status", "target", "source"}
+
+// pluginSortFields are the values of a plugin that can be used for sorting
+type pluginSortFields struct {
+	name    string
+	version string
+	status  string
+	target  string
+	source  string
+}
+
+func validatePluginSortKey(key string) error {
+	if key == "" {
+		return nil
+	}
+	for _, k := range pluginSortKeys {
+		if k == key {
+			return nil
+		}
+	}
+	return errors.Errorf("invalid sort key '%s', valid keys are: %s", key, strings.Join(pluginSortKeys, ", "))
+}
+
+// lessPluginSortFields compares two plugins using the key specified by the --sort-by flag.
+// Plugins are sorted by target and name when no key is specified or when the key values are equal.
+func lessPluginSortFields(a, b *pluginSortFields) bool {
+	var c int
+	switch sortBy {
+	case "name":
+		c = strings.Compare(a.name, b.name)
+	case "version":
+		c = utils.CompareVersions(a.version, b.version)
+	case "status":
+		c = strings.Compare(a.status, b.status)
+	case "target":
+		c = strings.Compare(a.target, b.target)
+	case "source":
+		c = strings.Compare(a.source, b.source)
+	}
+	if c == 0 {
+		c = strings.Compare(a.target, b.target)
+	}
+	if c == 0 {
+		c = strings.Compare(a.name, b.name)
+	}
+	if reverseSort {
+		return c > 0
+	}
+	return c < 0
+}
+
+func sortStandalonePlugins(plugins []cli.PluginInfo) {
+	fields := func(p *cli.PluginInfo) *pluginSortFields {
+		return &pluginSortFields{
+			name:    p.Name,
+			version: p.Version,
+			status:  p.Status,
+			target:  string(p.Target),
+			source:  p.Discovery,
+		}
+	}
+	sort.SliceStable(plugins, func(i, j int) bool {
+		return lessPluginSortFields(fields(&plugins[i]), fields(&plugins[j]))
+	})
+}
+
+func sortContextPlugins(plugins []discovery.Discovered) {
+	fields := func(p *discovery.Discovered) *pluginSortFields {
+		v := p.InstalledVersion
+		if p.Status == common.PluginStatusNotInstalled {
+			v = p.RecommendedVersion
+		}
+		return &pluginSortFields{
+			name:    p.Name,
+			version: v,
+			status:  p.Status,
+			target:  string(p.Target),
+			source:  p.Source,
+		}
+	}
+	sort.SliceStable(plugins, func(i, j int) bool {
+		return lessPluginSortFields(fields(&plugins[i]), fields(&plugins[j]))
+	})
 }
 
 func getTarget() configtypes.Target {
@@ -0,0 +1,77 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/component"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+)
+
+func newAuditPluginCmd() *cobra.Command {
+	var auditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "Verify the integrity and provenance of the installed plugins",
+		Long: `Verify the integrity and provenance of the installed plugins.
+For every installed plugin, the SHA256 hash of its binary is compared with the
+digest of the plugin found in the plugin inventory of its discovery source.
+Plugins whose binary does not match or is missing, as well as plugins installed
+from a discovery source which is no longer configured, are flagged.`,
+		Example: `
+    # Audit the installed plugins
+    tanzu plugin audit
+
+    # Audit the installed plugins and output the report in JSON
+    tanzu plugin audit -o json`,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: noMoreCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results, err := pluginmanager.AuditInstalledPlugins()
+			if results == nil && err != nil {
+				return err
+			}
+			if err != nil {
+				log.Warningf("some discovery sources could not be read, the plugins they provide cannot be verified: %v", err)
+			}
+
+			displayPluginAuditResults(results, cmd.OutOrStdout())
+
+			failed := 0
+			for _, r := range results {
+				if r.Failed() {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return errors.Errorf("%d of %d installed plugins failed the audit", failed, len(results))
+			}
+			return nil
+		},
+	}
+
+	auditCmd.Flags().StringVarP(&outputFormat, "output", "o", "", "output format (yaml|json|table)")
+	utils.PanicOnErr(auditCmd.RegisterFlagCompletionFunc("output", completionGetOutputFormats))
+
+	return auditCmd
+}
+
+func displayPluginAuditResults(results []*pluginmanager.PluginAuditResult, writer io.Writer) {
+	if outputFormat != "" && outputFormat != string(component.TableOutputType) {
+		component.NewObjectWriter(writer, outputFormat, results).Render()
+		return
+	}
+
+	output := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "Name", "Target", "Version", "Source", "Expected Digest", "Actual Digest", "Status")
+	for _, r := range results {
+		output.AddRow(r.Name, string(r.Target), r.Version, r.Source, r.ExpectedDigest, r.ActualDigest, r.Status)
+	}
+	output.Render()
+}
@@ -126,6 +126,44 @@ environment. The plugin bundle is obtained using the "download-bundle" command.`
 	return uploadBundleCmd
 }
 
+var copySourceGroups []string
+
+func newCopySourcePluginCmd() *cobra.Command {
+	var copySourceCmd = &cobra.Command{
+		Use:   "copy-source SOURCE_IMAGE DESTINATION_REPO",
+		Short: "Copy a plugin discovery source to a repository",
+		Long: `Copy the plugin inventory image of a discovery source and all the plugin images it references
+directly to an alternate container registry, for use in an internet-restricted environment.
+This is equivalent to using the "download-bundle" and "upload-bundle" commands, without an intermediate
+plugin bundle, and requires access to both registries.`,
+		Example: `
+    # Copy the entire plugin repository of the default discovery source to the remote repository
+    tanzu plugin copy-source projects.registry.vmware.com/tanzu_cli/plugins/plugin-inventory:latest custom.registry.company.com/tanzu-plugins/
+
+    # Only copy the plugins of a specific group version
+    tanzu plugin copy-source projects.registry.vmware.com/tanzu_cli/plugins/plugin-inventory:latest custom.registry.company.com/tanzu-plugins/ --group vmware-tkg/default:v1.0.0`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeCopySource,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options := airgapped.CopyPluginSourceOptions{
+				PluginInventoryImage: args[0],
+				DestinationRepo:      args[1],
+				Groups:               copySourceGroups,
+				ImageProcessor:       carvelhelpers.NewImageOperationsImpl(),
+			}
+			_, err := options.CopyPluginSource()
+			return err
+		},
+	}
+
+	copySourceCmd.Flags().StringSliceVarP(&copySourceGroups, "group", "", []string{}, "only copy the plugins specified in the plugin-group version (can specify multiple)")
+	utils.PanicOnErr(copySourceCmd.RegisterFlagCompletionFunc("group", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cobra.AppendActiveHelp(nil, "Please enter a plugin-group version to copy"), cobra.ShellCompDirectiveNoFileComp
+	}))
+
+	return copySourceCmd
+}
+
 // ====================================
 // Shell completion functions
 // ====================================
@@ -142,6 +180,16 @@ func completeDownloadBundle(_ *cobra.Command, args []string, _ string) ([]string
 	return activeHelpNoMoreArgs(nil), cobra.ShellCompDirectiveNoFileComp
 }
 
+func completeCopySource(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return cobra.AppendActiveHelp(nil, "Please enter the URI of the plugin discovery image to copy"), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		return cobra.AppendActiveHelp(nil, "Please enter the URI of the destination repository for publishing plugins"), cobra.ShellCompDirectiveNoFileComp
+	}
+	return activeHelpNoMoreArgs(nil), cobra.ShellCompDirectiveNoFileComp
+}
+
 func completeUploadBundle(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
 	if upbo.destinationRepo == "" || upbo.sourceTar == "" {
 		// Both flags are required, so completion will be provided for them
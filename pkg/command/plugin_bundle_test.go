@@ -159,6 +159,33 @@ func TestCompletionPluginBundle(t *testing.T) {
 			// ":4" is the value of the ShellCompDirectiveNoFileComp
 			expected: "_activeHelp_ " + compNoMoreArgsMsg + "\n:4\n",
 		},
+		// ============================
+		// tanzu plugin copy-source
+		// ============================
+		{
+			test: "completion of the source image of the copy-source command",
+			args: []string{"__complete", "plugin", "copy-source", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the URI of the plugin discovery image to copy\n:4\n",
+		},
+		{
+			test: "completion of the destination repository of the copy-source command",
+			args: []string{"__complete", "plugin", "copy-source", "example.com/image:latest", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the URI of the destination repository for publishing plugins\n:4\n",
+		},
+		{
+			test: "no completion after the copy-source command when all arguments are present",
+			args: []string{"__complete", "plugin", "copy-source", "example.com/image:latest", "repo", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ " + compNoMoreArgsMsg + "\n:4\n",
+		},
+		{
+			test: "completion for the --group flag value of the copy-source command",
+			args: []string{"__complete", "plugin", "copy-source", "--group", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter a plugin-group version to copy\n:4\n",
+		},
 	}
 
 	// Setup a plugin source and a set of installed plugins
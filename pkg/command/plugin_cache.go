@@ -0,0 +1,175 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/component"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+)
+
+var (
+	maxCacheSize         string
+	maxArtifactCacheSize string
+)
+
+func newPluginCacheCmd() *cobra.Command {
+	var pluginCacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the cache of plugin inventories",
+		Long:  "Manage the cache where the plugin inventories of the discovery sources are stored",
+	}
+	pluginCacheCmd.SetUsageFunc(cli.SubCmdUsageFunc)
+
+	pluginCacheCmd.AddCommand(
+		newInfoCacheCmd(),
+		newPruneCacheCmd(),
+	)
+
+	return pluginCacheCmd
+}
+
+func newInfoCacheCmd() *cobra.Command {
+	var infoCmd = &cobra.Command{
+		Use:   "info",
+		Short: "Show the plugin inventories stored in the cache",
+		Long:  "Show the plugin inventories stored in the cache along with the size, digest and last modification time of each of them",
+		Example: `
+    # Show the cached plugin inventories
+    tanzu plugin cache info
+
+    # Show the cached plugin inventories in json format
+    tanzu plugin cache info -o json`,
+		Args:              cobra.MaximumNArgs(0),
+		ValidArgsFunction: noMoreCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			infos, err := pluginmanager.ListCachedInventories()
+			if err != nil {
+				return err
+			}
+
+			if outputFormat != "" && outputFormat != string(component.TableOutputType) {
+				component.NewObjectWriter(cmd.OutOrStdout(), outputFormat, infos).Render()
+				return nil
+			}
+
+			var totalSize int64
+			output := component.NewOutputWriterWithOptions(cmd.OutOrStdout(), outputFormat, []component.OutputWriterOption{}, "name", "size", "digest", "metadata", "last modified", "database")
+			for i := range infos {
+				totalSize += infos[i].Size
+				output.AddRow(infos[i].Name, formatCacheSize(infos[i].Size), infos[i].Digest, infos[i].MetadataPresent, formatCacheTime(infos[i].LastModified), infos[i].DBFile)
+			}
+			output.Render()
+			fmt.Fprintf(cmd.OutOrStdout(), "\nTotal size: %s\n", formatCacheSize(totalSize))
+			return nil
+		},
+	}
+	infoCmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format (yaml|json|table)")
+	utils.PanicOnErr(infoCmd.RegisterFlagCompletionFunc("output", completionGetOutputFormats))
+
+	return infoCmd
+}
+
+// formatCacheSize returns a size in bytes in a human readable form such as "12Mi"
+func formatCacheSize(size int64) string {
+	return resource.NewQuantity(size, resource.BinarySI).String()
+}
+
+// formatCacheTime returns the time a cached inventory was last modified,
+// or an empty string if the cached inventory does not have a database
+func formatCacheTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func newPruneCacheCmd() *cobra.Command {
+	var pruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Evict the least recently used plugin inventories and binaries from the cache",
+		Long: "Evict the least recently used plugin inventories and plugin binaries from the cache until the cache is within its maximum size. " +
+			"The maximum size of the plugin inventories is specified with the --max-size flag or the " + constants.ConfigVariablePluginDiscoveryMaxCacheSize + " variable. " +
+			"The maximum size of the plugin binaries is specified with the --max-artifacts-size flag or the " + constants.ConfigVariablePluginArtifactMaxCacheSize + " variable, and is 1Gi by default. " +
+			"The previous plugin inventories retained through the " + constants.ConfigVariablePluginDiscoveryRetainedInventories + " variable are evicted first. " +
+			"The plugin inventories in use by another command are not evicted.",
+		Example: `
+    # Evict plugin inventories until the cache uses at most 200 MiB
+    tanzu plugin cache prune --max-size 200Mi
+
+    # Evict plugin binaries until the cache uses at most 1 GiB for them
+    tanzu plugin cache prune --max-artifacts-size 1Gi
+
+    # Evict plugin inventories based on the configured maximum size of the cache
+    tanzu config set env.TANZU_CLI_PLUGIN_DISCOVERY_MAX_CACHE_SIZE 500Mi
+    tanzu plugin cache prune`,
+		Args:              cobra.MaximumNArgs(0),
+		ValidArgsFunction: noMoreCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			maxSize, pruneInventories, err := getMaxCacheSize(maxCacheSize, discovery.GetMaxCacheSize)
+			if err != nil {
+				return err
+			}
+			// The plugin binaries always have a maximum size
+			maxArtifactsSize, _, err := getMaxCacheSize(maxArtifactCacheSize, discovery.GetMaxArtifactCacheSize)
+			if err != nil {
+				return err
+			}
+
+			errList := make([]error, 0)
+			if pruneInventories {
+				evicted, err := pluginmanager.PruneInventoryCache(maxSize)
+				for _, name := range evicted {
+					clilog.Infof("Evicted the cached plugin inventory of '%s'", name)
+				}
+				if err != nil {
+					errList = append(errList, err)
+				}
+			}
+			evicted, err := pluginmanager.PruneArtifactCache(maxArtifactsSize)
+			for _, digest := range evicted {
+				clilog.Infof("Evicted the cached plugin binary with digest '%s'", digest)
+			}
+			if err != nil {
+				errList = append(errList, err)
+			}
+			if err := kerrors.NewAggregate(errList); err != nil {
+				return err
+			}
+			clilog.Successf("successfully pruned the plugin cache")
+			return nil
+		},
+	}
+	pruneCmd.Flags().StringVar(&maxCacheSize, "max-size", "", "maximum size of the cached plugin inventories (e.g., 500Mi, 1G)")
+	pruneCmd.Flags().StringVar(&maxArtifactCacheSize, "max-artifacts-size", "", "maximum size of the cached plugin binaries (e.g., 500Mi, 1G)")
+
+	return pruneCmd
+}
+
+// getMaxCacheSize returns the maximum size in bytes specified by the flag value or,
+// if the flag is not set, the size configured by the user.  The returned boolean
+// is false if there is no maximum size.
+func getMaxCacheSize(flagValue string, getConfiguredSize func() (int64, bool)) (int64, bool, error) {
+	if flagValue != "" {
+		maxSize, err := discovery.ParseCacheSize(flagValue)
+		if err != nil {
+			return 0, false, err
+		}
+		return maxSize, true, nil
+	}
+	maxSize, found := getConfiguredSize()
+	return maxSize, found, nil
+}
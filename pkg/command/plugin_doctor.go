@@ -0,0 +1,74 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/component"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+)
+
+func newDoctorPluginCmd() *cobra.Command {
+	var doctorCmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose common problems of the plugin environment",
+		Long: `Diagnose common problems of the plugin environment.
+The following checks are run, each reporting pass, warn or fail along with how
+to fix the problems found:
+  data-directories     the plugin and cache directories are writable
+  discovery-sources    the plugins of each discovery source can be listed and
+                       the signature of its image is verified
+  inventory-cache      the cached plugin inventories can be used
+  plugin-binaries      the binary of each installed plugin is intact
+  plugin-registration  the CLI found in the PATH is the one running and the
+                       binary of each installed plugin is executable`,
+		Example: `
+    # Diagnose the plugin environment
+    tanzu plugin doctor
+
+    # Diagnose the plugin environment and output the report in JSON
+    tanzu plugin doctor -o json`,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: noMoreCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results := pluginmanager.RunDoctorChecks()
+			displayDoctorResults(results, cmd.OutOrStdout())
+
+			failed := 0
+			for _, r := range results {
+				if r.Status == pluginmanager.DoctorStatusFail {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return errors.Errorf("%d problems were found which prevent plugins from working", failed)
+			}
+			return nil
+		},
+	}
+
+	doctorCmd.Flags().StringVarP(&outputFormat, "output", "o", "", "output format (yaml|json|table)")
+	utils.PanicOnErr(doctorCmd.RegisterFlagCompletionFunc("output", completionGetOutputFormats))
+
+	return doctorCmd
+}
+
+func displayDoctorResults(results []*pluginmanager.DoctorResult, writer io.Writer) {
+	if outputFormat != "" && outputFormat != string(component.TableOutputType) {
+		component.NewObjectWriter(writer, outputFormat, results).Render()
+		return
+	}
+
+	output := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "Check", "Item", "Status", "Message", "Remediation")
+	for _, r := range results {
+		output.AddRow(r.Check, r.Item, r.Status, r.Message, r.Remediation)
+	}
+	output.Render()
+}
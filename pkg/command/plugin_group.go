@@ -26,6 +26,7 @@ import (
 var (
 	groupID          string
 	showNonMandatory bool
+	groupDiff        bool
 )
 
 func newPluginGroupCmd() *cobra.Command {
@@ -93,12 +94,36 @@ func newSearchCmd() *cobra.Command {
 
 func newGetCmd() *cobra.Command {
 	var getCmd = &cobra.Command{
-		Use:               "get GROUP_NAME",
-		Short:             "Get the content of the specified plugin-group",
-		Long:              "Get the content of the specified plugin-group.  A plugin-group provides a list of plugin name/version combinations which can be installed in one step.  This command allows to see the list of plugins included in the specified group.",
-		Args:              cobra.ExactArgs(1),
+		Use:   "get GROUP_NAME",
+		Short: "Get the content of the specified plugin-group",
+		Long: `Get the content of the specified plugin-group.  A plugin-group provides a list of plugin name/version combinations which can be installed in one step.  This command allows to see the list of plugins included in the specified group.
+With --diff, the plugins added, removed or whose version changed between two versions of the plugin-group are shown instead.`,
+		Example: `
+    # Get the plugins of the latest version of the vmware-tkg/default plugin group
+    tanzu plugin group get vmware-tkg/default
+
+    # Show how the plugins of the vmware-tkg/default plugin group changed between two versions
+    tanzu plugin group get vmware-tkg/default --diff v2.1.0 v2.2.0`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if groupDiff {
+				if len(args) != 3 {
+					return errors.New("the --diff flag requires the plugin-group followed by the two versions to compare")
+				}
+				return nil
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
 		ValidArgsFunction: completeGroupGet,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if groupDiff {
+				diff, err := pluginmanager.DiffPluginGroupVersions(args[0], args[1], args[2])
+				if err != nil {
+					return err
+				}
+				displayGroupDiff(diff, cmd.OutOrStdout())
+				return nil
+			}
+
 			var specifiedVersion string
 
 			gID := args[0]
@@ -146,10 +171,30 @@ func newGetCmd() *cobra.Command {
 	utils.PanicOnErr(getCmd.RegisterFlagCompletionFunc("output", completionGetOutputFormats))
 
 	f.BoolVarP(&showNonMandatory, "all", "", false, "include the contextual plugins")
+	f.BoolVar(&groupDiff, "diff", false, "show the plugins added, removed or changed between the two plugin-group versions specified after the plugin-group")
+	getCmd.MarkFlagsMutuallyExclusive("all", "diff")
 
 	return getCmd
 }
 
+// displayGroupDiff shows the differences between two versions of a plugin group
+func displayGroupDiff(diff []*pluginmanager.PluginGroupDiffEntry, writer io.Writer) {
+	if outputFormat != "" && outputFormat != string(component.TableOutputType) {
+		component.NewObjectWriter(writer, outputFormat, diff).Render()
+		return
+	}
+
+	if len(diff) == 0 {
+		fmt.Fprintln(writer, "The plugins of both plugin-group versions are the same")
+		return
+	}
+	output := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "Change", "Name", "Target", "From", "To")
+	for _, entry := range diff {
+		output.AddRow(entry.Change, entry.Name, entry.Target, entry.FromVersion, entry.ToVersion)
+	}
+	output.Render()
+}
+
 func displayGroupsFound(groups []*plugininventory.PluginGroup, writer io.Writer) {
 	output := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "group", "description", "latest")
 
@@ -268,6 +313,9 @@ func displayGroupContentAsList(group *plugininventory.PluginGroup, writer io.Wri
 // Shell completion functions
 // ====================================
 func completeGroupGet(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if groupDiff && len(args) > 0 && len(args) < 3 {
+		return cobra.AppendActiveHelp(nil, "Please enter a version of the plugin-group to compare"), cobra.ShellCompDirectiveNoFileComp
+	}
 	if len(args) > 0 {
 		return activeHelpNoMoreArgs(nil), cobra.ShellCompDirectiveNoFileComp
 	}
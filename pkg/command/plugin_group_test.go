@@ -177,6 +177,24 @@ func TestPluginGroupGet(t *testing.T) {
 			expectedFailure: true,
 			expected:        "plugin-group \"vmware-tkg/default:v0.888.0\" cannot be found",
 		},
+		{
+			test:            "get a plugin group diff without the versions",
+			args:            []string{"plugin", "group", "get", "vmware-tkg/default", "--diff"},
+			expectedFailure: true,
+			expected:        "the --diff flag requires the plugin-group followed by the two versions to compare",
+		},
+		{
+			test:            "get a plugin group diff with the same version",
+			args:            []string{"plugin", "group", "get", "vmware-tkg/default", "--diff", "v1.1.1", "v1.1.1"},
+			expectedFailure: false,
+			expected:        "The plugins of both plugin-group versions are the same",
+		},
+		{
+			test:            "get a plugin group diff with --all",
+			args:            []string{"plugin", "group", "get", "vmware-tkg/default", "--diff", "--all", "v1.1.1", "v2.2.2"},
+			expectedFailure: true,
+			expected:        "if any flags in the group [all diff] are set none of the others can be; [all diff] were all set",
+		},
 	}
 
 	// Setup a plugin source and a set of installed plugins
@@ -206,17 +206,22 @@ func setupPluginEntries(t *testing.T, db *sql.DB) {
 	}
 }
 
-func setupTestPluginInventory(t *testing.T) {
-	// Create a temporary directory for the plugin inventory DB
-	inventoryDir := filepath.Join(
+// getTestPluginInventoryDBFile returns the path of the plugin inventory DB of the test discovery source
+func getTestPluginInventoryDBFile() string {
+	return filepath.Join(
 		common.DefaultCacheDir,
 		common.PluginInventoryDirName,
-		config.DefaultStandaloneDiscoveryName)
-	err := os.MkdirAll(inventoryDir, 0755)
+		config.DefaultStandaloneDiscoveryName,
+		plugininventory.SQliteDBFileName)
+}
+
+func setupTestPluginInventory(t *testing.T) {
+	// Create a temporary directory for the plugin inventory DB
+	err := os.MkdirAll(filepath.Dir(getTestPluginInventoryDBFile()), 0755)
 	assert.Nil(t, err)
 
 	// Generate a test plugin inventory DB
-	dbFile, err := os.Create(filepath.Join(inventoryDir, plugininventory.SQliteDBFileName))
+	dbFile, err := os.Create(getTestPluginInventoryDBFile())
 	assert.Nil(t, err)
 
 	// Open DB with the sqlite driver
@@ -6,29 +6,44 @@ package command
 import (
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/component"
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
 
 var (
-	showDetails bool
-	pluginName  string
+	showDetails   bool
+	pluginName    string
+	searchOnline  bool
+	searchOffline bool
+)
+
+const (
+	searchModeOnline  = "online"
+	searchModeOffline = "offline"
 )
 
 const searchLongDesc = `Search provides the ability to search for plugins that can be installed.
 The command lists all plugins currently available for installation.
 The search command also provides flags to limit the scope of the search.
+By default, the plugin inventories are refreshed from the registry before searching;
+the --offline flag only searches the locally cached inventories, which is faster but
+may give stale results. The default can be changed by setting
+TANZU_CLI_PLUGIN_SEARCH_MODE to "online" or "offline".
 `
 
 func newSearchPluginCmd() *cobra.Command {
@@ -61,7 +76,12 @@ func newSearchPluginCmd() *cobra.Command {
 					Name:   pluginName,
 					Target: configtypes.StringToTarget(targetStr),
 				}
-				allPlugins, err = pluginmanager.DiscoverStandalonePlugins(discovery.WithPluginDiscoveryCriteria(criteria))
+				options := []discovery.DiscoveryOptions{discovery.WithPluginDiscoveryCriteria(criteria)}
+				if useOfflineSearch() {
+					options = append(options, discovery.WithUseLocalCacheOnly())
+					log.Warningf("The plugins were searched in the local cache only, the results may be stale. Use the --online flag to refresh the plugin inventories from the registry.")
+				}
+				allPlugins, err = pluginmanager.DiscoverStandalonePlugins(options...)
 				if err != nil {
 					errorList = append(errorList, fmt.Errorf("there was an error while discovering standalone plugins, error information: '%w'", err))
 				}
@@ -88,6 +108,9 @@ func newSearchPluginCmd() *cobra.Command {
 	f.StringVarP(&outputFormat, "output", "o", "", "output format (yaml|json|table)")
 	utils.PanicOnErr(searchCmd.RegisterFlagCompletionFunc("output", completionGetOutputFormats))
 
+	f.BoolVar(&searchOnline, "online", false, "refresh the plugin inventories from the registry before searching")
+	f.BoolVar(&searchOffline, "offline", false, "only search the locally cached plugin inventories, without accessing the registry")
+
 	f.StringVarP(&local, "local", "", "", "path to local plugin source")
 	msg := fmt.Sprintf("this was done in the %q release, it will be removed following the deprecation policy (6 months). Use the %q flag instead.\n", "v1.0.0", "--local-source")
 	utils.PanicOnErr(f.MarkDeprecated("local", msg))
@@ -109,10 +132,30 @@ func newSearchPluginCmd() *cobra.Command {
 	searchCmd.MarkFlagsMutuallyExclusive("local-source", "name")
 	searchCmd.MarkFlagsMutuallyExclusive("local-source", "target")
 	searchCmd.MarkFlagsMutuallyExclusive("local-source", "show-details")
+	searchCmd.MarkFlagsMutuallyExclusive("online", "offline")
 
 	return searchCmd
 }
 
+// useOfflineSearch returns true if the search must only use the locally cached plugin
+// inventories, as requested by the --online/--offline flags or else by the configured search mode
+func useOfflineSearch() bool {
+	if searchOnline {
+		return false
+	}
+	if searchOffline {
+		return true
+	}
+	mode := strings.TrimSpace(os.Getenv(constants.ConfigVariablePluginSearchMode))
+	switch {
+	case strings.EqualFold(mode, searchModeOffline):
+		return true
+	case mode != "" && !strings.EqualFold(mode, searchModeOnline):
+		log.Warningf("Ignoring the invalid value %q of %s, it must be %q or %q", mode, constants.ConfigVariablePluginSearchMode, searchModeOnline, searchModeOffline)
+	}
+	return false
+}
+
 func displayPluginsFound(plugins []discovery.Discovered, writer io.Writer) {
 	outputWriter := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "Name", "Description", "Target", "Latest")
 
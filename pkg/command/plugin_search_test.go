@@ -47,6 +47,12 @@ func TestPluginSearch(t *testing.T) {
 			expectedFailure: true,
 			expected:        "if any flags in the group [local show-details] are set none of the others can be",
 		},
+		{
+			test:            "no --online and --offline together",
+			args:            []string{"plugin", "search", "--online", "--offline"},
+			expectedFailure: true,
+			expected:        "if any flags in the group [online offline] are set none of the others can be",
+		},
 	}
 
 	assert := assert.New(t)
@@ -100,6 +106,39 @@ func TestPluginSearch(t *testing.T) {
 	}
 }
 
+func TestUseOfflineSearch(t *testing.T) {
+	tests := []struct {
+		test     string
+		online   bool
+		offline  bool
+		mode     string
+		expected bool
+	}{
+		{test: "online by default", expected: false},
+		{test: "offline flag", offline: true, expected: true},
+		{test: "online flag", online: true, expected: false},
+		{test: "offline mode configured", mode: "offline", expected: true},
+		{test: "offline mode configured with another case", mode: " Offline ", expected: true},
+		{test: "online mode configured", mode: "online", expected: false},
+		{test: "invalid mode configured", mode: "sometimes", expected: false},
+		{test: "online flag overrides the configured mode", online: true, mode: "offline", expected: false},
+		{test: "offline flag overrides the configured mode", offline: true, mode: "online", expected: true},
+	}
+
+	defer os.Unsetenv(constants.ConfigVariablePluginSearchMode)
+	for _, spec := range tests {
+		t.Run(spec.test, func(t *testing.T) {
+			searchOnline = spec.online
+			searchOffline = spec.offline
+			os.Setenv(constants.ConfigVariablePluginSearchMode, spec.mode)
+
+			assert.Equal(t, spec.expected, useOfflineSearch())
+
+			resetPluginCommandFlags()
+		})
+	}
+}
+
 func TestCompletionPluginSearch(t *testing.T) {
 	// This is global logic and needs not be tested for each
 	// command.  Let's deactivate it.
@@ -18,6 +18,9 @@ import (
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/config"
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/plugin"
@@ -31,6 +34,7 @@ func TestPluginList(t *testing.T) {
 		targets         []configtypes.Target
 		args            []string
 		expected        string
+		unexpected      string
 		expectedFailure bool
 	}{
 		{
@@ -64,6 +68,100 @@ func TestPluginList(t *testing.T) {
 			expectedFailure: false,
 			expected:        "NAME DESCRIPTION TARGET VERSION STATUS bar some bar description kubernetes v0.2.0 installed foo some foo description mission-control v0.1.0 installed",
 		},
+		{
+			test:            "when sorting by name",
+			plugins:         []string{"foo", "bar"},
+			versions:        []string{"v0.1.0", "v0.2.0"},
+			targets:         []configtypes.Target{configtypes.TargetK8s, configtypes.TargetTMC},
+			args:            []string{"plugin", "list", "--sort-by", "name"},
+			expectedFailure: false,
+			expected:        "NAME DESCRIPTION TARGET VERSION STATUS bar some bar description mission-control v0.2.0 installed foo some foo description kubernetes v0.1.0 installed",
+		},
+		{
+			test:            "when sorting by version in reverse order",
+			plugins:         []string{"foo", "bar", "qux"},
+			versions:        []string{"v0.10.0", "v0.2.0", "v0.9.0"},
+			targets:         []configtypes.Target{configtypes.TargetK8s, configtypes.TargetK8s, configtypes.TargetK8s},
+			args:            []string{"plugin", "list", "--sort-by", "version", "--reverse"},
+			expectedFailure: false,
+			expected:        "NAME DESCRIPTION TARGET VERSION STATUS foo some foo description kubernetes v0.10.0 installed qux some qux description kubernetes v0.9.0 installed bar some bar description kubernetes v0.2.0 installed",
+		},
+		{
+			test:            "when only listing the standalone plugins",
+			plugins:         []string{"foo"},
+			versions:        []string{"v0.1.0"},
+			targets:         []configtypes.Target{configtypes.TargetK8s},
+			args:            []string{"plugin", "list", "--standalone-only", "-o", "json"},
+			expectedFailure: false,
+			expected:        `[ { "context": "", "description": "some foo description", "name": "foo", "status": "installed", "target": "kubernetes", "version": "v0.1.0" } ]`,
+		},
+		{
+			test:            "when only listing the context plugins",
+			plugins:         []string{"foo"},
+			versions:        []string{"v0.1.0"},
+			targets:         []configtypes.Target{configtypes.TargetK8s},
+			args:            []string{"plugin", "list", "--context-only"},
+			expectedFailure: false,
+			unexpected:      "foo",
+		},
+		{
+			test:            "when only listing the standalone plugins and the context plugins",
+			args:            []string{"plugin", "list", "--standalone-only", "--context-only"},
+			expectedFailure: true,
+			expected:        "if any flags in the group [standalone-only context-only] are set none of the others can be",
+		},
+		{
+			test:            "when listing the plugins of a context which is not active",
+			plugins:         []string{"foo"},
+			versions:        []string{"v0.1.0"},
+			targets:         []configtypes.Target{configtypes.TargetK8s},
+			args:            []string{"plugin", "list", "--context", "prod-cluster"},
+			expectedFailure: true,
+			expected:        "context 'prod-cluster' not found, there is no active context",
+		},
+		{
+			test:            "when listing the plugins of a context and only the standalone plugins",
+			args:            []string{"plugin", "list", "--context", "prod-cluster", "--standalone-only"},
+			expectedFailure: true,
+			expected:        "if any flags in the group [context standalone-only] are set none of the others can be",
+		},
+		{
+			test:            "when selecting the columns",
+			plugins:         []string{"foo"},
+			versions:        []string{"v0.1.0"},
+			targets:         []configtypes.Target{configtypes.TargetK8s},
+			args:            []string{"plugin", "list", "--columns", "name, Version,vendor"},
+			expectedFailure: false,
+			expected:        "NAME VERSION VENDOR foo v0.1.0 vmware",
+			unexpected:      "some foo description",
+		},
+		{
+			test:            "when selecting the columns of the json output",
+			plugins:         []string{"foo"},
+			versions:        []string{"v0.1.0"},
+			targets:         []configtypes.Target{configtypes.TargetK8s},
+			args:            []string{"plugin", "list", "-o", "json", "--columns", "name,target,publisher"},
+			expectedFailure: false,
+			expected:        `[ { "name": "foo", "publisher": "tkg", "target": "kubernetes" } ]`,
+		},
+		{
+			test:            "when selecting an invalid column",
+			args:            []string{"plugin", "list", "--columns", "name,invalid"},
+			expectedFailure: true,
+			expected:        "invalid column 'invalid', valid columns are: name, description, target, version, status, context, source, vendor, publisher, size",
+		},
+		{
+			test:            "when sorting by an invalid key",
+			args:            []string{"plugin", "list", "--sort-by", "invalid"},
+			expectedFailure: true,
+			expected:        "invalid sort key 'invalid', valid keys are: name, version, status, target, source",
+		},
+		{
+			test:            "plugin describe with --version and --versions",
+			args:            []string{"plugin", "describe", "foo", "--version", "v0.1.0", "--versions"},
+			expectedFailure: true,
+			expected:        "if any flags in the group [versions version] are set none of the others can be",
+		},
 		{
 			test:            "when json output is requested",
 			plugins:         []string{"foo"},
@@ -82,6 +180,25 @@ func TestPluginList(t *testing.T) {
 			expectedFailure: false,
 			expected:        `- context: "" description: some foo description name: foo status: installed target: kubernetes version: v0.1.0`,
 		},
+		{
+			test:            "when only the names of the plugins are requested",
+			plugins:         []string{"foo", "bar"},
+			versions:        []string{"v0.1.0", "v0.2.0"},
+			targets:         []configtypes.Target{configtypes.TargetK8s, configtypes.TargetK8s},
+			args:            []string{"plugin", "list", "-o", "name"},
+			expectedFailure: false,
+			expected:        "bar foo",
+			unexpected:      "NAME",
+		},
+		{
+			test:            "when only the names and targets of the plugins are requested",
+			plugins:         []string{"foo", "foo"},
+			versions:        []string{"v0.1.0", "v0.1.0"},
+			targets:         []configtypes.Target{configtypes.TargetK8s, configtypes.TargetTMC},
+			args:            []string{"plugin", "list", "-o", "name:target"},
+			expectedFailure: false,
+			expected:        "foo:kubernetes foo:mission-control",
+		},
 		{
 			test:            "plugin describe json output requested",
 			plugins:         []string{"foo"},
@@ -89,7 +206,156 @@ func TestPluginList(t *testing.T) {
 			targets:         []configtypes.Target{configtypes.TargetK8s},
 			args:            []string{"plugin", "describe", "foo", "-o", "json"},
 			expectedFailure: false,
-			expected:        `[ { "description": "some foo description", "installationpath": "%v", "name": "foo", "status": "installed", "target": "kubernetes", "version": "v0.1.0" } ]`,
+			expected:        `[ { "description": "some foo description", "installationpath": "%v", "name": "foo", "publisher": "tkg", "status": "installed", "target": "kubernetes", "vendor": "vmware", "version": "v0.1.0" } ]`,
+		},
+		{
+			test:            "plugin describe for all targets with json output",
+			plugins:         []string{"foo", "foo"},
+			versions:        []string{"v0.1.0", "v0.2.0"},
+			targets:         []configtypes.Target{configtypes.TargetTMC, configtypes.TargetK8s},
+			args:            []string{"plugin", "describe", "foo", "--target", "all", "-o", "json"},
+			expectedFailure: false,
+			expected:        `"status": "installed", "target": "kubernetes", "vendor": "vmware", "version": "v0.2.0" }, { "description": "some foo description",`,
+		},
+		{
+			test:            "plugin describe without a target for a plugin installed for two targets",
+			plugins:         []string{"foo", "foo"},
+			versions:        []string{"v0.1.0", "v0.2.0"},
+			targets:         []configtypes.Target{configtypes.TargetTMC, configtypes.TargetK8s},
+			args:            []string{"plugin", "describe", "foo"},
+			expectedFailure: false,
+			expected:        "Target: kubernetes NAME VERSION STATUS DESCRIPTION VENDOR PUBLISHER INSTALLATIONPATH foo v0.2.0 installed some foo description",
+		},
+		{
+			test:            "plugin describe for all targets of a plugin installed for one target",
+			plugins:         []string{"foo"},
+			versions:        []string{"v0.1.0"},
+			targets:         []configtypes.Target{configtypes.TargetK8s},
+			args:            []string{"plugin", "describe", "foo", "--target", "all"},
+			expectedFailure: false,
+			expected:        "Target: kubernetes NAME VERSION STATUS DESCRIPTION",
+		},
+		{
+			test:            "plugin describe for all targets of a plugin which is not installed",
+			args:            []string{"plugin", "describe", "foo", "--target", "all"},
+			expectedFailure: true,
+			expected:        "unable to find plugin 'foo'",
+		},
+		{
+			test:            "plugin describe for all targets with --versions",
+			args:            []string{"plugin", "describe", "foo", "--target", "all", "--versions"},
+			expectedFailure: true,
+			expected:        "the --version, --versions, --show-signature and --check-availability flags cannot be used with '--target all'",
+		},
+		{
+			test:            "plugin describe with --show-signature and --version",
+			args:            []string{"plugin", "describe", "foo", "--version", "v0.1.0", "--show-signature"},
+			expectedFailure: true,
+			expected:        "if any flags in the group [show-signature version] are set none of the others can be",
+		},
+		{
+			test:            "plugin describe with --check-availability and --versions",
+			args:            []string{"plugin", "describe", "foo", "--versions", "--check-availability"},
+			expectedFailure: true,
+			expected:        "if any flags in the group [check-availability versions] are set none of the others can be",
+		},
+		{
+			test:            "plugin describe with --show-signature for a plugin not installed from a discovery source",
+			plugins:         []string{"foo"},
+			versions:        []string{"v0.1.0"},
+			targets:         []configtypes.Target{configtypes.TargetK8s},
+			args:            []string{"plugin", "describe", "foo", "--show-signature", "-o", "json"},
+			expectedFailure: false,
+			expected:        `"signature": { "source": "", "image": "", "status": "unavailable", "reason": "the plugin was not installed from a discovery source", "transparencyLogBundle": false }`,
+		},
+		{
+			test:            "when wide output is requested",
+			plugins:         []string{"foo"},
+			versions:        []string{"v0.1.0"},
+			targets:         []configtypes.Target{configtypes.TargetK8s},
+			args:            []string{"plugin", "list", "-o", "wide"},
+			expectedFailure: false,
+			expected:        "NAME DESCRIPTION TARGET VERSION STATUS VENDOR PUBLISHER SIZE foo some foo description kubernetes v0.1.0 installed vmware tkg unknown",
+		},
+		{
+			test:            "when the failed installations are requested",
+			plugins:         []string{"foo"},
+			versions:        []string{"v0.1.0"},
+			targets:         []configtypes.Target{configtypes.TargetK8s},
+			args:            []string{"plugin", "list", "--failed"},
+			expectedFailure: false,
+			expected:        "NAME TARGET VERSION STATUS TIME REASON",
+			unexpected:      "some foo description",
+		},
+		{
+			test:            "when compact json output is requested",
+			plugins:         []string{"foo"},
+			versions:        []string{"v0.1.0"},
+			targets:         []configtypes.Target{configtypes.TargetK8s},
+			args:            []string{"plugin", "list", "--json-compact"},
+			expectedFailure: false,
+			expected:        `[{"context":"","description":"some foo description","name":"foo","status":"installed","target":"kubernetes","version":"v0.1.0"}]`,
+		},
+		{
+			test:            "when compact json output is requested with the columns in any order",
+			plugins:         []string{"foo"},
+			versions:        []string{"v0.1.0"},
+			targets:         []configtypes.Target{configtypes.TargetK8s},
+			args:            []string{"plugin", "list", "-o", "json", "--json-compact", "--columns", "version,name,vendor"},
+			expectedFailure: false,
+			expected:        `[{"name":"foo","vendor":"vmware","version":"v0.1.0"}]`,
+		},
+		{
+			test:            "when compact json output is requested without any failed installation",
+			args:            []string{"plugin", "list", "--failed", "--json-compact"},
+			expectedFailure: false,
+			expected:        `[]`,
+		},
+		{
+			test:            "when compact json output is requested with the yaml output",
+			args:            []string{"plugin", "list", "-o", "yaml", "--json-compact"},
+			expectedFailure: true,
+			expected:        `the --json-compact flag cannot be used with the "yaml" output format`,
+		},
+		{
+			test:            "when a page of the plugins is requested",
+			plugins:         []string{"foo", "bar"},
+			versions:        []string{"v0.1.0", "v0.2.0"},
+			targets:         []configtypes.Target{configtypes.TargetTMC, configtypes.TargetK8s},
+			args:            []string{"plugin", "list", "--limit", "1", "--offset", "1"},
+			expectedFailure: false,
+			expected:        "NAME DESCRIPTION TARGET VERSION STATUS foo some foo description mission-control v0.1.0 installed Showing 1 of 2 plugins",
+			unexpected:      "some bar description",
+		},
+		{
+			test:            "when a negative offset is requested",
+			args:            []string{"plugin", "list", "--limit", "10", "--offset", "-1"},
+			expectedFailure: true,
+			expected:        "the values of the --limit and --offset flags cannot be negative",
+		},
+		{
+			test:            "no --limit and --failed together",
+			args:            []string{"plugin", "list", "--limit", "10", "--failed"},
+			expectedFailure: true,
+			expected:        "if any flags in the group [limit failed] are set none of the others can be",
+		},
+		{
+			test:            "no --failed and --db together",
+			args:            []string{"plugin", "list", "--failed", "--db", "plugin_inventory.db"},
+			expectedFailure: true,
+			expected:        "if any flags in the group [failed db] are set none of the others can be",
+		},
+		{
+			test:            "no --duplicates and --standalone-only together",
+			args:            []string{"plugin", "list", "--duplicates", "--standalone-only"},
+			expectedFailure: true,
+			expected:        "if any flags in the group [duplicates standalone-only] are set none of the others can be",
+		},
+		{
+			test:            "no --explain and --failed together",
+			args:            []string{"plugin", "list", "--explain", "login", "--failed"},
+			expectedFailure: true,
+			expected:        "if any flags in the group [explain failed] are set none of the others can be",
 		},
 	}
 
@@ -133,6 +399,8 @@ func TestPluginList(t *testing.T) {
 					InstallationPath: pluginInstallationPath,
 					Status:           common.PluginStatusInstalled,
 					Target:           spec.targets[i],
+					Vendor:           "vmware",
+					Publisher:        "tkg",
 				}
 				assert.Nil(err)
 				err = cc.Upsert(pi)
@@ -164,6 +432,11 @@ func TestPluginList(t *testing.T) {
 					assert.Contains(strings.Join(strings.Fields(string(got)), " "), spec.expected)
 				}
 			}
+			if spec.unexpected != "" {
+				got, err := io.ReadAll(b)
+				assert.Nil(err)
+				assert.NotContains(string(got), spec.unexpected)
+			}
 		})
 		os.Unsetenv("TEST_CUSTOM_CATALOG_CACHE_DIR")
 		os.Unsetenv("TANZU_CONFIG")
@@ -173,6 +446,51 @@ func TestPluginList(t *testing.T) {
 	}
 }
 
+func TestPluginListFromInventoryDB(t *testing.T) {
+	assert := assert.New(t)
+
+	defer setupPluginSourceForTesting(t)()
+	dbFile := getTestPluginInventoryDBFile()
+
+	rootCmd, err := NewRootCmd()
+	assert.Nil(err)
+	rootCmd.SetArgs([]string{"plugin", "list", "--db", dbFile})
+	b := bytes.NewBufferString("")
+	rootCmd.SetOut(b)
+	assert.Nil(rootCmd.Execute())
+	resetPluginCommandFlags()
+
+	got := strings.Join(strings.Fields(b.String()), " ")
+	assert.Contains(got, "NAME DESCRIPTION TARGET LATEST")
+	assert.Contains(got, "management-cluster Plugin management-cluster/kubernetes description kubernetes v0.1.0")
+
+	// A page of the plugins of the inventory DB
+	rootCmd, err = NewRootCmd()
+	assert.Nil(err)
+	rootCmd.SetArgs([]string{"plugin", "list", "--db", dbFile, "--limit", "1"})
+	b = bytes.NewBufferString("")
+	rootCmd.SetOut(b)
+	assert.Nil(rootCmd.Execute())
+	resetPluginCommandFlags()
+
+	got = strings.Join(strings.Fields(b.String()), " ")
+	assert.Regexp(`Showing 1 of [0-9]+ plugins`, got)
+	assert.NotContains(got, "Showing 1 of 1 plugins")
+
+	// A file which is not an inventory DB
+	notADB, err := os.CreateTemp("", "not-a-db")
+	assert.Nil(err)
+	defer os.Remove(notADB.Name())
+
+	rootCmd, err = NewRootCmd()
+	assert.Nil(err)
+	rootCmd.SetArgs([]string{"plugin", "list", "--db", notADB.Name()})
+	err = rootCmd.Execute()
+	resetPluginCommandFlags()
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "is not a valid plugin inventory DB")
+}
+
 func TestDeletePlugin(t *testing.T) {
 	tests := []struct {
 		test             string
@@ -238,6 +556,33 @@ func TestDeletePlugin(t *testing.T) {
 			args:             []string{"plugin", "delete", "all", "--target", string(configtypes.TargetK8s), "-y"},
 			expectedFailure:  false,
 		},
+		{
+			test:             "clean the installed plugins of a target",
+			plugins:          []string{"foo", "bar", "spaz"},
+			remainingPlugins: []bool{false, true, false},
+			versions:         []string{"v0.1.0", "v0.2.0", "v0.3.0"},
+			targets:          []configtypes.Target{configtypes.TargetTMC, configtypes.TargetK8s, configtypes.TargetTMC},
+			args:             []string{"plugin", "clean", "--target", string(configtypes.TargetTMC), "-y"},
+			expectedFailure:  false,
+		},
+		{
+			test:             "clean the installed plugins of an invalid target",
+			plugins:          []string{"foo"},
+			versions:         []string{"v0.1.0"},
+			targets:          []configtypes.Target{configtypes.TargetK8s},
+			args:             []string{"plugin", "clean", "--target", "invalid", "-y"},
+			expectedFailure:  true,
+			expectedErrorMsg: invalidTargetMsg,
+		},
+		{
+			test:             "clean the installed plugins of a discovery source without any installed plugins",
+			plugins:          []string{"foo"},
+			versions:         []string{"v0.1.0"},
+			targets:          []configtypes.Target{configtypes.TargetK8s},
+			args:             []string{"plugin", "clean", "--source", "other", "-y"},
+			expectedFailure:  true,
+			expectedErrorMsg: "unable to find any installed plugins from discovery source 'other'",
+		},
 	}
 
 	for _, spec := range tests {
@@ -375,6 +720,120 @@ func TestInstallPlugin(t *testing.T) {
 			expectedFailure:  true,
 			expectedErrorMsg: "if any flags in the group [group version] are set none of the others can be",
 		},
+		{
+			test:             "no --exclude and --only together",
+			args:             []string{"plugin", "install", "--group", "testgroup", "--exclude", "plugin1", "--only", "plugin2"},
+			expectedFailure:  true,
+			expectedErrorMsg: "if any flags in the group [exclude only] are set none of the others can be",
+		},
+		{
+			test:             "no --exclude without --group",
+			args:             []string{"plugin", "install", "--exclude", "plugin1", "myplugin"},
+			expectedFailure:  true,
+			expectedErrorMsg: "the '--exclude' and '--only' flags can only be used with the '--group' flag",
+		},
+		{
+			test:             "no --only with a plugin name",
+			args:             []string{"plugin", "install", "--group", "testgroup", "--only", "plugin1", "myplugin"},
+			expectedFailure:  true,
+			expectedErrorMsg: "the '--exclude' and '--only' flags can only be used when installing all the plugins of a group",
+		},
+		{
+			test:             "no --from-group and --group together",
+			args:             []string{"plugin", "install", "--group", "testgroup", "--from-group", "testgroup", "myplugin"},
+			expectedFailure:  true,
+			expectedErrorMsg: "if any flags in the group [from-group group] are set none of the others can be",
+		},
+		{
+			test:             "no --from-group and --version together",
+			args:             []string{"plugin", "install", "--version", "v1.0.0", "--from-group", "testgroup", "myplugin"},
+			expectedFailure:  true,
+			expectedErrorMsg: "if any flags in the group [from-group version] are set none of the others can be",
+		},
+		{
+			test:             "no --force and --binary together",
+			args:             []string{"plugin", "install", "--binary", "./myplugin", "--force"},
+			expectedFailure:  true,
+			expectedErrorMsg: "if any flags in the group [force binary] are set none of the others can be",
+		},
+		{
+			test:             "no --digest and --version together",
+			args:             []string{"plugin", "install", "myplugin", "--digest", "sha256:1234", "--version", "v1.0.0"},
+			expectedFailure:  true,
+			expectedErrorMsg: "if any flags in the group [digest version] are set none of the others can be",
+		},
+		{
+			test:             "no --install-dir and --group together",
+			args:             []string{"plugin", "install", "--group", "testgroup", "--install-dir", "/tmp/plugins", "all"},
+			expectedFailure:  true,
+			expectedErrorMsg: "if any flags in the group [install-dir group] are set none of the others can be",
+		},
+		{
+			test:             "no --install-dir and --local-source together",
+			args:             []string{"plugin", "install", "--local-source", "./", "--install-dir", "/tmp/plugins", "myplugin"},
+			expectedFailure:  true,
+			expectedErrorMsg: "if any flags in the group [install-dir local-source] are set none of the others can be",
+		},
+		{
+			test:             "no 'all' with --from-group",
+			args:             []string{"plugin", "install", "--from-group", "testgroup", "all"},
+			expectedFailure:  true,
+			expectedErrorMsg: "the 'all' argument can only be used with the '--group' flag",
+		},
+		{
+			test:             "no --wait-verify and --local-source together",
+			args:             []string{"plugin", "install", "--wait-verify", "--local-source", "./", "myplugin"},
+			expectedFailure:  true,
+			expectedErrorMsg: "if any flags in the group [wait-verify local-source] are set none of the others can be",
+		},
+		{
+			test:             "no --binary and --version together",
+			args:             []string{"plugin", "install", "--binary", "./tanzu-myplugin", "--version", "v1.1.1"},
+			expectedFailure:  true,
+			expectedErrorMsg: "if any flags in the group [binary version] are set none of the others can be",
+		},
+		{
+			test:             "no plugin name with --binary",
+			args:             []string{"plugin", "install", "--binary", "./tanzu-myplugin", "myplugin"},
+			expectedFailure:  true,
+			expectedErrorMsg: "the plugin name cannot be specified when using the '--binary' flag",
+		},
+		{
+			test:             "no --reinstall and --dry-run together",
+			args:             []string{"plugin", "install", "--reinstall", "--dry-run", "myplugin"},
+			expectedFailure:  true,
+			expectedErrorMsg: "if any flags in the group [reinstall dry-run] are set none of the others can be",
+		},
+		{
+			test:             "no --output and --dry-run together",
+			args:             []string{"plugin", "install", "--output", "json", "--dry-run", "myplugin"},
+			expectedFailure:  true,
+			expectedErrorMsg: "if any flags in the group [output dry-run] are set none of the others can be",
+		},
+		{
+			test:             "invalid output format",
+			args:             []string{"plugin", "install", "--output", "table", "myplugin"},
+			expectedFailure:  true,
+			expectedErrorMsg: "invalid output format 'table' for the installed plugins, valid formats are: json, yaml",
+		},
+		{
+			test:             "malformed platform",
+			args:             []string{"plugin", "install", "--platform", "linux-arm64", "myplugin"},
+			expectedFailure:  true,
+			expectedErrorMsg: "invalid platform \"linux-arm64\", the platform must be specified as <os>/<arch>",
+		},
+		{
+			test:             "unsupported platform",
+			args:             []string{"plugin", "install", "--platform", "plan9/arm64", "myplugin"},
+			expectedFailure:  true,
+			expectedErrorMsg: "unsupported platform \"plan9/arm64\", the supported platforms are: linux/386, windows/386, linux/amd64",
+		},
+		{
+			test:             "no --platform and --binary together",
+			args:             []string{"plugin", "install", "--platform", "linux/arm64", "--binary", "./tanzu-myplugin"},
+			expectedFailure:  true,
+			expectedErrorMsg: "if any flags in the group [platform binary] are set none of the others can be",
+		},
 	}
 
 	assert := assert.New(t)
@@ -417,6 +876,163 @@ func TestInstallPlugin(t *testing.T) {
 	}
 }
 
+func TestVerifyDiscoverySourcesSignature(t *testing.T) {
+	assert := assert.New(t)
+
+	cleanup := setupPluginSourceForTesting(t)
+	defer cleanup()
+
+	// The verification of the signature of the discovery image is skipped
+	// so that no registry is accessed
+	image := "example.com/tanzu_cli/plugins/plugin-inventory:latest"
+	os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, image)
+	os.Setenv(constants.SuppressSkipSignatureVerificationWarning, "true")
+	defer os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+	defer os.Unsetenv(constants.SuppressSkipSignatureVerificationWarning)
+
+	var out bytes.Buffer
+	err := verifyDiscoverySourcesSignature(&out)
+	assert.Nil(err)
+	assert.Equal(fmt.Sprintf("signature verification skipped for %s\n", image), out.String())
+}
+
+func TestPluginStatusWithDeprecation(t *testing.T) {
+	assert := assert.New(t)
+
+	standalonePlugin := cli.PluginInfo{Name: "myplugin", Target: configtypes.TargetK8s, Version: "v1.0.0"}
+	otherPlugin := cli.PluginInfo{Name: "myplugin", Target: configtypes.TargetTMC, Version: "v1.0.0"}
+	deprecations := map[string]string{
+		catalog.PluginNameTarget(standalonePlugin.Name, standalonePlugin.Target): "please upgrade",
+	}
+	assert.Equal("installed (deprecated)", getStandalonePluginStatus(&standalonePlugin, common.PluginStatusInstalled, deprecations, nil))
+	assert.Equal(common.PluginStatusInstalled, getStandalonePluginStatus(&otherPlugin, common.PluginStatusInstalled, deprecations, nil))
+
+	contextPlugin := discovery.Discovered{
+		Name:               "myplugin",
+		Target:             configtypes.TargetK8s,
+		DeprecatedVersions: map[string]string{"v1.0.0": ""},
+	}
+	assert.Equal("update available (deprecated)", getContextPluginStatus(&contextPlugin, "v1.0.0", common.PluginStatusUpdateAvailable))
+	assert.Equal(common.PluginStatusInstalled, getContextPluginStatus(&contextPlugin, "v2.0.0", common.PluginStatusInstalled))
+
+	contextPlugin.Deprecated = true
+	assert.Equal("not installed (deprecated)", getContextPluginStatus(&contextPlugin, "v2.0.0", common.PluginStatusNotInstalled))
+}
+
+func TestDisplayPluginVersionDescriptionWithLinks(t *testing.T) {
+	assert := assert.New(t)
+	defer resetPluginCommandFlags()
+
+	pvd := &pluginmanager.PluginVersionDescription{
+		Name:        "myplugin",
+		Version:     "v1.0.0",
+		Target:      configtypes.TargetK8s,
+		PluginLinks: pluginmanager.PluginLinks{Documentation: "https://example.com/docs"},
+	}
+	var out bytes.Buffer
+	displayPluginVersionDescription(pvd, &out)
+	assert.Contains(out.String(), "DOCUMENTATION")
+	assert.Contains(out.String(), "https://example.com/docs")
+	// The URLs that are not provided are omitted
+	assert.NotContains(out.String(), "HOMEPAGE")
+
+	outputFormat = "json"
+	out.Reset()
+	displayPluginVersionDescription(pvd, &out)
+	assert.Contains(out.String(), `"documentation": "https://example.com/docs"`)
+	assert.NotContains(out.String(), "homepage")
+}
+
+func TestPluginStatusWithUnavailableVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	standalonePlugin := cli.PluginInfo{Name: "myplugin", Target: configtypes.TargetK8s, Version: "v1.0.0"}
+	otherPlugin := cli.PluginInfo{Name: "myplugin", Target: configtypes.TargetTMC, Version: "v1.0.0"}
+	unavailable := map[string]bool{
+		catalog.PluginNameTarget(standalonePlugin.Name, standalonePlugin.Target): true,
+	}
+	assert.Equal("installed (unavailable)", getStandalonePluginStatus(&standalonePlugin, common.PluginStatusInstalled, nil, unavailable))
+	assert.Equal(common.PluginStatusInstalled, getStandalonePluginStatus(&otherPlugin, common.PluginStatusInstalled, nil, unavailable))
+
+	contextPlugin := discovery.Discovered{
+		Name:               "myplugin",
+		Target:             configtypes.TargetK8s,
+		RecommendedVersion: "v2.0.0",
+		SupportedVersions:  []string{"v2.0.0"},
+		InstalledVersion:   "v1.0.0",
+	}
+	assert.Equal("installed (unavailable)", getContextPluginStatus(&contextPlugin, "v1.0.0", common.PluginStatusInstalled))
+	// An outdated plugin is not marked again
+	assert.Equal(common.PluginStatusOutdated, getContextPluginStatus(&contextPlugin, "v1.0.0", common.PluginStatusOutdated))
+
+	contextPlugin.InstalledVersion = "v2.0.0"
+	assert.Equal(common.PluginStatusInstalled, getContextPluginStatus(&contextPlugin, "v2.0.0", common.PluginStatusInstalled))
+
+	// The supported versions are unknown
+	contextPlugin.SupportedVersions = nil
+	contextPlugin.InstalledVersion = "v1.0.0"
+	assert.Equal(common.PluginStatusInstalled, getContextPluginStatus(&contextPlugin, "v1.0.0", common.PluginStatusInstalled))
+}
+
+func TestPluginListSize(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("unknown", formatPluginSize(0))
+	assert.Equal("512", formatPluginSize(512))
+	assert.Equal("1.5Ki", formatPluginSize(1536))
+	assert.Equal("48.2Mi", formatPluginSize(50541363))
+	assert.Equal("2.0Gi", formatPluginSize(2*1024*1024*1024))
+
+	standalonePlugin := cli.PluginInfo{Name: "myplugin", Target: configtypes.TargetK8s, Version: "v1.0.0"}
+	sizes := map[string]int64{
+		catalog.PluginNameTarget(standalonePlugin.Name, standalonePlugin.Target): 52428800,
+	}
+	assert.Equal("50.0Mi", standalonePluginListRow(&standalonePlugin, common.PluginStatusInstalled, sizes).value("size"))
+	assert.Equal("unknown", standalonePluginListRow(&standalonePlugin, common.PluginStatusInstalled, nil).value("size"))
+
+	contextPlugin := discovery.Discovered{
+		Name:   "myplugin",
+		Target: configtypes.TargetK8s,
+		Distribution: distribution.Artifacts{
+			"v2.0.0": []distribution.Artifact{
+				{OS: cli.GOOS, Arch: cli.GOARCH, Size: 1048576},
+			},
+		},
+	}
+	assert.Equal("1.0Mi", contextPluginListRow(&contextPlugin, "v2.0.0", common.PluginStatusNotInstalled).value("size"))
+	// The inventory does not record the size of that version
+	assert.Equal("unknown", contextPluginListRow(&contextPlugin, "v1.0.0", common.PluginStatusNotInstalled).value("size"))
+}
+
+func TestPluginListPage(t *testing.T) {
+	assert := assert.New(t)
+
+	standalonePlugins := []cli.PluginInfo{{Name: "a"}, {Name: "b"}}
+	installedContextPlugins := []discovery.Discovered{{Name: "c"}}
+	missingContextPlugins := []discovery.Discovered{{Name: "d"}, {Name: "e"}}
+
+	// The page spans the three kinds of plugins
+	s, i, m, total := pagePluginList(standalonePlugins, installedContextPlugins, missingContextPlugins, 1, 3)
+	assert.Equal(5, total)
+	assert.Equal([]cli.PluginInfo{{Name: "b"}}, s)
+	assert.Equal([]discovery.Discovered{{Name: "c"}}, i)
+	assert.Equal([]discovery.Discovered{{Name: "d"}}, m)
+
+	// Without a limit, all the remaining plugins are listed
+	s, i, m, total = pagePluginList(standalonePlugins, installedContextPlugins, missingContextPlugins, 3, 0)
+	assert.Equal(5, total)
+	assert.Empty(s)
+	assert.Empty(i)
+	assert.Equal([]discovery.Discovered{{Name: "d"}, {Name: "e"}}, m)
+
+	// Beyond the last plugin
+	s, i, m, total = pagePluginList(standalonePlugins, installedContextPlugins, missingContextPlugins, 10, 2)
+	assert.Equal(5, total)
+	assert.Empty(s)
+	assert.Empty(i)
+	assert.Empty(m)
+}
+
 func TestUpgradePlugin(t *testing.T) {
 	tests := []struct {
 		test             string
@@ -430,6 +1046,24 @@ func TestUpgradePlugin(t *testing.T) {
 			expectedFailure:  true,
 			expectedErrorMsg: invalidTargetMsg,
 		},
+		{
+			test:             "plugin name with --all",
+			args:             []string{"plugin", "upgrade", "--all", "myplugin"},
+			expectedFailure:  true,
+			expectedErrorMsg: "the --all flag cannot be used with a plugin name",
+		},
+		{
+			test:             "no plugin name",
+			args:             []string{"plugin", "upgrade"},
+			expectedFailure:  true,
+			expectedErrorMsg: "must provide plugin name as positional argument",
+		},
+		{
+			test:             "output without dry-run",
+			args:             []string{"plugin", "upgrade", "myplugin", "-o", "json"},
+			expectedFailure:  true,
+			expectedErrorMsg: "the --output flag can only be used with --dry-run",
+		},
 	}
 
 	assert := assert.New(t)
@@ -468,6 +1102,143 @@ func TestUpgradePlugin(t *testing.T) {
 			if spec.expectedErrorMsg != "" {
 				assert.Contains(err.Error(), spec.expectedErrorMsg)
 			}
+			resetPluginCommandFlags()
+		})
+	}
+}
+
+func TestReinstallPlugin(t *testing.T) {
+	tests := []struct {
+		test             string
+		args             []string
+		expectedErrorMsg string
+		expectedFailure  bool
+	}{
+		{
+			test:             "invalid target",
+			args:             []string{"plugin", "reinstall", "--target", "invalid", "myplugin"},
+			expectedFailure:  true,
+			expectedErrorMsg: invalidTargetMsg,
+		},
+		{
+			test:             "no plugin name",
+			args:             []string{"plugin", "reinstall"},
+			expectedFailure:  true,
+			expectedErrorMsg: "must provide one plugin name as a positional argument",
+		},
+		{
+			test:             "all plugins",
+			args:             []string{"plugin", "reinstall", "all"},
+			expectedFailure:  true,
+			expectedErrorMsg: "the 'all' argument cannot be used to reinstall plugins",
+		},
+	}
+
+	assert := assert.New(t)
+
+	tkgConfigFile, err := os.CreateTemp("", "config")
+	assert.Nil(err)
+	os.Setenv("TANZU_CONFIG", tkgConfigFile.Name())
+
+	tkgConfigFileNG, err := os.CreateTemp("", "config_ng")
+	assert.Nil(err)
+	os.Setenv("TANZU_CONFIG_NEXT_GEN", tkgConfigFileNG.Name())
+	os.Setenv("TANZU_CLI_CEIP_OPT_IN_PROMPT_ANSWER", "No")
+	os.Setenv("TANZU_CLI_EULA_PROMPT_ANSWER", "Yes")
+
+	featureArray := strings.Split(constants.FeatureContextCommand, ".")
+	err = config.SetFeature(featureArray[1], featureArray[2], "true")
+	assert.Nil(err)
+
+	defer func() {
+		os.Unsetenv("TANZU_CONFIG")
+		os.Unsetenv("TANZU_CONFIG_NEXT_GEN")
+		os.Unsetenv("TANZU_CLI_CEIP_OPT_IN_PROMPT_ANSWER")
+		os.Unsetenv("TANZU_CLI_EULA_PROMPT_ANSWER")
+		os.RemoveAll(tkgConfigFile.Name())
+		os.RemoveAll(tkgConfigFileNG.Name())
+	}()
+
+	for _, spec := range tests {
+		t.Run(spec.test, func(t *testing.T) {
+			rootCmd, err := NewRootCmd()
+			assert.Nil(err)
+			rootCmd.SetArgs(spec.args)
+
+			err = rootCmd.Execute()
+			assert.Equal(err != nil, spec.expectedFailure)
+			if spec.expectedErrorMsg != "" {
+				assert.Contains(err.Error(), spec.expectedErrorMsg)
+			}
+			resetPluginCommandFlags()
+		})
+	}
+}
+
+func TestSyncPlugin(t *testing.T) {
+	tests := []struct {
+		test             string
+		args             []string
+		expectedErrorMsg string
+		expectedFailure  bool
+	}{
+		{
+			test:             "output without dry-run",
+			args:             []string{"plugin", "sync", "-o", "json"},
+			expectedFailure:  true,
+			expectedErrorMsg: "the --output flag can only be used with --dry-run",
+		},
+		{
+			test:             "invalid concurrency",
+			args:             []string{"plugin", "sync", "--concurrency", "0"},
+			expectedFailure:  true,
+			expectedErrorMsg: "the value of the --concurrency flag must be at least 1",
+		},
+		{
+			test:             "yes without prune",
+			args:             []string{"plugin", "sync", "--yes"},
+			expectedFailure:  true,
+			expectedErrorMsg: "the --yes flag can only be used with --prune",
+		},
+	}
+
+	assert := assert.New(t)
+
+	tkgConfigFile, err := os.CreateTemp("", "config")
+	assert.Nil(err)
+	os.Setenv("TANZU_CONFIG", tkgConfigFile.Name())
+
+	tkgConfigFileNG, err := os.CreateTemp("", "config_ng")
+	assert.Nil(err)
+	os.Setenv("TANZU_CONFIG_NEXT_GEN", tkgConfigFileNG.Name())
+	os.Setenv("TANZU_CLI_CEIP_OPT_IN_PROMPT_ANSWER", "No")
+	os.Setenv("TANZU_CLI_EULA_PROMPT_ANSWER", "Yes")
+
+	featureArray := strings.Split(constants.FeatureContextCommand, ".")
+	err = config.SetFeature(featureArray[1], featureArray[2], "true")
+	assert.Nil(err)
+
+	defer func() {
+		os.Unsetenv("TANZU_CONFIG")
+		os.Unsetenv("TANZU_CONFIG_NEXT_GEN")
+		os.Unsetenv("TANZU_CLI_CEIP_OPT_IN_PROMPT_ANSWER")
+		os.Unsetenv("TANZU_CLI_EULA_PROMPT_ANSWER")
+		os.RemoveAll(tkgConfigFile.Name())
+		os.RemoveAll(tkgConfigFileNG.Name())
+	}()
+
+	for _, spec := range tests {
+		t.Run(spec.test, func(t *testing.T) {
+			rootCmd, err := NewRootCmd()
+			assert.Nil(err)
+			rootCmd.SetArgs(spec.args)
+
+			err = rootCmd.Execute()
+			assert.Equal(err != nil, spec.expectedFailure)
+			if spec.expectedErrorMsg != "" {
+				assert.Contains(err.Error(), spec.expectedErrorMsg)
+			}
+			resetPluginCommandFlags()
 		})
 	}
 }
@@ -498,7 +1269,7 @@ func TestCompletionPlugin(t *testing.T) {
 			test: "completion for the --output flag value of the plugin list command",
 			args: []string{"__complete", "plugin", "list", "--output", ""},
 			// ":4" is the value of the ShellCompDirectiveNoFileComp
-			expected: expectedOutForOutputFlag + ":4\n",
+			expected: compTableOutput + "\n" + compWideOutput + "\n" + compJSONOutput + "\n" + compYAMLOutput + "\n" + compNameOutput + "\n" + compNameTargetOutput + "\n:4\n",
 		},
 		// =====================
 		// tanzu plugin clean
@@ -509,6 +1280,15 @@ func TestCompletionPlugin(t *testing.T) {
 			// ":4" is the value of the ShellCompDirectiveNoFileComp
 			expected: "_activeHelp_ " + compNoMoreArgsMsg + "\n:4\n",
 		},
+		{
+			test: "completion for the --target flag value of the plugin clean command",
+			args: []string{"__complete", "plugin", "clean", "--target", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: compGlobalTarget + "\n" +
+				compK8sTarget + "\n" +
+				compTMCTarget + "\n" +
+				":4\n",
+		},
 		// =====================
 		// tanzu plugin sync
 		// =====================
@@ -518,6 +1298,12 @@ func TestCompletionPlugin(t *testing.T) {
 			// ":4" is the value of the ShellCompDirectiveNoFileComp
 			expected: "_activeHelp_ " + compNoMoreArgsMsg + "\n:4\n",
 		},
+		{
+			test: "completion for the --output flag value of the plugin sync command",
+			args: []string{"__complete", "plugin", "sync", "--dry-run", "--output", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: expectedOutForOutputFlag + ":4\n",
+		},
 		// =====================
 		// tanzu plugin install
 		// =====================
@@ -981,7 +1767,60 @@ func resetPluginCommandFlags() {
 	targetStr = ""
 	group = ""
 	showNonMandatory = false
+	groupDiff = false
 	groupID = ""
 	showDetails = false
 	pluginName = ""
+	dryRun = false
+	upgradeAll = false
+	groupExclude = nil
+	groupOnly = nil
+	fromGroup = ""
+	installDir = ""
+	installDigest = ""
+	forceInstall = false
+	showVersions = false
+	syncSource = ""
+	cleanSource = ""
+	inventoryDB = ""
+	syncConcurrency = 1
+	syncStrict = false
+	syncPrune = false
+	pruneCache = false
+	skipCompletionRefresh = false
+	listLimit = 0
+	listOffset = 0
+	includePrerelease = false
+	binaryPath = ""
+	sortBy = ""
+	prefetchParallel = false
+	describeVersion = ""
+	maxCacheSize = ""
+	maxArtifactCacheSize = ""
+	reverseSort = false
+	waitVerify = false
+	discoveryProfile = ""
+	maxCacheAge = 0
+	registryCACert = ""
+	forceRefresh = false
+	pluginTimeout = 0
+	reinstall = false
+	standaloneOnly = false
+	contextOnly = false
+	listContext = ""
+	searchOnline = false
+	searchOffline = false
+	skipPostInstall = false
+	listColumns = ""
+	showSignature = false
+	checkAvailability = false
+	platform = ""
+	listFailed = false
+	listDuplicates = false
+	listExplain = ""
+	listJSONCompact = false
+	copySourceGroups = []string{}
+	loginUsername = ""
+	loginPasswordStdin = false
+	loginCredentialHelper = ""
 }
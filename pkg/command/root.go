@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
@@ -17,9 +18,12 @@ import (
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	cliconfig "github.com/vmware-tanzu/tanzu-cli/pkg/config"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/interrupt"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/telemetry"
@@ -27,9 +31,22 @@ import (
 
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/config"
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/plugin"
 )
 
+// quiet suppresses the informational and success messages of the CLI
+var quiet bool
+
+// backgroundRefreshGracePeriod is how long the CLI waits, once the command is done,
+// for the plugin inventories being refreshed in the background.  It is kept short so
+// that the user does not wait for a refresh; a refresh stopped by the exit of the CLI
+// leaves the previous inventory in the cache and is resumed by a later command.
+const backgroundRefreshGracePeriod = time.Second
+
+// stopTimeout stops the timeout of the command, if any, once the command is done
+var stopTimeout = func() {}
+
 // NewRootCmd creates a root command.
 func NewRootCmd() (*cobra.Command, error) {
 	var rootCmd = newRootCmd()
@@ -139,6 +156,27 @@ func newRootCmd() *cobra.Command {
 		// Flag parsing must be deactivated because the root plugin won't know about all flags.
 		DisableFlagParsing: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Only the informational and success messages are not written, so that both
+			// the actual command output and the warnings and errors are kept
+			clilog.SetQuiet(quiet)
+
+			// The discovery profile is only selected for the duration of the command
+			// and is therefore not persisted in the configuration
+			if discoveryProfile != "" {
+				os.Setenv(constants.ConfigVariablePluginDiscoveryProfile, discoveryProfile)
+			}
+			if maxCacheAge > 0 {
+				os.Setenv(constants.ConfigVariablePluginDiscoveryMaxCacheAge, maxCacheAge.String())
+			}
+			if registryCACert != "" {
+				os.Setenv(constants.ConfigVariableRegistryCACert, registryCACert)
+			}
+			if forceRefresh {
+				os.Setenv(constants.ConfigVariablePluginDiscoveryForceRefresh, "true")
+			}
+			// The timeout bounds everything the command does, including the discovery of the plugins
+			stopTimeout = interrupt.StartTimeout(pluginTimeout)
+
 			// Ensure mutual exclusion in current contexts just in case if any plugins with old
 			// plugin-runtime sets k8s context as current when tanzu context is already set as current
 			if err := utils.EnsureMutualExclusiveCurrentContexts(); err != nil {
@@ -186,6 +224,8 @@ func newRootCmd() *cobra.Command {
 			return nil
 		},
 	}
+	rootCmd.PersistentFlags().BoolVar(&quiet, "quiet", false, "suppress informational and success messages")
+
 	return rootCmd
 }
 
@@ -218,7 +258,7 @@ func InstallEssentialPlugins(cmd *cobra.Command) {
 	}
 	if !skipEssentials {
 		// Check if essential plugins are installed and up to date if not Install or Upgrade the Essential plugins
-		_, _ = pluginmanager.InstallPluginsFromEssentialPluginGroup()
+		_ = pluginmanager.EnsureEssentialPlugins()
 	}
 }
 
@@ -373,11 +413,39 @@ func Execute() error {
 	if err != nil {
 		return err
 	}
-	executionErr := root.Execute()
+	// An interrupted command that does not return promptly is not waited for
+	executionErr := interrupt.Run(root.Execute)
+	stopTimeout()
+	if err := interrupt.TimedOut(); err != nil {
+		// Whatever the command returned once interrupted, report that it timed out
+		log.V(4).Infof("The command returned after timing out: %v", executionErr)
+		executionErr = err
+	} else if interrupt.InterruptedBySignal() {
+		log.V(4).Infof("The command returned after being interrupted: %v", executionErr)
+		executionErr = interrupt.ErrInterrupted
+	}
+	if interrupt.Interrupted() {
+		// Clean up after the operations the command did not complete
+		interrupt.Cleanup()
+		if interrupt.InterruptedBySignal() {
+			fmt.Fprintln(os.Stderr, "Interrupted, cleaned up")
+		}
+	}
+	// The output of the command is complete, let the background refreshes
+	// of the plugin inventories finish for the next commands
+	if !discovery.WaitForBackgroundRefreshes(backgroundRefreshGracePeriod) {
+		log.V(4).Info("Some plugin inventories could not be refreshed in the background before the CLI exited")
+		// The refreshes must not update the cache from now on, and their temporary
+		// files must be removed as the CLI exits while they are still downloading
+		discovery.StopBackgroundRefreshes()
+		interrupt.Cleanup()
+	}
 	exitCode := 0
 	if executionErr != nil {
 		exitCode = 1
-		if errStr, ok := executionErr.(*exec.ExitError); ok {
+		if errors.Is(executionErr, interrupt.ErrInterrupted) {
+			exitCode = interrupt.ExitCode
+		} else if errStr, ok := executionErr.(*exec.ExitError); ok {
 			// If a plugin exited with an error, we don't want to print its
 			// exit status as a string, but want to use it as our own exit code.
 			exitCode = (errStr.ExitCode())
@@ -16,10 +16,12 @@ import (
 
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/config"
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/plugin"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 	configcli "github.com/vmware-tanzu/tanzu-cli/pkg/config"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 )
@@ -382,6 +384,55 @@ func TestEnvVarsSet(t *testing.T) {
 	os.Unsetenv(envVarName)
 }
 
+func TestQuietFlag(t *testing.T) {
+	assert := assert.New(t)
+
+	configFile, _ := os.CreateTemp("", "config")
+	os.Setenv(config.EnvConfigKey, configFile.Name())
+	defer os.RemoveAll(configFile.Name())
+
+	configFileNG, _ := os.CreateTemp("", "config_ng")
+	os.Setenv(config.EnvConfigNextGenKey, configFileNG.Name())
+	defer os.RemoveAll(configFileNG.Name())
+
+	os.Setenv(constants.CEIPOptInUserPromptAnswer, "No")
+	os.Setenv(constants.EULAPromptAnswer, "Yes")
+
+	for _, quietFlag := range []bool{false, true} {
+		rootCmd, err := NewRootCmd()
+		assert.Nil(err)
+
+		args := []string{"plugin", "source", "init"}
+		if quietFlag {
+			args = append(args, "--quiet")
+		}
+		rootCmd.SetArgs(args)
+		b := bytes.NewBufferString("")
+		rootCmd.SetOut(b)
+		rootCmd.SetErr(b)
+		log.SetStdout(b)
+		log.SetStderr(b)
+
+		err = rootCmd.Execute()
+		assert.Nil(err)
+		if quietFlag {
+			assert.NotContains(b.String(), "successfully initialized discovery source")
+		} else {
+			assert.Contains(b.String(), "successfully initialized discovery source")
+		}
+
+		quiet = false
+		clilog.SetQuiet(false)
+		log.SetStderr(os.Stderr)
+		log.SetStdout(os.Stdout)
+	}
+
+	os.Unsetenv(config.EnvConfigKey)
+	os.Unsetenv(config.EnvConfigNextGenKey)
+	os.Unsetenv(constants.CEIPOptInUserPromptAnswer)
+	os.Unsetenv(constants.EULAPromptAnswer)
+}
+
 func setupFakePlugin(dir, pluginName, version string, commandGroup plugin.CmdGroup, completionType uint8, target configtypes.Target, postInstallResult uint8, hidden bool, aliases []string) error {
 	filePath := filepath.Join(dir, fmt.Sprintf("%s_%s", pluginName, string(target)))
 
@@ -403,58 +454,3 @@ func setupFakePlugin(dir, pluginName, version string, commandGroup plugin.CmdGro
 
 	return nil
 }
-
-func TestCompletionShortHelpInActiveHelp(t *testing.T) {
-	tests := []struct {
-		test     string
-		args     []string
-		expected string
-	}{
-		{
-			test: "short help as active help at level 1",
-			args: []string{"__complete", "plugin", ""},
-			// ":4" is the value of the ShellCompDirectiveNoFileComp
-			expected: "clean\tClean the plugins\n" +
-				"describe\tDescribe a plugin\n" +
-				"download-bundle\tDownload plugin bundle to the local system\n" +
-				"group\tManage plugin-groups\n" +
-				"install\tInstall a plugin\n" +
-				"list\tList installed plugins\n" +
-				"search\tSearch for available plugins\n" +
-				"source\tManage plugin discovery sources\n" +
-				"sync\tInstalls all plugins recommended by the active contexts\n" +
-				"uninstall\tUninstall a plugin\n" +
-				"upgrade\tUpgrade a plugin\n" +
-				"upload-bundle\tUpload plugin bundle to a repository\n" +
-				"_activeHelp_ Command help: Manage CLI plugins\n" +
-				":4\n",
-		},
-		{
-			test: "short help as active help at level 2",
-			args: []string{"__complete", "plugin", "search", ""},
-			// ":4" is the value of the ShellCompDirectiveNoFileComp
-			expected: "_activeHelp_ Command help: Search for available plugins\n" +
-				"_activeHelp_ " + compNoMoreArgsMsg + "\n:4\n",
-		},
-	}
-
-	for _, spec := range tests {
-		t.Run(spec.test, func(t *testing.T) {
-			assert := assert.New(t)
-
-			rootCmd, err := NewRootCmd()
-			assert.Nil(err)
-
-			var out bytes.Buffer
-			rootCmd.SetOut(&out)
-			rootCmd.SetArgs(spec.args)
-
-			err = rootCmd.Execute()
-			assert.Nil(err)
-
-			assert.Equal(spec.expected, out.String())
-		})
-	}
-
-	os.Unsetenv("TANZU_ACTIVE_HELP")
-}
@@ -9,6 +9,10 @@ const (
 	PluginStatusInstalled       = "installed"
 	PluginStatusNotInstalled    = "not installed"
 	PluginStatusUpdateAvailable = "update available"
+	PluginStatusOutdated        = "outdated"
+	PluginStatusDeprecated      = "deprecated"
+	PluginStatusUnavailable     = "unavailable"
+	PluginStatusIncompatible    = "incompatible"
 	PluginScopeStandalone       = "Standalone"
 	PluginScopeContext          = "Context"
 )
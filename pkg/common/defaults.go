@@ -30,4 +30,9 @@ const (
 	// the inventory of the discovery will be downloaded and stored.
 	// It should be used as a sub-directory of the cache directory (DefaultCacheDir).
 	PluginInventoryDirName = "plugin_inventory"
+
+	// PluginArtifactDirName is the name of the directory where the downloaded plugin
+	// binaries are cached, each one in a file named after its sha256 digest.
+	// It should be used as a sub-directory of the cache directory (DefaultCacheDir).
+	PluginArtifactDirName = "plugin_artifacts"
 )
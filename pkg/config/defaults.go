@@ -129,6 +129,9 @@ func GetTrustedRegistries() []string {
 
 	// Add the configured central plugin discovery images to the trusted registries
 	discoveries, err := configlib.GetCLIDiscoverySources()
+	if err == nil {
+		discoveries, err = ApplyPluginDiscoveryProfile(discoveries)
+	}
 	if err == nil && discoveries != nil {
 		for _, discovery := range discoveries {
 			// These discoveries only support OCI images
@@ -179,6 +182,55 @@ func GetAdditionalTestDiscoveryImages() []string {
 	return additionalImages
 }
 
+// GetPluginInventoryDBFileName returns the name of the database file expected
+// inside the plugin inventory image of the specified discovery source.
+// The file name can be overridden per discovery source through the
+// "TANZU_CLI_PLUGIN_INVENTORY_DB_FILE_NAMES" variable, which holds a comma separated
+// list of "<discoveryName>=<fileName>" pairs.
+// An empty string is returned when no override is configured for the discovery.
+func GetPluginInventoryDBFileName(discoveryName string) string {
+	for _, entry := range strings.Split(os.Getenv(constants.ConfigVariablePluginInventoryDBFileNames), ",") {
+		name, fileName, found := strings.Cut(entry, "=")
+		if !found {
+			continue
+		}
+		if strings.TrimSpace(name) == discoveryName {
+			return strings.TrimSpace(fileName)
+		}
+	}
+	return ""
+}
+
+// GetPinnedPluginVersion returns the version the specified context plugin is pinned to.
+// Versions are pinned through the "TANZU_CLI_PINNED_PLUGIN_VERSIONS" variable, which
+// holds a comma separated list of "<pluginName>[:<target>]=<version>" pairs.  A pin
+// without a target applies to the plugin for any target, but a pin for the specific
+// target of the plugin takes precedence.
+// An empty string is returned when the plugin is not pinned.
+func GetPinnedPluginVersion(pluginName string, target configtypes.Target) string {
+	var version string
+	for _, entry := range strings.Split(os.Getenv(constants.ConfigVariablePinnedPluginVersions), ",") {
+		id, pinnedVersion, found := strings.Cut(entry, "=")
+		if !found {
+			continue
+		}
+		name, pinnedTarget, hasTarget := strings.Cut(strings.TrimSpace(id), ":")
+		if strings.TrimSpace(name) != pluginName {
+			continue
+		}
+		if !hasTarget {
+			if version == "" {
+				version = strings.TrimSpace(pinnedVersion)
+			}
+			continue
+		}
+		if configtypes.StringToTarget(strings.TrimSpace(pinnedTarget)) == target {
+			return strings.TrimSpace(pinnedVersion)
+		}
+	}
+	return version
+}
+
 func getHTTPURIForGCPPluginRepository(repo configtypes.GCPPluginRepository) string { //nolint:staticcheck // Deprecated
 	return fmt.Sprintf("https://storage.googleapis.com/%s/", repo.BucketName)
 }
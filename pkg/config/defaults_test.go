@@ -152,4 +152,25 @@ var _ = Describe("defaults test cases", func() {
 			Expect(err).To(BeNil())
 		})
 	})
+	Context("pinned plugin versions", func() {
+		AfterEach(func() {
+			os.Unsetenv(constants.ConfigVariablePinnedPluginVersions)
+		})
+		It("should return an empty version when no plugin is pinned", func() {
+			Expect(GetPinnedPluginVersion("cluster", types.TargetK8s)).To(BeEmpty())
+		})
+		It("should return the version the plugin is pinned to", func() {
+			os.Setenv(constants.ConfigVariablePinnedPluginVersions, "cluster=v1.0.0, package:k8s = v2.0.0,invalid")
+			Expect(GetPinnedPluginVersion("cluster", types.TargetK8s)).To(Equal("v1.0.0"))
+			Expect(GetPinnedPluginVersion("cluster", types.TargetTMC)).To(Equal("v1.0.0"))
+			Expect(GetPinnedPluginVersion("package", types.TargetK8s)).To(Equal("v2.0.0"))
+			Expect(GetPinnedPluginVersion("package", types.TargetTMC)).To(BeEmpty())
+			Expect(GetPinnedPluginVersion("invalid", types.TargetK8s)).To(BeEmpty())
+		})
+		It("should prefer the pin for the target of the plugin", func() {
+			os.Setenv(constants.ConfigVariablePinnedPluginVersions, "cluster=v1.0.0,cluster:tmc=v0.2.0")
+			Expect(GetPinnedPluginVersion("cluster", types.TargetK8s)).To(Equal("v1.0.0"))
+			Expect(GetPinnedPluginVersion("cluster", types.TargetTMC)).To(Equal("v0.2.0"))
+		})
+	})
 })
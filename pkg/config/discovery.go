@@ -4,10 +4,29 @@
 package config
 
 import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
 	configlib "github.com/vmware-tanzu/tanzu-plugin-runtime/config"
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+const (
+	// RecommendedVersionStrategyPublisher uses the version recommended by the publisher of the plugin
+	RecommendedVersionStrategyPublisher = "publisher-recommended"
+	// RecommendedVersionStrategyHighestStable uses the highest version that is not a pre-release
+	RecommendedVersionStrategyHighestStable = "highest-stable"
+	// RecommendedVersionStrategyHighestIncludingPrerelease uses the highest version, even if it is a pre-release
+	RecommendedVersionStrategyHighestIncludingPrerelease = "highest-including-prerelease"
 )
 
 func PopulateDefaultCentralDiscovery(force bool) error {
@@ -19,13 +38,316 @@ func PopulateDefaultCentralDiscovery(force bool) error {
 	// have the CLI add it again.  A user can then use "plugin source init"
 	// to add the default discovery again.
 	if force || discoverySources == nil {
-		defaultDiscovery := configtypes.PluginDiscovery{
-			OCI: &configtypes.OCIDiscovery{
-				Name:  DefaultStandaloneDiscoveryName,
-				Image: constants.TanzuCLIDefaultCentralPluginDiscoveryImage,
-			},
+		return configlib.SetCLIDiscoverySource(GetDefaultCentralDiscovery())
+	}
+	return nil
+}
+
+// GetDefaultCentralDiscovery returns the central plugin discovery the CLI ships with
+func GetDefaultCentralDiscovery() configtypes.PluginDiscovery {
+	return configtypes.PluginDiscovery{
+		OCI: &configtypes.OCIDiscovery{
+			Name:  DefaultStandaloneDiscoveryName,
+			Image: constants.TanzuCLIDefaultCentralPluginDiscoveryImage,
+		},
+	}
+}
+
+// ToEnvVariableSuffix converts a name so that it can be used as the suffix of
+// an environment variable: it is put in upper case and any character other
+// than letters and digits is replaced by '_'.
+func ToEnvVariableSuffix(name string) string {
+	return strings.ToUpper(strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name))
+}
+
+// GetPluginDiscoveryPriority returns the priority of the specified discovery source.
+// The default priority is 0; an invalid value is ignored with a warning.
+func GetPluginDiscoveryPriority(discoveryName string) int {
+	envVariable := constants.ConfigVariablePluginDiscoveryPriorityPrefix + ToEnvVariableSuffix(discoveryName)
+	value := strings.TrimSpace(os.Getenv(envVariable))
+	if value == "" {
+		return 0
+	}
+	priority, err := strconv.Atoi(value)
+	if err != nil {
+		log.Warningf("ignoring invalid value %q for %s, an integer is expected", value, envVariable)
+		return 0
+	}
+	return priority
+}
+
+// SetPluginDiscoveryPriority persists the priority of the specified discovery source
+// in the configuration.  A priority of 0, the default, removes the setting.
+func SetPluginDiscoveryPriority(discoveryName string, priority int) error {
+	envVariable := constants.ConfigVariablePluginDiscoveryPriorityPrefix + ToEnvVariableSuffix(discoveryName)
+	if priority == 0 {
+		os.Unsetenv(envVariable)
+		if _, err := configlib.GetEnv(envVariable); err != nil {
+			// The priority is not set
+			return nil
+		}
+		return configlib.DeleteEnv(envVariable)
+	}
+	value := strconv.Itoa(priority)
+	os.Setenv(envVariable, value)
+	return configlib.SetEnv(envVariable, value)
+}
+
+// GetRegistryCredentialHelper returns the credential helper storing the credentials
+// saved for the specified registry, or an empty string if none were saved.
+func GetRegistryCredentialHelper(registryHost string) string {
+	return strings.TrimSpace(os.Getenv(constants.ConfigVariableRegistryCredentialHelperPrefix + ToEnvVariableSuffix(registryHost)))
+}
+
+// SetRegistryCredentialHelper persists the credential helper storing the credentials
+// saved for the specified registry.  An empty helper removes the setting.
+func SetRegistryCredentialHelper(registryHost, helper string) error {
+	envVariable := constants.ConfigVariableRegistryCredentialHelperPrefix + ToEnvVariableSuffix(registryHost)
+	if helper == "" {
+		os.Unsetenv(envVariable)
+		if _, err := configlib.GetEnv(envVariable); err != nil {
+			// No credential helper is set for the registry
+			return nil
+		}
+		return configlib.DeleteEnv(envVariable)
+	}
+	os.Setenv(envVariable, helper)
+	return configlib.SetEnv(envVariable, helper)
+}
+
+// GetPluginDiscoveryCompositeImages returns the images aggregated with the image configured
+// for the specified discovery source, in order, or nil if the discovery source is not composite.
+func GetPluginDiscoveryCompositeImages(discoveryName string) []string {
+	value := os.Getenv(constants.ConfigVariablePluginDiscoveryCompositeImagesPrefix + ToEnvVariableSuffix(discoveryName))
+	var images []string
+	for _, image := range strings.Split(value, ",") {
+		if image = strings.TrimSpace(image); image != "" {
+			images = append(images, image)
+		}
+	}
+	return images
+}
+
+// pluginDiscoverySettingPrefixes are the prefixes of the variables configuring a single
+// discovery source, to which the name of the discovery source is appended
+var pluginDiscoverySettingPrefixes = []string{
+	constants.ConfigVariablePluginDiscoveryPriorityPrefix,
+	constants.ConfigVariablePluginDiscoveryUsernamePrefix,
+	constants.ConfigVariablePluginDiscoveryPasswordPrefix,
+	constants.ConfigVariablePluginDiscoveryTokenPrefix,
+	constants.ConfigVariablePluginDiscoveryCredentialHelperPrefix,
+	constants.ConfigVariablePluginDiscoveryCompositeImagesPrefix,
+}
+
+// RenamePluginDiscoverySettings moves the settings of a discovery source persisted in the
+// configuration to the new name of the discovery source: its priority, its registry
+// credentials, its composite images, the name of its database file and its images in
+// the discovery profiles.
+func RenamePluginDiscoverySettings(oldName, newName string) error {
+	envs, err := configlib.GetAllEnvs()
+	if err != nil {
+		return err
+	}
+
+	// Names differing only by the characters not allowed in a variable share their settings
+	oldSuffix, newSuffix := ToEnvVariableSuffix(oldName), ToEnvVariableSuffix(newName)
+	if oldSuffix != newSuffix {
+		for _, prefix := range pluginDiscoverySettingPrefixes {
+			value, found := envs[prefix+oldSuffix]
+			if !found {
+				continue
+			}
+			if err := configlib.SetEnv(prefix+newSuffix, value); err != nil {
+				return err
+			}
+			os.Setenv(prefix+newSuffix, value)
+			if err := configlib.DeleteEnv(prefix + oldSuffix); err != nil {
+				return err
+			}
+			os.Unsetenv(prefix + oldSuffix)
+		}
+	}
+
+	for key, value := range envs {
+		if key != constants.ConfigVariablePluginInventoryDBFileNames && !strings.HasPrefix(key, constants.ConfigVariablePluginDiscoveryProfileSourcesPrefix) {
+			continue
+		}
+		renamed, changed := renameDiscoveryInList(value, oldName, newName)
+		if !changed {
+			continue
+		}
+		if err := configlib.SetEnv(key, renamed); err != nil {
+			return err
 		}
-		return configlib.SetCLIDiscoverySource(defaultDiscovery)
+		os.Setenv(key, renamed)
 	}
 	return nil
 }
+
+// renameDiscoveryInList renames the discovery source in a comma separated list of
+// "<discoveryName>=<value>" pairs.  It returns false if the list does not refer to it.
+func renameDiscoveryInList(list, oldName, newName string) (string, bool) {
+	entries := strings.Split(list, ",")
+	changed := false
+	for i, entry := range entries {
+		name, value, found := strings.Cut(entry, "=")
+		if found && strings.TrimSpace(name) == oldName {
+			entries[i] = newName + "=" + value
+			changed = true
+		}
+	}
+	return strings.Join(entries, ","), changed
+}
+
+// GetSelectedPluginDiscoveryProfile returns the name of the discovery profile
+// selected by the user, or an empty string if none is selected.
+func GetSelectedPluginDiscoveryProfile() string {
+	return strings.TrimSpace(os.Getenv(constants.ConfigVariablePluginDiscoveryProfile))
+}
+
+// GetPluginDiscoveryProfileImages returns the discovery images of the specified
+// profile indexed by the name of the discovery source they apply to.
+func GetPluginDiscoveryProfileImages(profile string) (map[string]string, error) {
+	envVariable := constants.ConfigVariablePluginDiscoveryProfileSourcesPrefix + ToEnvVariableSuffix(profile)
+	value := strings.TrimSpace(os.Getenv(envVariable))
+	if value == "" {
+		return nil, errors.Errorf("the discovery profile '%s' is not defined, please set the '%s' variable", profile, envVariable)
+	}
+
+	images := map[string]string{}
+	for _, entry := range strings.Split(value, ",") {
+		name, image, found := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		image = strings.TrimSpace(image)
+		if !found || name == "" || image == "" {
+			return nil, errors.Errorf("invalid entry '%s' in the '%s' variable, the expected format is '<discoveryName>=<image>'", entry, envVariable)
+		}
+		images[name] = image
+	}
+	return images, nil
+}
+
+// ApplyPluginDiscoveryProfile returns the specified discovery sources with the
+// images overridden by the selected discovery profile.  The discovery sources of
+// the profile that are not part of the specified ones are added at the end.
+// The specified discovery sources are not modified and nothing is persisted.
+// If no profile is selected, the discovery sources are returned as is.
+func ApplyPluginDiscoveryProfile(discoverySources []configtypes.PluginDiscovery) ([]configtypes.PluginDiscovery, error) {
+	profile := GetSelectedPluginDiscoveryProfile()
+	if profile == "" {
+		return discoverySources, nil
+	}
+	images, err := GetPluginDiscoveryProfileImages(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]configtypes.PluginDiscovery, 0, len(discoverySources)+len(images))
+	for _, ds := range discoverySources {
+		if ds.OCI != nil {
+			if image, found := images[ds.OCI.Name]; found {
+				ds.OCI = &configtypes.OCIDiscovery{Name: ds.OCI.Name, Image: image}
+				delete(images, ds.OCI.Name)
+			}
+		}
+		result = append(result, ds)
+	}
+
+	// Add the remaining discovery sources of the profile in a predictable order
+	names := make([]string, 0, len(images))
+	for name := range images {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result = append(result, configtypes.PluginDiscovery{
+			OCI: &configtypes.OCIDiscovery{Name: name, Image: images[name]},
+		})
+	}
+	return result, nil
+}
+
+// GetPluginInventoryCacheName returns the name of the cache directory of the
+// plugin inventory of the specified discovery source.  When the selected discovery
+// profile overrides the discovery source, the name of the profile is included
+// so that switching between profiles does not invalidate the cache of each other.
+func GetPluginInventoryCacheName(discoveryName string) string {
+	profile := GetSelectedPluginDiscoveryProfile()
+	if profile == "" {
+		return discoveryName
+	}
+	images, err := GetPluginDiscoveryProfileImages(profile)
+	if err != nil {
+		return discoveryName
+	}
+	if _, found := images[discoveryName]; !found {
+		return discoveryName
+	}
+	return discoveryName + "@" + ToEnvVariableSuffix(profile)
+}
+
+// invalidRecommendedVersionStrategyWarning makes sure the warning about an invalid
+// strategy is only printed once per command, instead of once per plugin
+var invalidRecommendedVersionStrategyWarning sync.Once
+
+// GetPluginRecommendedVersionStrategy returns the strategy used to select the
+// recommended version of plugins.  An invalid value is ignored with a warning.
+func GetPluginRecommendedVersionStrategy() string {
+	strategy := strings.ToLower(strings.TrimSpace(os.Getenv(constants.ConfigVariablePluginRecommendedVersionStrategy)))
+	switch strategy {
+	case "":
+		return RecommendedVersionStrategyPublisher
+	case RecommendedVersionStrategyPublisher, RecommendedVersionStrategyHighestStable, RecommendedVersionStrategyHighestIncludingPrerelease:
+		return strategy
+	}
+	invalidRecommendedVersionStrategyWarning.Do(func() {
+		log.Warningf("ignoring invalid value %q for %s, the valid values are: %s, %s, %s", strategy, constants.ConfigVariablePluginRecommendedVersionStrategy,
+			RecommendedVersionStrategyPublisher, RecommendedVersionStrategyHighestStable, RecommendedVersionStrategyHighestIncludingPrerelease)
+	})
+	return RecommendedVersionStrategyPublisher
+}
+
+// invalidRecommendedVersionFallbackWarning makes sure the warning about an invalid
+// fallback setting is only printed once per command, instead of once per query
+var invalidRecommendedVersionFallbackWarning sync.Once
+
+// IsPluginRecommendedVersionFallbackEnabled returns true if a plugin whose recommended version
+// is not available in a discovery source recommends its highest available version instead.
+// Unless configured, the fallback is only enabled for the discovery sources of air-gapped
+// repositories, which may not contain every version of the plugins.  An invalid value is
+// ignored with a warning.
+func IsPluginRecommendedVersionFallbackEnabled(airgapped bool) bool {
+	value := strings.TrimSpace(os.Getenv(constants.ConfigVariablePluginRecommendedVersionFallback))
+	if value == "" {
+		return airgapped
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		invalidRecommendedVersionFallbackWarning.Do(func() {
+			log.Warningf("ignoring invalid value %q for %s, the valid values are: true, false", value, constants.ConfigVariablePluginRecommendedVersionFallback)
+		})
+		return airgapped
+	}
+	return enabled
+}
+
+// SelectRecommendedVersion returns the recommended version of a plugin based on the
+// configured strategy.  The publisherRecommended version is used with the default strategy
+// or when there are no versions.  The versions must be sorted in ascending order.
+func SelectRecommendedVersion(publisherRecommended string, sortedVersions []string) string {
+	if len(sortedVersions) == 0 {
+		return publisherRecommended
+	}
+	switch GetPluginRecommendedVersionStrategy() {
+	case RecommendedVersionStrategyHighestStable:
+		return utils.GetLatestVersion(sortedVersions, false)
+	case RecommendedVersionStrategyHighestIncludingPrerelease:
+		return utils.GetLatestVersion(sortedVersions, true)
+	}
+	return publisherRecommended
+}
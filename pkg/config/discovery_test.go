@@ -4,8 +4,10 @@
 package config
 
 import (
+	"bytes"
 	"os"
 	"strings"
+	"sync"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -13,6 +15,7 @@ import (
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 	configlib "github.com/vmware-tanzu/tanzu-plugin-runtime/config"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
 
 var _ = Describe("Populate default central discovery", func() {
@@ -171,3 +174,244 @@ var _ = Describe("Populate default central discovery", func() {
 		})
 	})
 })
+
+var _ = Describe("Plugin discovery profiles", func() {
+	const (
+		prodImage    = "registry.example.com/tanzu-cli/plugins/plugin-inventory:latest"
+		stagingImage = "staging.example.com/tanzu-cli/plugins/plugin-inventory:latest"
+		extraImage   = "staging.example.com/tanzu-cli/extra/plugin-inventory:latest"
+	)
+	var discoverySources []types.PluginDiscovery
+
+	BeforeEach(func() {
+		discoverySources = []types.PluginDiscovery{
+			{OCI: &types.OCIDiscovery{Name: "default", Image: prodImage}},
+			{Local: &types.LocalDiscovery{Name: "local", Path: "/tmp/local"}},
+		}
+	})
+	AfterEach(func() {
+		os.Unsetenv(constants.ConfigVariablePluginDiscoveryProfile)
+		os.Unsetenv(constants.ConfigVariablePluginDiscoveryProfileSourcesPrefix + "STAGING_EU")
+	})
+
+	Context("when no profile is selected", func() {
+		It("should return the discovery sources unchanged", func() {
+			result, err := ApplyPluginDiscoveryProfile(discoverySources)
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(discoverySources))
+			Expect(GetPluginInventoryCacheName("default")).To(Equal("default"))
+		})
+	})
+	Context("when the selected profile is not defined", func() {
+		It("should return an error", func() {
+			os.Setenv(constants.ConfigVariablePluginDiscoveryProfile, "staging-eu")
+			_, err := ApplyPluginDiscoveryProfile(discoverySources)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("the discovery profile 'staging-eu' is not defined"))
+			Expect(err.Error()).To(ContainSubstring(constants.ConfigVariablePluginDiscoveryProfileSourcesPrefix + "STAGING_EU"))
+		})
+	})
+	Context("when the selected profile is invalid", func() {
+		It("should return an error", func() {
+			os.Setenv(constants.ConfigVariablePluginDiscoveryProfile, "staging-eu")
+			os.Setenv(constants.ConfigVariablePluginDiscoveryProfileSourcesPrefix+"STAGING_EU", stagingImage)
+			_, err := ApplyPluginDiscoveryProfile(discoverySources)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("the expected format is '<discoveryName>=<image>'"))
+		})
+	})
+	Context("when the selected profile is defined", func() {
+		BeforeEach(func() {
+			os.Setenv(constants.ConfigVariablePluginDiscoveryProfile, "staging-eu")
+			os.Setenv(constants.ConfigVariablePluginDiscoveryProfileSourcesPrefix+"STAGING_EU", "extra="+extraImage+" , default="+stagingImage)
+		})
+		It("should override the images of the discovery sources and add the new ones", func() {
+			result, err := ApplyPluginDiscoveryProfile(discoverySources)
+			Expect(err).To(BeNil())
+			Expect(len(result)).To(Equal(3))
+			Expect(result[0].OCI.Name).To(Equal("default"))
+			Expect(result[0].OCI.Image).To(Equal(stagingImage))
+			Expect(result[1].Local.Name).To(Equal("local"))
+			Expect(result[2].OCI.Name).To(Equal("extra"))
+			Expect(result[2].OCI.Image).To(Equal(extraImage))
+
+			// The original discovery sources must not be modified
+			Expect(discoverySources[0].OCI.Image).To(Equal(prodImage))
+		})
+		It("should use a separate cache for the discovery sources of the profile", func() {
+			Expect(GetPluginInventoryCacheName("default")).To(Equal("default@STAGING_EU"))
+			Expect(GetPluginInventoryCacheName("extra")).To(Equal("extra@STAGING_EU"))
+			Expect(GetPluginInventoryCacheName("other")).To(Equal("other"))
+		})
+	})
+})
+
+var _ = Describe("Plugin recommended version strategy", func() {
+	versions := []string{"v0.1.0", "v0.2.0", "v0.10.0", "v0.11.0-beta.1"}
+	const publisherRecommended = "v0.2.0"
+
+	AfterEach(func() {
+		os.Unsetenv(constants.ConfigVariablePluginRecommendedVersionStrategy)
+	})
+
+	Context("when no strategy is configured", func() {
+		It("should use the version recommended by the publisher", func() {
+			Expect(GetPluginRecommendedVersionStrategy()).To(Equal(RecommendedVersionStrategyPublisher))
+			Expect(SelectRecommendedVersion(publisherRecommended, versions)).To(Equal(publisherRecommended))
+		})
+	})
+	Context("when the strategy is invalid", func() {
+		It("should use the version recommended by the publisher", func() {
+			os.Setenv(constants.ConfigVariablePluginRecommendedVersionStrategy, "invalid")
+			Expect(GetPluginRecommendedVersionStrategy()).To(Equal(RecommendedVersionStrategyPublisher))
+			Expect(SelectRecommendedVersion(publisherRecommended, versions)).To(Equal(publisherRecommended))
+		})
+		It("should only warn once about the invalid strategy", func() {
+			var stderr bytes.Buffer
+			log.SetStderr(&stderr)
+			defer log.SetStderr(os.Stderr)
+			invalidRecommendedVersionStrategyWarning = sync.Once{}
+
+			os.Setenv(constants.ConfigVariablePluginRecommendedVersionStrategy, "invalid")
+			Expect(GetPluginRecommendedVersionStrategy()).To(Equal(RecommendedVersionStrategyPublisher))
+			Expect(GetPluginRecommendedVersionStrategy()).To(Equal(RecommendedVersionStrategyPublisher))
+			Expect(strings.Count(stderr.String(), "ignoring invalid value")).To(Equal(1))
+		})
+	})
+	Context("when the strategy is publisher-recommended", func() {
+		It("should use the version recommended by the publisher", func() {
+			os.Setenv(constants.ConfigVariablePluginRecommendedVersionStrategy, RecommendedVersionStrategyPublisher)
+			Expect(SelectRecommendedVersion(publisherRecommended, versions)).To(Equal(publisherRecommended))
+		})
+	})
+	Context("when the strategy is highest-stable", func() {
+		It("should use the highest version that is not a pre-release", func() {
+			os.Setenv(constants.ConfigVariablePluginRecommendedVersionStrategy, "Highest-Stable")
+			Expect(SelectRecommendedVersion(publisherRecommended, versions)).To(Equal("v0.10.0"))
+		})
+	})
+	Context("when the strategy is highest-including-prerelease", func() {
+		It("should use the highest version", func() {
+			os.Setenv(constants.ConfigVariablePluginRecommendedVersionStrategy, RecommendedVersionStrategyHighestIncludingPrerelease)
+			Expect(SelectRecommendedVersion(publisherRecommended, versions)).To(Equal("v0.11.0-beta.1"))
+		})
+		It("should use the version recommended by the publisher if there are no versions", func() {
+			os.Setenv(constants.ConfigVariablePluginRecommendedVersionStrategy, RecommendedVersionStrategyHighestIncludingPrerelease)
+			Expect(SelectRecommendedVersion(publisherRecommended, nil)).To(Equal(publisherRecommended))
+		})
+	})
+})
+
+var _ = Describe("Plugin recommended version fallback", func() {
+	AfterEach(func() {
+		os.Unsetenv(constants.ConfigVariablePluginRecommendedVersionFallback)
+	})
+
+	Context("when the fallback is not configured", func() {
+		It("should only be enabled for air-gapped repositories", func() {
+			Expect(IsPluginRecommendedVersionFallbackEnabled(true)).To(BeTrue())
+			Expect(IsPluginRecommendedVersionFallbackEnabled(false)).To(BeFalse())
+		})
+	})
+	Context("when the fallback is configured", func() {
+		It("should use the configured value", func() {
+			os.Setenv(constants.ConfigVariablePluginRecommendedVersionFallback, "false")
+			Expect(IsPluginRecommendedVersionFallbackEnabled(true)).To(BeFalse())
+			os.Setenv(constants.ConfigVariablePluginRecommendedVersionFallback, "true")
+			Expect(IsPluginRecommendedVersionFallbackEnabled(false)).To(BeTrue())
+		})
+		It("should ignore an invalid value", func() {
+			os.Setenv(constants.ConfigVariablePluginRecommendedVersionFallback, "sometimes")
+			Expect(IsPluginRecommendedVersionFallbackEnabled(true)).To(BeTrue())
+			Expect(IsPluginRecommendedVersionFallbackEnabled(false)).To(BeFalse())
+		})
+		It("should only warn once about the invalid value", func() {
+			var stderr bytes.Buffer
+			log.SetStderr(&stderr)
+			defer log.SetStderr(os.Stderr)
+			invalidRecommendedVersionFallbackWarning = sync.Once{}
+
+			os.Setenv(constants.ConfigVariablePluginRecommendedVersionFallback, "sometimes")
+			Expect(IsPluginRecommendedVersionFallbackEnabled(true)).To(BeTrue())
+			Expect(IsPluginRecommendedVersionFallbackEnabled(true)).To(BeTrue())
+			Expect(strings.Count(stderr.String(), "ignoring invalid value")).To(Equal(1))
+		})
+	})
+})
+
+var _ = Describe("Plugin discovery priority", func() {
+	const priorityVariable = constants.ConfigVariablePluginDiscoveryPriorityPrefix + "MY_MIRROR"
+
+	AfterEach(func() {
+		os.Unsetenv(priorityVariable)
+	})
+
+	Context("when no priority is configured", func() {
+		It("should use the default priority", func() {
+			Expect(GetPluginDiscoveryPriority("my-mirror")).To(Equal(0))
+		})
+	})
+	Context("when a priority is configured", func() {
+		It("should use the priority", func() {
+			os.Setenv(priorityVariable, " -5 ")
+			Expect(GetPluginDiscoveryPriority("my-mirror")).To(Equal(-5))
+		})
+	})
+	Context("when the priority is invalid", func() {
+		It("should use the default priority", func() {
+			os.Setenv(priorityVariable, "high")
+			Expect(GetPluginDiscoveryPriority("my-mirror")).To(Equal(0))
+		})
+	})
+})
+
+var _ = Describe("Renaming the settings of a plugin discovery", func() {
+	const profileVariable = constants.ConfigVariablePluginDiscoveryProfileSourcesPrefix + "AIRGAPPED"
+	var (
+		configFile   *os.File
+		configFileNG *os.File
+		err          error
+	)
+	BeforeEach(func() {
+		configFile, err = os.CreateTemp("", "config")
+		Expect(err).To(BeNil())
+		os.Setenv("TANZU_CONFIG", configFile.Name())
+
+		configFileNG, err = os.CreateTemp("", "config_ng")
+		Expect(err).To(BeNil())
+		os.Setenv("TANZU_CONFIG_NEXT_GEN", configFileNG.Name())
+	})
+	AfterEach(func() {
+		os.Unsetenv("TANZU_CONFIG")
+		os.Unsetenv("TANZU_CONFIG_NEXT_GEN")
+		os.Unsetenv(constants.ConfigVariablePluginDiscoveryPriorityPrefix + "MIRROR")
+		os.Unsetenv(constants.ConfigVariablePluginDiscoveryPriorityPrefix + "CORP_MIRROR")
+		os.Unsetenv(constants.ConfigVariablePluginInventoryDBFileNames)
+		os.Unsetenv(profileVariable)
+		os.RemoveAll(configFile.Name())
+		os.RemoveAll(configFileNG.Name())
+	})
+
+	It("should move the settings to the new name", func() {
+		Expect(SetPluginDiscoveryPriority("mirror", 10)).To(Succeed())
+		Expect(configlib.SetEnv(constants.ConfigVariablePluginInventoryDBFileNames, "default=a.db,mirror=b.db")).To(Succeed())
+		Expect(configlib.SetEnv(profileVariable, "mirror=registry.example.com/inventory:latest")).To(Succeed())
+
+		Expect(RenamePluginDiscoverySettings("mirror", "corp-mirror")).To(Succeed())
+
+		Expect(GetPluginDiscoveryPriority("corp-mirror")).To(Equal(10))
+		Expect(GetPluginDiscoveryPriority("mirror")).To(Equal(0))
+		_, err = configlib.GetEnv(constants.ConfigVariablePluginDiscoveryPriorityPrefix + "MIRROR")
+		Expect(err).ToNot(BeNil())
+		Expect(GetPluginInventoryDBFileName("corp-mirror")).To(Equal("b.db"))
+		Expect(GetPluginInventoryDBFileName("default")).To(Equal("a.db"))
+		images, err := GetPluginDiscoveryProfileImages("airgapped")
+		Expect(err).To(BeNil())
+		Expect(images).To(Equal(map[string]string{"corp-mirror": "registry.example.com/inventory:latest"}))
+	})
+	It("should keep the settings shared by both names", func() {
+		Expect(SetPluginDiscoveryPriority("corp-mirror", 10)).To(Succeed())
+		Expect(RenamePluginDiscoverySettings("corp-mirror", "corp_mirror")).To(Succeed())
+		Expect(GetPluginDiscoveryPriority("corp_mirror")).To(Equal(10))
+	})
+})
@@ -14,6 +14,112 @@ const (
 	ConfigVariableAdditionalPrivateDiscoveryImages    = "TANZU_CLI_PRIVATE_PLUGIN_DISCOVERY_IMAGES"
 	ConfigVariableIncludeDeactivatedPluginsForTesting = "TANZU_CLI_INCLUDE_DEACTIVATED_PLUGINS_TEST_ONLY"
 	ConfigVariableStandaloneOverContextPlugins        = "TANZU_CLI_STANDALONE_OVER_CONTEXT_PLUGINS"
+	// ConfigVariablePluginInventoryDBFileNames is a comma separated list of "<discoveryName>=<fileName>"
+	// pairs specifying the database file name to look for in the image of a discovery source
+	ConfigVariablePluginInventoryDBFileNames = "TANZU_CLI_PLUGIN_INVENTORY_DB_FILE_NAMES"
+	// ConfigVariablePinnedPluginVersions is a comma separated list of "<pluginName>[:<target>]=<version>"
+	// pairs specifying the exact version of a context plugin to install when syncing plugins
+	ConfigVariablePinnedPluginVersions = "TANZU_CLI_PINNED_PLUGIN_VERSIONS"
+	// The following prefixes are used to specify the registry credentials of a discovery source.
+	// The name of the discovery source, in upper case and with any character other than
+	// letters and digits replaced by '_', is appended to the prefix.
+	// E.g., TANZU_CLI_PLUGIN_DISCOVERY_USERNAME_DEFAULT
+	ConfigVariablePluginDiscoveryUsernamePrefix         = "TANZU_CLI_PLUGIN_DISCOVERY_USERNAME_"
+	ConfigVariablePluginDiscoveryPasswordPrefix         = "TANZU_CLI_PLUGIN_DISCOVERY_PASSWORD_"
+	ConfigVariablePluginDiscoveryTokenPrefix            = "TANZU_CLI_PLUGIN_DISCOVERY_TOKEN_"
+	ConfigVariablePluginDiscoveryCredentialHelperPrefix = "TANZU_CLI_PLUGIN_DISCOVERY_CREDENTIAL_HELPER_"
+	// ConfigVariablePluginDiscoveryPriorityPrefix is used to specify the priority of a discovery source.
+	// The name of the discovery source, converted like above, is appended to the prefix.
+	// When a plugin is provided by multiple discovery sources, the sources with a higher priority win.
+	// E.g., TANZU_CLI_PLUGIN_DISCOVERY_PRIORITY_DEFAULT
+	ConfigVariablePluginDiscoveryPriorityPrefix = "TANZU_CLI_PLUGIN_DISCOVERY_PRIORITY_"
+	// ConfigVariablePluginDiscoveryGenerationMarkerPrefix is used to specify the URL of a small marker,
+	// such as a generation counter, that the registry updates every time the discovery image changes.
+	// The name of the discovery source, converted like above, is appended to the prefix.
+	// The digest of the discovery image is not resolved while the content of the marker is unchanged.
+	// E.g., TANZU_CLI_PLUGIN_DISCOVERY_GENERATION_MARKER_DEFAULT
+	ConfigVariablePluginDiscoveryGenerationMarkerPrefix = "TANZU_CLI_PLUGIN_DISCOVERY_GENERATION_MARKER_"
+	// ConfigVariablePluginDiscoveryCompositeImagesPrefix is used to aggregate multiple inventory images
+	// into a single OCI discovery source.  The name of the discovery source, converted like above, is
+	// appended to the prefix and the variable holds a comma separated list of images whose plugins and
+	// plugin groups are merged, in order, with those of the image configured for the discovery source.
+	// E.g., TANZU_CLI_PLUGIN_DISCOVERY_COMPOSITE_IMAGES_DEFAULT
+	ConfigVariablePluginDiscoveryCompositeImagesPrefix = "TANZU_CLI_PLUGIN_DISCOVERY_COMPOSITE_IMAGES_"
+	// ConfigVariablePluginDiscoveryBackgroundRefresh, when set to "true", makes the plugin commands use
+	// the cached plugin inventories right away, while refreshing them in the background for the
+	// next commands.  The results can therefore be out-of-date by one refresh.
+	ConfigVariablePluginDiscoveryBackgroundRefresh = "TANZU_CLI_PLUGIN_DISCOVERY_BACKGROUND_REFRESH"
+	// ConfigVariablePluginDiscoveryProfile is the name of the discovery profile to use, if any
+	ConfigVariablePluginDiscoveryProfile = "TANZU_CLI_PLUGIN_DISCOVERY_PROFILE"
+	// ConfigVariablePluginDiscoveryProfileSourcesPrefix is used to define a discovery profile.
+	// The name of the profile, converted like the name of a discovery source above, is appended
+	// to the prefix and the variable holds a comma separated list of "<discoveryName>=<image>" pairs.
+	// E.g., TANZU_CLI_PLUGIN_DISCOVERY_PROFILE_SOURCES_STAGING
+	ConfigVariablePluginDiscoveryProfileSourcesPrefix = "TANZU_CLI_PLUGIN_DISCOVERY_PROFILE_SOURCES_"
+	// ConfigVariablePluginDiscoveryMaxCacheAge is the maximum age (e.g., 720h) of the cached plugin
+	// inventories when only the cache is used.  An older cache must first be refreshed.
+	ConfigVariablePluginDiscoveryMaxCacheAge = "TANZU_CLI_PLUGIN_DISCOVERY_MAX_CACHE_AGE"
+	// ConfigVariablePluginDiscoverySignatureFailureCoolDown is how long (e.g., 5m) a failed signature
+	// verification of a plugin inventory image is remembered.  During that time, the image is not verified
+	// again and the recorded failure is reported instead.  A value of 0 disables the cool-down.
+	ConfigVariablePluginDiscoverySignatureFailureCoolDown = "TANZU_CLI_PLUGIN_DISCOVERY_SIGNATURE_FAILURE_COOLDOWN"
+	// ConfigVariablePluginDiscoveryForceRefresh, when set to "true", verifies the signature of the plugin
+	// inventory images again even if it recently failed.
+	ConfigVariablePluginDiscoveryForceRefresh = "TANZU_CLI_PLUGIN_DISCOVERY_FORCE_REFRESH"
+	// ConfigVariablePluginDiscoveryMaxCacheSize is the maximum size (e.g., 500Mi) of the cached plugin
+	// inventories.  The least recently used inventories are evicted when the cache grows larger.
+	ConfigVariablePluginDiscoveryMaxCacheSize = "TANZU_CLI_PLUGIN_DISCOVERY_MAX_CACHE_SIZE"
+	// ConfigVariablePluginDiscoveryMaxInventoryDBSize is the maximum size (e.g., 1Gi) of a plugin inventory
+	// database shipped compressed by its image once decompressed, 512Mi by default.  A larger database is
+	// rejected to protect against decompression bombs.
+	ConfigVariablePluginDiscoveryMaxInventoryDBSize = "TANZU_CLI_PLUGIN_DISCOVERY_MAX_INVENTORY_DB_SIZE"
+	// ConfigVariablePluginDiscoveryRetainedInventories is the number of inventory databases of each
+	// discovery, including the current one, kept in the cache keyed by the digest of their image, so that
+	// switching back to a recently used image does not download it again.  The default of 1 only keeps the
+	// current inventory.
+	ConfigVariablePluginDiscoveryRetainedInventories = "TANZU_CLI_PLUGIN_DISCOVERY_RETAINED_INVENTORIES"
+	// ConfigVariableConfirmDiscoveryDigestChange requires, when set to "true", a confirmation before
+	// downloading a discovery image whose digest has changed since it was last fetched.
+	ConfigVariableConfirmDiscoveryDigestChange = "TANZU_CLI_CONFIRM_DISCOVERY_IMAGE_DIGEST_CHANGE"
+	// DiscoveryDigestChangePromptAnswer answers ("Yes" or "No") the confirmation of a discovery
+	// image digest change without prompting, for non-interactive use.
+	DiscoveryDigestChangePromptAnswer = "TANZU_CLI_DISCOVERY_IMAGE_DIGEST_CHANGE_PROMPT_ANSWER"
+	// ConfigVariablePluginArtifactMaxCacheSize is the maximum size (e.g., 500Mi) of the cached plugin
+	// binaries, 1Gi by default.  The least recently used binaries are evicted when the cache grows
+	// larger.  A size of 0 disables the caching of the plugin binaries.
+	ConfigVariablePluginArtifactMaxCacheSize = "TANZU_CLI_PLUGIN_ARTIFACT_MAX_CACHE_SIZE"
+	// ConfigVariablePluginSearchMode is the default mode of the plugin search command: "online"
+	// refreshes the cached plugin inventories from the registry, "offline" only uses the cache.
+	ConfigVariablePluginSearchMode = "TANZU_CLI_PLUGIN_SEARCH_MODE"
+	// ConfigVariablePluginRecommendedVersionStrategy selects how the recommended version of a plugin,
+	// which is also the version installed as "latest", is chosen.  The possible values are
+	// "publisher-recommended" (the default), "highest-stable" and "highest-including-prerelease".
+	ConfigVariablePluginRecommendedVersionStrategy = "TANZU_CLI_PLUGIN_RECOMMENDED_VERSION_STRATEGY"
+	// ConfigVariablePluginRecommendedVersionFallback, when true, makes a plugin whose recommended
+	// version is not available in its discovery source, e.g., because it was not mirrored, recommend
+	// its highest available version instead.  It defaults to true for the discovery sources of
+	// air-gapped repositories and to false otherwise.
+	ConfigVariablePluginRecommendedVersionFallback = "TANZU_CLI_PLUGIN_RECOMMENDED_VERSION_FALLBACK"
+	// ConfigVariableRegistryCACert is the path to a file of PEM-encoded CA certificates trusted,
+	// in addition to the system trust store, when accessing any registry.  This includes fetching
+	// the plugin discovery images, the plugin binaries and the signatures of the discovery images.
+	ConfigVariableRegistryCACert = "TANZU_CLI_REGISTRY_CA_CERT"
+	// ConfigVariableRegistrySkipCertVerify disables the verification of the certificates of all
+	// registries when set to "true".  This is insecure and should only be used for testing.
+	ConfigVariableRegistrySkipCertVerify = "TANZU_CLI_REGISTRY_SKIP_CERT_VERIFY"
+	// ConfigVariableRegistryClientCert is the path to a PEM-encoded client certificate presented,
+	// together with the key of ConfigVariableRegistryClientKey, to the registries requiring mutual TLS.
+	ConfigVariableRegistryClientCert = "TANZU_CLI_REGISTRY_CLIENT_CERT"
+	// ConfigVariableRegistryClientKey is the path to the PEM-encoded private key of the client
+	// certificate of ConfigVariableRegistryClientCert.
+	ConfigVariableRegistryClientKey = "TANZU_CLI_REGISTRY_CLIENT_KEY"
+	// ConfigVariableRegistryCredentialHelperPrefix, followed by the host of a registry, is the credential
+	// helper storing the credentials saved for that registry by 'tanzu plugin source login'.  It is used
+	// to access that registry for the discovery sources without credentials of their own.
+	ConfigVariableRegistryCredentialHelperPrefix = "TANZU_CLI_REGISTRY_CREDENTIAL_HELPER_"
+	// ConfigVariableRegistryUserAgent overrides the User-Agent header of the requests sent to the
+	// registries, which is "tanzu-cli/<version>" followed by the user agent of the registry library by default.
+	ConfigVariableRegistryUserAgent = "TANZU_CLI_REGISTRY_USER_AGENT"
 	// PluginDiscoveryImageSignatureVerificationSkipList is a comma separated list of discovery image urls
 	PluginDiscoveryImageSignatureVerificationSkipList = "TANZU_CLI_PLUGIN_DISCOVERY_IMAGE_SIGNATURE_VERIFICATION_SKIP_LIST"
 	PublicKeyPathForPluginDiscoveryImageSignature     = "TANZU_CLI_PLUGIN_DISCOVERY_IMAGE_SIGNATURE_PUBLIC_KEY_PATH"
@@ -30,6 +136,10 @@ const (
 	// TanzuCLIEssentialsPluginGroupVersion is used to override and customize what version of essentials plugin group should be installed
 	TanzuCLIEssentialsPluginGroupVersion = "TANZU_CLI_ESSENTIALS_PLUGIN_GROUP_VERSION"
 
+	// SkipEssentialPluginsInstallation disables the automatic installation and upgrade of the
+	// essential plugins when set to "true"
+	SkipEssentialPluginsInstallation = "TANZU_CLI_SKIP_ESSENTIAL_PLUGINS_INSTALLATION"
+
 	// TanzuCLIShowPluginInstallationLogs is used to enable or disable the logs for essential plugin group installation process
 	// Possible values "True" or "False"
 	// by default logs are enabled
@@ -9,20 +9,28 @@ import (
 	"crypto"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/pkg/errors"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	"github.com/sigstore/cosign/v2/pkg/cosign/pkcs11key"
+	"github.com/sigstore/cosign/v2/pkg/oci"
 	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
 	sigs "github.com/sigstore/cosign/v2/pkg/signature"
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/payload"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
 )
 
 // RegistryOptions registry options used while interacting with registry
@@ -34,6 +42,13 @@ type RegistryOptions struct {
 	SkipCertVerify bool
 	// AllowInsecure is to allow using HTTP instead of HTTPS protocol while connecting to registries
 	AllowInsecure bool
+	// ClientCertPath and ClientKeyPath are the client certificate and its key
+	// presented to the registries requiring mutual TLS
+	ClientCertPath string
+	ClientKeyPath  string
+	// Keychain provides the credentials to access the registries.
+	// The registries are accessed anonymously if it is nil.
+	Keychain authn.Keychain
 }
 
 // CosignVerifyOptions implements the "cosign verify" command using cosign library
@@ -43,8 +58,34 @@ type CosignVerifyOptions struct {
 	PublicKeyPath string
 	// RegistryOpts registry options used while interacting with registry
 	RegistryOpts *RegistryOptions
+	// SharedState is the trust material shared with the verifiers of the other
+	// images of a batch.  It is loaded for each verification if nil.
+	SharedState *SharedVerifierState
+}
+
+// SignatureVerificationResult is the outcome of a successful signature verification of an image
+type SignatureVerificationResult struct {
+	// Image is the image whose signature was verified
+	Image string
+	// Digest is the digest of the image manifest covered by the signature
+	Digest string
+	// Signer is the identity of the signer, only available for keyless signatures
+	Signer string
+	// Issuer is the OIDC issuer that authenticated the signer, only available for keyless signatures
+	Issuer string
+	// TransparencyLogBundle is true if the signature comes with a bundle proving its inclusion in
+	// the Rekor transparency log.  The bundle is not verified as the transparency log is ignored.
+	TransparencyLogBundle bool
 }
 
+// The extensions of the certificates issued by Fulcio holding the OIDC issuer of the signer.
+// The original extension holds the issuer as a raw string while the newer one holds
+// it as a DER encoded string.
+var (
+	fulcioIssuerV1OID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	fulcioIssuerV2OID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
 func NewCosignVerifier(publicKeyPath string, registryOpts *RegistryOptions) Cosignhelper {
 	return &CosignVerifyOptions{
 		PublicKeyPath: publicKeyPath,
@@ -52,46 +93,40 @@ func NewCosignVerifier(publicKeyPath string, registryOpts *RegistryOptions) Cosi
 	}
 }
 
+// NewCosignVerifierWithSharedState returns a verifier which takes its public keys and
+// HTTP transports from the specified state, shared with the verifiers of a batch of images
+func NewCosignVerifierWithSharedState(publicKeyPath string, registryOpts *RegistryOptions, state *SharedVerifierState) Cosignhelper {
+	return &CosignVerifyOptions{
+		PublicKeyPath: publicKeyPath,
+		RegistryOpts:  registryOpts,
+		SharedState:   state,
+	}
+}
+
 // Verify verifies the signature on the images
 func (vo *CosignVerifyOptions) Verify(ctx context.Context, images []string) error {
-	var pubKeys []signature.Verifier
-	var err error
-	httpTrans, err := vo.newHTTPTransport()
+	_, err := vo.VerifyWithResults(ctx, images)
+	return err
+}
+
+// VerifyWithResults verifies the signature on the images and returns
+// the details of the verified signature of each image
+func (vo *CosignVerifyOptions) VerifyWithResults(ctx context.Context, images []string) ([]SignatureVerificationResult, error) {
+	var results []SignatureVerificationResult
+	httpTrans, err := vo.getHTTPTransport()
 	if err != nil {
-		return errors.Wrapf(err, "creating registry HTTP transport")
+		return nil, errors.Wrapf(err, "creating registry HTTP transport")
 	}
 	// TODO: Investigate If CLI need transparency log verification, and add support for RekorURL
 	// The Rekor Transparency log verification was experimental in v1.13.1 and regular feature in v2.x.x
 	// Using Rekor Default URL and Rekor public Keys (downloaded from online by default) not be feasible for air-gapped environment
 	ignoreTlog := true
 
-	switch {
-	// If PublicKeyPath is provided(custom public key) use it, else use the embedded public key
-	case vo.PublicKeyPath != "":
-		pubKey, err := sigs.PublicKeyFromKeyRefWithHashAlgo(ctx, vo.PublicKeyPath, crypto.SHA256)
-		if err != nil {
-			return fmt.Errorf("loading custom public key: %w", err)
-		}
-		pubKeys = append(pubKeys, pubKey)
-		pkcs11Key, ok := pubKey.(*pkcs11key.Key)
-		if ok {
-			defer pkcs11Key.Close()
-		}
-
-	default:
-		for _, raw := range [][]byte{tanzuCLIPluginDBImageSignPublicKeyOfficial} {
-			// PEM encoded file.
-			key, err := cryptoutils.UnmarshalPEMToPublicKey(raw)
-			if err != nil {
-				return fmt.Errorf("failed unmarshalling PEM encoded default public key: %w", err)
-			}
-			pubKey, err := signature.LoadVerifier(key, crypto.SHA256)
-			if err != nil {
-				return fmt.Errorf("loading default public key: %w", err)
-			}
-			pubKeys = append(pubKeys, pubKey)
-		}
+	pubKeys, closeKeys, err := vo.getPublicKeys(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer closeKeys()
 
 	var nameOpts []name.Option
 	if vo.RegistryOpts.AllowInsecure {
@@ -99,37 +134,180 @@ func (vo *CosignVerifyOptions) Verify(ctx context.Context, images []string) erro
 	}
 
 	for _, img := range images {
-		ref, err := name.ParseReference(img, nameOpts...)
+		verifySignatures, cleanup, err := getSignaturesVerifier(ctx, img, nameOpts)
 		if err != nil {
-			return fmt.Errorf("parsing reference: %w", err)
+			return nil, err
 		}
 
 		var arrErr []error
 		for _, verifier := range pubKeys {
 			co := &cosign.CheckOpts{
-				RegistryClientOpts: []ociremote.Option{
-					ociremote.WithRemoteOptions(remote.WithContext(ctx)),
-					ociremote.WithRemoteOptions(remote.WithTransport(httpTrans)),
-				},
-				IgnoreTlog:  ignoreTlog,
-				SigVerifier: verifier,
+				RegistryClientOpts: vo.getRegistryClientOpts(ctx, httpTrans),
+				IgnoreTlog:         ignoreTlog,
+				SigVerifier:        verifier,
 			}
 
-			_, _, err = cosign.VerifyImageSignatures(ctx, ref, co)
+			var verifiedSigs []oci.Signature
+			verifiedSigs, err = verifySignatures(co)
 			if err == nil {
+				results = append(results, getSignatureVerificationResult(img, verifiedSigs))
 				break // if signature verification successful break the loop
 			} else {
 				arrErr = append(arrErr, fmt.Errorf("failed validating the signature of the image %s :%w", img, err))
 			}
 		}
+		cleanup()
 		// If all the verifier has returned error then mark the verification as failed
 		// and return the error
 		if len(arrErr) == len(pubKeys) {
-			return kerrors.NewAggregate(arrErr)
+			return nil, kerrors.NewAggregate(arrErr)
 		}
 	}
 
-	return nil
+	return results, nil
+}
+
+// getRegistryClientOpts returns the options of the client accessing the registries,
+// using the credentials of the keychain of the registry options if any
+func (vo *CosignVerifyOptions) getRegistryClientOpts(ctx context.Context, httpTrans http.RoundTripper) []ociremote.Option {
+	opts := []ociremote.Option{
+		ociremote.WithRemoteOptions(remote.WithContext(ctx)),
+		ociremote.WithRemoteOptions(remote.WithTransport(httpTrans)),
+	}
+	if vo.RegistryOpts.Keychain != nil {
+		opts = append(opts, ociremote.WithRemoteOptions(remote.WithAuthFromKeychain(vo.RegistryOpts.Keychain)))
+	}
+	return opts
+}
+
+// getSignaturesVerifier returns a function verifying the signatures of the image with
+// the specified options.  The signatures of an image of a registry are fetched from the
+// registry, while the signatures of an OCI image layout directory or archive on disk must
+// be embedded in the layout, e.g., by `cosign save`.  The returned cleanup function must
+// be called once the verification is done.
+func getSignaturesVerifier(ctx context.Context, img string, nameOpts []name.Option) (func(co *cosign.CheckOpts) ([]oci.Signature, error), func(), error) {
+	if registry.IsLocalImage(img) {
+		layoutPath, cleanup, err := registry.OpenLocalImageLayout(img)
+		if err != nil {
+			return nil, nil, err
+		}
+		return func(co *cosign.CheckOpts) ([]oci.Signature, error) {
+			verifiedSigs, _, err := cosign.VerifyLocalImageSignatures(ctx, layoutPath, co)
+			return verifiedSigs, err
+		}, cleanup, nil
+	}
+
+	ref, err := name.ParseReference(img, nameOpts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing reference: %w", err)
+	}
+	return func(co *cosign.CheckOpts) ([]oci.Signature, error) {
+		verifiedSigs, _, err := cosign.VerifyImageSignatures(ctx, ref, co)
+		return verifiedSigs, err
+	}, func() {}, nil
+}
+
+// getSignatureVerificationResult extracts the digest and the signer identity from the verified signatures
+func getSignatureVerificationResult(image string, sigs []oci.Signature) SignatureVerificationResult {
+	result := SignatureVerificationResult{Image: image}
+	for _, sig := range sigs {
+		if result.Digest == "" {
+			if p, err := sig.Payload(); err == nil {
+				var simpleSigning payload.SimpleContainerImage
+				if err := json.Unmarshal(p, &simpleSigning); err == nil {
+					result.Digest = simpleSigning.Critical.Image.DockerManifestDigest
+				}
+			}
+		}
+		if result.Signer == "" {
+			// Only keyless signatures come with a certificate identifying the signer
+			if cert, err := sig.Cert(); err == nil && cert != nil {
+				if sans := cryptoutils.GetSubjectAlternateNames(cert); len(sans) > 0 {
+					result.Signer = sans[0]
+				}
+				result.Issuer = getCertificateOIDCIssuer(cert)
+			}
+		}
+		if !result.TransparencyLogBundle {
+			if bundle, err := sig.Bundle(); err == nil && bundle != nil {
+				result.TransparencyLogBundle = true
+			}
+		}
+	}
+	return result
+}
+
+// getCertificateOIDCIssuer returns the OIDC issuer found in the extensions of a
+// certificate issued by Fulcio, or an empty string if there is none
+func getCertificateOIDCIssuer(cert *x509.Certificate) string {
+	var issuer string
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(fulcioIssuerV2OID):
+			var v2Issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &v2Issuer); err == nil {
+				return v2Issuer
+			}
+		case ext.Id.Equal(fulcioIssuerV1OID):
+			issuer = string(ext.Value)
+		}
+	}
+	return issuer
+}
+
+// getPublicKeys returns the public keys to verify the signatures with, along with a
+// function releasing them.  The keys are taken from the shared state of the verifier,
+// if any, except for the keys of a hardware token which must be released after use.
+func (vo *CosignVerifyOptions) getPublicKeys(ctx context.Context) ([]signature.Verifier, func(), error) {
+	if vo.SharedState == nil || strings.HasPrefix(vo.PublicKeyPath, pkcs11key.ReferenceScheme) {
+		return vo.loadPublicKeys(ctx)
+	}
+	pubKeys, err := vo.SharedState.getPublicKeys(vo.PublicKeyPath, func() ([]signature.Verifier, error) {
+		pubKeys, _, err := vo.loadPublicKeys(ctx)
+		return pubKeys, err
+	})
+	return pubKeys, func() {}, err
+}
+
+// loadPublicKeys loads the custom public key if PublicKeyPath is provided, else the embedded public key
+func (vo *CosignVerifyOptions) loadPublicKeys(ctx context.Context) ([]signature.Verifier, func(), error) {
+	var pubKeys []signature.Verifier
+	closeKeys := func() {}
+	switch {
+	case vo.PublicKeyPath != "":
+		pubKey, err := sigs.PublicKeyFromKeyRefWithHashAlgo(ctx, vo.PublicKeyPath, crypto.SHA256)
+		if err != nil {
+			return nil, nil, fmt.Errorf("loading custom public key: %w", err)
+		}
+		pubKeys = append(pubKeys, pubKey)
+		if pkcs11Key, ok := pubKey.(*pkcs11key.Key); ok {
+			closeKeys = pkcs11Key.Close
+		}
+
+	default:
+		for _, raw := range [][]byte{tanzuCLIPluginDBImageSignPublicKeyOfficial} {
+			// PEM encoded file.
+			key, err := cryptoutils.UnmarshalPEMToPublicKey(raw)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed unmarshalling PEM encoded default public key: %w", err)
+			}
+			pubKey, err := signature.LoadVerifier(key, crypto.SHA256)
+			if err != nil {
+				return nil, nil, fmt.Errorf("loading default public key: %w", err)
+			}
+			pubKeys = append(pubKeys, pubKey)
+		}
+	}
+	return pubKeys, closeKeys, nil
+}
+
+// getHTTPTransport returns the HTTP transport to the registries, taken from
+// the shared state of the verifier if any
+func (vo *CosignVerifyOptions) getHTTPTransport() (*http.Transport, error) {
+	if vo.SharedState == nil {
+		return vo.newHTTPTransport()
+	}
+	return vo.SharedState.getHTTPTransport(vo.RegistryOpts, vo.newHTTPTransport)
 }
 
 func (vo *CosignVerifyOptions) newHTTPTransport() (*http.Transport, error) {
@@ -151,11 +329,17 @@ func (vo *CosignVerifyOptions) newHTTPTransport() (*http.Transport, error) {
 		}
 	}
 
+	clientCerts, err := registry.LoadClientCertificates(vo.RegistryOpts.ClientCertPath, vo.RegistryOpts.ClientKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
 	clonedDefaultTransport := http.DefaultTransport.(*http.Transport).Clone()
 	clonedDefaultTransport.ForceAttemptHTTP2 = false
 	// #nosec G402
 	clonedDefaultTransport.TLSClientConfig = &tls.Config{
 		RootCAs:            pool,
+		Certificates:       clientCerts,
 		InsecureSkipVerify: vo.RegistryOpts.SkipCertVerify,
 	}
 
@@ -0,0 +1,101 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cosignhelper
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// SharedVerifierState is the trust material of the signature verification, i.e., the
+// public keys and the HTTP transports to the registries, shared by the verifiers of a
+// batch of images so that it is loaded once instead of for each image.
+// It is safe for concurrent use by the verifiers.
+type SharedVerifierState struct {
+	mutex sync.Mutex
+	// pubKeys are the loaded public keys, by path of the custom public key
+	pubKeys map[string]*sharedPublicKeys
+	// transports are the created HTTP transports, by registry options
+	transports map[string]*sharedTransport
+}
+
+// sharedPublicKeys are public keys loaded once for all the verifiers sharing them
+type sharedPublicKeys struct {
+	once    sync.Once
+	pubKeys []signature.Verifier
+	err     error
+}
+
+// sharedTransport is an HTTP transport created once for all the verifiers sharing it
+type sharedTransport struct {
+	once      sync.Once
+	transport *http.Transport
+	err       error
+}
+
+// NewSharedVerifierState returns an empty state to share between the verifiers of a batch of images
+func NewSharedVerifierState() *SharedVerifierState {
+	return &SharedVerifierState{
+		pubKeys:    make(map[string]*sharedPublicKeys),
+		transports: make(map[string]*sharedTransport),
+	}
+}
+
+// getPublicKeys returns the public keys of the specified path, loading them on first use.
+// Concurrent callers wait for the keys being loaded instead of loading them again.
+// A loading error is not kept so that the next caller loads the keys again.
+func (s *SharedVerifierState) getPublicKeys(publicKeyPath string, load func() ([]signature.Verifier, error)) ([]signature.Verifier, error) {
+	s.mutex.Lock()
+	entry, exists := s.pubKeys[publicKeyPath]
+	if !exists {
+		entry = &sharedPublicKeys{}
+		s.pubKeys[publicKeyPath] = entry
+	}
+	s.mutex.Unlock()
+
+	entry.once.Do(func() {
+		entry.pubKeys, entry.err = load()
+	})
+	if entry.err != nil {
+		s.mutex.Lock()
+		if s.pubKeys[publicKeyPath] == entry {
+			delete(s.pubKeys, publicKeyPath)
+		}
+		s.mutex.Unlock()
+	}
+	return entry.pubKeys, entry.err
+}
+
+// getHTTPTransport returns the HTTP transport for the specified registry options, creating
+// it on first use.  Concurrent callers wait for the transport being created instead of
+// creating it again.  A creation error is not kept so that the next caller creates it again.
+func (s *SharedVerifierState) getHTTPTransport(registryOpts *RegistryOptions, create func() (*http.Transport, error)) (*http.Transport, error) {
+	// The credentials don't affect the transport
+	transportOpts := *registryOpts
+	transportOpts.Keychain = nil
+	key := fmt.Sprintf("%+v", transportOpts)
+
+	s.mutex.Lock()
+	entry, exists := s.transports[key]
+	if !exists {
+		entry = &sharedTransport{}
+		s.transports[key] = entry
+	}
+	s.mutex.Unlock()
+
+	entry.once.Do(func() {
+		entry.transport, entry.err = create()
+	})
+	if entry.err != nil {
+		s.mutex.Lock()
+		if s.transports[key] == entry {
+			delete(s.transports, key)
+		}
+		s.mutex.Unlock()
+	}
+	return entry.transport, entry.err
+}
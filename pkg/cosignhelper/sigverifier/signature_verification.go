@@ -10,7 +10,9 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/pkg/errors"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
@@ -19,8 +21,24 @@ import (
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
 
+// maxConcurrentSignatureVerifications bounds the number of images of a batch
+// whose signature is verified at the same time
+const maxConcurrentSignatureVerifications = 4
+
+// sharedVerifierState is the trust material shared by the signature verifications of the
+// command being run, e.g., of the inventory images of the discovery sources listed
+// concurrently when installing or syncing plugins
+var sharedVerifierState = cosignhelper.NewSharedVerifierState()
+
 func VerifyInventoryImageSignature(image string) error {
-	cosignVerifier, err := getCosignVerifier(image)
+	return VerifyInventoryImageSignatureWithKeychain(image, nil)
+}
+
+// VerifyInventoryImageSignatureWithKeychain verifies the signature of the inventory image like
+// VerifyInventoryImageSignature, accessing the registry with the credentials of the keychain.
+// The registry is accessed anonymously if the keychain is nil.
+func VerifyInventoryImageSignatureWithKeychain(image string, keychain authn.Keychain) error {
+	cosignVerifier, err := newCosignVerifier(image, keychain, sharedVerifierState)
 	if err != nil {
 		return errors.Wrapf(err, "failed to initialize the cosign verifier")
 	}
@@ -35,7 +53,67 @@ func VerifyInventoryImageSignature(image string) error {
 	return nil
 }
 
+// VerifyInventoryImageSignatureWithResult verifies the signature of the inventory image
+// and returns the details of the verified signature.  Contrary to VerifyInventoryImageSignature,
+// a verification failure is returned as an error.  A nil result is returned if the
+// signature verification is skipped for the image by the user.
+// The registry is accessed with the credentials of the keychain, or anonymously if it is nil.
+func VerifyInventoryImageSignatureWithResult(image string, keychain authn.Keychain) (*cosignhelper.SignatureVerificationResult, error) {
+	return verifyInventoryImageSignatureWithState(image, keychain, sharedVerifierState)
+}
+
+// VerifyInventoryImageSignaturesWithResults verifies the signatures of a batch of inventory
+// images concurrently, their verifiers sharing the trust material.  The registry of each image
+// is accessed with the credentials of the keychain at the same index, or anonymously if there
+// is none.  The results and the errors are in the order of the images.  A nil result without
+// error means the signature verification is skipped for the image by the user.
+func VerifyInventoryImageSignaturesWithResults(images []string, keychains []authn.Keychain) ([]*cosignhelper.SignatureVerificationResult, []error) {
+	return verifyInventoryImageSignaturesWithResults(images, keychains, sharedVerifierState)
+}
+
+func verifyInventoryImageSignaturesWithResults(images []string, keychains []authn.Keychain, state *cosignhelper.SharedVerifierState) ([]*cosignhelper.SignatureVerificationResult, []error) {
+	results := make([]*cosignhelper.SignatureVerificationResult, len(images))
+	errs := make([]error, len(images))
+
+	semaphore := make(chan struct{}, maxConcurrentSignatureVerifications)
+	var wg sync.WaitGroup
+	for i := range images {
+		var keychain authn.Keychain
+		if i < len(keychains) {
+			keychain = keychains[i]
+		}
+		wg.Add(1)
+		go func(index int, keychain authn.Keychain) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			results[index], errs[index] = verifyInventoryImageSignatureWithState(images[index], keychain, state)
+		}(i, keychain)
+	}
+	wg.Wait()
+	return results, errs
+}
+
+// verifyInventoryImageSignatureWithState verifies the signature of the inventory image with
+// a verifier using the keychain and the specified shared state, or loading its trust material
+// if it is nil
+func verifyInventoryImageSignatureWithState(image string, keychain authn.Keychain, state *cosignhelper.SharedVerifierState) (*cosignhelper.SignatureVerificationResult, error) {
+	cosignVerifier, err := newCosignVerifier(image, keychain, state)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to initialize the cosign verifier")
+	}
+	result, err := verifyInventoryImageSignatureWithResult(image, cosignVerifier)
+	if err != nil {
+		return nil, errors.Wrapf(err, "plugins discovery image signature verification failed for %q", image)
+	}
+	return result, nil
+}
+
 func getCosignVerifier(image string) (cosignhelper.Cosignhelper, error) {
+	return newCosignVerifier(image, nil, sharedVerifierState)
+}
+
+func newCosignVerifier(image string, keychain authn.Keychain, state *cosignhelper.SharedVerifierState) (cosignhelper.Cosignhelper, error) {
 	// Get the custom public key path and prepare cosign verifier, if empty, cosign verifier would use embedded public key for verification
 	customPublicKeyPath := os.Getenv(constants.PublicKeyPathForPluginDiscoveryImageSignature)
 
@@ -43,12 +121,20 @@ func getCosignVerifier(image string) (cosignhelper.Cosignhelper, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to prepare the registry options for cosign verification")
 	}
-	return cosignhelper.NewCosignVerifier(customPublicKeyPath, registryOptions), nil
+	registryOptions.Keychain = keychain
+	if state == nil {
+		return cosignhelper.NewCosignVerifier(customPublicKeyPath, registryOptions), nil
+	}
+	return cosignhelper.NewCosignVerifierWithSharedState(customPublicKeyPath, registryOptions, state), nil
 }
 
 // getCosignVerifierRegistryOptions prepares the registry options by including the custom certificate configuration if any
 func getCosignVerifierRegistryOptions(image string) (*cosignhelper.RegistryOptions, error) {
 	registryOpts := &cosignhelper.RegistryOptions{}
+	if registry.IsLocalImage(image) {
+		// No registry is accessed to verify the signature of a local image
+		return registryOpts, nil
+	}
 	registryName, err := registry.GetRegistryName(strings.TrimSpace(image))
 	if err != nil {
 		return nil, err
@@ -61,25 +147,55 @@ func getCosignVerifierRegistryOptions(image string) (*cosignhelper.RegistryOptio
 	registryOpts.CACertPaths = regCertOptions.CACertPaths
 	registryOpts.SkipCertVerify = regCertOptions.SkipCertVerify
 	registryOpts.AllowInsecure = regCertOptions.Insecure
+	registryOpts.ClientCertPath = regCertOptions.ClientCertPath
+	registryOpts.ClientKeyPath = regCertOptions.ClientKeyPath
 
 	return registryOpts, nil
 }
 
+// resultsVerifier is implemented by the verifiers able to report the details of the verified signatures
+type resultsVerifier interface {
+	VerifyWithResults(ctx context.Context, images []string) ([]cosignhelper.SignatureVerificationResult, error)
+}
+
 func verifyInventoryImageSignature(image string, verifier cosignhelper.Cosignhelper) error {
+	_, err := verifyInventoryImageSignatureWithResult(image, verifier)
+	return err
+}
+
+func verifyInventoryImageSignatureWithResult(image string, verifier cosignhelper.Cosignhelper) (*cosignhelper.SignatureVerificationResult, error) {
 	signatureVerificationSkipSet := getPluginDiscoveryImagesSkippedForSignatureVerification()
 	if _, exists := signatureVerificationSkipSet[strings.TrimSpace(image)]; exists {
 		// log warning message iff user had not chosen to skip warning message for signature verification
 		if skip, _ := strconv.ParseBool(os.Getenv(constants.SuppressSkipSignatureVerificationWarning)); !skip {
 			log.Warningf("Skipping the plugins discovery image signature verification for %q\n ", image)
 		}
-		return nil
+		return nil, nil
+	}
+
+	if rv, ok := verifier.(resultsVerifier); ok {
+		results, err := rv.VerifyWithResults(context.Background(), []string{image})
+		if err != nil {
+			return nil, err
+		}
+		if len(results) > 0 {
+			return &results[0], nil
+		}
+		return &cosignhelper.SignatureVerificationResult{Image: image}, nil
 	}
 
 	err := verifier.Verify(context.Background(), []string{image})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return &cosignhelper.SignatureVerificationResult{Image: image}, nil
+}
+
+// IsSignatureVerificationSkipped returns true if the user chose to skip the verification
+// of the signature of the specified plugin discovery image
+func IsSignatureVerificationSkipped(image string) bool {
+	_, exists := getPluginDiscoveryImagesSkippedForSignatureVerification()[strings.TrimSpace(image)]
+	return exists
 }
 
 func getPluginDiscoveryImagesSkippedForSignatureVerification() map[string]struct{} {
@@ -4,18 +4,34 @@
 package sigverifier
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"encoding/base64"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/random"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	cosignlayout "github.com/sigstore/cosign/v2/pkg/oci/layout"
+	cosignmutate "github.com/sigstore/cosign/v2/pkg/oci/mutate"
+	"github.com/sigstore/cosign/v2/pkg/oci/signed"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/payload"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/configpaths"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/fakes"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
 	configlib "github.com/vmware-tanzu/tanzu-plugin-runtime/config"
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 )
@@ -83,6 +99,127 @@ var _ = Describe("Unit tests for discovery image signature verification", func()
 		})
 	})
 
+	Describe("Verify inventory image signature with result", func() {
+		var (
+			cosignVerifier *fakes.Cosignhelperfake
+			image          string
+		)
+		BeforeEach(func() {
+			cosignVerifier = &fakes.Cosignhelperfake{}
+			image = "test-image:latest"
+		})
+		AfterEach(func() {
+			os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+		})
+		Context("Cosign signature verification is success", func() {
+			It("should return the verified image", func() {
+				cosignVerifier.VerifyReturns(nil)
+				result, err := verifyInventoryImageSignatureWithResult(image, cosignVerifier)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).ToNot(BeNil())
+				Expect(result.Image).To(Equal(image))
+				Expect(cosignVerifier.VerifyCallCount()).To(Equal(1))
+			})
+		})
+		Context("When the image is in the signature verification skip list", func() {
+			It("should not verify the signature and return a nil result", func() {
+				cosignVerifier.VerifyReturns(fmt.Errorf("signature verification fake error"))
+				os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, image)
+				result, err := verifyInventoryImageSignatureWithResult(image, cosignVerifier)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(BeNil())
+				Expect(cosignVerifier.VerifyCallCount()).To(Equal(0))
+			})
+		})
+		Context("Cosign signature verification failed", func() {
+			It("should return error", func() {
+				cosignVerifier.VerifyReturns(fmt.Errorf("signature verification fake error"))
+				result, err := verifyInventoryImageSignatureWithResult(image, cosignVerifier)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("signature verification fake error"))
+				Expect(result).To(BeNil())
+			})
+		})
+	})
+
+	Describe("Verify the signatures of a batch of inventory images", func() {
+		AfterEach(func() {
+			os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+		})
+		It("should return the result and the error of each image in the order of the images", func() {
+			layoutDir, err := os.MkdirTemp("", "layout")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(layoutDir)
+
+			images := make([]string, 2*maxConcurrentSignatureVerifications)
+			for i := range images {
+				images[i] = fmt.Sprintf("%s%s/image-%d", registry.OCILayoutPrefix, layoutDir, i)
+			}
+			os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, images[1])
+
+			results, errs := verifyInventoryImageSignaturesWithResults(images, nil, cosignhelper.NewSharedVerifierState())
+			Expect(results).To(HaveLen(len(images)))
+			Expect(errs).To(HaveLen(len(images)))
+			for i := range images {
+				Expect(results[i]).To(BeNil())
+				if i == 1 {
+					Expect(errs[i]).ToNot(HaveOccurred())
+					continue
+				}
+				// The images are not OCI image layouts
+				Expect(errs[i]).To(HaveOccurred())
+				Expect(errs[i].Error()).To(ContainSubstring(images[i]))
+			}
+		})
+		It("should report the failure to load the trust material for each image", func() {
+			os.Setenv(constants.PublicKeyPathForPluginDiscoveryImageSignature, "does/not/exist")
+			defer os.Unsetenv(constants.PublicKeyPathForPluginDiscoveryImageSignature)
+
+			images := []string{registry.OCILayoutPrefix + "image-1", registry.OCILayoutPrefix + "image-2"}
+			_, errs := verifyInventoryImageSignaturesWithResults(images, nil, cosignhelper.NewSharedVerifierState())
+			for i := range images {
+				Expect(errs[i]).To(HaveOccurred())
+				Expect(errs[i].Error()).To(ContainSubstring("loading custom public key"))
+			}
+		})
+		It("should load the trust material again after a failure to load it", func() {
+			keyDir, err := os.MkdirTemp("", "key")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(keyDir)
+			publicKeyPath := filepath.Join(keyDir, "cosign.pub")
+			os.Setenv(constants.PublicKeyPathForPluginDiscoveryImageSignature, publicKeyPath)
+			defer os.Unsetenv(constants.PublicKeyPathForPluginDiscoveryImageSignature)
+
+			state := cosignhelper.NewSharedVerifierState()
+			images := []string{registry.OCILayoutPrefix + "image-1"}
+			_, errs := verifyInventoryImageSignaturesWithResults(images, nil, state)
+			Expect(errs[0]).To(HaveOccurred())
+			Expect(errs[0].Error()).To(ContainSubstring("loading custom public key"))
+
+			_, publicKeyPEM := newSigningKey()
+			Expect(os.WriteFile(publicKeyPath, publicKeyPEM, 0o600)).To(Succeed())
+			_, errs = verifyInventoryImageSignaturesWithResults(images, nil, state)
+			// The image is not an OCI image layout
+			Expect(errs[0]).To(HaveOccurred())
+			Expect(errs[0].Error()).ToNot(ContainSubstring("loading custom public key"))
+		})
+	})
+
+	Describe("IsSignatureVerificationSkipped", func() {
+		AfterEach(func() {
+			os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+		})
+		It("should only return true for the images of the skip list", func() {
+			os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, "other-image:latest, test-image:latest")
+			Expect(IsSignatureVerificationSkipped("test-image:latest")).To(BeTrue())
+			Expect(IsSignatureVerificationSkipped(" other-image:latest ")).To(BeTrue())
+			Expect(IsSignatureVerificationSkipped("test-image:v1")).To(BeFalse())
+		})
+		It("should return false without a skip list", func() {
+			Expect(IsSignatureVerificationSkipped("test-image:latest")).To(BeFalse())
+		})
+	})
+
 	Describe("getCosignVerifier tests", func() {
 		var (
 			cosignVerifier cosignhelper.Cosignhelper
@@ -159,3 +296,112 @@ var _ = Describe("Unit tests for discovery image signature verification", func()
 		})
 	})
 })
+
+// newBenchmarkImages returns local images which are not OCI image layouts.  Their signature
+// verification fails once the trust material is loaded, so the benchmarks measure the
+// cost of setting up the verification, which is what the shared state saves.
+func newBenchmarkImages(b *testing.B, count int) []string {
+	layoutDir := b.TempDir()
+	images := make([]string, count)
+	for i := range images {
+		images[i] = fmt.Sprintf("%s%s/image-%d", registry.OCILayoutPrefix, layoutDir, i)
+	}
+	return images
+}
+
+func BenchmarkVerifyInventoryImageSignaturesPerImage(b *testing.B) {
+	images := newBenchmarkImages(b, 20)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, image := range images {
+			_, _ = verifyInventoryImageSignatureWithState(image, nil, nil)
+		}
+	}
+}
+
+func BenchmarkVerifyInventoryImageSignaturesBatched(b *testing.B) {
+	images := newBenchmarkImages(b, 20)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = verifyInventoryImageSignaturesWithResults(images, nil, cosignhelper.NewSharedVerifierState())
+	}
+}
+
+// newSigningKey returns a new ECDSA signing key along with its PEM encoded public key
+func newSigningKey() (*ecdsa.PrivateKey, []byte) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	publicKeyPEM, err := cryptoutils.MarshalPublicKeyToPEM(&privateKey.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+	return privateKey, publicKeyPEM
+}
+
+// newSignedBenchmarkImage returns a local image signed like `cosign sign` and saved like
+// `cosign save`, along with the path of the public key verifying its signature
+func newSignedBenchmarkImage(b *testing.B) (string, string) {
+	privateKey, publicKeyPEM := newSigningKey()
+	publicKeyPath := filepath.Join(b.TempDir(), "cosign.pub")
+	if err := os.WriteFile(publicKeyPath, publicKeyPEM, 0o600); err != nil {
+		b.Fatal(err)
+	}
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		b.Fatal(err)
+	}
+	ref, err := name.NewDigest("localhost/tanzu-cli/plugin-inventory@" + digest.String())
+	if err != nil {
+		b.Fatal(err)
+	}
+	signingPayload, err := payload.Cosign{Image: ref}.MarshalJSON()
+	if err != nil {
+		b.Fatal(err)
+	}
+	signer, err := signature.LoadECDSASignerVerifier(privateKey, crypto.SHA256)
+	if err != nil {
+		b.Fatal(err)
+	}
+	rawSignature, err := signer.SignMessage(bytes.NewReader(signingPayload))
+	if err != nil {
+		b.Fatal(err)
+	}
+	ociSignature, err := static.NewSignature(signingPayload, base64.StdEncoding.EncodeToString(rawSignature))
+	if err != nil {
+		b.Fatal(err)
+	}
+	signedImage, err := cosignmutate.AttachSignatureToImage(signed.Image(img), ociSignature)
+	if err != nil {
+		b.Fatal(err)
+	}
+	layoutDir := b.TempDir()
+	if err := cosignlayout.WriteSignedImage(layoutDir, signedImage); err != nil {
+		b.Fatal(err)
+	}
+	return registry.OCILayoutPrefix + layoutDir, publicKeyPath
+}
+
+func BenchmarkVerifyInventoryImageSignatureSuccess(b *testing.B) {
+	image, publicKeyPath := newSignedBenchmarkImage(b)
+	b.Setenv(constants.PublicKeyPathForPluginDiscoveryImageSignature, publicKeyPath)
+	state := cosignhelper.NewSharedVerifierState()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := verifyInventoryImageSignatureWithState(image, nil, state); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
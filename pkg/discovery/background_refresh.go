@@ -0,0 +1,122 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+// backgroundRefreshes tracks the inventories being refreshed in the background,
+// by cache directory, so that each inventory is only refreshed once at a time
+var backgroundRefreshes = struct {
+	sync.Mutex
+	inProgress map[string]bool
+	wg         sync.WaitGroup
+}{inProgress: map[string]bool{}}
+
+// backgroundRefreshStop tells the background refreshes that the CLI is exiting,
+// so that they no longer update the cache
+var backgroundRefreshStop struct {
+	sync.Mutex
+	stopped bool
+}
+
+// errBackgroundRefreshStopped is returned by a background refresh stopped by StopBackgroundRefreshes
+var errBackgroundRefreshStopped = errors.New("the refresh was stopped as the CLI is exiting")
+
+// isBackgroundRefreshEnabled returns whether the cached inventories are used right away
+// and refreshed in the background, as requested by TANZU_CLI_PLUGIN_DISCOVERY_BACKGROUND_REFRESH
+func isBackgroundRefreshEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv(constants.ConfigVariablePluginDiscoveryBackgroundRefresh))
+	return enabled
+}
+
+// refreshInBackground fetches the inventory image of the discovery in a goroutine,
+// unless it is already being refreshed.  The queries keep using the cached inventory,
+// and its digest file, until the new database is complete and renamed into place;
+// the digest file of the new inventory is written last.  A refresh stopped by
+// StopBackgroundRefreshes therefore leaves the previous inventory, which the next
+// command keeps using while it refreshes it again.
+func (od *DBBackedOCIDiscovery) refreshInBackground() {
+	backgroundRefreshes.Lock()
+	defer backgroundRefreshes.Unlock()
+	if backgroundRefreshes.inProgress[od.pluginDataDir] {
+		return
+	}
+	backgroundRefreshes.inProgress[od.pluginDataDir] = true
+	backgroundRefreshes.wg.Add(1)
+
+	refresher := *od
+	refresher.inBackground = true
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.V(4).Infof("The background refresh of the inventory of discovery '%s' panicked: %v", refresher.Name(), r)
+			}
+			backgroundRefreshes.Lock()
+			delete(backgroundRefreshes.inProgress, refresher.pluginDataDir)
+			backgroundRefreshes.Unlock()
+			backgroundRefreshes.wg.Done()
+		}()
+		unlock, err := refresher.lockCache()
+		if err != nil {
+			log.V(4).Infof("Unable to refresh the inventory of discovery '%s' in the background: %v", refresher.Name(), err)
+			return
+		}
+		defer unlock()
+		if err := refresher.fetchInventoryImage(); err != nil {
+			log.V(4).Infof("Unable to refresh the inventory of discovery '%s' in the background: %v", refresher.Name(), err)
+		}
+	}()
+}
+
+// WaitForBackgroundRefreshes waits for the inventories being refreshed in the background,
+// for at most the specified duration.  It returns false if some refreshes did not complete.
+func WaitForBackgroundRefreshes(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		backgroundRefreshes.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// StopBackgroundRefreshes stops the refreshes in progress in the background from updating
+// the cache, waiting for an update of the cache in progress to complete.  It is called
+// when the CLI exits without waiting for the refreshes; the temporary files of the
+// refreshes are then removed by interrupt.Cleanup(), with which they are registered.
+func StopBackgroundRefreshes() {
+	backgroundRefreshStop.Lock()
+	defer backgroundRefreshStop.Unlock()
+	backgroundRefreshStop.stopped = true
+}
+
+// updateCache runs the update moving what the discovery downloaded into its cache.
+// The update of a refresh in the background is not run once StopBackgroundRefreshes
+// has been called, and StopBackgroundRefreshes does not return while it is running,
+// so that the exit of the CLI never leaves it incomplete.
+func (od *DBBackedOCIDiscovery) updateCache(update func() error) error {
+	if !od.inBackground {
+		return update()
+	}
+	backgroundRefreshStop.Lock()
+	defer backgroundRefreshStop.Unlock()
+	if backgroundRefreshStop.stopped {
+		return errBackgroundRefreshStopped
+	}
+	return update()
+}
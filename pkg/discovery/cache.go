@@ -0,0 +1,377 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+// evictingSuffix is appended to the directory of a cached inventory being evicted
+const evictingSuffix = ".evicting"
+
+// cachedInventory describes the cache directory of the inventory of a discovery
+type cachedInventory struct {
+	dir      string
+	size     int64
+	lastUsed time.Time
+	// keep is true if the inventory must not be evicted
+	keep bool
+}
+
+// CachedInventoryInfo describes the cached inventory of a discovery as found on disk
+type CachedInventoryInfo struct {
+	// Name is the name of the cache directory of the inventory
+	Name string `json:"name" yaml:"name"`
+	// DBFile is the path of the cached inventory database
+	DBFile string `json:"dbFile" yaml:"dbFile"`
+	// Size is the size in bytes of all the files of the cache directory
+	Size int64 `json:"size" yaml:"size"`
+	// Digest is the digest of the inventory image as recorded in the digest file
+	Digest string `json:"digest" yaml:"digest"`
+	// MetadataPresent indicates if the inventory was merged with a metadata image
+	MetadataPresent bool `json:"metadataPresent" yaml:"metadataPresent"`
+	// LastModified is the time the cached database was last written
+	LastModified time.Time `json:"lastModified" yaml:"lastModified"`
+}
+
+// ListCachedInventories returns the inventories found in the plugin inventory cache,
+// sorted by name.  The leftovers of an interrupted eviction are ignored.
+func ListCachedInventories() ([]CachedInventoryInfo, error) {
+	inventoryDir := filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName)
+	entries, err := os.ReadDir(inventoryDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "unable to read the plugin inventory cache")
+	}
+
+	var infos []CachedInventoryInfo
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasSuffix(entry.Name(), evictingSuffix) {
+			continue
+		}
+		infos = append(infos, getCachedInventoryInfo(filepath.Join(inventoryDir, entry.Name())))
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
+
+// getCachedInventoryInfo reads the content of the cache directory of an inventory.
+// The digest files are named "digest.<identity>.<hash>" and
+// "metadata.digest.<identity>.<hash>", where <hash> is "none" when
+// there is no metadata image.
+func getCachedInventoryInfo(dir string) CachedInventoryInfo {
+	info := CachedInventoryInfo{
+		Name: filepath.Base(dir),
+		Size: getCachedInventory(dir).size,
+	}
+
+	dbFile := filepath.Join(dir, plugininventory.SQliteDBFileName)
+	if stat, err := os.Stat(dbFile); err == nil {
+		info.DBFile = dbFile
+		info.LastModified = stat.ModTime()
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "digest.*")); len(matches) > 0 {
+		info.Digest = digestFromFileName(matches[0])
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "metadata.digest.*")); len(matches) > 0 {
+		info.MetadataPresent = digestFromFileName(matches[0]) != "none"
+	}
+	return info
+}
+
+// CheckCachedInventory returns an error explaining why a cached inventory cannot be used:
+// a digest file left without its database, a database without its digest file, or a
+// corrupt database.  Such a cached inventory is fetched again by its discovery.
+func CheckCachedInventory(info *CachedInventoryInfo) error {
+	if info.DBFile == "" {
+		if info.Digest != "" {
+			return errors.New("the digest file has no inventory database")
+		}
+		return errors.New("the cache directory has no inventory database")
+	}
+	if _, err := plugininventory.NewSQLiteInventory(info.DBFile, "").CountPlugins(nil); err != nil && isCorruptDatabaseError(err) {
+		return errors.Wrap(err, "the inventory database is corrupt")
+	}
+	if info.Digest == "" {
+		return errors.New("the inventory database has no digest file")
+	}
+	return nil
+}
+
+// digestFromFileName returns the hash that ends the name of a digest file
+func digestFromFileName(file string) string {
+	name := filepath.Base(file)
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// ParseCacheSize parses a cache size expressed as a quantity such as "500Mi" or "1G"
+// and returns it in bytes.
+func ParseCacheSize(size string) (int64, error) {
+	quantity, err := resource.ParseQuantity(strings.TrimSpace(size))
+	if err != nil || quantity.Sign() < 0 {
+		return 0, errors.Errorf("invalid cache size %q, a size such as '500Mi' or '1G' is expected", size)
+	}
+	return quantity.Value(), nil
+}
+
+// GetMaxCacheSize returns the maximum size in bytes of the cached plugin inventories
+// as configured by the user.  The returned boolean is false if there is no such limit.
+func GetMaxCacheSize() (int64, bool) {
+	return getMaxCacheSizeFromVariable(constants.ConfigVariablePluginDiscoveryMaxCacheSize)
+}
+
+// DefaultMaxArtifactCacheSize is the maximum size in bytes of the cached plugin binaries
+// when the user does not configure it
+const DefaultMaxArtifactCacheSize int64 = 1 << 30
+
+// GetMaxArtifactCacheSize returns the maximum size in bytes of the cached plugin binaries
+// as configured by the user, or DefaultMaxArtifactCacheSize otherwise.  The returned
+// boolean is always true since the plugin binaries are never cached without a limit.
+func GetMaxArtifactCacheSize() (int64, bool) {
+	if maxSize, found := getMaxCacheSizeFromVariable(constants.ConfigVariablePluginArtifactMaxCacheSize); found {
+		return maxSize, true
+	}
+	return DefaultMaxArtifactCacheSize, true
+}
+
+// getMaxCacheSizeFromVariable parses the maximum size of a cache configured in the specified variable
+func getMaxCacheSizeFromVariable(variable string) (int64, bool) {
+	value := strings.TrimSpace(os.Getenv(variable))
+	if value == "" {
+		return 0, false
+	}
+	maxSize, err := ParseCacheSize(value)
+	if err != nil {
+		log.Warningf("Ignoring the invalid value %q of %s, a size such as '500Mi' or '1G' is expected", value, variable)
+		return 0, false
+	}
+	return maxSize, true
+}
+
+// PruneInventoryCache evicts the least recently used cached plugin inventories until
+// their total size is at most maxSize bytes.  The previous inventories retained by the
+// discoveries are evicted first.  A cached inventory was last used when a discovery last
+// locked it to fetch or query it, or when its digest file was last written by a discovery
+// finding the cache up-to-date with its discovery image.  The cached inventories locked by
+// a discovery of this process or of another CLI process are in use and are never evicted.
+// The cache directory keepDir, if specified, is never evicted either, though its retained
+// inventories can be; it must already be locked by the caller.  The names of the evicted
+// cache directories are returned.
+func PruneInventoryCache(maxSize int64, keepDir string) ([]string, error) {
+	inventoryDir := filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName)
+	entries, err := os.ReadDir(inventoryDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "unable to read the plugin inventory cache")
+	}
+
+	var caches []cachedInventory
+	var totalSize int64
+	var unlocks []func()
+	defer func() {
+		for _, unlock := range unlocks {
+			unlock()
+		}
+	}()
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(inventoryDir, entry.Name())
+		if strings.HasSuffix(entry.Name(), evictingSuffix) {
+			// Left over from an interrupted eviction
+			_ = os.RemoveAll(dir)
+			continue
+		}
+		cache := getCachedInventory(dir)
+		totalSize += cache.size
+		if keepDir != "" && filepath.Clean(dir) == filepath.Clean(keepDir) {
+			cache.keep = true
+		} else {
+			unlock, locked := tryLockInventoryCache(dir)
+			if !locked {
+				// The inventory is in use, it counts in the size of the cache but is left alone
+				continue
+			}
+			unlocks = append(unlocks, unlock)
+		}
+		caches = append(caches, cache)
+	}
+
+	sort.Slice(caches, func(i, j int) bool {
+		return caches[i].lastUsed.Before(caches[j].lastUsed)
+	})
+
+	// The inventories retained in case an image changes back to them are
+	// evicted first, before the current inventory of any discovery
+	for i := range caches {
+		if totalSize <= maxSize {
+			break
+		}
+		freed := evictRetainedInventories(caches[i].dir, getCachedInventoryInfo(caches[i].dir).Digest)
+		caches[i].size -= freed
+		totalSize -= freed
+	}
+
+	var evicted []string
+	errorList := make([]error, 0)
+	for _, cache := range caches {
+		if totalSize <= maxSize {
+			break
+		}
+		if cache.keep {
+			continue
+		}
+		if err := evictCachedInventory(cache.dir); err != nil {
+			errorList = append(errorList, err)
+			continue
+		}
+		totalSize -= cache.size
+		evicted = append(evicted, filepath.Base(cache.dir))
+	}
+	return evicted, kerrors.NewAggregate(errorList)
+}
+
+// RemoveUnreferencedInventories evicts the cached plugin inventories of the discovery
+// sources which are not referenced.  The cached inventory of a discovery source selected
+// through a discovery profile, named "<discovery>@<profile>", and those of the images
+// aggregated by a composite discovery source, named "<discovery>+<n>", are referenced by
+// their discovery source.  The names of the evicted cache directories are returned.
+func RemoveUnreferencedInventories(referenced map[string]bool) ([]string, error) {
+	inventories, err := ListCachedInventories()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	errorList := make([]error, 0)
+	for i := range inventories {
+		if discoveryName, _ := splitInventoryCacheName(inventories[i].Name); referenced[discoveryName] {
+			continue
+		}
+		dir := filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName, inventories[i].Name)
+		unlock, locked := tryLockInventoryCache(dir)
+		if !locked {
+			errorList = append(errorList, errors.Errorf("the cached plugin inventory %q is in use by another command", inventories[i].Name))
+			continue
+		}
+		err := evictCachedInventory(dir)
+		unlock()
+		if err != nil {
+			errorList = append(errorList, err)
+			continue
+		}
+		removed = append(removed, inventories[i].Name)
+	}
+	return removed, kerrors.NewAggregate(errorList)
+}
+
+// RenameCachedInventories moves the cached plugin inventories of a discovery source,
+// including those cached for its discovery profiles and its composite images, to the
+// cache directories of the new name of the discovery source.  The cached inventories are
+// keyed by the image and not by the name, so they remain up-to-date and are not
+// downloaded again.
+// A stale cache directory of the new name is evicted first.
+func RenameCachedInventories(oldName, newName string) error {
+	inventories, err := ListCachedInventories()
+	if err != nil {
+		return err
+	}
+
+	inventoryDir := filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName)
+	errorList := make([]error, 0)
+	for i := range inventories {
+		discoveryName, suffix := splitInventoryCacheName(inventories[i].Name)
+		if discoveryName != oldName {
+			continue
+		}
+
+		newDir := filepath.Join(inventoryDir, newName+suffix)
+		if _, err := os.Stat(newDir); err == nil {
+			if err := evictCachedInventory(newDir); err != nil {
+				errorList = append(errorList, err)
+				continue
+			}
+		}
+		if err := os.Rename(filepath.Join(inventoryDir, inventories[i].Name), newDir); err != nil {
+			errorList = append(errorList, errors.Wrapf(err, "unable to rename the cached plugin inventory %q", inventories[i].Name))
+		}
+	}
+	return kerrors.NewAggregate(errorList)
+}
+
+// splitInventoryCacheName splits the name of the cache directory of an inventory into
+// the name of its discovery source and the suffix identifying the discovery profile
+// and the composite image the inventory is cached for, if any, e.g., "default@STAGING+1".
+func splitInventoryCacheName(cacheName string) (discoveryName, suffix string) {
+	discoveryName = cacheName
+	if index := strings.LastIndex(discoveryName, compositeCacheSeparator); index > 0 && isDigits(discoveryName[index+1:]) {
+		discoveryName = discoveryName[:index]
+	}
+	if index := strings.LastIndex(discoveryName, "@"); index > 0 {
+		discoveryName = discoveryName[:index]
+	}
+	return discoveryName, cacheName[len(discoveryName):]
+}
+
+// isDigits returns true if the non-empty string s only contains decimal digits
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// getCachedInventory computes the size and the last use of the cache directory of an inventory
+func getCachedInventory(dir string) cachedInventory {
+	cache := cachedInventory{dir: dir}
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		cache.size += info.Size()
+		if strings.Contains(d.Name(), "digest.") && info.ModTime().After(cache.lastUsed) {
+			cache.lastUsed = info.ModTime()
+		}
+		return nil
+	})
+	// Without a digest file, the cache is incomplete and lastUsed
+	// remains the zero time so that it is evicted first
+	if lastAccess := getInventoryCacheLastAccess(dir); !cache.lastUsed.IsZero() && lastAccess.After(cache.lastUsed) {
+		cache.lastUsed = lastAccess
+	}
+	return cache
+}
+
+// evictCachedInventory removes the cache directory of an inventory.  The directory is
+// first renamed so that the database and its digest files disappear together and a
+// discovery never sees a digest file without its database.
+func evictCachedInventory(dir string) error {
+	evictingDir := dir + evictingSuffix
+	if err := os.Rename(dir, evictingDir); err != nil {
+		return errors.Wrapf(err, "unable to evict the cached plugin inventory %q", filepath.Base(dir))
+	}
+	return os.RemoveAll(evictingDir)
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/fslock"
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+)
+
+// cacheLockSuffix is appended to the cache directory of an inventory to name its lock file.
+// The lock file is kept next to the directory so that it survives the eviction of the directory.
+const cacheLockSuffix = ".lock"
+
+// getInventoryCacheLockFile returns the lock file of the cache directory of an inventory
+func getInventoryCacheLockFile(dir string) string {
+	return filepath.Clean(dir) + cacheLockSuffix
+}
+
+// isInInventoryCache returns true if the directory is the cache directory of an inventory,
+// as opposed to the directory of an inventory database provided by the user
+func isInInventoryCache(dir string) bool {
+	return filepath.Dir(filepath.Clean(dir)) == filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName)
+}
+
+// lockInventoryCache waits for the cache directory of an inventory to be available and locks
+// it, so that it is neither modified nor evicted by other discoveries of this process or of
+// other CLI processes while it is fetched and queried.  The time the lock is acquired is
+// recorded as the last use of the inventory.  The returned function releases the lock.
+func lockInventoryCache(dir string) (func(), error) {
+	if !isInInventoryCache(dir) {
+		return func() {}, nil
+	}
+	lockFile := getInventoryCacheLockFile(dir)
+	if err := os.MkdirAll(filepath.Dir(lockFile), 0755); err != nil {
+		return nil, errors.Wrapf(err, "unable to lock the cached plugin inventory %q", filepath.Base(dir))
+	}
+	lock := fslock.New(lockFile)
+	if err := lock.Lock(); err != nil {
+		return nil, errors.Wrapf(err, "unable to lock the cached plugin inventory %q", filepath.Base(dir))
+	}
+	now := time.Now()
+	_ = os.Chtimes(lockFile, now, now)
+	return func() { _ = lock.Unlock() }, nil
+}
+
+// tryLockInventoryCache locks the cache directory of an inventory unless it is in use.
+// The returned boolean is false if the directory is in use, in which case it is not locked.
+func tryLockInventoryCache(dir string) (func(), bool) {
+	lock := fslock.New(getInventoryCacheLockFile(dir))
+	if err := lock.TryLock(); err != nil {
+		return nil, false
+	}
+	return func() { _ = lock.Unlock() }, true
+}
+
+// getInventoryCacheLastAccess returns the time the cache directory of an inventory was last
+// locked to be used, or the zero time if it was never locked
+func getInventoryCacheLastAccess(dir string) time.Time {
+	info, err := os.Stat(getInventoryCacheLockFile(dir))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
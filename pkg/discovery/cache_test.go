@@ -0,0 +1,270 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+var _ = Describe("Cache of the plugin inventories", func() {
+	var (
+		tmpDir       string
+		inventoryDir string
+		origCacheDir string
+	)
+
+	// createCachedInventory creates the cache of an inventory with a database of the
+	// specified size and a digest file last written at the specified time
+	createCachedInventory := func(name string, size int, lastUsed time.Time) string {
+		dir := filepath.Join(inventoryDir, name)
+		Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, plugininventory.SQliteDBFileName), make([]byte, size), 0644)).To(Succeed())
+		digestFile := filepath.Join(dir, "digest.identity.hash")
+		Expect(os.WriteFile(digestFile, nil, 0644)).To(Succeed())
+		Expect(os.Chtimes(digestFile, lastUsed, lastUsed)).To(Succeed())
+		return dir
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "cache")
+		Expect(err).To(BeNil())
+		origCacheDir = common.DefaultCacheDir
+		common.DefaultCacheDir = tmpDir
+		inventoryDir = filepath.Join(tmpDir, common.PluginInventoryDirName)
+
+		now := time.Now()
+		createCachedInventory("oldest", 100, now.Add(-3*time.Hour))
+		createCachedInventory("middle", 100, now.Add(-2*time.Hour))
+		createCachedInventory("newest", 100, now.Add(-1*time.Hour))
+	})
+	AfterEach(func() {
+		common.DefaultCacheDir = origCacheDir
+		os.RemoveAll(tmpDir)
+		os.Unsetenv(constants.ConfigVariablePluginDiscoveryMaxCacheSize)
+	})
+
+	Context("when the cache is within its maximum size", func() {
+		It("should not evict anything", func() {
+			evicted, err := PruneInventoryCache(300, "")
+			Expect(err).To(BeNil())
+			Expect(evicted).To(BeEmpty())
+			Expect(filepath.Join(inventoryDir, "oldest")).To(BeADirectory())
+		})
+	})
+	Context("when the cache exceeds its maximum size", func() {
+		It("should evict the least recently used inventories first", func() {
+			evicted, err := PruneInventoryCache(150, "")
+			Expect(err).To(BeNil())
+			Expect(evicted).To(Equal([]string{"oldest", "middle"}))
+			Expect(filepath.Join(inventoryDir, "oldest")).ToNot(BeADirectory())
+			Expect(filepath.Join(inventoryDir, "middle")).ToNot(BeADirectory())
+			Expect(filepath.Join(inventoryDir, "newest")).To(BeADirectory())
+		})
+		It("should never evict the inventories in use", func() {
+			unlock, err := lockInventoryCache(filepath.Join(inventoryDir, "oldest"))
+			Expect(err).To(BeNil())
+			defer unlock()
+
+			evicted, err := PruneInventoryCache(150, "")
+			Expect(err).To(BeNil())
+			Expect(evicted).To(Equal([]string{"middle", "newest"}))
+			Expect(filepath.Join(inventoryDir, "oldest")).To(BeADirectory())
+		})
+		It("should evict the least recently accessed inventories first", func() {
+			unlock, err := lockInventoryCache(filepath.Join(inventoryDir, "oldest"))
+			Expect(err).To(BeNil())
+			unlock()
+
+			evicted, err := PruneInventoryCache(250, "")
+			Expect(err).To(BeNil())
+			Expect(evicted).To(Equal([]string{"middle"}))
+			Expect(filepath.Join(inventoryDir, "oldest")).To(BeADirectory())
+		})
+		It("should never evict the inventory being refreshed", func() {
+			evicted, err := PruneInventoryCache(250, filepath.Join(inventoryDir, "oldest"))
+			Expect(err).To(BeNil())
+			Expect(evicted).To(Equal([]string{"middle"}))
+			Expect(filepath.Join(inventoryDir, "oldest")).To(BeADirectory())
+		})
+		It("should evict incomplete inventories first", func() {
+			incompleteDir := filepath.Join(inventoryDir, "incomplete")
+			Expect(os.MkdirAll(incompleteDir, 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(incompleteDir, plugininventory.SQliteDBFileName), make([]byte, 100), 0644)).To(Succeed())
+
+			evicted, err := PruneInventoryCache(300, "")
+			Expect(err).To(BeNil())
+			Expect(evicted).To(Equal([]string{"incomplete"}))
+		})
+		It("should remove the leftovers of an interrupted eviction", func() {
+			leftoverDir := filepath.Join(inventoryDir, "leftover"+evictingSuffix)
+			Expect(os.MkdirAll(leftoverDir, 0755)).To(Succeed())
+
+			_, err := PruneInventoryCache(300, "")
+			Expect(err).To(BeNil())
+			Expect(leftoverDir).ToNot(BeADirectory())
+		})
+	})
+	Context("when the cache does not exist", func() {
+		It("should not fail", func() {
+			Expect(os.RemoveAll(inventoryDir)).To(Succeed())
+			evicted, err := PruneInventoryCache(0, "")
+			Expect(err).To(BeNil())
+			Expect(evicted).To(BeEmpty())
+		})
+	})
+	Context("when listing the cached inventories", func() {
+		It("should describe each cached inventory", func() {
+			Expect(os.WriteFile(filepath.Join(inventoryDir, "middle", "metadata.digest.identity.none"), nil, 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(inventoryDir, "newest", "metadata.digest.identity.metahash"), nil, 0644)).To(Succeed())
+
+			infos, err := ListCachedInventories()
+			Expect(err).To(BeNil())
+			Expect(infos).To(HaveLen(3))
+
+			Expect(infos[0].Name).To(Equal("middle"))
+			Expect(infos[0].DBFile).To(Equal(filepath.Join(inventoryDir, "middle", plugininventory.SQliteDBFileName)))
+			Expect(infos[0].Size).To(Equal(int64(100)))
+			Expect(infos[0].Digest).To(Equal("hash"))
+			Expect(infos[0].MetadataPresent).To(BeFalse())
+			Expect(infos[0].LastModified.IsZero()).To(BeFalse())
+
+			Expect(infos[1].Name).To(Equal("newest"))
+			Expect(infos[1].MetadataPresent).To(BeTrue())
+
+			Expect(infos[2].Name).To(Equal("oldest"))
+			Expect(infos[2].MetadataPresent).To(BeFalse())
+		})
+		It("should describe an incomplete inventory", func() {
+			incompleteDir := filepath.Join(inventoryDir, "incomplete")
+			Expect(os.MkdirAll(incompleteDir, 0755)).To(Succeed())
+
+			infos, err := ListCachedInventories()
+			Expect(err).To(BeNil())
+			Expect(infos).To(HaveLen(4))
+			Expect(infos[0].Name).To(Equal("incomplete"))
+			Expect(infos[0].DBFile).To(BeEmpty())
+			Expect(infos[0].Digest).To(BeEmpty())
+			Expect(infos[0].Size).To(BeZero())
+			Expect(infos[0].LastModified.IsZero()).To(BeTrue())
+		})
+		It("should ignore the leftovers of an interrupted eviction", func() {
+			Expect(os.MkdirAll(filepath.Join(inventoryDir, "leftover"+evictingSuffix), 0755)).To(Succeed())
+
+			infos, err := ListCachedInventories()
+			Expect(err).To(BeNil())
+			Expect(infos).To(HaveLen(3))
+		})
+		It("should not fail when the cache does not exist", func() {
+			Expect(os.RemoveAll(inventoryDir)).To(Succeed())
+			infos, err := ListCachedInventories()
+			Expect(err).To(BeNil())
+			Expect(infos).To(BeEmpty())
+		})
+	})
+	Context("when checking the integrity of the cached inventories", func() {
+		It("should report the unusable inventories", func() {
+			validDir := filepath.Join(inventoryDir, "valid")
+			Expect(os.MkdirAll(validDir, 0755)).To(Succeed())
+			Expect(plugininventory.NewSQLiteInventory(filepath.Join(validDir, plugininventory.SQliteDBFileName), "").CreateSchema()).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(validDir, "digest.identity.hash"), nil, 0644)).To(Succeed())
+			Expect(os.Remove(filepath.Join(inventoryDir, "newest", plugininventory.SQliteDBFileName))).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(inventoryDir, "incomplete"), 0755)).To(Succeed())
+
+			infos, err := ListCachedInventories()
+			Expect(err).To(BeNil())
+			Expect(infos).To(HaveLen(5))
+			Expect(CheckCachedInventory(&infos[0])).To(MatchError(ContainSubstring("the cache directory has no inventory database")))
+			Expect(CheckCachedInventory(&infos[1])).To(MatchError(ContainSubstring("the inventory database is corrupt")))
+			Expect(CheckCachedInventory(&infos[2])).To(MatchError(ContainSubstring("the digest file has no inventory database")))
+			Expect(CheckCachedInventory(&infos[4])).To(Succeed())
+		})
+	})
+	Context("when removing the inventories no longer referenced", func() {
+		It("should only remove the unreferenced inventories", func() {
+			createCachedInventory("middle@PROFILE", 100, time.Now())
+
+			removed, err := RemoveUnreferencedInventories(map[string]bool{"middle": true, "newest": true})
+			Expect(err).To(BeNil())
+			Expect(removed).To(Equal([]string{"oldest"}))
+			Expect(filepath.Join(inventoryDir, "oldest")).ToNot(BeADirectory())
+			Expect(filepath.Join(inventoryDir, "middle")).To(BeADirectory())
+			Expect(filepath.Join(inventoryDir, "middle@PROFILE")).To(BeADirectory())
+			Expect(filepath.Join(inventoryDir, "newest")).To(BeADirectory())
+		})
+		It("should remove the inventories of a profile of an unreferenced discovery", func() {
+			createCachedInventory("oldest@PROFILE", 100, time.Now())
+
+			removed, err := RemoveUnreferencedInventories(map[string]bool{"middle": true, "newest": true})
+			Expect(err).To(BeNil())
+			Expect(removed).To(ConsistOf("oldest", "oldest@PROFILE"))
+		})
+		It("should not fail when the cache does not exist", func() {
+			Expect(os.RemoveAll(inventoryDir)).To(Succeed())
+			removed, err := RemoveUnreferencedInventories(nil)
+			Expect(err).To(BeNil())
+			Expect(removed).To(BeEmpty())
+		})
+	})
+	Context("when renaming the inventories of a discovery", func() {
+		It("should move the inventories of the discovery and of its profiles", func() {
+			createCachedInventory("middle@PROFILE", 100, time.Now())
+			createCachedInventory("renamed", 50, time.Now())
+
+			Expect(RenameCachedInventories("middle", "renamed")).To(Succeed())
+			Expect(filepath.Join(inventoryDir, "middle")).ToNot(BeADirectory())
+			Expect(filepath.Join(inventoryDir, "middle@PROFILE")).ToNot(BeADirectory())
+			Expect(filepath.Join(inventoryDir, "renamed@PROFILE")).To(BeADirectory())
+
+			// The stale inventory of the new name is replaced
+			info, err := os.Stat(filepath.Join(inventoryDir, "renamed", plugininventory.SQliteDBFileName))
+			Expect(err).To(BeNil())
+			Expect(info.Size()).To(Equal(int64(100)))
+		})
+		It("should not fail when the discovery has no cached inventory", func() {
+			Expect(RenameCachedInventories("unknown", "renamed")).To(Succeed())
+			Expect(filepath.Join(inventoryDir, "renamed")).ToNot(BeADirectory())
+		})
+	})
+	Context("when configuring the maximum size of the cache", func() {
+		It("should parse the size", func() {
+			os.Setenv(constants.ConfigVariablePluginDiscoveryMaxCacheSize, "1Mi")
+			maxSize, found := GetMaxCacheSize()
+			Expect(found).To(BeTrue())
+			Expect(maxSize).To(Equal(int64(1024 * 1024)))
+		})
+		It("should ignore an invalid size", func() {
+			os.Setenv(constants.ConfigVariablePluginDiscoveryMaxCacheSize, "big")
+			_, found := GetMaxCacheSize()
+			Expect(found).To(BeFalse())
+
+			_, err := ParseCacheSize("-1G")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should report when no size is configured", func() {
+			_, found := GetMaxCacheSize()
+			Expect(found).To(BeFalse())
+		})
+		It("should limit the size of the plugin binaries by default", func() {
+			maxSize, found := GetMaxArtifactCacheSize()
+			Expect(found).To(BeTrue())
+			Expect(maxSize).To(Equal(DefaultMaxArtifactCacheSize))
+
+			os.Setenv(constants.ConfigVariablePluginArtifactMaxCacheSize, "1Mi")
+			defer os.Unsetenv(constants.ConfigVariablePluginArtifactMaxCacheSize)
+			maxSize, found = GetMaxArtifactCacheSize()
+			Expect(found).To(BeTrue())
+			Expect(maxSize).To(Equal(int64(1024 * 1024)))
+		})
+	})
+})
@@ -0,0 +1,340 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+// compositeCacheSeparator separates the cache name of a composite discovery source
+// from the position of the image cached, for every image but the first one
+const compositeCacheSeparator = "+"
+
+// CompositeDiscovery is a discovery aggregating the plugin inventories of multiple
+// OCI images, e.g., one image per team, so that they appear as a single discovery.
+// The inventory of each image is fetched and cached separately.
+type CompositeDiscovery struct {
+	// name is the name given to the discovery
+	name string
+	// images are the inventory images aggregated, in order of precedence
+	images []string
+	// discoveries are the discoveries of each image, in the order of the images
+	discoveries []*DBBackedOCIDiscovery
+	// pluginCriteria specifies different conditions that a plugin must respect to be discovered.
+	// Its limit and offset apply to the merged plugins rather than to those of each image.
+	pluginCriteria *PluginDiscoveryCriteria
+}
+
+// NewCompositeDiscovery returns a new Discovery aggregating the plugins of the
+// specified OCI images.  When the images provide the same version of a plugin,
+// the one of the first image wins.
+func NewCompositeDiscovery(name string, images []string, options ...DiscoveryOptions) Discovery {
+	return newCompositeDiscovery(name, images, options...)
+}
+
+// NewCompositeGroupDiscovery returns a new plugin group Discovery aggregating the
+// plugin groups of the specified OCI images.  When the images provide the same
+// version of a plugin group, the one of the first image wins.
+func NewCompositeGroupDiscovery(name string, images []string, options ...DiscoveryOptions) GroupDiscovery {
+	return newCompositeDiscovery(name, images, options...)
+}
+
+func newCompositeDiscovery(name string, images []string, options ...DiscoveryOptions) *CompositeDiscovery {
+	opts := NewDiscoveryOpts()
+	for _, option := range options {
+		option(opts)
+	}
+
+	cd := &CompositeDiscovery{
+		name:           name,
+		images:         images,
+		pluginCriteria: opts.PluginDiscoveryCriteria,
+	}
+
+	// The plugins of an image cannot be paginated before being merged with those of the other images
+	imageOptions := append([]DiscoveryOptions(nil), options...)
+	if opts.PluginDiscoveryCriteria != nil {
+		criteria := *opts.PluginDiscoveryCriteria
+		criteria.Limit = 0
+		criteria.Offset = 0
+		imageOptions = append(imageOptions, WithPluginDiscoveryCriteria(&criteria))
+	}
+
+	for i, image := range images {
+		od := NewOCIDiscovery(name, image, imageOptions...).(*DBBackedOCIDiscovery)
+		od.groupCriteria = opts.GroupDiscoveryCriteria
+		if i > 0 {
+			// The first image uses the cache of a non-composite discovery of the same name
+			od.pluginDataDir = getCompositeInventoryCacheDir(name, i)
+			od.inventory = plugininventory.NewSQLiteInventory(filepath.Join(od.pluginDataDir, plugininventory.SQliteDBFileName), path.Dir(image))
+			// The generation marker only tracks the image configured for the discovery source
+			od.generationMarkerURL = ""
+		}
+		cd.discoveries = append(cd.discoveries, od)
+	}
+	return cd
+}
+
+// getCompositeInventoryCacheDir returns the cache directory of the inventory of
+// the image at the specified position of a composite discovery source
+func getCompositeInventoryCacheDir(name string, index int) string {
+	cacheName := config.GetPluginInventoryCacheName(name)
+	if index > 0 {
+		cacheName += compositeCacheSeparator + strconv.Itoa(index)
+	}
+	return filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName, cacheName)
+}
+
+// GetInventoryCacheDirs returns the cache directories of the plugin inventories of the
+// specified discovery source, including those of the images it aggregates if it is composite.
+func GetInventoryCacheDirs(discoveryName string) []string {
+	dirs := []string{getCompositeInventoryCacheDir(discoveryName, 0)}
+	for i := range config.GetPluginDiscoveryCompositeImages(discoveryName) {
+		dirs = append(dirs, getCompositeInventoryCacheDir(discoveryName, i+1))
+	}
+	return dirs
+}
+
+// Name of the discovery.
+func (cd *CompositeDiscovery) Name() string {
+	return cd.name
+}
+
+// Type of the discovery.
+func (cd *CompositeDiscovery) Type() string {
+	return common.DiscoveryTypeOCI
+}
+
+// List returns the plugins of all the images of the discovery, merging the entries
+// of a plugin provided by more than one image.  The listing fails if the plugins
+// of any of the images cannot be listed, as the result would be incomplete.
+func (cd *CompositeDiscovery) List() ([]Discovered, error) {
+	pluginsPerImage := make([][]Discovered, len(cd.discoveries))
+	for i, od := range cd.discoveries {
+		plugins, err := od.List()
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to list the plugins of image '%s'", cd.images[i])
+		}
+		pluginsPerImage[i] = plugins
+	}
+	return cd.paginate(cd.mergePlugins(pluginsPerImage)), nil
+}
+
+// GetGroups returns the plugin groups of all the images of the discovery, merging
+// the entries of a plugin group provided by more than one image.
+func (cd *CompositeDiscovery) GetGroups() ([]*plugininventory.PluginGroup, error) {
+	var merged []*plugininventory.PluginGroup
+	indexes := make(map[string]int)
+	for i, od := range cd.discoveries {
+		groups, err := od.GetGroups()
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to list the plugin groups of image '%s'", cd.images[i])
+		}
+		for _, group := range groups {
+			id := plugininventory.PluginGroupToID(group)
+			index, exists := indexes[id]
+			if !exists {
+				indexes[id] = len(merged)
+				merged = append(merged, group)
+				continue
+			}
+			merged[index] = cd.mergeGroupEntries(merged[index], group, cd.images[i])
+		}
+	}
+	return merged, nil
+}
+
+// Refresh updates the cached inventory of every image of the discovery
+// that is not up-to-date.
+func (cd *CompositeDiscovery) Refresh() error {
+	errorList := make([]error, 0)
+	for i, od := range cd.discoveries {
+		if err := od.Refresh(); err != nil {
+			errorList = append(errorList, errors.Wrapf(err, "unable to refresh the inventory of image '%s'", cd.images[i]))
+		}
+	}
+	return kerrors.NewAggregate(errorList)
+}
+
+// mergePlugins merges the plugins listed for each image into a single entry per
+// name-target combination, sorted by name and target as the inventories sort them
+func (cd *CompositeDiscovery) mergePlugins(pluginsPerImage [][]Discovered) []Discovered {
+	var merged []Discovered
+	indexes := make(map[string]int)
+	for i, plugins := range pluginsPerImage {
+		for j := range plugins {
+			key := plugins[j].Name + "_" + string(plugins[j].Target)
+			index, exists := indexes[key]
+			if !exists {
+				indexes[key] = len(merged)
+				merged = append(merged, plugins[j])
+				continue
+			}
+			cd.mergePluginEntries(&merged[index], &plugins[j], cd.images[i])
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Name != merged[j].Name {
+			return merged[i].Name < merged[j].Name
+		}
+		return merged[i].Target < merged[j].Target
+	})
+	return merged
+}
+
+// mergePluginEntries adds the versions of the plugin found in another image to the
+// plugin.  A version already provided by the plugin is kept; a warning is logged if
+// the other image provides different binaries for it.
+func (cd *CompositeDiscovery) mergePluginEntries(plugin, other *Discovered, image string) {
+	artifacts, ok := plugin.Distribution.(distribution.Artifacts)
+	if !ok {
+		return
+	}
+	otherArtifacts, ok := other.Distribution.(distribution.Artifacts)
+	if !ok {
+		return
+	}
+
+	// The deprecation of the whole plugin only applies to the versions of the image declaring it
+	if !other.Deprecated {
+		plugin.DeprecateVersions()
+	}
+
+	// The entries are copied before being modified, as they may be shared with the
+	// results of the queries of the inventory
+	mergedArtifacts := make(distribution.Artifacts, len(artifacts)+len(otherArtifacts))
+	for version := range artifacts {
+		mergedArtifacts[version] = artifacts[version]
+	}
+	versions := append([]string(nil), plugin.SupportedVersions...)
+	dependencies := copyMap(plugin.Dependencies)
+	deprecatedVersions := copyMap(plugin.DeprecatedVersions)
+	minCLIVersions := copyMap(plugin.MinCLIVersions)
+	for version := range otherArtifacts {
+		if existing, exists := mergedArtifacts[version]; exists {
+			if !isSameArtifactList(existing, otherArtifacts[version]) {
+				log.Warningf("Ignoring version '%s' of plugin '%s' of image '%s' as discovery '%s' already provides different binaries for it",
+					version, plugin.Name, image, cd.name)
+			}
+			continue
+		}
+		mergedArtifacts[version] = otherArtifacts[version]
+		versions = append(versions, version)
+
+		// Keep the information that goes with the version that was added
+		if deps, found := other.Dependencies[version]; found {
+			dependencies[version] = deps
+		}
+		if deprecated, message := other.GetDeprecation(version); deprecated {
+			deprecatedVersions[version] = message
+		}
+		if minCLIVersion, found := other.MinCLIVersions[version]; found {
+			minCLIVersions[version] = minCLIVersion
+		}
+	}
+	_ = utils.SortVersions(versions)
+
+	plugin.Distribution = mergedArtifacts
+	plugin.SupportedVersions = versions
+	plugin.Dependencies = dependencies
+	plugin.DeprecatedVersions = deprecatedVersions
+	plugin.MinCLIVersions = minCLIVersions
+	if plugin.Description == "" {
+		plugin.Description = other.Description
+	}
+	if !plugin.HasLinks() {
+		plugin.HomepageURL = other.HomepageURL
+		plugin.DocumentationURL = other.DocumentationURL
+		plugin.IssueTrackerURL = other.IssueTrackerURL
+	}
+
+	// The highest version recommended by the images is recommended
+	recommendedVersion := plugin.RecommendedVersion
+	if utils.IsNewVersion(other.RecommendedVersion, recommendedVersion) {
+		recommendedVersion = other.RecommendedVersion
+	}
+	plugin.RecommendedVersion = config.SelectRecommendedVersion(recommendedVersion, versions)
+}
+
+// mergeGroupEntries adds the versions of the plugin group found in another image to
+// the group.  A version already provided by the group is kept; a warning is logged if
+// the other image defines it with different plugins.
+func (cd *CompositeDiscovery) mergeGroupEntries(group, other *plugininventory.PluginGroup, image string) *plugininventory.PluginGroup {
+	merged := *group
+	merged.Versions = make(map[string][]*plugininventory.PluginGroupPluginEntry, len(group.Versions)+len(other.Versions))
+	for version := range group.Versions {
+		merged.Versions[version] = group.Versions[version]
+	}
+	for version := range other.Versions {
+		if existing, exists := merged.Versions[version]; exists {
+			if !reflect.DeepEqual(existing, other.Versions[version]) {
+				log.Warningf("Ignoring version '%s' of plugin group '%s' of image '%s' as discovery '%s' already provides different plugins for it",
+					version, plugininventory.PluginGroupToID(group), image, cd.name)
+			}
+			continue
+		}
+		merged.Versions[version] = other.Versions[version]
+	}
+
+	// The recommended version and the description are the ones of the group recommending the highest version
+	if utils.IsNewVersion(other.RecommendedVersion, group.RecommendedVersion) {
+		merged.RecommendedVersion = other.RecommendedVersion
+		merged.Description = other.Description
+	}
+	return &merged
+}
+
+// paginate applies the limit and offset of the plugin criteria to the merged plugins
+func (cd *CompositeDiscovery) paginate(plugins []Discovered) []Discovered {
+	if cd.pluginCriteria == nil {
+		return plugins
+	}
+	if cd.pluginCriteria.Offset > 0 {
+		if cd.pluginCriteria.Offset >= len(plugins) {
+			return nil
+		}
+		plugins = plugins[cd.pluginCriteria.Offset:]
+	}
+	if cd.pluginCriteria.Limit > 0 && cd.pluginCriteria.Limit < len(plugins) {
+		plugins = plugins[:cd.pluginCriteria.Limit]
+	}
+	return plugins
+}
+
+// isSameArtifactList returns false if the artifact lists provide binaries
+// with different digests for the same platform
+func isSameArtifactList(list, other distribution.ArtifactList) bool {
+	for i := range list {
+		for j := range other {
+			if list[i].OS == other[j].OS && list[i].Arch == other[j].Arch && list[i].Digest != other[j].Digest {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// copyMap returns a non-nil copy of the specified map
+func copyMap[V any](m map[string]V) map[string]V {
+	c := make(map[string]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
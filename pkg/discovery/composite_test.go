@@ -0,0 +1,192 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+// staticGroupInventory is an inventory returning a fixed list of plugins and plugin groups
+type staticGroupInventory struct {
+	staticInventory
+	groups []*plugininventory.PluginGroup
+}
+
+func (s *staticGroupInventory) GetPluginGroups(_ plugininventory.PluginGroupFilter) ([]*plugininventory.PluginGroup, error) {
+	return s.groups, nil
+}
+
+// newInventoryPlugin returns a plugin entry providing the specified versions,
+// each with a binary of the specified digest
+func newInventoryPlugin(name, recommendedVersion, digest string, versions ...string) *plugininventory.PluginInventoryEntry {
+	artifacts := distribution.Artifacts{}
+	for _, version := range versions {
+		artifacts[version] = distribution.ArtifactList{{OS: "linux", Arch: "amd64", Digest: digest}}
+	}
+	return &plugininventory.PluginInventoryEntry{
+		Name:               name,
+		Target:             configtypes.TargetK8s,
+		Description:        name + " description",
+		RecommendedVersion: recommendedVersion,
+		Artifacts:          artifacts,
+	}
+}
+
+var _ = Describe("Composite discovery", func() {
+	var (
+		tmpDir       string
+		origCacheDir string
+		inventories  []*staticGroupInventory
+	)
+
+	// newTestCompositeDiscovery returns a composite discovery of two images
+	// using the test inventories
+	newTestCompositeDiscovery := func(options ...DiscoveryOptions) *CompositeDiscovery {
+		cd := newCompositeDiscovery("test-discovery", []string{"registry/team1:latest", "registry/team2:latest"}, options...)
+		for i := range cd.discoveries {
+			cd.discoveries[i].useLocalCacheOnly = true
+			cd.discoveries[i].maxCacheAge = 0
+			cd.discoveries[i].inventory = inventories[i]
+		}
+		return cd
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "composite")
+		Expect(err).To(BeNil())
+		origCacheDir = common.DefaultCacheDir
+		common.DefaultCacheDir = tmpDir
+
+		inventories = []*staticGroupInventory{
+			{
+				staticInventory: staticInventory{plugins: []*plugininventory.PluginInventoryEntry{
+					newInventoryPlugin("cluster", "v1.0.0", "1111", "v1.0.0"),
+					newInventoryPlugin("login", "v0.1.0", "2222", "v0.1.0", "v0.2.0"),
+				}},
+				groups: []*plugininventory.PluginGroup{{
+					Vendor: "vmware", Publisher: "team", Name: "default", RecommendedVersion: "v1.0.0",
+					Versions: map[string][]*plugininventory.PluginGroupPluginEntry{
+						"v1.0.0": {{PluginIdentifier: plugininventory.PluginIdentifier{Name: "cluster", Target: configtypes.TargetK8s, Version: "v1.0.0"}}},
+					},
+				}},
+			},
+			{
+				staticInventory: staticInventory{plugins: []*plugininventory.PluginInventoryEntry{
+					newInventoryPlugin("apps", "v2.0.0", "3333", "v2.0.0"),
+					newInventoryPlugin("login", "v0.3.0", "4444", "v0.2.0", "v0.3.0"),
+				}},
+				groups: []*plugininventory.PluginGroup{{
+					Vendor: "vmware", Publisher: "team", Name: "default", RecommendedVersion: "v2.0.0",
+					Versions: map[string][]*plugininventory.PluginGroupPluginEntry{
+						"v1.0.0": {{PluginIdentifier: plugininventory.PluginIdentifier{Name: "apps", Target: configtypes.TargetK8s, Version: "v2.0.0"}}},
+						"v2.0.0": {{PluginIdentifier: plugininventory.PluginIdentifier{Name: "apps", Target: configtypes.TargetK8s, Version: "v2.0.0"}}},
+					},
+				}},
+			},
+		}
+	})
+	AfterEach(func() {
+		common.DefaultCacheDir = origCacheDir
+		os.RemoveAll(tmpDir)
+		os.Unsetenv(constants.ConfigVariablePluginDiscoveryCompositeImagesPrefix + "TEST_DISCOVERY")
+	})
+
+	It("should cache the inventory of each image separately", func() {
+		cd := newCompositeDiscovery("test-discovery", []string{"registry/team1:latest", "registry/team2:latest"})
+		inventoryDir := filepath.Join(tmpDir, common.PluginInventoryDirName)
+		Expect(cd.discoveries[0].pluginDataDir).To(Equal(filepath.Join(inventoryDir, "test-discovery")))
+		Expect(cd.discoveries[1].pluginDataDir).To(Equal(filepath.Join(inventoryDir, "test-discovery+1")))
+
+		discoveryName, suffix := splitInventoryCacheName("test-discovery@STAGING+1")
+		Expect(discoveryName).To(Equal("test-discovery"))
+		Expect(suffix).To(Equal("@STAGING+1"))
+	})
+
+	It("should be created for an OCI discovery source with composite images", func() {
+		source := configtypes.PluginDiscovery{OCI: &configtypes.OCIDiscovery{Name: "test-discovery", Image: "registry/team1:latest"}}
+		os.Setenv(constants.ConfigVariablePluginDiscoveryCompositeImagesPrefix+"TEST_DISCOVERY", "registry/team2:latest, registry/team3:latest")
+		Expect(config.GetPluginDiscoveryCompositeImages("test-discovery")).To(HaveLen(2))
+
+		discovery, err := CreateDiscoveryFromV1alpha1(source)
+		Expect(err).To(BeNil())
+		cd, ok := discovery.(*CompositeDiscovery)
+		Expect(ok).To(BeTrue())
+		Expect(cd.images).To(Equal([]string{"registry/team1:latest", "registry/team2:latest", "registry/team3:latest"}))
+		Expect(cd.Type()).To(Equal(common.DiscoveryTypeOCI))
+		Expect(GetInventoryCacheDirs("test-discovery")).To(HaveLen(3))
+
+		groupDiscovery, err := CreateGroupDiscovery(source)
+		Expect(err).To(BeNil())
+		Expect(groupDiscovery).To(BeAssignableToTypeOf(&CompositeDiscovery{}))
+	})
+
+	It("should merge the plugins of all the images", func() {
+		plugins, err := newTestCompositeDiscovery().List()
+		Expect(err).To(BeNil())
+		Expect(plugins).To(HaveLen(3))
+		Expect(plugins[0].Name).To(Equal("apps"))
+		Expect(plugins[1].Name).To(Equal("cluster"))
+
+		login := plugins[2]
+		Expect(login.Name).To(Equal("login"))
+		Expect(login.Source).To(Equal("test-discovery"))
+		Expect(login.SupportedVersions).To(Equal([]string{"v0.1.0", "v0.2.0", "v0.3.0"}))
+		Expect(login.RecommendedVersion).To(Equal("v0.3.0"))
+
+		// The first image wins for a conflicting version
+		digest, err := login.Distribution.GetDigest("v0.2.0", "linux", "amd64")
+		Expect(err).To(BeNil())
+		Expect(digest).To(Equal("2222"))
+		digest, err = login.Distribution.GetDigest("v0.3.0", "linux", "amd64")
+		Expect(err).To(BeNil())
+		Expect(digest).To(Equal("4444"))
+
+		// The inventory entries are not modified by the merge
+		Expect(inventories[0].plugins[1].Artifacts).To(HaveLen(2))
+	})
+
+	It("should paginate the merged plugins", func() {
+		plugins, err := newTestCompositeDiscovery(WithPluginDiscoveryCriteria(&PluginDiscoveryCriteria{Limit: 1, Offset: 1})).List()
+		Expect(err).To(BeNil())
+		Expect(plugins).To(HaveLen(1))
+		Expect(plugins[0].Name).To(Equal("cluster"))
+
+		plugins, err = newTestCompositeDiscovery(WithPluginDiscoveryCriteria(&PluginDiscoveryCriteria{Offset: 3})).List()
+		Expect(err).To(BeNil())
+		Expect(plugins).To(BeEmpty())
+	})
+
+	It("should merge the plugin groups of all the images", func() {
+		groups, err := newTestCompositeDiscovery().GetGroups()
+		Expect(err).To(BeNil())
+		Expect(groups).To(HaveLen(1))
+		Expect(groups[0].RecommendedVersion).To(Equal("v2.0.0"))
+		Expect(groups[0].Versions).To(HaveLen(2))
+
+		// The first image wins for a conflicting version
+		Expect(groups[0].Versions["v1.0.0"][0].Name).To(Equal("cluster"))
+		Expect(inventories[0].groups[0].Versions).To(HaveLen(1))
+	})
+
+	It("should fail if the plugins of an image cannot be listed", func() {
+		cd := newTestCompositeDiscovery()
+		cd.discoveries[1].inventory = &stubInventory{}
+
+		_, err := cd.List()
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("registry/team2:latest"))
+	})
+})
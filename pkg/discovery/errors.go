@@ -0,0 +1,103 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/interrupt"
+)
+
+// DiscoveryErrorCode identifies the step of a plugin discovery that failed.
+// The values are stable and can be used to handle the errors programmatically.
+type DiscoveryErrorCode string
+
+const (
+	// DiscoveryErrorImageResolution is the failure to resolve the digest of the discovery image
+	DiscoveryErrorImageResolution DiscoveryErrorCode = "ImageResolution"
+	// DiscoveryErrorSignatureVerification is the failure to verify the signature of the discovery image
+	DiscoveryErrorSignatureVerification DiscoveryErrorCode = "SignatureVerification"
+	// DiscoveryErrorDigestChangeNotConfirmed is the refusal of the change of the digest of the discovery image
+	DiscoveryErrorDigestChangeNotConfirmed DiscoveryErrorCode = "DigestChangeNotConfirmed"
+	// DiscoveryErrorDownload is the failure to download the discovery image or to find its database
+	DiscoveryErrorDownload DiscoveryErrorCode = "Download"
+	// DiscoveryErrorMetadataUpdate is the failure to update the inventory database based on the
+	// inventory metadata image of an internet-restricted environment
+	DiscoveryErrorMetadataUpdate DiscoveryErrorCode = "MetadataUpdate"
+	// DiscoveryErrorCacheIO is the failure to read or write the cache of the discovery
+	DiscoveryErrorCacheIO DiscoveryErrorCode = "CacheIO"
+	// DiscoveryErrorCacheExpired is a cached inventory older than the maximum cache age
+	DiscoveryErrorCacheExpired DiscoveryErrorCode = "CacheExpired"
+	// DiscoveryErrorSQLiteCorrupt is a cached inventory database that cannot be queried
+	// because it is corrupt, even after fetching it again when possible
+	DiscoveryErrorSQLiteCorrupt DiscoveryErrorCode = "SQLiteCorrupt"
+	// DiscoveryErrorQuery is any other failure to query the cached inventory database
+	DiscoveryErrorQuery DiscoveryErrorCode = "Query"
+	// DiscoveryErrorInterrupted is the interruption of the CLI during the discovery
+	DiscoveryErrorInterrupted DiscoveryErrorCode = "Interrupted"
+)
+
+// DiscoveryError is the error returned by the methods of a discovery.  Its message is
+// the message of the wrapped cause.  The code can be checked with errors.As, or with
+// errors.Is and a DiscoveryError specifying the code, and optionally the discovery, to match:
+//
+//	errors.Is(err, &discovery.DiscoveryError{Code: discovery.DiscoveryErrorSignatureVerification})
+type DiscoveryError struct {
+	// Code identifies the step of the discovery that failed
+	Code DiscoveryErrorCode
+	// Discovery is the name of the discovery that failed
+	Discovery string
+	// Err is the cause of the error
+	Err error
+}
+
+// Error implements the error interface
+func (e *DiscoveryError) Error() string {
+	if e.Err == nil {
+		return string(e.Code)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the cause of the error
+func (e *DiscoveryError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true if the target is a DiscoveryError with the same code and,
+// if the target specifies one, the same discovery
+func (e *DiscoveryError) Is(target error) bool {
+	t, ok := target.(*DiscoveryError)
+	if !ok {
+		return false
+	}
+	return t.Code == e.Code && (t.Discovery == "" || t.Discovery == e.Discovery)
+}
+
+// GetDiscoveryErrorCode returns the code of the first DiscoveryError found in
+// the chain of the specified error, and false if there is none
+func GetDiscoveryErrorCode(err error) (DiscoveryErrorCode, bool) {
+	var de *DiscoveryError
+	if errors.As(err, &de) {
+		return de.Code, true
+	}
+	return "", false
+}
+
+// newDiscoveryError returns a DiscoveryError of the specified discovery with the
+// specified code, unless the error already is or wraps a DiscoveryError, in which
+// case it is returned as is so that the code of the step that failed is kept.
+// An interruption of the CLI always uses the DiscoveryErrorInterrupted code.
+func newDiscoveryError(code DiscoveryErrorCode, discoveryName string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, found := GetDiscoveryErrorCode(err); found {
+		return err
+	}
+	if errors.Is(err, interrupt.ErrInterrupted) {
+		code = DiscoveryErrorInterrupted
+	}
+	return &DiscoveryError{Code: code, Discovery: discoveryName, Err: err}
+}
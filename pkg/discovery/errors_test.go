@@ -0,0 +1,54 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/interrupt"
+)
+
+var _ = Describe("DiscoveryError", func() {
+	cause := errors.New("image not found")
+
+	It("should have the message of its cause and unwrap to it", func() {
+		err := newDiscoveryError(DiscoveryErrorDownload, "default", cause)
+		Expect(err.Error()).To(Equal("image not found"))
+		Expect(errors.Is(err, cause)).To(BeTrue())
+	})
+	It("should match a DiscoveryError with the same code and discovery", func() {
+		err := fmt.Errorf("wrapped: %w", newDiscoveryError(DiscoveryErrorDownload, "default", cause))
+		Expect(errors.Is(err, &DiscoveryError{Code: DiscoveryErrorDownload})).To(BeTrue())
+		Expect(errors.Is(err, &DiscoveryError{Code: DiscoveryErrorDownload, Discovery: "default"})).To(BeTrue())
+		Expect(errors.Is(err, &DiscoveryError{Code: DiscoveryErrorDownload, Discovery: "other"})).To(BeFalse())
+		Expect(errors.Is(err, &DiscoveryError{Code: DiscoveryErrorCacheIO})).To(BeFalse())
+
+		var discoveryErr *DiscoveryError
+		Expect(errors.As(err, &discoveryErr)).To(BeTrue())
+		Expect(discoveryErr.Code).To(Equal(DiscoveryErrorDownload))
+		Expect(discoveryErr.Discovery).To(Equal("default"))
+	})
+	It("should keep the code of the step that failed", func() {
+		err := newDiscoveryError(DiscoveryErrorMetadataUpdate, "default", cause)
+		err = newDiscoveryError(DiscoveryErrorDownload, "default", fmt.Errorf("download failed: %w", err))
+		code, found := GetDiscoveryErrorCode(err)
+		Expect(found).To(BeTrue())
+		Expect(code).To(Equal(DiscoveryErrorMetadataUpdate))
+	})
+	It("should use the interrupted code when the CLI is interrupted", func() {
+		err := newDiscoveryError(DiscoveryErrorDownload, "default", interrupt.ErrInterrupted)
+		code, _ := GetDiscoveryErrorCode(err)
+		Expect(code).To(Equal(DiscoveryErrorInterrupted))
+		Expect(errors.Is(err, interrupt.ErrInterrupted)).To(BeTrue())
+	})
+	It("should not return an error without a cause", func() {
+		Expect(newDiscoveryError(DiscoveryErrorDownload, "default", nil)).To(BeNil())
+		_, found := GetDiscoveryErrorCode(cause)
+		Expect(found).To(BeFalse())
+	})
+})
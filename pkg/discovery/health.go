@@ -0,0 +1,156 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper/sigverifier"
+	configlib "github.com/vmware-tanzu/tanzu-plugin-runtime/config"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+// The signature policies of a discovery source reported by HealthCheck
+const (
+	// SignaturePolicyVerify indicates that the signature of the discovery image is verified
+	SignaturePolicyVerify = "verify"
+	// SignaturePolicySkip indicates that the user chose to skip the verification
+	// of the signature of the discovery image
+	SignaturePolicySkip = "skip"
+)
+
+// SourceHealth is the health of a discovery source as reported by HealthCheck
+type SourceHealth struct {
+	// Name of the discovery source
+	Name string `json:"name" yaml:"name"`
+	// Image is the plugin inventory image of the discovery source
+	Image string `json:"image" yaml:"image"`
+	// Reachable tells whether the digest of the image could be resolved
+	Reachable bool `json:"reachable" yaml:"reachable"`
+	// Digest is the resolved digest of the image, empty if the image is not reachable
+	Digest string `json:"digest,omitempty" yaml:"digest,omitempty"`
+	// Cached tells whether the cache contains an inventory of the discovery source
+	Cached bool `json:"cached" yaml:"cached"`
+	// UpToDate tells whether the cached inventory matches the resolved digests
+	// of the image and of its metadata image
+	UpToDate bool `json:"upToDate" yaml:"upToDate"`
+	// CacheAge is the time since the cached inventory was last found up-to-date,
+	// zero if there is no cached inventory
+	CacheAge time.Duration `json:"cacheAge,omitempty" yaml:"cacheAge,omitempty"`
+	// SignaturePolicy is SignaturePolicyVerify or SignaturePolicySkip
+	SignaturePolicy string `json:"signaturePolicy" yaml:"signaturePolicy"`
+	// LastError is the error met while checking the discovery source, empty if there was none
+	LastError string `json:"lastError,omitempty" yaml:"lastError,omitempty"`
+}
+
+// Healthy returns true if no error was met while checking the discovery source
+func (h *SourceHealth) Healthy() bool {
+	return h.LastError == ""
+}
+
+// HealthCheck checks the health of the OCI discovery sources configured in the CLI,
+// without listing their plugins.  The digest of the inventory image of every source is
+// resolved and compared with the cached inventory, but nothing is downloaded and the
+// cache is left untouched, which makes it suitable for readiness probes.  The sources
+// are checked concurrently and those not checked before the context is done are reported
+// with the error of the context.  The other types of discovery sources are not reported.
+func HealthCheck(ctx context.Context) ([]SourceHealth, error) {
+	discoverySources, err := configlib.GetCLIDiscoverySources()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the configured discovery sources")
+	}
+	return healthCheck(ctx, discoverySources), nil
+}
+
+// healthCheck checks the health of the OCI discovery sources among the specified ones
+func healthCheck(ctx context.Context, discoverySources []configtypes.PluginDiscovery) []SourceHealth {
+	var discoveries []*DBBackedOCIDiscovery
+	for _, source := range discoverySources {
+		if source.OCI != nil {
+			discoveries = append(discoveries, newDBBackedOCIDiscovery(source.OCI.Name, source.OCI.Image))
+		}
+	}
+
+	type indexedHealth struct {
+		index  int
+		health SourceHealth
+	}
+	// The channel is large enough for the checks to never block
+	// when they complete after the context is done
+	results := make(chan indexedHealth, len(discoveries))
+	for i := range discoveries {
+		go func(index int, od *DBBackedOCIDiscovery) {
+			results <- indexedHealth{index: index, health: od.checkHealth()}
+		}(i, discoveries[i])
+	}
+
+	healths := make([]SourceHealth, len(discoveries))
+	done := make([]bool, len(discoveries))
+	for received := 0; received < len(discoveries); received++ {
+		select {
+		case result := <-results:
+			healths[result.index] = result.health
+			done[result.index] = true
+		case <-ctx.Done():
+			for i, od := range discoveries {
+				if !done[i] {
+					healths[i] = od.newSourceHealth()
+					healths[i].LastError = ctx.Err().Error()
+				}
+			}
+			return healths
+		}
+	}
+	return healths
+}
+
+// newSourceHealth returns the health of the discovery before it is checked
+func (od *DBBackedOCIDiscovery) newSourceHealth() SourceHealth {
+	health := SourceHealth{
+		Name:            od.Name(),
+		Image:           od.image,
+		SignaturePolicy: SignaturePolicyVerify,
+	}
+	if sigverifier.IsSignatureVerificationSkipped(od.image) {
+		health.SignaturePolicy = SignaturePolicySkip
+	}
+	return health
+}
+
+// checkHealth resolves the digests of the images of the discovery and compares them
+// with its cached inventory, without modifying the cache
+func (od *DBBackedOCIDiscovery) checkHealth() SourceHealth {
+	health := od.newSourceHealth()
+
+	hashFile := od.getCachedInventoryHashFile()
+	if hashFile != "" {
+		health.Cached = true
+		if info, err := os.Stat(hashFile); err == nil {
+			health.CacheAge = time.Since(info.ModTime())
+		}
+	}
+
+	hashHexValInventoryImage, hashHexValMetadataImage, err := od.resolveImageDigests()
+	if err != nil {
+		health.LastError = err.Error()
+		return health
+	}
+	health.Reachable = true
+	health.Digest = "sha256:" + hashHexValInventoryImage
+
+	if health.Cached {
+		if hashHexValMetadataImage == "" {
+			hashHexValMetadataImage = "none"
+		}
+		metadataHashFile := filepath.Join(od.pluginDataDir, "metadata.digest."+od.identityHash()+"."+hashHexValMetadataImage)
+		_, err := os.Stat(metadataHashFile)
+		health.UpToDate = getDigestFromHashFile(hashFile) == hashHexValInventoryImage && err == nil
+	}
+	return health
+}
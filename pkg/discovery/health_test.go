@@ -0,0 +1,160 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+// healthImageOperations resolves the digests of the images of a map;
+// the other images are unreachable.  Nothing can be downloaded.
+type healthImageOperations struct {
+	carvelhelpers.ImageOperationsImpl
+	digests map[string]string
+	// blocked is the image whose resolution does not complete until gate is closed
+	blocked string
+	gate    chan struct{}
+}
+
+func (h *healthImageOperations) GetImageDigest(imageWithTag string) (string, string, error) {
+	if imageWithTag == h.blocked {
+		<-h.gate
+	}
+	if digest, found := h.digests[imageWithTag]; found {
+		return "sha256:" + digest, digest, nil
+	}
+	return "", "", errors.Errorf("unable to reach %q", imageWithTag)
+}
+
+func (h *healthImageOperations) DownloadImageAndSaveFilesToDir(imageWithTag, destinationDir string) error {
+	return errors.New("a health check must not download images")
+}
+
+var _ = Describe("Health check of the discovery sources", func() {
+	var (
+		tmpDir       string
+		origCacheDir string
+		sources      []configtypes.PluginDiscovery
+		gate         chan struct{}
+	)
+
+	ociSource := func(name, image string) configtypes.PluginDiscovery {
+		return configtypes.PluginDiscovery{OCI: &configtypes.OCIDiscovery{Name: name, Image: image}}
+	}
+
+	// cacheInventory records the inventory of the discovery source
+	// as cached with the specified digest and no metadata image
+	cacheInventory := func(name, image, digest string) string {
+		od := newDBBackedOCIDiscovery(name, image)
+		Expect(os.MkdirAll(od.pluginDataDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(od.pluginDataDir, plugininventory.SQliteDBFileName), []byte("db"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(od.pluginDataDir, "digest."+od.identityHash()+"."+digest), nil, 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(od.pluginDataDir, "metadata.digest."+od.identityHash()+".none"), nil, 0644)).To(Succeed())
+		return od.pluginDataDir
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "cache")
+		Expect(err).To(BeNil())
+		origCacheDir = common.DefaultCacheDir
+		common.DefaultCacheDir = tmpDir
+
+		gate = make(chan struct{})
+		newImageOperations = func(options ...carvelhelpers.ImageOperationOption) carvelhelpers.ImageOperationsImpl {
+			return &healthImageOperations{
+				digests: map[string]string{"reachable:latest": "1234", "outdated:latest": "5678"},
+				blocked: "blocked:latest",
+				gate:    gate,
+			}
+		}
+		sources = []configtypes.PluginDiscovery{
+			ociSource("reachable", "reachable:latest"),
+			ociSource("unreachable", "unreachable:latest"),
+			ociSource("outdated", "outdated:latest"),
+			{Local: &configtypes.LocalDiscovery{Name: "local", Path: tmpDir}},
+		}
+	})
+	AfterEach(func() {
+		close(gate)
+		newImageOperations = carvelhelpers.NewImageOperationsImpl
+		common.DefaultCacheDir = origCacheDir
+		os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+		os.RemoveAll(tmpDir)
+	})
+
+	It("should report the health of each OCI discovery source", func() {
+		cacheInventory("reachable", "reachable:latest", "1234")
+		cacheInventory("outdated", "outdated:latest", "1234")
+		os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, "outdated:latest")
+
+		healths := healthCheck(context.Background(), sources)
+		Expect(healths).To(HaveLen(3))
+
+		Expect(healths[0].Name).To(Equal("reachable"))
+		Expect(healths[0].Healthy()).To(BeTrue())
+		Expect(healths[0].Reachable).To(BeTrue())
+		Expect(healths[0].Digest).To(Equal("sha256:1234"))
+		Expect(healths[0].Cached).To(BeTrue())
+		Expect(healths[0].UpToDate).To(BeTrue())
+		Expect(healths[0].CacheAge).To(BeNumerically(">", 0))
+		Expect(healths[0].SignaturePolicy).To(Equal(SignaturePolicyVerify))
+
+		Expect(healths[1].Name).To(Equal("unreachable"))
+		Expect(healths[1].Healthy()).To(BeFalse())
+		Expect(healths[1].Reachable).To(BeFalse())
+		Expect(healths[1].Digest).To(BeEmpty())
+		Expect(healths[1].Cached).To(BeFalse())
+		Expect(healths[1].LastError).To(ContainSubstring(`unable to reach "unreachable:latest"`))
+
+		Expect(healths[2].Name).To(Equal("outdated"))
+		Expect(healths[2].Healthy()).To(BeTrue())
+		Expect(healths[2].Cached).To(BeTrue())
+		Expect(healths[2].UpToDate).To(BeFalse())
+		Expect(healths[2].SignaturePolicy).To(Equal(SignaturePolicySkip))
+	})
+	It("should not modify the cache", func() {
+		dataDir := cacheInventory("outdated", "outdated:latest", "1234")
+		before, err := filepath.Glob(filepath.Join(dataDir, "*"))
+		Expect(err).To(BeNil())
+
+		healths := healthCheck(context.Background(), sources)
+		Expect(healths).To(HaveLen(3))
+
+		after, err := filepath.Glob(filepath.Join(dataDir, "*"))
+		Expect(err).To(BeNil())
+		Expect(after).To(Equal(before))
+
+		// No cache is created for the other discovery sources
+		caches, err := os.ReadDir(filepath.Join(tmpDir, common.PluginInventoryDirName))
+		Expect(err).To(BeNil())
+		Expect(caches).To(HaveLen(1))
+	})
+	It("should report the sources not checked before the context is done", func() {
+		sources = append(sources, ociSource("blocked", "blocked:latest"))
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		healths := healthCheck(ctx, sources)
+		Expect(healths).To(HaveLen(4))
+		Expect(healths[0].Healthy()).To(BeTrue())
+		Expect(healths[3].Name).To(Equal("blocked"))
+		Expect(healths[3].Healthy()).To(BeFalse())
+		Expect(healths[3].Reachable).To(BeFalse())
+		Expect(healths[3].LastError).To(Equal(context.DeadlineExceeded.Error()))
+	})
+})
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 )
@@ -38,11 +39,28 @@ type GroupDiscovery interface {
 	GetGroups() ([]*plugininventory.PluginGroup, error)
 }
 
+// RefreshableDiscovery is a discovery which caches its content locally
+type RefreshableDiscovery interface {
+	// Refresh updates the local cache of the content of the discovery
+	// if it is not up-to-date.
+	Refresh() error
+}
+
+// CountableDiscovery is a discovery which can count its plugins without listing them
+type CountableDiscovery interface {
+	// CountPlugins returns the number of available plugins matching the criteria
+	// of the discovery, ignoring the limit and offset of the criteria.
+	CountPlugins() (int, error)
+}
+
 // DiscoveryOpts used to customize the plugin discovery process or mechanism
 type DiscoveryOpts struct {
 	UseLocalCacheOnly       bool // UseLocalCacheOnly used to pull the plugin data from the cache
 	PluginDiscoveryCriteria *PluginDiscoveryCriteria
 	GroupDiscoveryCriteria  *GroupDiscoveryCriteria
+	// InventoryDBFileName is the name of the database file expected inside
+	// the OCI discovery image. Defaults to plugininventory.SQliteDBFileName.
+	InventoryDBFileName string
 }
 
 type DiscoveryOptions func(options *DiscoveryOpts)
@@ -65,6 +83,14 @@ func WithGroupDiscoveryCriteria(criteria *GroupDiscoveryCriteria) DiscoveryOptio
 	}
 }
 
+// WithInventoryDBFileName sets the name of the database file to look for
+// when extracting the plugin inventory from an OCI discovery image.
+func WithInventoryDBFileName(fileName string) DiscoveryOptions {
+	return func(o *DiscoveryOpts) {
+		o.InventoryDBFileName = fileName
+	}
+}
+
 func NewDiscoveryOpts() *DiscoveryOpts {
 	return &DiscoveryOpts{}
 }
@@ -82,6 +108,11 @@ type PluginDiscoveryCriteria struct {
 	OS string
 	// Arch of the plugin binary in `GOARCH` format.
 	Arch string
+	// Limit is the maximum number of plugins to discover; there is no limit when 0.
+	Limit int
+	// Offset is the number of plugins to skip, in the order of their name and target,
+	// before the discovered ones.
+	Offset int
 }
 
 // GroupDiscoveryCriteria provides criteria to look for
@@ -102,6 +133,9 @@ func CreateDiscoveryFromV1alpha1(pd configtypes.PluginDiscovery, options ...Disc
 	switch {
 	case pd.OCI != nil:
 		// Only the OCI Discovery currently supports a criteria
+		if images := config.GetPluginDiscoveryCompositeImages(pd.OCI.Name); len(images) > 0 {
+			return NewCompositeDiscovery(pd.OCI.Name, append([]string{pd.OCI.Image}, images...), options...), nil
+		}
 		return NewOCIDiscovery(pd.OCI.Name, pd.OCI.Image, options...), nil
 	case pd.Local != nil:
 		return NewLocalDiscovery(pd.Local.Name, pd.Local.Path), nil
@@ -115,6 +149,9 @@ func CreateDiscoveryFromV1alpha1(pd configtypes.PluginDiscovery, options ...Disc
 
 func CreateGroupDiscovery(pd configtypes.PluginDiscovery, options ...DiscoveryOptions) (GroupDiscovery, error) {
 	if pd.OCI != nil {
+		if images := config.GetPluginDiscoveryCompositeImages(pd.OCI.Name); len(images) > 0 {
+			return NewCompositeGroupDiscovery(pd.OCI.Name, append([]string{pd.OCI.Image}, images...), options...), nil
+		}
 		return NewOCIGroupDiscovery(pd.OCI.Name, pd.OCI.Image, options...), nil
 	}
 	return nil, errors.New("unknown group discovery source")
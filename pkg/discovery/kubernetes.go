@@ -11,6 +11,7 @@ import (
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 
 	cliv1alpha1 "github.com/vmware-tanzu/tanzu-cli/apis/cli/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cluster"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
@@ -82,7 +83,7 @@ func (k *KubernetesDiscovery) GetDiscoveredPlugins(clusterClient cluster.Client)
 
 	imageRepositoryOverride, err := clusterClient.GetCLIPluginImageRepositoryOverride()
 	if err != nil {
-		log.Infof("unable to get image repository override information for some of the plugins. Error: %v", err)
+		clilog.Infof("unable to get image repository override information for some of the plugins. Error: %v", err)
 	}
 
 	// Convert all CLIPlugin resources to Discovered object
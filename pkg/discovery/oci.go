@@ -8,9 +8,16 @@ import (
 	"path"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
 
 // NewOCIDiscovery returns a new Discovery using the specified OCI image.
@@ -24,6 +31,9 @@ func NewOCIDiscovery(name, image string, options ...DiscoveryOptions) Discovery
 	discovery := newDBBackedOCIDiscovery(name, image)
 	discovery.pluginCriteria = opts.PluginDiscoveryCriteria
 	discovery.useLocalCacheOnly = opts.UseLocalCacheOnly
+	if opts.InventoryDBFileName != "" {
+		discovery.inventoryDBFileName = opts.InventoryDBFileName
+	}
 	// NOTE: the use of TEST_TANZU_CLI_USE_DB_CACHE_ONLY is for testing only
 	if useCacheOnlyForTesting, _ := strconv.ParseBool(os.Getenv("TEST_TANZU_CLI_USE_DB_CACHE_ONLY")); useCacheOnlyForTesting {
 		discovery.useLocalCacheOnly = true
@@ -31,6 +41,26 @@ func NewOCIDiscovery(name, image string, options ...DiscoveryOptions) Discovery
 	return discovery
 }
 
+// NewInventoryDBDiscovery returns a new plugin Discovery which queries the plugin
+// inventory database file 'dbFile' directly instead of fetching it from an OCI image.
+func NewInventoryDBDiscovery(name, dbFile string, options ...DiscoveryOptions) Discovery {
+	// Initialize discovery options
+	opts := NewDiscoveryOpts()
+	for _, option := range options {
+		option(opts)
+	}
+
+	return &DBBackedOCIDiscovery{
+		name:           name,
+		pluginCriteria: opts.PluginDiscoveryCriteria,
+		// The inventory is never fetched
+		useLocalCacheOnly:   true,
+		pluginDataDir:       filepath.Dir(dbFile),
+		inventoryDBFileName: filepath.Base(dbFile),
+		inventory:           plugininventory.NewSQLiteInventory(dbFile, ""),
+	}
+}
+
 // NewOCIGroupDiscovery returns a new plugn group Discovery using the specified OCI image.
 func NewOCIGroupDiscovery(name, image string, options ...DiscoveryOptions) GroupDiscovery {
 	// Initialize discovery options
@@ -42,6 +72,9 @@ func NewOCIGroupDiscovery(name, image string, options ...DiscoveryOptions) Group
 	discovery := newDBBackedOCIDiscovery(name, image)
 	discovery.groupCriteria = opts.GroupDiscoveryCriteria
 	discovery.useLocalCacheOnly = opts.UseLocalCacheOnly
+	if opts.InventoryDBFileName != "" {
+		discovery.inventoryDBFileName = opts.InventoryDBFileName
+	}
 	// NOTE: the use of TEST_TANZU_CLI_USE_DB_CACHE_ONLY is for testing only
 	if useCacheOnlyForTesting, _ := strconv.ParseBool(os.Getenv("TEST_TANZU_CLI_USE_DB_CACHE_ONLY")); useCacheOnlyForTesting {
 		discovery.useLocalCacheOnly = true
@@ -57,13 +90,67 @@ func newDBBackedOCIDiscovery(name, image string) *DBBackedOCIDiscovery {
 	// then the image prefix should be project.registry.vmware.com/tanzu-cli/plugins/
 	imagePrefix := path.Dir(image)
 	// The data for the inventory is stored in the cache
-	pluginDataDir := filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName, name)
+	pluginDataDir := filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName, config.GetPluginInventoryCacheName(name))
 
 	inventory := plugininventory.NewSQLiteInventory(filepath.Join(pluginDataDir, plugininventory.SQliteDBFileName), imagePrefix)
 	return &DBBackedOCIDiscovery{
-		name:          name,
-		image:         image,
-		pluginDataDir: pluginDataDir,
-		inventory:     inventory,
+		name:                name,
+		image:               image,
+		pluginDataDir:       pluginDataDir,
+		inventory:           inventory,
+		inventoryDBFileName: config.GetPluginInventoryDBFileName(name),
+		credentials:         getRegistryCredentials(name, image),
+		maxCacheAge:         getMaxCacheAge(),
+		generationMarkerURL: os.Getenv(constants.ConfigVariablePluginDiscoveryGenerationMarkerPrefix + config.ToEnvVariableSuffix(name)),
+		backgroundRefresh:   isBackgroundRefreshEnabled(),
+	}
+}
+
+// getRegistryCredentials returns the registry credentials configured
+// for the specified discovery source, or nil if there are none.
+// Without credentials of its own, a discovery source uses the credentials
+// saved by 'tanzu plugin source login' for the registries of its images, if any.
+func getRegistryCredentials(discoveryName, image string) *carvelhelpers.RegistryCredentials {
+	suffix := config.ToEnvVariableSuffix(discoveryName)
+
+	credentials := &carvelhelpers.RegistryCredentials{
+		Username:         os.Getenv(constants.ConfigVariablePluginDiscoveryUsernamePrefix + suffix),
+		Password:         os.Getenv(constants.ConfigVariablePluginDiscoveryPasswordPrefix + suffix),
+		Token:            os.Getenv(constants.ConfigVariablePluginDiscoveryTokenPrefix + suffix),
+		CredentialHelper: os.Getenv(constants.ConfigVariablePluginDiscoveryCredentialHelperPrefix + suffix),
+	}
+	if credentials.IsEmpty() {
+		credentials.LoginCredentials = hasLoginCredentials(append([]string{image}, config.GetPluginDiscoveryCompositeImages(discoveryName)...))
+	}
+	if credentials.IsEmpty() {
+		return nil
+	}
+	return credentials
+}
+
+// hasLoginCredentials returns true if credentials were saved by
+// 'tanzu plugin source login' for the registry of any of the images
+func hasLoginCredentials(images []string) bool {
+	for _, image := range images {
+		registryHost, err := registry.GetRegistryName(image)
+		if err == nil && config.GetRegistryCredentialHelper(registryHost) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// getMaxCacheAge returns the maximum age allowed for the cached inventory
+// when only the cache is used, or 0 if there is no such limit.
+func getMaxCacheAge() time.Duration {
+	value := strings.TrimSpace(os.Getenv(constants.ConfigVariablePluginDiscoveryMaxCacheAge))
+	if value == "" {
+		return 0
+	}
+	maxAge, err := time.ParseDuration(value)
+	if err != nil || maxAge < 0 {
+		log.Warningf("Ignoring the invalid value %q of %s, a duration such as '720h' is expected", value, constants.ConfigVariablePluginDiscoveryMaxCacheAge)
+		return 0
 	}
+	return maxAge
 }
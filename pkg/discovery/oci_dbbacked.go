@@ -4,20 +4,35 @@
 package discovery
 
 import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/airgapped"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
-	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper/sigverifier"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/interrupt"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/component"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
 
@@ -45,6 +60,24 @@ type DBBackedOCIDiscovery struct {
 	pluginDataDir string
 	// inventory is the pluginInventory to be used by this discovery.
 	inventory plugininventory.PluginInventory
+	// inventoryDBFileName is the name of the database file expected
+	// inside the OCI image.  Once downloaded, the database is always stored
+	// in the cache as plugininventory.SQliteDBFileName.
+	inventoryDBFileName string
+	// credentials are used to access the images of the discovery.
+	// The registry is accessed anonymously when nil.
+	credentials *carvelhelpers.RegistryCredentials
+	// maxCacheAge is the maximum age of the cached inventory when useLocalCacheOnly is set.
+	// There is no limit when it is 0.
+	maxCacheAge time.Duration
+	// generationMarkerURL is the URL of a marker whose content changes every time
+	// the discovery image changes.  It is not used when empty.
+	generationMarkerURL string
+	// backgroundRefresh serves the queries from the cached inventory, if there is one,
+	// while the inventory image is fetched in the background for the next queries
+	backgroundRefresh bool
+	// inBackground is set on the copy of the discovery refreshing its inventory in the background
+	inBackground bool
 }
 
 func (od *DBBackedOCIDiscovery) getInventory() plugininventory.PluginInventory {
@@ -64,14 +97,14 @@ func (od *DBBackedOCIDiscovery) Type() string {
 // List is a method of the DBBackedOCIDiscovery struct that retrieves the available plugins.
 // It returns a slice of Discovered interfaces and an error if any occurs during the process.
 func (od *DBBackedOCIDiscovery) List() ([]Discovered, error) {
-	// If useLocalCacheOnly option is not set, fetch the inventory image
-	if !od.useLocalCacheOnly {
-		// Fetch the inventory image
-		err := od.fetchInventoryImage()
-		if err != nil {
-			// Return an error if unable to fetch the inventory image for plugins
-			return nil, errors.Wrapf(err, "unable to fetch the inventory of discovery '%s' for plugins", od.Name())
-		}
+	unlock, err := od.lockCache()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err = od.prepareInventory("plugins"); err != nil {
+		return nil, err
 	}
 
 	// List and return the plugins from the inventory
@@ -81,61 +114,130 @@ func (od *DBBackedOCIDiscovery) List() ([]Discovered, error) {
 // GetGroups is a method of the DBBackedOCIDiscovery struct that retrieves the plugin groups defined in the discovery.
 // It returns a slice of PluginGroup pointers and an error if any occurs during the process.
 func (od *DBBackedOCIDiscovery) GetGroups() ([]*plugininventory.PluginGroup, error) {
-	// If useLocalCacheOnly option is not set, fetch the inventory image
-	if !od.useLocalCacheOnly {
-		// Fetch the inventory image
-		err := od.fetchInventoryImage()
-		if err != nil {
-			// Return an error if unable to fetch the inventory image for groups
-			return nil, errors.Wrapf(err, "unable to fetch the inventory of discovery '%s' for groups", od.Name())
-		}
+	unlock, err := od.lockCache()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err = od.prepareInventory("groups"); err != nil {
+		return nil, err
 	}
 
 	// List and return the groups from the inventory
 	return od.listGroupsFromInventory()
 }
 
-func (od *DBBackedOCIDiscovery) listPluginsFromInventory() ([]Discovered, error) {
-	var pluginEntries []*plugininventory.PluginInventoryEntry
-	var err error
+// CountPlugins returns the number of plugins of the discovery matching its criteria,
+// ignoring the limit and offset of the criteria.
+func (od *DBBackedOCIDiscovery) CountPlugins() (int, error) {
+	unlock, err := od.lockCache()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	if err = od.prepareInventory("plugins"); err != nil {
+		return 0, err
+	}
+
+	count, err := od.getInventory().CountPlugins(od.getPluginInventoryFilter())
+	if err != nil {
+		return 0, od.queryError(err)
+	}
+	return count, nil
+}
 
+// prepareInventory makes sure the cached inventory can be queried for the specified
+// content.  The inventory image is fetched unless the discovery must only use the local
+// cache, in which case the age of the cache is checked instead.  With the background
+// refresh, a cached inventory is used as is while it is refreshed for the next queries.
+func (od *DBBackedOCIDiscovery) prepareInventory(content string) error {
+	if od.useLocalCacheOnly {
+		return newDiscoveryError(DiscoveryErrorCacheExpired, od.Name(), od.checkCacheAge())
+	}
+	if od.backgroundRefresh && od.getCachedInventoryHashFile() != "" {
+		od.refreshInBackground()
+		return nil
+	}
+	if err := od.fetchInventoryImage(); err != nil {
+		return errors.Wrapf(err, "unable to fetch the inventory of discovery '%s' for %s", od.Name(), content)
+	}
+	return nil
+}
+
+// getPluginInventoryFilter converts the plugin criteria of the discovery into an inventory filter
+func (od *DBBackedOCIDiscovery) getPluginInventoryFilter() *plugininventory.PluginInventoryFilter {
 	shouldIncludeHidden, _ := strconv.ParseBool(os.Getenv(constants.ConfigVariableIncludeDeactivatedPluginsForTesting))
+	fallbackToAvailableVersion := config.IsPluginRecommendedVersionFallbackEnabled(od.isAirgappedInventory())
 	if od.pluginCriteria == nil {
-		pluginEntries, err = od.getInventory().GetPlugins(&plugininventory.PluginInventoryFilter{
-			IncludeHidden: shouldIncludeHidden,
-		})
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		pluginEntries, err = od.getInventory().GetPlugins(&plugininventory.PluginInventoryFilter{
-			Name:          od.pluginCriteria.Name,
-			Target:        od.pluginCriteria.Target,
-			Version:       od.pluginCriteria.Version,
-			OS:            od.pluginCriteria.OS,
-			Arch:          od.pluginCriteria.Arch,
-			IncludeHidden: shouldIncludeHidden,
-		})
-		if err != nil {
-			return nil, err
+		return &plugininventory.PluginInventoryFilter{
+			IncludeHidden:              shouldIncludeHidden,
+			FallbackToAvailableVersion: fallbackToAvailableVersion,
 		}
 	}
+	return &plugininventory.PluginInventoryFilter{
+		Name:                       od.pluginCriteria.Name,
+		Target:                     od.pluginCriteria.Target,
+		Version:                    od.pluginCriteria.Version,
+		OS:                         od.pluginCriteria.OS,
+		Arch:                       od.pluginCriteria.Arch,
+		IncludeHidden:              shouldIncludeHidden,
+		Limit:                      od.pluginCriteria.Limit,
+		Offset:                     od.pluginCriteria.Offset,
+		FallbackToAvailableVersion: fallbackToAvailableVersion,
+	}
+}
 
-	var discoveredPlugins []Discovered
-	for _, entry := range pluginEntries {
+// isAirgappedInventory returns true if the cached inventory was updated based on a plugin
+// inventory metadata image, which is the case of the inventory of an air-gapped repository
+// only containing the mirrored plugins
+func (od *DBBackedOCIDiscovery) isAirgappedInventory() bool {
+	matches, _ := filepath.Glob(filepath.Join(od.pluginDataDir, "metadata.digest."+od.identityHash()+".*"))
+	return len(matches) == 1 && getDigestFromHashFile(matches[0]) != "none"
+}
+
+func (od *DBBackedOCIDiscovery) listPluginsFromInventory() ([]Discovered, error) {
+	pluginEntries, err := od.queryPlugins(od.getPluginInventoryFilter())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pluginEntries) == 0 {
+		return nil, nil
+	}
+
+	// The slices are allocated with their final size as large inventories
+	// contain thousands of plugins and versions
+	discoveredPlugins := make([]Discovered, len(pluginEntries))
+	for i, entry := range pluginEntries {
 		// First build the sorted list of versions from the Artifacts map
 		var versions []string
+		if len(entry.Artifacts) > 0 {
+			versions = make([]string, 0, len(entry.Artifacts))
+		}
 		for v := range entry.Artifacts {
 			versions = append(versions, v)
 		}
 		if err := utils.SortVersions(versions); err != nil {
 			fmt.Fprintf(os.Stderr, "error parsing versions for plugin %s: %v\n", entry.Name, err)
 		}
+		if entry.UnavailableRecommendedVersion != "" {
+			// Only warn when a specific plugin is looked up, e.g., to install it, as
+			// listing all the plugins of a partial mirror would warn about many of them
+			warn := log.V(4).Warningf
+			if od.pluginCriteria != nil && od.pluginCriteria.Name != "" {
+				warn = log.Warningf
+			}
+			warn("The version %s recommended by the publisher of plugin '%s' for target '%s' is not available in discovery '%s', using the highest available version %s instead",
+				entry.UnavailableRecommendedVersion, entry.Name, entry.Target, od.Name(), entry.RecommendedVersion)
+		}
 
-		plugin := Discovered{
+		// Fill the entry in place rather than copying a temporary Discovered
+		discoveredPlugins[i] = Discovered{
 			Name:               entry.Name,
 			Description:        entry.Description,
-			RecommendedVersion: entry.RecommendedVersion,
+			RecommendedVersion: config.SelectRecommendedVersion(entry.RecommendedVersion, versions),
 			InstalledVersion:   "", // Not set when discovered, but later.
 			SupportedVersions:  versions,
 			Distribution:       entry.Artifacts,
@@ -145,74 +247,498 @@ func (od *DBBackedOCIDiscovery) listPluginsFromInventory() ([]Discovered, error)
 			ContextName:        "", // Not set when discovered.
 			DiscoveryType:      common.DiscoveryTypeOCI,
 			Target:             entry.Target,
+			Vendor:             entry.Vendor,
+			Publisher:          entry.Publisher,
 			Status:             common.PluginStatusNotInstalled, // Not set yet
+			Dependencies:       entry.Dependencies,
+			Deprecated:         entry.Deprecated,
+			DeprecationMessage: entry.DeprecationMessage,
+			DeprecatedVersions: entry.DeprecatedVersions,
+			MinCLIVersions:     entry.MinCLIVersions,
+			HomepageURL:        entry.HomepageURL,
+			DocumentationURL:   entry.DocumentationURL,
+			IssueTrackerURL:    entry.IssueTrackerURL,
 		}
-		discoveredPlugins = append(discoveredPlugins, plugin)
 	}
 	return discoveredPlugins, nil
 }
 
+// queryPlugins returns the plugins of the inventory matching the filter.  The result
+// of a query is cached so that the same query of the same inventory does not need
+// to run against the database again.
+func (od *DBBackedOCIDiscovery) queryPlugins(filter *plugininventory.PluginInventoryFilter) ([]*plugininventory.PluginInventoryEntry, error) {
+	if pluginEntries, found := od.getCachedQueryResult(filter); found {
+		return pluginEntries, nil
+	}
+
+	pluginEntries, err := od.getInventory().GetPlugins(filter)
+	if err != nil && od.isCorruptCache(err) {
+		// Fetch the inventory again and retry the query only once
+		if err = od.refetchCorruptInventory(err); err == nil {
+			pluginEntries, err = od.getInventory().GetPlugins(filter)
+		}
+	}
+	if err != nil {
+		return nil, od.queryError(err)
+	}
+	od.saveQueryResult(filter, pluginEntries)
+	return pluginEntries, nil
+}
+
 func (od *DBBackedOCIDiscovery) listGroupsFromInventory() ([]*plugininventory.PluginGroup, error) {
 	shouldIncludeHidden, _ := strconv.ParseBool(os.Getenv(constants.ConfigVariableIncludeDeactivatedPluginsForTesting))
 
-	if od.groupCriteria == nil {
-		return od.getInventory().GetPluginGroups(plugininventory.PluginGroupFilter{
-			IncludeHidden: shouldIncludeHidden,
-		})
+	filter := plugininventory.PluginGroupFilter{
+		IncludeHidden: shouldIncludeHidden,
+	}
+	if od.groupCriteria != nil {
+		filter.Vendor = od.groupCriteria.Vendor
+		filter.Publisher = od.groupCriteria.Publisher
+		filter.Name = od.groupCriteria.Name
+		filter.Version = od.groupCriteria.Version
 	}
 
-	return od.getInventory().GetPluginGroups(plugininventory.PluginGroupFilter{
-		Vendor:        od.groupCriteria.Vendor,
-		Publisher:     od.groupCriteria.Publisher,
-		Name:          od.groupCriteria.Name,
-		Version:       od.groupCriteria.Version,
-		IncludeHidden: shouldIncludeHidden,
-	})
+	groups, err := od.getInventory().GetPluginGroups(filter)
+	if err != nil && od.isCorruptCache(err) {
+		// Fetch the inventory again and retry the query only once
+		if err = od.refetchCorruptInventory(err); err == nil {
+			groups, err = od.getInventory().GetPluginGroups(filter)
+		}
+	}
+	if err != nil {
+		return nil, od.queryError(err)
+	}
+	return groups, nil
+}
+
+// corruptDatabaseErrors are the messages of the SQLite errors indicating
+// that the database file cannot be used as a plugin inventory
+var corruptDatabaseErrors = []string{
+	"file is not a database",
+	"database disk image is malformed",
+	"no such table",
+	"failed to open the DB",
+}
+
+// isCorruptCache returns true if the error of a query of the cached inventory
+// indicates that the cached database is corrupt and should be fetched again.
+// The cache cannot be fetched again if the discovery must only use the local cache.
+func (od *DBBackedOCIDiscovery) isCorruptCache(err error) bool {
+	return !od.useLocalCacheOnly && isCorruptDatabaseError(err)
+}
+
+// isCorruptDatabaseError returns true if the error of a query of an inventory
+// database indicates that the database is corrupt
+func isCorruptDatabaseError(err error) bool {
+	for _, msg := range corruptDatabaseErrors {
+		if strings.Contains(err.Error(), msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryError classifies the error of a query of the cached inventory
+func (od *DBBackedOCIDiscovery) queryError(err error) error {
+	if isCorruptDatabaseError(err) {
+		return newDiscoveryError(DiscoveryErrorSQLiteCorrupt, od.Name(), err)
+	}
+	return newDiscoveryError(DiscoveryErrorQuery, od.Name(), err)
+}
+
+// refetchCorruptInventory invalidates the cached inventory after a query failed
+// with the specified error and fetches the inventory image again.
+func (od *DBBackedOCIDiscovery) refetchCorruptInventory(queryErr error) error {
+	log.Warningf("The cached plugin inventory of discovery '%s' is corrupt, fetching it again: %v", od.Name(), queryErr)
+	od.invalidateCache()
+	if err := od.fetchInventoryImage(); err != nil {
+		return errors.Wrapf(err, "unable to fetch the inventory of discovery '%s' again after the cached inventory was found corrupt", od.Name())
+	}
+	return nil
+}
+
+// invalidateCache removes the cached inventory database of the discovery along with
+// its retained copy, digest files, generation marker and cached query results, so
+// that the inventory image gets downloaded the next time it is fetched
+func (od *DBBackedOCIDiscovery) invalidateCache() {
+	od.logCache(cacheLogLevel, "invalidating the cached inventory")
+	// The retained copy of a corrupt inventory must not be restored either
+	if hashFile := od.getCachedInventoryHashFile(); hashFile != "" {
+		od.forgetRetainedInventory(getDigestFromHashFile(hashFile))
+	}
+	_ = os.Remove(filepath.Join(od.pluginDataDir, plugininventory.SQliteDBFileName))
+	for _, pattern := range []string{"digest.*", "metadata.digest.*"} {
+		matches, _ := filepath.Glob(filepath.Join(od.pluginDataDir, pattern))
+		for _, filePath := range matches {
+			_ = os.Remove(filePath)
+		}
+	}
+	_ = os.Remove(od.getGenerationFile())
+	od.invalidateQueryCache()
+}
+
+// Refresh downloads the inventory image of the discovery into the cache
+// unless the cached inventory is already up-to-date.  Nothing is done if
+// the discovery must only use the local cache.
+func (od *DBBackedOCIDiscovery) Refresh() error {
+	if od.useLocalCacheOnly {
+		return nil
+	}
+	unlock, err := od.lockCache()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return od.fetchInventoryImage()
+}
+
+// lockCache locks the cache directory of the discovery for the time its inventory is
+// fetched and queried, so that it is not modified or evicted concurrently
+func (od *DBBackedOCIDiscovery) lockCache() (func(), error) {
+	return lockInventoryCache(od.pluginDataDir)
 }
 
 // fetchInventoryImage downloads the OCI image containing the information about the
 // inventory of this discovery and stores it in the cache directory.
 func (od *DBBackedOCIDiscovery) fetchInventoryImage() error {
+	// The digest file of the cached inventory is read before checkImageCache(),
+	// which removes it if the inventory it describes is missing
+	oldHashFileForInventoryImage := od.getCachedInventoryHashFile()
+
+	// An unchanged generation marker avoids resolving the digests of the images
+	generation, upToDate := od.checkGenerationMarker(oldHashFileForInventoryImage)
+	if upToDate {
+		od.logCache(cacheLogLevel, "the generation marker %q is unchanged, reusing the cached inventory", generation)
+		return nil
+	}
+
 	// check the cache to see if downloaded plugin inventory database is up-to-date or not
 	// by comparing the image digests
 	newCacheHashFileForInventoryImage, newCacheHashFileForMetadataImage, err := od.checkImageCache()
 	if err != nil {
-		return err
+		return newDiscoveryError(DiscoveryErrorImageResolution, od.Name(), err)
 	}
 
 	if newCacheHashFileForInventoryImage == "" && newCacheHashFileForMetadataImage == "" {
 		// The cache can be re-used. We are done.
+		od.logCache(cacheLogLevel, "the digests of the images are unchanged, reusing the cached inventory")
+		od.saveGeneration(generation)
+		return nil
+	}
+
+	if err := od.confirmDigestChange(oldHashFileForInventoryImage, newCacheHashFileForInventoryImage); err != nil {
+		// The cached inventory, whose digest file was kept, is used until the change is confirmed
+		return newDiscoveryError(DiscoveryErrorDigestChangeNotConfirmed, od.Name(), err)
+	}
+
+	inventoryImageHashFile := newCacheHashFileForInventoryImage
+	if inventoryImageHashFile == "" {
+		inventoryImageHashFile = oldHashFileForInventoryImage
+	}
+	metadataImageHashFile := newCacheHashFileForMetadataImage
+	if metadataImageHashFile == "" {
+		if matches, _ := filepath.Glob(filepath.Join(od.pluginDataDir, "metadata.digest."+od.identityHash()+".*")); len(matches) == 1 {
+			metadataImageHashFile = matches[0]
+		}
+	}
+	inventoryDigest := getDigestFromHashFile(inventoryImageHashFile)
+	metadataDigest := getDigestFromHashFile(metadataImageHashFile)
+
+	// Switching back to a recently used image does not require downloading it again
+	restored := false
+	if err := od.updateCache(func() error {
+		if restored = od.restoreRetainedInventory(inventoryDigest, metadataDigest); restored {
+			od.createHashFiles(newCacheHashFileForInventoryImage, newCacheHashFileForMetadataImage)
+			od.saveGeneration(generation)
+		}
 		return nil
+	}); err != nil || restored {
+		return err
 	}
 
 	// The DB has changed and needs to be updated in the cache.
-	log.Infof("Reading plugin inventory for %q, this will take a few seconds.", od.image)
+	od.logCache(cacheLogLevel, "downloading the inventory of digest sha256:%s to refresh the cached inventory", inventoryDigest)
+	if od.inBackground {
+		log.V(4).Infof("Reading plugin inventory for %q in the background.", od.image)
+	} else {
+		clilog.Infof("Reading plugin inventory for %q, this will take a few seconds.", od.image)
+	}
 
 	// Verify the inventory image signature before downloading the plugin inventory database
-	err = sigverifier.VerifyInventoryImageSignature(od.image)
+	err = od.verifySignature(inventoryDigest)
 	if err != nil {
-		return err
+		return newDiscoveryError(DiscoveryErrorSignatureVerification, od.Name(), err)
 	}
 
 	// download plugin inventory image to get the 'plugin_inventory.db'
 	// also handle the air-gapped scenario where additional plugin inventory metadata image is present
 	err = od.downloadInventoryDatabase()
 	if err != nil {
+		return newDiscoveryError(DiscoveryErrorDownload, od.Name(), err)
+	}
+
+	// Never mark an inventory as up-to-date once the CLI is interrupted,
+	// as the cleanup may have removed what was downloaded
+	if interrupt.Interrupted() {
+		return newDiscoveryError(DiscoveryErrorInterrupted, od.Name(), interrupt.ErrInterrupted)
+	}
+
+	// Now that everything is ready, create the digest hash files and keep the new
+	// inventory around in case the image changes back to it later
+	if err := od.updateCache(func() error {
+		od.createHashFiles(newCacheHashFileForInventoryImage, newCacheHashFileForMetadataImage)
+		od.saveGeneration(generation)
+		od.retainInventory(inventoryDigest, metadataDigest)
+		return nil
+	}); err != nil {
 		return err
 	}
 
-	// Now that everything is ready, create the digest hash file
-	if newCacheHashFileForInventoryImage != "" {
-		_, _ = os.Create(newCacheHashFileForInventoryImage)
+	// The cache has grown, make sure it remains within its maximum size
+	od.pruneInventoryCache()
+
+	return nil
+}
+
+// createHashFiles creates the digest files of the inventory image and of its
+// metadata image which are not empty, once the cached inventory is ready.
+// Each replaces the digest file of the previous inventory, if any.
+func (od *DBBackedOCIDiscovery) createHashFiles(hashFileForInventoryImage, hashFileForMetadataImage string) {
+	for _, hashFile := range []string{hashFileForInventoryImage, hashFileForMetadataImage} {
+		if hashFile != "" {
+			digestPrefix := filepath.Base(hashFile)[:strings.Index(filepath.Base(hashFile), "digest.")]
+			matches, _ := filepath.Glob(filepath.Join(od.pluginDataDir, digestPrefix+"digest.*"))
+			for _, oldHashFile := range matches {
+				if oldHashFile != hashFile {
+					os.Remove(oldHashFile)
+					od.logCache(cacheDetailLogLevel, "removed the digest file %s", filepath.Base(oldHashFile))
+				}
+			}
+			_, _ = os.Create(hashFile)
+			od.logCache(cacheDetailLogLevel, "created the digest file %s", filepath.Base(hashFile))
+		}
+	}
+}
+
+// The verbosity levels of the logs describing how the cached inventory is managed
+const (
+	// cacheLogLevel is the level of the decisions to reuse or to refresh the cache
+	cacheLogLevel = 4
+	// cacheDetailLogLevel is the level of the files of the cache being created or removed
+	cacheDetailLogLevel = 6
+)
+
+// logCache logs how the cached inventory is managed at the specified verbosity level,
+// along with the name and the image of the discovery
+func (od *DBBackedOCIDiscovery) logCache(level int, format string, args ...interface{}) {
+	log.V(level).Infof("Plugin inventory cache of discovery '%s' (%s): %s", od.Name(), od.image, fmt.Sprintf(format, args...))
+}
+
+// getCachedInventoryHashFile returns the path of the digest file of the inventory
+// of this discovery in the cache, or an empty string if the cache does not contain
+// an inventory of this discovery
+func (od *DBBackedOCIDiscovery) getCachedInventoryHashFile() string {
+	if _, err := os.Stat(filepath.Join(od.pluginDataDir, plugininventory.SQliteDBFileName)); err != nil {
+		return ""
+	}
+	matches, _ := filepath.Glob(filepath.Join(od.pluginDataDir, "digest."+od.identityHash()+".*"))
+	if len(matches) != 1 {
+		return ""
+	}
+	return matches[0]
+}
+
+// fetchGenerationMarker returns the content of the generation marker at the specified URL.
+// It is a variable so that it can be replaced by tests.
+var fetchGenerationMarker = fetchGenerationMarkerFromURL
+
+// maxGenerationMarkerSize is the maximum number of bytes of a generation marker that are read
+const maxGenerationMarkerSize = 1024
+
+// fetchGenerationMarkerFromURL reads the generation marker at the specified URL with the
+// certificate and proxy configuration used to access a registry of the same host
+func fetchGenerationMarkerFromURL(markerURL string) (string, error) {
+	u, err := url.Parse(markerURL)
+	if err != nil {
+		return "", err
+	}
+	client, err := registry.NewHTTPClient(u.Host)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", markerURL, http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", errors.Errorf("unexpected status code %d", res.StatusCode)
+	}
+	content, err := io.ReadAll(io.LimitReader(res.Body, maxGenerationMarkerSize))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// getGenerationFile returns the path of the file of the cache storing the
+// generation marker of the discovery image the cached inventory was fetched from
+func (od *DBBackedOCIDiscovery) getGenerationFile() string {
+	return filepath.Join(od.pluginDataDir, "generation."+od.identityHash())
+}
+
+// checkGenerationMarker returns the current generation marker of the discovery image,
+// and whether it is the same as the one of the cached inventory, in which case the cache
+// is up-to-date without resolving the digests of the images.  An empty generation is
+// returned when no marker is configured or when the marker cannot be read; the digests
+// of the images must then be resolved as usual.
+func (od *DBBackedOCIDiscovery) checkGenerationMarker(cachedHashFile string) (string, bool) {
+	if od.generationMarkerURL == "" {
+		return "", false
+	}
+	generation, err := fetchGenerationMarker(od.generationMarkerURL)
+	if err == nil && generation == "" {
+		err = errors.New("the marker is empty")
+	}
+	if err != nil {
+		od.logCache(cacheLogLevel, "unable to read the generation marker %q, resolving the digest of the image instead: %v", od.generationMarkerURL, err)
+		return "", false
+	}
+	od.logCache(cacheDetailLogLevel, "read the generation marker %q from %q", generation, od.generationMarkerURL)
+
+	// The marker is only meaningful if the cache holds the inventory of this discovery
+	if cachedHashFile == "" {
+		od.logCache(cacheLogLevel, "no cached inventory to compare the generation marker with")
+		return generation, false
+	}
+	cachedGeneration, err := os.ReadFile(od.getGenerationFile())
+	if err != nil {
+		od.logCache(cacheLogLevel, "no generation marker recorded for the cached inventory")
+		return generation, false
+	}
+	if string(cachedGeneration) != generation {
+		od.logCache(cacheLogLevel, "the generation marker changed from %q to %q", string(cachedGeneration), generation)
+		return generation, false
+	}
+
+	// Record that the cache was just found up-to-date; this is what checkCacheAge() relies on.
+	now := time.Now()
+	_ = os.Chtimes(cachedHashFile, now, now)
+	return generation, true
+}
+
+// saveGeneration stores the generation marker of the discovery image the
+// cached inventory was fetched from.  Nothing is stored for an empty generation
+// and any previously stored generation is removed so that the digests
+// of the images get resolved the next time.
+func (od *DBBackedOCIDiscovery) saveGeneration(generation string) {
+	if generation == "" {
+		if err := os.Remove(od.getGenerationFile()); err == nil {
+			od.logCache(cacheDetailLogLevel, "removed the generation file %s", filepath.Base(od.getGenerationFile()))
+		}
+		return
+	}
+	if err := os.WriteFile(od.getGenerationFile(), []byte(generation), 0644); err == nil {
+		od.logCache(cacheDetailLogLevel, "recorded the generation marker %q in %s", generation, filepath.Base(od.getGenerationFile()))
+	}
+}
+
+// confirmDigestChange asks the user to confirm the download of the inventory image
+// when its digest has changed since the cached inventory was fetched.  There is
+// nothing to confirm unless the confirmation is enabled through the
+// TANZU_CLI_CONFIRM_DISCOVERY_IMAGE_DIGEST_CHANGE variable.
+func (od *DBBackedOCIDiscovery) confirmDigestChange(oldHashFile, newHashFile string) error {
+	if confirm, _ := strconv.ParseBool(os.Getenv(constants.ConfigVariableConfirmDiscoveryDigestChange)); !confirm {
+		return nil
+	}
+	if oldHashFile == "" || newHashFile == "" || oldHashFile == newHashFile {
+		return nil
 	}
-	// Also create digest hash file for inventory metadata image if not empty
-	if newCacheHashFileForMetadataImage != "" {
-		_, _ = os.Create(newCacheHashFileForMetadataImage)
+
+	oldDigest := getDigestFromHashFile(oldHashFile)
+	newDigest := getDigestFromHashFile(newHashFile)
+	if od.inBackground {
+		// Never prompt from a background refresh
+		return errors.Errorf("the change of the digest of the plugin inventory image %q from sha256:%s to sha256:%s must be confirmed by refreshing the inventory in the foreground", od.image, oldDigest, newDigest)
 	}
+	msg := fmt.Sprintf("The digest of the plugin inventory image %q of discovery '%s' has changed from sha256:%s to sha256:%s since it was last fetched. Do you want to download and trust the new plugin inventory?",
+		od.image, od.Name(), oldDigest, newDigest)
 
+	var accepted bool
+	if answer := os.Getenv(constants.DiscoveryDigestChangePromptAnswer); answer != "" {
+		accepted = strings.EqualFold(answer, "Yes")
+	} else {
+		var err error
+		if accepted, err = promptForDigestChangeSerially(msg); err != nil {
+			return errors.Wrapf(err, "prompt failed")
+		}
+	}
+	if !accepted {
+		return errors.Errorf("the change of the digest of the plugin inventory image %q from sha256:%s to sha256:%s was not confirmed", od.image, oldDigest, newDigest)
+	}
 	return nil
 }
 
+// getDigestFromHashFile returns the digest encoded in the name of a digest file
+func getDigestFromHashFile(hashFile string) string {
+	name := filepath.Base(hashFile)
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// promptMutex prevents the prompts of discoveries that are listed concurrently from interleaving
+var promptMutex sync.Mutex
+
+// promptForDigestChangeSerially prompts for the change of a digest, waiting for any other
+// prompt of a discovery to be answered first
+func promptForDigestChangeSerially(msg string) (bool, error) {
+	promptMutex.Lock()
+	defer promptMutex.Unlock()
+	return promptForDigestChange(msg)
+}
+
+// promptForDigestChange asks the user to answer the specified question with yes or no;
+// it is a variable so that tests can replace it
+var promptForDigestChange = func(msg string) (bool, error) {
+	var answer string
+	err := component.Prompt(
+		&component.PromptConfig{
+			Message: msg,
+			Options: []string{"Yes", "No"},
+			Default: "No",
+		},
+		&answer,
+	)
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(answer, "Yes"), nil
+}
+
+// pruneInventoryCache evicts the least recently used inventories of other discoveries
+// from the cache if it exceeds its configured maximum size.
+func (od *DBBackedOCIDiscovery) pruneInventoryCache() {
+	maxSize, found := GetMaxCacheSize()
+	if !found {
+		return
+	}
+	evicted, err := PruneInventoryCache(maxSize, od.pluginDataDir)
+	if err != nil {
+		log.Warningf("Unable to prune the plugin inventory cache: %v", err)
+	}
+	if len(evicted) > 0 {
+		od.logCache(cacheLogLevel, "evicted the cached inventories %s to remain within %d bytes", strings.Join(evicted, ", "), maxSize)
+	}
+}
+
 // downloadInventoryDatabase downloads plugin inventory image to get the 'plugin_inventory.db'
 //
 // Additional check for airgapped environment as below:
@@ -222,36 +748,189 @@ func (od *DBBackedOCIDiscovery) fetchInventoryImage() error {
 func (od *DBBackedOCIDiscovery) downloadInventoryDatabase() error {
 	tempDir1, err := os.MkdirTemp("", "")
 	if err != nil {
-		return errors.Wrap(err, "unable to create temp directory")
+		return newDiscoveryError(DiscoveryErrorCacheIO, od.Name(), errors.Wrap(err, "unable to create temp directory"))
 	}
+	defer os.RemoveAll(tempDir1)
 	tempDir2, err := os.MkdirTemp("", "")
 	if err != nil {
-		return errors.Wrap(err, "unable to create temp directory")
+		return newDiscoveryError(DiscoveryErrorCacheIO, od.Name(), errors.Wrap(err, "unable to create temp directory"))
 	}
-	defer os.RemoveAll(tempDir1)
 	defer os.RemoveAll(tempDir2)
 
+	// The temporary directories must also be removed if the CLI is interrupted during the download
+	unregisterCleanup := interrupt.RegisterCleanup(func() {
+		os.RemoveAll(tempDir1)
+		os.RemoveAll(tempDir2)
+	})
+	defer unregisterCleanup()
+
 	// Download the plugin inventory image and save to tempDir1
-	if err := carvelhelpers.DownloadImageAndSaveFilesToDir(od.image, tempDir1); err != nil {
-		return errors.Wrapf(err, "failed to download OCI image from discovery '%s'", od.Name())
+	if err := od.imageOperations().DownloadImageAndSaveFilesToDir(od.image, tempDir1); err != nil {
+		return errors.Wrapf(truncateErrorMessage(err), "failed to download OCI image from discovery '%s'", od.Name())
 	}
 
-	inventoryDBFilePath := filepath.Join(tempDir1, plugininventory.SQliteDBFileName)
+	inventoryDBFilePath, err := findInventoryDBFile(tempDir1, od.getInventoryDBFileName())
+	if err != nil {
+		return errors.Wrapf(err, "invalid OCI image from discovery '%s'", od.Name())
+	}
 	metadataDBFilePath := filepath.Join(tempDir2, plugininventory.SQliteInventoryMetadataDBFileName)
 
-	// Download the plugin inventory metadata image if exists and save to tempDir2
-	pluginInventoryMetadataImage, _ := airgapped.GetPluginInventoryMetadataImage(od.image)
-	if err := carvelhelpers.DownloadImageAndSaveFilesToDir(pluginInventoryMetadataImage, tempDir2); err == nil {
-		// Update the plugin inventory database (plugin_inventory.db) based on the plugin
-		// inventory metadata database (plugin_inventory_metadata.db)
-		err = plugininventory.NewSQLiteInventoryMetadata(metadataDBFilePath).UpdatePluginInventoryDatabase(inventoryDBFilePath)
+	// Download the plugin inventory metadata image if exists and save to tempDir2.
+	// A local OCI image layout has no corresponding metadata image.
+	if !registry.IsLocalImage(od.image) {
+		pluginInventoryMetadataImage, err := od.getMetadataImage()
 		if err != nil {
-			return errors.Wrap(err, "error while updating inventory database based on the inventory metadata database")
+			return err
+		}
+		if err := od.imageOperations().DownloadImageAndSaveFilesToDir(pluginInventoryMetadataImage, tempDir2); err == nil {
+			// Update the plugin inventory database (plugin_inventory.db) based on the plugin
+			// inventory metadata database (plugin_inventory_metadata.db)
+			err = plugininventory.NewSQLiteInventoryMetadata(metadataDBFilePath).UpdatePluginInventoryDatabase(inventoryDBFilePath)
+			if err != nil {
+				return newDiscoveryError(DiscoveryErrorMetadataUpdate, od.Name(), errors.Wrap(err, "error while updating inventory database based on the inventory metadata database"))
+			}
+		}
+	}
+
+	if interrupt.Interrupted() {
+		return interrupt.ErrInterrupted
+	}
+
+	// Copy the inventory database file from temp directory to pluginDataDir.
+	// The file is first copied next to its destination and then renamed so
+	// that an interruption never leaves a partial database in the cache.
+	return od.updateCache(func() error {
+		dbFilePath := filepath.Join(od.pluginDataDir, plugininventory.SQliteDBFileName)
+		if err := utils.CopyFile(inventoryDBFilePath, dbFilePath+".tmp"); err != nil {
+			os.Remove(dbFilePath + ".tmp")
+			return newDiscoveryError(DiscoveryErrorCacheIO, od.Name(), err)
+		}
+		return newDiscoveryError(DiscoveryErrorCacheIO, od.Name(), os.Rename(dbFilePath+".tmp", dbFilePath))
+	})
+}
+
+// newImageOperations creates the image operations used by the discovery;
+// it is a variable so that tests can replace it
+var newImageOperations = carvelhelpers.NewImageOperationsImpl
+
+// imageOperations returns the image operations using the credentials of the discovery
+func (od *DBBackedOCIDiscovery) imageOperations() carvelhelpers.ImageOperationsImpl {
+	return newImageOperations(carvelhelpers.WithRegistryCredentials(od.credentials))
+}
+
+func (od *DBBackedOCIDiscovery) getInventoryDBFileName() string {
+	if od.inventoryDBFileName == "" {
+		return plugininventory.SQliteDBFileName
+	}
+	return od.inventoryDBFileName
+}
+
+// compressedInventoryDBFileSuffix is the suffix of the gzip-compressed
+// inventory database file that an image can ship instead of the database file
+const compressedInventoryDBFileSuffix = ".gz"
+
+// defaultMaxInventoryDBFileSize is the maximum size of a decompressed inventory database
+// file, to protect against decompression bombs, unless the user configures another one
+const defaultMaxInventoryDBFileSize = 512 << 20
+
+// getMaxInventoryDBFileSize returns the maximum size in bytes of a decompressed inventory database file
+func getMaxInventoryDBFileSize() int64 {
+	if maxSize, found := getMaxCacheSizeFromVariable(constants.ConfigVariablePluginDiscoveryMaxInventoryDBSize); found {
+		return maxSize
+	}
+	return defaultMaxInventoryDBFileSize
+}
+
+// findInventoryDBFile returns the path of the inventory database file named
+// dbFileName within dir.  If the image only contains the database compressed
+// with gzip, as dbFileName with the ".gz" suffix, it is decompressed into dir
+// first; the uncompressed database is used when both are present.
+// If the file cannot be found, the returned error lists the files that are
+// actually present to help diagnose malformed images.
+func findInventoryDBFile(dir, dbFileName string) (string, error) {
+	dbFilePath := filepath.Join(dir, dbFileName)
+	if info, err := os.Stat(dbFilePath); err == nil && !info.IsDir() {
+		return dbFilePath, nil
+	}
+
+	compressedDBFilePath := dbFilePath + compressedInventoryDBFileSuffix
+	if info, err := os.Stat(compressedDBFilePath); err == nil && !info.IsDir() {
+		if err := decompressInventoryDBFile(compressedDBFilePath, dbFilePath); err != nil {
+			return "", errors.Wrapf(err, "unable to decompress the plugin inventory database file '%s'", dbFileName+compressedInventoryDBFileSuffix)
 		}
+		return dbFilePath, nil
+	}
+
+	var foundFiles []string
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		foundFiles = append(foundFiles, entry.Name())
+	}
+	if len(foundFiles) == 0 {
+		return "", errors.Errorf("the plugin inventory database file '%s' was not found: the image does not contain any file", dbFileName)
+	}
+	return "", errors.Errorf("the plugin inventory database file '%s' was not found, the image contains: %s", dbFileName, strings.Join(foundFiles, ", "))
+}
+
+// decompressInventoryDBFile decompresses the gzip-compressed file src into dst.
+// The decompressed file is rejected if it would exceed the maximum size of an
+// inventory database, as declared by the gzip trailer before decompressing
+// anything, or as found while decompressing since the trailer can lie.
+func decompressInventoryDBFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	gzipReader, err := gzip.NewReader(in)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	maxSize := getMaxInventoryDBFileSize()
+	tooLargeErr := errors.Errorf("the decompressed file exceeds the maximum size of %d bytes, which can be changed with %s", maxSize, constants.ConfigVariablePluginDiscoveryMaxInventoryDBSize)
+	declaredSize, err := getGzipDeclaredSize(in)
+	if err != nil {
+		return err
+	}
+	if declaredSize > maxSize {
+		return tooLargeErr
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	written, err := io.Copy(out, io.LimitReader(gzipReader, maxSize+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && written > maxSize {
+		err = tooLargeErr
 	}
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return nil
+}
 
-	// Copy the inventory database file from temp directory to pluginDataDir
-	return utils.CopyFile(inventoryDBFilePath, filepath.Join(od.pluginDataDir, plugininventory.SQliteDBFileName))
+// getGzipDeclaredSize returns the size of the decompressed content declared by the trailer
+// of the gzip-compressed file, i.e., its last 4 bytes.  The declared size is the size
+// modulo 2^32 of the content of the last member of the file.  The read offset of the
+// file is left unchanged.
+func getGzipDeclaredSize(f *os.File) (int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	var trailer [4]byte
+	if _, err := f.ReadAt(trailer[:], info.Size()-4); err != nil {
+		return 0, err
+	}
+	return int64(binary.LittleEndian.Uint32(trailer[:])), nil
 }
 
 // checkImageCache will get the plugin inventory image digest as well as
@@ -269,19 +948,22 @@ func (od *DBBackedOCIDiscovery) checkImageCache() (string, string, error) {
 	// Get the latest digest of the discovery image.
 	// If the cache already contains the image with this digest
 	// we do not need to verify its signature nor to download it again.
-	_, hashHexValInventoryImage, err := carvelhelpers.GetImageDigest(od.image)
+	hashHexValInventoryImage, hashHexValMetadataImage, err := od.resolveImageDigests()
 	if err != nil {
-		// This will happen when the user has configured an invalid image discovery URI
-		return "", "", errors.Wrapf(err, "plugins discovery image resolution failed. Please check that the repository image URL %q is correct", od.image)
+		od.logCache(cacheLogLevel, "unable to resolve the digests of the images: %v", err)
+		return "", "", err
 	}
+	metadataDigest := "none"
+	if hashHexValMetadataImage != "" {
+		metadataDigest = "sha256:" + hashHexValMetadataImage
+	}
+	od.logCache(cacheLogLevel, "resolved the digest of the inventory image sha256:%s and of the metadata image %s", hashHexValInventoryImage, metadataDigest)
 
 	correctHashFileForInventoryImage := od.checkDigestFileExistence(hashHexValInventoryImage, "")
 
-	pluginInventoryMetadataImage, _ := airgapped.GetPluginInventoryMetadataImage(od.image)
-	_, hashHexValMetadataImage, _ := carvelhelpers.GetImageDigest(pluginInventoryMetadataImage)
 	// Always store the metadata image digest file even if the image does not exists.
-	// If the metadata image does not exist, a file named `metadata.digest.none` will be stored.
-	// If the metadata image exists, a file named `metadata.digest.<hexval>` will be stored.
+	// If the metadata image does not exist, a file named `metadata.digest.<identity>.none` will be stored.
+	// If the metadata image exists, a file named `metadata.digest.<identity>.<hexval>` will be stored.
 	// It is important to store the metadata digest file irrespective of if the metadata image exists
 	// or not for future comparisons and validating the cache.
 	// We do this, for this case:
@@ -294,10 +976,108 @@ func (od *DBBackedOCIDiscovery) checkImageCache() (string, string, error) {
 	return correctHashFileForInventoryImage, correctHashFileForMetadataImage, nil
 }
 
+// resolveImageDigests returns the hex value of the digest of the inventory image of
+// the discovery and of its metadata image.  The digest of the metadata image is empty
+// if there is no such image, which is always the case of a local OCI image layout.
+// The digests already resolved by VerifyInventoryImageSignatures are used instead, if any.
+func (od *DBBackedOCIDiscovery) resolveImageDigests() (string, string, error) {
+	if digests, found := od.takeResolvedImageDigests(); found {
+		return digests.inventory, digests.metadata, nil
+	}
+	_, hashHexValInventoryImage, err := od.imageOperations().GetImageDigest(od.image)
+	if err != nil {
+		// This will happen when the user has configured an invalid image discovery URI
+		return "", "", errors.Wrapf(truncateErrorMessage(err), "plugins discovery image resolution failed. Please check that the repository image URL %q is correct", od.image)
+	}
+
+	var hashHexValMetadataImage string
+	if !registry.IsLocalImage(od.image) {
+		pluginInventoryMetadataImage, err := od.getMetadataImage()
+		if err != nil {
+			return "", "", err
+		}
+		_, hashHexValMetadataImage, _ = od.imageOperations().GetImageDigest(pluginInventoryMetadataImage)
+	}
+	return hashHexValInventoryImage, hashHexValMetadataImage, nil
+}
+
+// getMetadataImage returns the plugin inventory metadata image corresponding to the
+// inventory image of the discovery.  It is only called once the inventory image has
+// been found valid, so failing to derive the metadata image is a configuration problem
+// which must not be mistaken for the absence of a metadata image, as that would
+// silently skip the air-gapped update of the inventory.
+func (od *DBBackedOCIDiscovery) getMetadataImage() (string, error) {
+	metadataImage, err := airgapped.GetPluginInventoryMetadataImage(od.image)
+	if err != nil {
+		// Make the problem visible even when the error is only logged at a high verbosity,
+		// as is the case for a refresh in the background
+		log.Warningf("Unable to determine the plugin inventory metadata image of discovery '%s': %v", od.Name(), err)
+		return "", errors.Wrapf(err, "the discovery image %q is valid but the corresponding plugin inventory metadata image cannot be determined; please check the image reference of discovery '%s'", od.image, od.Name())
+	}
+	return metadataImage, nil
+}
+
+// maxErrorMessageLength is the maximum length of the message of an error returned
+// when accessing the discovery image.  Such errors can include the complete
+// response body of the registry.
+const maxErrorMessageLength = 1024
+
+// truncateErrorMessage returns an error whose message only keeps the beginning and the
+// end of the message of the specified error if it is longer than maxErrorMessageLength.
+// The complete message is logged at verbosity level 4.
+func truncateErrorMessage(err error) error {
+	msg := err.Error()
+	if len(msg) <= maxErrorMessageLength {
+		return err
+	}
+	log.V(4).Infof("Complete error message: %s", msg)
+
+	keep := maxErrorMessageLength / 2
+	return errors.Errorf("%s ... [%d characters truncated, use a verbosity level of 4 or more to see them] ... %s", msg[:keep], len(msg)-2*keep, msg[len(msg)-keep:])
+}
+
+// checkCacheAge returns an error if the cached inventory is older than maxCacheAge.
+// The age of the cache is the time since the digest file was last written, which
+// happens every time the cache is found up-to-date with the discovery image.
+// There is nothing to check if the cache is empty.
+func (od *DBBackedOCIDiscovery) checkCacheAge() error {
+	if od.maxCacheAge <= 0 {
+		return nil
+	}
+	// Only the digest file of this discovery tells when its inventory was last refreshed;
+	// the cache directory can also hold the digest files of other discoveries
+	matches, _ := filepath.Glob(filepath.Join(od.pluginDataDir, "digest."+od.identityHash()+".*"))
+	if len(matches) != 1 {
+		return nil
+	}
+	info, err := os.Stat(matches[0])
+	if err != nil {
+		return nil
+	}
+	if age := time.Since(info.ModTime()); age > od.maxCacheAge {
+		return errors.Errorf("the cached inventory of discovery '%s' was last refreshed %v ago, which is more than the maximum age of %v. Please refresh it by running a plugin command with access to the registry (e.g., 'tanzu plugin search') or increase the maximum age",
+			od.Name(), age.Round(time.Minute), od.maxCacheAge)
+	}
+	return nil
+}
+
+// identityHash returns a short hash identifying what this discovery fetches
+// into its cache, that is the image and the name of the database file within it.
+// The plugin and group criteria are not part of the identity as they only filter
+// the content of the cached database; discoveries that differ only by their criteria
+// can therefore share the same cache without invalidating it.
+func (od *DBBackedOCIDiscovery) identityHash() string {
+	hash := sha256.Sum256([]byte(od.image + "\n" + od.getInventoryDBFileName()))
+	return hex.EncodeToString(hash[:])[:12]
+}
+
 // checkDigestFileExistence check the digest file already exists in the cache or not
-// We store the digest hash of the cached DB as a file named "<digestPrefix>digest.<hash>.
-// If this file exists, we are done. If not, we remove the current digest file
-// as we are about to download a new DB and will create a new digest file.
+// We store the digest hash of the cached DB as a file named "<digestPrefix>digest.<identity>.<hash>
+// where <identity> is the identityHash() of the discovery.  This way, a discovery fetching
+// a different image or database file into the same cache directory does not mistake
+// the cached DB for its own, even if the image digests are the same.
+// If this file exists, we are done. If not, the current digest file is replaced
+// by createHashFiles() once the new DB has been downloaded.
 // First check any existing "<digestPrefix>digest.*" file; there should only be one, but
 // to protect ourselves, we check first and if there are more then one due
 // to some bug, we clean them up and invalidate the cache.
@@ -310,22 +1090,37 @@ func (od *DBBackedOCIDiscovery) checkDigestFileExistence(hashHexVal, digestPrefi
 		hashHexVal = "none"
 	}
 
-	correctHashFile := filepath.Join(od.pluginDataDir, digestPrefix+"digest."+hashHexVal)
+	correctHashFile := filepath.Join(od.pluginDataDir, digestPrefix+"digest."+od.identityHash()+"."+hashHexVal)
 	matches, _ := filepath.Glob(filepath.Join(od.pluginDataDir, digestPrefix+"digest.*"))
 	if len(matches) > 1 {
 		// Too many digest files.  This is a bug!  Cleanup the cache.
-		log.V(4).Warningf("Too many digest files in the cache!  Invalidating the cache.")
+		log.V(4).Warningf("Too many digest files in the cache of discovery '%s' (%s)!  Invalidating the cache.", od.Name(), od.image)
 		for _, filePath := range matches {
 			os.Remove(filePath)
+			od.logCache(cacheDetailLogLevel, "removed the digest file %s", filepath.Base(filePath))
 		}
 	} else if len(matches) == 1 {
 		if matches[0] == correctHashFile {
+			if _, err := os.Stat(filepath.Join(od.pluginDataDir, plugininventory.SQliteDBFileName)); err != nil {
+				// The hash file is orphaned: the DB it refers to is missing.  Remove the
+				// hash file and treat it as a cache miss so that the DB gets downloaded again.
+				log.V(4).Warningf("Digest file %s found without a plugin inventory DB in the cache of discovery '%s' (%s)!  Invalidating the cache.", filepath.Base(correctHashFile), od.Name(), od.image)
+				os.Remove(correctHashFile)
+				return correctHashFile
+			}
 			// The hash file exists which means the DB is up-to-date.  We are done.
+			// Record that the cache was just found up-to-date; this is what checkCacheAge() relies on.
+			now := time.Now()
+			_ = os.Chtimes(correctHashFile, now, now)
+			od.logCache(cacheDetailLogLevel, "the digest file %s matches the image", filepath.Base(correctHashFile))
 			return ""
 		}
-		// The hash file indicates a different digest hash. Remove this old hash file
-		// as we will download the new DB.
-		os.Remove(matches[0])
+		// The hash file indicates a different digest hash.  It is kept, along with the DB
+		// it describes, until the new DB is in place and createHashFiles() replaces it, so
+		// that a download which does not complete leaves the cached inventory usable.
+		od.logCache(cacheLogLevel, "the digest file %s does not match the image", filepath.Base(matches[0]))
+	} else {
+		od.logCache(cacheLogLevel, "no %sdigest file in the cache", digestPrefix)
 	}
 	return correctHashFile
 }
@@ -4,12 +4,24 @@
 package discovery
 
 import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/interrupt"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 )
@@ -32,6 +44,9 @@ func (stub *stubInventory) GetPlugins(filter *plugininventory.PluginInventoryFil
 	// Return the plugin filter so the tests can verify if it is correct
 	return nil, inventoryFilterInError{pluginFilter: filter}
 }
+func (stub *stubInventory) CountPlugins(filter *plugininventory.PluginInventoryFilter) (int, error) {
+	return 0, inventoryFilterInError{pluginFilter: filter}
+}
 func (stub *stubInventory) GetPluginGroups(filter plugininventory.PluginGroupFilter) ([]*plugininventory.PluginGroup, error) {
 	// Return the group filter so the tests can verify if it is correct
 	return nil, inventoryFilterInError{groupFilter: &filter}
@@ -52,6 +67,140 @@ func (stub *stubInventory) UpdatePluginGroupActivationState(pg *plugininventory.
 	return nil
 }
 
+// staticInventory is an inventory returning a fixed list of plugins
+type staticInventory struct {
+	stubInventory
+	plugins []*plugininventory.PluginInventoryEntry
+}
+
+func (s *staticInventory) GetPlugins(_ *plugininventory.PluginInventoryFilter) ([]*plugininventory.PluginInventoryEntry, error) {
+	return s.plugins, nil
+}
+
+// newLargeInventory returns an inventory of the specified number of plugins, each
+// providing the specified number of versions for a few platforms
+func newLargeInventory(pluginCount, versionCount int) *staticInventory {
+	inventory := &staticInventory{}
+	for i := 0; i < pluginCount; i++ {
+		artifacts := make(distribution.Artifacts, versionCount)
+		for j := 0; j < versionCount; j++ {
+			artifacts[fmt.Sprintf("v%d.%d.0", j/10, j%10)] = []distribution.Artifact{
+				{OS: "linux", Arch: "amd64", Digest: "0000000000"},
+				{OS: "darwin", Arch: "arm64", Digest: "1111111111"},
+			}
+		}
+		inventory.plugins = append(inventory.plugins, &plugininventory.PluginInventoryEntry{
+			Name:               fmt.Sprintf("plugin%d", i),
+			Target:             configtypes.TargetK8s,
+			Description:        fmt.Sprintf("plugin%d description", i),
+			Publisher:          "tkg",
+			Vendor:             "vmware",
+			RecommendedVersion: "v0.1.0",
+			Artifacts:          artifacts,
+		})
+	}
+	return inventory
+}
+
+// interruptedImageOperations simulates the interruption of the CLI while an image is being downloaded
+type interruptedImageOperations struct {
+	carvelhelpers.ImageOperationsImpl
+	downloadDir string
+}
+
+func (i *interruptedImageOperations) GetImageDigest(imageWithTag string) (string, string, error) {
+	return "sha256:1234", "1234", nil
+}
+
+func (i *interruptedImageOperations) DownloadImageAndSaveFilesToDir(imageWithTag, destinationDir string) error {
+	i.downloadDir = destinationDir
+	// Leave a partially downloaded file behind
+	if err := os.WriteFile(filepath.Join(destinationDir, plugininventory.SQliteDBFileName), []byte("partial"), 0644); err != nil {
+		return err
+	}
+	interrupt.Interrupt()
+	return errors.New("download canceled")
+}
+
+// refreshImageOperations simulates the download of an inventory image without any metadata image
+type refreshImageOperations struct {
+	carvelhelpers.ImageOperationsImpl
+	image     string
+	downloads int
+	// compressed makes the image ship the database compressed with gzip
+	compressed bool
+	// digest is the digest of the image, "1234" if not set
+	digest string
+	// resolutions is the number of times the digest of the image was resolved
+	resolutions int
+	// database is the content of the database of the image, "inventory" if not set
+	database []byte
+	// gate, when set, blocks the resolution of the digests until it is closed
+	gate chan struct{}
+	// downloadGate, when set, blocks the download of the image until it is closed, once
+	// a partial database is written and downloadStarted is closed
+	downloadGate    chan struct{}
+	downloadStarted chan struct{}
+}
+
+func (r *refreshImageOperations) GetImageDigest(imageWithTag string) (string, string, error) {
+	if r.gate != nil {
+		<-r.gate
+	}
+	if imageWithTag == r.image {
+		r.resolutions++
+	}
+	if r.digest != "" {
+		return "sha256:" + r.digest, r.digest, nil
+	}
+	return "sha256:1234", "1234", nil
+}
+
+func (r *refreshImageOperations) DownloadImageAndSaveFilesToDir(imageWithTag, destinationDir string) error {
+	if imageWithTag != r.image {
+		return errors.New("image not found")
+	}
+	r.downloads++
+	database := r.database
+	if database == nil {
+		database = []byte("inventory")
+	}
+	if r.downloadGate != nil {
+		if err := os.WriteFile(filepath.Join(destinationDir, plugininventory.SQliteDBFileName), database[:len(database)/2], 0644); err != nil {
+			return err
+		}
+		close(r.downloadStarted)
+		<-r.downloadGate
+	}
+	if r.compressed {
+		return writeCompressedFile(filepath.Join(destinationDir, plugininventory.SQliteDBFileName+".gz"), database)
+	}
+	return os.WriteFile(filepath.Join(destinationDir, plugininventory.SQliteDBFileName), database, 0644)
+}
+
+// failingImageOperations simulates a registry which fails with the specified error message
+type failingImageOperations struct {
+	carvelhelpers.ImageOperationsImpl
+	message string
+}
+
+func (f *failingImageOperations) GetImageDigest(imageWithTag string) (string, string, error) {
+	return "", "", errors.New(f.message)
+}
+
+// writeCompressedFile writes the content compressed with gzip to the file
+func writeCompressedFile(path string, content []byte) error {
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	if _, err := gzipWriter.Write(content); err != nil {
+		return err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
 var _ = Describe("Unit tests for DB-backed OCI discovery", func() {
 	var (
 		err          error
@@ -164,6 +313,29 @@ var _ = Describe("Unit tests for DB-backed OCI discovery", func() {
 					IncludeHidden: false,
 				}))
 			})
+			It("should pass the limit and offset of the criteria to the filter", func() {
+				criteria := &PluginDiscoveryCriteria{
+					Target: filteredTarget,
+					Limit:  10,
+					Offset: 20,
+				}
+				discovery := NewOCIDiscovery("test-discovery", "test-image:latest", WithPluginDiscoveryCriteria(criteria))
+				dbDiscovery, ok := discovery.(*DBBackedOCIDiscovery)
+				Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+
+				// Inject the stub inventory and data dir
+				dbDiscovery.pluginDataDir = tmpDir
+				dbDiscovery.inventory = &stubInventory{}
+
+				_, err := dbDiscovery.listPluginsFromInventory()
+				filterInErr, ok := err.(inventoryFilterInError)
+				Expect(ok).To(BeTrue())
+				Expect(*filterInErr.pluginFilter).To(Equal(plugininventory.PluginInventoryFilter{
+					Target: filteredTarget,
+					Limit:  10,
+					Offset: 20,
+				}))
+			})
 			It("with TANZU_CLI_INCLUDE_DEACTIVATED_PLUGINS_TEST_ONLY=1 the filter should include hidden plugin", func() {
 				criteria := &PluginDiscoveryCriteria{
 					Name:    filteredName,
@@ -362,4 +534,1159 @@ var _ = Describe("Unit tests for DB-backed OCI discovery", func() {
 			})
 		})
 	})
+	Describe("Inventory database file name", func() {
+		var imageDir string
+
+		BeforeEach(func() {
+			imageDir, err = os.MkdirTemp("", "image")
+			Expect(err).To(BeNil())
+		})
+		AfterEach(func() {
+			os.Unsetenv(constants.ConfigVariablePluginInventoryDBFileNames)
+			os.RemoveAll(imageDir)
+		})
+		Context("when no file name is configured", func() {
+			It("should use the default database file name", func() {
+				discovery := NewOCIDiscovery("test-discovery", "test-image:latest")
+				dbDiscovery, ok := discovery.(*DBBackedOCIDiscovery)
+				Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+				Expect(dbDiscovery.getInventoryDBFileName()).To(Equal(plugininventory.SQliteDBFileName))
+
+				_, err = os.Create(filepath.Join(imageDir, plugininventory.SQliteDBFileName))
+				Expect(err).To(BeNil())
+
+				dbFile, err := findInventoryDBFile(imageDir, dbDiscovery.getInventoryDBFileName())
+				Expect(err).To(BeNil())
+				Expect(dbFile).To(Equal(filepath.Join(imageDir, plugininventory.SQliteDBFileName)))
+			})
+		})
+		Context("when a file name is configured for the discovery source", func() {
+			It("should use the file name from the option", func() {
+				discovery := NewOCIDiscovery("test-discovery", "test-image:latest", WithInventoryDBFileName("custom.db"))
+				dbDiscovery, ok := discovery.(*DBBackedOCIDiscovery)
+				Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+				Expect(dbDiscovery.getInventoryDBFileName()).To(Equal("custom.db"))
+
+				_, err = os.Create(filepath.Join(imageDir, "custom.db"))
+				Expect(err).To(BeNil())
+
+				dbFile, err := findInventoryDBFile(imageDir, dbDiscovery.getInventoryDBFileName())
+				Expect(err).To(BeNil())
+				Expect(dbFile).To(Equal(filepath.Join(imageDir, "custom.db")))
+			})
+			It("should use the file name from the environment variable for the matching discovery only", func() {
+				os.Setenv(constants.ConfigVariablePluginInventoryDBFileNames, "other=other.db, test-discovery = custom.db")
+
+				discovery := NewOCIGroupDiscovery("test-discovery", "test-image:latest")
+				dbDiscovery, ok := discovery.(*DBBackedOCIDiscovery)
+				Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+				Expect(dbDiscovery.getInventoryDBFileName()).To(Equal("custom.db"))
+
+				discovery = NewOCIGroupDiscovery("default", "test-image:latest")
+				dbDiscovery, ok = discovery.(*DBBackedOCIDiscovery)
+				Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+				Expect(dbDiscovery.getInventoryDBFileName()).To(Equal(plugininventory.SQliteDBFileName))
+			})
+			It("should list the files found in the image if the database file is missing", func() {
+				_, err = os.Create(filepath.Join(imageDir, plugininventory.SQliteDBFileName))
+				Expect(err).To(BeNil())
+				_, err = os.Create(filepath.Join(imageDir, "README.md"))
+				Expect(err).To(BeNil())
+
+				_, err := findInventoryDBFile(imageDir, "custom.db")
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal("the plugin inventory database file 'custom.db' was not found, the image contains: README.md, plugin_inventory.db"))
+			})
+			It("should decompress the database file if the image only contains it compressed", func() {
+				Expect(writeCompressedFile(filepath.Join(imageDir, "custom.db.gz"), []byte("compressed inventory"))).To(Succeed())
+
+				dbFile, err := findInventoryDBFile(imageDir, "custom.db")
+				Expect(err).To(BeNil())
+				Expect(dbFile).To(Equal(filepath.Join(imageDir, "custom.db")))
+				content, err := os.ReadFile(dbFile)
+				Expect(err).To(BeNil())
+				Expect(string(content)).To(Equal("compressed inventory"))
+			})
+			It("should use the uncompressed database file if the image contains both", func() {
+				Expect(os.WriteFile(filepath.Join(imageDir, "custom.db"), []byte("inventory"), 0644)).To(Succeed())
+				Expect(writeCompressedFile(filepath.Join(imageDir, "custom.db.gz"), []byte("compressed inventory"))).To(Succeed())
+
+				dbFile, err := findInventoryDBFile(imageDir, "custom.db")
+				Expect(err).To(BeNil())
+				content, err := os.ReadFile(dbFile)
+				Expect(err).To(BeNil())
+				Expect(string(content)).To(Equal("inventory"))
+			})
+			It("should fail if the decompressed database file is too large", func() {
+				os.Setenv(constants.ConfigVariablePluginDiscoveryMaxInventoryDBSize, "10")
+				defer os.Unsetenv(constants.ConfigVariablePluginDiscoveryMaxInventoryDBSize)
+				Expect(writeCompressedFile(filepath.Join(imageDir, "custom.db.gz"), []byte(strings.Repeat("inventory", 100)))).To(Succeed())
+
+				_, err := findInventoryDBFile(imageDir, "custom.db")
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("the decompressed file exceeds the maximum size of 10 bytes"))
+				Expect(filepath.Join(imageDir, "custom.db")).ToNot(BeAnExistingFile())
+			})
+			It("should fail if the compressed database file is invalid", func() {
+				Expect(os.WriteFile(filepath.Join(imageDir, "custom.db.gz"), []byte("not gzip"), 0644)).To(Succeed())
+
+				_, err := findInventoryDBFile(imageDir, "custom.db")
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("unable to decompress the plugin inventory database file 'custom.db.gz'"))
+				Expect(filepath.Join(imageDir, "custom.db")).ToNot(BeAnExistingFile())
+			})
+			It("should report an empty image if the database file is missing", func() {
+				_, err := findInventoryDBFile(imageDir, "custom.db")
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("the image does not contain any file"))
+			})
+		})
+	})
+	Describe("Digest files in the cache", func() {
+		var dataDir string
+
+		newDiscoveryWithDataDir := func(image string, criteria *PluginDiscoveryCriteria) *DBBackedOCIDiscovery {
+			discovery := NewOCIDiscovery("test-discovery", image, WithPluginDiscoveryCriteria(criteria))
+			dbDiscovery, ok := discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			dbDiscovery.pluginDataDir = dataDir
+			return dbDiscovery
+		}
+
+		BeforeEach(func() {
+			dataDir, err = os.MkdirTemp("", "cache")
+			Expect(err).To(BeNil())
+			_, err = os.Create(filepath.Join(dataDir, plugininventory.SQliteDBFileName))
+			Expect(err).To(BeNil())
+		})
+		AfterEach(func() {
+			os.RemoveAll(dataDir)
+		})
+		Context("with two discoveries of the same image using different criteria", func() {
+			It("should share the digest files of the cache", func() {
+				discovery1 := newDiscoveryWithDataDir("test-image:latest", &PluginDiscoveryCriteria{Name: "plugin1", Target: configtypes.TargetK8s})
+				discovery2 := newDiscoveryWithDataDir("test-image:latest", &PluginDiscoveryCriteria{Name: "plugin2", Target: configtypes.TargetTMC})
+
+				// The first discovery fills the cache
+				hashFile := discovery1.checkDigestFileExistence("1234", "")
+				Expect(hashFile).ToNot(BeEmpty())
+				_, err = os.Create(hashFile)
+				Expect(err).To(BeNil())
+				metadataHashFile := discovery1.checkDigestFileExistence("", "metadata.")
+				Expect(metadataHashFile).ToNot(BeEmpty())
+				_, err = os.Create(metadataHashFile)
+				Expect(err).To(BeNil())
+
+				// The second discovery can use the cache as is
+				Expect(discovery2.checkDigestFileExistence("1234", "")).To(BeEmpty())
+				Expect(discovery2.checkDigestFileExistence("", "metadata.")).To(BeEmpty())
+				// and so can the first one
+				Expect(discovery1.checkDigestFileExistence("1234", "")).To(BeEmpty())
+				Expect(discovery1.checkDigestFileExistence("", "metadata.")).To(BeEmpty())
+			})
+		})
+		Context("with two discoveries of different images", func() {
+			It("should invalidate the cache even if the digests are the same", func() {
+				discovery1 := newDiscoveryWithDataDir("test-image:latest", nil)
+				discovery2 := newDiscoveryWithDataDir("other-image:latest", nil)
+
+				hashFile := discovery1.checkDigestFileExistence("1234", "")
+				Expect(hashFile).ToNot(BeEmpty())
+				_, err = os.Create(hashFile)
+				Expect(err).To(BeNil())
+
+				hashFile2 := discovery2.checkDigestFileExistence("1234", "")
+				Expect(hashFile2).ToNot(BeEmpty())
+				Expect(hashFile2).ToNot(Equal(hashFile))
+				// The previous digest file is kept until the new one is created
+				Expect(hashFile).To(BeAnExistingFile())
+				discovery2.createHashFiles(hashFile2, "")
+				Expect(hashFile).ToNot(BeAnExistingFile())
+				Expect(hashFile2).To(BeAnExistingFile())
+			})
+		})
+		Context("when there is no metadata image", func() {
+			It("should not use a digest file name ending with a '.'", func() {
+				discovery := newDiscoveryWithDataDir("test-image:latest", nil)
+
+				hashFile := discovery.checkDigestFileExistence("", "metadata.")
+				Expect(hashFile).To(HaveSuffix(".none"))
+				_, err = os.Create(hashFile)
+				Expect(err).To(BeNil())
+
+				matches, err := filepath.Glob(filepath.Join(dataDir, "metadata.digest.*"))
+				Expect(err).To(BeNil())
+				Expect(matches).To(Equal([]string{hashFile}))
+				Expect(discovery.checkDigestFileExistence("", "metadata.")).To(BeEmpty())
+			})
+		})
+		Context("when the database is missing from the cache", func() {
+			It("should remove the orphaned digest files and consider the cache outdated", func() {
+				discovery := newDiscoveryWithDataDir("test-image:latest", nil)
+
+				hashFile := discovery.checkDigestFileExistence("1234", "")
+				Expect(hashFile).ToNot(BeEmpty())
+				_, err = os.Create(hashFile)
+				Expect(err).To(BeNil())
+				metadataHashFile := discovery.checkDigestFileExistence("", "metadata.")
+				Expect(metadataHashFile).ToNot(BeEmpty())
+				_, err = os.Create(metadataHashFile)
+				Expect(err).To(BeNil())
+
+				Expect(os.Remove(filepath.Join(dataDir, plugininventory.SQliteDBFileName))).To(Succeed())
+
+				Expect(discovery.checkDigestFileExistence("1234", "")).To(Equal(hashFile))
+				Expect(hashFile).ToNot(BeAnExistingFile())
+				Expect(discovery.checkDigestFileExistence("", "metadata.")).To(Equal(metadataHashFile))
+				Expect(metadataHashFile).ToNot(BeAnExistingFile())
+			})
+		})
+	})
+	Describe("Orphaned digest files", func() {
+		var (
+			dataDir         string
+			imageOperations *refreshImageOperations
+		)
+		BeforeEach(func() {
+			dataDir, err = os.MkdirTemp("", "cache")
+			Expect(err).To(BeNil())
+			os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, "test-image:latest")
+
+			imageOperations = &refreshImageOperations{image: "test-image:latest"}
+			newImageOperations = func(options ...carvelhelpers.ImageOperationOption) carvelhelpers.ImageOperationsImpl {
+				return imageOperations
+			}
+		})
+		AfterEach(func() {
+			newImageOperations = carvelhelpers.NewImageOperationsImpl
+			os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+			os.RemoveAll(dataDir)
+		})
+		It("should download the database again when only the digest files remain in the cache", func() {
+			discovery := NewOCIDiscovery("test-discovery", "test-image:latest")
+			dbDiscovery, ok := discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			dbDiscovery.pluginDataDir = dataDir
+			dbFile := filepath.Join(dataDir, plugininventory.SQliteDBFileName)
+
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.downloads).To(Equal(1))
+			Expect(dbFile).To(BeAnExistingFile())
+			hashFiles, err := filepath.Glob(filepath.Join(dataDir, "*digest.*"))
+			Expect(err).To(BeNil())
+			Expect(hashFiles).To(HaveLen(2))
+
+			// The cache is up-to-date
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.downloads).To(Equal(1))
+
+			// Remove the database but keep the digest files
+			Expect(os.Remove(dbFile)).To(Succeed())
+
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.downloads).To(Equal(2))
+			Expect(dbFile).To(BeAnExistingFile())
+			content, err := os.ReadFile(dbFile)
+			Expect(err).To(BeNil())
+			Expect(string(content)).To(Equal("inventory"))
+			matches, err := filepath.Glob(filepath.Join(dataDir, "*digest.*"))
+			Expect(err).To(BeNil())
+			Expect(matches).To(ConsistOf(hashFiles))
+		})
+	})
+	Describe("Corrupt cached inventory database", func() {
+		var (
+			dataDir         string
+			imageOperations *refreshImageOperations
+			dbDiscovery     *DBBackedOCIDiscovery
+			dbFile          string
+		)
+		BeforeEach(func() {
+			dataDir, err = os.MkdirTemp("", "cache")
+			Expect(err).To(BeNil())
+			os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, "test-image:latest")
+
+			// The image provides a valid, empty, inventory database
+			validDBFile := filepath.Join(dataDir, "valid.db")
+			Expect(plugininventory.NewSQLiteInventory(validDBFile, "").CreateSchema()).To(Succeed())
+			validDB, err := os.ReadFile(validDBFile)
+			Expect(err).To(BeNil())
+			Expect(os.Remove(validDBFile)).To(Succeed())
+
+			imageOperations = &refreshImageOperations{image: "test-image:latest", database: validDB}
+			newImageOperations = func(options ...carvelhelpers.ImageOperationOption) carvelhelpers.ImageOperationsImpl {
+				return imageOperations
+			}
+
+			discovery := NewOCIDiscovery("test-discovery", "test-image:latest")
+			var ok bool
+			dbDiscovery, ok = discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			dbFile = filepath.Join(dataDir, plugininventory.SQliteDBFileName)
+			dbDiscovery.pluginDataDir = dataDir
+			dbDiscovery.inventory = plugininventory.NewSQLiteInventory(dbFile, "")
+
+			// Cache the inventory and then corrupt it
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.downloads).To(Equal(1))
+			Expect(os.WriteFile(dbFile, []byte("this is a corrupt plugin inventory database"), 0644)).To(Succeed())
+		})
+		AfterEach(func() {
+			newImageOperations = carvelhelpers.NewImageOperationsImpl
+			os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+			os.RemoveAll(dataDir)
+		})
+		It("should fetch the inventory again and retry listing the plugins", func() {
+			plugins, err := dbDiscovery.List()
+			Expect(err).To(BeNil())
+			Expect(plugins).To(BeEmpty())
+			Expect(imageOperations.downloads).To(Equal(2))
+
+			// The cache is valid again
+			_, err = dbDiscovery.List()
+			Expect(err).To(BeNil())
+			Expect(imageOperations.downloads).To(Equal(2))
+		})
+		It("should fetch the inventory again and retry listing the groups", func() {
+			groups, err := dbDiscovery.GetGroups()
+			Expect(err).To(BeNil())
+			Expect(groups).To(BeEmpty())
+			Expect(imageOperations.downloads).To(Equal(2))
+		})
+		It("should only retry once when the fetched inventory is also corrupt", func() {
+			imageOperations.database = []byte("this is also a corrupt plugin inventory database")
+
+			_, err := dbDiscovery.List()
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("file is not a database"))
+			Expect(errors.Is(err, &DiscoveryError{Code: DiscoveryErrorSQLiteCorrupt, Discovery: "test-discovery"})).To(BeTrue())
+			Expect(imageOperations.downloads).To(Equal(2))
+		})
+		It("should not fetch the inventory again when only using the local cache", func() {
+			dbDiscovery.useLocalCacheOnly = true
+
+			_, err := dbDiscovery.List()
+			Expect(err).ToNot(BeNil())
+			code, found := GetDiscoveryErrorCode(err)
+			Expect(found).To(BeTrue())
+			Expect(code).To(Equal(DiscoveryErrorSQLiteCorrupt))
+			Expect(imageOperations.downloads).To(Equal(1))
+		})
+	})
+	Describe("Background refresh", func() {
+		var (
+			dataDir         string
+			imageOperations *refreshImageOperations
+			dbDiscovery     *DBBackedOCIDiscovery
+		)
+		BeforeEach(func() {
+			dataDir, err = os.MkdirTemp("", "cache")
+			Expect(err).To(BeNil())
+			os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, "test-image:latest")
+			os.Setenv(constants.ConfigVariablePluginDiscoveryBackgroundRefresh, "true")
+
+			validDBFile := filepath.Join(dataDir, "valid.db")
+			Expect(plugininventory.NewSQLiteInventory(validDBFile, "").CreateSchema()).To(Succeed())
+			validDB, err := os.ReadFile(validDBFile)
+			Expect(err).To(BeNil())
+			Expect(os.Remove(validDBFile)).To(Succeed())
+
+			imageOperations = &refreshImageOperations{image: "test-image:latest", database: validDB}
+			newImageOperations = func(options ...carvelhelpers.ImageOperationOption) carvelhelpers.ImageOperationsImpl {
+				return imageOperations
+			}
+
+			discovery := NewOCIDiscovery("test-discovery", "test-image:latest")
+			var ok bool
+			dbDiscovery, ok = discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			Expect(dbDiscovery.backgroundRefresh).To(BeTrue())
+			dbDiscovery.pluginDataDir = dataDir
+			dbDiscovery.inventory = plugininventory.NewSQLiteInventory(filepath.Join(dataDir, plugininventory.SQliteDBFileName), "")
+		})
+		AfterEach(func() {
+			Expect(WaitForBackgroundRefreshes(10 * time.Second)).To(BeTrue())
+			backgroundRefreshStop.stopped = false
+			interrupt.Reset()
+			newImageOperations = carvelhelpers.NewImageOperationsImpl
+			os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+			os.Unsetenv(constants.ConfigVariablePluginDiscoveryBackgroundRefresh)
+			os.RemoveAll(dataDir)
+		})
+		It("should fetch the inventory in the foreground when there is no cache", func() {
+			_, err := dbDiscovery.List()
+			Expect(err).To(BeNil())
+			Expect(imageOperations.downloads).To(Equal(1))
+			Expect(dbDiscovery.getCachedInventoryHashFile()).ToNot(BeEmpty())
+		})
+		It("should serve the cached inventory while refreshing it in the background", func() {
+			// Cache the inventory and then change the image
+			_, err := dbDiscovery.List()
+			Expect(err).To(BeNil())
+			Expect(imageOperations.downloads).To(Equal(1))
+			oldHashFile := dbDiscovery.getCachedInventoryHashFile()
+			imageOperations.digest = "5678"
+			imageOperations.gate = make(chan struct{})
+
+			// The queries do not wait for the refresh, which is only started once
+			_, err = dbDiscovery.List()
+			Expect(err).To(BeNil())
+			_, err = dbDiscovery.GetGroups()
+			Expect(err).To(BeNil())
+			Expect(dbDiscovery.getCachedInventoryHashFile()).To(Equal(oldHashFile))
+			Expect(WaitForBackgroundRefreshes(10 * time.Millisecond)).To(BeFalse())
+
+			close(imageOperations.gate)
+			Expect(WaitForBackgroundRefreshes(10 * time.Second)).To(BeTrue())
+			Expect(imageOperations.downloads).To(Equal(2))
+			Expect(imageOperations.resolutions).To(Equal(2))
+
+			// The next query uses the refreshed inventory
+			newHashFile := dbDiscovery.getCachedInventoryHashFile()
+			Expect(newHashFile).ToNot(Equal(oldHashFile))
+			Expect(newHashFile).To(HaveSuffix(".5678"))
+		})
+		It("should keep the cached inventory when the background refresh fails", func() {
+			_, err := dbDiscovery.List()
+			Expect(err).To(BeNil())
+			oldHashFile := dbDiscovery.getCachedInventoryHashFile()
+			newImageOperations = func(options ...carvelhelpers.ImageOperationOption) carvelhelpers.ImageOperationsImpl {
+				return &failingImageOperations{message: "registry unavailable"}
+			}
+
+			_, err = dbDiscovery.List()
+			Expect(err).To(BeNil())
+			Expect(WaitForBackgroundRefreshes(10 * time.Second)).To(BeTrue())
+			Expect(dbDiscovery.getCachedInventoryHashFile()).To(Equal(oldHashFile))
+		})
+		It("should leave the cache and the temporary directory clean when the CLI exits during the refresh", func() {
+			tmpDir, err := os.MkdirTemp("", "tmp")
+			Expect(err).To(BeNil())
+			defer os.RemoveAll(tmpDir)
+			origTmpDir, tmpDirSet := os.LookupEnv("TMPDIR")
+			os.Setenv("TMPDIR", tmpDir)
+			defer func() {
+				if tmpDirSet {
+					os.Setenv("TMPDIR", origTmpDir)
+				} else {
+					os.Unsetenv("TMPDIR")
+				}
+			}()
+
+			_, err = dbDiscovery.List()
+			Expect(err).To(BeNil())
+			oldHashFile := dbDiscovery.getCachedInventoryHashFile()
+			dbFile := filepath.Join(dataDir, plugininventory.SQliteDBFileName)
+			oldDB, err := os.ReadFile(dbFile)
+			Expect(err).To(BeNil())
+
+			expectCleanCache := func() {
+				entries, err := os.ReadDir(tmpDir)
+				Expect(err).To(BeNil())
+				Expect(entries).To(BeEmpty())
+				Expect(dbDiscovery.getCachedInventoryHashFile()).To(Equal(oldHashFile))
+				db, err := os.ReadFile(dbFile)
+				Expect(err).To(BeNil())
+				Expect(db).To(Equal(oldDB))
+				matches, err := filepath.Glob(filepath.Join(dataDir, "*.tmp"))
+				Expect(err).To(BeNil())
+				Expect(matches).To(BeEmpty())
+			}
+
+			// The image changes and the CLI exits while its download is in progress
+			imageOperations.digest = "5678"
+			imageOperations.downloadStarted = make(chan struct{})
+			imageOperations.downloadGate = make(chan struct{})
+			_, err = dbDiscovery.List()
+			Expect(err).To(BeNil())
+			Eventually(imageOperations.downloadStarted).Should(BeClosed())
+			Expect(WaitForBackgroundRefreshes(10 * time.Millisecond)).To(BeFalse())
+			StopBackgroundRefreshes()
+			interrupt.Cleanup()
+			expectCleanCache()
+
+			// Nothing changes if the refresh goes on before the CLI exits
+			close(imageOperations.downloadGate)
+			Expect(WaitForBackgroundRefreshes(10 * time.Second)).To(BeTrue())
+			expectCleanCache()
+		})
+	})
+	Describe("Compressed inventory database", func() {
+		var (
+			dataDir         string
+			imageOperations *refreshImageOperations
+		)
+		BeforeEach(func() {
+			dataDir, err = os.MkdirTemp("", "cache")
+			Expect(err).To(BeNil())
+			os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, "test-image:latest")
+
+			imageOperations = &refreshImageOperations{image: "test-image:latest"}
+			newImageOperations = func(options ...carvelhelpers.ImageOperationOption) carvelhelpers.ImageOperationsImpl {
+				return imageOperations
+			}
+		})
+		AfterEach(func() {
+			newImageOperations = carvelhelpers.NewImageOperationsImpl
+			os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+			os.RemoveAll(dataDir)
+		})
+		DescribeTable("should store the decompressed database in the cache",
+			func(compressed bool) {
+				imageOperations.compressed = compressed
+				discovery := NewOCIDiscovery("test-discovery", "test-image:latest")
+				dbDiscovery, ok := discovery.(*DBBackedOCIDiscovery)
+				Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+				dbDiscovery.pluginDataDir = dataDir
+
+				Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+				Expect(imageOperations.downloads).To(Equal(1))
+				content, err := os.ReadFile(filepath.Join(dataDir, plugininventory.SQliteDBFileName))
+				Expect(err).To(BeNil())
+				Expect(string(content)).To(Equal("inventory"))
+				Expect(filepath.Join(dataDir, plugininventory.SQliteDBFileName+".gz")).ToNot(BeAnExistingFile())
+			},
+			Entry("for an image with a compressed database", true),
+			Entry("for an image with an uncompressed database", false),
+		)
+	})
+	Describe("Confirmation of a digest change", func() {
+		var (
+			dataDir         string
+			imageOperations *refreshImageOperations
+			dbDiscovery     *DBBackedOCIDiscovery
+			prompts         int
+
+			defaultPromptForDigestChange = promptForDigestChange
+		)
+		BeforeEach(func() {
+			dataDir, err = os.MkdirTemp("", "cache")
+			Expect(err).To(BeNil())
+			os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, "test-image:latest")
+
+			imageOperations = &refreshImageOperations{image: "test-image:latest"}
+			newImageOperations = func(options ...carvelhelpers.ImageOperationOption) carvelhelpers.ImageOperationsImpl {
+				return imageOperations
+			}
+			prompts = 0
+			promptForDigestChange = func(msg string) (bool, error) {
+				prompts++
+				Expect(msg).To(ContainSubstring("has changed from sha256:1234 to sha256:5678"))
+				return true, nil
+			}
+
+			discovery := NewOCIDiscovery("test-discovery", "test-image:latest")
+			var ok bool
+			dbDiscovery, ok = discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			dbDiscovery.pluginDataDir = dataDir
+
+			// Fetch the inventory a first time, which never requires a confirmation
+			os.Setenv(constants.ConfigVariableConfirmDiscoveryDigestChange, "true")
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.downloads).To(Equal(1))
+			Expect(prompts).To(Equal(0))
+
+			imageOperations.digest = "5678"
+		})
+		AfterEach(func() {
+			newImageOperations = carvelhelpers.NewImageOperationsImpl
+			promptForDigestChange = defaultPromptForDigestChange
+			os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+			os.Unsetenv(constants.ConfigVariableConfirmDiscoveryDigestChange)
+			os.Unsetenv(constants.DiscoveryDigestChangePromptAnswer)
+			os.RemoveAll(dataDir)
+		})
+		It("should not ask for a confirmation unless enabled", func() {
+			os.Unsetenv(constants.ConfigVariableConfirmDiscoveryDigestChange)
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.downloads).To(Equal(2))
+			Expect(prompts).To(Equal(0))
+		})
+		It("should download the new inventory once the change is confirmed", func() {
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.downloads).To(Equal(2))
+			Expect(prompts).To(Equal(1))
+
+			// The new digest is now the cached one
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.downloads).To(Equal(2))
+			Expect(prompts).To(Equal(1))
+		})
+		It("should keep the cached inventory when the change is refused", func() {
+			os.Setenv(constants.DiscoveryDigestChangePromptAnswer, "No")
+			err = dbDiscovery.fetchInventoryImage()
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("from sha256:1234 to sha256:5678 was not confirmed"))
+			Expect(imageOperations.downloads).To(Equal(1))
+			Expect(prompts).To(Equal(0))
+
+			// The change must still be confirmed the next time
+			hashFiles, err := filepath.Glob(filepath.Join(dataDir, "digest.*"))
+			Expect(err).To(BeNil())
+			Expect(hashFiles).To(HaveLen(1))
+			Expect(hashFiles[0]).To(HaveSuffix(".1234"))
+
+			os.Setenv(constants.DiscoveryDigestChangePromptAnswer, "Yes")
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.downloads).To(Equal(2))
+		})
+	})
+	Describe("Discovery errors", func() {
+		var dataDir string
+		BeforeEach(func() {
+			dataDir, err = os.MkdirTemp("", "cache")
+			Expect(err).To(BeNil())
+		})
+		AfterEach(func() {
+			newImageOperations = carvelhelpers.NewImageOperationsImpl
+			os.RemoveAll(dataDir)
+		})
+		newDiscovery := func() *DBBackedOCIDiscovery {
+			discovery := NewOCIDiscovery("test-discovery", "test-image:latest")
+			dbDiscovery, ok := discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			dbDiscovery.pluginDataDir = dataDir
+			return dbDiscovery
+		}
+		It("should return an image resolution error with the name of the discovery", func() {
+			newImageOperations = func(options ...carvelhelpers.ImageOperationOption) carvelhelpers.ImageOperationsImpl {
+				return &failingImageOperations{message: "image not found"}
+			}
+
+			_, err := newDiscovery().List()
+			Expect(err).ToNot(BeNil())
+			// The message is unchanged
+			Expect(err.Error()).To(ContainSubstring("unable to fetch the inventory of discovery 'test-discovery' for plugins"))
+			Expect(err.Error()).To(ContainSubstring("image not found"))
+
+			var discoveryErr *DiscoveryError
+			Expect(errors.As(err, &discoveryErr)).To(BeTrue())
+			Expect(discoveryErr.Code).To(Equal(DiscoveryErrorImageResolution))
+			Expect(discoveryErr.Discovery).To(Equal("test-discovery"))
+
+			_, err = newDiscovery().GetGroups()
+			Expect(errors.Is(err, &DiscoveryError{Code: DiscoveryErrorImageResolution})).To(BeTrue())
+			Expect(errors.Is(err, &DiscoveryError{Code: DiscoveryErrorDownload})).To(BeFalse())
+		})
+		It("should return a cache expired error when the cache is too old", func() {
+			dbDiscovery := newDiscovery()
+			dbDiscovery.useLocalCacheOnly = true
+			dbDiscovery.maxCacheAge = time.Minute
+			digestFile := filepath.Join(dataDir, "digest.test")
+			Expect(os.WriteFile(digestFile, nil, 0644)).To(Succeed())
+			old := time.Now().Add(-time.Hour)
+			Expect(os.Chtimes(digestFile, old, old)).To(Succeed())
+
+			_, err := dbDiscovery.List()
+			Expect(err).ToNot(BeNil())
+			code, found := GetDiscoveryErrorCode(err)
+			Expect(found).To(BeTrue())
+			Expect(code).To(Equal(DiscoveryErrorCacheExpired))
+		})
+	})
+	Describe("Truncation of error messages", func() {
+		It("should keep a short error unchanged", func() {
+			err := errors.New("image not found")
+			Expect(truncateErrorMessage(err)).To(Equal(err))
+		})
+		It("should keep the beginning and the end of a huge error", func() {
+			msg := "HEAD" + strings.Repeat("x", 100*maxErrorMessageLength) + "TAIL"
+			truncated := truncateErrorMessage(errors.New(msg)).Error()
+
+			Expect(len(truncated)).To(BeNumerically("<", 2*maxErrorMessageLength))
+			Expect(truncated).To(HavePrefix("HEAD"))
+			Expect(truncated).To(HaveSuffix("TAIL"))
+			Expect(truncated).To(ContainSubstring(fmt.Sprintf("[%d characters truncated", len(msg)-maxErrorMessageLength)))
+		})
+		It("should truncate the error of a failed image resolution", func() {
+			newImageOperations = func(options ...carvelhelpers.ImageOperationOption) carvelhelpers.ImageOperationsImpl {
+				return &failingImageOperations{message: "unexpected response body: " + strings.Repeat("<html>", maxErrorMessageLength)}
+			}
+			defer func() { newImageOperations = carvelhelpers.NewImageOperationsImpl }()
+
+			discovery := NewOCIDiscovery("test-discovery", "test-image:latest")
+			dbDiscovery, ok := discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+
+			_, _, err := dbDiscovery.checkImageCache()
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(HavePrefix(`plugins discovery image resolution failed. Please check that the repository image URL "test-image:latest" is correct: unexpected response body: <html>`))
+			Expect(err.Error()).To(ContainSubstring("characters truncated"))
+			Expect(len(err.Error())).To(BeNumerically("<", 2*maxErrorMessageLength))
+		})
+	})
+	Describe("Generation marker", func() {
+		var (
+			dataDir         string
+			imageOperations *refreshImageOperations
+			dbDiscovery     *DBBackedOCIDiscovery
+			generation      string
+			markerErr       error
+
+			defaultFetchGenerationMarker = fetchGenerationMarker
+		)
+		BeforeEach(func() {
+			dataDir, err = os.MkdirTemp("", "cache")
+			Expect(err).To(BeNil())
+			os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, "test-image:latest")
+
+			imageOperations = &refreshImageOperations{image: "test-image:latest"}
+			newImageOperations = func(options ...carvelhelpers.ImageOperationOption) carvelhelpers.ImageOperationsImpl {
+				return imageOperations
+			}
+			generation = "1"
+			markerErr = nil
+			fetchGenerationMarker = func(url string) (string, error) {
+				Expect(url).To(Equal("https://example.com/generation"))
+				return generation, markerErr
+			}
+
+			discovery := NewOCIDiscovery("test-discovery", "test-image:latest")
+			var ok bool
+			dbDiscovery, ok = discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			dbDiscovery.pluginDataDir = dataDir
+			dbDiscovery.generationMarkerURL = "https://example.com/generation"
+
+			// The first fetch always resolves the digest of the image
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.resolutions).To(Equal(1))
+			Expect(imageOperations.downloads).To(Equal(1))
+		})
+		AfterEach(func() {
+			newImageOperations = carvelhelpers.NewImageOperationsImpl
+			fetchGenerationMarker = defaultFetchGenerationMarker
+			os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+			os.RemoveAll(dataDir)
+		})
+		It("should not resolve the digest of the image while the marker is unchanged", func() {
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.resolutions).To(Equal(1))
+			Expect(imageOperations.downloads).To(Equal(1))
+		})
+		It("should resolve the digest of the image once the marker changes", func() {
+			generation = "2"
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.resolutions).To(Equal(2))
+			// The digest of the image has not changed
+			Expect(imageOperations.downloads).To(Equal(1))
+
+			imageOperations.digest = "5678"
+			generation = "3"
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.resolutions).To(Equal(3))
+			Expect(imageOperations.downloads).To(Equal(2))
+
+			// The new generation is now the cached one
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.resolutions).To(Equal(3))
+		})
+		It("should resolve the digest of the image when the marker cannot be read", func() {
+			markerErr = errors.New("not found")
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.resolutions).To(Equal(2))
+
+			// The cached generation was forgotten
+			markerErr = nil
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.resolutions).To(Equal(3))
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.resolutions).To(Equal(3))
+		})
+		It("should resolve the digest of the image when the inventory is missing from the cache", func() {
+			Expect(os.Remove(filepath.Join(dataDir, plugininventory.SQliteDBFileName))).To(Succeed())
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.resolutions).To(Equal(2))
+			Expect(imageOperations.downloads).To(Equal(2))
+		})
+		It("should always resolve the digest of the image without a marker", func() {
+			dbDiscovery.generationMarkerURL = ""
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.resolutions).To(Equal(3))
+			Expect(imageOperations.downloads).To(Equal(1))
+		})
+	})
+	Describe("Interrupted download", func() {
+		var (
+			dataDir         string
+			imageOperations *interruptedImageOperations
+		)
+		BeforeEach(func() {
+			dataDir, err = os.MkdirTemp("", "cache")
+			Expect(err).To(BeNil())
+			os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, "test-image:latest")
+
+			imageOperations = &interruptedImageOperations{}
+			newImageOperations = func(options ...carvelhelpers.ImageOperationOption) carvelhelpers.ImageOperationsImpl {
+				return imageOperations
+			}
+		})
+		AfterEach(func() {
+			newImageOperations = carvelhelpers.NewImageOperationsImpl
+			interrupt.Reset()
+			os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+			os.RemoveAll(dataDir)
+		})
+		It("should clean up the temporary files and not write any digest file", func() {
+			discovery := NewOCIDiscovery("test-discovery", "test-image:latest")
+			dbDiscovery, ok := discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			dbDiscovery.pluginDataDir = dataDir
+
+			err = dbDiscovery.fetchInventoryImage()
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("download canceled"))
+			Expect(interrupt.Interrupted()).To(BeTrue())
+
+			Expect(imageOperations.downloadDir).ToNot(BeEmpty())
+			Expect(imageOperations.downloadDir).ToNot(BeADirectory())
+
+			matches, err := filepath.Glob(filepath.Join(dataDir, "*digest.*"))
+			Expect(err).To(BeNil())
+			Expect(matches).To(BeEmpty())
+			Expect(filepath.Join(dataDir, plugininventory.SQliteDBFileName)).ToNot(BeAnExistingFile())
+		})
+	})
+	Describe("Plugin inventory metadata image", func() {
+		// The image is accepted by the registry but cannot be parsed to derive its metadata image
+		const invalidImage = "invalid-inventory-image$#"
+		var (
+			dataDir         string
+			imageOperations *refreshImageOperations
+		)
+		BeforeEach(func() {
+			dataDir, err = os.MkdirTemp("", "cache")
+			Expect(err).To(BeNil())
+
+			imageOperations = &refreshImageOperations{image: invalidImage}
+			newImageOperations = func(options ...carvelhelpers.ImageOperationOption) carvelhelpers.ImageOperationsImpl {
+				return imageOperations
+			}
+		})
+		AfterEach(func() {
+			newImageOperations = carvelhelpers.NewImageOperationsImpl
+			os.RemoveAll(dataDir)
+		})
+		It("should derive the metadata image of a valid image", func() {
+			dbDiscovery := newDBBackedOCIDiscovery("test-discovery", "test-image:latest")
+			metadataImage, err := dbDiscovery.getMetadataImage()
+			Expect(err).To(BeNil())
+			Expect(metadataImage).To(Equal("test-image-metadata:latest"))
+		})
+		It("should fail to check the cache if the metadata image cannot be derived", func() {
+			dbDiscovery := newDBBackedOCIDiscovery("test-discovery", invalidImage)
+			dbDiscovery.pluginDataDir = dataDir
+
+			_, _, err := dbDiscovery.checkImageCache()
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(`the discovery image "invalid-inventory-image$#" is valid but the corresponding plugin inventory metadata image cannot be determined`))
+			Expect(err.Error()).To(ContainSubstring("invalid image"))
+		})
+		It("should fail to download the inventory if the metadata image cannot be derived", func() {
+			dbDiscovery := newDBBackedOCIDiscovery("test-discovery", invalidImage)
+			dbDiscovery.pluginDataDir = dataDir
+
+			err := dbDiscovery.downloadInventoryDatabase()
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("the corresponding plugin inventory metadata image cannot be determined"))
+			Expect(imageOperations.downloads).To(Equal(1))
+			Expect(filepath.Join(dataDir, plugininventory.SQliteDBFileName)).ToNot(BeAnExistingFile())
+		})
+	})
+	Describe("Listing the plugins of the inventory", func() {
+		It("should build the discovered plugins with their sorted versions", func() {
+			discovery := NewOCIDiscovery("test-discovery", "test-image:latest")
+			dbDiscovery, ok := discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			dbDiscovery.inventory = newLargeInventory(3, 12)
+
+			plugins, err := dbDiscovery.listPluginsFromInventory()
+			Expect(err).To(BeNil())
+			Expect(plugins).To(HaveLen(3))
+			for i := range plugins {
+				Expect(plugins[i].Name).To(Equal(fmt.Sprintf("plugin%d", i)))
+				Expect(plugins[i].Source).To(Equal("test-discovery"))
+				Expect(plugins[i].Scope).To(Equal(common.PluginScopeStandalone))
+				Expect(plugins[i].Status).To(Equal(common.PluginStatusNotInstalled))
+				Expect(plugins[i].RecommendedVersion).To(Equal("v0.1.0"))
+				Expect(plugins[i].SupportedVersions).To(HaveLen(12))
+				Expect(plugins[i].SupportedVersions[0]).To(Equal("v0.0.0"))
+				Expect(plugins[i].SupportedVersions[2]).To(Equal("v0.2.0"))
+				Expect(plugins[i].SupportedVersions[11]).To(Equal("v1.1.0"))
+			}
+		})
+		It("should return no plugins for an empty inventory", func() {
+			discovery := NewOCIDiscovery("test-discovery", "test-image:latest")
+			dbDiscovery, ok := discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			dbDiscovery.inventory = &staticInventory{}
+
+			plugins, err := dbDiscovery.listPluginsFromInventory()
+			Expect(err).To(BeNil())
+			Expect(plugins).To(BeNil())
+		})
+	})
+	Describe("Recommended version fallback", func() {
+		var (
+			dataDir     string
+			dbDiscovery *DBBackedOCIDiscovery
+		)
+		BeforeEach(func() {
+			dataDir, err = os.MkdirTemp("", "cache")
+			Expect(err).To(BeNil())
+
+			dbDiscovery = NewOCIDiscovery("test-discovery", "test-image:latest").(*DBBackedOCIDiscovery)
+			dbDiscovery.pluginDataDir = dataDir
+		})
+		AfterEach(func() {
+			os.Unsetenv(constants.ConfigVariablePluginRecommendedVersionFallback)
+			os.RemoveAll(dataDir)
+		})
+		It("should only fall back to an available version for an air-gapped inventory by default", func() {
+			Expect(dbDiscovery.isAirgappedInventory()).To(BeFalse())
+			Expect(dbDiscovery.getPluginInventoryFilter().FallbackToAvailableVersion).To(BeFalse())
+
+			dbDiscovery.createHashFiles("", dbDiscovery.checkDigestFileExistence("", "metadata."))
+			Expect(dbDiscovery.isAirgappedInventory()).To(BeFalse())
+
+			dbDiscovery.createHashFiles("", dbDiscovery.checkDigestFileExistence("5678", "metadata."))
+			Expect(dbDiscovery.isAirgappedInventory()).To(BeTrue())
+			Expect(dbDiscovery.getPluginInventoryFilter().FallbackToAvailableVersion).To(BeTrue())
+
+			os.Setenv(constants.ConfigVariablePluginRecommendedVersionFallback, "false")
+			Expect(dbDiscovery.getPluginInventoryFilter().FallbackToAvailableVersion).To(BeFalse())
+		})
+		It("should recommend the available version selected by the inventory", func() {
+			inventory := newLargeInventory(1, 3)
+			inventory.plugins[0].RecommendedVersion = "v0.1.0"
+			inventory.plugins[0].UnavailableRecommendedVersion = "v0.3.0"
+			dbDiscovery.inventory = inventory
+			dbDiscovery.pluginCriteria = &PluginDiscoveryCriteria{Name: "plugin0"}
+
+			plugins, err := dbDiscovery.listPluginsFromInventory()
+			Expect(err).To(BeNil())
+			Expect(plugins).To(HaveLen(1))
+			Expect(plugins[0].RecommendedVersion).To(Equal("v0.1.0"))
+		})
+	})
+	Describe("Maximum age of the cache", func() {
+		var (
+			dataDir     string
+			dbDiscovery *DBBackedOCIDiscovery
+			hashFile    string
+		)
+		BeforeEach(func() {
+			dataDir, err = os.MkdirTemp("", "cache")
+			Expect(err).To(BeNil())
+
+			discovery := NewOCIDiscovery("test-discovery", "test-image:latest", WithUseLocalCacheOnly())
+			var ok bool
+			dbDiscovery, ok = discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			dbDiscovery.pluginDataDir = dataDir
+			dbDiscovery.inventory = plugininventory.NewSQLiteInventory(filepath.Join(dataDir, plugininventory.SQliteDBFileName), "")
+
+			hashFile = dbDiscovery.checkDigestFileExistence("1234", "")
+			_, err = os.Create(hashFile)
+			Expect(err).To(BeNil())
+			lastRefresh := time.Now().Add(-48 * time.Hour)
+			Expect(os.Chtimes(hashFile, lastRefresh, lastRefresh)).To(Succeed())
+		})
+		AfterEach(func() {
+			os.Unsetenv(constants.ConfigVariablePluginDiscoveryMaxCacheAge)
+			os.RemoveAll(dataDir)
+		})
+		It("should read the maximum age from the environment variable", func() {
+			Expect(dbDiscovery.maxCacheAge).To(Equal(time.Duration(0)))
+
+			os.Setenv(constants.ConfigVariablePluginDiscoveryMaxCacheAge, "24h")
+			discovery := NewOCIDiscovery("test-discovery", "test-image:latest")
+			Expect(discovery.(*DBBackedOCIDiscovery).maxCacheAge).To(Equal(24 * time.Hour))
+
+			os.Setenv(constants.ConfigVariablePluginDiscoveryMaxCacheAge, "one day")
+			discovery = NewOCIDiscovery("test-discovery", "test-image:latest")
+			Expect(discovery.(*DBBackedOCIDiscovery).maxCacheAge).To(Equal(time.Duration(0)))
+		})
+		It("should not limit the age of the cache by default", func() {
+			_, err = dbDiscovery.List()
+			Expect(err).To(BeNil())
+		})
+		It("should accept a cache younger than the maximum age", func() {
+			dbDiscovery.maxCacheAge = 72 * time.Hour
+			_, err = dbDiscovery.List()
+			Expect(err).To(BeNil())
+		})
+		It("should refuse a cache older than the maximum age", func() {
+			dbDiscovery.maxCacheAge = 24 * time.Hour
+			_, err = dbDiscovery.List()
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("the cached inventory of discovery 'test-discovery' was last refreshed 48h0m0s ago"))
+			_, err = dbDiscovery.GetGroups()
+			Expect(err).ToNot(BeNil())
+		})
+		It("should consider the cache refreshed when it is found up-to-date", func() {
+			dbDiscovery.maxCacheAge = 24 * time.Hour
+			_, err = os.Create(filepath.Join(dataDir, plugininventory.SQliteDBFileName))
+			Expect(err).To(BeNil())
+			Expect(dbDiscovery.checkDigestFileExistence("1234", "")).To(BeEmpty())
+			Expect(dbDiscovery.checkCacheAge()).To(Succeed())
+		})
+		It("should only consider the digest file of the discovery", func() {
+			dbDiscovery.maxCacheAge = 24 * time.Hour
+			Expect(os.Remove(hashFile)).To(Succeed())
+
+			// The old digest file of another image sharing the cache directory is ignored
+			otherHashFile := filepath.Join(dataDir, "digest.000000000000.5678")
+			_, err = os.Create(otherHashFile)
+			Expect(err).To(BeNil())
+			lastRefresh := time.Now().Add(-48 * time.Hour)
+			Expect(os.Chtimes(otherHashFile, lastRefresh, lastRefresh)).To(Succeed())
+			Expect(dbDiscovery.checkCacheAge()).To(Succeed())
+
+			// Whereas the recent digest file of the discovery is used
+			hashFile = dbDiscovery.checkDigestFileExistence("1234", "")
+			_, err = os.Create(hashFile)
+			Expect(err).To(BeNil())
+			Expect(dbDiscovery.checkCacheAge()).To(Succeed())
+		})
+	})
+	Describe("Registry credentials", func() {
+		AfterEach(func() {
+			os.Unsetenv(constants.ConfigVariablePluginDiscoveryUsernamePrefix + "MY_DISCOVERY")
+			os.Unsetenv(constants.ConfigVariablePluginDiscoveryPasswordPrefix + "MY_DISCOVERY")
+			os.Unsetenv(constants.ConfigVariablePluginDiscoveryCredentialHelperPrefix + "MY_DISCOVERY")
+			os.Unsetenv(constants.ConfigVariableRegistryCredentialHelperPrefix + "REGISTRY_EXAMPLE_COM")
+		})
+		It("should access the registry anonymously when no credentials are configured", func() {
+			discovery := NewOCIDiscovery("my-discovery", "test-image:latest")
+			dbDiscovery, ok := discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			Expect(dbDiscovery.credentials).To(BeNil())
+		})
+		It("should use the credentials configured for the matching discovery only", func() {
+			os.Setenv(constants.ConfigVariablePluginDiscoveryUsernamePrefix+"MY_DISCOVERY", "user")
+			os.Setenv(constants.ConfigVariablePluginDiscoveryPasswordPrefix+"MY_DISCOVERY", "secret")
+
+			discovery := NewOCIDiscovery("my-discovery", "test-image:latest")
+			dbDiscovery, ok := discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			Expect(dbDiscovery.credentials).ToNot(BeNil())
+			Expect(dbDiscovery.credentials.Username).To(Equal("user"))
+			Expect(dbDiscovery.credentials.Password).To(Equal("secret"))
+			Expect(dbDiscovery.credentials.String()).ToNot(ContainSubstring("secret"))
+
+			discovery = NewOCIGroupDiscovery("default", "test-image:latest")
+			dbDiscovery, ok = discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			Expect(dbDiscovery.credentials).To(BeNil())
+		})
+		It("should use the credential helper configured for the discovery", func() {
+			os.Setenv(constants.ConfigVariablePluginDiscoveryCredentialHelperPrefix+"MY_DISCOVERY", "ecr-login")
+
+			discovery := NewOCIGroupDiscovery("my.discovery", "test-image:latest")
+			dbDiscovery, ok := discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			Expect(dbDiscovery.credentials).ToNot(BeNil())
+			Expect(dbDiscovery.credentials.CredentialHelper).To(Equal("ecr-login"))
+		})
+		It("should use the credentials saved by the login for the registry of the discovery", func() {
+			os.Setenv(constants.ConfigVariableRegistryCredentialHelperPrefix+"REGISTRY_EXAMPLE_COM", "osxkeychain")
+
+			discovery := NewOCIDiscovery("default", "registry.example.com/test-image:latest")
+			dbDiscovery, ok := discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			Expect(dbDiscovery.credentials).ToNot(BeNil())
+			Expect(dbDiscovery.credentials.LoginCredentials).To(BeTrue())
+			Expect(dbDiscovery.credentials.CredentialHelper).To(BeEmpty())
+
+			// The credentials saved for another registry are not used
+			discovery = NewOCIDiscovery("default", "other.example.com/test-image:latest")
+			dbDiscovery, ok = discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			Expect(dbDiscovery.credentials).To(BeNil())
+
+			// The credentials of the discovery take precedence
+			os.Setenv(constants.ConfigVariablePluginDiscoveryUsernamePrefix+"MY_DISCOVERY", "user")
+			os.Setenv(constants.ConfigVariablePluginDiscoveryPasswordPrefix+"MY_DISCOVERY", "secret")
+			discovery = NewOCIDiscovery("my-discovery", "registry.example.com/test-image:latest")
+			dbDiscovery, ok = discovery.(*DBBackedOCIDiscovery)
+			Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			Expect(dbDiscovery.credentials.Username).To(Equal("user"))
+			Expect(dbDiscovery.credentials.LoginCredentials).To(BeFalse())
+		})
+	})
 })
+
+func BenchmarkListPluginsFromInventory(b *testing.B) {
+	discovery := NewOCIDiscovery("test-discovery", "test-image:latest")
+	dbDiscovery := discovery.(*DBBackedOCIDiscovery)
+	dbDiscovery.inventory = newLargeInventory(1000, 50)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := dbDiscovery.listPluginsFromInventory(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// newBenchmarkDBDiscovery returns a discovery whose cached inventory is an SQLite database
+// of the specified number of plugins and versions.  The query results are only cached
+// if withQueryCache is set, as the inventory otherwise has no digest.
+func newBenchmarkDBDiscovery(b *testing.B, pluginCount, versionCount int, withQueryCache bool) *DBBackedOCIDiscovery {
+	dataDir, err := os.MkdirTemp("", "cache")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { os.RemoveAll(dataDir) })
+
+	dbFile := filepath.Join(dataDir, plugininventory.SQliteDBFileName)
+	inventory := plugininventory.NewSQLiteInventory(dbFile, "")
+	if err := inventory.CreateSchema(); err != nil {
+		b.Fatal(err)
+	}
+	for _, entry := range newLargeInventory(pluginCount, versionCount).plugins {
+		if err := inventory.InsertPlugin(entry); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	dbDiscovery := NewOCIDiscovery("test-discovery", "test-image:latest").(*DBBackedOCIDiscovery)
+	dbDiscovery.pluginDataDir = dataDir
+	dbDiscovery.inventory = inventory
+	if withQueryCache {
+		if err := os.WriteFile(filepath.Join(dataDir, "digest."+dbDiscovery.identityHash()+".1234"), nil, 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return dbDiscovery
+}
+
+// benchmarkListPluginsFromDB lists the plugins of the SQLite inventory of the discovery
+func benchmarkListPluginsFromDB(b *testing.B, dbDiscovery *DBBackedOCIDiscovery) {
+	dbDiscovery.pluginCriteria = &PluginDiscoveryCriteria{Target: configtypes.TargetK8s}
+	// Warm the query cache, if used
+	if _, err := dbDiscovery.listPluginsFromInventory(); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := dbDiscovery.listPluginsFromInventory(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkListPluginsFromDB(b *testing.B) {
+	benchmarkListPluginsFromDB(b, newBenchmarkDBDiscovery(b, 200, 20, false))
+}
+
+func BenchmarkListPluginsFromDBWarmQueryCache(b *testing.B) {
+	benchmarkListPluginsFromDB(b, newBenchmarkDBDiscovery(b, 200, 20, true))
+}
@@ -0,0 +1,204 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/buildinfo"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+const (
+	// queryCacheDirName is the directory of the cache of an inventory holding
+	// the results of the plugin queries run against that inventory
+	queryCacheDirName = "queries"
+	// maxQueryCacheEntries is the maximum number of query results cached for an inventory
+	maxQueryCacheEntries = 32
+)
+
+// queryCacheFormat is part of the key of every cached query result so that the results
+// cached by another version of the CLI, which may query the inventory differently, or
+// with another definition of the plugin entries are never read
+var queryCacheFormat = buildinfo.Version + "\n" + typeSchema(reflect.TypeOf(plugininventory.PluginInventoryEntry{}), map[reflect.Type]bool{})
+
+// typeSchema describes the type along with its fields, recursively, so that
+// the description changes whenever the definition of the type changes
+func typeSchema(t reflect.Type, described map[reflect.Type]bool) string {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return t.Kind().String() + " " + typeSchema(t.Elem(), described)
+	case reflect.Map:
+		return "map[" + typeSchema(t.Key(), described) + "]" + typeSchema(t.Elem(), described)
+	case reflect.Struct:
+		if described[t] {
+			return t.String()
+		}
+		described[t] = true
+		var b strings.Builder
+		b.WriteString("struct{")
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			fmt.Fprintf(&b, "%s %s %q;", field.Name, typeSchema(field.Type, described), field.Tag)
+		}
+		b.WriteString("}")
+		return b.String()
+	}
+	return t.String()
+}
+
+// getQueryCacheFile returns the file caching the result of the plugin query using the
+// specified filter against the cached inventory of the discovery.  The directory of the
+// file depends on the digests of the inventory image and of its metadata image, so that
+// the cached results are never used once the inventory has changed.  Empty strings are
+// returned if the cache does not contain an inventory of the discovery.
+func (od *DBBackedOCIDiscovery) getQueryCacheFile(filter *plugininventory.PluginInventoryFilter) (dir, file string) {
+	hashFile := od.getCachedInventoryHashFile()
+	if hashFile == "" {
+		return "", ""
+	}
+	digests := getDigestFromHashFile(hashFile)
+	if matches, _ := filepath.Glob(filepath.Join(od.pluginDataDir, "metadata.digest."+od.identityHash()+".*")); len(matches) == 1 {
+		digests += "." + getDigestFromHashFile(matches[0])
+	}
+
+	criteria, err := json.Marshal(filter)
+	if err != nil {
+		return "", ""
+	}
+	digestHash := sha256.Sum256([]byte(digests))
+	criteriaHash := sha256.Sum256([]byte(queryCacheFormat + "\n" + string(criteria)))
+
+	dir = filepath.Join(od.pluginDataDir, queryCacheDirName, hex.EncodeToString(digestHash[:]))
+	return dir, filepath.Join(dir, hex.EncodeToString(criteriaHash[:])+".json")
+}
+
+// getCachedQueryResult returns the cached result of the plugin query using the specified
+// filter.  The returned boolean is false if the result is not cached.
+func (od *DBBackedOCIDiscovery) getCachedQueryResult(filter *plugininventory.PluginInventoryFilter) ([]*plugininventory.PluginInventoryEntry, bool) {
+	_, file := od.getQueryCacheFile(filter)
+	if file == "" {
+		return nil, false
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, false
+	}
+	var entries []*plugininventory.PluginInventoryEntry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		log.V(4).Infof("Ignoring the invalid cached query result %q of discovery '%s': %v", filepath.Base(file), od.Name(), err)
+		_ = os.Remove(file)
+		return nil, false
+	}
+
+	// The least recently used results are evicted first
+	now := time.Now()
+	_ = os.Chtimes(file, now, now)
+	return entries, true
+}
+
+// saveQueryResult caches the result of the plugin query using the specified filter.
+// The results cached for any previous inventory of the discovery are removed, and the
+// least recently used results are evicted to keep at most maxQueryCacheEntries results.
+// Failing to cache a result only makes the next identical query slower, so it is not an error.
+func (od *DBBackedOCIDiscovery) saveQueryResult(filter *plugininventory.PluginInventoryFilter, entries []*plugininventory.PluginInventoryEntry) {
+	dir, file := od.getQueryCacheFile(filter)
+	if file == "" {
+		return
+	}
+	if err := writeQueryCacheFile(dir, file, entries); err != nil {
+		log.V(4).Infof("Unable to cache the query result of discovery '%s': %v", od.Name(), err)
+		return
+	}
+
+	// Remove the results cached for the previous inventories
+	if matches, _ := filepath.Glob(filepath.Join(od.pluginDataDir, queryCacheDirName, "*")); len(matches) > 1 {
+		for _, match := range matches {
+			if filepath.Clean(match) != filepath.Clean(dir) {
+				_ = os.RemoveAll(match)
+			}
+		}
+	}
+	pruneQueryCache(dir, maxQueryCacheEntries)
+}
+
+// writeQueryCacheFile writes the query result to a temporary file first
+// so that a partially written result is never found in the cache
+func writeQueryCacheFile(dir, file string, entries []*plugininventory.PluginInventoryEntry) error {
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrap(err, "unable to create the query cache")
+	}
+
+	tmpFile, err := os.CreateTemp(dir, filepath.Base(file)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmpFile.Write(b)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), file)
+	}
+	if err != nil {
+		_ = os.Remove(tmpFile.Name())
+	}
+	return err
+}
+
+// pruneQueryCache evicts the least recently used query results of the directory
+// until at most maxEntries results remain
+func pruneQueryCache(dir string, maxEntries int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	type cachedResult struct {
+		file     string
+		lastUsed time.Time
+	}
+	var results []cachedResult
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		results = append(results, cachedResult{file: filepath.Join(dir, entry.Name()), lastUsed: info.ModTime()})
+	}
+	if len(results) <= maxEntries {
+		return
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].lastUsed.Before(results[j].lastUsed)
+	})
+	for _, result := range results[:len(results)-maxEntries] {
+		_ = os.Remove(result.file)
+	}
+}
+
+// invalidateQueryCache removes all the cached query results of the discovery
+func (od *DBBackedOCIDiscovery) invalidateQueryCache() {
+	_ = os.RemoveAll(filepath.Join(od.pluginDataDir, queryCacheDirName))
+}
@@ -0,0 +1,204 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/buildinfo"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+// countingInventory is an inventory counting the plugin queries it receives
+type countingInventory struct {
+	staticInventory
+	queries int
+}
+
+func (c *countingInventory) GetPlugins(filter *plugininventory.PluginInventoryFilter) ([]*plugininventory.PluginInventoryEntry, error) {
+	c.queries++
+	return c.staticInventory.GetPlugins(filter)
+}
+
+var _ = Describe("Cache of the plugin query results", func() {
+	var (
+		dataDir     string
+		inventory   *countingInventory
+		dbDiscovery *DBBackedOCIDiscovery
+		err         error
+	)
+
+	// setDigest records the inventory of the discovery as cached with the specified digest
+	setDigest := func(digest string) {
+		matches, _ := filepath.Glob(filepath.Join(dataDir, "digest.*"))
+		for _, match := range matches {
+			Expect(os.Remove(match)).To(Succeed())
+		}
+		Expect(os.WriteFile(filepath.Join(dataDir, "digest."+dbDiscovery.identityHash()+"."+digest), nil, 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		dataDir, err = os.MkdirTemp("", "cache")
+		Expect(err).To(BeNil())
+		Expect(os.WriteFile(filepath.Join(dataDir, plugininventory.SQliteDBFileName), []byte("db"), 0644)).To(Succeed())
+
+		inventory = &countingInventory{staticInventory: *newLargeInventory(3, 2)}
+		dbDiscovery = NewOCIDiscovery("test-discovery", "test-image:latest").(*DBBackedOCIDiscovery)
+		dbDiscovery.pluginDataDir = dataDir
+		dbDiscovery.inventory = inventory
+		setDigest("1234")
+	})
+	AfterEach(func() {
+		os.RemoveAll(dataDir)
+	})
+
+	It("should not query the inventory again for the same criteria", func() {
+		plugins, err := dbDiscovery.listPluginsFromInventory()
+		Expect(err).To(BeNil())
+		Expect(plugins).To(HaveLen(3))
+		Expect(inventory.queries).To(Equal(1))
+
+		cachedPlugins, err := dbDiscovery.listPluginsFromInventory()
+		Expect(err).To(BeNil())
+		Expect(inventory.queries).To(Equal(1))
+		Expect(cachedPlugins).To(HaveLen(3))
+		for i := range plugins {
+			Expect(cachedPlugins[i].Name).To(Equal(plugins[i].Name))
+			Expect(cachedPlugins[i].Target).To(Equal(plugins[i].Target))
+			Expect(cachedPlugins[i].SupportedVersions).To(Equal(plugins[i].SupportedVersions))
+			Expect(cachedPlugins[i].Distribution).To(Equal(plugins[i].Distribution))
+			Expect(cachedPlugins[i].Source).To(Equal("test-discovery"))
+		}
+	})
+	It("should query the inventory for different criteria", func() {
+		_, err = dbDiscovery.listPluginsFromInventory()
+		Expect(err).To(BeNil())
+
+		dbDiscovery.pluginCriteria = &PluginDiscoveryCriteria{Target: configtypes.TargetK8s}
+		_, err = dbDiscovery.listPluginsFromInventory()
+		Expect(err).To(BeNil())
+		Expect(inventory.queries).To(Equal(2))
+
+		dbDiscovery.pluginCriteria = &PluginDiscoveryCriteria{Target: configtypes.TargetTMC}
+		_, err = dbDiscovery.listPluginsFromInventory()
+		Expect(err).To(BeNil())
+		Expect(inventory.queries).To(Equal(3))
+
+		// All the results are cached
+		dbDiscovery.pluginCriteria = &PluginDiscoveryCriteria{Target: configtypes.TargetK8s}
+		_, err = dbDiscovery.listPluginsFromInventory()
+		Expect(err).To(BeNil())
+		dbDiscovery.pluginCriteria = nil
+		_, err = dbDiscovery.listPluginsFromInventory()
+		Expect(err).To(BeNil())
+		Expect(inventory.queries).To(Equal(3))
+	})
+	It("should query the inventory again once its digest has changed", func() {
+		_, err = dbDiscovery.listPluginsFromInventory()
+		Expect(err).To(BeNil())
+
+		setDigest("5678")
+		_, err = dbDiscovery.listPluginsFromInventory()
+		Expect(err).To(BeNil())
+		Expect(inventory.queries).To(Equal(2))
+
+		// Only the results of the current inventory are kept
+		matches, err := filepath.Glob(filepath.Join(dataDir, queryCacheDirName, "*"))
+		Expect(err).To(BeNil())
+		Expect(matches).To(HaveLen(1))
+	})
+	It("should not cache the results without a cached inventory", func() {
+		Expect(os.Remove(filepath.Join(dataDir, plugininventory.SQliteDBFileName))).To(Succeed())
+
+		_, err = dbDiscovery.listPluginsFromInventory()
+		Expect(err).To(BeNil())
+		_, err = dbDiscovery.listPluginsFromInventory()
+		Expect(err).To(BeNil())
+		Expect(inventory.queries).To(Equal(2))
+		Expect(filepath.Join(dataDir, queryCacheDirName)).ToNot(BeADirectory())
+	})
+	It("should ignore an invalid cached result", func() {
+		_, err = dbDiscovery.listPluginsFromInventory()
+		Expect(err).To(BeNil())
+
+		_, file := dbDiscovery.getQueryCacheFile(dbDiscovery.getPluginInventoryFilter())
+		Expect(os.WriteFile(file, []byte("invalid"), 0644)).To(Succeed())
+		plugins, err := dbDiscovery.listPluginsFromInventory()
+		Expect(err).To(BeNil())
+		Expect(plugins).To(HaveLen(3))
+		Expect(inventory.queries).To(Equal(2))
+	})
+	It("should remove the cached results when the cache is invalidated", func() {
+		_, err = dbDiscovery.listPluginsFromInventory()
+		Expect(err).To(BeNil())
+		Expect(filepath.Join(dataDir, queryCacheDirName)).To(BeADirectory())
+
+		dbDiscovery.invalidateCache()
+		Expect(filepath.Join(dataDir, queryCacheDirName)).ToNot(BeADirectory())
+	})
+	It("should not read the results cached in another format", func() {
+		_, err = dbDiscovery.listPluginsFromInventory()
+		Expect(err).To(BeNil())
+
+		format := queryCacheFormat
+		queryCacheFormat += "\nchanged"
+		defer func() { queryCacheFormat = format }()
+		_, err = dbDiscovery.listPluginsFromInventory()
+		Expect(err).To(BeNil())
+		Expect(inventory.queries).To(Equal(2))
+	})
+	It("should change the format when the plugin entries or the CLI version change", func() {
+		Expect(queryCacheFormat).To(HavePrefix(buildinfo.Version + "\n"))
+		Expect(queryCacheFormat).To(ContainSubstring("UnavailableRecommendedVersion string"))
+
+		type version struct {
+			Version string
+		}
+		type entry struct {
+			Name     string
+			Versions []*version
+		}
+		type entryWithNewField struct {
+			Name     string
+			Versions []*version
+			URL      string
+		}
+		type entryWithNewType struct {
+			Name     int
+			Versions []*version
+		}
+		schema := typeSchema(reflect.TypeOf(entry{}), map[reflect.Type]bool{})
+		Expect(typeSchema(reflect.TypeOf(entry{}), map[reflect.Type]bool{})).To(Equal(schema))
+		Expect(typeSchema(reflect.TypeOf(entryWithNewField{}), map[reflect.Type]bool{})).ToNot(Equal(schema))
+		Expect(typeSchema(reflect.TypeOf(entryWithNewType{}), map[reflect.Type]bool{})).ToNot(Equal(schema))
+	})
+	It("should evict the least recently used results", func() {
+		dir := filepath.Join(dataDir, "results")
+		Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+		now := time.Now()
+		for i := 0; i < 5; i++ {
+			file := filepath.Join(dir, fmt.Sprintf("result%d.json", i))
+			Expect(os.WriteFile(file, []byte("[]"), 0644)).To(Succeed())
+			lastUsed := now.Add(time.Duration(i-5) * time.Hour)
+			Expect(os.Chtimes(file, lastUsed, lastUsed)).To(Succeed())
+		}
+
+		pruneQueryCache(dir, 3)
+		matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		Expect(err).To(BeNil())
+		Expect(matches).To(ConsistOf(
+			filepath.Join(dir, "result2.json"),
+			filepath.Join(dir, "result3.json"),
+			filepath.Join(dir, "result4.json"),
+		))
+	})
+})
@@ -0,0 +1,249 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+const (
+	// defaultRetainedInventories is the number of inventory databases of a discovery
+	// kept in the cache by default, which is only the current one
+	defaultRetainedInventories = 1
+	// maxRetainedInventories bounds the number of inventory databases of a discovery
+	// kept in the cache, as each one can be large
+	maxRetainedInventories = 5
+	// retainedInventoriesFileName is the file of the cache directory of a discovery
+	// mapping the digests of the retained inventories to their database file
+	retainedInventoriesFileName = "retained_inventories.json"
+)
+
+// retainedInventory describes an inventory database kept in the cache directory of a
+// discovery so that it can be used again without being downloaded
+type retainedInventory struct {
+	// Identity is the identityHash() of the discovery the inventory was fetched by
+	Identity string `json:"identity"`
+	// Digest is the digest of the inventory image
+	Digest string `json:"digest"`
+	// MetadataDigest is the digest of the metadata image, "none" if there is none
+	MetadataDigest string `json:"metadataDigest"`
+	// File is the name of the database file within the cache directory
+	File string `json:"file"`
+	// LastUsed is the last time the inventory was downloaded or restored
+	LastUsed time.Time `json:"lastUsed"`
+	// Uses is the number of times the inventory was downloaded or restored
+	Uses int `json:"uses"`
+}
+
+// getRetainedInventoriesLimit returns the number of inventory databases of a discovery
+// to keep in the cache, as configured by TANZU_CLI_PLUGIN_DISCOVERY_RETAINED_INVENTORIES
+func getRetainedInventoriesLimit() int {
+	value := strings.TrimSpace(os.Getenv(constants.ConfigVariablePluginDiscoveryRetainedInventories))
+	if value == "" {
+		return defaultRetainedInventories
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		log.Warningf("Ignoring the invalid value %q of %s, a number between 1 and %d is expected", value, constants.ConfigVariablePluginDiscoveryRetainedInventories, maxRetainedInventories)
+		return defaultRetainedInventories
+	}
+	if limit > maxRetainedInventories {
+		log.V(4).Infof("Only retaining %d plugin inventories per discovery instead of the requested %d", maxRetainedInventories, limit)
+		return maxRetainedInventories
+	}
+	return limit
+}
+
+// getRetainedInventoryFileName returns the name of the file retaining the
+// inventory database of the inventory image with the specified digest
+func getRetainedInventoryFileName(digest string) string {
+	return "plugin_inventory." + digest + ".db"
+}
+
+// readRetainedInventories returns the inventories retained in the specified cache directory,
+// from the highest to the lowest ranked.  The entries whose database file is missing are ignored.
+func readRetainedInventories(dir string) []retainedInventory {
+	b, err := os.ReadFile(filepath.Join(dir, retainedInventoriesFileName))
+	if err != nil {
+		return nil
+	}
+	var entries []retainedInventory
+	if err := json.Unmarshal(b, &entries); err != nil {
+		log.V(4).Infof("Ignoring the invalid list of retained plugin inventories %q: %v", filepath.Join(dir, retainedInventoriesFileName), err)
+		return nil
+	}
+	var retained []retainedInventory
+	for i := range entries {
+		if _, err := os.Stat(filepath.Join(dir, entries[i].File)); err == nil {
+			retained = append(retained, entries[i])
+		}
+	}
+	rankRetainedInventories(retained)
+	return retained
+}
+
+// writeRetainedInventories stores the list of the inventories retained in the specified
+// cache directory, or removes it when there are none
+func writeRetainedInventories(dir string, entries []retainedInventory) {
+	mappingFile := filepath.Join(dir, retainedInventoriesFileName)
+	if len(entries) == 0 {
+		_ = os.Remove(mappingFile)
+		return
+	}
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(mappingFile+".tmp", b, 0644); err != nil {
+		_ = os.Remove(mappingFile + ".tmp")
+		return
+	}
+	_ = os.Rename(mappingFile+".tmp", mappingFile)
+}
+
+// rankRetainedInventories sorts the retained inventories from the one to keep the longest
+// to the one to evict first.  The most recently used inventory ranks first; the number
+// of uses only breaks ties, so that an inventory used often long ago is still evicted.
+func rankRetainedInventories(entries []retainedInventory) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].LastUsed.Equal(entries[j].LastUsed) {
+			return entries[i].LastUsed.After(entries[j].LastUsed)
+		}
+		return entries[i].Uses > entries[j].Uses
+	})
+}
+
+// retainInventory keeps a copy of the cached inventory database, just downloaded from the
+// images with the specified digests, and evicts the lowest ranked retained inventories
+// beyond the configured limit.  Nothing is retained when only the current inventory is
+// to be kept, in which case the inventories retained previously are removed.
+func (od *DBBackedOCIDiscovery) retainInventory(digest, metadataDigest string) {
+	limit := getRetainedInventoriesLimit()
+	if limit <= 1 {
+		evictRetainedInventories(od.pluginDataDir, "")
+		return
+	}
+
+	file := getRetainedInventoryFileName(digest)
+	filePath := filepath.Join(od.pluginDataDir, file)
+	if err := utils.CopyFile(filepath.Join(od.pluginDataDir, plugininventory.SQliteDBFileName), filePath+".tmp"); err != nil {
+		_ = os.Remove(filePath + ".tmp")
+		log.V(4).Infof("Unable to retain the plugin inventory of discovery '%s': %v", od.Name(), err)
+		return
+	}
+	if err := os.Rename(filePath+".tmp", filePath); err != nil {
+		_ = os.Remove(filePath + ".tmp")
+		return
+	}
+
+	entry := retainedInventory{
+		Identity:       od.identityHash(),
+		Digest:         digest,
+		MetadataDigest: metadataDigest,
+		File:           file,
+		LastUsed:       time.Now(),
+		Uses:           1,
+	}
+	retained := []retainedInventory{}
+	for _, r := range readRetainedInventories(od.pluginDataDir) {
+		// The file now holds the new database
+		if r.File == file {
+			entry.Uses += r.Uses
+			continue
+		}
+		retained = append(retained, r)
+	}
+	retained = append([]retainedInventory{entry}, retained...)
+
+	if len(retained) > limit {
+		for _, r := range retained[limit:] {
+			log.V(4).Infof("Evicting the retained plugin inventory sha256:%s of discovery '%s'", r.Digest, od.Name())
+			_ = os.Remove(filepath.Join(od.pluginDataDir, r.File))
+		}
+		retained = retained[:limit]
+	}
+	writeRetainedInventories(od.pluginDataDir, retained)
+}
+
+// restoreRetainedInventory makes the retained inventory database fetched from the images with
+// the specified digests the cached inventory of the discovery.  It returns false if no such
+// inventory is retained, in which case the inventory image must be downloaded.
+func (od *DBBackedOCIDiscovery) restoreRetainedInventory(digest, metadataDigest string) bool {
+	if getRetainedInventoriesLimit() <= 1 {
+		return false
+	}
+	retained := readRetainedInventories(od.pluginDataDir)
+	for i := range retained {
+		r := &retained[i]
+		if r.Identity != od.identityHash() || r.Digest != digest || r.MetadataDigest != metadataDigest {
+			continue
+		}
+
+		// The database is copied next to its destination and then renamed so
+		// that an interruption never leaves a partial database in the cache
+		dbFilePath := filepath.Join(od.pluginDataDir, plugininventory.SQliteDBFileName)
+		if err := utils.CopyFile(filepath.Join(od.pluginDataDir, r.File), dbFilePath+".tmp"); err != nil {
+			_ = os.Remove(dbFilePath + ".tmp")
+			return false
+		}
+		if err := os.Rename(dbFilePath+".tmp", dbFilePath); err != nil {
+			_ = os.Remove(dbFilePath + ".tmp")
+			return false
+		}
+
+		log.V(4).Infof("Using the retained plugin inventory sha256:%s of discovery '%s' instead of downloading it again", digest, od.Name())
+		r.LastUsed = time.Now()
+		r.Uses++
+		rankRetainedInventories(retained)
+		writeRetainedInventories(od.pluginDataDir, retained)
+		return true
+	}
+	return false
+}
+
+// forgetRetainedInventory removes the retained inventory of the inventory image with
+// the specified digest, e.g. because the database fetched from that image is corrupt
+func (od *DBBackedOCIDiscovery) forgetRetainedInventory(digest string) {
+	var retained []retainedInventory
+	for _, r := range readRetainedInventories(od.pluginDataDir) {
+		if r.Digest == digest {
+			_ = os.Remove(filepath.Join(od.pluginDataDir, r.File))
+			continue
+		}
+		retained = append(retained, r)
+	}
+	writeRetainedInventories(od.pluginDataDir, retained)
+}
+
+// evictRetainedInventories removes the inventories retained in the specified cache
+// directory, except the one of the inventory image with the digest keepDigest, if any,
+// which is the current inventory.  It returns the number of bytes freed.
+func evictRetainedInventories(dir, keepDigest string) int64 {
+	var freed int64
+	var kept []retainedInventory
+	for _, r := range readRetainedInventories(dir) {
+		if keepDigest != "" && r.Digest == keepDigest {
+			kept = append(kept, r)
+			continue
+		}
+		filePath := filepath.Join(dir, r.File)
+		if info, err := os.Stat(filePath); err == nil {
+			freed += info.Size()
+		}
+		_ = os.Remove(filePath)
+	}
+	writeRetainedInventories(dir, kept)
+	return freed
+}
@@ -0,0 +1,161 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+var _ = Describe("Retained inventories", func() {
+	var (
+		tmpDir          string
+		origCacheDir    string
+		dataDir         string
+		imageOperations *refreshImageOperations
+		dbDiscovery     *DBBackedOCIDiscovery
+	)
+
+	// fetchDigest points the image to the specified digest, whose database
+	// is "inventory-<digest>", and fetches the inventory
+	fetchDigest := func(digest string) {
+		imageOperations.digest = digest
+		imageOperations.database = []byte("inventory-" + digest)
+		Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+		content, err := os.ReadFile(filepath.Join(dataDir, plugininventory.SQliteDBFileName))
+		Expect(err).To(BeNil())
+		Expect(string(content)).To(Equal("inventory-" + digest))
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "cache")
+		Expect(err).To(BeNil())
+		origCacheDir = common.DefaultCacheDir
+		common.DefaultCacheDir = tmpDir
+		dataDir = filepath.Join(tmpDir, common.PluginInventoryDirName, "test-discovery")
+		Expect(os.MkdirAll(dataDir, 0755)).To(Succeed())
+		os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, "test-image:latest")
+
+		imageOperations = &refreshImageOperations{image: "test-image:latest"}
+		newImageOperations = func(options ...carvelhelpers.ImageOperationOption) carvelhelpers.ImageOperationsImpl {
+			return imageOperations
+		}
+
+		discovery := NewOCIDiscovery("test-discovery", "test-image:latest")
+		var ok bool
+		dbDiscovery, ok = discovery.(*DBBackedOCIDiscovery)
+		Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+		dbDiscovery.pluginDataDir = dataDir
+	})
+	AfterEach(func() {
+		newImageOperations = carvelhelpers.NewImageOperationsImpl
+		common.DefaultCacheDir = origCacheDir
+		os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+		os.Unsetenv(constants.ConfigVariablePluginDiscoveryRetainedInventories)
+		os.RemoveAll(tmpDir)
+	})
+
+	Context("by default", func() {
+		It("should download the inventory again when the image flips back", func() {
+			fetchDigest("1111")
+			fetchDigest("2222")
+			fetchDigest("1111")
+			Expect(imageOperations.downloads).To(Equal(3))
+			Expect(filepath.Join(dataDir, retainedInventoriesFileName)).ToNot(BeAnExistingFile())
+		})
+	})
+
+	Context("when two inventories are retained", func() {
+		BeforeEach(func() {
+			os.Setenv(constants.ConfigVariablePluginDiscoveryRetainedInventories, "2")
+		})
+		It("should restore the inventory when the image flips back and forth", func() {
+			fetchDigest("1111")
+			fetchDigest("2222")
+			Expect(imageOperations.downloads).To(Equal(2))
+
+			fetchDigest("1111")
+			fetchDigest("2222")
+			fetchDigest("1111")
+			Expect(imageOperations.downloads).To(Equal(2))
+
+			// The digest files follow the restored inventory
+			hashFiles, err := filepath.Glob(filepath.Join(dataDir, "digest.*"))
+			Expect(err).To(BeNil())
+			Expect(hashFiles).To(HaveLen(1))
+			Expect(hashFiles[0]).To(HaveSuffix(".1111"))
+
+			// The cache is up-to-date once restored
+			Expect(dbDiscovery.fetchInventoryImage()).To(Succeed())
+			Expect(imageOperations.downloads).To(Equal(2))
+		})
+		It("should evict the least recently used inventory beyond the limit", func() {
+			fetchDigest("1111")
+			fetchDigest("2222")
+			fetchDigest("3333")
+			Expect(imageOperations.downloads).To(Equal(3))
+			Expect(filepath.Join(dataDir, getRetainedInventoryFileName("1111"))).ToNot(BeAnExistingFile())
+
+			fetchDigest("2222")
+			Expect(imageOperations.downloads).To(Equal(3))
+			fetchDigest("1111")
+			Expect(imageOperations.downloads).To(Equal(4))
+
+			// 3333 was the least recently used
+			Expect(filepath.Join(dataDir, getRetainedInventoryFileName("3333"))).ToNot(BeAnExistingFile())
+			Expect(readRetainedInventories(dataDir)).To(HaveLen(2))
+		})
+		It("should not restore a corrupt inventory", func() {
+			fetchDigest("1111")
+			fetchDigest("2222")
+			fetchDigest("1111")
+			dbDiscovery.invalidateCache()
+			Expect(filepath.Join(dataDir, getRetainedInventoryFileName("1111"))).ToNot(BeAnExistingFile())
+
+			fetchDigest("1111")
+			Expect(imageOperations.downloads).To(Equal(3))
+		})
+		It("should remove the retained inventories once they are no longer wanted", func() {
+			fetchDigest("1111")
+			fetchDigest("2222")
+			Expect(readRetainedInventories(dataDir)).To(HaveLen(2))
+
+			os.Setenv(constants.ConfigVariablePluginDiscoveryRetainedInventories, "1")
+			fetchDigest("3333")
+			Expect(readRetainedInventories(dataDir)).To(BeEmpty())
+			Expect(filepath.Join(dataDir, getRetainedInventoryFileName("1111"))).ToNot(BeAnExistingFile())
+			Expect(filepath.Join(dataDir, getRetainedInventoryFileName("2222"))).ToNot(BeAnExistingFile())
+		})
+		It("should evict the retained inventories before the current inventories when pruning the cache", func() {
+			fetchDigest("1111")
+			fetchDigest("2222")
+			// Evicting the retained inventory of 1111 is enough
+			evicted, err := PruneInventoryCache(getCachedInventory(dataDir).size-1, "")
+			Expect(err).To(BeNil())
+			Expect(evicted).To(BeEmpty())
+			Expect(filepath.Join(dataDir, getRetainedInventoryFileName("1111"))).ToNot(BeAnExistingFile())
+			Expect(filepath.Join(dataDir, plugininventory.SQliteDBFileName)).To(BeAnExistingFile())
+			Expect(readRetainedInventories(dataDir)).To(HaveLen(1))
+		})
+	})
+
+	Context("when the number of retained inventories is too large", func() {
+		It("should be bounded", func() {
+			os.Setenv(constants.ConfigVariablePluginDiscoveryRetainedInventories, "100")
+			Expect(getRetainedInventoriesLimit()).To(Equal(maxRetainedInventories))
+
+			os.Setenv(constants.ConfigVariablePluginDiscoveryRetainedInventories, "invalid")
+			Expect(getRetainedInventoriesLimit()).To(Equal(defaultRetainedInventories))
+		})
+	})
+})
@@ -0,0 +1,152 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper/sigverifier"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+// verifyInventoryImageSignatures verifies the signatures of a batch of inventory images,
+// accessing the registry of each image with the credentials of the keychain at the same index.
+// It is a variable so that it can be replaced by tests.
+var verifyInventoryImageSignatures = sigverifier.VerifyInventoryImageSignaturesWithResults
+
+// verifiedInventoryImages are the inventory images whose signature was verified by
+// VerifyInventoryImageSignatures, as "<image>@sha256:<digest>", so that the discovery
+// fetching their inventory does not verify them again
+var verifiedInventoryImages sync.Map
+
+// resolvedImageDigests are the digests of the inventory image and of the metadata
+// image resolved by VerifyInventoryImageSignatures, by identityHash() of the discovery.
+// They are used once, by the discovery fetching the inventory, instead of resolving
+// the digests again.
+var resolvedImageDigests sync.Map
+
+// resolvedDigests are the hex values of the digests returned by resolveImageDigests()
+type resolvedDigests struct {
+	inventory string
+	metadata  string
+}
+
+// GetRegistryKeychain returns the keychain providing the registry credentials of the
+// specified OCI discovery source, or nil if its registries are accessed anonymously
+func GetRegistryKeychain(discoveryName, image string) authn.Keychain {
+	return getRegistryCredentials(discoveryName, image).Keychain()
+}
+
+// VerifyInventoryImageSignatures verifies, as one batch, the signatures of the inventory
+// images of the OCI discovery sources whose cached inventory is about to be downloaded again,
+// so that the discovery sources listed when installing or syncing plugins do not each
+// verify their image on their own.  The images are verified concurrently, each with the
+// registry credentials of its discovery source.
+// Nothing is returned: a discovery source whose digest cannot be resolved or whose image
+// fails the verification is left to the discovery fetching its inventory, which reports
+// the error as usual.  The composite discovery sources are also left to their discovery.
+func VerifyInventoryImageSignatures(discoverySources []configtypes.PluginDiscovery, options ...DiscoveryOptions) {
+	var pending []*DBBackedOCIDiscovery
+	var images []string
+	var digests []string
+	var keychains []authn.Keychain
+	for i := range discoverySources {
+		ds := discoverySources[i].OCI
+		if ds == nil || len(config.GetPluginDiscoveryCompositeImages(ds.Name)) > 0 {
+			continue
+		}
+		od, ok := NewOCIDiscovery(ds.Name, ds.Image, options...).(*DBBackedOCIDiscovery)
+		if !ok {
+			continue
+		}
+		digest, needed := od.needsSignatureVerification()
+		if !needed {
+			continue
+		}
+		pending = append(pending, od)
+		images = append(images, od.image)
+		digests = append(digests, digest)
+		keychains = append(keychains, od.credentials.Keychain())
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	_, errs := verifyInventoryImageSignatures(images, keychains)
+	for i, od := range pending {
+		if errs[i] != nil {
+			log.V(4).Infof("The signature of the inventory image of discovery '%s' will be verified again when fetching its inventory: %v", od.Name(), errs[i])
+			continue
+		}
+		od.removeSignatureFailures()
+		verifiedInventoryImages.Store(od.image+"@sha256:"+digests[i], true)
+	}
+}
+
+// needsSignatureVerification returns the digest of the inventory image of the discovery
+// and whether its signature is going to be verified when fetching the inventory, that is
+// when the image is downloaded instead of being served from the cache.  The resolved
+// digests are kept for the next resolveImageDigests() of the discovery.
+func (od *DBBackedOCIDiscovery) needsSignatureVerification() (string, bool) {
+	// The inventory is not fetched from the image, or it is fetched in the background,
+	// or the generation marker of the image will tell whether it must be fetched
+	if od.useLocalCacheOnly || od.generationMarkerURL != "" || (od.backgroundRefresh && od.getCachedInventoryHashFile() != "") {
+		return "", false
+	}
+	if sigverifier.IsSignatureVerificationSkipped(od.image) {
+		return "", false
+	}
+
+	inventoryDigest, metadataDigest, err := od.resolveImageDigests()
+	if err != nil {
+		return "", false
+	}
+	resolvedImageDigests.Store(od.identityHash(), resolvedDigests{inventory: inventoryDigest, metadata: metadataDigest})
+
+	if hashFile := od.getCachedInventoryHashFile(); hashFile != "" && getDigestFromHashFile(hashFile) == inventoryDigest {
+		return "", false
+	}
+	if metadataDigest == "" {
+		metadataDigest = "none"
+	}
+	if od.hasRetainedInventory(inventoryDigest, metadataDigest) || od.checkSignatureFailure(inventoryDigest) != nil {
+		return "", false
+	}
+	return inventoryDigest, true
+}
+
+// hasRetainedInventory returns true if the inventory with the specified digests is retained
+// in the cache, in which case it is restored instead of being downloaded
+func (od *DBBackedOCIDiscovery) hasRetainedInventory(digest, metadataDigest string) bool {
+	if getRetainedInventoriesLimit() <= 1 {
+		return false
+	}
+	for _, r := range readRetainedInventories(od.pluginDataDir) {
+		if r.Identity == od.identityHash() && r.Digest == digest && r.MetadataDigest == metadataDigest {
+			return true
+		}
+	}
+	return false
+}
+
+// takeResolvedImageDigests returns the digests resolved for the discovery by
+// VerifyInventoryImageSignatures, if any, so that they are only used once
+func (od *DBBackedOCIDiscovery) takeResolvedImageDigests() (resolvedDigests, bool) {
+	digests, found := resolvedImageDigests.LoadAndDelete(od.identityHash())
+	if !found {
+		return resolvedDigests{}, false
+	}
+	return digests.(resolvedDigests), true
+}
+
+// isSignatureVerified returns true if the signature of the inventory image of the
+// discovery with the specified digest was verified by VerifyInventoryImageSignatures
+func (od *DBBackedOCIDiscovery) isSignatureVerified(digest string) bool {
+	_, verified := verifiedInventoryImages.Load(od.image + "@sha256:" + digest)
+	return verified
+}
@@ -0,0 +1,188 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+// countingImageOperations counts the resolutions of the digests of the images of a map;
+// the other images are unreachable
+type countingImageOperations struct {
+	carvelhelpers.ImageOperationsImpl
+	digests     map[string]string
+	resolutions *int
+}
+
+func (c *countingImageOperations) GetImageDigest(imageWithTag string) (string, string, error) {
+	if digest, found := c.digests[imageWithTag]; found {
+		*c.resolutions++
+		return "sha256:" + digest, digest, nil
+	}
+	return "", "", errors.Errorf("unable to reach %q", imageWithTag)
+}
+
+var _ = Describe("Batch verification of the inventory image signatures", func() {
+	var (
+		tmpDir          string
+		origCacheDir    string
+		origVerify      func(string, authn.Keychain) error
+		origBatchVerify func([]string, []authn.Keychain) ([]*cosignhelper.SignatureVerificationResult, []error)
+		batches         [][]string
+		batchKeychains  []authn.Keychain
+		failingImage    string
+		attempts        int
+		resolutions     int
+		sources         []configtypes.PluginDiscovery
+	)
+
+	ociSource := func(name, image string) configtypes.PluginDiscovery {
+		return configtypes.PluginDiscovery{OCI: &configtypes.OCIDiscovery{Name: name, Image: image}}
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "cache")
+		Expect(err).To(BeNil())
+		origCacheDir = common.DefaultCacheDir
+		common.DefaultCacheDir = tmpDir
+
+		batches = nil
+		batchKeychains = nil
+		failingImage = ""
+		attempts = 0
+		resolutions = 0
+		verifiedInventoryImages = sync.Map{}
+		resolvedImageDigests = sync.Map{}
+
+		origVerify = verifyInventoryImageSignature
+		verifyInventoryImageSignature = func(image string, keychain authn.Keychain) error {
+			attempts++
+			return nil
+		}
+		origBatchVerify = verifyInventoryImageSignatures
+		verifyInventoryImageSignatures = func(images []string, keychains []authn.Keychain) ([]*cosignhelper.SignatureVerificationResult, []error) {
+			batches = append(batches, images)
+			batchKeychains = keychains
+			results := make([]*cosignhelper.SignatureVerificationResult, len(images))
+			errs := make([]error, len(images))
+			for i := range images {
+				if images[i] == failingImage {
+					errs[i] = errors.New("no matching signatures")
+					continue
+				}
+				results[i] = &cosignhelper.SignatureVerificationResult{Image: images[i]}
+			}
+			return results, errs
+		}
+		newImageOperations = func(options ...carvelhelpers.ImageOperationOption) carvelhelpers.ImageOperationsImpl {
+			return &countingImageOperations{
+				digests:     map[string]string{"first:latest": "1234", "second:latest": "5678", "cached:latest": "9999"},
+				resolutions: &resolutions,
+			}
+		}
+
+		sources = []configtypes.PluginDiscovery{
+			ociSource("first", "first:latest"),
+			ociSource("second", "second:latest"),
+			ociSource("unreachable", "unreachable:latest"),
+			{Local: &configtypes.LocalDiscovery{Name: "local", Path: tmpDir}},
+		}
+	})
+
+	AfterEach(func() {
+		verifyInventoryImageSignature = origVerify
+		verifyInventoryImageSignatures = origBatchVerify
+		newImageOperations = carvelhelpers.NewImageOperationsImpl
+		verifiedInventoryImages = sync.Map{}
+		resolvedImageDigests = sync.Map{}
+		common.DefaultCacheDir = origCacheDir
+		os.RemoveAll(tmpDir)
+	})
+
+	It("should verify the images to download as one batch and not again when fetching them", func() {
+		VerifyInventoryImageSignatures(sources)
+		Expect(batches).To(Equal([][]string{{"first:latest", "second:latest"}}))
+		Expect(batchKeychains).To(HaveLen(2))
+
+		od := newDBBackedOCIDiscovery("first", "first:latest")
+		Expect(od.verifySignature("1234")).To(Succeed())
+		Expect(attempts).To(Equal(0))
+
+		// A different digest was not verified by the batch
+		Expect(od.verifySignature("4321")).To(Succeed())
+		Expect(attempts).To(Equal(1))
+	})
+
+	It("should not resolve the digests again when fetching the inventory", func() {
+		VerifyInventoryImageSignatures(sources)
+		Expect(resolutions).To(Equal(2))
+
+		od := newDBBackedOCIDiscovery("first", "first:latest")
+		digest, _, err := od.resolveImageDigests()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(digest).To(Equal("1234"))
+		Expect(resolutions).To(Equal(2))
+
+		// The resolved digests are only used once
+		_, _, err = od.resolveImageDigests()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resolutions).To(Equal(3))
+	})
+
+	It("should leave the images failing the verification to the discovery", func() {
+		failingImage = "second:latest"
+		VerifyInventoryImageSignatures(sources)
+
+		od := newDBBackedOCIDiscovery("second", "second:latest")
+		Expect(od.verifySignature("5678")).To(Succeed())
+		Expect(attempts).To(Equal(1))
+	})
+
+	It("should not verify the images whose cached inventory is up-to-date", func() {
+		od := newDBBackedOCIDiscovery("cached", "cached:latest")
+		Expect(os.MkdirAll(od.pluginDataDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(od.pluginDataDir, plugininventory.SQliteDBFileName), []byte("db"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(od.pluginDataDir, "digest."+od.identityHash()+".9999"), nil, 0644)).To(Succeed())
+
+		VerifyInventoryImageSignatures([]configtypes.PluginDiscovery{ociSource("cached", "cached:latest")})
+		Expect(batches).To(BeEmpty())
+	})
+
+	It("should not verify the images whose verification is skipped or which only use the cache", func() {
+		os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, "first:latest")
+		defer os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+
+		VerifyInventoryImageSignatures(sources)
+		Expect(batches).To(Equal([][]string{{"second:latest"}}))
+
+		batches = nil
+		VerifyInventoryImageSignatures(sources, WithUseLocalCacheOnly())
+		Expect(batches).To(BeEmpty())
+	})
+
+	It("should verify the images with the credentials of their discovery source", func() {
+		os.Setenv(constants.ConfigVariablePluginDiscoveryTokenPrefix+"SECOND", "secret")
+		defer os.Unsetenv(constants.ConfigVariablePluginDiscoveryTokenPrefix + "SECOND")
+
+		VerifyInventoryImageSignatures(sources)
+		Expect(batchKeychains).To(HaveLen(2))
+		Expect(batchKeychains[0]).To(BeNil())
+		Expect(batchKeychains[1]).ToNot(BeNil())
+	})
+})
@@ -0,0 +1,139 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper/sigverifier"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+// defaultSignatureFailureCoolDown is how long a failed signature verification of an
+// inventory image is remembered before the image is verified again
+const defaultSignatureFailureCoolDown = 5 * time.Minute
+
+// verifyInventoryImageSignature verifies the signature of an inventory image, accessing
+// the registry with the credentials of the keychain.
+// It is a variable so that it can be replaced by tests.
+var verifyInventoryImageSignature = sigverifier.VerifyInventoryImageSignatureWithKeychain
+
+// getSignatureFailureCoolDown returns how long a failed signature verification is
+// remembered, as configured by TANZU_CLI_PLUGIN_DISCOVERY_SIGNATURE_FAILURE_COOLDOWN.
+// A value of 0 disables the cool-down.
+func getSignatureFailureCoolDown() time.Duration {
+	value := strings.TrimSpace(os.Getenv(constants.ConfigVariablePluginDiscoverySignatureFailureCoolDown))
+	if value == "" {
+		return defaultSignatureFailureCoolDown
+	}
+	coolDown, err := time.ParseDuration(value)
+	if err != nil || coolDown < 0 {
+		log.Warningf("Ignoring the invalid value %q of %s, a duration such as '5m' is expected", value, constants.ConfigVariablePluginDiscoverySignatureFailureCoolDown)
+		return defaultSignatureFailureCoolDown
+	}
+	return coolDown
+}
+
+// isForceRefresh returns whether the recent signature verification failures must be
+// ignored, as requested by the --refresh flag through TANZU_CLI_PLUGIN_DISCOVERY_FORCE_REFRESH
+func isForceRefresh() bool {
+	force, _ := strconv.ParseBool(os.Getenv(constants.ConfigVariablePluginDiscoveryForceRefresh))
+	return force
+}
+
+// getSignatureFailureFile returns the path of the file recording a failed signature
+// verification of the inventory image of this discovery with the specified digest.
+// The file is named "sigfailure.<identity>.<digest>.<config>", where <config> is the
+// signatureVerificationConfigHash(), and contains the reason of the failure.
+func (od *DBBackedOCIDiscovery) getSignatureFailureFile(digest string) string {
+	return filepath.Join(od.pluginDataDir, "sigfailure."+od.identityHash()+"."+digest+"."+od.signatureVerificationConfigHash())
+}
+
+// signatureVerificationConfigHash returns a hash of the configuration of the signature
+// verification of the inventory image: the path and the content of the custom public key,
+// if any, and whether the verification of the image is skipped.  This way, a failure
+// recorded before the configuration changed, e.g., before the public key was updated,
+// is not used once it has changed.
+func (od *DBBackedOCIDiscovery) signatureVerificationConfigHash() string {
+	publicKeyPath := os.Getenv(constants.PublicKeyPathForPluginDiscoveryImageSignature)
+	config := publicKeyPath + "\n"
+	if publicKeyPath != "" {
+		if publicKey, err := os.ReadFile(publicKeyPath); err == nil {
+			publicKeyHash := sha256.Sum256(publicKey)
+			config += hex.EncodeToString(publicKeyHash[:])
+		}
+	}
+	config += "\n" + strconv.FormatBool(sigverifier.IsSignatureVerificationSkipped(od.image))
+	hash := sha256.Sum256([]byte(config))
+	return hex.EncodeToString(hash[:])[:12]
+}
+
+// verifySignature verifies the signature of the inventory image with the specified digest.
+// A failure is recorded in the cache so that, for the duration of the cool-down, the
+// following attempts to verify the same image fail right away with the same reason
+// instead of accessing the registry again.  The cool-down does not apply to the images
+// whose signature verification is skipped by the user.  Nothing is verified if the
+// image was already verified by VerifyInventoryImageSignatures.
+func (od *DBBackedOCIDiscovery) verifySignature(digest string) error {
+	if sigverifier.IsSignatureVerificationSkipped(od.image) {
+		return verifyInventoryImageSignature(od.image, od.credentials.Keychain())
+	}
+	if digest == "" {
+		digest = "none"
+	}
+	if od.isSignatureVerified(digest) {
+		return nil
+	}
+	if err := od.checkSignatureFailure(digest); err != nil {
+		return err
+	}
+
+	// Any previous failure is obsolete once the signature is verified again
+	od.removeSignatureFailures()
+
+	err := verifyInventoryImageSignature(od.image, od.credentials.Keychain())
+	if err != nil && getSignatureFailureCoolDown() > 0 {
+		if mkdirErr := os.MkdirAll(od.pluginDataDir, 0755); mkdirErr == nil {
+			_ = os.WriteFile(od.getSignatureFailureFile(digest), []byte(err.Error()), 0644)
+		}
+	}
+	return err
+}
+
+// checkSignatureFailure returns the recorded failure of the signature verification of the
+// inventory image with the specified digest if the failure is still in its cool-down
+func (od *DBBackedOCIDiscovery) checkSignatureFailure(digest string) error {
+	coolDown := getSignatureFailureCoolDown()
+	if coolDown <= 0 || isForceRefresh() {
+		return nil
+	}
+	failureFile := od.getSignatureFailureFile(digest)
+	info, err := os.Stat(failureFile)
+	if err != nil {
+		return nil
+	}
+	if age := time.Since(info.ModTime()); age < coolDown {
+		reason, _ := os.ReadFile(failureFile)
+		return errors.Errorf("%s (the signature verification failed %v ago and will not be attempted again for %v; use --refresh to retry now)",
+			strings.TrimSpace(string(reason)), age.Round(time.Second), (coolDown - age).Round(time.Second))
+	}
+	return nil
+}
+
+// removeSignatureFailures removes the recorded signature verification failures of this discovery
+func (od *DBBackedOCIDiscovery) removeSignatureFailures() {
+	matches, _ := filepath.Glob(filepath.Join(od.pluginDataDir, "sigfailure."+od.identityHash()+".*"))
+	for _, match := range matches {
+		_ = os.Remove(match)
+	}
+}
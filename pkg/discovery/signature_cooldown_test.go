@@ -0,0 +1,148 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+)
+
+var _ = Describe("Cool-down of failed inventory image signature verifications", func() {
+	var (
+		tmpDir       string
+		origCacheDir string
+		origVerify   func(string, authn.Keychain) error
+		attempts     int
+		usedKeychain authn.Keychain
+		verifyErr    error
+		od           *DBBackedOCIDiscovery
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "cache")
+		Expect(err).To(BeNil())
+		origCacheDir = common.DefaultCacheDir
+		common.DefaultCacheDir = tmpDir
+
+		attempts = 0
+		verifyErr = errors.New("signature verification failed: no matching signatures")
+		origVerify = verifyInventoryImageSignature
+		verifyInventoryImageSignature = func(image string, keychain authn.Keychain) error {
+			attempts++
+			usedKeychain = keychain
+			return verifyErr
+		}
+		od = newDBBackedOCIDiscovery("default", "example.com/inventory:latest")
+	})
+
+	AfterEach(func() {
+		verifyInventoryImageSignature = origVerify
+		common.DefaultCacheDir = origCacheDir
+		os.RemoveAll(tmpDir)
+		os.Unsetenv(constants.ConfigVariablePluginDiscoverySignatureFailureCoolDown)
+		os.Unsetenv(constants.ConfigVariablePluginDiscoveryForceRefresh)
+	})
+
+	It("should fail fast with the recorded reason during the cool-down", func() {
+		err := od.verifySignature("1234")
+		Expect(err).To(HaveOccurred())
+		Expect(attempts).To(Equal(1))
+
+		err = od.verifySignature("1234")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("no matching signatures"))
+		Expect(err.Error()).To(ContainSubstring("use --refresh to retry now"))
+		Expect(attempts).To(Equal(1))
+	})
+
+	It("should verify an image with a different digest", func() {
+		Expect(od.verifySignature("1234")).ToNot(Succeed())
+		Expect(od.verifySignature("5678")).ToNot(Succeed())
+		Expect(attempts).To(Equal(2))
+	})
+
+	It("should verify the image again once the cool-down has expired", func() {
+		Expect(od.verifySignature("1234")).ToNot(Succeed())
+		past := time.Now().Add(-defaultSignatureFailureCoolDown - time.Minute)
+		Expect(os.Chtimes(od.getSignatureFailureFile("1234"), past, past)).To(Succeed())
+
+		verifyErr = nil
+		Expect(od.verifySignature("1234")).To(Succeed())
+		Expect(attempts).To(Equal(2))
+		Expect(od.getSignatureFailureFile("1234")).ToNot(BeAnExistingFile())
+	})
+
+	It("should bypass the cool-down when a refresh is forced", func() {
+		Expect(od.verifySignature("1234")).ToNot(Succeed())
+
+		os.Setenv(constants.ConfigVariablePluginDiscoveryForceRefresh, "true")
+		verifyErr = nil
+		Expect(od.verifySignature("1234")).To(Succeed())
+		Expect(attempts).To(Equal(2))
+		Expect(od.getSignatureFailureFile("1234")).ToNot(BeAnExistingFile())
+	})
+
+	It("should not record failures when the cool-down is disabled", func() {
+		os.Setenv(constants.ConfigVariablePluginDiscoverySignatureFailureCoolDown, "0")
+		Expect(od.verifySignature("1234")).ToNot(Succeed())
+		Expect(od.verifySignature("1234")).ToNot(Succeed())
+		Expect(attempts).To(Equal(2))
+		Expect(od.getSignatureFailureFile("1234")).ToNot(BeAnExistingFile())
+	})
+
+	It("should not apply the cool-down to an image whose verification is skipped", func() {
+		Expect(od.verifySignature("1234")).ToNot(Succeed())
+
+		os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, "example.com/inventory:latest")
+		defer os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+		verifyErr = nil
+		Expect(od.verifySignature("1234")).To(Succeed())
+		Expect(attempts).To(Equal(2))
+	})
+
+	It("should verify the image again once the public key has changed", func() {
+		publicKeyPath := filepath.Join(tmpDir, "cosign.pub")
+		Expect(os.WriteFile(publicKeyPath, []byte("key1"), 0644)).To(Succeed())
+		os.Setenv(constants.PublicKeyPathForPluginDiscoveryImageSignature, publicKeyPath)
+		defer os.Unsetenv(constants.PublicKeyPathForPluginDiscoveryImageSignature)
+
+		Expect(od.verifySignature("1234")).ToNot(Succeed())
+		Expect(od.verifySignature("1234")).ToNot(Succeed())
+		Expect(attempts).To(Equal(1))
+
+		// Same path, different key
+		Expect(os.WriteFile(publicKeyPath, []byte("key2"), 0644)).To(Succeed())
+		Expect(od.verifySignature("1234")).ToNot(Succeed())
+		Expect(attempts).To(Equal(2))
+
+		// Different path
+		os.Unsetenv(constants.PublicKeyPathForPluginDiscoveryImageSignature)
+		verifyErr = nil
+		Expect(od.verifySignature("1234")).To(Succeed())
+		Expect(attempts).To(Equal(3))
+	})
+
+	It("should verify the signature with the credentials of the discovery", func() {
+		Expect(od.verifySignature("1234")).ToNot(Succeed())
+		Expect(usedKeychain).To(BeNil())
+
+		os.Setenv(constants.ConfigVariablePluginDiscoveryUsernamePrefix+"DEFAULT", "user")
+		os.Setenv(constants.ConfigVariablePluginDiscoveryPasswordPrefix+"DEFAULT", "secret")
+		defer os.Unsetenv(constants.ConfigVariablePluginDiscoveryUsernamePrefix + "DEFAULT")
+		defer os.Unsetenv(constants.ConfigVariablePluginDiscoveryPasswordPrefix + "DEFAULT")
+		od = newDBBackedOCIDiscovery("default", "example.com/inventory:latest")
+		Expect(od.verifySignature("5678")).ToNot(Succeed())
+		Expect(usedKeychain).ToNot(BeNil())
+	})
+})
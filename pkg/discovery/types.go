@@ -6,7 +6,10 @@ package discovery
 import (
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
 )
 
 // Discovered defines discovered plugin resource
@@ -59,8 +62,128 @@ type Discovered struct {
 	// Target defines the target to which this plugin is applicable to
 	Target configtypes.Target
 
+	// Vendor is the name of the vendor of the plugin (e.g., a company's name).
+	// It is empty when the discovery does not provide this information.
+	Vendor string
+
+	// Publisher is the name of the publisher of the plugin.
+	// It is empty when the discovery does not provide this information.
+	Publisher string
+
 	// Status is the installed/uninstalled status of the plugin.
 	Status string
+
+	// Dependencies lists, for each version declaring any, the other plugins
+	// that must also be installed for this plugin to work.
+	// It is empty when the discovery does not provide dependency information.
+	Dependencies map[string][]*plugininventory.PluginIdentifier
+
+	// Deprecated tells whether all the versions of the plugin are deprecated.
+	Deprecated bool
+
+	// DeprecationMessage explains why the plugin is deprecated, if Deprecated is true.
+	DeprecationMessage string
+
+	// DeprecatedVersions contains the deprecation message of each deprecated version.
+	// It is empty when the discovery does not provide deprecation information.
+	DeprecatedVersions map[string]string
+
+	// MinCLIVersions contains, for the versions that declare one,
+	// the minimum version of the Tanzu CLI required by the plugin.
+	// It is empty when the discovery does not provide this information.
+	MinCLIVersions map[string]string
+
+	// HomepageURL, DocumentationURL and IssueTrackerURL are the URLs of the web pages
+	// about the plugin.  They are empty when the discovery does not provide them.
+	HomepageURL      string
+	DocumentationURL string
+	IssueTrackerURL  string
+}
+
+// HasLinks returns true if the discovery provides the URL of any web page about the plugin
+func (d *Discovered) HasLinks() bool {
+	return d.HomepageURL != "" || d.DocumentationURL != "" || d.IssueTrackerURL != ""
+}
+
+// GetDeprecation returns whether the specified version of the plugin
+// is deprecated, along with the reason for the deprecation.
+func (d *Discovered) GetDeprecation(version string) (bool, string) {
+	if d.Deprecated {
+		return true, d.DeprecationMessage
+	}
+	message, found := d.DeprecatedVersions[version]
+	return found, message
+}
+
+// DeprecateVersions replaces the deprecation of the whole plugin by the deprecation
+// of each of its supported versions, so that the versions merged into the plugin
+// afterwards from other sources are not considered deprecated.
+func (d *Discovered) DeprecateVersions() {
+	if !d.Deprecated {
+		return
+	}
+	deprecatedVersions := make(map[string]string, len(d.SupportedVersions)+len(d.DeprecatedVersions))
+	for version, message := range d.DeprecatedVersions {
+		deprecatedVersions[version] = message
+	}
+	for _, version := range d.SupportedVersions {
+		deprecatedVersions[version] = d.DeprecationMessage
+	}
+	d.DeprecatedVersions = deprecatedVersions
+	d.Deprecated = false
+	d.DeprecationMessage = ""
+}
+
+// IsCompatibleWithCLI returns false if the specified version of the plugin requires a more
+// recent version of the CLI than cliVersion, along with the minimum CLI version it requires.
+// A version without such a requirement is compatible with any CLI, and so is any version
+// when cliVersion is not a valid semantic version.
+func (d *Discovered) IsCompatibleWithCLI(version, cliVersion string) (bool, string) {
+	minCLIVersion := d.MinCLIVersions[version]
+	if minCLIVersion == "" {
+		return true, ""
+	}
+	return !utils.IsNewVersion(minCLIVersion, cliVersion), minCLIVersion
+}
+
+// GetBinarySize returns the size in bytes of the plugin binary of the specified
+// version and platform, or zero if the discovery does not provide it.
+func (d *Discovered) GetBinarySize(version, os, arch string) int64 {
+	if d.Distribution == nil {
+		return 0
+	}
+	a, err := d.Distribution.DescribeArtifact(version, os, arch)
+	if err != nil {
+		return 0
+	}
+	return a.Size
+}
+
+// IsInstalledVersionAvailable returns false if the installed version of the plugin
+// is no longer provided by the discovery, e.g., after its inventory was pruned.
+// It returns true if the plugin is not installed or if the supported versions are unknown.
+func (d *Discovered) IsInstalledVersionAvailable() bool {
+	if d.InstalledVersion == "" || len(d.SupportedVersions) == 0 {
+		return true
+	}
+	return utils.ContainsString(d.SupportedVersions, d.InstalledVersion)
+}
+
+// IsInstalled returns true if a version of the plugin is installed.
+// The Status of the plugin must have been reconciled with the installed plugins.
+func (d *Discovered) IsInstalled() bool {
+	return d.IsUpToDate() || d.IsUpgradable()
+}
+
+// IsUpToDate returns true if the recommended version of the plugin is installed.
+func (d *Discovered) IsUpToDate() bool {
+	return d.Status == common.PluginStatusInstalled
+}
+
+// IsUpgradable returns true if the plugin is installed but the
+// recommended version should be installed instead.
+func (d *Discovered) IsUpgradable() bool {
+	return d.Status == common.PluginStatusUpdateAvailable || d.Status == common.PluginStatusOutdated
 }
 
 // DiscoveredSorter sorts discovered objects.
@@ -17,6 +17,50 @@ func CheckDiscoveryName(ds configtypes.PluginDiscovery, dn string) bool {
 		(ds.OCI != nil && ds.OCI.Name == dn)
 }
 
+// GetDiscoveryName returns the name of the discovery source
+func GetDiscoveryName(ds configtypes.PluginDiscovery) string {
+	switch {
+	case ds.OCI != nil:
+		return ds.OCI.Name
+	case ds.Local != nil:
+		return ds.Local.Name
+	case ds.Kubernetes != nil:
+		return ds.Kubernetes.Name
+	case ds.REST != nil:
+		return ds.REST.Name
+	case ds.GCP != nil: //nolint:staticcheck // Deprecated
+		return ds.GCP.Name //nolint:staticcheck // Deprecated
+	}
+	return ""
+}
+
+// SetDiscoveryName returns a copy of the discovery source with the specified name
+func SetDiscoveryName(ds configtypes.PluginDiscovery, name string) configtypes.PluginDiscovery {
+	switch {
+	case ds.OCI != nil:
+		oci := *ds.OCI
+		oci.Name = name
+		ds.OCI = &oci
+	case ds.Local != nil:
+		local := *ds.Local
+		local.Name = name
+		ds.Local = &local
+	case ds.Kubernetes != nil:
+		kubernetes := *ds.Kubernetes
+		kubernetes.Name = name
+		ds.Kubernetes = &kubernetes
+	case ds.REST != nil:
+		rest := *ds.REST
+		rest.Name = name
+		ds.REST = &rest
+	case ds.GCP != nil: //nolint:staticcheck // Deprecated
+		gcp := *ds.GCP //nolint:staticcheck // Deprecated
+		gcp.Name = name
+		ds.GCP = &gcp //nolint:staticcheck // Deprecated
+	}
+	return ds
+}
+
 // CompareDiscoverySource returns true if both discovery source are same for the given type
 func CompareDiscoverySource(ds1, ds2 configtypes.PluginDiscovery, dsType string) bool {
 	switch dsType {
@@ -27,6 +27,9 @@ type Artifact struct {
 
 	// Arch of the plugin binary in `GOARCH` format.
 	Arch string
+
+	// Size of the plugin binary in bytes, zero when it is unknown.
+	Size int64
 }
 
 // ArtifactList contains an Artifact object for every supported platform of a
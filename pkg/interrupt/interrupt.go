@@ -0,0 +1,229 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package interrupt handles the interruption of long operations of the CLI,
+// such as plugin installations, so that they can clean up after themselves.
+package interrupt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ExitCode is the exit code of the CLI when it is interrupted
+const ExitCode = 130
+
+// ErrInterrupted is returned by the operations that stopped because the CLI was interrupted
+var ErrInterrupted = errors.New("interrupted")
+
+// TimeoutError is the error of a command which did not complete before its timeout
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("operation timed out after %v", e.Timeout)
+}
+
+var (
+	mutex       sync.Mutex
+	cleanups    = map[int]func(){}
+	nextID      int
+	ctx, cancel = context.WithCancel(context.Background())
+	// timedOut is the timeout that interrupted the CLI, if any
+	timedOut time.Duration
+	// signaled is true if the CLI was interrupted by a signal (e.g., Ctrl-C)
+	signaled bool
+
+	// gracePeriod is how long an interrupted command is given to return before Run()
+	// stops waiting for it; it is a variable so that tests can shorten it
+	gracePeriod = 10 * time.Second
+)
+
+// Context returns a context that is canceled when the CLI is interrupted.
+// Long operations should stop starting new work once it is done.
+func Context() context.Context {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return ctx
+}
+
+// Interrupted returns true if the CLI was interrupted
+func Interrupted() bool {
+	return Context().Err() != nil
+}
+
+// RegisterCleanup registers a function to call if the CLI is interrupted, for example
+// to remove temporary files.  The returned function unregisters the cleanup function
+// and must be called once the cleanup is no longer needed.
+func RegisterCleanup(cleanup func()) (unregister func()) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	id := nextID
+	nextID++
+	cleanups[id] = cleanup
+	return func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		delete(cleanups, id)
+	}
+}
+
+// Interrupt cancels the context returned by Context() and calls the registered
+// cleanup functions, as Cleanup() does.
+func Interrupt() {
+	mutex.Lock()
+	cancel()
+	mutex.Unlock()
+	Cleanup()
+}
+
+// Cleanup calls the registered cleanup functions, the most recently registered first.
+// The cleanup functions are then unregistered.  Once an interrupted command has
+// returned, it is called to clean up after the operations that did not complete.
+func Cleanup() {
+	mutex.Lock()
+	ids := make([]int, 0, len(cleanups))
+	for id := range cleanups {
+		ids = append(ids, id)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+	toCall := make([]func(), 0, len(ids))
+	for _, id := range ids {
+		toCall = append(toCall, cleanups[id])
+	}
+	cleanups = map[int]func(){}
+	mutex.Unlock()
+
+	for _, cleanup := range toCall {
+		cleanup()
+	}
+}
+
+// Reset restores the state of the CLI before any interruption and unregisters
+// all cleanup functions.  It is meant to be used by tests.
+func Reset() {
+	mutex.Lock()
+	defer mutex.Unlock()
+	cleanups = map[int]func(){}
+	ctx, cancel = context.WithCancel(context.Background())
+	timedOut = 0
+	signaled = false
+}
+
+// StartTimeout cancels the context returned by Context() if the returned function
+// is not called within the specified duration; the returned function must be called
+// once the command completes.  As for an interruption by a signal, the cleanup
+// functions are not called until the command has returned, see Cleanup(), and
+// Run() stops waiting for a command that does not return promptly.  Nothing is
+// done for a duration that is not positive.
+func StartTimeout(timeout time.Duration) (stop func()) {
+	if timeout <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	timer := time.NewTimer(timeout)
+	go func() {
+		select {
+		case <-timer.C:
+			mutex.Lock()
+			timedOut = timeout
+			cancel()
+			mutex.Unlock()
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			timer.Stop()
+			close(done)
+		})
+	}
+}
+
+// Run runs the command and returns its error.  Once the CLI is interrupted, the command
+// is expected to return promptly; if it is still running after a grace period, for
+// example because it is blocked on an unresponsive registry, Run returns without
+// waiting for it any longer.  The error is then the one of the interruption: the
+// *TimeoutError of TimedOut(), or ErrInterrupted.
+func Run(command func() error) error {
+	result := make(chan error, 1)
+	go func() {
+		result <- command()
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-Context().Done():
+	}
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(gracePeriod):
+	}
+	if err := TimedOut(); err != nil {
+		return err
+	}
+	return ErrInterrupted
+}
+
+// TimedOut returns a *TimeoutError if the CLI was interrupted because
+// a timeout started with StartTimeout() expired, nil otherwise
+func TimedOut() error {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if timedOut == 0 {
+		return nil
+	}
+	return &TimeoutError{Timeout: timedOut}
+}
+
+// InterruptedBySignal returns true if the CLI was interrupted by a signal
+// handled with HandleSignals()
+func InterruptedBySignal() bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return signaled
+}
+
+// HandleSignals makes an interruption (e.g., Ctrl-C) of the CLI cancel the context
+// returned by Context(), so that the command stops and returns ErrInterrupted.  The
+// cleanup functions are not called until the command has returned, see Cleanup().
+// A second interruption terminates the CLI right away, as the signals are only handled
+// once.  The returned function restores the default handling of the signals.  It should
+// only be used by commands that do not run plugins, as plugins handle the interruption
+// themselves.
+func HandleSignals() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			fmt.Fprintln(os.Stderr, "\nInterrupted, cleaning up...")
+			mutex.Lock()
+			signaled = true
+			cancel()
+			mutex.Unlock()
+		case <-done:
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package interrupt
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// reset restores the initial state after a test interrupted the CLI
+func reset() {
+	Reset()
+	gracePeriod = 10 * time.Second
+}
+
+func TestInterrupt(t *testing.T) {
+	assert := assert.New(t)
+	defer reset()
+
+	var calls []string
+	RegisterCleanup(func() { calls = append(calls, "first") })
+	unregister := RegisterCleanup(func() { calls = append(calls, "unregistered") })
+	RegisterCleanup(func() { calls = append(calls, "last") })
+	unregister()
+
+	assert.False(Interrupted())
+	Interrupt()
+	assert.True(Interrupted())
+	assert.False(InterruptedBySignal())
+	assert.NotNil(Context().Err())
+
+	// The most recently registered cleanup is called first
+	assert.Equal([]string{"last", "first"}, calls)
+
+	// The cleanup functions are only called once
+	Interrupt()
+	assert.Equal([]string{"last", "first"}, calls)
+}
+
+func TestHandleSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending an interrupt signal is not supported on Windows")
+	}
+	assert := assert.New(t)
+	defer reset()
+
+	cleanedUp := false
+	RegisterCleanup(func() { cleanedUp = true })
+	stop := HandleSignals()
+	defer stop()
+
+	process, err := os.FindProcess(os.Getpid())
+	assert.NoError(err)
+	assert.NoError(process.Signal(os.Interrupt))
+
+	select {
+	case <-Context().Done():
+	case <-time.After(5 * time.Second):
+		assert.Fail("the interruption was not handled")
+	}
+	assert.True(Interrupted())
+	assert.True(InterruptedBySignal())
+
+	// The cleanup is left to the command once it returns
+	assert.False(cleanedUp)
+	Cleanup()
+	assert.True(cleanedUp)
+	stop()
+}
+
+func TestStartTimeout(t *testing.T) {
+	assert := assert.New(t)
+	defer reset()
+
+	// A command completing in time is not interrupted
+	stop := StartTimeout(time.Hour)
+	stop()
+	stop()
+	assert.False(Interrupted())
+	assert.Nil(TimedOut())
+
+	// There is no timeout by default
+	StartTimeout(0)()
+	assert.False(Interrupted())
+
+	cleanedUp := false
+	RegisterCleanup(func() { cleanedUp = true })
+	stop = StartTimeout(10 * time.Millisecond)
+	defer stop()
+
+	select {
+	case <-Context().Done():
+	case <-time.After(5 * time.Second):
+		assert.Fail("the command did not time out")
+	}
+	assert.True(Interrupted())
+	assert.EqualError(TimedOut(), "operation timed out after 10ms")
+
+	// The cleanup is left to the command once it returns
+	assert.False(cleanedUp)
+}
+
+func TestRun(t *testing.T) {
+	assert := assert.New(t)
+	defer reset()
+
+	// The error of a command that completes is returned
+	assert.EqualError(Run(func() error { return errors.New("failed") }), "failed")
+	assert.NoError(Run(func() error { return nil }))
+
+	// An interrupted command returning promptly is waited for
+	err := Run(func() error {
+		Interrupt()
+		return errors.New("stopped")
+	})
+	assert.EqualError(err, "stopped")
+
+	// An interrupted command that does not return is not waited for any longer
+	// than the grace period
+	Reset()
+	gracePeriod = 10 * time.Millisecond
+	blocked := make(chan struct{})
+	defer close(blocked)
+	err = Run(func() error {
+		Interrupt()
+		<-blocked
+		return nil
+	})
+	assert.ErrorIs(err, ErrInterrupted)
+
+	Reset()
+	stop := StartTimeout(10 * time.Millisecond)
+	defer stop()
+	err = Run(func() error {
+		<-blocked
+		return nil
+	})
+	assert.EqualError(err, "operation timed out after 10ms")
+}
@@ -25,6 +25,10 @@ type PluginInventory interface {
 	// GetPlugins returns the plugins found in the inventory that match the provided filter.
 	GetPlugins(*PluginInventoryFilter) ([]*PluginInventoryEntry, error)
 
+	// CountPlugins returns the number of plugins found in the inventory that match the provided filter,
+	// ignoring the Limit and Offset of the filter.
+	CountPlugins(*PluginInventoryFilter) (int, error)
+
 	// GetPluginGroups returns the plugin groups found in the inventory that match the provided filter.
 	GetPluginGroups(PluginGroupFilter) ([]*PluginGroup, error)
 
@@ -69,6 +73,28 @@ type PluginInventoryEntry struct {
 	Hidden bool
 	// Artifacts contains an artifact list for every available version.
 	Artifacts distribution.Artifacts
+	// Dependencies contains, for the versions that declare any, the list
+	// of other plugins that must be installed along with this plugin.
+	Dependencies map[string][]*PluginIdentifier
+	// Deprecated tells whether all the versions of the plugin are deprecated.
+	Deprecated bool
+	// DeprecationMessage explains why the plugin is deprecated, if Deprecated is true.
+	DeprecationMessage string
+	// DeprecatedVersions contains the deprecation message of each deprecated version.
+	DeprecatedVersions map[string]string
+	// MinCLIVersions contains, for the versions that declare one,
+	// the minimum version of the Tanzu CLI required by the plugin.
+	MinCLIVersions map[string]string
+	// HomepageURL is the URL of the homepage of the plugin, if any.
+	HomepageURL string
+	// DocumentationURL is the URL of the documentation of the plugin, if any.
+	DocumentationURL string
+	// IssueTrackerURL is the URL where to report issues with the plugin, if any.
+	IssueTrackerURL string
+	// UnavailableRecommendedVersion is the version recommended by the publisher when it is
+	// not available in the inventory, e.g., because it was not mirrored, and RecommendedVersion
+	// was replaced by the highest available version.  See PluginInventoryFilter.FallbackToAvailableVersion.
+	UnavailableRecommendedVersion string
 }
 
 // PluginInventoryFilter allows to specify different criteria for
@@ -90,6 +116,17 @@ type PluginInventoryFilter struct {
 	Vendor string
 	// IncludeHidden indicates if hidden plugins should be included
 	IncludeHidden bool
+	// Limit is the maximum number of plugins to return; there is no limit when 0.
+	// A plugin counts once no matter how many versions of it are returned.
+	Limit int
+	// Offset is the number of matching plugins to skip before the returned ones,
+	// in the order of their name and target.
+	Offset int
+	// FallbackToAvailableVersion makes the highest available version the recommended
+	// version of a plugin whose recommended version is not in the inventory, as happens
+	// in a partial mirror of a repository.  It only applies when the recommended version
+	// is looked up, i.e., when Version is empty or "latest".
+	FallbackToAvailableVersion bool
 }
 
 // PluginIdentifier uniquely identifies a single version of a specific plugin
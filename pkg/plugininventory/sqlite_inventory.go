@@ -53,6 +53,28 @@ const (
 	// It MUST be used, as the order of the results is required by the functions processing the results.
 	// The column order must also match the order used in getGroupNextRow().
 	groupOrderClause = "ORDER by Vendor,Publisher,GroupName,GroupVersion,PluginName,Target"
+
+	// dependencySelectClause is the SELECT section of the query used to extract plugin dependencies
+	// from the PluginDependencies table.  The column order must match the order used in getDependencyNextRow().
+	dependencySelectClause = "SELECT PluginName,Target,Version,DependencyName,DependencyTarget,DependencyVersion FROM PluginDependencies"
+
+	// deprecationSelectClause is the SELECT section of the query used to extract plugin deprecations
+	// from the PluginDeprecations table.  The column order must match the order used in getDeprecationNextRow().
+	deprecationSelectClause = "SELECT PluginName,Target,Version,Message FROM PluginDeprecations"
+
+	// binarySizeSelectClause is the SELECT section of the query used to extract the sizes of the plugin
+	// binaries from the PluginBinarySizes table.  The column order must match the order used in getBinarySizeNextRow().
+	binarySizeSelectClause = "SELECT PluginName,Target,Version,OS,Architecture,Size FROM PluginBinarySizes"
+
+	// cliCompatibilitySelectClause is the SELECT section of the query used to extract the minimum CLI version
+	// required by the plugins from the PluginCLICompatibility table.  The column order must match the order
+	// used in getCLICompatibilityNextRow().
+	cliCompatibilitySelectClause = "SELECT PluginName,Target,Version,MinCLIVersion FROM PluginCLICompatibility"
+
+	// linksSelectClause is the SELECT section of the query used to extract the URLs of the web pages
+	// about the plugins from the PluginLinks table.  The column order must match the order used in
+	// getLinksNextRow().
+	linksSelectClause = "SELECT PluginName,Target,Homepage,Documentation,IssueTracker FROM PluginLinks"
 )
 
 // Structure of each row of the PluginBinaries table within the SQLite database
@@ -85,6 +107,52 @@ type groupDBRow struct {
 	hidden        string
 }
 
+// Structure of each row of the PluginDependencies table within the SQLite database
+type dependencyDBRow struct {
+	pluginName        string
+	target            string
+	version           string
+	dependencyName    string
+	dependencyTarget  string
+	dependencyVersion string
+}
+
+// Structure of each row of the PluginDeprecations table within the SQLite database.
+// An empty version means that all the versions of the plugin are deprecated.
+type deprecationDBRow struct {
+	pluginName string
+	target     string
+	version    string
+	message    string
+}
+
+// Structure of each row of the PluginCLICompatibility table within the SQLite database
+type cliCompatibilityDBRow struct {
+	pluginName    string
+	target        string
+	version       string
+	minCLIVersion string
+}
+
+// Structure of each row of the PluginLinks table within the SQLite database
+type linksDBRow struct {
+	pluginName    string
+	target        string
+	homepage      string
+	documentation string
+	issueTracker  string
+}
+
+// Structure of each row of the PluginBinarySizes table within the SQLite database
+type binarySizeDBRow struct {
+	pluginName string
+	target     string
+	version    string
+	os         string
+	arch       string
+	size       int64
+}
+
 // NewSQLiteInventory returns a new PluginInventory connected to the data found at 'inventoryFile'.
 func NewSQLiteInventory(inventoryFile, prefix string) PluginInventory {
 	return &SQLiteInventory{
@@ -93,6 +161,30 @@ func NewSQLiteInventory(inventoryFile, prefix string) PluginInventory {
 	}
 }
 
+// ValidateInventoryDB verifies that the file 'inventoryFile' is a plugin inventory
+// database, that is an SQLite database with the tables and columns queried by SQLiteInventory.
+func ValidateInventoryDB(inventoryFile string) error {
+	if _, err := os.Stat(inventoryFile); err != nil {
+		return errors.Wrapf(err, "unable to find the inventory DB '%s'", inventoryFile)
+	}
+
+	db, err := sql.Open("sqlite", inventoryFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open the DB at '%s'", inventoryFile)
+	}
+	defer db.Close()
+
+	for _, selectClause := range []string{pluginSelectClause, groupSelectClause} {
+		// Selecting no rows is enough for the DB to check that the tables and columns exist
+		rows, err := db.Query(selectClause + " LIMIT 0")
+		if err != nil {
+			return errors.Wrapf(err, "'%s' is not a valid plugin inventory DB", inventoryFile)
+		}
+		rows.Close()
+	}
+	return nil
+}
+
 // GetAllPlugins returns all plugins found in the inventory.
 func (b *SQLiteInventory) GetAllPlugins() ([]*PluginInventoryEntry, error) {
 	return b.GetPlugins(&PluginInventoryFilter{})
@@ -128,10 +220,54 @@ func (b *SQLiteInventory) GetPlugins(filter *PluginInventoryFilter) ([]*PluginIn
 		}
 
 		// We can now use the RecommendedVersion field which was filled when parsing the DB.
+		if filter.FallbackToAvailableVersion {
+			fallbackToAvailableVersion(plugins[0])
+		}
 		filter.Version = plugins[0].RecommendedVersion
+		if plugins[0].UnavailableRecommendedVersion == "" {
+			return b.getPluginsFromDB(filter)
+		}
+
+		// The entries of the available version still carry the unavailable recommended version
+		latestPlugins, err := b.getPluginsFromDB(filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range latestPlugins {
+			p.RecommendedVersion = plugins[0].RecommendedVersion
+			p.UnavailableRecommendedVersion = plugins[0].UnavailableRecommendedVersion
+		}
+		return latestPlugins, nil
+	}
+
+	plugins, err := b.getPluginsFromDB(filter)
+	if err == nil && filter.Version == "" && filter.FallbackToAvailableVersion {
+		for _, p := range plugins {
+			fallbackToAvailableVersion(p)
+		}
 	}
+	return plugins, err
+}
 
-	return b.getPluginsFromDB(filter)
+// fallbackToAvailableVersion replaces the recommended version of the plugin by its highest
+// available version if the recommended version is not one of the versions of the plugin.
+// Pre-release versions are only used if the plugin has no other version.
+func fallbackToAvailableVersion(plugin *PluginInventoryEntry) {
+	if plugin.RecommendedVersion == "" || len(plugin.Artifacts) == 0 {
+		return
+	}
+	if _, found := plugin.Artifacts[plugin.RecommendedVersion]; found {
+		return
+	}
+	versions := make([]string, 0, len(plugin.Artifacts))
+	for v := range plugin.Artifacts {
+		versions = append(versions, v)
+	}
+	if err := utils.SortVersions(versions); err != nil {
+		fmt.Fprintf(os.Stderr, "error parsing versions for plugin %s: %v\n", plugin.Name, err)
+	}
+	plugin.UnavailableRecommendedVersion = plugin.RecommendedVersion
+	plugin.RecommendedVersion = utils.GetLatestVersion(versions, false)
 }
 
 func (b *SQLiteInventory) GetPluginGroups(filter PluginGroupFilter) ([]*PluginGroup, error) {
@@ -183,6 +319,7 @@ func (b *SQLiteInventory) getPluginsFromDB(filter *PluginInventoryFilter) ([]*Pl
 	if err != nil {
 		return nil, err
 	}
+	whereClause = addPluginPageToWhereClause(whereClause, filter)
 
 	// Build the final query with the SELECT, WHERE and ORDER clauses.
 	// The ORDER clause is essential because the parsing algorithm of extractPluginsFromRows()
@@ -194,7 +331,299 @@ func (b *SQLiteInventory) getPluginsFromDB(filter *PluginInventoryFilter) ([]*Pl
 	}
 	defer rows.Close()
 
-	return b.extractPluginsFromRows(rows)
+	plugins, err := b.extractPluginsFromRows(rows)
+	if err != nil {
+		return plugins, err
+	}
+	if err := addPluginDependencies(db, plugins); err != nil {
+		return nil, errors.Wrapf(err, "unable to get the dependencies of the plugins from the DB at '%s'", b.inventoryFile)
+	}
+	if err := addPluginDeprecations(db, plugins); err != nil {
+		return nil, errors.Wrapf(err, "unable to get the deprecations of the plugins from the DB at '%s'", b.inventoryFile)
+	}
+	addPluginBinarySizes(db, plugins)
+	addPluginCLICompatibility(db, plugins)
+	addPluginLinks(db, plugins)
+	return plugins, nil
+}
+
+// CountPlugins returns the number of plugins found in the inventory that match the provided filter,
+// ignoring the Limit and Offset of the filter.  A plugin counts once no matter how many versions it has.
+func (b *SQLiteInventory) CountPlugins(filter *PluginInventoryFilter) (int, error) {
+	// Check if the inventory file exists.
+	if _, err := os.Stat(b.inventoryFile); os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	countFilter := PluginInventoryFilter{}
+	if filter != nil {
+		countFilter = *filter
+	}
+	if countFilter.Version == cli.VersionLatest {
+		// Every plugin has a latest version
+		countFilter.Version = ""
+	}
+
+	db, err := sql.Open("sqlite", b.inventoryFile)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to open the DB at '%s'", b.inventoryFile)
+	}
+	defer db.Close()
+
+	whereClause, err := createPluginWhereClause(&countFilter)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	dbQuery := fmt.Sprintf("SELECT COUNT(*) FROM (SELECT DISTINCT PluginName,Target FROM PluginBinaries %s)", whereClause)
+	if err := db.QueryRow(dbQuery).Scan(&count); err != nil {
+		return 0, errors.Wrapf(err, "unable to count the plugins of the DB at '%s'", b.inventoryFile)
+	}
+	return count, nil
+}
+
+// addPluginPageToWhereClause restricts the WHERE clause to the page of plugins requested
+// by the Limit and Offset of the filter.  The page cannot be obtained with a LIMIT clause
+// on the main query since each plugin spans one row per version, OS and architecture.
+func addPluginPageToWhereClause(whereClause string, filter *PluginInventoryFilter) string {
+	if filter == nil || (filter.Limit <= 0 && filter.Offset <= 0) {
+		return whereClause
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		// A negative LIMIT means there is no limit in SQLite
+		limit = -1
+	}
+	pageClause := fmt.Sprintf("(PluginName,Target) IN (SELECT DISTINCT PluginName,Target FROM PluginBinaries %s ORDER BY PluginName,Target LIMIT %d OFFSET %d)",
+		whereClause, limit, filter.Offset)
+
+	if whereClause == "" {
+		return fmt.Sprintf("WHERE %s", pageClause)
+	}
+	return fmt.Sprintf("%s AND %s", whereClause, pageClause)
+}
+
+// addPluginDependencies fills the Dependencies field of the specified plugins
+// based on the content of the PluginDependencies table.
+// Older inventories do not have such a table, in which case the plugins
+// are left without dependencies.  Any other error is returned, as installing
+// a plugin without its dependencies would leave it incomplete.
+func addPluginDependencies(db *sql.DB, plugins []*PluginInventoryEntry) error {
+	if len(plugins) == 0 {
+		return nil
+	}
+
+	rows, err := db.Query(dependencySelectClause)
+	if err != nil {
+		if isMissingTableError(err) {
+			return nil
+		}
+		return errors.Wrap(err, "unable to query the dependencies of the plugins")
+	}
+	defer rows.Close()
+
+	pluginsByID := make(map[string]*PluginInventoryEntry, len(plugins))
+	for _, p := range plugins {
+		pluginsByID[catalog.PluginNameTarget(p.Name, p.Target)] = p
+	}
+
+	for rows.Next() {
+		row, err := getDependencyNextRow(rows)
+		if err != nil {
+			return err
+		}
+		target := configtypes.StringToTarget(strings.ToLower(row.target))
+		p, found := pluginsByID[catalog.PluginNameTarget(row.pluginName, target)]
+		if !found {
+			continue
+		}
+		// Only keep the dependencies of the versions that were selected
+		if _, found := p.Artifacts[row.version]; !found {
+			continue
+		}
+		if p.Dependencies == nil {
+			p.Dependencies = make(map[string][]*PluginIdentifier)
+		}
+		p.Dependencies[row.version] = append(p.Dependencies[row.version], &PluginIdentifier{
+			Name:    row.dependencyName,
+			Target:  configtypes.StringToTarget(strings.ToLower(row.dependencyTarget)),
+			Version: row.dependencyVersion,
+		})
+	}
+	return errors.Wrap(rows.Err(), "unable to read the dependencies of the plugins")
+}
+
+// isMissingTableError returns true if the query failed because the inventory does
+// not have the queried table, as is the case of inventories created by older CLIs
+func isMissingTableError(err error) bool {
+	return strings.Contains(err.Error(), "no such table")
+}
+
+// addPluginDeprecations fills the deprecation fields of the specified plugins
+// based on the content of the PluginDeprecations table.
+// Older inventories do not have such a table, in which case the plugins
+// are left as not deprecated.
+func addPluginDeprecations(db *sql.DB, plugins []*PluginInventoryEntry) error {
+	if len(plugins) == 0 {
+		return nil
+	}
+
+	rows, err := db.Query(deprecationSelectClause)
+	if err != nil {
+		if isMissingTableError(err) {
+			return nil
+		}
+		return errors.Wrap(err, "unable to query the deprecations of the plugins")
+	}
+	defer rows.Close()
+
+	pluginsByID := make(map[string]*PluginInventoryEntry, len(plugins))
+	for _, p := range plugins {
+		pluginsByID[catalog.PluginNameTarget(p.Name, p.Target)] = p
+	}
+
+	for rows.Next() {
+		row, err := getDeprecationNextRow(rows)
+		if err != nil {
+			return err
+		}
+		target := configtypes.StringToTarget(strings.ToLower(row.target))
+		p, found := pluginsByID[catalog.PluginNameTarget(row.pluginName, target)]
+		if !found {
+			continue
+		}
+		if row.version == "" {
+			p.Deprecated = true
+			p.DeprecationMessage = row.message
+			continue
+		}
+		// Only keep the deprecations of the versions that were selected
+		if _, found := p.Artifacts[row.version]; !found {
+			continue
+		}
+		if p.DeprecatedVersions == nil {
+			p.DeprecatedVersions = make(map[string]string)
+		}
+		p.DeprecatedVersions[row.version] = row.message
+	}
+	return errors.Wrap(rows.Err(), "unable to read the deprecations of the plugins")
+}
+
+// addPluginBinarySizes fills the Size field of the artifacts of the specified
+// plugins based on the content of the PluginBinarySizes table.
+// Older inventories do not have such a table, in which case the sizes
+// of the artifacts are left unknown.
+func addPluginBinarySizes(db *sql.DB, plugins []*PluginInventoryEntry) {
+	if len(plugins) == 0 {
+		return
+	}
+
+	rows, err := db.Query(binarySizeSelectClause)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	pluginsByID := make(map[string]*PluginInventoryEntry, len(plugins))
+	for _, p := range plugins {
+		pluginsByID[catalog.PluginNameTarget(p.Name, p.Target)] = p
+	}
+
+	for rows.Next() {
+		row, err := getBinarySizeNextRow(rows)
+		if err != nil {
+			return
+		}
+		target := configtypes.StringToTarget(strings.ToLower(row.target))
+		p, found := pluginsByID[catalog.PluginNameTarget(row.pluginName, target)]
+		if !found {
+			continue
+		}
+		// Only the artifacts of the versions that were selected are found
+		artifacts := p.Artifacts[row.version]
+		for i := range artifacts {
+			if artifacts[i].OS == row.os && artifacts[i].Arch == row.arch {
+				artifacts[i].Size = row.size
+			}
+		}
+	}
+}
+
+// addPluginCLICompatibility fills the MinCLIVersions field of the specified plugins
+// based on the content of the PluginCLICompatibility table.
+// Older inventories do not have such a table, in which case the plugins
+// are considered compatible with any version of the CLI.
+func addPluginCLICompatibility(db *sql.DB, plugins []*PluginInventoryEntry) {
+	if len(plugins) == 0 {
+		return
+	}
+
+	rows, err := db.Query(cliCompatibilitySelectClause)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	pluginsByID := make(map[string]*PluginInventoryEntry, len(plugins))
+	for _, p := range plugins {
+		pluginsByID[catalog.PluginNameTarget(p.Name, p.Target)] = p
+	}
+
+	for rows.Next() {
+		row, err := getCLICompatibilityNextRow(rows)
+		if err != nil {
+			return
+		}
+		target := configtypes.StringToTarget(strings.ToLower(row.target))
+		p, found := pluginsByID[catalog.PluginNameTarget(row.pluginName, target)]
+		if !found {
+			continue
+		}
+		// Only keep the requirements of the versions that were selected
+		if _, found := p.Artifacts[row.version]; !found {
+			continue
+		}
+		if p.MinCLIVersions == nil {
+			p.MinCLIVersions = make(map[string]string)
+		}
+		p.MinCLIVersions[row.version] = row.minCLIVersion
+	}
+}
+
+// addPluginLinks fills the URL fields of the specified plugins
+// based on the content of the PluginLinks table.
+// Older inventories do not have such a table, in which case the
+// plugins are left without URLs.
+func addPluginLinks(db *sql.DB, plugins []*PluginInventoryEntry) {
+	if len(plugins) == 0 {
+		return
+	}
+
+	rows, err := db.Query(linksSelectClause)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	pluginsByID := make(map[string]*PluginInventoryEntry, len(plugins))
+	for _, p := range plugins {
+		pluginsByID[catalog.PluginNameTarget(p.Name, p.Target)] = p
+	}
+
+	for rows.Next() {
+		row, err := getLinksNextRow(rows)
+		if err != nil {
+			return
+		}
+		target := configtypes.StringToTarget(strings.ToLower(row.target))
+		if p, found := pluginsByID[catalog.PluginNameTarget(row.pluginName, target)]; found {
+			p.HomepageURL = row.homepage
+			p.DocumentationURL = row.documentation
+			p.IssueTrackerURL = row.issueTracker
+		}
+	}
 }
 
 // createPluginWhereClause parses the filter and creates the WHERE clause for the DB query.
@@ -513,6 +942,81 @@ func getPluginNextRow(rows *sql.Rows) (*pluginDBRow, error) {
 	return &row, err
 }
 
+// getDependencyNextRow simply extracts the next row of data from the DB.
+func getDependencyNextRow(rows *sql.Rows) (*dependencyDBRow, error) {
+	var row dependencyDBRow
+	// The order of the fields MUST match the order specified in the
+	// SELECT query that generated the rows.
+	err := rows.Scan(
+		&row.pluginName,
+		&row.target,
+		&row.version,
+		&row.dependencyName,
+		&row.dependencyTarget,
+		&row.dependencyVersion,
+	)
+	return &row, err
+}
+
+// getDeprecationNextRow simply extracts the next row of data from the DB.
+func getDeprecationNextRow(rows *sql.Rows) (*deprecationDBRow, error) {
+	var row deprecationDBRow
+	// The order of the fields MUST match the order specified in the
+	// SELECT query that generated the rows.
+	err := rows.Scan(
+		&row.pluginName,
+		&row.target,
+		&row.version,
+		&row.message,
+	)
+	return &row, err
+}
+
+// getCLICompatibilityNextRow simply extracts the next row of data from the DB.
+func getCLICompatibilityNextRow(rows *sql.Rows) (*cliCompatibilityDBRow, error) {
+	var row cliCompatibilityDBRow
+	// The order of the fields MUST match the order specified in the
+	// SELECT query that generated the rows.
+	err := rows.Scan(
+		&row.pluginName,
+		&row.target,
+		&row.version,
+		&row.minCLIVersion,
+	)
+	return &row, err
+}
+
+// getLinksNextRow simply extracts the next row of data from the DB.
+func getLinksNextRow(rows *sql.Rows) (*linksDBRow, error) {
+	var row linksDBRow
+	// The order of the fields MUST match the order specified in the
+	// SELECT query that generated the rows.
+	err := rows.Scan(
+		&row.pluginName,
+		&row.target,
+		&row.homepage,
+		&row.documentation,
+		&row.issueTracker,
+	)
+	return &row, err
+}
+
+// getBinarySizeNextRow simply extracts the next row of data from the DB.
+func getBinarySizeNextRow(rows *sql.Rows) (*binarySizeDBRow, error) {
+	var row binarySizeDBRow
+	// The order of the fields MUST match the order specified in the
+	// SELECT query that generated the rows.
+	err := rows.Scan(
+		&row.pluginName,
+		&row.target,
+		&row.version,
+		&row.os,
+		&row.arch,
+		&row.size,
+	)
+	return &row, err
+}
+
 // getGroupNextRow simply extracts the next row of data from the DB.
 func getGroupNextRow(rows *sql.Rows) (*groupDBRow, error) {
 	var row groupDBRow
@@ -538,7 +1042,8 @@ func getGroupNextRow(rows *sql.Rows) (*groupDBRow, error) {
 func appendPlugin(allPlugins []*PluginInventoryEntry, plugin *PluginInventoryEntry) []*PluginInventoryEntry {
 	// Now that we are done gathering the information for the plugin
 	// we need to compute the recommendedVersion if it wasn't provided
-	// by the database
+	// by the database.  Pre-release versions are only recommended
+	// if the plugin has no other version.
 	if plugin.RecommendedVersion == "" && len(plugin.Artifacts) > 0 {
 		var versions []string
 		for v := range plugin.Artifacts {
@@ -547,7 +1052,7 @@ func appendPlugin(allPlugins []*PluginInventoryEntry, plugin *PluginInventoryEnt
 		if err := utils.SortVersions(versions); err != nil {
 			fmt.Fprintf(os.Stderr, "error parsing versions for plugin %s: %v\n", plugin.Name, err)
 		}
-		plugin.RecommendedVersion = versions[len(versions)-1]
+		plugin.RecommendedVersion = utils.GetLatestVersion(versions, false)
 	}
 	allPlugins = append(allPlugins, plugin)
 	return allPlugins
@@ -624,8 +1129,104 @@ func (b *SQLiteInventory) InsertPlugin(pluginInventoryEntry *PluginInventoryEntr
 
 			// Write sql statement logs if required
 			writeSQLStatementLogs(fmt.Sprintf("INSERT INTO PluginBinaries VALUES(%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v);\n", row.name, row.target, row.recommendedVersion, row.version, row.hidden, row.description, row.publisher, row.vendor, row.os, row.arch, row.digest, row.uri))
+
+			// The size of a binary is only recorded when it is known
+			if a.Size <= 0 {
+				continue
+			}
+			sizeRow := binarySizeDBRow{
+				pluginName: row.name,
+				target:     row.target,
+				version:    version,
+				os:         a.OS,
+				arch:       a.Arch,
+				size:       a.Size,
+			}
+			_, err = db.Exec("INSERT INTO PluginBinarySizes VALUES(?,?,?,?,?,?);", sizeRow.pluginName, sizeRow.target, sizeRow.version, sizeRow.os, sizeRow.arch, sizeRow.size)
+			if err != nil {
+				return errors.Wrapf(err, "unable to insert plugin binary size row %v", sizeRow)
+			}
+
+			// Write sql statement logs if required
+			writeSQLStatementLogs(fmt.Sprintf("INSERT INTO PluginBinarySizes VALUES(%v,%v,%v,%v,%v,%v);\n", sizeRow.pluginName, sizeRow.target, sizeRow.version, sizeRow.os, sizeRow.arch, sizeRow.size))
 		}
 	}
+
+	for version, dependencies := range pluginInventoryEntry.Dependencies {
+		for _, d := range dependencies {
+			row := dependencyDBRow{
+				pluginName:        pluginInventoryEntry.Name,
+				target:            string(pluginInventoryEntry.Target),
+				version:           version,
+				dependencyName:    d.Name,
+				dependencyTarget:  string(d.Target),
+				dependencyVersion: d.Version,
+			}
+
+			_, err = db.Exec("INSERT INTO PluginDependencies VALUES(?,?,?,?,?,?);", row.pluginName, row.target, row.version, row.dependencyName, row.dependencyTarget, row.dependencyVersion)
+			if err != nil {
+				return errors.Wrapf(err, "unable to insert plugin dependency row %v", row)
+			}
+
+			// Write sql statement logs if required
+			writeSQLStatementLogs(fmt.Sprintf("INSERT INTO PluginDependencies VALUES(%v,%v,%v,%v,%v,%v);\n", row.pluginName, row.target, row.version, row.dependencyName, row.dependencyTarget, row.dependencyVersion))
+		}
+	}
+
+	var deprecations []deprecationDBRow
+	if pluginInventoryEntry.Deprecated {
+		deprecations = append(deprecations, deprecationDBRow{message: pluginInventoryEntry.DeprecationMessage})
+	}
+	for version, message := range pluginInventoryEntry.DeprecatedVersions {
+		deprecations = append(deprecations, deprecationDBRow{version: version, message: message})
+	}
+	for i := range deprecations {
+		row := &deprecations[i]
+		row.pluginName = pluginInventoryEntry.Name
+		row.target = string(pluginInventoryEntry.Target)
+
+		_, err = db.Exec("INSERT INTO PluginDeprecations VALUES(?,?,?,?);", row.pluginName, row.target, row.version, row.message)
+		if err != nil {
+			return errors.Wrapf(err, "unable to insert plugin deprecation row %v", *row)
+		}
+
+		// Write sql statement logs if required
+		writeSQLStatementLogs(fmt.Sprintf("INSERT INTO PluginDeprecations VALUES(%v,%v,%v,%v);\n", row.pluginName, row.target, row.version, row.message))
+	}
+
+	for version, minCLIVersion := range pluginInventoryEntry.MinCLIVersions {
+		row := cliCompatibilityDBRow{
+			pluginName:    pluginInventoryEntry.Name,
+			target:        string(pluginInventoryEntry.Target),
+			version:       version,
+			minCLIVersion: minCLIVersion,
+		}
+		_, err = db.Exec("INSERT INTO PluginCLICompatibility VALUES(?,?,?,?);", row.pluginName, row.target, row.version, row.minCLIVersion)
+		if err != nil {
+			return errors.Wrapf(err, "unable to insert plugin CLI compatibility row %v", row)
+		}
+
+		// Write sql statement logs if required
+		writeSQLStatementLogs(fmt.Sprintf("INSERT INTO PluginCLICompatibility VALUES(%v,%v,%v,%v);\n", row.pluginName, row.target, row.version, row.minCLIVersion))
+	}
+
+	// The URLs are only recorded when the plugin has any
+	if pluginInventoryEntry.HomepageURL != "" || pluginInventoryEntry.DocumentationURL != "" || pluginInventoryEntry.IssueTrackerURL != "" {
+		row := linksDBRow{
+			pluginName:    pluginInventoryEntry.Name,
+			target:        string(pluginInventoryEntry.Target),
+			homepage:      pluginInventoryEntry.HomepageURL,
+			documentation: pluginInventoryEntry.DocumentationURL,
+			issueTracker:  pluginInventoryEntry.IssueTrackerURL,
+		}
+		_, err = db.Exec("INSERT OR REPLACE INTO PluginLinks VALUES(?,?,?,?,?);", row.pluginName, row.target, row.homepage, row.documentation, row.issueTracker)
+		if err != nil {
+			return errors.Wrapf(err, "unable to insert plugin links row %v", row)
+		}
+
+		// Write sql statement logs if required
+		writeSQLStatementLogs(fmt.Sprintf("INSERT OR REPLACE INTO PluginLinks VALUES(%v,%v,%v,%v,%v);\n", row.pluginName, row.target, row.homepage, row.documentation, row.issueTracker))
+	}
 	return nil
 }
 
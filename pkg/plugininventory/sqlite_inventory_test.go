@@ -279,6 +279,49 @@ INSERT INTO PluginBinaries VALUES(
     '2222222222',
     'vmware/tmc/linux/amd64/tmc/management-cluster:v0.0.3');
 `
+
+// createGappedPluginStmt creates a plugin whose recommended version was not mirrored
+const createGappedPluginStmt = `
+INSERT INTO PluginBinaries VALUES(
+	'isolated-cluster',
+	'global',
+	'v1.2.0',
+	'v1.0.0',
+	'false',
+	'Isolated cluster plugin',
+	'otherpublisher',
+	'othervendor',
+	'linux',
+	'amd64',
+	'0000000000',
+	'othervendor/otherpublisher/linux/amd64/global/isolated-cluster:v1.0.0');
+INSERT INTO PluginBinaries VALUES(
+	'isolated-cluster',
+	'global',
+	'v1.2.0',
+	'v1.1.0',
+	'false',
+	'Isolated cluster plugin',
+	'otherpublisher',
+	'othervendor',
+	'linux',
+	'amd64',
+	'1111111111',
+	'othervendor/otherpublisher/linux/amd64/global/isolated-cluster:v1.1.0');
+INSERT INTO PluginBinaries VALUES(
+	'isolated-cluster',
+	'global',
+	'v1.2.0',
+	'v1.2.0-rc.1',
+	'false',
+	'Isolated cluster plugin',
+	'otherpublisher',
+	'othervendor',
+	'linux',
+	'amd64',
+	'2222222222',
+	'othervendor/otherpublisher/linux/amd64/global/isolated-cluster:v1.2.0-rc.1');
+`
 const createGroupsStmt = `
 INSERT INTO PluginGroups VALUES(
 	'vmware',
@@ -442,6 +485,18 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("unable to setup DB"))
 			})
+			It("should not be a valid inventory DB", func() {
+				err = ValidateInventoryDB(dbFile.Name())
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("is not a valid plugin inventory DB"))
+			})
+		})
+		Context("With a missing DB file", func() {
+			It("should not be a valid inventory DB", func() {
+				err = ValidateInventoryDB(filepath.Join(os.TempDir(), "missing", SQliteDBFileName))
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("unable to find the inventory DB"))
+			})
 		})
 		Context("With an empty DB table", func() {
 			BeforeEach(func() {
@@ -470,6 +525,9 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(len(plugins)).To(Equal(0))
 			})
+			It("should be a valid inventory DB", func() {
+				Expect(ValidateInventoryDB(dbFile.Name())).To(Succeed())
+			})
 		})
 		Describe("With a DB table with two plugins", func() {
 			BeforeEach(func() {
@@ -658,6 +716,37 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 					Expect(p.Publisher).To(Equal("otherpublisher"))
 				})
 			})
+			Context("When getting a page of plugins", func() {
+				It("should return the plugins of the page with all their versions", func() {
+					plugins, err := inventory.GetPlugins(&PluginInventoryFilter{Limit: 1, Offset: 1})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(1))
+					Expect(plugins[0].Name).To(Equal("management-cluster"))
+					Expect(len(plugins[0].Artifacts)).To(Equal(2))
+
+					plugins, err = inventory.GetPlugins(&PluginInventoryFilter{Limit: 1})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(1))
+					Expect(plugins[0].Name).To(Equal("isolated-cluster"))
+
+					plugins, err = inventory.GetPlugins(&PluginInventoryFilter{Offset: 2})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(plugins).To(BeEmpty())
+				})
+				It("should count all the matching plugins ignoring the page", func() {
+					count, err := inventory.CountPlugins(&PluginInventoryFilter{Limit: 1, Offset: 1})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(count).To(Equal(2))
+
+					count, err = inventory.CountPlugins(&PluginInventoryFilter{IncludeHidden: true})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(count).To(Equal(3))
+
+					count, err = inventory.CountPlugins(&PluginInventoryFilter{Target: types.TargetK8s})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(count).To(Equal(1))
+				})
+			})
 			Context("When getting all plugins including hidden ones", func() {
 				It("should return a list of three plugins with no error", func() {
 					plugins, err := inventory.GetPlugins(&PluginInventoryFilter{IncludeHidden: true})
@@ -836,6 +925,89 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 		})
 	})
 
+	Describe("Getting plugins from a partially mirrored inventory", func() {
+		BeforeEach(func() {
+			tmpDir, err = os.MkdirTemp(os.TempDir(), "")
+			Expect(err).To(BeNil(), "unable to create temporary directory")
+
+			// Create DB file
+			dbFile, err = os.Create(filepath.Join(tmpDir, SQliteDBFileName))
+			Expect(err).To(BeNil())
+			// Open DB with the sqlite driver
+			db, err := sql.Open("sqlite", dbFile.Name())
+			Expect(err).To(BeNil(), "failed to open the DB for testing")
+			defer db.Close()
+
+			// Create the table
+			_, err = db.Exec(CreateTablesSchema)
+			Expect(err).To(BeNil(), "failed to create DB table for testing")
+
+			// Add plugin entries to the DB
+			_, err = db.Exec(createGappedPluginStmt)
+			Expect(err).To(BeNil(), "failed to create plugin for testing")
+
+			inventory = NewSQLiteInventory(dbFile.Name(), tmpDir)
+		})
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+		Context("When the fallback to an available version is not requested", func() {
+			It("should keep the unavailable recommended version", func() {
+				plugins, err := inventory.GetAllPlugins()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].RecommendedVersion).To(Equal("v1.2.0"))
+				Expect(plugins[0].UnavailableRecommendedVersion).To(BeEmpty())
+			})
+			It("should not find the recommended version of the plugin", func() {
+				plugins, err := inventory.GetPlugins(&PluginInventoryFilter{
+					Name:    "isolated-cluster",
+					Target:  "global",
+					Version: cli.VersionLatest,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(0))
+			})
+		})
+		Context("When the fallback to an available version is requested", func() {
+			It("should recommend the highest available stable version", func() {
+				plugins, err := inventory.GetPlugins(&PluginInventoryFilter{FallbackToAvailableVersion: true})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(len(plugins[0].Artifacts)).To(Equal(3))
+				Expect(plugins[0].RecommendedVersion).To(Equal("v1.1.0"))
+				Expect(plugins[0].UnavailableRecommendedVersion).To(Equal("v1.2.0"))
+			})
+			It("should return the highest available version as the recommended version of the plugin", func() {
+				plugins, err := inventory.GetPlugins(&PluginInventoryFilter{
+					Name:                       "isolated-cluster",
+					Target:                     "global",
+					Version:                    cli.VersionLatest,
+					OS:                         "linux",
+					Arch:                       "amd64",
+					FallbackToAvailableVersion: true,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(len(plugins[0].Artifacts)).To(Equal(1))
+				Expect(plugins[0].Artifacts["v1.1.0"]).ToNot(BeNil())
+				Expect(plugins[0].RecommendedVersion).To(Equal("v1.1.0"))
+				Expect(plugins[0].UnavailableRecommendedVersion).To(Equal("v1.2.0"))
+			})
+			It("should not change the recommended version when getting a specific version", func() {
+				plugins, err := inventory.GetPlugins(&PluginInventoryFilter{
+					Name:                       "isolated-cluster",
+					Version:                    "v1.0.0",
+					FallbackToAvailableVersion: true,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].RecommendedVersion).To(Equal("v1.2.0"))
+				Expect(plugins[0].UnavailableRecommendedVersion).To(BeEmpty())
+			})
+		})
+	})
+
 	Describe("Getting plugin groups from inventory", func() {
 		Context("With an empty DB file", func() {
 			BeforeEach(func() {
@@ -1323,6 +1495,254 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 				Expect(err.Error()).To(ContainSubstring("UNIQUE constraint failed"))
 			})
 		})
+		Context("When inserting a plugin with dependencies", func() {
+			It("getplugins should return the dependencies of the selected versions only", func() {
+				pluginWithDeps := piEntry1
+				pluginWithDeps.Dependencies = map[string][]*PluginIdentifier{
+					"v0.28.0": {
+						{Name: "isolated-cluster", Target: types.TargetGlobal, Version: "v1.2.3"},
+					},
+				}
+				err = inventory.InsertPlugin(&pluginWithDeps)
+				Expect(err).To(BeNil(), "failed to insert plugin with dependencies")
+				err = inventory.InsertPlugin(&piEntry2)
+				Expect(err).To(BeNil(), "failed to insert plugin2")
+
+				plugins, err := inventory.GetPlugins(&PluginInventoryFilter{Name: "management-cluster", Target: types.TargetK8s})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(len(plugins[0].Dependencies)).To(Equal(1))
+				deps := plugins[0].Dependencies["v0.28.0"]
+				Expect(len(deps)).To(Equal(1))
+				Expect(deps[0].Name).To(Equal("isolated-cluster"))
+				Expect(deps[0].Target).To(Equal(types.TargetGlobal))
+				Expect(deps[0].Version).To(Equal("v1.2.3"))
+
+				// A plugin without dependencies should not have any
+				plugins, err = inventory.GetPlugins(&PluginInventoryFilter{Name: "isolated-cluster", Target: types.TargetGlobal})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].Dependencies).To(BeNil())
+			})
+			It("getplugins should ignore dependencies when the inventory does not support them", func() {
+				err = inventory.InsertPlugin(&piEntry1)
+				Expect(err).To(BeNil(), "failed to insert plugin1")
+
+				// Older inventories don't have the PluginDependencies table
+				db, err := sql.Open("sqlite", dbFile.Name())
+				Expect(err).To(BeNil())
+				_, err = db.Exec("DROP TABLE PluginDependencies;")
+				Expect(err).To(BeNil())
+				db.Close()
+
+				plugins, err := inventory.GetPlugins(&PluginInventoryFilter{Name: piEntry1.Name, Target: piEntry1.Target})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].Dependencies).To(BeNil())
+			})
+			It("getplugins should return an error when the dependencies cannot be read", func() {
+				err = inventory.InsertPlugin(&piEntry1)
+				Expect(err).To(BeNil(), "failed to insert plugin1")
+
+				// A corrupt PluginDependencies table
+				db, err := sql.Open("sqlite", dbFile.Name())
+				Expect(err).To(BeNil())
+				_, err = db.Exec("DROP TABLE PluginDependencies; CREATE TABLE PluginDependencies (PluginName TEXT);")
+				Expect(err).To(BeNil())
+				db.Close()
+
+				_, err = inventory.GetPlugins(&PluginInventoryFilter{Name: piEntry1.Name, Target: piEntry1.Target})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("unable to get the dependencies of the plugins"))
+			})
+		})
+		Context("When inserting deprecated plugins", func() {
+			It("getplugins should return the deprecation of the plugins and of their versions", func() {
+				pluginWithDeprecatedVersion := piEntry1
+				pluginWithDeprecatedVersion.DeprecatedVersions = map[string]string{
+					"v0.28.0": "please use v0.29.0 or later",
+				}
+				err = inventory.InsertPlugin(&pluginWithDeprecatedVersion)
+				Expect(err).To(BeNil(), "failed to insert plugin with a deprecated version")
+				deprecatedPlugin := piEntry2
+				deprecatedPlugin.Deprecated = true
+				deprecatedPlugin.DeprecationMessage = "replaced by another plugin"
+				err = inventory.InsertPlugin(&deprecatedPlugin)
+				Expect(err).To(BeNil(), "failed to insert deprecated plugin")
+				err = inventory.InsertPlugin(&piEntry3)
+				Expect(err).To(BeNil(), "failed to insert plugin3")
+
+				plugins, err := inventory.GetPlugins(&PluginInventoryFilter{Name: piEntry1.Name, Target: piEntry1.Target})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].Deprecated).To(BeFalse())
+				Expect(plugins[0].DeprecatedVersions).To(Equal(map[string]string{"v0.28.0": "please use v0.29.0 or later"}))
+
+				plugins, err = inventory.GetPlugins(&PluginInventoryFilter{Name: piEntry2.Name, Target: piEntry2.Target})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].Deprecated).To(BeTrue())
+				Expect(plugins[0].DeprecationMessage).To(Equal("replaced by another plugin"))
+				Expect(plugins[0].DeprecatedVersions).To(BeNil())
+
+				// A plugin that is not deprecated should not have any deprecation
+				plugins, err = inventory.GetPlugins(&PluginInventoryFilter{Name: piEntry3.Name, Target: piEntry3.Target})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].Deprecated).To(BeFalse())
+				Expect(plugins[0].DeprecatedVersions).To(BeNil())
+			})
+			It("getplugins should ignore deprecations when the inventory does not support them", func() {
+				err = inventory.InsertPlugin(&piEntry1)
+				Expect(err).To(BeNil(), "failed to insert plugin1")
+
+				// Older inventories don't have the PluginDeprecations table
+				db, err := sql.Open("sqlite", dbFile.Name())
+				Expect(err).To(BeNil())
+				_, err = db.Exec("DROP TABLE PluginDeprecations;")
+				Expect(err).To(BeNil())
+				db.Close()
+
+				plugins, err := inventory.GetPlugins(&PluginInventoryFilter{Name: piEntry1.Name, Target: piEntry1.Target})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].Deprecated).To(BeFalse())
+				Expect(plugins[0].DeprecatedVersions).To(BeNil())
+			})
+			It("getplugins should return an error when the deprecations cannot be read", func() {
+				err = inventory.InsertPlugin(&piEntry1)
+				Expect(err).To(BeNil(), "failed to insert plugin1")
+
+				// A corrupt PluginDeprecations table
+				db, err := sql.Open("sqlite", dbFile.Name())
+				Expect(err).To(BeNil())
+				_, err = db.Exec("DROP TABLE PluginDeprecations; CREATE TABLE PluginDeprecations (PluginName TEXT);")
+				Expect(err).To(BeNil())
+				db.Close()
+
+				_, err = inventory.GetPlugins(&PluginInventoryFilter{Name: piEntry1.Name, Target: piEntry1.Target})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("unable to get the deprecations of the plugins"))
+			})
+		})
+		Context("When inserting plugins requiring a minimum CLI version", func() {
+			It("getplugins should return the minimum CLI version required by the versions that declare one", func() {
+				pluginWithRequirement := piEntry1
+				pluginWithRequirement.MinCLIVersions = map[string]string{
+					"v0.28.0": "v1.1.0",
+				}
+				err = inventory.InsertPlugin(&pluginWithRequirement)
+				Expect(err).To(BeNil(), "failed to insert plugin with a minimum CLI version")
+				err = inventory.InsertPlugin(&piEntry2)
+				Expect(err).To(BeNil(), "failed to insert plugin2")
+
+				plugins, err := inventory.GetPlugins(&PluginInventoryFilter{Name: piEntry1.Name, Target: piEntry1.Target})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].MinCLIVersions).To(Equal(map[string]string{"v0.28.0": "v1.1.0"}))
+
+				plugins, err = inventory.GetPlugins(&PluginInventoryFilter{Name: piEntry2.Name, Target: piEntry2.Target})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].MinCLIVersions).To(BeNil())
+			})
+			It("getplugins should ignore the minimum CLI version when the inventory does not support it", func() {
+				err = inventory.InsertPlugin(&piEntry1)
+				Expect(err).To(BeNil(), "failed to insert plugin1")
+
+				// Older inventories don't have the PluginCLICompatibility table
+				db, err := sql.Open("sqlite", dbFile.Name())
+				Expect(err).To(BeNil())
+				_, err = db.Exec("DROP TABLE PluginCLICompatibility;")
+				Expect(err).To(BeNil())
+				db.Close()
+
+				plugins, err := inventory.GetPlugins(&PluginInventoryFilter{Name: piEntry1.Name, Target: piEntry1.Target})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].MinCLIVersions).To(BeNil())
+			})
+		})
+		Context("When inserting plugins with the URLs of their web pages", func() {
+			It("getplugins should return the URLs of the plugins that have any", func() {
+				pluginWithLinks := piEntry1
+				pluginWithLinks.HomepageURL = "https://example.com/management-cluster"
+				pluginWithLinks.IssueTrackerURL = "https://example.com/management-cluster/issues"
+				err = inventory.InsertPlugin(&pluginWithLinks)
+				Expect(err).To(BeNil(), "failed to insert plugin with links")
+				err = inventory.InsertPlugin(&piEntry2)
+				Expect(err).To(BeNil(), "failed to insert plugin2")
+
+				plugins, err := inventory.GetPlugins(&PluginInventoryFilter{Name: piEntry1.Name, Target: piEntry1.Target})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].HomepageURL).To(Equal("https://example.com/management-cluster"))
+				Expect(plugins[0].DocumentationURL).To(BeEmpty())
+				Expect(plugins[0].IssueTrackerURL).To(Equal("https://example.com/management-cluster/issues"))
+
+				plugins, err = inventory.GetPlugins(&PluginInventoryFilter{Name: piEntry2.Name, Target: piEntry2.Target})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].HomepageURL).To(BeEmpty())
+			})
+			It("getplugins should ignore the URLs when the inventory does not support them", func() {
+				err = inventory.InsertPlugin(&piEntry1)
+				Expect(err).To(BeNil(), "failed to insert plugin1")
+
+				// Older inventories don't have the PluginLinks table
+				db, err := sql.Open("sqlite", dbFile.Name())
+				Expect(err).To(BeNil())
+				_, err = db.Exec("DROP TABLE PluginLinks;")
+				Expect(err).To(BeNil())
+				db.Close()
+
+				plugins, err := inventory.GetPlugins(&PluginInventoryFilter{Name: piEntry1.Name, Target: piEntry1.Target})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].HomepageURL).To(BeEmpty())
+			})
+		})
+		Context("When inserting plugins with the sizes of their binaries", func() {
+			It("getplugins should return the size of the binaries that have one", func() {
+				pluginWithSizes := piEntry1
+				pluginWithSizes.Artifacts = distribution.Artifacts{
+					"v0.28.0": []distribution.Artifact{
+						{OS: "linux", Arch: "amd64", Digest: "0000000000", Image: "vmware/tkg/linux/amd64/k8s/management-cluster:v0.28.0", Size: 52428800},
+						{OS: "darwin", Arch: "amd64", Digest: "1111111111", Image: "vmware/tkg/darwin/amd64/k8s/management-cluster:v0.28.0", Size: 53477376},
+						{OS: "windows", Arch: "amd64", Digest: "2222222222", Image: "vmware/tkg/windows/amd64/k8s/management-cluster:v0.28.0"},
+					},
+				}
+				err = inventory.InsertPlugin(&pluginWithSizes)
+				Expect(err).To(BeNil(), "failed to insert plugin with binary sizes")
+
+				plugins, err := inventory.GetPlugins(&PluginInventoryFilter{Name: piEntry1.Name, Target: piEntry1.Target})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				sizes := make(map[string]int64)
+				for _, a := range plugins[0].Artifacts["v0.28.0"] {
+					sizes[a.OS] = a.Size
+				}
+				Expect(sizes).To(Equal(map[string]int64{"linux": 52428800, "darwin": 53477376, "windows": 0}))
+			})
+			It("getplugins should leave the sizes unknown when the inventory does not support them", func() {
+				err = inventory.InsertPlugin(&piEntry1)
+				Expect(err).To(BeNil(), "failed to insert plugin1")
+
+				// Older inventories don't have the PluginBinarySizes table
+				db, err := sql.Open("sqlite", dbFile.Name())
+				Expect(err).To(BeNil())
+				_, err = db.Exec("DROP TABLE PluginBinarySizes;")
+				Expect(err).To(BeNil())
+				db.Close()
+
+				plugins, err := inventory.GetPlugins(&PluginInventoryFilter{Name: piEntry1.Name, Target: piEntry1.Target})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				for _, a := range plugins[0].Artifacts["v0.28.0"] {
+					Expect(a.Size).To(BeZero())
+				}
+			})
+		})
 	})
 
 	Describe("Inserting plugin-groups to inventory and verifying it with GetPluginGroups", func() {
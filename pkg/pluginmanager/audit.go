@@ -0,0 +1,139 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
+)
+
+// The statuses of the audit of an installed plugin
+const (
+	// PluginAuditStatusVerified means the binary matches the digest of the plugin inventory
+	PluginAuditStatusVerified = "verified"
+	// PluginAuditStatusMismatch means the binary does not match the digest of the plugin inventory
+	PluginAuditStatusMismatch = "mismatch"
+	// PluginAuditStatusMissingBinary means the binary of the plugin cannot be read
+	PluginAuditStatusMissingBinary = "missing-binary"
+	// PluginAuditStatusSourceNotConfigured means the plugin was installed from a
+	// discovery source which is no longer configured
+	PluginAuditStatusSourceNotConfigured = "source-not-configured"
+	// PluginAuditStatusUnverifiable means no plugin inventory provides the digest of
+	// the installed version, e.g., for a plugin installed from a local source
+	PluginAuditStatusUnverifiable = "unverifiable"
+)
+
+// PluginAuditResult is the integrity and provenance report of an installed plugin
+type PluginAuditResult struct {
+	Name           string             `json:"name" yaml:"name"`
+	Target         configtypes.Target `json:"target" yaml:"target"`
+	Version        string             `json:"version" yaml:"version"`
+	Source         string             `json:"source" yaml:"source"`
+	ExpectedDigest string             `json:"expectedDigest" yaml:"expectedDigest"`
+	ActualDigest   string             `json:"actualDigest" yaml:"actualDigest"`
+	Status         string             `json:"status" yaml:"status"`
+}
+
+// Failed returns true if the binary of the plugin is missing or does not
+// match the digest found in the plugin inventory
+func (r *PluginAuditResult) Failed() bool {
+	return r.Status == PluginAuditStatusMismatch || r.Status == PluginAuditStatusMissingBinary
+}
+
+// AuditInstalledPlugins compares the SHA256 hash of the binary of each installed plugin
+// with the digest of the artifact found in the plugin inventory of its discovery source,
+// which is the same verification done when a plugin is downloaded.  The results of all
+// installed plugins are returned even if some discovery sources cannot be read, in which
+// case an error is also returned.
+func AuditInstalledPlugins() ([]*PluginAuditResult, error) {
+	installedPlugins, err := pluginsupplier.GetInstalledPlugins()
+	if err != nil {
+		return nil, err
+	}
+	discoveries, err := getPluginDiscoveries()
+	if err != nil {
+		return nil, err
+	}
+
+	configuredSources := make(map[string]bool, len(discoveries))
+	for _, d := range discoveries {
+		configuredSources[discovery.GetDiscoveryName(d)] = true
+	}
+
+	// The plugins are not merged across discovery sources so that the digest
+	// is looked up in the discovery source the plugin was installed from
+	discoveredPlugins, discoveryErr := discoverSpecificPlugins(discoveries)
+	discoveredByID := make(map[string][]*discovery.Discovered)
+	for i := range discoveredPlugins {
+		id := catalog.PluginNameTarget(discoveredPlugins[i].Name, discoveredPlugins[i].Target)
+		discoveredByID[id] = append(discoveredByID[id], &discoveredPlugins[i])
+	}
+
+	results := make([]*PluginAuditResult, 0, len(installedPlugins))
+	for i := range installedPlugins {
+		id := catalog.PluginNameTarget(installedPlugins[i].Name, installedPlugins[i].Target)
+		results = append(results, auditPlugin(&installedPlugins[i], discoveredByID[id], configuredSources))
+	}
+	return results, discoveryErr
+}
+
+// auditPlugin audits an installed plugin against the entries of that plugin
+// found in the discovery sources
+func auditPlugin(plugin *cli.PluginInfo, discovered []*discovery.Discovered, configuredSources map[string]bool) *PluginAuditResult {
+	result := &PluginAuditResult{
+		Name:    plugin.Name,
+		Target:  plugin.Target,
+		Version: plugin.Version,
+		Source:  plugin.Discovery,
+	}
+
+	if b, err := os.ReadFile(plugin.InstallationPath); err == nil {
+		result.ActualDigest = fmt.Sprintf("%x", sha256.Sum256(b))
+	}
+	result.ExpectedDigest = getExpectedDigest(plugin, discovered)
+
+	switch {
+	case result.ActualDigest == "":
+		result.Status = PluginAuditStatusMissingBinary
+	case result.ExpectedDigest != "" && result.ExpectedDigest != result.ActualDigest:
+		result.Status = PluginAuditStatusMismatch
+	case plugin.Discovery != "" && !configuredSources[plugin.Discovery]:
+		result.Status = PluginAuditStatusSourceNotConfigured
+	case result.ExpectedDigest == "":
+		result.Status = PluginAuditStatusUnverifiable
+	default:
+		result.Status = PluginAuditStatusVerified
+	}
+	return result
+}
+
+// getExpectedDigest returns the digest of the installed version of the plugin for the
+// current platform, preferably as provided by the discovery source it was installed from
+func getExpectedDigest(plugin *cli.PluginInfo, discovered []*discovery.Discovered) string {
+	var digest string
+	for _, p := range discovered {
+		if p.Distribution == nil {
+			continue
+		}
+		d, err := p.Distribution.GetDigest(plugin.Version, cli.GOOS, cli.GOARCH)
+		if err != nil || d == "" {
+			continue
+		}
+		if p.Source == plugin.Discovery {
+			return d
+		}
+		if digest == "" {
+			digest = d
+		}
+	}
+	return digest
+}
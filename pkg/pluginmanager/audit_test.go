@@ -0,0 +1,118 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+)
+
+func TestAuditPlugin(t *testing.T) {
+	assertions := assert.New(t)
+
+	dir, err := os.MkdirTemp("", "audit")
+	assertions.Nil(err)
+	defer os.RemoveAll(dir)
+
+	binary := []byte("plugin binary")
+	digest := fmt.Sprintf("%x", sha256.Sum256(binary))
+	binaryPath := filepath.Join(dir, "myplugin")
+	assertions.Nil(os.WriteFile(binaryPath, binary, 0755))
+
+	newDiscovered := func(source, digest string) *discovery.Discovered {
+		return &discovery.Discovered{
+			Name:   "myplugin",
+			Target: configtypes.TargetK8s,
+			Source: source,
+			Distribution: distribution.Artifacts{
+				"v1.0.0": []distribution.Artifact{{OS: cli.GOOS, Arch: cli.GOARCH, Digest: digest}},
+			},
+		}
+	}
+	plugin := &cli.PluginInfo{
+		Name:             "myplugin",
+		Target:           configtypes.TargetK8s,
+		Version:          "v1.0.0",
+		Discovery:        "default",
+		InstallationPath: binaryPath,
+	}
+	configuredSources := map[string]bool{"default": true, "other": true}
+
+	// The binary matches the digest of its discovery source
+	result := auditPlugin(plugin, []*discovery.Discovered{newDiscovered("other", "1234"), newDiscovered("default", digest)}, configuredSources)
+	assertions.Equal(PluginAuditStatusVerified, result.Status)
+	assertions.Equal(digest, result.ExpectedDigest)
+	assertions.Equal(digest, result.ActualDigest)
+	assertions.Equal("default", result.Source)
+	assertions.False(result.Failed())
+
+	// The discovery source is no longer configured but another one provides the same digest
+	result = auditPlugin(plugin, []*discovery.Discovered{newDiscovered("other", digest)}, map[string]bool{"other": true})
+	assertions.Equal(PluginAuditStatusSourceNotConfigured, result.Status)
+	assertions.Equal(digest, result.ExpectedDigest)
+	assertions.False(result.Failed())
+
+	// No discovery source provides the installed version
+	result = auditPlugin(plugin, nil, configuredSources)
+	assertions.Equal(PluginAuditStatusUnverifiable, result.Status)
+	assertions.Empty(result.ExpectedDigest)
+	assertions.Equal(digest, result.ActualDigest)
+	assertions.False(result.Failed())
+
+	// The binary has been tampered with
+	assertions.Nil(os.WriteFile(binaryPath, []byte("tampered plugin binary"), 0755))
+	result = auditPlugin(plugin, []*discovery.Discovered{newDiscovered("default", digest)}, configuredSources)
+	assertions.Equal(PluginAuditStatusMismatch, result.Status)
+	assertions.Equal(digest, result.ExpectedDigest)
+	assertions.Equal(fmt.Sprintf("%x", sha256.Sum256([]byte("tampered plugin binary"))), result.ActualDigest)
+	assertions.True(result.Failed())
+
+	// The binary has been removed
+	assertions.Nil(os.Remove(binaryPath))
+	result = auditPlugin(plugin, []*discovery.Discovered{newDiscovered("default", digest)}, configuredSources)
+	assertions.Equal(PluginAuditStatusMissingBinary, result.Status)
+	assertions.Empty(result.ActualDigest)
+	assertions.True(result.Failed())
+}
+
+func TestAuditInstalledPlugins(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	err := InstallStandalonePlugin("login", "v0.2.0", configtypes.TargetUnknown)
+	assertions.Nil(err)
+
+	results, err := AuditInstalledPlugins()
+	assertions.Nil(err)
+	assertions.Equal(1, len(results))
+	assertions.Equal("login", results[0].Name)
+	assertions.Equal(configtypes.TargetGlobal, results[0].Target)
+	assertions.Equal("v0.2.0", results[0].Version)
+
+	// The test plugin inventory does not contain the real digests of the test
+	// binaries, which is what a tampered binary looks like
+	expectedDigest := digestForAMD64
+	if cli.GOARCH == cli.DarwinARM64.Arch() {
+		expectedDigest = digestForARM64
+	}
+	assertions.Equal(expectedDigest, results[0].ExpectedDigest)
+	assertions.NotEmpty(results[0].ActualDigest)
+	assertions.Equal(PluginAuditStatusMismatch, results[0].Status)
+	assertions.True(results[0].Failed())
+}
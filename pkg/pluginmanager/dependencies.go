@@ -0,0 +1,205 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+// resolvedPlugin is a specific version of a discovered plugin
+// that was selected to be installed.
+type resolvedPlugin struct {
+	plugin  *discovery.Discovered
+	version string
+}
+
+// dependencyResolver computes the set of plugins that must be installed
+// for a plugin to be usable, following dependencies transitively.
+type dependencyResolver struct {
+	// discoverFunc looks up the plugin matching a dependency
+	discoverFunc func(dep *plugininventory.PluginIdentifier) (*discovery.Discovered, error)
+	// resolved holds the plugins already resolved, keyed by name and target
+	resolved map[string]*resolvedPlugin
+	// inProgress holds the plugins being resolved, used to detect cycles
+	inProgress map[string]bool
+	// order is the list of resolved plugins with dependencies before their dependents
+	order []*resolvedPlugin
+}
+
+func newDependencyResolver(discoverFunc func(dep *plugininventory.PluginIdentifier) (*discovery.Discovered, error)) *dependencyResolver {
+	return &dependencyResolver{
+		discoverFunc: discoverFunc,
+		resolved:     make(map[string]*resolvedPlugin),
+		inProgress:   make(map[string]bool),
+	}
+}
+
+// resolvePluginDependencies returns the list of plugins to install for the
+// specified plugin version, including the plugin itself.  The list is ordered
+// so that every dependency comes before the plugins requiring it.
+// When the plugin does not declare any dependency, the list only contains the plugin.
+func resolvePluginDependencies(p *discovery.Discovered, version string) ([]*resolvedPlugin, error) {
+	resolver := newDependencyResolver(discoverPluginDependency)
+	if err := resolver.resolve(p, version, nil); err != nil {
+		return nil, err
+	}
+	return resolver.order, nil
+}
+
+func (r *dependencyResolver) resolve(p *discovery.Discovered, version string, path []string) error {
+	if version == "" || version == cli.VersionLatest {
+		version = p.RecommendedVersion
+	}
+
+	key := catalog.PluginNameTarget(p.Name, p.Target)
+	id := pluginIDString(p.Name, p.Target, version)
+	if r.inProgress[key] {
+		return errors.Errorf("dependency cycle detected: %s", strings.Join(append(path, id), " -> "))
+	}
+	if existing, found := r.resolved[key]; found {
+		if existing.version != version {
+			return errors.Errorf("conflicting versions required for plugin '%s': '%s' and '%s'", existing.plugin.Name, existing.version, version)
+		}
+		return nil
+	}
+
+	r.inProgress[key] = true
+	path = append(path, id)
+	for _, dep := range p.Dependencies[version] {
+		depPlugin, err := r.discoverFunc(dep)
+		if err != nil {
+			return errors.Wrapf(err, "unable to resolve dependency '%s' of plugin '%s'", dep.Name, id)
+		}
+		// A dependency is installed in the same context as the plugin requiring it
+		depPlugin.ContextName = p.ContextName
+		if err := r.resolve(depPlugin, depPlugin.RecommendedVersion, path); err != nil {
+			return err
+		}
+	}
+	delete(r.inProgress, key)
+
+	rp := &resolvedPlugin{plugin: p, version: version}
+	r.resolved[key] = rp
+	r.order = append(r.order, rp)
+	return nil
+}
+
+// discoverPluginDependency finds the plugin matching the specified dependency
+// amongst the configured discovery sources.  The version of the returned plugin
+// to use is its RecommendedVersion.
+func discoverPluginDependency(dep *plugininventory.PluginIdentifier) (*discovery.Discovered, error) {
+	discoveries, err := getPluginDiscoveries()
+	if err != nil {
+		return nil, err
+	}
+	criteria := &discovery.PluginDiscoveryCriteria{
+		Name:    dep.Name,
+		Target:  dep.Target,
+		Version: dep.Version,
+		OS:      cli.GOOS,
+		Arch:    cli.GOARCH,
+	}
+	errorList := make([]error, 0)
+	availablePlugins, err := discoverSpecificPlugins(discoveries, discovery.WithPluginDiscoveryCriteria(criteria))
+	if err != nil {
+		errorList = append(errorList, err)
+	}
+
+	availablePlugins = mergeDuplicatePlugins(availablePlugins)
+	for i := range availablePlugins {
+		if availablePlugins[i].Name == dep.Name &&
+			(dep.Target == configtypes.TargetUnknown || dep.Target == availablePlugins[i].Target) {
+			return &availablePlugins[i], nil
+		}
+	}
+	errorList = append(errorList, errors.Errorf("unable to find plugin '%v' matching version '%v' for target '%s'", dep.Name, dep.Version, string(dep.Target)))
+	return nil, kerrors.NewAggregate(errorList)
+}
+
+// installPluginWithDependencies installs the specified plugin version
+// after installing any plugin it depends on.  The returned result is the
+// one of the specified plugin.  If reinstall is true, the specified plugin
+// is installed again even if that version is already installed; the plugins
+// it depends on are only installed if they are not already.  The post-install command
+// of the plugins is not run if skipPostInstall is true.  Only the binary of the specified
+// plugin is installed under installDir, if not empty.  Nothing is installed if any of
+// the plugins requires a more recent version of the CLI, unless force is true.
+func installPluginWithDependencies(p *discovery.Discovered, version string, reinstall, skipPostInstall, force bool, installDir string) (*InstallResult, error) {
+	plugins, err := resolvePluginDependencies(p, version)
+	if err != nil {
+		return nil, err
+	}
+	for _, rp := range plugins {
+		if err := checkCLICompatibility(rp.plugin, rp.version, force); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(plugins) > 1 {
+		clilog.Infof("Plugin '%s' requires the installation of: %s", pluginIDString(p.Name, p.Target, plugins[len(plugins)-1].version), resolvedPluginsString(plugins[:len(plugins)-1]))
+	}
+
+	var result *InstallResult
+	for i, rp := range plugins {
+		// The specified plugin is the last one to be installed
+		last := i == len(plugins)-1
+		pluginInstallDir := ""
+		if last {
+			pluginInstallDir = installDir
+		}
+		if result, err = installOrUpgradePlugin(rp.plugin, rp.version, false, reinstall && last, skipPostInstall, pluginInstallDir); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// ResolvePluginDependencies returns the plugins that would be installed when
+// installing the specified plugin, including the plugin itself.
+// The plugins are returned in installation order.
+func ResolvePluginDependencies(pluginName, version string, target configtypes.Target, options ...PluginManagerOptions) ([]*plugininventory.PluginIdentifier, error) {
+	var pluginsToInstall []*plugininventory.PluginIdentifier
+	err := selectPluginForInstallation(pluginName, version, target, "", func(p *discovery.Discovered) error {
+		plugins, err := resolvePluginDependencies(p, p.RecommendedVersion)
+		if err != nil {
+			return err
+		}
+		for _, rp := range plugins {
+			pluginsToInstall = append(pluginsToInstall, &plugininventory.PluginIdentifier{
+				Name:    rp.plugin.Name,
+				Target:  rp.plugin.Target,
+				Version: rp.version,
+			})
+		}
+		return nil
+	}, options...)
+	return pluginsToInstall, err
+}
+
+func pluginIDString(name string, target configtypes.Target, version string) string {
+	if target == configtypes.TargetUnknown {
+		return fmt.Sprintf("%s:%s", name, version)
+	}
+	return fmt.Sprintf("%s/%s:%s", name, target, version)
+}
+
+func resolvedPluginsString(plugins []*resolvedPlugin) string {
+	ids := make([]string, 0, len(plugins))
+	for _, rp := range plugins {
+		ids = append(ids, pluginIDString(rp.plugin.Name, rp.plugin.Target, rp.version))
+	}
+	return strings.Join(ids, ", ")
+}
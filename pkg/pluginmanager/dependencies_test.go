@@ -0,0 +1,163 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+func newTestDiscoveredWithDeps(name, version string, deps ...*plugininventory.PluginIdentifier) *discovery.Discovered {
+	p := &discovery.Discovered{
+		Name:               name,
+		Target:             configtypes.TargetGlobal,
+		RecommendedVersion: version,
+		SupportedVersions:  []string{version},
+	}
+	if len(deps) > 0 {
+		p.Dependencies = map[string][]*plugininventory.PluginIdentifier{version: deps}
+	}
+	return p
+}
+
+func dependsOn(name, version string) *plugininventory.PluginIdentifier {
+	return &plugininventory.PluginIdentifier{Name: name, Target: configtypes.TargetGlobal, Version: version}
+}
+
+func TestResolvePluginDependencies(t *testing.T) {
+	tests := []struct {
+		name        string
+		plugin      *discovery.Discovered
+		available   []*discovery.Discovered
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name:     "no dependencies",
+			plugin:   newTestDiscoveredWithDeps("p1", "v1.0.0"),
+			expected: []string{"p1/global:v1.0.0"},
+		},
+		{
+			name:   "transitive dependencies",
+			plugin: newTestDiscoveredWithDeps("p1", "v1.0.0", dependsOn("p2", "v2.0.0")),
+			available: []*discovery.Discovered{
+				newTestDiscoveredWithDeps("p2", "v2.0.0", dependsOn("p3", "v3.0.0")),
+				newTestDiscoveredWithDeps("p3", "v3.0.0"),
+			},
+			expected: []string{"p3/global:v3.0.0", "p2/global:v2.0.0", "p1/global:v1.0.0"},
+		},
+		{
+			name:   "shared dependency with the same version",
+			plugin: newTestDiscoveredWithDeps("p1", "v1.0.0", dependsOn("p2", "v2.0.0"), dependsOn("p3", "v3.0.0")),
+			available: []*discovery.Discovered{
+				newTestDiscoveredWithDeps("p2", "v2.0.0", dependsOn("p3", "v3.0.0")),
+				newTestDiscoveredWithDeps("p3", "v3.0.0"),
+			},
+			expected: []string{"p3/global:v3.0.0", "p2/global:v2.0.0", "p1/global:v1.0.0"},
+		},
+		{
+			name:   "dependency cycle",
+			plugin: newTestDiscoveredWithDeps("p1", "v1.0.0", dependsOn("p2", "v2.0.0")),
+			available: []*discovery.Discovered{
+				newTestDiscoveredWithDeps("p2", "v2.0.0", dependsOn("p1", "v1.0.0")),
+				newTestDiscoveredWithDeps("p1", "v1.0.0", dependsOn("p2", "v2.0.0")),
+			},
+			expectedErr: "dependency cycle detected: p1/global:v1.0.0 -> p2/global:v2.0.0 -> p1/global:v1.0.0",
+		},
+		{
+			name:   "version conflict",
+			plugin: newTestDiscoveredWithDeps("p1", "v1.0.0", dependsOn("p2", "v2.0.0"), dependsOn("p3", "v3.0.0")),
+			available: []*discovery.Discovered{
+				newTestDiscoveredWithDeps("p2", "v2.0.0", dependsOn("p3", "v3.1.0")),
+				newTestDiscoveredWithDeps("p3", "v3.0.0"),
+				newTestDiscoveredWithDeps("p3", "v3.1.0"),
+			},
+			expectedErr: "conflicting versions required for plugin 'p3': 'v3.1.0' and 'v3.0.0'",
+		},
+		{
+			name:        "missing dependency",
+			plugin:      newTestDiscoveredWithDeps("p1", "v1.0.0", dependsOn("p2", "v2.0.0")),
+			expectedErr: "unable to resolve dependency 'p2' of plugin 'p1/global:v1.0.0'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := newDependencyResolver(func(dep *plugininventory.PluginIdentifier) (*discovery.Discovered, error) {
+				for _, p := range tt.available {
+					if p.Name == dep.Name && p.RecommendedVersion == dep.Version {
+						found := *p
+						return &found, nil
+					}
+				}
+				return nil, errors.New("not found")
+			})
+
+			err := resolver.resolve(tt.plugin, "", nil)
+			if tt.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			var resolved []string
+			for _, rp := range resolver.order {
+				resolved = append(resolved, pluginIDString(rp.plugin.Name, rp.plugin.Target, rp.version))
+			}
+			assert.Equal(t, tt.expected, resolved)
+		})
+	}
+}
+
+func TestDedupeContextPluginDependencies(t *testing.T) {
+	assert := assert.New(t)
+
+	shared := &resolvedPlugin{plugin: newTestDiscoveredWithDeps("shared", "v1.0.0"), version: "v1.0.0"}
+	sharedCopy := &resolvedPlugin{plugin: newTestDiscoveredWithDeps("shared", "v1.0.0"), version: "v1.0.0"}
+	other := &resolvedPlugin{plugin: newTestDiscoveredWithDeps("other", "v2.0.0"), version: "v2.0.0"}
+	p1 := &resolvedPlugin{plugin: newTestDiscoveredWithDeps("p1", "v1.0.0"), version: "v1.0.0"}
+	p2 := &resolvedPlugin{plugin: newTestDiscoveredWithDeps("p2", "v1.0.0"), version: "v1.0.0"}
+	p3 := &resolvedPlugin{plugin: newTestDiscoveredWithDeps("p3", "v1.0.0"), version: "v1.0.0"}
+
+	// The plugin requiring the others is not a dependency, and a plugin
+	// required by several plugins is only installed once
+	dependencies := dedupeContextPluginDependencies([][]*resolvedPlugin{
+		{shared, p1},
+		{sharedCopy, other, p2},
+		{p3},
+		nil,
+	})
+	assert.Equal([]*resolvedPlugin{shared, other}, dependencies)
+
+	// The same plugin required in another context is installed in each context
+	otherContext := &resolvedPlugin{plugin: newTestDiscoveredWithDeps("shared", "v1.0.0"), version: "v1.0.0"}
+	otherContext.plugin.ContextName = "ctx"
+	dependencies = dedupeContextPluginDependencies([][]*resolvedPlugin{{shared, p1}, {otherContext, p2}})
+	assert.Equal([]*resolvedPlugin{shared, otherContext}, dependencies)
+}
+
+func TestInstallResolvedContextPluginWithFailedDependency(t *testing.T) {
+	assert := assert.New(t)
+
+	dep := &resolvedPlugin{plugin: newTestDiscoveredWithDeps("dep", "v1.0.0"), version: "v1.0.0"}
+	p := &resolvedPlugin{plugin: newTestDiscoveredWithDeps("p1", "v1.0.0"), version: "v1.0.0"}
+
+	// The plugin is not installed if a plugin it requires failed to install
+	dependencyErrors := map[string]error{resolvedContextPluginKey(dep): errors.New("download failed")}
+	err := installResolvedContextPlugin(p, []*resolvedPlugin{dep}, dependencyErrors)
+	assert.Error(err)
+	assert.Contains(err.Error(), "unable to install plugin 'dep/global:v1.0.0' required by plugin 'p1/global:v1.0.0': download failed")
+
+	// The plugin is not installed again if it was itself required by another plugin
+	dependencyErrors = map[string]error{resolvedContextPluginKey(p): nil}
+	assert.NoError(installResolvedContextPlugin(p, nil, dependencyErrors))
+}
@@ -0,0 +1,302 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper/sigverifier"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/interrupt"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
+)
+
+// The statuses of the results of the checks of the plugin doctor
+const (
+	// DoctorStatusPass means no problem was found
+	DoctorStatusPass = "pass"
+	// DoctorStatusWarn means a problem was found which does not prevent the CLI from working
+	DoctorStatusWarn = "warn"
+	// DoctorStatusFail means a problem was found which prevents the CLI from working
+	DoctorStatusFail = "fail"
+)
+
+// The names of the checks of the plugin doctor
+const (
+	DoctorCheckDataDirectories    = "data-directories"
+	DoctorCheckDiscoverySources   = "discovery-sources"
+	DoctorCheckInventoryCache     = "inventory-cache"
+	DoctorCheckPluginBinaries     = "plugin-binaries"
+	DoctorCheckPluginRegistration = "plugin-registration"
+)
+
+// DoctorResult is the outcome of a check of the plugin doctor for one of the items it checks
+type DoctorResult struct {
+	// Check is the name of the check
+	Check string `json:"check" yaml:"check"`
+	// Item is what was checked, e.g., a directory or a discovery source
+	Item string `json:"item" yaml:"item"`
+	// Status is one of the DoctorStatus constants
+	Status  string `json:"status" yaml:"status"`
+	Message string `json:"message" yaml:"message"`
+	// Remediation explains how to fix the problem that was found, if any
+	Remediation string `json:"remediation,omitempty" yaml:"remediation,omitempty"`
+}
+
+// DoctorCheck is one of the checks of the plugin doctor
+type DoctorCheck struct {
+	Name string
+	// Run runs the check and returns a result for each of the items it checks
+	Run func() []*DoctorResult
+}
+
+// GetDoctorChecks returns the checks of the plugin doctor, in the order they are run
+func GetDoctorChecks() []DoctorCheck {
+	return []DoctorCheck{
+		{Name: DoctorCheckDataDirectories, Run: checkDataDirectories},
+		{Name: DoctorCheckDiscoverySources, Run: checkDiscoverySources},
+		{Name: DoctorCheckInventoryCache, Run: checkInventoryCache},
+		{Name: DoctorCheckPluginBinaries, Run: checkPluginBinaries},
+		{Name: DoctorCheckPluginRegistration, Run: checkPluginRegistration},
+	}
+}
+
+// RunDoctorChecks diagnoses the common problems of the plugin environment by running
+// all the checks of the plugin doctor, and returns their results
+func RunDoctorChecks() []*DoctorResult {
+	var results []*DoctorResult
+	for _, check := range GetDoctorChecks() {
+		if interrupt.Interrupted() {
+			break
+		}
+		results = append(results, check.Run()...)
+	}
+	return results
+}
+
+// checkDataDirectories checks that the CLI can write to the directories of the
+// installed plugins and of the cache.  A missing directory is fine as long as
+// it can be created.
+func checkDataDirectories() []*DoctorResult {
+	results := make([]*DoctorResult, 0, 2)
+	for _, dir := range []string{common.DefaultPluginRoot, common.DefaultCacheDir} {
+		result := &DoctorResult{Check: DoctorCheckDataDirectories, Item: dir, Status: DoctorStatusPass, Message: "the directory is writable"}
+
+		// A missing directory is created in its closest existing parent
+		existingDir := dir
+		for {
+			if _, err := os.Stat(existingDir); err == nil || filepath.Dir(existingDir) == existingDir {
+				break
+			}
+			existingDir = filepath.Dir(existingDir)
+		}
+		if err := checkDirWritable(existingDir); err != nil {
+			result.Status = DoctorStatusFail
+			result.Message = fmt.Sprintf("the directory is not writable: %v", err)
+			result.Remediation = fmt.Sprintf("make %q a writable directory", existingDir)
+		} else if existingDir != dir {
+			result.Message = "the directory does not exist yet and can be created"
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// checkDiscoverySources checks that the plugins of each discovery source can be
+// listed, which includes the verification of the signature of its image, and
+// warns about the images whose signature verification is skipped
+func checkDiscoverySources() []*DoctorResult {
+	discoveries, err := getPluginDiscoveries()
+	if err != nil {
+		return []*DoctorResult{{Check: DoctorCheckDiscoverySources, Status: DoctorStatusFail, Message: err.Error()}}
+	}
+	if len(discoveries) == 0 {
+		return []*DoctorResult{{
+			Check:       DoctorCheckDiscoverySources,
+			Status:      DoctorStatusWarn,
+			Message:     "no discovery source is configured, no plugin can be installed",
+			Remediation: "run 'tanzu plugin source init'",
+		}}
+	}
+
+	resultsPerSource := make([]discoveryResult, len(discoveries))
+	for result := range listPluginsFromDiscoveries(interrupt.Context(), discoveries, false) {
+		resultsPerSource[result.index] = result
+	}
+
+	results := make([]*DoctorResult, 0, len(discoveries))
+	for i := range discoveries {
+		result := &DoctorResult{Check: DoctorCheckDiscoverySources, Item: discovery.GetDiscoveryName(discoveries[i])}
+		switch {
+		case resultsPerSource[i].err != nil:
+			result.Status = DoctorStatusFail
+			result.Message = resultsPerSource[i].err.Error()
+			result.Remediation = fmt.Sprintf("check the access to the registry of the discovery source, or update it with 'tanzu plugin source update %s'", result.Item)
+		case discoveries[i].OCI != nil && sigverifier.IsSignatureVerificationSkipped(discoveries[i].OCI.Image):
+			result.Status = DoctorStatusWarn
+			result.Message = fmt.Sprintf("%d plugins found, but the signature of the image %q is not verified", len(resultsPerSource[i].plugins), discoveries[i].OCI.Image)
+			result.Remediation = fmt.Sprintf("remove the image from the %s variable", constants.PluginDiscoveryImageSignatureVerificationSkipList)
+		default:
+			result.Status = DoctorStatusPass
+			result.Message = fmt.Sprintf("%d plugins found", len(resultsPerSource[i].plugins))
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// checkInventoryCache checks that the cached plugin inventories can be used.
+// An unusable inventory is fetched again by its discovery source, which is
+// only worth a warning.
+func checkInventoryCache() []*DoctorResult {
+	infos, err := discovery.ListCachedInventories()
+	if err != nil {
+		return []*DoctorResult{{
+			Check:       DoctorCheckInventoryCache,
+			Status:      DoctorStatusFail,
+			Message:     err.Error(),
+			Remediation: fmt.Sprintf("make %q a readable directory", filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName)),
+		}}
+	}
+
+	var results []*DoctorResult
+	for i := range infos {
+		if err := discovery.CheckCachedInventory(&infos[i]); err != nil {
+			results = append(results, &DoctorResult{
+				Check:       DoctorCheckInventoryCache,
+				Item:        infos[i].Name,
+				Status:      DoctorStatusWarn,
+				Message:     err.Error(),
+				Remediation: "the inventory is fetched again the next time it is used, run 'tanzu plugin prefetch' to fetch it now",
+			})
+		}
+	}
+	if len(results) == 0 {
+		results = append(results, &DoctorResult{
+			Check:   DoctorCheckInventoryCache,
+			Status:  DoctorStatusPass,
+			Message: fmt.Sprintf("%d cached plugin inventories can be used", len(infos)),
+		})
+	}
+	return results
+}
+
+// checkPluginBinaries checks that the binary of each installed plugin matches the
+// digest found in the plugin inventory, as 'plugin audit' does
+func checkPluginBinaries() []*DoctorResult {
+	auditResults, err := AuditInstalledPlugins()
+	if auditResults == nil && err != nil {
+		return []*DoctorResult{{Check: DoctorCheckPluginBinaries, Status: DoctorStatusFail, Message: err.Error()}}
+	}
+
+	var results []*DoctorResult
+	for _, r := range auditResults {
+		if !r.Failed() {
+			continue
+		}
+		result := &DoctorResult{
+			Check:       DoctorCheckPluginBinaries,
+			Item:        fmt.Sprintf("%s (%s)", r.Name, r.Target),
+			Status:      DoctorStatusFail,
+			Message:     "the binary of the plugin is missing",
+			Remediation: fmt.Sprintf("run 'tanzu plugin reinstall %s --target %s'", r.Name, r.Target),
+		}
+		if r.Status == PluginAuditStatusMismatch {
+			result.Message = fmt.Sprintf("the binary of the plugin has the digest %s instead of %s", r.ActualDigest, r.ExpectedDigest)
+		}
+		results = append(results, result)
+	}
+	if err != nil {
+		results = append(results, &DoctorResult{
+			Check:   DoctorCheckPluginBinaries,
+			Status:  DoctorStatusWarn,
+			Message: fmt.Sprintf("the plugins of the discovery sources which could not be read cannot be verified: %v", err),
+		})
+	}
+	if len(results) == 0 {
+		results = append(results, &DoctorResult{
+			Check:   DoctorCheckPluginBinaries,
+			Status:  DoctorStatusPass,
+			Message: fmt.Sprintf("the binaries of the %d installed plugins are intact", len(auditResults)),
+		})
+	}
+	return results
+}
+
+// checkPluginRegistration checks that the CLI found in the PATH is the CLI running,
+// so that the plugins it reports are those the user runs, and that the binary
+// of each installed plugin can be executed
+func checkPluginRegistration() []*DoctorResult {
+	results := []*DoctorResult{checkCLIInPath()}
+
+	installedPlugins, err := pluginsupplier.GetInstalledPlugins()
+	if err != nil {
+		return append(results, &DoctorResult{Check: DoctorCheckPluginRegistration, Status: DoctorStatusFail, Message: err.Error()})
+	}
+	if cli.BuildArch().IsWindows() {
+		// Windows does not record whether a file can be executed
+		return results
+	}
+	for i := range installedPlugins {
+		stat, err := os.Stat(installedPlugins[i].InstallationPath)
+		// A missing binary is reported by the check of the plugin binaries
+		if err != nil || stat.Mode().Perm()&0111 != 0 {
+			continue
+		}
+		results = append(results, &DoctorResult{
+			Check:       DoctorCheckPluginRegistration,
+			Item:        fmt.Sprintf("%s (%s)", installedPlugins[i].Name, installedPlugins[i].Target),
+			Status:      DoctorStatusFail,
+			Message:     fmt.Sprintf("the binary %q of the plugin is not executable", installedPlugins[i].InstallationPath),
+			Remediation: fmt.Sprintf("run 'tanzu plugin reinstall %s --target %s'", installedPlugins[i].Name, installedPlugins[i].Target),
+		})
+	}
+	return results
+}
+
+// checkCLIInPath checks that the executable of the running CLI is the one found in the PATH
+func checkCLIInPath() *DoctorResult {
+	result := &DoctorResult{Check: DoctorCheckPluginRegistration, Status: DoctorStatusPass}
+	executable, err := os.Executable()
+	if err != nil {
+		result.Status = DoctorStatusWarn
+		result.Message = fmt.Sprintf("unable to find the executable of the CLI: %v", err)
+		return result
+	}
+	result.Item = executable
+	executable = evalSymlinks(executable)
+
+	pathExecutable, err := exec.LookPath(filepath.Base(executable))
+	switch {
+	case err != nil:
+		result.Status = DoctorStatusWarn
+		result.Message = "the CLI is not found in the PATH"
+		result.Remediation = fmt.Sprintf("add %q to the PATH", filepath.Dir(executable))
+	case evalSymlinks(pathExecutable) != executable:
+		result.Status = DoctorStatusWarn
+		result.Message = fmt.Sprintf("the CLI found first in the PATH is %q, which has different plugins installed", pathExecutable)
+		result.Remediation = fmt.Sprintf("put %q before %q in the PATH", filepath.Dir(executable), filepath.Dir(pathExecutable))
+	default:
+		result.Message = "the CLI is the one found in the PATH"
+	}
+	return result
+}
+
+// evalSymlinks returns the absolute path of a file with its symbolic links evaluated,
+// or the path itself if this is not possible
+func evalSymlinks(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return path
+}
@@ -0,0 +1,143 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+func TestCheckDataDirectories(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	// The plugin root does not exist yet while the cache does
+	results := checkDataDirectories()
+	assertions.Equal(2, len(results))
+	assertions.Equal(common.DefaultPluginRoot, results[0].Item)
+	assertions.Equal(DoctorStatusPass, results[0].Status)
+	assertions.Contains(results[0].Message, "can be created")
+	assertions.Equal(common.DefaultCacheDir, results[1].Item)
+	assertions.Equal(DoctorStatusPass, results[1].Status)
+	assertions.Equal("the directory is writable", results[1].Message)
+
+	// A directory cannot be created under a file
+	file := filepath.Join(t.TempDir(), "file")
+	assertions.Nil(os.WriteFile(file, []byte("file"), 0644))
+	common.DefaultPluginRoot = filepath.Join(file, "plugins")
+	results = checkDataDirectories()
+	assertions.Equal(DoctorStatusFail, results[0].Status)
+	assertions.Contains(results[0].Remediation, file)
+}
+
+func TestCheckDiscoverySources(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	results := checkDiscoverySources()
+	assertions.NotEmpty(results)
+	assertions.Equal("default", results[0].Item)
+	assertions.Equal(DoctorStatusPass, results[0].Status)
+	assertions.Contains(results[0].Message, "plugins found")
+
+	// Skipping the signature verification of the image is reported
+	os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, "example.com/plugin-inventory:latest")
+	defer os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+	results = checkDiscoverySources()
+	assertions.Equal(DoctorStatusWarn, results[0].Status)
+	assertions.Contains(results[0].Remediation, constants.PluginDiscoveryImageSignatureVerificationSkipList)
+}
+
+func TestCheckInventoryCache(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	// The test inventory has no digest file
+	results := checkInventoryCache()
+	assertions.Equal(1, len(results))
+	assertions.Equal(DoctorStatusWarn, results[0].Status)
+	assertions.Contains(results[0].Message, "no digest file")
+
+	// An empty cache is fine
+	assertions.Nil(os.RemoveAll(filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName)))
+	results = checkInventoryCache()
+	assertions.Equal(1, len(results))
+	assertions.Equal(DoctorStatusPass, results[0].Status)
+}
+
+func TestCheckPluginBinaries(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	results := checkPluginBinaries()
+	assertions.Equal(1, len(results))
+	assertions.Equal(DoctorStatusPass, results[0].Status)
+
+	// The test plugin inventory does not contain the real digests of the test binaries
+	err := InstallStandalonePlugin("login", "v0.2.0", configtypes.TargetGlobal)
+	assertions.Nil(err)
+	results = checkPluginBinaries()
+	assertions.Equal(1, len(results))
+	assertions.Equal("login (global)", results[0].Item)
+	assertions.Equal(DoctorStatusFail, results[0].Status)
+	assertions.Equal("run 'tanzu plugin reinstall login --target global'", results[0].Remediation)
+}
+
+func TestCheckPluginRegistration(t *testing.T) {
+	assertions := assert.New(t)
+
+	if cli.BuildArch().IsWindows() {
+		t.Skip("the test relies on the permissions of files")
+	}
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+
+	// The test binary is not in the PATH
+	os.Setenv("PATH", t.TempDir())
+	results := checkPluginRegistration()
+	assertions.Equal(1, len(results))
+	assertions.Equal(DoctorStatusWarn, results[0].Status)
+	assertions.Equal("the CLI is not found in the PATH", results[0].Message)
+
+	executable, err := os.Executable()
+	assertions.Nil(err)
+	os.Setenv("PATH", filepath.Dir(executable))
+	results = checkPluginRegistration()
+	assertions.Equal(DoctorStatusPass, results[0].Status)
+
+	// A plugin binary which cannot be executed is reported
+	err = InstallStandalonePlugin("login", "v0.2.0", configtypes.TargetGlobal)
+	assertions.Nil(err)
+	installedPlugins, err := pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	installed := findPluginInfo(installedPlugins, "login", configtypes.TargetGlobal)
+	assertions.NotNil(installed)
+	assertions.Nil(os.Chmod(installed.InstallationPath, 0644))
+
+	results = checkPluginRegistration()
+	assertions.Equal(2, len(results))
+	assertions.Equal("login (global)", results[1].Item)
+	assertions.Equal(DoctorStatusFail, results[1].Status)
+}
@@ -6,12 +6,34 @@ package pluginmanager
 import (
 	"fmt"
 	"os"
+	"strconv"
 
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/essentials"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
 
+// EnsureEssentialPlugins installs the essential plugins which are missing and upgrades
+// the outdated ones.  The essential plugins are the mandatory plugins of the recommended
+// version of the essentials plugin group, "vmware-tanzucli/essentials" by default, as
+// published in the plugin inventory of the discovery sources.  The name and version of the
+// group can be changed through the TANZU_CLI_ESSENTIALS_PLUGIN_GROUP_NAME and
+// TANZU_CLI_ESSENTIALS_PLUGIN_GROUP_VERSION variables.
+//
+// Checking the essential plugins only uses the cached plugin inventories, so it is fast
+// when they are all installed and calling it again does nothing.  The plugins are installed
+// like any other plugin, which verifies the signature of the discovery images as configured.
+// Nothing is done when the TANZU_CLI_SKIP_ESSENTIAL_PLUGINS_INSTALLATION variable is "true".
+func EnsureEssentialPlugins() error {
+	if skip, _ := strconv.ParseBool(os.Getenv(constants.SkipEssentialPluginsInstallation)); skip {
+		log.V(4).Infof("Skipping the installation of the essential plugins as %s is set", constants.SkipEssentialPluginsInstallation)
+		return nil
+	}
+	_, err := InstallPluginsFromEssentialPluginGroup()
+	return err
+}
+
 // InstallPluginsFromEssentialPluginGroup is a function that installs or upgrades the essential plugin groups.
 func InstallPluginsFromEssentialPluginGroup() (string, error) {
 	// Retrieve the name and version of the essential plugin group.
@@ -36,7 +58,7 @@ func InstallPluginsFromEssentialPluginGroup() (string, error) {
 		actionMessage = constants.UpgradeEssentialPluginGroupsMsg
 	}
 
-	log.Info(actionMessage)
+	clilog.Info(actionMessage)
 
 	// Attempt to install or upgrade the essential plugin group.
 	_, err = installPluginsFromEssentialPluginGroup(name, version)
@@ -4,9 +4,13 @@
 package pluginmanager
 
 import (
+	"os"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
 )
 
@@ -319,3 +323,22 @@ func TestIsNewPluginVersionAvailable(t *testing.T) {
 		})
 	}
 }
+
+// TestEnsureEssentialPlugins tests the EnsureEssentialPlugins function.
+func TestEnsureEssentialPlugins(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	// An essentials plugin group which cannot be found in the cache fails the check
+	os.Setenv(constants.TanzuCLIEssentialsPluginGroupName, "vmware-invalid/essentials")
+	defer os.Unsetenv(constants.TanzuCLIEssentialsPluginGroupName)
+	err := EnsureEssentialPlugins()
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "failed to check if plugins from group are installed")
+
+	// Nothing is checked when the installation of the essential plugins is disabled
+	os.Setenv(constants.SkipEssentialPluginsInstallation, "true")
+	defer os.Unsetenv(constants.SkipEssentialPluginsInstallation)
+	assertions.Nil(EnsureEssentialPlugins())
+}
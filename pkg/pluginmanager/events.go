@@ -0,0 +1,99 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"sync"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+// EventType is the type of a plugin lifecycle event
+type EventType string
+
+// The types of the plugin lifecycle events
+const (
+	// EventPluginInstalled is published once a plugin is installed
+	EventPluginInstalled EventType = "PluginInstalled"
+	// EventPluginUpgraded is published once an installed plugin is replaced by
+	// another version; the event gives both the new and the previous version
+	EventPluginUpgraded EventType = "PluginUpgraded"
+	// EventPluginDeleted is published once a plugin is uninstalled
+	EventPluginDeleted EventType = "PluginDeleted"
+	// EventSyncStarted is published when a plugin sync starts installing the plugins
+	// of the contexts; the discovery source is set if the sync is restricted to it
+	EventSyncStarted EventType = "SyncStarted"
+	// EventSyncCompleted is published when a plugin sync is done, with its error if it failed
+	EventSyncCompleted EventType = "SyncCompleted"
+)
+
+// Event describes a step of the lifecycle of the plugins.  The fields that do
+// not apply to the type of the event are left empty.
+type Event struct {
+	Type    EventType
+	Name    string
+	Target  configtypes.Target
+	Version string
+	// PreviousVersion is the version replaced by an EventPluginUpgraded
+	PreviousVersion string
+	// ContextName is the context of a context-scope plugin
+	ContextName string
+	// Path is the path of the binary of the plugin
+	Path string
+	// DiscoverySource is the discovery source a sync is restricted to
+	DiscoverySource string
+	// Err is the error of a failed sync
+	Err error
+}
+
+// eventObserver is a function subscribed to the plugin lifecycle events
+type eventObserver struct {
+	id       int
+	observer func(Event)
+}
+
+// eventObservers are the functions subscribed to the plugin lifecycle events, in subscription order
+var eventObservers = struct {
+	sync.RWMutex
+	observers []eventObserver
+	nextID    int
+}{}
+
+// Subscribe registers a function to call with each plugin lifecycle event, such as the
+// installation of a plugin.  The function is called synchronously, by the goroutine doing
+// the operation, and must therefore return quickly.  The returned function unsubscribes it.
+func Subscribe(observer func(Event)) (unsubscribe func()) {
+	eventObservers.Lock()
+	defer eventObservers.Unlock()
+
+	id := eventObservers.nextID
+	eventObservers.nextID++
+	eventObservers.observers = append(eventObservers.observers, eventObserver{id: id, observer: observer})
+	return func() {
+		eventObservers.Lock()
+		defer eventObservers.Unlock()
+		for i := range eventObservers.observers {
+			if eventObservers.observers[i].id == id {
+				eventObservers.observers = append(eventObservers.observers[:i:i], eventObservers.observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// publishEvent calls the subscribed functions with the event.  Nothing
+// is done, and the event not even built, when there is no subscriber.
+func publishEvent(buildEvent func() Event) {
+	eventObservers.RLock()
+	observers := eventObservers.observers
+	eventObservers.RUnlock()
+	if len(observers) == 0 {
+		return
+	}
+
+	event := buildEvent()
+	for _, o := range observers {
+		o.observer(event)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+func TestSubscribe(t *testing.T) {
+	assertions := assert.New(t)
+
+	// Without subscriber, the event is not even built
+	publishEvent(func() Event {
+		assertions.Fail("the event must not be built without subscriber")
+		return Event{}
+	})
+
+	var first, second []Event
+	unsubscribeFirst := Subscribe(func(e Event) { first = append(first, e) })
+	unsubscribeSecond := Subscribe(func(e Event) { second = append(second, e) })
+
+	publishEvent(func() Event { return Event{Type: EventSyncStarted} })
+	assertions.Equal([]Event{{Type: EventSyncStarted}}, first)
+	assertions.Equal([]Event{{Type: EventSyncStarted}}, second)
+
+	// An unsubscribed function is no longer called
+	unsubscribeFirst()
+	publishEvent(func() Event { return Event{Type: EventSyncCompleted} })
+	assertions.Len(first, 1)
+	assertions.Equal([]Event{{Type: EventSyncStarted}, {Type: EventSyncCompleted}}, second)
+
+	// Unsubscribing twice is harmless
+	unsubscribeFirst()
+	unsubscribeSecond()
+	publishEvent(func() Event {
+		assertions.Fail("the event must not be built once all subscribers are gone")
+		return Event{}
+	})
+}
+
+func TestPluginLifecycleEvents(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	var events []Event
+	defer Subscribe(func(e Event) { events = append(events, e) })()
+
+	err := InstallStandalonePlugin("login", "v0.2.0", configtypes.TargetUnknown)
+	assertions.Nil(err)
+	assertions.Len(events, 1)
+	assertions.Equal(EventPluginInstalled, events[0].Type)
+	assertions.Equal("login", events[0].Name)
+	assertions.Equal(configtypes.TargetGlobal, events[0].Target)
+	assertions.Equal("v0.2.0", events[0].Version)
+	assertions.Empty(events[0].PreviousVersion)
+	assertions.NotEmpty(events[0].Path)
+
+	// Installing another version upgrades the plugin
+	err = InstallStandalonePlugin("login", "v0.20.0", configtypes.TargetUnknown)
+	assertions.Nil(err)
+	assertions.Len(events, 2)
+	assertions.Equal(EventPluginUpgraded, events[1].Type)
+	assertions.Equal("login", events[1].Name)
+	assertions.Equal("v0.20.0", events[1].Version)
+	assertions.Equal("v0.2.0", events[1].PreviousVersion)
+
+	err = DeleteStandalonePlugins([]cli.PluginInfo{{Name: "login", Target: configtypes.TargetGlobal, Version: "v0.20.0"}})
+	assertions.Nil(err)
+	assertions.Len(events, 3)
+	assertions.Equal(Event{Type: EventPluginDeleted, Name: "login", Target: configtypes.TargetGlobal, Version: "v0.20.0"}, events[2])
+}
@@ -0,0 +1,178 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+// PluginOffer describes a plugin as provided by a single discovery source
+type PluginOffer struct {
+	Source string `json:"source" yaml:"source"`
+	// ContextName is the context whose discovery source provides the plugin, if any
+	ContextName        string   `json:"context,omitempty" yaml:"context,omitempty"`
+	RecommendedVersion string   `json:"recommendedVersion" yaml:"recommendedVersion"`
+	Versions           []string `json:"versions" yaml:"versions"`
+}
+
+// PluginStatusExplanation describes how the status of a plugin, as shown
+// by "tanzu plugin list", was determined for one of its targets
+type PluginStatusExplanation struct {
+	Name   string             `json:"name" yaml:"name"`
+	Target configtypes.Target `json:"target" yaml:"target"`
+	Scope  string             `json:"scope" yaml:"scope"`
+	// ContextName is the context recommending the version of a context-scope plugin
+	ContextName string `json:"context,omitempty" yaml:"context,omitempty"`
+	// Offers are the discovery sources providing the plugin, in the order they are searched
+	Offers []PluginOffer `json:"offers" yaml:"offers"`
+	// RecommendedVersion is the version the plugin is compared with, which may be a pinned version
+	RecommendedVersion string `json:"recommendedVersion,omitempty" yaml:"recommendedVersion,omitempty"`
+	// RecommendedSource is the first discovery source providing the recommended version
+	RecommendedSource string `json:"recommendedSource,omitempty" yaml:"recommendedSource,omitempty"`
+	InstalledVersion  string `json:"installedVersion,omitempty" yaml:"installedVersion,omitempty"`
+	// InstalledFrom is the discovery source the installed version was installed from
+	InstalledFrom string `json:"installedFrom,omitempty" yaml:"installedFrom,omitempty"`
+	// InstalledRecommendedVersion is the recommended version at the time the plugin was installed
+	InstalledRecommendedVersion string `json:"installedRecommendedVersion,omitempty" yaml:"installedRecommendedVersion,omitempty"`
+	Status                      string `json:"status" yaml:"status"`
+	// Reason is the comparison of the versions that resulted in the status
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// ExplainPluginStatus explains the status of the specified plugin for each of its targets,
+// by detailing which discovery sources offer the plugin, which version is recommended and
+// which version is installed.  The plugins of the active contexts take precedence over the
+// standalone plugins, as they do when listing the plugins.  The explanations are sorted by target.
+func ExplainPluginStatus(pluginName string) ([]*PluginStatusExplanation, error) {
+	installedPlugins, err := pluginsupplier.GetInstalledPlugins()
+	if err != nil {
+		return nil, err
+	}
+
+	criteria := &discovery.PluginDiscoveryCriteria{Name: pluginName}
+	errorList := make([]error, 0)
+
+	// The offers of each discovery source, before they are merged
+	var offered []discovery.Discovered
+	discoveries, err := getPluginDiscoveries()
+	if err != nil {
+		return nil, err
+	}
+	standalonePlugins, err := discoverSpecificPlugins(discoveries, discovery.WithPluginDiscoveryCriteria(criteria))
+	if err != nil {
+		errorList = append(errorList, err)
+	}
+	for i := range standalonePlugins {
+		standalonePlugins[i].Scope = common.PluginScopeStandalone
+	}
+	offered = append(offered, standalonePlugins...)
+
+	serverPlugins, err := DiscoverServerPlugins()
+	if err != nil {
+		errorList = append(errorList, err)
+	}
+	for i := range serverPlugins {
+		if serverPlugins[i].Name == pluginName {
+			offered = append(offered, serverPlugins[i])
+		}
+	}
+
+	explanations := make(map[configtypes.Target]*PluginStatusExplanation)
+	getExplanation := func(target configtypes.Target) *PluginStatusExplanation {
+		if explanations[target] == nil {
+			explanations[target] = &PluginStatusExplanation{Name: pluginName, Target: target, Scope: common.PluginScopeStandalone}
+		}
+		return explanations[target]
+	}
+	for i := range offered {
+		if offered[i].Name != pluginName {
+			continue
+		}
+		e := getExplanation(offered[i].Target)
+		e.Offers = append(e.Offers, PluginOffer{
+			Source:             offered[i].Source,
+			ContextName:        offered[i].ContextName,
+			RecommendedVersion: offered[i].RecommendedVersion,
+			Versions:           append([]string(nil), offered[i].SupportedVersions...),
+		})
+	}
+
+	// The plugins of the contexts override the standalone plugins
+	recommended := mergeDuplicatePlugins(append([]discovery.Discovered(nil), standalonePlugins...))
+	for i := range serverPlugins {
+		if serverPlugins[i].Name == pluginName {
+			recommended = append(recommended, serverPlugins[i])
+		}
+	}
+	discoveredByTarget := make(map[configtypes.Target]*discovery.Discovered)
+	for i := range recommended {
+		if recommended[i].Name != pluginName {
+			continue
+		}
+		if existing := discoveredByTarget[recommended[i].Target]; existing == nil || recommended[i].ContextName != "" {
+			discoveredByTarget[recommended[i].Target] = &recommended[i]
+		}
+	}
+
+	installedByTarget := make(map[configtypes.Target]*cli.PluginInfo)
+	for i := range installedPlugins {
+		if installedPlugins[i].Name == pluginName {
+			installedByTarget[installedPlugins[i].Target] = &installedPlugins[i]
+			getExplanation(installedPlugins[i].Target)
+		}
+	}
+
+	if len(explanations) == 0 {
+		errorList = append(errorList, errors.Errorf("unable to find plugin '%v' in the discovery sources or among the installed plugins", pluginName))
+		return nil, kerrors.NewAggregate(errorList)
+	}
+
+	result := make([]*PluginStatusExplanation, 0, len(explanations))
+	for target, e := range explanations {
+		installed := installedByTarget[target]
+		if installed != nil {
+			e.InstalledVersion = installed.Version
+			e.InstalledFrom = installed.Discovery
+			e.InstalledRecommendedVersion = installed.DiscoveredRecommendedVersion
+		}
+
+		p := discoveredByTarget[target]
+		if p == nil {
+			e.Status = common.PluginStatusUnavailable
+			e.Reason = "the installed plugin is not provided by any discovery source"
+			result = append(result, e)
+			continue
+		}
+		e.Scope = p.Scope
+		e.ContextName = p.ContextName
+		e.RecommendedVersion = p.RecommendedVersion
+		for _, o := range e.Offers {
+			if utils.ContainsString(o.Versions, p.RecommendedVersion) {
+				e.RecommendedSource = o.Source
+				break
+			}
+		}
+		e.Status, e.Reason = explainPluginStatus(p, installed)
+		if p.ContextName != "" {
+			e.Reason = fmt.Sprintf("%s; the version is recommended by context '%s'", e.Reason, p.ContextName)
+		}
+		result = append(result, e)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Target < result[j].Target
+	})
+	return result, kerrors.NewAggregate(errorList)
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+func TestExplainPluginStatusReason(t *testing.T) {
+	assertions := assert.New(t)
+
+	p := &discovery.Discovered{Name: "fake1", RecommendedVersion: "v3.0.0", SupportedVersions: []string{"v1.0.0", "v3.0.0"}}
+
+	status, reason := explainPluginStatus(p, nil)
+	assertions.Equal(common.PluginStatusNotInstalled, status)
+	assertions.Equal("no version of the plugin is installed", reason)
+
+	status, reason = explainPluginStatus(p, &cli.PluginInfo{Name: "fake1", Version: "v3.0.0"})
+	assertions.Equal(common.PluginStatusInstalled, status)
+	assertions.Contains(reason, "the installed version v3.0.0 is the recommended version")
+
+	status, reason = explainPluginStatus(p, &cli.PluginInfo{Name: "fake1", Version: "v1.0.0", DiscoveredRecommendedVersion: "v3.0.0"})
+	assertions.Equal(common.PluginStatusInstalled, status)
+	assertions.Contains(reason, "has not changed since version v1.0.0 was installed")
+
+	status, reason = explainPluginStatus(p, &cli.PluginInfo{Name: "fake1", Version: "v1.0.0", DiscoveredRecommendedVersion: "v1.0.0"})
+	assertions.Equal(common.PluginStatusUpdateAvailable, status)
+	assertions.Contains(reason, "the recommended version changed from v1.0.0 to v3.0.0")
+
+	status, reason = explainPluginStatus(p, &cli.PluginInfo{Name: "fake1", Version: "v2.0.0"})
+	assertions.Equal(common.PluginStatusOutdated, status)
+	assertions.Contains(reason, "the installed version v2.0.0 is no longer provided")
+}
+
+func TestExplainPluginStatus(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	explanations, err := ExplainPluginStatus("login")
+	assertions.Nil(err)
+	assertions.NotEmpty(explanations)
+	for _, e := range explanations {
+		assertions.Equal("login", e.Name)
+		assertions.NotEmpty(e.Offers)
+		assertions.NotEmpty(e.RecommendedSource)
+		assertions.Equal(common.PluginStatusNotInstalled, e.Status)
+	}
+
+	err = InstallStandalonePlugin("login", "v0.2.0", configtypes.TargetUnknown)
+	assertions.Nil(err)
+
+	explanations, err = ExplainPluginStatus("login")
+	assertions.Nil(err)
+	var found bool
+	for _, e := range explanations {
+		if e.InstalledVersion != "" {
+			found = true
+			assertions.Equal("v0.2.0", e.InstalledVersion)
+			assertions.NotEqual(common.PluginStatusNotInstalled, e.Status)
+			assertions.NotEmpty(e.Reason)
+		}
+	}
+	assertions.True(found)
+
+	_, err = ExplainPluginStatus("not-exists")
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to find plugin 'not-exists'")
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+)
+
+// normalizeDigest returns the hex value of a sha256 digest specified
+// with or without its "sha256:" prefix
+func normalizeDigest(digest string) (string, error) {
+	hexVal := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(digest), "sha256:"))
+	if b, err := hex.DecodeString(hexVal); err != nil || len(b) != sha256.Size {
+		return "", errors.Errorf("invalid digest %q, a sha256 digest such as 'sha256:<64 hexadecimal characters>' is expected", digest)
+	}
+	return hexVal, nil
+}
+
+// findPluginVersionByDigest returns the version of the plugin whose artifact
+// for the platform of the CLI has the specified digest, in hex
+func findPluginVersionByDigest(p *discovery.Discovered, digest string) (string, error) {
+	for _, version := range p.SupportedVersions {
+		if d, err := p.Distribution.GetDigest(version, cli.GOOS, cli.GOARCH); err == nil && strings.EqualFold(d, digest) {
+			return version, nil
+		}
+	}
+	return "", errors.Errorf("no artifact of plugin '%s' for %s/%s has the digest sha256:%s", p.Name, cli.GOOS, cli.GOARCH, digest)
+}
+
+// verifyInstalledPluginDigest checks that the installed plugin binary has the specified digest, in hex
+func verifyInstalledPluginDigest(pluginPath, digest string) error {
+	b, err := os.ReadFile(pluginPath)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read the installed plugin binary %q", pluginPath)
+	}
+	if actual := fmt.Sprintf("%x", sha256.Sum256(b)); !strings.EqualFold(actual, digest) {
+		return errors.Errorf("the installed plugin binary %q has the digest sha256:%s instead of the requested sha256:%s", pluginPath, actual, digest)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+func TestNormalizeDigest(t *testing.T) {
+	assertions := assert.New(t)
+
+	hexVal := fmt.Sprintf("%x", sha256.Sum256([]byte("binary")))
+
+	digest, err := normalizeDigest("sha256:" + hexVal)
+	assertions.Nil(err)
+	assertions.Equal(hexVal, digest)
+
+	digest, err = normalizeDigest(hexVal)
+	assertions.Nil(err)
+	assertions.Equal(hexVal, digest)
+
+	_, err = normalizeDigest("sha256:1234")
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "invalid digest \"sha256:1234\"")
+}
+
+func TestFindPluginVersionByDigest(t *testing.T) {
+	assertions := assert.New(t)
+
+	artifact := func(digest string) distribution.ArtifactList {
+		return distribution.ArtifactList{{Image: "localhost:9876/my/discovery/myplugin", Digest: digest, OS: cli.GOOS, Arch: cli.GOARCH}}
+	}
+	p := &discovery.Discovered{
+		Name:              "myplugin",
+		Target:            configtypes.TargetK8s,
+		SupportedVersions: []string{"v1.0.0", "v2.0.0"},
+		Distribution: distribution.Artifacts{
+			"v1.0.0": artifact("1111"),
+			"v2.0.0": artifact("2222"),
+		},
+	}
+
+	version, err := findPluginVersionByDigest(p, "1111")
+	assertions.Nil(err)
+	assertions.Equal("v1.0.0", version)
+
+	version, err = findPluginVersionByDigest(p, "2222")
+	assertions.Nil(err)
+	assertions.Equal("v2.0.0", version)
+
+	_, err = findPluginVersionByDigest(p, "3333")
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "no artifact of plugin 'myplugin'")
+	assertions.Contains(err.Error(), "has the digest sha256:3333")
+}
+
+func TestVerifyInstalledPluginDigest(t *testing.T) {
+	assertions := assert.New(t)
+
+	tmpDir, err := os.MkdirTemp("", "install-digest")
+	assertions.Nil(err)
+	defer os.RemoveAll(tmpDir)
+
+	pluginPath := filepath.Join(tmpDir, "myplugin")
+	assertions.Nil(os.WriteFile(pluginPath, []byte("binary"), 0755))
+
+	assertions.Nil(verifyInstalledPluginDigest(pluginPath, fmt.Sprintf("%x", sha256.Sum256([]byte("binary")))))
+
+	err = verifyInstalledPluginDigest(pluginPath, fmt.Sprintf("%x", sha256.Sum256([]byte("other"))))
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "instead of the requested")
+
+	err = verifyInstalledPluginDigest(filepath.Join(tmpDir, "missing"), "1234")
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to read the installed plugin binary")
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+// validateInstallDir returns the absolute path of the directory a plugin must be
+// installed to instead of the default plugin root.  The directory is created if
+// it does not exist and must be writable.
+func validateInstallDir(dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid installation directory %q", dir)
+	}
+	if !filepath.IsAbs(dir) {
+		log.Warningf("The relative installation directory %q is recorded as %q", dir, absDir)
+	}
+
+	if err := os.MkdirAll(absDir, 0755); err != nil {
+		return "", errors.Wrapf(err, "unable to create the installation directory %q", absDir)
+	}
+	if err := checkDirWritable(absDir); err != nil {
+		return "", errors.Wrapf(err, "the installation directory %q is not writable", absDir)
+	}
+	return absDir, nil
+}
+
+// checkDirWritable returns an error if a file cannot be created in the directory
+func checkDirWritable(dir string) error {
+	tmpFile, err := os.CreateTemp(dir, ".tanzu-write-check-*")
+	if err != nil {
+		return err
+	}
+	tmpFile.Close()
+	_ = os.Remove(tmpFile.Name())
+	return nil
+}
+
+// isCustomInstallation returns true if the plugin binary is not
+// installed under the default plugin root
+func isCustomInstallation(pluginPath string) bool {
+	if pluginPath == "" {
+		return false
+	}
+	rel, err := filepath.Rel(common.DefaultPluginRoot, pluginPath)
+	return err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// removeCustomPluginBinaries removes the binaries of the plugins installed outside
+// of the default plugin root, as they are not kept as a cache of the plugin binaries.
+// The directory of the plugin in the installation directory is removed if it is empty.
+func removeCustomPluginBinaries(plugins []cli.PluginInfo) {
+	for i := range plugins {
+		removeCustomPluginBinary(plugins[i].InstallationPath)
+	}
+}
+
+// removeCustomPluginBinary removes a plugin binary installed outside of the default plugin root
+func removeCustomPluginBinary(pluginPath string) {
+	if !isCustomInstallation(pluginPath) {
+		return
+	}
+	if err := os.Remove(pluginPath); err != nil && !os.IsNotExist(err) {
+		log.Warningf("Unable to remove the plugin binary %q: %v", pluginPath, err)
+		return
+	}
+	// Only succeeds if the directory is empty
+	_ = os.Remove(filepath.Dir(pluginPath))
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+func TestValidateInstallDir(t *testing.T) {
+	assertions := assert.New(t)
+
+	tmpDir, err := os.MkdirTemp("", "install-dir")
+	assertions.Nil(err)
+	defer os.RemoveAll(tmpDir)
+
+	// A missing directory is created
+	dir, err := validateInstallDir(filepath.Join(tmpDir, "plugins"))
+	assertions.Nil(err)
+	assertions.Equal(filepath.Join(tmpDir, "plugins"), dir)
+	assertions.DirExists(dir)
+
+	// No file is left behind by the write check
+	entries, err := os.ReadDir(dir)
+	assertions.Nil(err)
+	assertions.Empty(entries)
+
+	// A file cannot be used as installation directory
+	file := filepath.Join(tmpDir, "file")
+	assertions.Nil(os.WriteFile(file, nil, 0644))
+	_, err = validateInstallDir(filepath.Join(file, "plugins"))
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to create the installation directory")
+}
+
+func TestIsCustomInstallation(t *testing.T) {
+	assertions := assert.New(t)
+
+	assertions.False(isCustomInstallation(""))
+	assertions.False(isCustomInstallation(filepath.Join(common.DefaultPluginRoot, "login", "v0.2.0_1234_global")))
+	assertions.True(isCustomInstallation(filepath.Join(filepath.Dir(common.DefaultPluginRoot), "other", "login", "v0.2.0_1234_global")))
+	assertions.True(isCustomInstallation(common.DefaultPluginRoot + "-other"))
+}
+
+func TestInstallStandalonePluginWithInstallDir(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	installDir, err := os.MkdirTemp("", "install-dir")
+	assertions.Nil(err)
+	defer os.RemoveAll(installDir)
+
+	result, err := InstallStandalonePluginWithResult("login", "v0.2.0", configtypes.TargetUnknown, WithInstallDir(installDir))
+	assertions.Nil(err)
+	assertions.Equal(filepath.Join(installDir, "login"), filepath.Dir(result.Path))
+	assertions.FileExists(result.Path)
+
+	// The catalog reflects the installation directory
+	installedPlugins, err := pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	assertions.Equal(1, len(installedPlugins))
+	assertions.Equal(result.Path, installedPlugins[0].InstallationPath)
+
+	// Installing the plugin to the default plugin root removes the binary of the installation directory
+	result2, err := InstallStandalonePluginWithResult("login", "v0.2.0", configtypes.TargetUnknown)
+	assertions.Nil(err)
+	assertions.False(isCustomInstallation(result2.Path))
+	assertions.NoFileExists(result.Path)
+
+	// The binary is removed from the installation directory when the plugin is uninstalled
+	result, err = InstallStandalonePluginWithResult("login", "v0.2.0", configtypes.TargetUnknown, WithInstallDir(installDir))
+	assertions.Nil(err)
+	assertions.FileExists(result.Path)
+	assertions.FileExists(result2.Path)
+
+	err = DeletePlugin(DeletePluginOptions{PluginName: "login", Target: configtypes.TargetUnknown, ForceDelete: true})
+	assertions.Nil(err)
+	assertions.NoFileExists(result.Path)
+	assertions.NoDirExists(filepath.Join(installDir, "login"))
+	// The binaries of the plugin root remain as a cache
+	assertions.FileExists(result2.Path)
+}
@@ -0,0 +1,356 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rogpeppe/go-internal/lockedfile"
+	"gopkg.in/yaml.v3"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+)
+
+// pluginJournalFileName is the name of the file of the cache directory which
+// journals the installations and deletions of plugins in progress
+const pluginJournalFileName = "plugin_journal.yaml"
+
+// The operations on plugins which are journaled
+const (
+	journalOperationInstall = "install"
+	journalOperationDelete  = "delete"
+	// journalOperationReinstall is the uninstallation of a standalone plugin
+	// which is about to be installed again
+	journalOperationReinstall = "reinstall"
+)
+
+// The steps of a journaled installation
+const (
+	// journalStepStarted means the binary of the plugin may be partially written
+	journalStepStarted = "started"
+	// journalStepWritten means the binary of the plugin is written and described,
+	// but the plugin may not be registered in the plugin catalog yet
+	journalStepWritten = "written"
+)
+
+// journalEntry records an installation or a deletion of a plugin in progress so that,
+// if the CLI is interrupted before completing it, the next CLI run can complete or
+// roll back the operation
+type journalEntry struct {
+	Operation string `yaml:"operation"`
+	Step      string `yaml:"step"`
+	// PID is the process running the operation
+	PID int `yaml:"pid"`
+	// ContextName is the context whose catalog the plugin is registered in or deleted
+	// from, empty for the catalog of the standalone plugins.  The recovery of the
+	// operation only updates this catalog.
+	ContextName string         `yaml:"contextName,omitempty"`
+	Plugin      cli.PluginInfo `yaml:"plugin"`
+	Time        time.Time      `yaml:"time"`
+}
+
+// journaledOperation is the handle of an operation recorded in the journal.
+// A nil handle, returned when the journal cannot be written, does nothing.
+type journaledOperation struct {
+	id string
+}
+
+var (
+	// journalMutex serializes the updates of the journal by the plugins
+	// installed concurrently, e.g., by a plugin sync
+	journalMutex sync.Mutex
+	// journalCounter makes the identifiers of the operations of a process unique
+	journalCounter uint64
+	// recoverOnce runs the recovery of the interrupted operations once per process
+	recoverOnce sync.Once
+)
+
+func getPluginJournalFilePath() string {
+	return filepath.Join(common.DefaultCacheDir, pluginJournalFileName)
+}
+
+// startJournaledOperation records the intent to install or delete a plugin before
+// any change is made to the catalog of the specified context, empty for the catalog
+// of the standalone plugins.  Errors are only logged as journaling must not fail the
+// operation itself.
+func startJournaledOperation(operation, contextName string, plugin *cli.PluginInfo) *journaledOperation {
+	return startJournaledOperations(operation, []string{contextName}, []cli.PluginInfo{*plugin})[0]
+}
+
+// startJournaledOperations records the intent to install or delete each plugin in each of
+// the catalogs of the specified contexts with a single update of the journal.  The operations
+// are returned by catalog, then by plugin.  They are all nil if the journal cannot be written.
+func startJournaledOperations(operation string, contextNames []string, plugins []cli.PluginInfo) []*journaledOperation {
+	ops := make([]*journaledOperation, 0, len(contextNames)*len(plugins))
+	entries := make([]*journalEntry, 0, cap(ops))
+	for _, contextName := range contextNames {
+		for i := range plugins {
+			ops = append(ops, &journaledOperation{id: fmt.Sprintf("%d-%d", os.Getpid(), atomic.AddUint64(&journalCounter, 1))})
+			entries = append(entries, &journalEntry{
+				Operation:   operation,
+				Step:        journalStepStarted,
+				PID:         os.Getpid(),
+				ContextName: contextName,
+				Plugin:      plugins[i],
+				Time:        time.Now().UTC(),
+			})
+		}
+	}
+	if err := updatePluginJournal(func(journal map[string]*journalEntry) {
+		for i := range ops {
+			journal[ops[i].id] = entries[i]
+		}
+	}); err != nil {
+		for i := range plugins {
+			log.V(4).Warningf("Unable to journal the %s of plugin '%s': %v", operation, plugins[i].Name, err)
+		}
+		return make([]*journaledOperation, len(ops))
+	}
+	return ops
+}
+
+// advance records that the operation reached the specified step, along with the
+// description of the plugin known at that step
+func (op *journaledOperation) advance(step string, plugin *cli.PluginInfo) {
+	if op == nil {
+		return
+	}
+	if err := updatePluginJournal(func(entries map[string]*journalEntry) {
+		if entry, exists := entries[op.id]; exists {
+			entry.Step = step
+			entry.Plugin = *plugin
+		}
+	}); err != nil {
+		log.V(4).Warningf("Unable to journal the progress of the operation on plugin '%s': %v", plugin.Name, err)
+	}
+}
+
+// complete removes the operation from the journal, whether it succeeded or failed,
+// as a failed operation cleans up after itself
+func (op *journaledOperation) complete() {
+	completeJournaledOperations([]*journaledOperation{op})
+}
+
+// completeJournaledOperations removes the operations from the journal with a single update
+func completeJournaledOperations(ops []*journaledOperation) {
+	var ids []string
+	for _, op := range ops {
+		if op != nil {
+			ids = append(ids, op.id)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	if err := updatePluginJournal(func(entries map[string]*journalEntry) {
+		for _, id := range ids {
+			delete(entries, id)
+		}
+	}); err != nil {
+		log.V(4).Warningf("Unable to remove a completed operation from the plugin journal: %v", err)
+	}
+}
+
+// updatePluginJournal applies the update to the entries of the journal while
+// holding a write lock on the journal file
+func updatePluginJournal(update func(entries map[string]*journalEntry)) error {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+
+	if err := os.MkdirAll(common.DefaultCacheDir, 0755); err != nil {
+		return err
+	}
+	lockedFile, err := lockedfile.Edit(getPluginJournalFilePath())
+	if err != nil {
+		return err
+	}
+	defer lockedFile.Close()
+
+	b, err := io.ReadAll(lockedFile)
+	if err != nil {
+		return err
+	}
+	entries := make(map[string]*journalEntry)
+	if err := yaml.Unmarshal(b, &entries); err != nil {
+		// Start over rather than failing every future operation
+		entries = make(map[string]*journalEntry)
+	}
+	update(entries)
+
+	var out []byte
+	if len(entries) > 0 {
+		if out, err = yaml.Marshal(entries); err != nil {
+			return err
+		}
+	}
+	if err := lockedFile.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := lockedFile.Seek(0, 0); err != nil {
+		return err
+	}
+	_, err = lockedFile.Write(out)
+	return err
+}
+
+// recoverInterruptedPluginOperationsOnce recovers the interrupted operations the
+// first time the plugins are installed or deleted by this process
+func recoverInterruptedPluginOperationsOnce() {
+	recoverOnce.Do(func() {
+		if err := recoverInterruptedPluginOperations(); err != nil {
+			log.V(4).Warningf("Unable to recover the interrupted plugin operations: %v", err)
+		}
+	})
+}
+
+// recoverInterruptedPluginOperations completes or rolls back the operations journaled
+// by CLI processes which are no longer running, e.g., because they crashed:
+//   - the binary of an installation interrupted while it was written is removed,
+//   - the registration of an installation interrupted after its binary was written is completed,
+//   - a deletion is completed by unregistering the plugin and removing its binary,
+//   - a plugin whose reinstallation was interrupted before it was installed again is restored.
+func recoverInterruptedPluginOperations() error {
+	b, err := lockedfile.Read(getPluginJournalFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrap(err, "could not read the plugin journal")
+	}
+	entries := make(map[string]*journalEntry)
+	if err := yaml.Unmarshal(b, &entries); err != nil {
+		return errors.Wrap(err, "could not decode the plugin journal")
+	}
+
+	var ids []string
+	for id, entry := range entries {
+		if entry.PID != os.Getpid() && !isProcessRunning(entry.PID) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	// The operations are recovered in the order they were started
+	sort.Slice(ids, func(i, j int) bool {
+		return entries[ids[i]].Time.Before(entries[ids[j]].Time)
+	})
+
+	var recovered []string
+	for _, id := range ids {
+		if err := recoverInterruptedPluginOperation(entries[id]); err != nil {
+			log.Warningf("Unable to recover the interrupted %s of plugin '%s': %v", entries[id].Operation, entries[id].Plugin.Name, err)
+			continue
+		}
+		recovered = append(recovered, id)
+	}
+
+	return updatePluginJournal(func(entries map[string]*journalEntry) {
+		for _, id := range recovered {
+			delete(entries, id)
+		}
+	})
+}
+
+// recoverInterruptedPluginOperation completes or rolls back an interrupted operation
+func recoverInterruptedPluginOperation(entry *journalEntry) error {
+	plugin := &entry.Plugin
+	switch {
+	case entry.Operation == journalOperationReinstall:
+		return completeInterruptedReinstallation(plugin)
+
+	case entry.Operation == journalOperationDelete:
+		clilog.Infof("Completing the interrupted deletion of plugin '%s' for target '%s'", plugin.Name, plugin.Target)
+		return completeInterruptedPluginDeletion(entry.ContextName, plugin)
+
+	case entry.Step == journalStepWritten:
+		if _, err := os.Stat(plugin.InstallationPath); err != nil {
+			// The binary was removed since, there is nothing to register
+			return nil
+		}
+		clilog.Infof("Completing the interrupted installation of plugin '%s' for target '%s'", plugin.Name, plugin.Target)
+		c, err := catalog.NewContextCatalogUpdater(entry.ContextName)
+		if err != nil {
+			return err
+		}
+		err = c.Upsert(plugin)
+		c.Unlock()
+		if err != nil {
+			return err
+		}
+		addPluginToCommandTreeCache(plugin)
+		return nil
+
+	default:
+		// A binary used by a registered plugin, e.g., reinstalled, must be kept,
+		// including when the plugin is registered for a context which is not active
+		registered, err := catalog.IsInstallationPathRegistered(plugin.InstallationPath)
+		if err != nil || registered {
+			return err
+		}
+		clilog.Infof("Removing the binary of the interrupted installation of plugin '%s' for target '%s'", plugin.Name, plugin.Target)
+		if err := os.Remove(plugin.InstallationPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+}
+
+// completeInterruptedPluginDeletion removes the plugin from the catalog of the specified
+// context, empty for the catalog of the standalone plugins, if the catalog still registers
+// its binary.  Once no catalog registers the binary anymore, the binary is removed if it was
+// installed to a custom directory, as a completed deletion does.
+func completeInterruptedPluginDeletion(contextName string, plugin *cli.PluginInfo) error {
+	c, err := catalog.NewContextCatalogUpdater(contextName)
+	if err != nil {
+		return err
+	}
+	key := catalog.PluginNameTarget(plugin.Name, plugin.Target)
+	if registered, found := c.Get(key); found && registered.InstallationPath == plugin.InstallationPath {
+		err = c.Delete(key)
+	}
+	c.Unlock()
+	if err != nil {
+		return err
+	}
+
+	registered, err := catalog.IsInstallationPathRegistered(plugin.InstallationPath)
+	if err != nil || registered {
+		return err
+	}
+	deletePluginFromCommandTreeCache(plugin)
+	removeCustomPluginBinary(plugin.InstallationPath)
+	return nil
+}
+
+// isProcessRunning returns true if a process with the specified identifier is running
+func isProcessRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if cli.BuildArch().IsWindows() {
+		// Finding a process only succeeds on Windows if the process exists
+		_ = process.Release()
+		return true
+	}
+	// The signal 0 only checks that the process exists; a process of another
+	// user exists but cannot be signaled
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
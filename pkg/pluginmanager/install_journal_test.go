@@ -0,0 +1,257 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+// simulateCrash makes the journaled operations look like they were
+// interrupted by a CLI process which is no longer running
+func simulateCrash(t *testing.T) {
+	err := updatePluginJournal(func(entries map[string]*journalEntry) {
+		for _, entry := range entries {
+			entry.PID = math.MaxInt32
+		}
+	})
+	assert.Nil(t, err)
+}
+
+// getJournalEntries returns the operations recorded in the journal
+func getJournalEntries(t *testing.T) map[string]*journalEntry {
+	var entries map[string]*journalEntry
+	err := updatePluginJournal(func(e map[string]*journalEntry) {
+		entries = e
+	})
+	assert.Nil(t, err)
+	return entries
+}
+
+// writeTestPluginBinary writes a binary for the login plugin under the plugin root
+func writeTestPluginBinary(t *testing.T) string {
+	pluginPath := filepath.Join(common.DefaultPluginRoot, "login", "v0.2.0_1234_global")
+	assert.Nil(t, os.MkdirAll(filepath.Dir(pluginPath), 0755))
+	assert.Nil(t, os.WriteFile(pluginPath, []byte("partial binary"), 0755))
+	return pluginPath
+}
+
+func TestJournaledOperation(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	op := startJournaledOperation(journalOperationInstall, "", &cli.PluginInfo{Name: "login", Target: configtypes.TargetGlobal})
+	assertions.NotNil(op)
+	entries := getJournalEntries(t)
+	assertions.Equal(1, len(entries))
+	assertions.Equal(journalStepStarted, entries[op.id].Step)
+	assertions.Equal(os.Getpid(), entries[op.id].PID)
+
+	op.advance(journalStepWritten, &cli.PluginInfo{Name: "login", Target: configtypes.TargetGlobal, Version: "v0.2.0"})
+	entries = getJournalEntries(t)
+	assertions.Equal(journalStepWritten, entries[op.id].Step)
+	assertions.Equal("v0.2.0", entries[op.id].Plugin.Version)
+
+	// The operations of a running process are not recovered
+	assertions.Nil(recoverInterruptedPluginOperations())
+	assertions.Equal(1, len(getJournalEntries(t)))
+
+	op.complete()
+	assertions.Empty(getJournalEntries(t))
+
+	// A nil operation does nothing
+	var nilOp *journaledOperation
+	nilOp.advance(journalStepWritten, &cli.PluginInfo{})
+	nilOp.complete()
+}
+
+func TestRecoverInstallationInterruptedWhileWritingBinary(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	// The binary being written is removed
+	pluginPath := writeTestPluginBinary(t)
+	startJournaledOperation(journalOperationInstall, "", &cli.PluginInfo{Name: "login", Target: configtypes.TargetGlobal, Version: "v0.2.0", InstallationPath: pluginPath})
+	simulateCrash(t)
+
+	assertions.Nil(recoverInterruptedPluginOperations())
+	assertions.NoFileExists(pluginPath)
+	assertions.Empty(getJournalEntries(t))
+
+	// The binary of a registered plugin, being reinstalled, is kept
+	err := InstallStandalonePlugin("login", "v0.2.0", configtypes.TargetGlobal)
+	assertions.Nil(err)
+	installedPlugins, err := pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	installed := findPluginInfo(installedPlugins, "login", configtypes.TargetGlobal)
+	assertions.NotNil(installed)
+	startJournaledOperation(journalOperationInstall, "", installed)
+	simulateCrash(t)
+
+	assertions.Nil(recoverInterruptedPluginOperations())
+	assertions.FileExists(installed.InstallationPath)
+	assertions.Empty(getJournalEntries(t))
+}
+
+func TestRecoverInstallationInterruptedBeforeRegistration(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	pluginPath := writeTestPluginBinary(t)
+	op := startJournaledOperation(journalOperationInstall, "", &cli.PluginInfo{Name: "login", Target: configtypes.TargetGlobal, Version: "v0.2.0", InstallationPath: pluginPath})
+	op.advance(journalStepWritten, &cli.PluginInfo{Name: "login", Target: configtypes.TargetGlobal, Version: "v0.2.0", InstallationPath: pluginPath})
+	simulateCrash(t)
+
+	// The registration of the written binary is completed
+	assertions.Nil(recoverInterruptedPluginOperations())
+	installedPlugins, err := pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	installed := findPluginInfo(installedPlugins, "login", configtypes.TargetGlobal)
+	assertions.NotNil(installed)
+	assertions.Equal("v0.2.0", installed.Version)
+	assertions.Equal(pluginPath, installed.InstallationPath)
+	assertions.Empty(getJournalEntries(t))
+}
+
+func TestRecoverInterruptedDeletion(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	installDir, err := os.MkdirTemp("", "install-dir")
+	assertions.Nil(err)
+	defer os.RemoveAll(installDir)
+
+	// Interrupted before the plugin is unregistered
+	_, err = InstallStandalonePluginWithResult("login", "v0.2.0", configtypes.TargetGlobal, WithInstallDir(installDir))
+	assertions.Nil(err)
+	installedPlugins, err := pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	installed := findPluginInfo(installedPlugins, "login", configtypes.TargetGlobal)
+	assertions.NotNil(installed)
+	startJournaledOperation(journalOperationDelete, "", installed)
+	simulateCrash(t)
+
+	assertions.Nil(recoverInterruptedPluginOperations())
+	installedPlugins, err = pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	assertions.Nil(findPluginInfo(installedPlugins, "login", configtypes.TargetGlobal))
+	assertions.NoFileExists(installed.InstallationPath)
+	assertions.Empty(getJournalEntries(t))
+
+	// Interrupted after the plugin is unregistered but before its binary is removed
+	_, err = InstallStandalonePluginWithResult("login", "v0.2.0", configtypes.TargetGlobal, WithInstallDir(installDir))
+	assertions.Nil(err)
+	installedPlugins, err = pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	installed = findPluginInfo(installedPlugins, "login", configtypes.TargetGlobal)
+	assertions.NotNil(installed)
+	startJournaledOperation(journalOperationDelete, "", installed)
+	c, err := catalog.NewContextCatalogUpdater("")
+	assertions.Nil(err)
+	assertions.Nil(c.Delete(catalog.PluginNameTarget("login", configtypes.TargetGlobal)))
+	c.Unlock()
+	simulateCrash(t)
+
+	assertions.Nil(recoverInterruptedPluginOperations())
+	assertions.NoFileExists(installed.InstallationPath)
+	assertions.Empty(getJournalEntries(t))
+}
+
+func TestRecoverInstallationInterruptedKeepsBinaryOfInactiveContext(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	// The binary is registered for a context which is not active
+	pluginPath := writeTestPluginBinary(t)
+	plugin := &cli.PluginInfo{Name: "login", Target: configtypes.TargetGlobal, Version: "v0.2.0", InstallationPath: pluginPath}
+	c, err := catalog.NewContextCatalogUpdater("inactive-context")
+	assertions.Nil(err)
+	assertions.Nil(c.Upsert(plugin))
+	c.Unlock()
+
+	startJournaledOperation(journalOperationInstall, "", plugin)
+	simulateCrash(t)
+
+	assertions.Nil(recoverInterruptedPluginOperations())
+	assertions.FileExists(pluginPath)
+	assertions.Empty(getJournalEntries(t))
+}
+
+func TestRecoverInterruptedDeletionFromItsCatalogOnly(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	installDir, err := os.MkdirTemp("", "install-dir")
+	assertions.Nil(err)
+	defer os.RemoveAll(installDir)
+
+	// The same binary is registered as a standalone plugin and for a context
+	_, err = InstallStandalonePluginWithResult("login", "v0.2.0", configtypes.TargetGlobal, WithInstallDir(installDir))
+	assertions.Nil(err)
+	installedPlugins, err := pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	installed := findPluginInfo(installedPlugins, "login", configtypes.TargetGlobal)
+	assertions.NotNil(installed)
+	c, err := catalog.NewContextCatalogUpdater("my-context")
+	assertions.Nil(err)
+	assertions.Nil(c.Upsert(installed))
+	c.Unlock()
+
+	// Interrupted while deleting the plugin of the context
+	startJournaledOperation(journalOperationDelete, "my-context", installed)
+	simulateCrash(t)
+
+	assertions.Nil(recoverInterruptedPluginOperations())
+	reader, err := catalog.NewContextCatalog("my-context")
+	assertions.Nil(err)
+	_, found := reader.Get(catalog.PluginNameTarget("login", configtypes.TargetGlobal))
+	assertions.False(found)
+	installedPlugins, err = pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	assertions.NotNil(findPluginInfo(installedPlugins, "login", configtypes.TargetGlobal))
+	// The binary is still used by the standalone plugin
+	assertions.FileExists(installed.InstallationPath)
+	assertions.Empty(getJournalEntries(t))
+}
+
+func TestStartJournaledOperations(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	plugins := []cli.PluginInfo{{Name: "login", Target: configtypes.TargetGlobal}, {Name: "cluster", Target: configtypes.TargetK8s}}
+	ops := startJournaledOperations(journalOperationDelete, []string{"my-context", ""}, plugins)
+	assertions.Equal(4, len(ops))
+	entries := getJournalEntries(t)
+	assertions.Equal(4, len(entries))
+	assertions.Equal("my-context", entries[ops[0].id].ContextName)
+	assertions.Equal("cluster", entries[ops[1].id].Plugin.Name)
+	assertions.Equal("", entries[ops[2].id].ContextName)
+
+	completeJournaledOperations(ops)
+	assertions.Empty(getJournalEntries(t))
+}
@@ -0,0 +1,157 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rogpeppe/go-internal/lockedfile"
+	"gopkg.in/yaml.v3"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+)
+
+// pluginInstallStatusFileName is the name of the file of the cache directory
+// which holds the outcome of the last installation of each plugin
+const pluginInstallStatusFileName = "plugin_install_status.yaml"
+
+// The outcomes of the installation of a plugin
+const (
+	// PluginInstallStatusSucceeded means the last installation of the plugin succeeded
+	PluginInstallStatusSucceeded = "succeeded"
+	// PluginInstallStatusFailed means the last installation of the plugin failed
+	PluginInstallStatusFailed = "failed"
+)
+
+// PluginInstallStatus is the outcome of the last installation of a plugin,
+// either by a plugin install or upgrade, or by a plugin sync
+type PluginInstallStatus struct {
+	Name    string             `json:"name" yaml:"name"`
+	Target  configtypes.Target `json:"target" yaml:"target"`
+	Version string             `json:"version" yaml:"version"`
+	Status  string             `json:"status" yaml:"status"`
+	Reason  string             `json:"reason,omitempty" yaml:"reason,omitempty"`
+	Time    time.Time          `json:"time" yaml:"time"`
+}
+
+// installStatusMutex serializes the updates of the install status file
+// by the plugins installed concurrently, e.g., by a plugin sync
+var installStatusMutex sync.Mutex
+
+func getPluginInstallStatusFilePath() string {
+	return filepath.Join(common.DefaultCacheDir, pluginInstallStatusFileName)
+}
+
+// GetFailedPluginInstallations returns the plugins whose last installation failed,
+// sorted by name and target
+func GetFailedPluginInstallations() ([]*PluginInstallStatus, error) {
+	b, err := lockedfile.Read(getPluginInstallStatusFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "could not read the plugin install status file")
+	}
+	statuses := make(map[string]*PluginInstallStatus)
+	if err := yaml.Unmarshal(b, &statuses); err != nil {
+		return nil, errors.Wrap(err, "could not decode the plugin install status file")
+	}
+
+	var failed []*PluginInstallStatus
+	for _, status := range statuses {
+		if status.Status == PluginInstallStatusFailed {
+			failed = append(failed, status)
+		}
+	}
+	sort.Slice(failed, func(i, j int) bool {
+		if failed[i].Name != failed[j].Name {
+			return failed[i].Name < failed[j].Name
+		}
+		return failed[i].Target < failed[j].Target
+	})
+	return failed, nil
+}
+
+// recordPluginInstallStatus records the outcome of the installation of a plugin.
+// A successful installation replaces any failure recorded for the plugin, including
+// a failure recorded without a target when the target was not specified.
+// Errors are only logged as they must not fail the installation itself.
+func recordPluginInstallStatus(name string, target configtypes.Target, version string, installErr error) {
+	installStatusMutex.Lock()
+	defer installStatusMutex.Unlock()
+
+	if err := updatePluginInstallStatus(func(statuses map[string]*PluginInstallStatus) {
+		status := &PluginInstallStatus{
+			Name:    name,
+			Target:  target,
+			Version: version,
+			Status:  PluginInstallStatusSucceeded,
+			Time:    time.Now().UTC(),
+		}
+		if installErr != nil {
+			status.Status = PluginInstallStatusFailed
+			status.Reason = installErr.Error()
+		} else {
+			delete(statuses, catalog.PluginNameTarget(name, configtypes.TargetUnknown))
+		}
+		statuses[catalog.PluginNameTarget(name, target)] = status
+	}); err != nil {
+		log.V(4).Warningf("Unable to record the install status of plugin '%s': %v", name, err)
+	}
+}
+
+// updatePluginInstallStatus applies the update to the content of the install
+// status file while holding a write lock on the file
+func updatePluginInstallStatus(update func(statuses map[string]*PluginInstallStatus)) error {
+	if err := os.MkdirAll(common.DefaultCacheDir, 0755); err != nil {
+		return err
+	}
+	lockedFile, err := lockedfile.Edit(getPluginInstallStatusFilePath())
+	if err != nil {
+		return err
+	}
+	defer lockedFile.Close()
+
+	b, err := io.ReadAll(lockedFile)
+	if err != nil {
+		return err
+	}
+	statuses := make(map[string]*PluginInstallStatus)
+	if err := yaml.Unmarshal(b, &statuses); err != nil {
+		// Start over rather than failing every future installation
+		statuses = make(map[string]*PluginInstallStatus)
+	}
+	update(statuses)
+
+	out, err := yaml.Marshal(statuses)
+	if err != nil {
+		return err
+	}
+	if err := lockedFile.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := lockedFile.Seek(0, 0); err != nil {
+		return err
+	}
+	_, err = lockedFile.Write(out)
+	return err
+}
+
+// cleanPluginInstallStatus removes the recorded outcomes of the plugin installations
+func cleanPluginInstallStatus() error {
+	if err := os.Remove(getPluginInstallStatusFilePath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
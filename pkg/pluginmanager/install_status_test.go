@@ -0,0 +1,87 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+)
+
+func Test_RecordPluginInstallStatus(t *testing.T) {
+	assertions := assert.New(t)
+
+	tmpDir, err := os.MkdirTemp("", "cache")
+	assertions.Nil(err)
+	defer os.RemoveAll(tmpDir)
+	origCacheDir := common.DefaultCacheDir
+	common.DefaultCacheDir = tmpDir
+	defer func() { common.DefaultCacheDir = origCacheDir }()
+
+	// Nothing was recorded yet
+	failed, err := GetFailedPluginInstallations()
+	assertions.Nil(err)
+	assertions.Empty(failed)
+
+	recordPluginInstallStatus("login", configtypes.TargetUnknown, "v0.2.0", errors.New("download failed"))
+	recordPluginInstallStatus("cluster", configtypes.TargetK8s, "v1.6.0", errors.New("digest mismatch"))
+	recordPluginInstallStatus("feature", configtypes.TargetK8s, "v0.2.0", nil)
+
+	failed, err = GetFailedPluginInstallations()
+	assertions.Nil(err)
+	assertions.Equal(2, len(failed))
+	assertions.Equal("cluster", failed[0].Name)
+	assertions.Equal(configtypes.TargetK8s, failed[0].Target)
+	assertions.Equal("v1.6.0", failed[0].Version)
+	assertions.Equal(PluginInstallStatusFailed, failed[0].Status)
+	assertions.Equal("digest mismatch", failed[0].Reason)
+	assertions.False(failed[0].Time.IsZero())
+	assertions.Equal("login", failed[1].Name)
+	assertions.Equal("download failed", failed[1].Reason)
+
+	// A successful installation clears the failure, even when its target was not specified
+	recordPluginInstallStatus("login", configtypes.TargetGlobal, "v0.2.0", nil)
+	failed, err = GetFailedPluginInstallations()
+	assertions.Nil(err)
+	assertions.Equal(1, len(failed))
+	assertions.Equal("cluster", failed[0].Name)
+
+	assertions.Nil(cleanPluginInstallStatus())
+	failed, err = GetFailedPluginInstallations()
+	assertions.Nil(err)
+	assertions.Empty(failed)
+}
+
+func Test_InstallStandalonePluginRecordsStatus(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	err := InstallStandalonePlugin("login", "v0.888.0", configtypes.TargetUnknown)
+	assertions.NotNil(err)
+
+	failed, err := GetFailedPluginInstallations()
+	assertions.Nil(err)
+	assertions.Equal(1, len(failed))
+	assertions.Equal("login", failed[0].Name)
+	assertions.Equal("v0.888.0", failed[0].Version)
+	assertions.Contains(failed[0].Reason, "unable to find plugin 'login' matching version 'v0.888.0'")
+
+	// Installing the plugin successfully clears the failure
+	err = InstallStandalonePlugin("login", "v0.2.0", configtypes.TargetUnknown)
+	assertions.Nil(err)
+
+	failed, err = GetFailedPluginInstallations()
+	assertions.Nil(err)
+	assertions.Empty(failed)
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	configlib "github.com/vmware-tanzu/tanzu-plugin-runtime/config"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
+)
+
+// installedStatusCacheFileName is the name of the file of the cache directory which
+// holds the installed context plugins used to compute the status of the discovered plugins
+const installedStatusCacheFileName = "installed_plugins_status.yaml"
+
+// catalogModTimeGranularity is how long after it was saved the catalog could be saved
+// again without its modification time changing, on file systems with a coarse one
+const catalogModTimeGranularity = 2 * time.Second
+
+// installedStatusCacheKey identifies the state the installed context plugins were read
+// from: the catalog, the active contexts and the precedence of the standalone plugins
+type installedStatusCacheKey struct {
+	CatalogModTime               time.Time `yaml:"catalogModTime"`
+	CatalogSize                  int64     `yaml:"catalogSize"`
+	ActiveContexts               []string  `yaml:"activeContexts"`
+	StandaloneOverContextPlugins string    `yaml:"standaloneOverContextPlugins"`
+}
+
+// installedStatusCache is the content of the installed status cache file
+type installedStatusCache struct {
+	Key     installedStatusCacheKey `yaml:"key"`
+	Plugins []cli.PluginInfo        `yaml:"plugins"`
+}
+
+func getInstalledStatusCacheFilePath() string {
+	return filepath.Join(common.DefaultCacheDir, installedStatusCacheFileName)
+}
+
+// getInstalledStatusCacheKey returns the key of the current state of the installed plugins
+func getInstalledStatusCacheKey() (installedStatusCacheKey, error) {
+	activeContexts, err := configlib.GetAllActiveContextsList()
+	if err != nil {
+		return installedStatusCacheKey{}, err
+	}
+	modTime, size := catalog.GetCatalogCacheModTime()
+	return installedStatusCacheKey{
+		CatalogModTime:               modTime,
+		CatalogSize:                  size,
+		ActiveContexts:               activeContexts,
+		StandaloneOverContextPlugins: os.Getenv(constants.ConfigVariableStandaloneOverContextPlugins),
+	}, nil
+}
+
+// GetInstalledServerPlugins returns the installed context plugins, to compute the status
+// of the discovered plugins.  They are read from the installed status cache as long as
+// the modification time and the size of the catalog, and the active contexts, are unchanged;
+// every installation or deletion of a plugin saves the catalog and so invalidates the cache.
+// This way repeated 'plugin list' commands do not read the catalog of each active context.
+func GetInstalledServerPlugins() ([]cli.PluginInfo, error) {
+	// The key is computed before reading the catalog, so that a catalog saved meanwhile
+	// is cached under an outdated key, which is never used, rather than the opposite
+	key, err := getInstalledStatusCacheKey()
+	if err != nil {
+		return nil, err
+	}
+	if plugins, found := readInstalledStatusCache(key); found {
+		return plugins, nil
+	}
+
+	plugins, err := pluginsupplier.GetInstalledServerPlugins()
+	if err != nil {
+		return nil, err
+	}
+	// A catalog saved a moment ago could be saved again without its modification time
+	// changing, so its content is only cached once such a change would be noticed
+	if !key.CatalogModTime.IsZero() && time.Since(key.CatalogModTime) > catalogModTimeGranularity {
+		writeInstalledStatusCache(key, plugins)
+	}
+	return plugins, nil
+}
+
+// readInstalledStatusCache returns the cached installed context plugins if they were
+// read from the state identified by the specified key
+func readInstalledStatusCache(key installedStatusCacheKey) ([]cli.PluginInfo, bool) {
+	b, err := os.ReadFile(getInstalledStatusCacheFilePath())
+	if err != nil {
+		return nil, false
+	}
+	var cache installedStatusCache
+	if err := yaml.Unmarshal(b, &cache); err != nil {
+		return nil, false
+	}
+	if !cache.Key.CatalogModTime.Equal(key.CatalogModTime) || cache.Key.CatalogSize != key.CatalogSize ||
+		!slices.Equal(cache.Key.ActiveContexts, key.ActiveContexts) ||
+		cache.Key.StandaloneOverContextPlugins != key.StandaloneOverContextPlugins {
+		return nil, false
+	}
+	return cache.Plugins, true
+}
+
+// writeInstalledStatusCache saves the installed context plugins read from the state
+// identified by the specified key.  The file is written next to the cache and renamed
+// so that a concurrent command never reads a partial cache.
+func writeInstalledStatusCache(key installedStatusCacheKey, plugins []cli.PluginInfo) {
+	b, err := yaml.Marshal(&installedStatusCache{Key: key, Plugins: plugins})
+	if err == nil {
+		tmpFile := getInstalledStatusCacheFilePath() + ".tmp"
+		if err = os.WriteFile(tmpFile, b, 0644); err == nil {
+			err = os.Rename(tmpFile, getInstalledStatusCacheFilePath())
+		}
+	}
+	if err != nil {
+		log.V(4).Infof("Unable to cache the installed plugins: %v", err)
+	}
+}
+
+// UpdatePluginsInstallationStatus updates the installation status of the given plugins
+func UpdatePluginsInstallationStatus(plugins []discovery.Discovered) {
+	if installedPlugins, err := GetInstalledServerPlugins(); err == nil {
+		ReconcilePluginsStatus(plugins, installedPlugins)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+)
+
+// ageCatalog makes the catalog look like it was saved a while ago,
+// so that the installed plugins read from it can be cached
+func ageCatalog(t testing.TB) {
+	lastSaved := time.Now().Add(-time.Minute)
+	assert.Nil(t, os.Chtimes(filepath.Join(common.DefaultCacheDir, "catalog.yaml"), lastSaved, lastSaved))
+}
+
+func TestGetInstalledServerPluginsCache(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	_ = SyncPlugins()
+
+	// A catalog which was just saved is not cached
+	installed, err := GetInstalledServerPlugins()
+	assertions.Nil(err)
+	assertions.Equal(len(expectedDiscoveredContextPlugins), len(installed))
+	assertions.NoFileExists(getInstalledStatusCacheFilePath())
+
+	ageCatalog(t)
+	installed, err = GetInstalledServerPlugins()
+	assertions.Nil(err)
+	assertions.Equal(len(expectedDiscoveredContextPlugins), len(installed))
+	assertions.FileExists(getInstalledStatusCacheFilePath())
+
+	// The cached plugins are used as long as the catalog is unchanged
+	b, err := os.ReadFile(getInstalledStatusCacheFilePath())
+	assertions.Nil(err)
+	var cache installedStatusCache
+	assertions.Nil(yaml.Unmarshal(b, &cache))
+	cache.Plugins[0].Version = "cached"
+	b, err = yaml.Marshal(&cache)
+	assertions.Nil(err)
+	assertions.Nil(os.WriteFile(getInstalledStatusCacheFilePath(), b, 0644))
+
+	installed, err = GetInstalledServerPlugins()
+	assertions.Nil(err)
+	assertions.Equal("cached", installed[0].Version)
+
+	serverPlugins, err := DiscoverServerPlugins()
+	assertions.NotNil(err)
+	assertions.NotEmpty(serverPlugins)
+	UpdatePluginsInstallationStatus(serverPlugins)
+	for i := range serverPlugins {
+		if serverPlugins[i].Name == installed[0].Name && serverPlugins[i].Target == installed[0].Target {
+			assertions.Equal("cached", serverPlugins[i].InstalledVersion)
+		}
+	}
+
+	// The cache is not used once the precedence of the standalone plugins changes
+	os.Setenv(constants.ConfigVariableStandaloneOverContextPlugins, "false")
+	installed, err = GetInstalledServerPlugins()
+	os.Unsetenv(constants.ConfigVariableStandaloneOverContextPlugins)
+	assertions.Nil(err)
+	assertions.NotEqual("cached", installed[0].Version)
+
+	// The cache is invalidated by the deletion of a plugin, which saves the catalog
+	err = DeletePlugin(DeletePluginOptions{PluginName: installed[0].Name, Target: installed[0].Target, ForceDelete: true})
+	assertions.Nil(err)
+	installed, err = GetInstalledServerPlugins()
+	assertions.Nil(err)
+	assertions.Equal(len(expectedDiscoveredContextPlugins)-1, len(installed))
+	for i := range installed {
+		assertions.NotEqual("cached", installed[i].Version)
+	}
+}
+
+func BenchmarkReconcilePluginsStatus(b *testing.B) {
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	_ = SyncPlugins()
+	ageCatalog(b)
+	serverPlugins, _ := DiscoverServerPlugins()
+
+	// reconcile computes the status of the context plugins as "plugin list" does
+	reconcile := func(b *testing.B, removeCache bool) {
+		for i := 0; i < b.N; i++ {
+			if removeCache {
+				_ = os.Remove(getInstalledStatusCacheFilePath())
+			}
+			installed, err := GetInstalledServerPlugins()
+			if err != nil {
+				b.Fatal(err)
+			}
+			ReconcilePluginsStatus(append([]discovery.Discovered(nil), serverPlugins...), installed)
+		}
+	}
+	b.Run("uncached", func(b *testing.B) {
+		reconcile(b, true)
+	})
+	b.Run("cached", func(b *testing.B) {
+		reconcile(b, false)
+	})
+}
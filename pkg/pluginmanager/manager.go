@@ -5,14 +5,21 @@
 package pluginmanager
 
 import (
+	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,12 +31,18 @@ import (
 
 	cliv1alpha1 "github.com/vmware-tanzu/tanzu-cli/apis/cli/v1alpha1"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/artifact"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/buildinfo"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper/sigverifier"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/interrupt"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugincmdtree"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
@@ -57,31 +70,144 @@ const (
 
 var execCommand = exec.Command
 
+// pluginHandshakeTimeout is the maximum time given to a plugin binary
+// to describe itself through its "info" command
+var pluginHandshakeTimeout = 30 * time.Second
+
+// pluginPostInstallTimeout is the maximum time given to a plugin
+// to initialize itself through its "post-install" command
+var pluginPostInstallTimeout = 2 * time.Minute
+
 type DeletePluginOptions struct {
 	Target      configtypes.Target
 	PluginName  string
 	ForceDelete bool
+	// PruneCache also removes the cached plugin inventories no longer used
+	// by any installed plugin or configured discovery source
+	PruneCache bool
+}
+
+// DiscoverySourceError is returned when the plugins of a discovery source
+// cannot be fetched or verified
+type DiscoverySourceError struct {
+	// Source is the name of the discovery source
+	Source string
+	// Err describes why the discovery source could not be used
+	Err error
+}
+
+func (e *DiscoverySourceError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DiscoverySourceError) Unwrap() error {
+	return e.Err
+}
+
+// UnreachableDiscoverySourcesError is returned by a strict plugin sync
+// when some of its discovery sources cannot be fetched or verified
+type UnreachableDiscoverySourcesError struct {
+	// Sources are the errors of the discovery sources that failed, in the order of the sources
+	Sources []*DiscoverySourceError
+}
+
+func (e *UnreachableDiscoverySourcesError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d discovery source(s) could not be fetched or verified:", len(e.Sources))
+	for _, sourceErr := range e.Sources {
+		fmt.Fprintf(&sb, "\n  - '%s': %v", sourceErr.Source, sourceErr.Err)
+	}
+	return sb.String()
+}
+
+// discoveryResult holds the outcome of listing the plugins of a single discovery source.
+type discoveryResult struct {
+	// index is the position of the discovery source in the list of sources
+	index   int
+	plugins []discovery.Discovered
+	err     error
+}
+
+// listPluginsFromDiscoveries lists the plugins of every PluginDiscovery source and sends the
+// result of each source on the returned channel as soon as it is available.
+// The channel is closed once all sources have been processed.  If the context is done,
+// the pending results are dropped.
+// The sources are only queried concurrently when requested, as the discoveries can log
+// messages and prompt the user, which would otherwise interleave between sources;
+// concurrent callers must therefore be prepared for such interleaving.
+func listPluginsFromDiscoveries(ctx context.Context, pd []configtypes.PluginDiscovery, concurrent bool, options ...discovery.DiscoveryOptions) <-chan discoveryResult {
+	results := make(chan discoveryResult)
+
+	listPlugins := func(index int, d configtypes.PluginDiscovery) bool {
+		result := discoveryResult{index: index}
+		discObject, err := discovery.CreateDiscoveryFromV1alpha1(d, options...)
+		if err != nil {
+			result.err = &DiscoverySourceError{Source: discovery.GetDiscoveryName(d), Err: errors.Wrapf(err, "unable to create discovery")}
+		} else if result.plugins, err = discObject.List(); err != nil {
+			result.err = &DiscoverySourceError{Source: discObject.Name(), Err: errors.Wrapf(err, "unable to list plugins from discovery source '%v'", discObject.Name())}
+		}
+
+		select {
+		case results <- result:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	if !concurrent {
+		go func() {
+			defer close(results)
+			for i := range pd {
+				if !listPlugins(i, pd[i]) {
+					return
+				}
+			}
+		}()
+		return results
+	}
+
+	var wg sync.WaitGroup
+	for i := range pd {
+		wg.Add(1)
+		go func(index int, d configtypes.PluginDiscovery) {
+			defer wg.Done()
+			listPlugins(index, d)
+		}(i, pd[i])
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
 }
 
 // discoverSpecificPlugins returns all plugins that match the specified criteria from all PluginDiscovery sources,
 // along with an aggregated error (if any) that occurred while creating the plugin discovery source or fetching plugins.
+// The sources are queried one after the other so that any message or prompt of a source is not interleaved
+// with those of another source.
 func discoverSpecificPlugins(pd []configtypes.PluginDiscovery, options ...discovery.DiscoveryOptions) ([]discovery.Discovered, error) {
+	// Verify the signatures of the inventory images to download as one batch
+	// instead of letting each discovery source verify its own image
+	discovery.VerifyInventoryImageSignatures(pd, options...)
+
+	resultsPerSource := make([]discoveryResult, len(pd))
+	for result := range listPluginsFromDiscoveries(interrupt.Context(), pd, false, options...) {
+		resultsPerSource[result.index] = result
+	}
+	if interrupt.Interrupted() {
+		return nil, interrupt.ErrInterrupted
+	}
+
 	allPlugins := make([]discovery.Discovered, 0)
 	errorList := make([]error, 0)
-	for _, d := range pd {
-		discObject, err := discovery.CreateDiscoveryFromV1alpha1(d, options...)
-		if err != nil {
-			errorList = append(errorList, errors.Wrapf(err, "unable to create discovery"))
-			continue
-		}
-
-		plugins, err := discObject.List()
-		if err != nil {
-			errorList = append(errorList, errors.Wrapf(err, "unable to list plugins from discovery source '%v'", discObject.Name()))
+	for _, result := range resultsPerSource {
+		if result.err != nil {
+			errorList = append(errorList, result.err)
 			continue
 		}
-
-		allPlugins = append(allPlugins, plugins...)
+		allPlugins = append(allPlugins, result.plugins...)
 	}
 	return allPlugins, kerrors.NewAggregate(errorList)
 }
@@ -125,6 +251,138 @@ func DiscoverStandalonePlugins(options ...discovery.DiscoveryOptions) ([]discove
 	return mergeDuplicatePlugins(plugins), err
 }
 
+// DuplicatePlugin is a plugin provided by more than one discovery source
+type DuplicatePlugin struct {
+	Name    string                  `json:"name" yaml:"name"`
+	Target  configtypes.Target      `json:"target" yaml:"target"`
+	Sources []DuplicatePluginSource `json:"sources" yaml:"sources"`
+}
+
+// DuplicatePluginSource is a discovery source providing a DuplicatePlugin
+type DuplicatePluginSource struct {
+	Source   string   `json:"source" yaml:"source"`
+	Versions []string `json:"versions" yaml:"versions"`
+}
+
+// GetDuplicateStandalonePlugins returns the standalone plugins provided by more than one
+// of the configured discovery sources, as found before their entries are merged.
+// It helps identifying discovery sources that overlap, e.g., misconfigured mirrors.
+// As for DiscoverStandalonePlugins(), the error of a discovery source is returned
+// along with the duplicates found among the other sources.
+func GetDuplicateStandalonePlugins(options ...discovery.DiscoveryOptions) ([]DuplicatePlugin, error) {
+	discoveries, err := getPluginDiscoveries()
+	if err != nil {
+		return nil, err
+	} else if len(discoveries) == 0 {
+		return nil, errors.New(errorNoDiscoverySourcesFound)
+	}
+
+	plugins, err := discoverSpecificPlugins(discoveries, options...)
+	return findDuplicatePlugins(plugins), err
+}
+
+// findDuplicatePlugins groups the plugins by name and target and returns those provided
+// by more than one source, sorted by name and target.  The sources of a plugin are kept
+// in the order in which they were found.  As for mergeDuplicatePlugins(), a plugin
+// without a target is considered the same as the plugin for the kubernetes target.
+func findDuplicatePlugins(plugins []discovery.Discovered) []DuplicatePlugin {
+	var keys []string
+	pluginsByKey := make(map[string]*DuplicatePlugin)
+	for i := range plugins {
+		target := plugins[i].Target
+		if target == configtypes.TargetUnknown {
+			target = configtypes.TargetK8s
+		}
+		key := catalog.PluginNameTarget(plugins[i].Name, target)
+		dp, exists := pluginsByKey[key]
+		if !exists {
+			dp = &DuplicatePlugin{Name: plugins[i].Name, Target: target}
+			pluginsByKey[key] = dp
+			keys = append(keys, key)
+		}
+
+		// The same source cannot normally provide a plugin twice but,
+		// if it does, it must not be reported as a duplicate
+		var source *DuplicatePluginSource
+		for j := range dp.Sources {
+			if dp.Sources[j].Source == plugins[i].Source {
+				source = &dp.Sources[j]
+				break
+			}
+		}
+		if source == nil {
+			dp.Sources = append(dp.Sources, DuplicatePluginSource{Source: plugins[i].Source})
+			source = &dp.Sources[len(dp.Sources)-1]
+		}
+		for _, v := range plugins[i].SupportedVersions {
+			if !utils.ContainsString(source.Versions, v) {
+				source.Versions = append(source.Versions, v)
+			}
+		}
+	}
+
+	var duplicates []DuplicatePlugin
+	for _, key := range keys {
+		if len(pluginsByKey[key].Sources) > 1 {
+			duplicates = append(duplicates, *pluginsByKey[key])
+		}
+	}
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		if duplicates[i].Name != duplicates[j].Name {
+			return duplicates[i].Name < duplicates[j].Name
+		}
+		return duplicates[i].Target < duplicates[j].Target
+	})
+	return duplicates
+}
+
+// DiscoverStandalonePluginsStream returns the available standalone plugins through a channel,
+// sending the plugins of each discovery source as soon as that source has been processed.
+// Errors encountered for a discovery source are sent on the error channel.
+// Contrary to DiscoverStandalonePlugins(), the same plugin can be received more than once
+// if it is provided by multiple discovery sources.
+// Both channels are closed once all discovery sources have been processed or when the
+// context is done.  The error channel is buffered so that the caller can read the plugins
+// until the plugin channel is closed before reading the errors.
+func DiscoverStandalonePluginsStream(ctx context.Context, options ...discovery.DiscoveryOptions) (<-chan discovery.Discovered, <-chan error) {
+	pluginsCh := make(chan discovery.Discovered)
+
+	discoveries, err := getPluginDiscoveries()
+	if err == nil && len(discoveries) == 0 {
+		err = errors.New(errorNoDiscoverySourcesFound)
+	}
+	if err != nil {
+		errCh := make(chan error, 1)
+		errCh <- err
+		close(errCh)
+		close(pluginsCh)
+		return pluginsCh, errCh
+	}
+
+	errCh := make(chan error, len(discoveries))
+	go func() {
+		defer close(errCh)
+		defer close(pluginsCh)
+
+		for result := range listPluginsFromDiscoveries(ctx, discoveries, true, options...) {
+			if result.err != nil {
+				errCh <- result.err
+				continue
+			}
+			for i := range result.plugins {
+				result.plugins[i].Scope = common.PluginScopeStandalone
+				result.plugins[i].Status = common.PluginStatusNotInstalled
+				select {
+				case pluginsCh <- result.plugins[i]:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return pluginsCh, errCh
+}
+
 // DiscoverPluginGroups returns the available plugin groups
 func DiscoverPluginGroups(options ...discovery.DiscoveryOptions) ([]*plugininventory.PluginGroup, error) {
 	discoveries, err := getPluginDiscoveries()
@@ -163,7 +421,7 @@ func GetAdditionalTestPluginDiscoveries() []configtypes.PluginDiscovery {
 }
 
 // DiscoverServerPlugins returns the available discovered plugins associated with all active contexts
-func DiscoverServerPlugins() ([]discovery.Discovered, error) {
+func DiscoverServerPlugins(options ...PluginManagerOptions) ([]discovery.Discovered, error) {
 	currentContextMap, err := configlib.GetAllActiveContextsMap()
 	if err != nil {
 		return nil, err
@@ -172,20 +430,82 @@ func DiscoverServerPlugins() ([]discovery.Discovered, error) {
 	for _, context := range currentContextMap {
 		contexts = append(contexts, context)
 	}
-	return DiscoverServerPluginsForGivenContexts(contexts)
+	return DiscoverServerPluginsForGivenContexts(contexts, options...)
+}
+
+// getServerDiscoverySources returns the discovery sources providing the plugins of a context
+func getServerDiscoverySources(context *configtypes.Context) []configtypes.PluginDiscovery {
+	var discoverySources []configtypes.PluginDiscovery
+	discoverySources = append(discoverySources, context.DiscoverySources...)
+	discoverySources = append(discoverySources, defaultDiscoverySourceBasedOnContext(context)...)
+	return discoverySources
+}
+
+// ValidateServerDiscoverySource returns an error if none of the active contexts
+// has a discovery source with the specified name.
+func ValidateServerDiscoverySource(name string) error {
+	currentContextMap, err := configlib.GetAllActiveContextsMap()
+	if err != nil {
+		return err
+	}
+
+	var sourceNames []string
+	for _, context := range currentContextMap {
+		for _, ds := range getServerDiscoverySources(context) {
+			if discovery.CheckDiscoveryName(ds, name) {
+				return nil
+			}
+			sourceNames = append(sourceNames, discovery.GetDiscoveryName(ds))
+		}
+	}
+	if len(sourceNames) == 0 {
+		return errors.Errorf("discovery source '%s' not found, the active contexts don't have any discovery source", name)
+	}
+	sort.Strings(sourceNames)
+	return errors.Errorf("discovery source '%s' not found, the discovery sources of the active contexts are: %s", name, strings.Join(sourceNames, ", "))
+}
+
+// ValidateActiveContext returns an error if none of the active contexts has the specified name
+func ValidateActiveContext(name string) error {
+	currentContextMap, err := configlib.GetAllActiveContextsMap()
+	if err != nil {
+		return err
+	}
+
+	var contextNames []string
+	for _, context := range currentContextMap {
+		if context.Name == name {
+			return nil
+		}
+		contextNames = append(contextNames, context.Name)
+	}
+	if len(contextNames) == 0 {
+		return errors.Errorf("context '%s' not found, there is no active context", name)
+	}
+	sort.Strings(contextNames)
+	return errors.Errorf("context '%s' not found, the active contexts are: %s", name, strings.Join(contextNames, ", "))
 }
 
 // DiscoverServerPluginsForGivenContexts returns the available discovered plugins associated with specific contexts
-func DiscoverServerPluginsForGivenContexts(contexts []*configtypes.Context) ([]discovery.Discovered, error) {
+func DiscoverServerPluginsForGivenContexts(contexts []*configtypes.Context, options ...PluginManagerOptions) ([]discovery.Discovered, error) {
+	opts := NewPluginManagerOpts(options...)
+
 	var plugins []discovery.Discovered
 	var errList []error
 	if len(contexts) == 0 {
 		return plugins, nil
 	}
 	for _, context := range contexts {
-		var discoverySources []configtypes.PluginDiscovery
-		discoverySources = append(discoverySources, context.DiscoverySources...)
-		discoverySources = append(discoverySources, defaultDiscoverySourceBasedOnContext(context)...)
+		discoverySources := getServerDiscoverySources(context)
+		if opts.discoverySource != "" {
+			var selectedSources []configtypes.PluginDiscovery
+			for _, ds := range discoverySources {
+				if discovery.CheckDiscoveryName(ds, opts.discoverySource) {
+					selectedSources = append(selectedSources, ds)
+				}
+			}
+			discoverySources = selectedSources
+		}
 		discoveredPlugins, err := discoverSpecificPlugins(discoverySources)
 
 		// If there is an error while discovering plugins from all of the given plugin sources,
@@ -214,12 +534,35 @@ func DiscoverServerPluginsForGivenContexts(contexts []*configtypes.Context) ([]d
 			if matchedRecommendedVersion != "" {
 				discoveredPlugins[i].RecommendedVersion = matchedRecommendedVersion
 			}
+			applyPinnedVersion(&discoveredPlugins[i])
 		}
 		plugins = append(plugins, discoveredPlugins...)
 	}
 	return plugins, kerrors.NewAggregate(errList)
 }
 
+// applyPinnedVersion replaces the recommended version of a context plugin with the
+// version it is pinned to, if any, so that syncing plugins installs or keeps that
+// exact version instead of the version recommended by the server.
+// The pin is honored even when the version is no longer available, in which case
+// the installed plugin is kept as is but a warning is logged.
+func applyPinnedVersion(p *discovery.Discovered) {
+	pinnedVersion := config.GetPinnedPluginVersion(p.Name, p.Target)
+	if pinnedVersion == "" {
+		return
+	}
+	if matchedVersion := getMatchingRecommendedVersionOfPlugin(p.Name, p.Target, pinnedVersion); matchedVersion != "" {
+		pinnedVersion = matchedVersion
+	}
+	if len(p.SupportedVersions) > 0 && !utils.ContainsString(p.SupportedVersions, pinnedVersion) {
+		log.Warningf("plugin '%s' is pinned to version '%s' which is no longer available, the recommended version is '%s'", p.Name, pinnedVersion, p.RecommendedVersion)
+	}
+	if pinnedVersion != p.RecommendedVersion {
+		log.V(6).Infof("using pinned version '%s' of plugin '%s' instead of the recommended version '%s'", pinnedVersion, p.Name, p.RecommendedVersion)
+	}
+	p.RecommendedVersion = pinnedVersion
+}
+
 func getMatchingRecommendedVersionOfPlugin(pluginName string, pluginTarget configtypes.Target, version string) string {
 	criteria := &discovery.PluginDiscoveryCriteria{
 		Name:    pluginName,
@@ -266,6 +609,19 @@ func mergePluginEntries(plugin1, plugin2 *discovery.Discovered) *discovery.Disco
 		plugin1.DiscoveryType = ""
 	}
 
+	// Keep the vendor and publisher of the first plugin unless it does not provide them
+	if plugin1.Vendor == "" && plugin1.Publisher == "" {
+		plugin1.Vendor = plugin2.Vendor
+		plugin1.Publisher = plugin2.Publisher
+	}
+
+	// Same for the URLs of the web pages about the plugin
+	if !plugin1.HasLinks() {
+		plugin1.HomepageURL = plugin2.HomepageURL
+		plugin1.DocumentationURL = plugin2.DocumentationURL
+		plugin1.IssueTrackerURL = plugin2.IssueTrackerURL
+	}
+
 	artifacts1, ok := plugin1.Distribution.(distribution.Artifacts)
 	if !ok {
 		// This should not happened
@@ -280,6 +636,11 @@ func mergePluginEntries(plugin1, plugin2 *discovery.Discovered) *discovery.Disco
 		return plugin1
 	}
 
+	// The deprecation of the whole first plugin only applies to its own versions
+	if !plugin2.Deprecated {
+		plugin1.DeprecateVersions()
+	}
+
 	// For every version in the second plugin, if it doesn't already exist
 	// in the first plugin, add it.
 	// Also build the new list of supported versions
@@ -288,14 +649,40 @@ func mergePluginEntries(plugin1, plugin2 *discovery.Discovered) *discovery.Disco
 		if !exists {
 			artifacts1[version] = artifacts2[version]
 			plugin1.SupportedVersions = append(plugin1.SupportedVersions, version)
+
+			// Keep the dependencies that go with the version that was added
+			if deps, found := plugin2.Dependencies[version]; found {
+				if plugin1.Dependencies == nil {
+					plugin1.Dependencies = make(map[string][]*plugininventory.PluginIdentifier)
+				}
+				plugin1.Dependencies[version] = deps
+			}
+
+			// Same for the deprecation of the version that was added
+			if deprecated, message := plugin2.GetDeprecation(version); deprecated {
+				if plugin1.DeprecatedVersions == nil {
+					plugin1.DeprecatedVersions = make(map[string]string)
+				}
+				plugin1.DeprecatedVersions[version] = message
+			}
+
+			// And for the minimum CLI version required by the version that was added
+			if minCLIVersion, found := plugin2.MinCLIVersions[version]; found {
+				if plugin1.MinCLIVersions == nil {
+					plugin1.MinCLIVersions = make(map[string]string)
+				}
+				plugin1.MinCLIVersions[version] = minCLIVersion
+			}
 		}
 	}
 	plugin1.Distribution = artifacts1
 	_ = utils.SortVersions(plugin1.SupportedVersions)
 
-	// Set the recommended version to the highest version
+	// Set the recommended version to the highest stable version, or to the highest
+	// version if the configured strategy includes pre-release versions
 	if len(plugin1.SupportedVersions) > 0 {
-		plugin1.RecommendedVersion = plugin1.SupportedVersions[len(plugin1.SupportedVersions)-1]
+		includePrerelease := config.GetPluginRecommendedVersionStrategy() == config.RecommendedVersionStrategyHighestIncludingPrerelease
+		plugin1.RecommendedVersion = utils.GetLatestVersion(plugin1.SupportedVersions, includePrerelease)
 	}
 
 	// Keep the following fields from the first plugin found
@@ -314,8 +701,15 @@ func mergePluginEntries(plugin1, plugin2 *discovery.Discovered) *discovery.Disco
 // found that will be kept.  The order of the array "plugins" therefore matters.
 // This merge operation is deterministic due to the sequence of sources/plugins that we process always
 // being the same.
+// When the discovery sources providing a plugin have different priorities, the entry of the source
+// with the highest priority is preferred: its versions and its recommended version win.  When the
+// priorities are equal, the order of the array "plugins" breaks the tie as described above.
 func mergeDuplicatePlugins(plugins []discovery.Discovered) []discovery.Discovered {
 	mapOfSelectedPlugins := make(map[string]*discovery.Discovered)
+	// The priority of the source of the entry selected for each plugin
+	selectedPriorities := make(map[string]int)
+	// The priority of each discovery source, to only look it up once
+	sourcePriorities := make(map[string]int)
 	for i := range plugins {
 		target := plugins[i].Target
 		if target == configtypes.TargetUnknown {
@@ -324,13 +718,31 @@ func mergeDuplicatePlugins(plugins []discovery.Discovered) []discovery.Discovere
 			target = configtypes.TargetK8s
 		}
 
+		priority, found := sourcePriorities[plugins[i].Source]
+		if !found {
+			priority = config.GetPluginDiscoveryPriority(plugins[i].Source)
+			sourcePriorities[plugins[i].Source] = priority
+		}
+
 		// If plugin doesn't exist in the map then add the plugin to the map
-		// else merge the two entries, giving priority to the first one found
+		// else merge the two entries, giving priority to the entry of the source
+		// with the highest priority or, if they are equal, to the first one found
 		key := fmt.Sprintf("%s_%s", plugins[i].Name, target)
 		dp, exists := mapOfSelectedPlugins[key]
-		if !exists {
+		switch {
+		case !exists:
 			mapOfSelectedPlugins[key] = &plugins[i]
-		} else {
+			selectedPriorities[key] = priority
+		case priority > selectedPriorities[key]:
+			recommendedVersion := plugins[i].RecommendedVersion
+			mapOfSelectedPlugins[key] = mergePluginEntries(&plugins[i], dp)
+			mapOfSelectedPlugins[key].RecommendedVersion = recommendedVersion
+			selectedPriorities[key] = priority
+		case priority < selectedPriorities[key]:
+			recommendedVersion := dp.RecommendedVersion
+			mapOfSelectedPlugins[key] = mergePluginEntries(dp, &plugins[i])
+			mapOfSelectedPlugins[key].RecommendedVersion = recommendedVersion
+		default:
 			mapOfSelectedPlugins[key] = mergePluginEntries(dp, &plugins[i])
 		}
 	}
@@ -398,20 +810,192 @@ func mergeDuplicateGroups(groups []*plugininventory.PluginGroup) []*plugininvent
 	return mergedGroups
 }
 
-func setAvailablePluginsStatus(availablePlugins []discovery.Discovered, installedPlugins []cli.PluginInfo) {
-	for i := range installedPlugins {
-		for j := range availablePlugins {
-			if installedPlugins[i].Name == availablePlugins[j].Name && installedPlugins[i].Target == availablePlugins[j].Target {
-				// Match found, Check for update available and update status
-				if installedPlugins[i].DiscoveredRecommendedVersion == availablePlugins[j].RecommendedVersion {
-					availablePlugins[j].Status = common.PluginStatusInstalled
-				} else {
-					availablePlugins[j].Status = common.PluginStatusUpdateAvailable
-				}
-				availablePlugins[j].InstalledVersion = installedPlugins[i].Version
+// ReconcilePluginsStatus sets the InstalledVersion and Status of the discovered plugins
+// based on the installed plugins.  A discovered plugin matches an installed plugin if
+// they have the same name and target.  The resulting Status is one of:
+//   - common.PluginStatusNotInstalled: no installed plugin matches
+//   - common.PluginStatusInstalled: the recommended version is installed
+//   - common.PluginStatusUpdateAvailable: another version is installed and the recommended version should be installed instead
+//   - common.PluginStatusOutdated: the installed version is no longer provided by the discovery sources
+func ReconcilePluginsStatus(discoveredPlugins []discovery.Discovered, installedPlugins []cli.PluginInfo) {
+	for i := range discoveredPlugins {
+		var installed *cli.PluginInfo
+		for j := range installedPlugins {
+			if installedPlugins[j].Name == discoveredPlugins[i].Name && installedPlugins[j].Target == discoveredPlugins[i].Target {
+				installed = &installedPlugins[j]
+				break
 			}
 		}
+
+		discoveredPlugins[i].Status = getPluginStatus(&discoveredPlugins[i], installed)
+		discoveredPlugins[i].InstalledVersion = ""
+		if installed != nil {
+			discoveredPlugins[i].InstalledVersion = installed.Version
+		}
+	}
+}
+
+// getPluginStatus returns the status of a discovered plugin given the installed
+// plugin matching it, which is nil if the plugin is not installed.
+func getPluginStatus(p *discovery.Discovered, installed *cli.PluginInfo) string {
+	status, _ := explainPluginStatus(p, installed)
+	return status
+}
+
+// explainPluginStatus returns the status of a discovered plugin given the installed
+// plugin matching it, which is nil if the plugin is not installed, along with the
+// comparison of the versions that produced it.
+func explainPluginStatus(p *discovery.Discovered, installed *cli.PluginInfo) (status, reason string) {
+	if installed == nil {
+		return common.PluginStatusNotInstalled, "no version of the plugin is installed"
+	}
+	// The recommended version at the time of installation is stored in the catalog.
+	// If it has not changed since then, the plugin is considered up-to-date.
+	if installed.Version == p.RecommendedVersion {
+		return common.PluginStatusInstalled, fmt.Sprintf("the installed version %s is the recommended version", installed.Version)
+	}
+	if installed.DiscoveredRecommendedVersion == p.RecommendedVersion {
+		return common.PluginStatusInstalled, fmt.Sprintf("the recommended version %s has not changed since version %s was installed", p.RecommendedVersion, installed.Version)
+	}
+	if len(p.SupportedVersions) > 0 && !utils.ContainsString(p.SupportedVersions, installed.Version) {
+		return common.PluginStatusOutdated, fmt.Sprintf("the installed version %s is no longer provided by the discovery sources, the recommended version is %s", installed.Version, p.RecommendedVersion)
+	}
+	if installed.DiscoveredRecommendedVersion == "" {
+		return common.PluginStatusUpdateAvailable, fmt.Sprintf("the installed version %s differs from the recommended version %s", installed.Version, p.RecommendedVersion)
+	}
+	return common.PluginStatusUpdateAvailable, fmt.Sprintf("the recommended version changed from %s to %s since version %s was installed", installed.DiscoveredRecommendedVersion, p.RecommendedVersion, installed.Version)
+}
+
+// getCachedStandalonePluginsByID returns the standalone plugins of the plugin inventories
+// already in the cache, indexed by catalog.PluginNameTarget().  The discovery images are
+// not fetched, so that the details about the installed plugins it provides, e.g., for
+// 'plugin list' or 'plugin describe', never require access to the registries.
+func getCachedStandalonePluginsByID() (map[string]*discovery.Discovered, error) {
+	discoveredPlugins, err := DiscoverStandalonePlugins(discovery.WithUseLocalCacheOnly())
+	if err != nil {
+		return nil, err
+	}
+	discoveredByID := make(map[string]*discovery.Discovered, len(discoveredPlugins))
+	for i := range discoveredPlugins {
+		discoveredByID[catalog.PluginNameTarget(discoveredPlugins[i].Name, discoveredPlugins[i].Target)] = &discoveredPlugins[i]
+	}
+	return discoveredByID, nil
+}
+
+// GetPluginsDeprecation returns the deprecation message of the specified plugins
+// whose version is deprecated, indexed by catalog.PluginNameTarget().
+func GetPluginsDeprecation(plugins []cli.PluginInfo) map[string]string {
+	deprecations := make(map[string]string)
+	if len(plugins) == 0 {
+		return deprecations
+	}
+
+	discoveredByID, err := getCachedStandalonePluginsByID()
+	if err != nil {
+		log.V(4).Warningf("unable to get the deprecation of the plugins: %v", err)
+		return deprecations
+	}
+
+	for i := range plugins {
+		id := catalog.PluginNameTarget(plugins[i].Name, plugins[i].Target)
+		p, found := discoveredByID[id]
+		if !found {
+			continue
+		}
+		if deprecated, message := p.GetDeprecation(plugins[i].Version); deprecated {
+			deprecations[id] = message
+		}
+	}
+	return deprecations
+}
+
+// PluginLinks are the URLs of the web pages about a plugin provided by its discovery source
+type PluginLinks struct {
+	Homepage      string `json:"homepage,omitempty" yaml:"homepage,omitempty"`
+	Documentation string `json:"documentation,omitempty" yaml:"documentation,omitempty"`
+	IssueTracker  string `json:"issueTracker,omitempty" yaml:"issueTracker,omitempty"`
+}
+
+func getPluginLinks(p *discovery.Discovered) PluginLinks {
+	return PluginLinks{
+		Homepage:      p.HomepageURL,
+		Documentation: p.DocumentationURL,
+		IssueTracker:  p.IssueTrackerURL,
+	}
+}
+
+// GetPluginLinks returns the URLs of the web pages about the specified plugin
+// provided by the discovery sources; they are empty if none is provided.
+func GetPluginLinks(plugin *cli.PluginInfo) PluginLinks {
+	discoveredByID, err := getCachedStandalonePluginsByID()
+	if err != nil {
+		log.V(4).Warningf("unable to get the links of plugin '%s': %v", plugin.Name, err)
+		return PluginLinks{}
+	}
+	if p, found := discoveredByID[catalog.PluginNameTarget(plugin.Name, plugin.Target)]; found {
+		return getPluginLinks(p)
+	}
+	return PluginLinks{}
+}
+
+// GetUnavailablePlugins returns the specified plugins whose installed version is no
+// longer provided by the discovery sources, indexed by catalog.PluginNameTarget().
+// This happens when a version is removed from a plugin inventory after being installed;
+// such a version can no longer be reinstalled or verified from the discovery sources.
+// Plugins that are not found in any plugin inventory, e.g., plugins installed from a
+// local source, are not reported.
+func GetUnavailablePlugins(plugins []cli.PluginInfo) map[string]bool {
+	unavailable := make(map[string]bool)
+	if len(plugins) == 0 {
+		return unavailable
+	}
+
+	discoveredByID, err := getCachedStandalonePluginsByID()
+	if err != nil {
+		log.V(4).Warningf("unable to get the availability of the plugins: %v", err)
+		return unavailable
+	}
+
+	for i := range plugins {
+		id := catalog.PluginNameTarget(plugins[i].Name, plugins[i].Target)
+		p, found := discoveredByID[id]
+		if !found {
+			continue
+		}
+		p.InstalledVersion = plugins[i].Version
+		if !p.IsInstalledVersionAvailable() {
+			unavailable[id] = true
+		}
+	}
+	return unavailable
+}
+
+// GetPluginsBinarySize returns the size in bytes of the binary of the installed version of the
+// specified plugins for the current platform, indexed by catalog.PluginNameTarget().
+// Plugins whose size is not recorded by the plugin inventories are not reported.
+func GetPluginsBinarySize(plugins []cli.PluginInfo) map[string]int64 {
+	sizes := make(map[string]int64)
+	if len(plugins) == 0 {
+		return sizes
+	}
+
+	discoveredByID, err := getCachedStandalonePluginsByID()
+	if err != nil {
+		log.V(4).Warningf("unable to get the size of the plugins: %v", err)
+		return sizes
+	}
+
+	for i := range plugins {
+		id := catalog.PluginNameTarget(plugins[i].Name, plugins[i].Target)
+		p, found := discoveredByID[id]
+		if !found {
+			continue
+		}
+		if size := p.GetBinarySize(plugins[i].Version, cli.GOOS, cli.GOARCH); size > 0 {
+			sizes[id] = size
+		}
 	}
+	return sizes
 }
 
 // DescribePlugin describes a plugin.
@@ -448,64 +1032,405 @@ func DescribePlugin(pluginName string, target configtypes.Target) (info *cli.Plu
 	return nil, errors.Errorf(missingTargetStr, pluginName)
 }
 
-// InitializePlugin initializes the plugin configuration
-func InitializePlugin(plugin *cli.PluginInfo) error {
-	if plugin == nil {
-		return fmt.Errorf("could not get plugin information")
+// DescribePluginForAllTargets describes a plugin for every target it is installed for.
+// The descriptions are sorted by target.
+func DescribePluginForAllTargets(pluginName string) ([]cli.PluginInfo, error) {
+	plugins, err := pluginsupplier.GetInstalledPlugins()
+	if err != nil {
+		return nil, err
 	}
-
-	b, err := execCommand(plugin.InstallationPath, "post-install").CombinedOutput()
-
-	// Note: If user is installing old version of plugin than it is possible that
-	// the plugin does not implement post-install command. Ignoring the
-	// errors if the command does not exist for a particular plugin.
-	if err != nil && !strings.Contains(string(b), "unknown command") {
-		log.Warningf("Warning: Failed to initialize plugin '%q' after installation. %v", plugin.Name, string(b))
+	var matchedPlugins []cli.PluginInfo
+	for i := range plugins {
+		if plugins[i].Name == pluginName {
+			matchedPlugins = append(matchedPlugins, plugins[i])
+		}
+	}
+	if len(matchedPlugins) == 0 {
+		return nil, errors.Errorf("unable to find plugin '%v'", pluginName)
 	}
 
-	return nil
+	sort.Slice(matchedPlugins, func(i, j int) bool {
+		return matchedPlugins[i].Target < matchedPlugins[j].Target
+	})
+	return matchedPlugins, nil
 }
 
-// InstallStandalonePlugin installs a plugin by name, version and target as a standalone plugin.
-func InstallStandalonePlugin(pluginName, version string, target configtypes.Target) error {
-	return installPlugin(pluginName, version, target, "")
+// PluginArtifactInfo describes the binary of a plugin version for a specific platform
+type PluginArtifactInfo struct {
+	OS     string `json:"os" yaml:"os"`
+	Arch   string `json:"arch" yaml:"arch"`
+	Digest string `json:"digest" yaml:"digest"`
+	// Size is the size of the binary in bytes, zero when the discovery does not provide it
+	Size int64 `json:"size,omitempty" yaml:"size,omitempty"`
 }
 
-// InstallPluginFromContext installs a plugin by name, version and target as a context-scope plugin.
-func InstallPluginFromContext(pluginName, version string, target configtypes.Target, contextName string) error {
-	if contextName == "" {
-		log.Warningf("Missing context name for a context-scope plugin: %s/%s/%s", pluginName, version, string(target))
-	}
-	return installPlugin(pluginName, version, target, contextName)
+// PluginVersionInfo describes a version of a plugin available from the discovery sources
+type PluginVersionInfo struct {
+	Version   string               `json:"version" yaml:"version"`
+	Artifacts []PluginArtifactInfo `json:"artifacts" yaml:"artifacts"`
 }
 
-// installs a plugin by name, version and target.
-// If the contextName is not empty, it implies the plugin is a context-scope plugin, otherwise
-// we are installing a standalone plugin.
-//
-//nolint:gocyclo
-func installPlugin(pluginName, version string, target configtypes.Target, contextName string) error {
-	discoveries, err := getPluginDiscoveries()
-	if err != nil {
-		return err
-	}
-	if len(discoveries) == 0 {
-		return errors.New(errorNoDiscoverySourcesFound)
+// Platforms returns the list of platforms supported by the version in the "os/arch" format
+func (v *PluginVersionInfo) Platforms() []string {
+	platforms := make([]string, 0, len(v.Artifacts))
+	for _, a := range v.Artifacts {
+		platforms = append(platforms, fmt.Sprintf("%s/%s", a.OS, a.Arch))
 	}
+	return platforms
+}
+
+// DescribePluginVersions returns all the versions of a plugin available from the
+// discovery sources along with the platforms each version supports.
+// The versions are sorted in ascending order.
+func DescribePluginVersions(pluginName string, target configtypes.Target) ([]PluginVersionInfo, error) {
 	criteria := &discovery.PluginDiscoveryCriteria{
-		Name:    pluginName,
-		Target:  target,
-		Version: version,
-		OS:      cli.GOOS,
-		Arch:    cli.GOARCH,
+		Name:   pluginName,
+		Target: target,
 	}
 	errorList := make([]error, 0)
-	availablePlugins, err := discoverSpecificPlugins(discoveries, discovery.WithPluginDiscoveryCriteria(criteria))
+	plugins, err := DiscoverStandalonePlugins(discovery.WithPluginDiscoveryCriteria(criteria))
 	if err != nil {
 		errorList = append(errorList, err)
 	}
 
-	// If we cannot find the plugin for DarwinARM64, let's fallback to DarwinAMD64.
+	for i := range plugins {
+		if plugins[i].Name == pluginName &&
+			(target == configtypes.TargetUnknown || target == plugins[i].Target) {
+			return getPluginVersionsInfo(&plugins[i]), nil
+		}
+	}
+
+	if target != configtypes.TargetUnknown {
+		errorList = append(errorList, errors.Errorf("unable to find plugin '%v' for target '%s' in the discovery sources", pluginName, string(target)))
+	} else {
+		errorList = append(errorList, errors.Errorf("unable to find plugin '%v' in the discovery sources", pluginName))
+	}
+	return nil, kerrors.NewAggregate(errorList)
+}
+
+// PluginVersionDescription describes a specific version of a plugin available
+// from the discovery sources, whether or not that version is installed
+type PluginVersionDescription struct {
+	Name        string             `json:"name" yaml:"name"`
+	Version     string             `json:"version" yaml:"version"`
+	Status      string             `json:"status" yaml:"status"`
+	Target      configtypes.Target `json:"target" yaml:"target"`
+	Description string             `json:"description" yaml:"description"`
+	Vendor      string             `json:"vendor" yaml:"vendor"`
+	Publisher   string             `json:"publisher" yaml:"publisher"`
+	// The URLs of the web pages about the plugin are omitted when empty
+	PluginLinks `json:",inline" yaml:",inline"`
+	Artifacts   []PluginArtifactInfo `json:"artifacts" yaml:"artifacts"`
+}
+
+// DescribeAvailablePluginVersion describes the specified version of a plugin
+// as found in the discovery sources, even if the plugin is not installed.
+// If the version is not available, the returned error lists the available versions.
+func DescribeAvailablePluginVersion(pluginName string, target configtypes.Target, version string) (*PluginVersionDescription, error) {
+	p, err := findAvailablePlugin(pluginName, target)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range getPluginVersionsInfo(p) {
+		if v.Version != version {
+			continue
+		}
+		status := common.PluginStatusNotInstalled
+		if pluginsupplier.IsStandalonePluginInstalled(p.Name, p.Target, version) {
+			status = common.PluginStatusInstalled
+		}
+		return &PluginVersionDescription{
+			Name:        p.Name,
+			Version:     version,
+			Status:      status,
+			Target:      p.Target,
+			Description: p.Description,
+			Vendor:      p.Vendor,
+			Publisher:   p.Publisher,
+			PluginLinks: getPluginLinks(p),
+			Artifacts:   v.Artifacts,
+		}, nil
+	}
+	return nil, errors.Errorf("unable to find version '%v' of plugin '%v', the available versions are: %s", version, pluginName, strings.Join(p.SupportedVersions, ", "))
+}
+
+// findAvailablePlugin returns the plugin of the specified name and target
+// available from the discovery sources.  Without a target, the plugin must
+// only be available for a single target.
+func findAvailablePlugin(pluginName string, target configtypes.Target) (*discovery.Discovered, error) {
+	criteria := &discovery.PluginDiscoveryCriteria{
+		Name:   pluginName,
+		Target: target,
+	}
+	errorList := make([]error, 0)
+	plugins, err := DiscoverStandalonePlugins(discovery.WithPluginDiscoveryCriteria(criteria))
+	if err != nil {
+		errorList = append(errorList, err)
+	}
+
+	var matchedPlugins []*discovery.Discovered
+	for i := range plugins {
+		if plugins[i].Name == pluginName &&
+			(target == configtypes.TargetUnknown || target == plugins[i].Target) {
+			matchedPlugins = append(matchedPlugins, &plugins[i])
+		}
+	}
+	if len(matchedPlugins) == 0 {
+		if target != configtypes.TargetUnknown {
+			errorList = append(errorList, errors.Errorf("unable to find plugin '%v' for target '%s' in the discovery sources", pluginName, string(target)))
+		} else {
+			errorList = append(errorList, errors.Errorf("unable to find plugin '%v' in the discovery sources", pluginName))
+		}
+		return nil, kerrors.NewAggregate(errorList)
+	}
+	if len(matchedPlugins) > 1 {
+		return nil, errors.Errorf(missingTargetStr, pluginName)
+	}
+	return matchedPlugins[0], nil
+}
+
+func getPluginVersionsInfo(p *discovery.Discovered) []PluginVersionInfo {
+	artifacts, ok := p.Distribution.(distribution.Artifacts)
+
+	versions := make([]PluginVersionInfo, 0, len(p.SupportedVersions))
+	for _, version := range p.SupportedVersions {
+		versionInfo := PluginVersionInfo{Version: version}
+		if ok {
+			for _, a := range artifacts[version] {
+				versionInfo.Artifacts = append(versionInfo.Artifacts, PluginArtifactInfo{OS: a.OS, Arch: a.Arch, Digest: a.Digest, Size: a.Size})
+			}
+		} else if p.Distribution != nil {
+			// Only the artifact of the current platform can be looked up
+			if a, err := p.Distribution.DescribeArtifact(version, cli.GOOS, cli.GOARCH); err == nil {
+				versionInfo.Artifacts = append(versionInfo.Artifacts, PluginArtifactInfo{OS: a.OS, Arch: a.Arch, Digest: a.Digest, Size: a.Size})
+			}
+		}
+		sort.Slice(versionInfo.Artifacts, func(i, j int) bool {
+			if versionInfo.Artifacts[i].OS != versionInfo.Artifacts[j].OS {
+				return versionInfo.Artifacts[i].OS < versionInfo.Artifacts[j].OS
+			}
+			return versionInfo.Artifacts[i].Arch < versionInfo.Artifacts[j].Arch
+		})
+		versions = append(versions, versionInfo)
+	}
+	return versions
+}
+
+// InitializePlugin initializes the plugin configuration by running the post-install
+// command of the plugin.  The output of the command is only shown if it fails; a failure
+// is reported as a warning and does not undo the installation of the plugin.
+func InitializePlugin(plugin *cli.PluginInfo) error {
+	if plugin == nil {
+		return fmt.Errorf("could not get plugin information")
+	}
+
+	output, err := runPostInstallHook(plugin)
+
+	// Note: If user is installing old version of plugin than it is possible that
+	// the plugin does not implement post-install command. Ignoring the
+	// errors if the command does not exist for a particular plugin.
+	if err != nil && !strings.Contains(output, "unknown command") {
+		log.Warningf("Warning: Failed to initialize plugin '%q' after installation. %v", plugin.Name, err)
+		if output != "" {
+			log.Warningf("Output of the post-install command of plugin '%q':\n%s", plugin.Name, output)
+		}
+	}
+
+	return nil
+}
+
+// postInstallEnvironmentVariables are the environment variables of the CLI passed to the
+// post-install command of the plugins, besides the TANZU_ variables locating the configuration
+var postInstallEnvironmentVariables = []string{
+	"PATH", "HOME", "USER", "TMPDIR", "LANG", "LC_ALL", "KUBECONFIG",
+	"XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_CACHE_HOME",
+	// Required to run a program on Windows
+	"SYSTEMROOT", "USERPROFILE", "APPDATA", "LOCALAPPDATA", "TEMP", "TMP",
+}
+
+// postInstallEnvironment returns the minimal environment in which the post-install
+// command of the plugins runs.  It is enough to locate the home directory, the CLI
+// configuration and the kubeconfig; the credentials of the discovery sources and the
+// other variables of the CLI environment are not passed to the plugins.
+func postInstallEnvironment() []string {
+	var env []string
+	for _, e := range os.Environ() {
+		name, _, _ := strings.Cut(e, "=")
+		switch {
+		case strings.HasPrefix(name, constants.ConfigVariablePluginDiscoveryPasswordPrefix),
+			strings.HasPrefix(name, constants.ConfigVariablePluginDiscoveryTokenPrefix):
+			continue
+		case strings.HasPrefix(name, "TANZU_"):
+			env = append(env, e)
+			continue
+		}
+		for _, allowed := range postInstallEnvironmentVariables {
+			if strings.EqualFold(name, allowed) {
+				env = append(env, e)
+				break
+			}
+		}
+	}
+	return env
+}
+
+// runPostInstallHook runs the post-install command of a plugin and returns its combined
+// output.  The command is sandboxed as follows: it runs in the installation directory of
+// the plugin with the minimal environment of postInstallEnvironment(), it cannot read
+// from the terminal, and it is stopped if it does not complete within
+// pluginPostInstallTimeout or if the CLI is interrupted.  It still runs with the
+// privileges of the user and can access the file system and the network.
+func runPostInstallHook(plugin *cli.PluginInfo) (string, error) {
+	cmd := execCommand(plugin.InstallationPath, "post-install")
+	cmd.Dir = filepath.Dir(plugin.InstallationPath)
+	cmd.Env = append(cmd.Env, postInstallEnvironment()...)
+	// Stdin is left unset so that the command reads from the null device, not the terminal
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Start(); err != nil {
+		return "", errors.Wrapf(err, "could not run the post-install command of plugin %q", plugin.Name)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		return output.String(), err
+	case <-time.After(pluginPostInstallTimeout):
+		_ = cmd.Process.Kill()
+		<-done
+		return output.String(), errors.Errorf("the post-install command of plugin %q did not complete within %s", plugin.Name, pluginPostInstallTimeout)
+	case <-interrupt.Context().Done():
+		_ = cmd.Process.Kill()
+		<-done
+		return output.String(), interrupt.ErrInterrupted
+	}
+}
+
+// InstallResult describes the outcome of the installation of a plugin
+type InstallResult struct {
+	Name             string             `json:"name" yaml:"name"`
+	Target           configtypes.Target `json:"target" yaml:"target"`
+	Version          string             `json:"version" yaml:"version"`
+	Digest           string             `json:"digest" yaml:"digest"`
+	Path             string             `json:"path" yaml:"path"`
+	AlreadyInstalled bool               `json:"alreadyInstalled" yaml:"alreadyInstalled"`
+	Duration         time.Duration      `json:"duration" yaml:"duration"`
+}
+
+// InstallStandalonePlugin installs a plugin by name, version and target as a standalone plugin.
+// Unless WithIncludePrerelease() is used, pre-release versions are not considered when
+// looking for the latest version of the plugin.
+func InstallStandalonePlugin(pluginName, version string, target configtypes.Target, options ...PluginManagerOptions) error {
+	_, err := InstallStandalonePluginWithResult(pluginName, version, target, options...)
+	return err
+}
+
+// InstallStandalonePluginWithResult installs a plugin by name, version and target as a
+// standalone plugin and returns the result of the installation of that plugin.
+func InstallStandalonePluginWithResult(pluginName, version string, target configtypes.Target, options ...PluginManagerOptions) (*InstallResult, error) {
+	start := time.Now()
+	result, err := installPlugin(pluginName, version, target, "", options...)
+	if err != nil {
+		return nil, err
+	}
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// InstallPluginFromContext installs a plugin by name, version and target as a context-scope plugin.
+func InstallPluginFromContext(pluginName, version string, target configtypes.Target, contextName string) error {
+	if contextName == "" {
+		log.Warningf("Missing context name for a context-scope plugin: %s/%s/%s", pluginName, version, string(target))
+	}
+	_, err := installPlugin(pluginName, version, target, contextName)
+	return err
+}
+
+// installs a plugin by name, version and target, along with any plugin it depends on.
+// If the contextName is not empty, it implies the plugin is a context-scope plugin, otherwise
+// we are installing a standalone plugin.
+func installPlugin(pluginName, version string, target configtypes.Target, contextName string, options ...PluginManagerOptions) (*InstallResult, error) {
+	opts := NewPluginManagerOpts(options...)
+	installDir := ""
+	if opts.installDir != "" {
+		var err error
+		if installDir, err = validateInstallDir(opts.installDir); err != nil {
+			return nil, err
+		}
+	}
+
+	criteriaVersion := version
+	digest := ""
+	if opts.digest != "" {
+		var err error
+		if digest, err = normalizeDigest(opts.digest); err != nil {
+			return nil, err
+		}
+		// All the versions of the plugin are looked up for the artifact with the digest
+		criteriaVersion = ""
+	}
+
+	var result *InstallResult
+	err := selectPluginForInstallation(pluginName, criteriaVersion, target, contextName, func(p *discovery.Discovered) error {
+		var err error
+		pluginVersion := p.RecommendedVersion
+		if digest != "" {
+			if pluginVersion, err = findPluginVersionByDigest(p, digest); err != nil {
+				return err
+			}
+		}
+		result, err = installPluginWithDependencies(p, pluginVersion, opts.reinstall, opts.skipPostInstall, opts.force, installDir)
+		if err != nil {
+			return err
+		}
+		if digest != "" {
+			return verifyInstalledPluginDigest(result.Path, digest)
+		}
+		return nil
+	}, options...)
+	if err != nil {
+		// An interrupted installation is not a failure of the plugin
+		if !interrupt.Interrupted() {
+			recordPluginInstallStatus(pluginName, target, version, err)
+		}
+		return nil, err
+	}
+	recordPluginInstallStatus(result.Name, result.Target, result.Version, nil)
+	return result, nil
+}
+
+// selectPluginForInstallation discovers the plugin matching the name, version and target
+// and calls 'action' on it.  The action is called before any temporary change to the
+// platform (see the DarwinARM64 fallback) is reverted.
+//
+//nolint:gocyclo
+func selectPluginForInstallation(pluginName, version string, target configtypes.Target, contextName string, action func(p *discovery.Discovered) error, options ...PluginManagerOptions) error {
+	opts := NewPluginManagerOpts(options...)
+	discoveries, err := getPluginDiscoveries()
+	if err != nil {
+		return err
+	}
+	if len(discoveries) == 0 {
+		return errors.New(errorNoDiscoverySourcesFound)
+	}
+	criteria := &discovery.PluginDiscoveryCriteria{
+		Name:    pluginName,
+		Target:  target,
+		Version: version,
+		OS:      cli.GOOS,
+		Arch:    cli.GOARCH,
+	}
+	errorList := make([]error, 0)
+	availablePlugins, err := discoverSpecificPlugins(discoveries, discovery.WithPluginDiscoveryCriteria(criteria))
+	if err != nil {
+		errorList = append(errorList, err)
+	}
+
+	// If we cannot find the plugin for DarwinARM64, let's fallback to DarwinAMD64.
 	// This leverages Apples Rosetta emulator and helps until plugins are all available
 	// for ARM64.  Note that this cannot be used on Linux since there is no such emulator.
 	if len(availablePlugins) == 0 && cli.BuildArch() == cli.DarwinARM64 {
@@ -540,6 +1465,10 @@ func installPlugin(pluginName, version string, target configtypes.Target, contex
 			if contextName != "" {
 				availablePlugins[i].ContextName = contextName
 			}
+			// The recommended version excludes pre-release versions by default
+			if opts.includePrerelease {
+				availablePlugins[i].RecommendedVersion = getRecommendedVersionIncludingPrerelease(&availablePlugins[i])
+			}
 			matchedPlugins = append(matchedPlugins, availablePlugins[i])
 		}
 	}
@@ -553,86 +1482,250 @@ func installPlugin(pluginName, version string, target configtypes.Target, contex
 	}
 
 	if len(matchedPlugins) == 1 {
-		return installOrUpgradePlugin(&matchedPlugins[0], matchedPlugins[0].RecommendedVersion, false)
+		return action(&matchedPlugins[0])
 	}
 
 	for i := range matchedPlugins {
 		if matchedPlugins[i].Target == target {
-			return installOrUpgradePlugin(&matchedPlugins[i], matchedPlugins[i].RecommendedVersion, false)
+			return action(&matchedPlugins[i])
 		}
 	}
 	errorList = append(errorList, errors.Errorf(missingTargetStr, pluginName))
 	return kerrors.NewAggregate(errorList)
 }
 
+// getRecommendedVersionIncludingPrerelease returns the version of the plugin to install when
+// pre-release versions are allowed.  The recommended version of the inventory is kept unless
+// a pre-release version newer than it is available.
+func getRecommendedVersionIncludingPrerelease(p *discovery.Discovered) string {
+	latest := utils.GetLatestVersion(p.SupportedVersions, true)
+	if !utils.IsPreRelease(latest) {
+		return p.RecommendedVersion
+	}
+	if p.RecommendedVersion == "" || utils.IsNewVersion(latest, p.RecommendedVersion) {
+		return latest
+	}
+	return p.RecommendedVersion
+}
+
 // UpgradePlugin upgrades a plugin from the given repository.
-func UpgradePlugin(pluginName, version string, target configtypes.Target) error {
+func UpgradePlugin(pluginName, version string, target configtypes.Target, options ...PluginManagerOptions) error {
 	// Upgrade is only triggered from a manual user operation.
 	// This means a plugin is installed manually, which means it is installed as a standalone plugin.
-	return InstallStandalonePlugin(pluginName, version, target)
+	return InstallStandalonePlugin(pluginName, version, target, options...)
+}
+
+// The actions of the entries of an upgrade plan
+const (
+	// UpgradeActionUpgrade means a newer version of the plugin would be installed
+	UpgradeActionUpgrade = "upgrade"
+	// UpgradeActionNoChange means the plugin is already at the version that would be installed
+	UpgradeActionNoChange = "no change"
+	// UpgradeActionDowngrade means the installed version is newer than the version that would be installed
+	UpgradeActionDowngrade = "downgrade"
+	// UpgradeActionInstall means the plugin is not installed and would be installed
+	UpgradeActionInstall = "install"
+)
+
+// UpgradePlanEntry describes what upgrading a plugin would do
+type UpgradePlanEntry struct {
+	Name   string             `json:"name" yaml:"name"`
+	Target configtypes.Target `json:"target" yaml:"target"`
+	// InstalledVersion is the version of the plugin currently installed; it is empty if there is none
+	InstalledVersion string `json:"installedVersion,omitempty" yaml:"installedVersion,omitempty"`
+	// Version is the version the plugin would be upgraded to
+	Version string `json:"version" yaml:"version"`
+	Action  string `json:"action" yaml:"action"`
+}
+
+// PlanPluginUpgrade computes what UpgradePlugin would do for the specified plugin
+// without installing anything, by comparing the installed version of the plugin
+// with the recommended version found in the discovery sources.
+func PlanPluginUpgrade(pluginName string, target configtypes.Target, options ...PluginManagerOptions) (*UpgradePlanEntry, error) {
+	installedPlugins, err := pluginsupplier.GetInstalledPlugins()
+	if err != nil {
+		return nil, err
+	}
+
+	var entry *UpgradePlanEntry
+	err = selectPluginForInstallation(pluginName, cli.VersionLatest, target, "", func(p *discovery.Discovered) error {
+		entry = &UpgradePlanEntry{
+			Name:    p.Name,
+			Target:  p.Target,
+			Version: p.RecommendedVersion,
+			Action:  UpgradeActionInstall,
+		}
+		for i := range installedPlugins {
+			if installedPlugins[i].Name == p.Name && installedPlugins[i].Target == p.Target {
+				entry.InstalledVersion = installedPlugins[i].Version
+				break
+			}
+		}
+		switch {
+		case entry.InstalledVersion == "":
+			// The plugin is not installed yet
+		case entry.InstalledVersion == entry.Version:
+			entry.Action = UpgradeActionNoChange
+		case utils.IsNewVersion(entry.Version, entry.InstalledVersion):
+			entry.Action = UpgradeActionUpgrade
+		default:
+			entry.Action = UpgradeActionDowngrade
+		}
+		return nil
+	}, options...)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// PlanAllPluginsUpgrade computes what upgrading every installed standalone plugin would do,
+// optionally restricted to the plugins of a target.  The plugins which cannot be found in the
+// discovery sources are left out of the plan and reported in the returned error.
+func PlanAllPluginsUpgrade(target configtypes.Target, options ...PluginManagerOptions) ([]*UpgradePlanEntry, error) {
+	plugins, err := pluginsupplier.GetInstalledStandalonePlugins()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := make([]*UpgradePlanEntry, 0, len(plugins))
+	errorList := make([]error, 0)
+	for i := range plugins {
+		if target != configtypes.TargetUnknown && plugins[i].Target != target {
+			continue
+		}
+		entry, err := PlanPluginUpgrade(plugins[i].Name, plugins[i].Target, options...)
+		if err != nil {
+			errorList = append(errorList, err)
+			continue
+		}
+		plan = append(plan, entry)
+	}
+	return plan, kerrors.NewAggregate(errorList)
 }
 
 // InstallPluginsFromGroup installs either the specified plugin or all plugins from the specified group version.
 // If the group version is not specified, the latest available version will be used.
 // The group identifier including the version used is returned.
 func InstallPluginsFromGroup(pluginName, groupIDAndVersion string, options ...PluginManagerOptions) (string, error) {
+	groupWithVersion, _, err := InstallPluginsFromGroupWithResults(pluginName, groupIDAndVersion, options...)
+	return groupWithVersion, err
+}
+
+// InstallPluginsFromGroupWithResults installs either the specified plugin or all plugins from the
+// specified group version like InstallPluginsFromGroup and also returns the result of the
+// installation of each plugin.
+func InstallPluginsFromGroupWithResults(pluginName, groupIDAndVersion string, options ...PluginManagerOptions) (string, []*InstallResult, error) {
 	// get plugins from the specific plugin group
 	pg, err := GetPluginGroup(groupIDAndVersion, options...)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	// It is possible that user has provided plugin group version in form of vMAJOR or vMAJOR.MINOR
 	// So always update the groupIdentifier and groupIDAndVersion to the recommendedVersion we got
 	// from the database
 	groupIDAndVersion = fmt.Sprintf("%s-%s/%s:%s", pg.Vendor, pg.Publisher, pg.Name, pg.RecommendedVersion)
-	log.Infof("Installing plugins from plugin group '%s'", groupIDAndVersion)
+	clilog.Infof("Installing plugins from plugin group '%s'", groupIDAndVersion)
 
-	return InstallPluginsFromGivenPluginGroup(pluginName, groupIDAndVersion, pg)
+	return InstallPluginsFromGivenPluginGroupWithResults(pluginName, groupIDAndVersion, pg, options...)
 }
 
 // InstallPluginsFromGivenPluginGroup installs either the specified plugin or all plugins from given plugin group plugins.
-func InstallPluginsFromGivenPluginGroup(pluginName, groupIDAndVersion string, pg *plugininventory.PluginGroup) (string, error) {
+func InstallPluginsFromGivenPluginGroup(pluginName, groupIDAndVersion string, pg *plugininventory.PluginGroup, options ...PluginManagerOptions) (string, error) {
+	groupWithVersion, _, err := InstallPluginsFromGivenPluginGroupWithResults(pluginName, groupIDAndVersion, pg, options...)
+	return groupWithVersion, err
+}
+
+// InstallPluginsFromGivenPluginGroupWithResults installs either the specified plugin or all plugins
+// from given plugin group plugins and returns the result of the installation of each plugin.
+// The results of the plugins successfully installed are returned even if the installation of
+// other plugins failed.  Only the WithSkipPostInstall option applies to the installation
+// of each plugin, and the WithGroupMemberFilter option selects the members installed
+// when installing all the plugins of the group.
+func InstallPluginsFromGivenPluginGroupWithResults(pluginName, groupIDAndVersion string, pg *plugininventory.PluginGroup, options ...PluginManagerOptions) (string, []*InstallResult, error) {
+	opts := NewPluginManagerOpts(options...)
+	filtered := len(opts.groupOnly) > 0 || len(opts.groupExclude) > 0
+	if filtered {
+		if pluginName != cli.AllPlugins {
+			return groupIDAndVersion, nil, fmt.Errorf("the members of group '%s' can only be filtered when installing all its plugins", groupIDAndVersion)
+		}
+		if err := validateGroupMemberFilter(pg, groupIDAndVersion, opts); err != nil {
+			return groupIDAndVersion, nil, err
+		}
+	}
+
+	var results []*InstallResult
 	numErrors := 0
-	numInstalled := 0
 	mandatoryPluginsExist := false
 	pluginExist := false
 	for _, plugin := range pg.Versions[pg.RecommendedVersion] {
+		if filtered && !isGroupMemberSelected(plugin.Name, opts) {
+			clilog.Infof("Skipping plugin '%s' of group '%s'", plugin.Name, groupIDAndVersion)
+			continue
+		}
 		if pluginName == cli.AllPlugins || pluginName == plugin.Name {
 			pluginExist = true
 			if plugin.Mandatory {
 				mandatoryPluginsExist = true
-				err := InstallStandalonePlugin(plugin.Name, plugin.Version, plugin.Target)
+				result, err := InstallStandalonePluginWithResult(plugin.Name, plugin.Version, plugin.Target, WithSkipPostInstall(opts.skipPostInstall), WithForce(opts.force))
 				if err != nil {
 					numErrors++
 					log.Warningf("unable to install plugin '%s': %v", plugin.Name, err.Error())
 				} else {
-					numInstalled++
+					results = append(results, result)
 				}
 			}
 		}
 	}
 
 	if !pluginExist {
-		return groupIDAndVersion, fmt.Errorf("plugin '%s' is not part of the group '%s'", pluginName, groupIDAndVersion)
+		return groupIDAndVersion, results, fmt.Errorf("plugin '%s' is not part of the group '%s'", pluginName, groupIDAndVersion)
 	}
 
 	if !mandatoryPluginsExist {
+		if filtered {
+			return groupIDAndVersion, results, fmt.Errorf("none of the selected plugins of group '%s' are mandatory to install", groupIDAndVersion)
+		}
 		if pluginName == cli.AllPlugins {
-			return groupIDAndVersion, fmt.Errorf("plugin group '%s' has no mandatory plugins to install", groupIDAndVersion)
+			return groupIDAndVersion, results, fmt.Errorf("plugin group '%s' has no mandatory plugins to install", groupIDAndVersion)
 		}
-		return groupIDAndVersion, fmt.Errorf("plugin '%s' from group '%s' is not mandatory to install", pluginName, groupIDAndVersion)
+		return groupIDAndVersion, results, fmt.Errorf("plugin '%s' from group '%s' is not mandatory to install", pluginName, groupIDAndVersion)
 	}
 
 	if numErrors > 0 {
-		return groupIDAndVersion, fmt.Errorf("could not install %d plugin(s) from group '%s'", numErrors, groupIDAndVersion)
+		return groupIDAndVersion, results, fmt.Errorf("could not install %d plugin(s) from group '%s'", numErrors, groupIDAndVersion)
+	}
+
+	if len(results) == 0 {
+		return groupIDAndVersion, results, fmt.Errorf("plugin '%s' is not part of the group '%s'", pluginName, groupIDAndVersion)
 	}
 
-	if numInstalled == 0 {
-		return groupIDAndVersion, fmt.Errorf("plugin '%s' is not part of the group '%s'", pluginName, groupIDAndVersion)
+	return groupIDAndVersion, results, nil
+}
+
+// validateGroupMemberFilter checks that the plugins named by the WithGroupMemberFilter
+// option are members of the recommended version of the plugin group
+func validateGroupMemberFilter(pg *plugininventory.PluginGroup, groupIDAndVersion string, opts *PluginManagerOpts) error {
+	members := make(map[string]bool)
+	for _, plugin := range pg.Versions[pg.RecommendedVersion] {
+		members[plugin.Name] = true
+	}
+	var errorList []error
+	for _, name := range append(append([]string{}, opts.groupOnly...), opts.groupExclude...) {
+		if !members[name] {
+			errorList = append(errorList, fmt.Errorf("plugin '%s' is not part of the group '%s'", name, groupIDAndVersion))
+		}
 	}
+	return kerrors.NewAggregate(errorList)
+}
 
-	return groupIDAndVersion, nil
+// isGroupMemberSelected returns whether the group member is selected by the WithGroupMemberFilter option
+func isGroupMemberSelected(name string, opts *PluginManagerOpts) bool {
+	if len(opts.groupOnly) > 0 && !utils.ContainsString(opts.groupOnly, name) {
+		return false
+	}
+	return !utils.ContainsString(opts.groupExclude, name)
 }
 
 // GetPluginGroup returns the plugin group for the specified groupIDAndVersion.
@@ -688,6 +1781,100 @@ func GetPluginGroup(groupIDAndVersion string, options ...PluginManagerOptions) (
 	return pg, nil
 }
 
+// GetPluginVersionFromGroup returns the version and the target of the specified plugin
+// as pinned by the specified plugin group version.  The version may be partial,
+// e.g., vMAJOR.MINOR, as specified by the group.  The target must be specified if the
+// group contains the plugin for more than one target.
+func GetPluginVersionFromGroup(pluginName string, target configtypes.Target, groupIDAndVersion string, options ...PluginManagerOptions) (string, configtypes.Target, error) {
+	pg, err := GetPluginGroup(groupIDAndVersion, options...)
+	if err != nil {
+		return "", target, err
+	}
+	groupWithVersion := fmt.Sprintf("%s-%s/%s:%s", pg.Vendor, pg.Publisher, pg.Name, pg.RecommendedVersion)
+
+	var members []*plugininventory.PluginGroupPluginEntry
+	for _, plugin := range pg.Versions[pg.RecommendedVersion] {
+		if plugin.Name == pluginName && (target == configtypes.TargetUnknown || plugin.Target == target) {
+			members = append(members, plugin)
+		}
+	}
+	switch {
+	case len(members) == 0 && target != configtypes.TargetUnknown:
+		return "", target, fmt.Errorf("plugin '%s' for target '%s' is not part of the group '%s'", pluginName, string(target), groupWithVersion)
+	case len(members) == 0:
+		return "", target, fmt.Errorf("plugin '%s' is not part of the group '%s'", pluginName, groupWithVersion)
+	case len(members) > 1:
+		return "", target, fmt.Errorf(missingTargetStr, pluginName)
+	}
+	return members[0].Version, members[0].Target, nil
+}
+
+// The changes of a plugin between two versions of a plugin group
+const (
+	PluginGroupDiffAdded   = "added"
+	PluginGroupDiffRemoved = "removed"
+	PluginGroupDiffChanged = "changed"
+)
+
+// PluginGroupDiffEntry describes how a plugin of a plugin group differs between two versions of the group
+type PluginGroupDiffEntry struct {
+	Change      string             `json:"change" yaml:"change"`
+	Name        string             `json:"name" yaml:"name"`
+	Target      configtypes.Target `json:"target" yaml:"target"`
+	FromVersion string             `json:"fromVersion" yaml:"fromVersion"`
+	ToVersion   string             `json:"toVersion" yaml:"toVersion"`
+}
+
+// DiffPluginGroupVersions returns the plugins that were added, removed or whose version
+// changed between the two specified versions of a plugin group.  The groupID must not
+// include a version.  The entries are sorted by change and then by plugin.
+func DiffPluginGroupVersions(groupID, fromVersion, toVersion string, options ...PluginManagerOptions) ([]*PluginGroupDiffEntry, error) {
+	groupIdentifier := plugininventory.PluginGroupIdentifierFromID(groupID)
+	if groupIdentifier == nil || groupIdentifier.Version != "" {
+		return nil, errors.Errorf("incorrect plugin-group %q specified, the versions to compare must be specified separately", groupID)
+	}
+
+	var members [2]map[string]*plugininventory.PluginGroupPluginEntry
+	for i, version := range []string{fromVersion, toVersion} {
+		pg, err := GetPluginGroup(groupID+":"+version, options...)
+		if err != nil {
+			return nil, err
+		}
+		members[i] = make(map[string]*plugininventory.PluginGroupPluginEntry)
+		for _, plugin := range pg.Versions[pg.RecommendedVersion] {
+			members[i][catalog.PluginNameTarget(plugin.Name, plugin.Target)] = plugin
+		}
+	}
+
+	var diff []*PluginGroupDiffEntry
+	for id, from := range members[0] {
+		to, found := members[1][id]
+		switch {
+		case !found:
+			diff = append(diff, &PluginGroupDiffEntry{Change: PluginGroupDiffRemoved, Name: from.Name, Target: from.Target, FromVersion: from.Version})
+		case to.Version != from.Version:
+			diff = append(diff, &PluginGroupDiffEntry{Change: PluginGroupDiffChanged, Name: from.Name, Target: from.Target, FromVersion: from.Version, ToVersion: to.Version})
+		}
+	}
+	for id, to := range members[1] {
+		if _, found := members[0][id]; !found {
+			diff = append(diff, &PluginGroupDiffEntry{Change: PluginGroupDiffAdded, Name: to.Name, Target: to.Target, ToVersion: to.Version})
+		}
+	}
+
+	changeOrder := map[string]int{PluginGroupDiffAdded: 0, PluginGroupDiffRemoved: 1, PluginGroupDiffChanged: 2}
+	sort.Slice(diff, func(i, j int) bool {
+		if diff[i].Change != diff[j].Change {
+			return changeOrder[diff[i].Change] < changeOrder[diff[j].Change]
+		}
+		if diff[i].Name != diff[j].Name {
+			return diff[i].Name < diff[j].Name
+		}
+		return diff[i].Target < diff[j].Target
+	})
+	return diff, nil
+}
+
 func logPluginInstallationMessage(p *discovery.Discovered, version string, isPluginInCache, isPluginAlreadyInstalled bool) {
 	withTarget := ""
 	if p.Target != configtypes.TargetUnknown {
@@ -696,16 +1883,52 @@ func logPluginInstallationMessage(p *discovery.Discovered, version string, isPlu
 
 	if isPluginInCache {
 		if !isPluginAlreadyInstalled {
-			log.Infof("Installing plugin '%v:%v' %v(from cache)", p.Name, version, withTarget)
+			clilog.Infof("Installing plugin '%v:%v' %v(from cache)", p.Name, version, withTarget)
 		} else {
-			log.Infof("Plugin '%v:%v' %vis already installed. Reinitializing...", p.Name, version, withTarget)
+			clilog.Infof("Plugin '%v:%v' %vis already installed. Skipping installation...", p.Name, version, withTarget)
 		}
 	} else {
-		log.Infof("Installing plugin '%v:%v' %v", p.Name, version, withTarget)
+		clilog.Infof("Installing plugin '%v:%v' %v", p.Name, version, withTarget)
+	}
+}
+
+func logPluginDeprecationMessage(p *discovery.Discovered, version string) {
+	deprecated, message := p.GetDeprecation(version)
+	if !deprecated {
+		return
+	}
+	if message == "" {
+		log.Warningf("Plugin '%v:%v' is deprecated", p.Name, version)
+		return
+	}
+	log.Warningf("Plugin '%v:%v' is deprecated: %v", p.Name, version, message)
+}
+
+// checkCLICompatibility returns an error if the specified version of the plugin requires
+// a more recent version of the running CLI.  If force is true, a warning is logged instead.
+func checkCLICompatibility(p *discovery.Discovered, version string, force bool) error {
+	compatible, minCLIVersion := p.IsCompatibleWithCLI(version, buildinfo.Version)
+	if compatible {
+		return nil
+	}
+	if force {
+		log.Warningf("Plugin '%v:%v' requires version %v or later of the Tanzu CLI but the CLI version is %v, the plugin may not work as expected", p.Name, version, minCLIVersion, buildinfo.Version)
+		return nil
 	}
+	return errors.Errorf("plugin '%v:%v' requires version %v or later of the Tanzu CLI but the CLI version is %v, please upgrade the CLI or force the installation", p.Name, version, minCLIVersion, buildinfo.Version)
 }
 
-func installOrUpgradePlugin(p *discovery.Discovered, version string, installTestPlugin bool) error {
+// installOrUpgradePlugin installs the specified version of a plugin.  A standalone plugin
+// whose exact version is already installed for the same target is not installed again,
+// unless reinstall is true, in which case the plugin binary is also downloaded anew.
+// The post-install command of the plugin is not run if skipPostInstall is true.
+// The plugin binary is installed under installDir, or the default plugin root if empty.
+func installOrUpgradePlugin(p *discovery.Discovered, version string, installTestPlugin, reinstall, skipPostInstall bool, installDir string) (*InstallResult, error) {
+	recoverInterruptedPluginOperationsOnce()
+
+	if installDir == "" {
+		installDir = common.DefaultPluginRoot
+	}
 	// If the version requested was the RecommendedVersion, we should set it explicitly
 	if version == "" || version == cli.VersionLatest {
 		version = p.RecommendedVersion
@@ -717,7 +1940,9 @@ func installOrUpgradePlugin(p *discovery.Discovered, version string, installTest
 		// If we need to install the test plugin we know we are doing a local
 		// installation.  In that case, we don't use the cache as the binary is
 		// already local to the machine.
-		plugin = getPluginFromCache(p, version)
+		if !reinstall {
+			plugin = getPluginFromCache(p, version, installDir)
+		}
 		if p.ContextName == "" {
 			isPluginAlreadyInstalled = pluginsupplier.IsStandalonePluginInstalled(p.Name, p.Target, version)
 		}
@@ -725,28 +1950,67 @@ func installOrUpgradePlugin(p *discovery.Discovered, version string, installTest
 
 	// Log message based on different installation conditions
 	logPluginInstallationMessage(p, version, plugin != nil, isPluginAlreadyInstalled)
+	logPluginDeprecationMessage(p, version)
+
+	// The binary of an installed plugin may have been removed from the cache,
+	// in which case the plugin is installed again
+	if isPluginAlreadyInstalled && plugin != nil {
+		digest, _ := p.Distribution.GetDigest(version, cli.GOOS, cli.GOARCH)
+		return &InstallResult{
+			Name:             p.Name,
+			Target:           p.Target,
+			Version:          version,
+			Digest:           digest,
+			Path:             plugin.InstallationPath,
+			AlreadyInstalled: true,
+		}, nil
+	}
 
 	if plugin == nil {
-		binary, err := fetchAndVerifyPlugin(p, version)
+		binary, err := fetchAndVerifyPlugin(p, version, !reinstall)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		plugin, err = installAndDescribePlugin(p, version, binary)
+		// The installation is journaled until the plugin is registered in the catalog
+		// so that it can be recovered if the CLI is interrupted in between
+		journal := startJournaledOperation(journalOperationInstall, p.ContextName, &cli.PluginInfo{
+			Name:             p.Name,
+			Target:           p.Target,
+			Version:          version,
+			InstallationPath: getPluginInstallationPath(p, version, binary, installDir),
+		})
+		defer journal.complete()
+
+		plugin, err = installAndDescribePluginInDir(p, version, binary, installDir)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		journal.advance(journalStepWritten, plugin)
 	}
 	if installTestPlugin {
 		if err := doInstallTestPlugin(p, plugin.InstallationPath, version); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	return updatePluginInfoAndInitializePlugin(p, plugin)
+	if err := updatePluginInfoAndInitializePlugin(p, plugin, skipPostInstall); err != nil {
+		return nil, err
+	}
+
+	// The digest is not available for plugins installed from a local source
+	digest, _ := p.Distribution.GetDigest(version, cli.GOOS, cli.GOARCH)
+	return &InstallResult{
+		Name:             p.Name,
+		Target:           p.Target,
+		Version:          version,
+		Digest:           digest,
+		Path:             plugin.InstallationPath,
+		AlreadyInstalled: isPluginAlreadyInstalled,
+	}, nil
 }
 
-func getPluginFromCache(p *discovery.Discovered, version string) *cli.PluginInfo {
+func getPluginFromCache(p *discovery.Discovered, version, installDir string) *cli.PluginInfo {
 	pluginArtifact, err := p.Distribution.DescribeArtifact(version, cli.GOOS, cli.GOARCH)
 	if err != nil {
 		return nil
@@ -756,7 +2020,7 @@ func getPluginFromCache(p *discovery.Discovered, version string) *cli.PluginInfo
 	// as it bypasses the plugin catalog abstraction.  Instead, we should ask the plugin
 	// catalog to know if the plugin binary is present already.
 	pluginFileName := fmt.Sprintf("%s_%s_%s", version, pluginArtifact.Digest, p.Target)
-	pluginPath := filepath.Join(common.DefaultPluginRoot, p.Name, pluginFileName)
+	pluginPath := filepath.Join(installDir, p.Name, pluginFileName)
 
 	if cli.BuildArch().IsWindows() {
 		pluginPath += exe
@@ -772,80 +2036,168 @@ func getPluginFromCache(p *discovery.Discovered, version string) *cli.PluginInfo
 	return plugin
 }
 
-func fetchAndVerifyPlugin(p *discovery.Discovered, version string) ([]byte, error) {
+// fetchAndVerifyPlugin returns the verified binary of the specified version of a plugin.
+// If useArtifactCache is true, a binary with the expected digest found in the artifact
+// cache is used instead of downloading it again.  A downloaded binary is always cached.
+func fetchAndVerifyPlugin(p *discovery.Discovered, version string, useArtifactCache bool) ([]byte, error) {
 	// verify plugin before download
 	err := verifyPluginPreDownload(p, version)
 	if err != nil {
 		return nil, errors.Wrapf(err, "%q plugin pre-download verification failed", p.Name)
 	}
 
-	b, err := p.Distribution.Fetch(version, cli.GOOS, cli.GOARCH)
+	d, err := p.Distribution.GetDigest(version, cli.GOOS, cli.GOARCH)
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to fetch the plugin metadata for plugin %q", p.Name)
 	}
+	if useArtifactCache {
+		if b, found := artifact.GetCachedArtifact(d); found {
+			log.V(6).Infof("Using the cached binary of plugin %q with digest %s", p.Name, d)
+			return b, nil
+		}
+	}
 
-	// verify plugin after download but before installation
-	d, err := p.Distribution.GetDigest(version, cli.GOOS, cli.GOARCH)
+	b, err := p.Distribution.Fetch(version, cli.GOOS, cli.GOARCH)
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrapf(err, "unable to fetch the plugin metadata for plugin %q", p.Name)
 	}
+
+	// verify plugin after download but before installation
 	err = verifyPluginPostDownload(p, d, b)
 	if err != nil {
 		return nil, errors.Wrapf(err, "%q plugin post-download verification failed", p.Name)
 	}
+
+	if err := artifact.CacheArtifact(d, b); err != nil {
+		log.V(4).Warningf("Unable to cache the binary of plugin %q: %v", p.Name, err)
+	} else {
+		pruneArtifactCache()
+	}
 	return b, nil
 }
 
+// pruneArtifactCache evicts the least recently used plugin binaries from the
+// artifact cache if it exceeds its maximum size.
+func pruneArtifactCache() {
+	maxSize, _ := discovery.GetMaxArtifactCacheSize()
+	if _, err := artifact.PruneArtifactCache(maxSize); err != nil {
+		log.Warningf("Unable to prune the plugin artifact cache: %v", err)
+	}
+}
+
+// PruneArtifactCache evicts the least recently used plugin binaries from the
+// artifact cache until the cache uses at most maxSize bytes.  It returns the
+// digests of the evicted binaries.
+func PruneArtifactCache(maxSize int64) ([]string, error) {
+	return artifact.PruneArtifactCache(maxSize)
+}
+
 func installAndDescribePlugin(p *discovery.Discovered, version string, binary []byte) (*cli.PluginInfo, error) {
-	pluginFileName := fmt.Sprintf("%s_%x_%s", version, sha256.Sum256(binary), p.Target)
-	pluginPath := filepath.Join(common.DefaultPluginRoot, p.Name, pluginFileName)
+	return installAndDescribePluginInDir(p, version, binary, common.DefaultPluginRoot)
+}
 
+// installAndDescribePluginInDir writes the plugin binary under the specified
+// installation directory and describes the installed plugin
+func installAndDescribePluginInDir(p *discovery.Discovered, version string, binary []byte, installDir string) (*cli.PluginInfo, error) {
+	pluginPath := getPluginInstallationPath(p, version, binary, installDir)
 	if err := os.MkdirAll(filepath.Dir(pluginPath), os.ModePerm); err != nil {
 		return nil, err
 	}
 
-	if cli.BuildArch().IsWindows() {
-		pluginPath += exe
-	}
+	// Don't leave a partially installed plugin behind if the CLI is interrupted
+	unregisterCleanup := interrupt.RegisterCleanup(func() { _ = os.Remove(pluginPath) })
+	defer unregisterCleanup()
 
 	if err := os.WriteFile(pluginPath, binary, 0755); err != nil {
 		return nil, errors.Wrap(err, "could not write file")
 	}
 
-	return describePlugin(p, pluginPath)
+	plugin, err := describePlugin(p, pluginPath)
+	if err != nil {
+		// Don't leave a binary that cannot run in the plugin cache
+		_ = os.Remove(pluginPath)
+		return nil, err
+	}
+	return plugin, nil
 }
 
-func describePlugin(p *discovery.Discovered, pluginPath string) (*cli.PluginInfo, error) {
-	bytesInfo, err := execCommand(pluginPath, "info").Output()
-	if err != nil {
-		return nil, errors.Wrapf(err, "could not describe plugin %q", p.Name)
+// getPluginInstallationPath returns the path the binary of the plugin is written to
+// under the specified installation directory
+func getPluginInstallationPath(p *discovery.Discovered, version string, binary []byte, installDir string) string {
+	pluginFileName := fmt.Sprintf("%s_%x_%s", version, sha256.Sum256(binary), p.Target)
+	pluginPath := filepath.Join(installDir, p.Name, pluginFileName)
+	if cli.BuildArch().IsWindows() {
+		pluginPath += exe
+	}
+	return pluginPath
+}
+
+// runPluginHandshake invokes the "info" command of a plugin binary to confirm that it runs on
+// this platform and speaks the plugin protocol, and returns the description of the plugin.
+// The plugin is given at most pluginHandshakeTimeout to answer.
+func runPluginHandshake(pluginName, pluginPath string) (*cli.PluginInfo, error) {
+	cmd := execCommand(pluginPath, "info")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Start(); err != nil {
+		if errors.Is(err, syscall.ENOEXEC) {
+			return nil, errors.Errorf("plugin %q cannot run on this platform (%s): the binary was likely built for another OS or architecture", pluginName, cli.BuildArch())
+		}
+		return nil, errors.Wrapf(err, "could not run plugin %q", pluginName)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not describe plugin %q: the binary may be corrupt", pluginName)
+		}
+	case <-time.After(pluginHandshakeTimeout):
+		_ = cmd.Process.Kill()
+		<-done
+		return nil, errors.Errorf("plugin %q did not describe itself within %s: the binary may be corrupt", pluginName, pluginHandshakeTimeout)
 	}
 
 	var plugin cli.PluginInfo
-	if err = json.Unmarshal(bytesInfo, &plugin); err != nil {
-		return nil, errors.Wrapf(err, "could not unmarshal plugin %q description", p.Name)
+	if err := json.Unmarshal(stdout.Bytes(), &plugin); err != nil {
+		return nil, errors.Wrapf(err, "could not unmarshal plugin %q description: the binary may be corrupt or not be a tanzu plugin", pluginName)
+	}
+	if plugin.Name == "" || plugin.Version == "" {
+		return nil, errors.Errorf("the description of plugin %q is missing the name or version of the plugin: the binary may not be a tanzu plugin", pluginName)
+	}
+	return &plugin, nil
+}
+
+func describePlugin(p *discovery.Discovered, pluginPath string) (*cli.PluginInfo, error) {
+	plugin, err := runPluginHandshake(p.Name, pluginPath)
+	if err != nil {
+		return nil, err
 	}
 	plugin.InstallationPath = pluginPath
 	plugin.Discovery = p.Source
 	plugin.DiscoveredRecommendedVersion = p.RecommendedVersion
 	plugin.Target = p.Target
 	plugin.Scope = p.Scope
+	plugin.Vendor = p.Vendor
+	plugin.Publisher = p.Publisher
 	if plugin.Version == p.RecommendedVersion {
 		plugin.Status = common.PluginStatusInstalled
 	} else {
 		plugin.Status = common.PluginStatusUpdateAvailable
 	}
-	return &plugin, nil
+	return plugin, nil
 }
 
 func doInstallTestPlugin(p *discovery.Discovered, pluginPath, version string) error {
-	log.Infof("Installing test plugin for '%v:%v'", p.Name, version)
+	clilog.Infof("Installing test plugin for '%v:%v'", p.Name, version)
 	binary, err := p.Distribution.FetchTest(version, cli.GOOS, cli.GOARCH)
 	if err != nil {
 		if os.Getenv("TZ_ENFORCE_TEST_PLUGIN") == "1" {
 			return errors.Wrapf(err, "unable to install test plugin for '%v:%v'", p.Name, version)
 		}
-		log.Infof("  ... skipped: %s", err.Error())
+		clilog.Infof("  ... skipped: %s", err.Error())
 		return nil
 	}
 	testPluginPath := cli.TestPluginPathFromPluginPath(pluginPath)
@@ -857,13 +2209,17 @@ func doInstallTestPlugin(p *discovery.Discovered, pluginPath, version string) er
 	return nil
 }
 
-func updatePluginInfoAndInitializePlugin(p *discovery.Discovered, plugin *cli.PluginInfo) error {
+func updatePluginInfoAndInitializePlugin(p *discovery.Discovered, plugin *cli.PluginInfo, skipPostInstall bool) error {
 	c, err := catalog.NewContextCatalogUpdater(p.ContextName)
 	if err != nil {
 		return err
 	}
+	// The binary of a plugin installed to a custom directory is not kept once replaced
+	previous, found := c.Get(catalog.PluginNameTarget(plugin.Name, plugin.Target))
 	if err := c.Upsert(plugin); err != nil {
-		log.Info("Plugin Info could not be updated in cache")
+		clilog.Info("Plugin Info could not be updated in cache")
+	} else if found && previous.InstallationPath != plugin.InstallationPath {
+		removeCustomPluginBinary(previous.InstallationPath)
 	}
 
 	// We are not using defer `c.Unlock()` to release the lock here because we want to unlock the lock as soon as possible
@@ -871,11 +2227,22 @@ func updatePluginInfoAndInitializePlugin(p *discovery.Discovered, plugin *cli.Pl
 	// `addPluginToCommandTreeCache` invocations which is not what we want.
 	c.Unlock()
 
-	if err := InitializePlugin(plugin); err != nil {
-		log.Infof("could not initialize plugin after installing: %v", err.Error())
+	publishEvent(func() Event {
+		event := Event{Type: EventPluginInstalled, Name: plugin.Name, Target: plugin.Target, Version: plugin.Version, ContextName: p.ContextName, Path: plugin.InstallationPath}
+		if found && previous.Version != plugin.Version {
+			event.Type = EventPluginUpgraded
+			event.PreviousVersion = previous.Version
+		}
+		return event
+	})
+
+	if skipPostInstall {
+		log.V(4).Infof("Skipping the post-install command of plugin '%s'", plugin.Name)
+	} else if err := InitializePlugin(plugin); err != nil {
+		clilog.Infof("could not initialize plugin after installing: %v", err.Error())
 	}
 	if err := config.ConfigureDefaultFeatureFlagsIfMissing(plugin.DefaultFeatureFlags); err != nil {
-		log.Infof("could not configure default featureflags for the plugin: %v", err.Error())
+		clilog.Infof("could not configure default featureflags for the plugin: %v", err.Error())
 	}
 	// add plugin to the plugin command tree cache for telemetry to consume later for plugin command chain parsing
 	addPluginToCommandTreeCache(plugin)
@@ -1003,21 +2370,32 @@ func DeletePlugin(options DeletePluginOptions) error {
 	}
 
 	// Delete the plugins that match from the catalog
-	return doDeletePluginsFromCatalog(matchedPlugins)
-
-	// TODO: delete the plugin binary if it is not used by any server
+	if err := doDeletePluginsFromCatalog(matchedPlugins); err != nil {
+		return err
+	}
+	if !options.PruneCache {
+		return nil
+	}
+	_, err = PruneUnusedInventoryCache()
+	return err
 }
 
 func doDeletePluginsFromCatalog(plugins []cli.PluginInfo) error {
+	recoverInterruptedPluginOperationsOnce()
 	errList := make([]error, 0)
 
 	catalogNames, err := configlib.GetAllActiveContextsList()
 	if err != nil {
 		return err
 	}
+
 	// Add empty serverName for standalone plugins
 	catalogNames = append(catalogNames, "")
 
+	// The deletions from each catalog are journaled until the binaries are removed
+	// so that they can be completed if the CLI is interrupted in between
+	journals := startJournaledOperations(journalOperationDelete, catalogNames, plugins)
+
 	for _, n := range catalogNames {
 		// We must create one catalog at a time to be able to delete a plugin.
 		// If we create more than one catalog at a time, then, when we delete the plugin
@@ -1038,15 +2416,44 @@ func doDeletePluginsFromCatalog(plugins []cli.PluginInfo) error {
 	}
 
 	for i := range plugins {
-		log.Infof("Uninstalling plugin '%s' for target '%s'", plugins[i].Name, plugins[i].Target)
+		clilog.Infof("Uninstalling plugin '%s' for target '%s'", plugins[i].Name, plugins[i].Target)
 	}
+	removeCustomPluginBinaries(plugins)
+	completeJournaledOperations(journals)
+	publishPluginsDeleted(plugins)
 	return kerrors.NewAggregate(errList)
 }
 
+// publishPluginsDeleted publishes an EventPluginDeleted for each of the plugins
+func publishPluginsDeleted(plugins []cli.PluginInfo) {
+	for i := range plugins {
+		publishEvent(func() Event {
+			return Event{Type: EventPluginDeleted, Name: plugins[i].Name, Target: plugins[i].Target, Version: plugins[i].Version, Path: plugins[i].InstallationPath}
+		})
+	}
+}
+
 // SyncPlugins will install the plugins required by the current contexts.
 // If the central-repo is disabled, all discovered plugins will be installed.
-func SyncPlugins() error {
-	log.Info("Checking for required plugins...")
+// WithDiscoverySource() restricts the sync to the plugins of a single discovery source.
+func SyncPlugins(options ...PluginManagerOptions) error {
+	opts := NewPluginManagerOpts(options...)
+	if opts.discoverySource != "" {
+		if err := ValidateServerDiscoverySource(opts.discoverySource); err != nil {
+			return err
+		}
+	}
+
+	if opts.strict {
+		if err := CheckSyncDiscoverySources(options...); err != nil {
+			return err
+		}
+	}
+
+	publishEvent(func() Event {
+		return Event{Type: EventSyncStarted, DiscoverySource: opts.discoverySource}
+	})
+	clilog.Info("Checking for required plugins...")
 	errList := make([]error, 0)
 	// We no longer sync standalone plugins.
 	// With a centralized approach to discovering plugins, synchronizing
@@ -1057,76 +2464,563 @@ func SyncPlugins() error {
 	//
 	// Note: to install all plugins for a specific product, plugin groups will
 	// need to be used.
-	plugins, err := DiscoverServerPlugins()
+	// Only the plugins of the selected discovery source are installed.
+	// Installed plugins provided by other sources are left untouched.
+	plugins, err := DiscoverServerPlugins(options...)
+	if err != nil {
+		errList = append(errList, err)
+	}
+	err = InstallDiscoveredContextPlugins(plugins, options...)
 	if err != nil {
 		errList = append(errList, err)
 	}
-	err = InstallDiscoveredContextPlugins(plugins)
-	if err != nil {
-		errList = append(errList, err)
+	err = kerrors.NewAggregate(errList)
+	publishEvent(func() Event {
+		return Event{Type: EventSyncCompleted, DiscoverySource: opts.discoverySource, Err: err}
+	})
+	return err
+}
+
+// CheckSyncDiscoverySources fetches all the discovery sources a plugin sync relies upon:
+// the discovery sources of the active contexts, restricted to the one selected with
+// WithDiscoverySource() if any, and the configured discovery sources providing the
+// plugin binaries.  If any of them cannot be fetched or verified, an
+// *UnreachableDiscoverySourcesError enumerating the failed sources is returned.
+func CheckSyncDiscoverySources(options ...PluginManagerOptions) error {
+	opts := NewPluginManagerOpts(options...)
+
+	currentContextMap, err := configlib.GetAllActiveContextsMap()
+	if err != nil {
+		return err
+	}
+	contextNames := make([]string, 0, len(currentContextMap))
+	for name := range currentContextMap {
+		contextNames = append(contextNames, name)
+	}
+	sort.Strings(contextNames)
+
+	var sources []configtypes.PluginDiscovery
+	for _, name := range contextNames {
+		for _, ds := range getServerDiscoverySources(currentContextMap[name]) {
+			if opts.discoverySource == "" || discovery.CheckDiscoveryName(ds, opts.discoverySource) {
+				sources = append(sources, ds)
+			}
+		}
+	}
+	if len(sources) == 0 {
+		// Nothing will be installed so the configured discovery sources are not needed
+		return nil
+	}
+	configuredSources, err := getPluginDiscoveries()
+	if err != nil {
+		return err
+	}
+	sources = append(sources, configuredSources...)
+
+	resultsPerSource := make([]discoveryResult, len(sources))
+	for result := range listPluginsFromDiscoveries(interrupt.Context(), sources, false) {
+		resultsPerSource[result.index] = result
+	}
+	if interrupt.Interrupted() {
+		return interrupt.ErrInterrupted
+	}
+
+	var failedSources []*DiscoverySourceError
+	for _, result := range resultsPerSource {
+		if result.err == nil {
+			continue
+		}
+		var sourceErr *DiscoverySourceError
+		if !errors.As(result.err, &sourceErr) {
+			sourceErr = &DiscoverySourceError{Source: discovery.GetDiscoveryName(sources[result.index]), Err: result.err}
+		}
+		failedSources = append(failedSources, sourceErr)
+	}
+	if len(failedSources) > 0 {
+		return &UnreachableDiscoverySourcesError{Sources: failedSources}
+	}
+	return nil
+}
+
+// SyncPlanEntry is a plugin that a plugin sync would act upon
+type SyncPlanEntry struct {
+	Name   string             `json:"name" yaml:"name"`
+	Target configtypes.Target `json:"target" yaml:"target"`
+	// Context is the name of the context recommending the plugin or,
+	// for a removal, the context the plugin was installed for
+	Context string `json:"context" yaml:"context"`
+	// InstalledVersion is the version of the plugin currently installed
+	// with the same name and target; it is empty if there is none
+	InstalledVersion string `json:"installedVersion,omitempty" yaml:"installedVersion,omitempty"`
+	// InstalledScope is the scope (Standalone or Context) of the installed plugin
+	InstalledScope string `json:"installedScope,omitempty" yaml:"installedScope,omitempty"`
+	// Version is the version that would be installed; it is empty for a removal
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// SyncPlan is the difference between the installed plugins and the
+// plugins recommended by the active contexts
+type SyncPlan struct {
+	// Add lists the context plugins that would be installed.  A standalone
+	// plugin of the same name and target may already be installed, in which
+	// case the context plugin would take precedence over it.
+	Add []SyncPlanEntry `json:"add" yaml:"add"`
+	// Upgrade lists the installed context plugins whose recommended version has changed
+	Upgrade []SyncPlanEntry `json:"upgrade" yaml:"upgrade"`
+	// Remove lists the installed context plugins no longer recommended by their context.
+	// Plugin sync does not uninstall them; they would only be removed if sync removed
+	// unmanaged plugins.
+	Remove []SyncPlanEntry `json:"remove" yaml:"remove"`
+	// Prune lists the installed standalone plugins no longer provided by any discovery
+	// source, which a plugin sync with pruning would uninstall.  It is only computed
+	// when WithPrune() is specified.
+	Prune []SyncPlanEntry `json:"prune,omitempty" yaml:"prune,omitempty"`
+}
+
+// PlanSyncPlugins computes what SyncPlugins would do without installing anything.
+// WithDiscoverySource() restricts the plan to the plugins of a single discovery source.
+// If some plugins cannot be discovered, the partial plan is returned along with the error.
+func PlanSyncPlugins(options ...PluginManagerOptions) (*SyncPlan, error) {
+	opts := NewPluginManagerOpts(options...)
+	if opts.discoverySource != "" {
+		if err := ValidateServerDiscoverySource(opts.discoverySource); err != nil {
+			return nil, err
+		}
+	}
+
+	errList := make([]error, 0)
+	desired, err := DiscoverServerPlugins(options...)
+	if err != nil {
+		errList = append(errList, err)
+	}
+	standalonePlugins, err := pluginsupplier.GetInstalledStandalonePlugins()
+	if err != nil {
+		errList = append(errList, err)
+	}
+
+	plan := &SyncPlan{}
+	UpdatePluginsInstallationStatus(desired)
+	for i := range desired {
+		p := &desired[i]
+		if p.IsUpToDate() {
+			continue
+		}
+		entry := SyncPlanEntry{Name: p.Name, Target: p.Target, Context: p.ContextName, Version: p.RecommendedVersion}
+		if p.Status != common.PluginStatusNotInstalled {
+			entry.InstalledVersion = p.InstalledVersion
+			entry.InstalledScope = common.PluginScopeContext
+			plan.Upgrade = append(plan.Upgrade, entry)
+			continue
+		}
+		for j := range standalonePlugins {
+			if standalonePlugins[j].Name == p.Name && standalonePlugins[j].Target == p.Target {
+				entry.InstalledVersion = standalonePlugins[j].Version
+				entry.InstalledScope = common.PluginScopeStandalone
+				break
+			}
+		}
+		plan.Add = append(plan.Add, entry)
+	}
+
+	removals, err := getUnmanagedContextPlugins(desired, opts.discoverySource)
+	if err != nil {
+		errList = append(errList, err)
+	}
+	plan.Remove = removals
+
+	if opts.prune {
+		orphaned, err := GetOrphanedStandalonePlugins()
+		if err != nil {
+			errList = append(errList, err)
+		}
+		for i := range orphaned {
+			plan.Prune = append(plan.Prune, SyncPlanEntry{
+				Name:             orphaned[i].Name,
+				Target:           orphaned[i].Target,
+				InstalledVersion: orphaned[i].Version,
+				InstalledScope:   common.PluginScopeStandalone,
+			})
+		}
+	}
+
+	for _, entries := range [][]SyncPlanEntry{plan.Add, plan.Upgrade, plan.Remove, plan.Prune} {
+		sortSyncPlanEntries(entries)
+	}
+	return plan, kerrors.NewAggregate(errList)
+}
+
+// getUnmanagedContextPlugins returns the installed context plugins that are no longer
+// recommended by the context they were installed for.  If discoverySource is not empty,
+// only the plugins installed from that discovery source are considered.
+func getUnmanagedContextPlugins(desired []discovery.Discovered, discoverySource string) ([]SyncPlanEntry, error) {
+	contextNames, err := configlib.GetAllActiveContextsList()
+	if err != nil {
+		return nil, err
+	}
+
+	var unmanaged []SyncPlanEntry
+	for _, contextName := range contextNames {
+		if contextName == "" {
+			continue
+		}
+		c, err := catalog.NewContextCatalog(contextName)
+		if err != nil {
+			return nil, err
+		}
+		installed := c.List()
+		for i := range installed {
+			if discoverySource != "" && installed[i].Discovery != discoverySource {
+				continue
+			}
+			recommended := false
+			for j := range desired {
+				if desired[j].ContextName == contextName && desired[j].Name == installed[i].Name && desired[j].Target == installed[i].Target {
+					recommended = true
+					break
+				}
+			}
+			if !recommended {
+				unmanaged = append(unmanaged, SyncPlanEntry{
+					Name:             installed[i].Name,
+					Target:           installed[i].Target,
+					Context:          contextName,
+					InstalledVersion: installed[i].Version,
+					InstalledScope:   common.PluginScopeContext,
+				})
+			}
+		}
+	}
+	return unmanaged, nil
+}
+
+// GetOrphanedStandalonePlugins returns the installed standalone plugins that are no longer
+// provided by any of the configured discovery sources.  Context plugins are never reported,
+// and neither are the plugins installed without a discovery source, e.g., from a local source.
+// No plugin is reported if any discovery source cannot be read, since the plugins it provides
+// would look orphaned.
+func GetOrphanedStandalonePlugins() ([]cli.PluginInfo, error) {
+	standalonePlugins, err := pluginsupplier.GetInstalledStandalonePlugins()
+	if err != nil {
+		return nil, err
+	}
+	if len(standalonePlugins) == 0 {
+		return nil, nil
+	}
+
+	discovered, err := DiscoverStandalonePlugins()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to find the orphaned plugins as the plugins of all the discovery sources cannot be listed")
+	}
+	return getOrphanedPlugins(standalonePlugins, discovered), nil
+}
+
+// getOrphanedPlugins returns the installed plugins installed from a discovery source
+// whose name and target are not found among the discovered plugins
+func getOrphanedPlugins(installed []cli.PluginInfo, discovered []discovery.Discovered) []cli.PluginInfo {
+	provided := make(map[string]bool, len(discovered))
+	for i := range discovered {
+		provided[catalog.PluginNameTarget(discovered[i].Name, discovered[i].Target)] = true
+	}
+
+	var orphaned []cli.PluginInfo
+	for i := range installed {
+		if installed[i].Discovery == "" {
+			continue
+		}
+		if !provided[catalog.PluginNameTarget(installed[i].Name, installed[i].Target)] {
+			orphaned = append(orphaned, installed[i])
+		}
+	}
+	return orphaned
+}
+
+// DeleteStandalonePlugins uninstalls the specified standalone plugins without
+// asking for confirmation.  The context plugins of the same name and target are kept.
+func DeleteStandalonePlugins(plugins []cli.PluginInfo) error {
+	recoverInterruptedPluginOperationsOnce()
+
+	for i := range plugins {
+		// Delete the plugins from the command tree cache which would be consumed by telemetry
+		deletePluginFromCommandTreeCache(&plugins[i])
+	}
+
+	c, err := catalog.NewContextCatalogUpdater("")
+	if err != nil {
+		return err
+	}
+	defer c.Unlock()
+
+	errList := make([]error, 0)
+	for i := range plugins {
+		journal := startJournaledOperation(journalOperationDelete, "", &plugins[i])
+		if err := c.Delete(catalog.PluginNameTarget(plugins[i].Name, plugins[i].Target)); err != nil {
+			journal.complete()
+			errList = append(errList, fmt.Errorf("plugin %q could not be deleted from cache", plugins[i].Name))
+			continue
+		}
+		removeCustomPluginBinary(plugins[i].InstallationPath)
+		journal.complete()
+		clilog.Infof("Uninstalling plugin '%s' for target '%s'", plugins[i].Name, plugins[i].Target)
+		publishPluginsDeleted(plugins[i : i+1])
+	}
+	return kerrors.NewAggregate(errList)
+}
+
+// sortSyncPlanEntries sorts the entries of a sync plan by context, name and target
+func sortSyncPlanEntries(entries []SyncPlanEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Context != entries[j].Context {
+			return entries[i].Context < entries[j].Context
+		}
+		if entries[i].Name != entries[j].Name {
+			return entries[i].Name < entries[j].Name
+		}
+		return entries[i].Target < entries[j].Target
+	})
+}
+
+func DiscoverPluginsForContextType(contextType configtypes.ContextType, options ...PluginManagerOptions) ([]discovery.Discovered, error) {
+	ctx, err := configlib.GetActiveContext(contextType)
+	if err != nil {
+		return nil, err
+	}
+	if ctx == nil {
+		return nil, fmt.Errorf(errorNoActiveContexForGivenContextType, contextType)
+	}
+	clilog.Infof("Checking for required plugins for context '%s'...", ctx.Name)
+	return DiscoverServerPluginsForGivenContexts([]*configtypes.Context{ctx}, options...)
+}
+
+// InstallDiscoveredContextPlugins installs the given context scope plugins.
+// The plugins are installed in a deterministic order: by context name, then by plugin
+// name and target.  WithConcurrency() allows several plugins to be installed at the
+// same time; by default they are installed one at a time.  Installation errors are
+// reported grouped by context.
+func InstallDiscoveredContextPlugins(plugins []discovery.Discovered, options ...PluginManagerOptions) error {
+	opts := NewPluginManagerOpts(options...)
+	UpdatePluginsInstallationStatus(plugins)
+
+	var toInstall []discovery.Discovered
+	for idx := range plugins {
+		if !plugins[idx].IsUpToDate() {
+			toInstall = append(toInstall, plugins[idx])
+		}
+	}
+	if len(toInstall) == 0 {
+		clilog.Info("All required plugins are already installed and up-to-date")
+		return nil
+	}
+	sort.SliceStable(toInstall, func(i, j int) bool {
+		if toInstall[i].ContextName != toInstall[j].ContextName {
+			return toInstall[i].ContextName < toInstall[j].ContextName
+		}
+		if toInstall[i].Name != toInstall[j].Name {
+			return toInstall[i].Name < toInstall[j].Name
+		}
+		return toInstall[i].Target < toInstall[j].Target
+	})
+
+	concurrency := opts.concurrency
+	// The DarwinARM64 fallback of selectPluginForInstallation() temporarily changes
+	// the architecture of the whole process, so plugins must be installed one at a time
+	if concurrency < 1 || cli.BuildArch() == cli.DarwinARM64 {
+		concurrency = 1
+	}
+
+	var installErrors []error
+	if concurrency == 1 {
+		installErrors = make([]error, len(toInstall))
+		for idx := range toInstall {
+			if interrupt.Interrupted() {
+				// Don't start installing more plugins
+				break
+			}
+			p := toInstall[idx]
+			installErrors[idx] = InstallPluginFromContext(p.Name, p.RecommendedVersion, p.Target, p.ContextName)
+		}
+	} else {
+		installErrors = installContextPluginsConcurrently(toInstall, concurrency)
+	}
+	if interrupt.Interrupted() {
+		return interrupt.ErrInterrupted
 	}
-	return kerrors.NewAggregate(errList)
-}
 
-func DiscoverPluginsForContextType(contextType configtypes.ContextType) ([]discovery.Discovered, error) {
-	ctx, err := configlib.GetActiveContext(contextType)
-	if err != nil {
-		return nil, err
+	// Group the errors by context
+	var errList, contextErrList []error
+	for idx := range toInstall {
+		if installErrors[idx] != nil {
+			contextErrList = append(contextErrList, installErrors[idx])
+		}
+		if idx == len(toInstall)-1 || toInstall[idx+1].ContextName != toInstall[idx].ContextName {
+			if len(contextErrList) > 0 {
+				errList = append(errList, errors.Wrapf(kerrors.NewAggregate(contextErrList), "unable to install the plugins of context '%s'", toInstall[idx].ContextName))
+			}
+			contextErrList = nil
+		}
 	}
-	if ctx == nil {
-		return nil, fmt.Errorf(errorNoActiveContexForGivenContextType, contextType)
+	if err := kerrors.NewAggregate(errList); err != nil {
+		return err
 	}
-	log.Infof("Checking for required plugins for context '%s'...", ctx.Name)
-	return DiscoverServerPluginsForGivenContexts([]*configtypes.Context{ctx})
+
+	clilog.Info("Successfully installed all required plugins")
+	return nil
 }
 
-// UpdatePluginsInstallationStatus updates the installation status of the given plugins
-func UpdatePluginsInstallationStatus(plugins []discovery.Discovered) {
-	if installedPlugins, err := pluginsupplier.GetInstalledServerPlugins(); err == nil {
-		setAvailablePluginsStatus(plugins, installedPlugins)
+// installContextPluginsConcurrently installs the context plugins, along with any plugin
+// they depend on, running up to 'concurrency' installations at the same time, and returns
+// the error of the installation of each plugin.  The plugins and their dependencies are
+// first resolved one at a time, as resolving them accesses the inventories of the discovery
+// sources, so that only the binaries of the plugins are installed concurrently.  The plugins
+// required by the plugins are installed first, each of them once even if required by several.
+func installContextPluginsConcurrently(plugins []discovery.Discovered, concurrency int) []error {
+	installErrors := make([]error, len(plugins))
+	resolved := make([][]*resolvedPlugin, len(plugins))
+	for idx := range plugins {
+		if interrupt.Interrupted() {
+			return installErrors
+		}
+		p := plugins[idx]
+		if p.ContextName == "" {
+			log.Warningf("Missing context name for a context-scope plugin: %s/%s/%s", p.Name, p.RecommendedVersion, string(p.Target))
+		}
+		resolved[idx], installErrors[idx] = resolveContextPluginForInstallation(&p)
+		if installErrors[idx] != nil && !interrupt.Interrupted() {
+			recordPluginInstallStatus(p.Name, p.Target, p.RecommendedVersion, installErrors[idx])
+		}
 	}
+
+	// Install the plugins required by the plugins first, each of them once
+	dependencies := dedupeContextPluginDependencies(resolved)
+	dependencyErrors := make(map[string]error, len(dependencies))
+	var dependencyErrorsMutex sync.Mutex
+	runConcurrently(len(dependencies), concurrency, func(idx int) {
+		rp := dependencies[idx]
+		_, err := installOrUpgradePlugin(rp.plugin, rp.version, false, false, false, "")
+		dependencyErrorsMutex.Lock()
+		dependencyErrors[resolvedContextPluginKey(rp)] = err
+		dependencyErrorsMutex.Unlock()
+	})
+	if interrupt.Interrupted() {
+		return installErrors
+	}
+
+	runConcurrently(len(plugins), concurrency, func(idx int) {
+		if installErrors[idx] != nil {
+			return
+		}
+		rp := resolved[idx][len(resolved[idx])-1]
+		installErrors[idx] = installResolvedContextPlugin(rp, resolved[idx][:len(resolved[idx])-1], dependencyErrors)
+		if !interrupt.Interrupted() {
+			recordPluginInstallStatus(rp.plugin.Name, rp.plugin.Target, rp.version, installErrors[idx])
+		}
+	})
+	return installErrors
 }
 
-// InstallDiscoveredContextPlugins installs the given context scope plugins
-func InstallDiscoveredContextPlugins(plugins []discovery.Discovered) error {
-	var errList []error
-	var err error
-	installed := false
-	UpdatePluginsInstallationStatus(plugins)
-	for idx := range plugins {
-		if plugins[idx].Status == common.PluginStatusNotInstalled || plugins[idx].Status == common.PluginStatusUpdateAvailable {
-			installed = true
-			p := plugins[idx]
-			err = InstallPluginFromContext(p.Name, p.RecommendedVersion, p.Target, p.ContextName)
-			if err != nil {
-				errList = append(errList, err)
+// resolveContextPluginForInstallation returns the plugins to install for the specified
+// context plugin, in installation order and ending with the plugin itself
+func resolveContextPluginForInstallation(p *discovery.Discovered) ([]*resolvedPlugin, error) {
+	var plugins []*resolvedPlugin
+	err := selectPluginForInstallation(p.Name, p.RecommendedVersion, p.Target, p.ContextName, func(sp *discovery.Discovered) error {
+		var err error
+		if plugins, err = resolvePluginDependencies(sp, sp.RecommendedVersion); err != nil {
+			return err
+		}
+		for _, rp := range plugins {
+			if err := checkCLICompatibility(rp.plugin, rp.version, false); err != nil {
+				return err
 			}
 		}
+		if len(plugins) > 1 {
+			clilog.Infof("Plugin '%s' requires the installation of: %s", pluginIDString(sp.Name, sp.Target, plugins[len(plugins)-1].version), resolvedPluginsString(plugins[:len(plugins)-1]))
+		}
+		return nil
+	})
+	return plugins, err
+}
+
+// installResolvedContextPlugin installs a resolved context plugin once the plugins it
+// requires have been installed, unless the installation of any of them failed.
+// The plugin is not installed again if it was itself required by another plugin.
+func installResolvedContextPlugin(rp *resolvedPlugin, dependencies []*resolvedPlugin, dependencyErrors map[string]error) error {
+	for _, dep := range dependencies {
+		if err := dependencyErrors[resolvedContextPluginKey(dep)]; err != nil {
+			return errors.Wrapf(err, "unable to install plugin '%s' required by plugin '%s'", pluginIDString(dep.plugin.Name, dep.plugin.Target, dep.version), pluginIDString(rp.plugin.Name, rp.plugin.Target, rp.version))
+		}
 	}
-	err = kerrors.NewAggregate(errList)
-	if err != nil {
+	if err, installed := dependencyErrors[resolvedContextPluginKey(rp)]; installed {
 		return err
 	}
+	_, err := installOrUpgradePlugin(rp.plugin, rp.version, false, false, false, "")
+	return err
+}
 
-	if !installed {
-		log.Info("All required plugins are already installed and up-to-date")
-	} else {
-		log.Info("Successfully installed all required plugins")
+// dedupeContextPluginDependencies returns the plugins required by the resolved plugins,
+// each of them once, in the order in which they are first required.  The last plugin
+// of each resolved list is the plugin requiring the others.
+func dedupeContextPluginDependencies(resolved [][]*resolvedPlugin) []*resolvedPlugin {
+	var dependencies []*resolvedPlugin
+	seen := make(map[string]bool)
+	for _, plugins := range resolved {
+		for i := 0; i < len(plugins)-1; i++ {
+			key := resolvedContextPluginKey(plugins[i])
+			if !seen[key] {
+				seen[key] = true
+				dependencies = append(dependencies, plugins[i])
+			}
+		}
 	}
-	return nil
+	return dependencies
+}
+
+// resolvedContextPluginKey identifies a resolved plugin by its context, name, target and version
+func resolvedContextPluginKey(rp *resolvedPlugin) string {
+	return rp.plugin.ContextName + "/" + pluginIDString(rp.plugin.Name, rp.plugin.Target, rp.version)
+}
+
+// runConcurrently calls 'f' for each index from 0 to n-1, with up to 'concurrency'
+// calls running at the same time.  No more calls are started once interrupted.
+func runConcurrently(n, concurrency int, f func(idx int)) {
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for idx := 0; idx < n; idx++ {
+		semaphore <- struct{}{}
+		if interrupt.Interrupted() {
+			break
+		}
+		wg.Add(1)
+		go func(idx int) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			f(idx)
+		}(idx)
+	}
+	wg.Wait()
 }
 
 // InstallPluginsFromLocalSource installs plugin from local source directory
+func InstallPluginsFromLocalSource(pluginName, version string, target configtypes.Target, localPath string, installTestPlugin bool, options ...PluginManagerOptions) error {
+	_, err := InstallPluginsFromLocalSourceWithResults(pluginName, version, target, localPath, installTestPlugin, options...)
+	return err
+}
+
+// InstallPluginsFromLocalSourceWithResults installs plugin from local source directory
+// and returns the result of the installation of each plugin.  The results of the plugins
+// successfully installed are returned even if the installation of other plugins failed.
 //
 //nolint:gocyclo
-func InstallPluginsFromLocalSource(pluginName, version string, target configtypes.Target, localPath string, installTestPlugin bool) error {
+func InstallPluginsFromLocalSourceWithResults(pluginName, version string, target configtypes.Target, localPath string, installTestPlugin bool, options ...PluginManagerOptions) ([]*InstallResult, error) {
+	opts := NewPluginManagerOpts(options...)
+
 	// Set default local plugin distro to local-path as while installing the plugin
 	// from local source we should take t
 	common.DefaultLocalPluginDistroDir = localPath
 
 	availablePlugins, err := DiscoverPluginsFromLocalSource(localPath)
 	if err != nil {
-		return errors.Wrap(err, "unable to discover plugins")
+		return nil, errors.Wrap(err, "unable to discover plugins")
 	}
 
 	var errList []error
@@ -1141,37 +3035,148 @@ func InstallPluginsFromLocalSource(pluginName, version string, target configtype
 	if len(matchedPlugins) == 0 {
 		if pluginName == cli.AllPlugins {
 			if target != configtypes.TargetUnknown {
-				return errors.Errorf("unable to find any plugins for target '%s'", string(target))
+				return nil, errors.Errorf("unable to find any plugins for target '%s'", string(target))
 			}
-			return errors.Errorf("unable to find any plugins at the specified location")
+			return nil, errors.Errorf("unable to find any plugins at the specified location")
 		}
 
 		if target != configtypes.TargetUnknown {
-			return errors.Errorf("unable to find plugin '%v' matching version '%v' for target '%s'", pluginName, version, string(target))
+			return nil, errors.Errorf("unable to find plugin '%v' matching version '%v' for target '%s'", pluginName, version, string(target))
 		}
-		return errors.Errorf("unable to find plugin '%v' matching version '%v'", pluginName, version)
+		return nil, errors.Errorf("unable to find plugin '%v' matching version '%v'", pluginName, version)
 	}
 
 	if len(matchedPlugins) == 1 {
-		return installOrUpgradePlugin(&matchedPlugins[0], version, installTestPlugin)
+		result, err := installLocalPlugin(&matchedPlugins[0], version, installTestPlugin, opts.skipPostInstall)
+		if err != nil {
+			return nil, err
+		}
+		return []*InstallResult{result}, nil
 	}
 
+	var results []*InstallResult
 	for i := range matchedPlugins {
 		// Install all plugins otherwise include all matching plugins
 		if pluginName == cli.AllPlugins || matchedPlugins[i].Target == target {
-			err = installOrUpgradePlugin(&matchedPlugins[i], version, installTestPlugin)
+			result, err := installLocalPlugin(&matchedPlugins[i], version, installTestPlugin, opts.skipPostInstall)
 			if err != nil {
 				errList = append(errList, err)
+				continue
 			}
+			results = append(results, result)
 		}
 	}
 
-	err = kerrors.NewAggregate(errList)
+	return results, kerrors.NewAggregate(errList)
+}
+
+// installLocalPlugin installs a plugin discovered from a local source and
+// returns the result of its installation, including its duration
+func installLocalPlugin(p *discovery.Discovered, version string, installTestPlugin, skipPostInstall bool) (*InstallResult, error) {
+	start := time.Now()
+	result, err := installOrUpgradePlugin(p, version, installTestPlugin, false, skipPostInstall, "")
 	if err != nil {
-		return err
+		return nil, err
 	}
+	result.Duration = time.Since(start)
+	return result, nil
+}
 
-	return nil
+// InstallPluginFromBinary installs a pre-built plugin binary as a standalone plugin
+// without using any discovery source.  The binary must respond to the "info" command
+// of the plugin protocol, which provides the name and version of the plugin.
+// If the target is TargetUnknown, the target reported by the plugin is used.
+func InstallPluginFromBinary(binaryPath string, target configtypes.Target, options ...PluginManagerOptions) (*InstallResult, error) {
+	start := time.Now()
+	opts := NewPluginManagerOpts(options...)
+
+	binary, err := os.ReadFile(binaryPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read plugin binary %q", binaryPath)
+	}
+
+	info, err := getPluginInfoFromBinary(binaryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if target == configtypes.TargetUnknown {
+		target = info.Target
+		if target == configtypes.TargetUnknown {
+			target = configtypes.TargetGlobal
+		}
+	} else if info.Target != configtypes.TargetUnknown && info.Target != target {
+		return nil, errors.Errorf("plugin binary %q is for target '%s', not '%s'", binaryPath, string(info.Target), string(target))
+	}
+
+	p := &discovery.Discovered{
+		Name:               info.Name,
+		Description:        info.Description,
+		RecommendedVersion: info.Version,
+		SupportedVersions:  []string{info.Version},
+		Target:             target,
+		Scope:              common.PluginScopeStandalone,
+	}
+	isPluginAlreadyInstalled := pluginsupplier.IsStandalonePluginInstalled(p.Name, p.Target, info.Version)
+
+	clilog.Infof("Installing plugin '%v:%v' with target '%v' from binary %q", p.Name, info.Version, p.Target, binaryPath)
+	plugin, err := installAndDescribePlugin(p, info.Version, binary)
+	if err != nil {
+		return nil, err
+	}
+	if err := updatePluginInfoAndInitializePlugin(p, plugin, opts.skipPostInstall); err != nil {
+		return nil, err
+	}
+
+	return &InstallResult{
+		Name:             p.Name,
+		Target:           p.Target,
+		Version:          info.Version,
+		Digest:           fmt.Sprintf("%x", sha256.Sum256(binary)),
+		Path:             plugin.InstallationPath,
+		AlreadyInstalled: isPluginAlreadyInstalled,
+		Duration:         time.Since(start),
+	}, nil
+}
+
+// getPluginInfoFromBinary invokes the "info" command of a plugin binary
+// to confirm it is a tanzu plugin and to obtain its description.
+// Like for the plugins being installed from a discovery, the binary is
+// given at most pluginHandshakeTimeout to answer.
+func getPluginInfoFromBinary(binaryPath string) (*cli.PluginInfo, error) {
+	info, err := runPluginHandshake(filepath.Base(binaryPath), binaryPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%q is not a valid plugin binary", binaryPath)
+	}
+	return info, nil
+}
+
+// DiscoverPluginsFromInventoryDB returns the plugins found in the plugin inventory database
+// file 'dbFile', which is queried directly without accessing any registry.
+// The options can restrict the plugins returned, e.g. to a page of them.
+func DiscoverPluginsFromInventoryDB(dbFile string, options ...discovery.DiscoveryOptions) ([]discovery.Discovered, error) {
+	if err := plugininventory.ValidateInventoryDB(dbFile); err != nil {
+		return nil, err
+	}
+	plugins, err := discovery.NewInventoryDBDiscovery(dbFile, dbFile, options...).List()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to list the plugins of the inventory DB '%s'", dbFile)
+	}
+	return plugins, nil
+}
+
+// CountPluginsFromInventoryDB returns the number of plugins found in the plugin inventory
+// database file 'dbFile'.  A plugin is counted once no matter how many versions of it
+// the inventory contains, as in the result of DiscoverPluginsFromInventoryDB.
+func CountPluginsFromInventoryDB(dbFile string) (int, error) {
+	if err := plugininventory.ValidateInventoryDB(dbFile); err != nil {
+		return 0, err
+	}
+	count, err := discovery.NewInventoryDBDiscovery(dbFile, dbFile).(discovery.CountableDiscovery).CountPlugins()
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to count the plugins of the inventory DB '%s'", dbFile)
+	}
+	return count, nil
 }
 
 // DiscoverPluginsFromLocalSource returns the available plugins that are discovered from the provided local path
@@ -1235,15 +3240,53 @@ func discoverPluginsFromLocalSource(localPath string) ([]discovery.Discovered, e
 	return plugins, nil
 }
 
+// PruneInventoryCache evicts the least recently used plugin inventories from the
+// cache until the cache uses at most maxSize bytes.  It returns the names of the
+// evicted cache entries.
+func PruneInventoryCache(maxSize int64) ([]string, error) {
+	return discovery.PruneInventoryCache(maxSize, "")
+}
+
+// ListCachedInventories returns the plugin inventories found in the cache
+// along with their on-disk footprint.
+func ListCachedInventories() ([]discovery.CachedInventoryInfo, error) {
+	return discovery.ListCachedInventories()
+}
+
 // Clean deletes all plugins and tests.
-func Clean() error {
+// If WithTarget() or WithDiscoverySource() is specified, only the installed plugins
+// of that target and/or installed from that discovery source are removed instead.
+func Clean(options ...PluginManagerOptions) error {
+	opts := NewPluginManagerOpts(options...)
+	if opts.target != configtypes.TargetUnknown || opts.discoverySource != "" {
+		if err := cleanMatchingPlugins(opts.target, opts.discoverySource); err != nil {
+			return err
+		}
+		if opts.pruneCache {
+			_, err := PruneUnusedInventoryCache()
+			return err
+		}
+		return nil
+	}
+
 	errorList := make([]error, 0)
 
+	// Remove the binaries of the plugins installed outside of the plugin root,
+	// which must be found before cleaning the catalog
+	if installedPlugins, err := matchPluginsForClean(configtypes.TargetUnknown, ""); err == nil {
+		removeCustomPluginBinaries(installedPlugins)
+	}
+
 	// Clean the plugin catalog
 	if err := catalog.CleanCatalogCache(); err != nil {
 		errorList = append(errorList, errors.Wrapf(err, "Failed to clean the catalog cache"))
 	}
 
+	// Clean the outcomes of the plugin installations
+	if err := cleanPluginInstallStatus(); err != nil {
+		errorList = append(errorList, errors.Wrapf(err, "Failed to clean the plugin install status"))
+	}
+
 	// Clean plugin inventory cache
 	pluginDataDir := filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName)
 	if err := os.RemoveAll(pluginDataDir); err != nil {
@@ -1264,6 +3307,148 @@ func Clean() error {
 	return kerrors.NewAggregate(errorList)
 }
 
+// PruneUnusedInventoryCache removes the cached plugin inventories of the discovery sources
+// which are neither configured, as a standalone discovery source or as a discovery source of
+// a context, nor the source of an installed plugin.  Each removal is reported and the names
+// of the removed cache directories are returned.
+func PruneUnusedInventoryCache() ([]string, error) {
+	referenced, err := getReferencedDiscoverySources()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to determine the discovery sources in use")
+	}
+	removed, err := discovery.RemoveUnreferencedInventories(referenced)
+	for _, name := range removed {
+		clilog.Infof("Removed the cached plugin inventory '%s', which is no longer used by any installed plugin or configured discovery source", name)
+	}
+	return removed, err
+}
+
+// getReferencedDiscoverySources returns the names of the discovery sources that are
+// configured, including those of all the contexts, or that installed plugins come from
+func getReferencedDiscoverySources() (map[string]bool, error) {
+	referenced := make(map[string]bool)
+
+	discoveries, err := getPluginDiscoveries()
+	if err != nil {
+		return nil, err
+	}
+	for _, ds := range discoveries {
+		referenced[discovery.GetDiscoveryName(ds)] = true
+	}
+
+	cfg, err := configlib.GetClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	for _, context := range cfg.KnownContexts {
+		for _, ds := range getServerDiscoverySources(context) {
+			referenced[discovery.GetDiscoveryName(ds)] = true
+		}
+	}
+
+	installedPlugins, err := pluginsupplier.GetInstalledPlugins()
+	if err != nil {
+		return nil, err
+	}
+	for i := range installedPlugins {
+		if installedPlugins[i].Discovery != "" {
+			referenced[installedPlugins[i].Discovery] = true
+		}
+	}
+	return referenced, nil
+}
+
+// matchPluginsForClean returns the installed plugins of the active contexts and the
+// standalone plugins that a clean restricted to the specified target and/or discovery
+// source would remove.
+func matchPluginsForClean(target configtypes.Target, discoverySource string) ([]cli.PluginInfo, error) {
+	var matchedPlugins []cli.PluginInfo
+	catalogNames, err := configlib.GetAllActiveContextsList()
+	if err != nil {
+		return matchedPlugins, err
+	}
+	// Add empty serverName for standalone plugins
+	catalogNames = append(catalogNames, "")
+
+	for _, serverName := range catalogNames {
+		c, err := catalog.NewContextCatalog(serverName)
+		if err != nil {
+			continue
+		}
+		plugins := c.List()
+		for i := range plugins {
+			if isPluginMatchingCleanFilters(&plugins[i], target, discoverySource) {
+				matchedPlugins = append(matchedPlugins, plugins[i])
+			}
+		}
+	}
+	return matchedPlugins, nil
+}
+
+func isPluginMatchingCleanFilters(plugin *cli.PluginInfo, target configtypes.Target, discoverySource string) bool {
+	return (target == configtypes.TargetUnknown || plugin.Target == target) &&
+		(discoverySource == "" || plugin.Discovery == discoverySource)
+}
+
+// cleanMatchingPlugins removes the installed plugins of the specified target and/or
+// installed from the specified discovery source.  Unlike doDeletePluginsFromCatalog,
+// a catalog entry is only removed if it matches the filters itself, so that a plugin
+// of the same name and target installed from another discovery source is kept.
+// The cached plugin inventories are left untouched, as the discovery source may
+// still be configured and used by other commands.
+func cleanMatchingPlugins(target configtypes.Target, discoverySource string) error {
+	matchedPlugins, err := matchPluginsForClean(target, discoverySource)
+	if err != nil {
+		return err
+	}
+	if len(matchedPlugins) == 0 {
+		switch {
+		case target != configtypes.TargetUnknown && discoverySource != "":
+			return errors.Errorf("unable to find any installed plugins for target '%s' from discovery source '%s'", string(target), discoverySource)
+		case target != configtypes.TargetUnknown:
+			return errors.Errorf("unable to find any installed plugins for target '%s'", string(target))
+		default:
+			return errors.Errorf("unable to find any installed plugins from discovery source '%s'", discoverySource)
+		}
+	}
+
+	catalogNames, err := configlib.GetAllActiveContextsList()
+	if err != nil {
+		return err
+	}
+	// Add empty serverName for standalone plugins
+	catalogNames = append(catalogNames, "")
+
+	errorList := make([]error, 0)
+	for _, n := range catalogNames {
+		// One catalog at a time, see doDeletePluginsFromCatalog()
+		c, err := catalog.NewContextCatalogUpdater(n)
+		if err != nil {
+			continue
+		}
+		plugins := c.List()
+		for i := range plugins {
+			if !isPluginMatchingCleanFilters(&plugins[i], target, discoverySource) {
+				continue
+			}
+			if err := c.Delete(catalog.PluginNameTarget(plugins[i].Name, plugins[i].Target)); err != nil {
+				errorList = append(errorList, fmt.Errorf("plugin %q could not be deleted from cache", plugins[i].Name))
+			}
+		}
+		c.Unlock()
+	}
+
+	for i := range matchedPlugins {
+		// Delete the plugins from the command tree cache which would be consumed by telemetry
+		deletePluginFromCommandTreeCache(&matchedPlugins[i])
+		clilog.Infof("Removing plugin '%s' for target '%s'", matchedPlugins[i].Name, matchedPlugins[i].Target)
+	}
+	removeCustomPluginBinaries(matchedPlugins)
+	publishPluginsDeleted(matchedPlugins)
+
+	return kerrors.NewAggregate(errorList)
+}
+
 // getCLIPluginResourceWithLocalDistroFromPluginInfo return cliv1alpha1.CLIPlugin resource from the pluginInfo
 // Note: This function generates cliv1alpha1.CLIPlugin which contains only single local distribution type artifact for
 // OS-ARCH where user is running the cli
@@ -1484,9 +3669,233 @@ func getPluginDiscoveries() ([]configtypes.PluginDiscovery, error) {
 	// may contain older versions of a plugin that is now published to the production
 	// central repo; we therefore need to search the test discoveries last.
 	discoverySources, _ := configlib.GetCLIDiscoverySources()
+
+	// The selected discovery profile, if any, overrides the configured discoveries
+	discoverySources, err := config.ApplyPluginDiscoveryProfile(discoverySources)
+	if err != nil {
+		return nil, err
+	}
+
+	// When a plugin is provided by multiple discoveries, the first one found wins,
+	// so the discoveries with the highest priority are searched first
+	sortPluginDiscoveriesByPriority(discoverySources)
 	return append(discoverySources, testDiscoveries...), nil
 }
 
+// sortPluginDiscoveriesByPriority sorts the discovery sources from the highest to
+// the lowest priority.  Discovery sources with the same priority keep their order,
+// which is the order in which they are configured.
+func sortPluginDiscoveriesByPriority(discoverySources []configtypes.PluginDiscovery) {
+	priorities := make(map[string]int)
+	for _, ds := range discoverySources {
+		name := discovery.GetDiscoveryName(ds)
+		priorities[name] = config.GetPluginDiscoveryPriority(name)
+	}
+	sort.SliceStable(discoverySources, func(i, j int) bool {
+		return priorities[discovery.GetDiscoveryName(discoverySources[i])] > priorities[discovery.GetDiscoveryName(discoverySources[j])]
+	})
+}
+
+// DiscoverySignatureVerification is the outcome of the signature verification
+// of the inventory image of a discovery source
+type DiscoverySignatureVerification struct {
+	// Source is the name of the discovery source
+	Source string
+	// Image is the inventory image of the discovery source
+	Image string
+	// Result contains the details of the verified signature.
+	// It is nil if the user chose to skip the verification for the image.
+	Result *cosignhelper.SignatureVerificationResult
+}
+
+// VerifyDiscoverySourcesSignature verifies the signature of the inventory image
+// of each OCI discovery source, whether or not the inventory is already cached.
+// The images are verified concurrently and the error of the first discovery
+// source whose image fails the verification is returned.
+func VerifyDiscoverySourcesSignature() ([]DiscoverySignatureVerification, error) {
+	discoveries, err := getPluginDiscoveries()
+	if err != nil {
+		return nil, err
+	}
+	var verifications []DiscoverySignatureVerification
+	var images []string
+	var keychains []authn.Keychain
+	for i := range discoveries {
+		if discoveries[i].OCI == nil {
+			continue
+		}
+		verifications = append(verifications, DiscoverySignatureVerification{
+			Source: discoveries[i].OCI.Name,
+			Image:  discoveries[i].OCI.Image,
+		})
+		images = append(images, discoveries[i].OCI.Image)
+		keychains = append(keychains, discovery.GetRegistryKeychain(discoveries[i].OCI.Name, discoveries[i].OCI.Image))
+	}
+
+	results, errs := sigverifier.VerifyInventoryImageSignaturesWithResults(images, keychains)
+	for i := range verifications {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		verifications[i].Result = results[i]
+	}
+	return verifications, nil
+}
+
+// The statuses of the signature verification of the source of a plugin
+const (
+	// PluginSignatureStatusVerified means the signature of the inventory image was verified
+	PluginSignatureStatusVerified = "verified"
+	// PluginSignatureStatusSkipped means the user chose to skip the verification for the image
+	PluginSignatureStatusSkipped = "skipped"
+	// PluginSignatureStatusFailed means the image is not signed or its signature is invalid
+	PluginSignatureStatusFailed = "failed"
+	// PluginSignatureStatusUnavailable means there is no signed image to verify for the plugin
+	PluginSignatureStatusUnavailable = "unavailable"
+)
+
+// PluginSignatureVerification is the outcome of the signature verification
+// of the inventory image of the discovery source an installed plugin comes from
+type PluginSignatureVerification struct {
+	// Source is the name of the discovery source the plugin was installed from
+	Source string `json:"source" yaml:"source"`
+	// Image is the inventory image of the discovery source
+	Image string `json:"image" yaml:"image"`
+	// Status is one of the PluginSignatureStatus constants
+	Status string `json:"status" yaml:"status"`
+	// Reason explains why the signature could not be verified
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	// Digest is the digest of the image covered by the signature
+	Digest string `json:"digest,omitempty" yaml:"digest,omitempty"`
+	// Signer is the identity of the signer, only available for keyless signatures
+	Signer string `json:"signer,omitempty" yaml:"signer,omitempty"`
+	// Issuer is the OIDC issuer of the signer certificate, only available for keyless signatures
+	Issuer string `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+	// TransparencyLogBundle is true if the signature comes with a Rekor inclusion bundle
+	TransparencyLogBundle bool `json:"transparencyLogBundle" yaml:"transparencyLogBundle"`
+}
+
+// VerifyPluginSourceSignature verifies, on demand, the signature of the inventory image
+// of the discovery source the specified installed plugin was installed from.
+// When the signature cannot be verified, the returned verification says why.
+func VerifyPluginSourceSignature(plugin *cli.PluginInfo) *PluginSignatureVerification {
+	verification := &PluginSignatureVerification{Source: plugin.Discovery}
+	if plugin.Discovery == "" {
+		verification.Status = PluginSignatureStatusUnavailable
+		verification.Reason = "the plugin was not installed from a discovery source"
+		return verification
+	}
+
+	discoveries, err := getPluginDiscoveries()
+	if err != nil {
+		verification.Status = PluginSignatureStatusUnavailable
+		verification.Reason = err.Error()
+		return verification
+	}
+	var source *configtypes.PluginDiscovery
+	for i := range discoveries {
+		if discovery.CheckDiscoveryName(discoveries[i], plugin.Discovery) {
+			source = &discoveries[i]
+			break
+		}
+	}
+	switch {
+	case source == nil:
+		verification.Status = PluginSignatureStatusUnavailable
+		verification.Reason = fmt.Sprintf("the discovery source '%s' is no longer configured", plugin.Discovery)
+		return verification
+	case source.OCI == nil:
+		verification.Status = PluginSignatureStatusUnavailable
+		verification.Reason = fmt.Sprintf("the discovery source '%s' is not an OCI image", plugin.Discovery)
+		return verification
+	}
+
+	verification.Image = source.OCI.Image
+	result, err := sigverifier.VerifyInventoryImageSignatureWithResult(source.OCI.Image, discovery.GetRegistryKeychain(source.OCI.Name, source.OCI.Image))
+	switch {
+	case err != nil:
+		verification.Status = PluginSignatureStatusFailed
+		verification.Reason = err.Error()
+	case result == nil:
+		verification.Status = PluginSignatureStatusSkipped
+		verification.Reason = fmt.Sprintf("the image is listed in %s", constants.PluginDiscoveryImageSignatureVerificationSkipList)
+	default:
+		verification.Status = PluginSignatureStatusVerified
+		verification.Digest = result.Digest
+		verification.Signer = result.Signer
+		verification.Issuer = result.Issuer
+		verification.TransparencyLogBundle = result.TransparencyLogBundle
+	}
+	return verification
+}
+
+// DiscoveryPrefetchResult is the outcome of the prefetching of the
+// inventory of a discovery source
+type DiscoveryPrefetchResult struct {
+	// Source is the name of the discovery source
+	Source string
+	// Image is the inventory image of the discovery source
+	Image string
+	// Duration is the time it took to refresh the cached inventory
+	Duration time.Duration
+	// Err is the error that occurred while refreshing the cached inventory, if any
+	Err error
+}
+
+// PrefetchDiscoverySources refreshes the cached inventory of each OCI discovery
+// source so that later commands don't need to download it.  If parallel is true,
+// the discovery sources are refreshed concurrently.  The results are returned
+// in the order of the discovery sources.
+func PrefetchDiscoverySources(parallel bool) ([]DiscoveryPrefetchResult, error) {
+	discoveries, err := getPluginDiscoveries()
+	if err != nil {
+		return nil, err
+	}
+
+	var ociDiscoveries []configtypes.PluginDiscovery
+	for i := range discoveries {
+		if discoveries[i].OCI != nil {
+			ociDiscoveries = append(ociDiscoveries, discoveries[i])
+		}
+	}
+	if len(ociDiscoveries) == 0 {
+		return nil, errors.New(errorNoDiscoverySourcesFound)
+	}
+
+	results := make([]DiscoveryPrefetchResult, len(ociDiscoveries))
+	var wg sync.WaitGroup
+	for i := range ociDiscoveries {
+		if !parallel {
+			results[i] = prefetchDiscoverySource(ociDiscoveries[i])
+			continue
+		}
+		wg.Add(1)
+		go func(index int, d configtypes.PluginDiscovery) {
+			defer wg.Done()
+			results[index] = prefetchDiscoverySource(d)
+		}(i, ociDiscoveries[i])
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// prefetchDiscoverySource refreshes the cached inventory of an OCI discovery source
+func prefetchDiscoverySource(d configtypes.PluginDiscovery) DiscoveryPrefetchResult {
+	start := time.Now()
+	result := DiscoveryPrefetchResult{Source: d.OCI.Name, Image: d.OCI.Image}
+
+	discObject, err := discovery.CreateDiscoveryFromV1alpha1(d)
+	if err != nil {
+		result.Err = errors.Wrapf(err, "unable to create discovery")
+	} else if refreshable, ok := discObject.(discovery.RefreshableDiscovery); !ok {
+		result.Err = errors.Errorf("discovery source '%s' does not support prefetching", d.OCI.Name)
+	} else if err = refreshable.Refresh(); err != nil {
+		result.Err = errors.Wrapf(err, "unable to prefetch the inventory of discovery source '%s'", d.OCI.Name)
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
 // IsPluginsFromPluginGroupInstalled checks if all plugins from a specific group are installed and if a new version is available.
 // This function uses cache data to verify rather than fetching the inventory image
 func IsPluginsFromPluginGroupInstalled(name, version string, options ...PluginManagerOptions) (bool, bool, error) {
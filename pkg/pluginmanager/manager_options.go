@@ -9,12 +9,27 @@ import (
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
 
 // PluginManagerOpts options to customize plugin lifecycle operations
 type PluginManagerOpts struct {
-	showLogs bool // Enable or disable logs
+	showLogs          bool               // Enable or disable logs
+	discoverySource   string             // Restrict the operation to a single discovery source
+	target            configtypes.Target // Restrict the operation to the plugins of a single target
+	includePrerelease bool               // Consider pre-release versions when looking for the latest version
+	reinstall         bool               // Install the plugin again even if the same version is already installed
+	concurrency       int                // Maximum number of plugins installed at the same time
+	strict            bool               // Fail if any discovery source cannot be fetched
+	skipPostInstall   bool               // Do not run the post-install command of the installed plugins
+	groupOnly         []string           // Only install these members when installing all the plugins of a group
+	groupExclude      []string           // Do not install these members when installing all the plugins of a group
+	prune             bool               // Also plan the removal of the orphaned standalone plugins
+	installDir        string             // Install the plugin binary to this directory instead of the default plugin root
+	force             bool               // Install plugin versions requiring a more recent CLI, with a warning
+	digest            string             // Install the version of the plugin whose artifact has this digest
+	pruneCache        bool               // Also remove the cached plugin inventories no longer in use
 }
 
 // GetLogMode sets the log mode based on the environment variable.
@@ -60,6 +75,110 @@ func EnableLogs() PluginManagerOptions {
 	}
 }
 
+// WithDiscoverySource restricts the operation to the plugins
+// provided by the discovery source with the specified name
+func WithDiscoverySource(name string) PluginManagerOptions {
+	return func(p *PluginManagerOpts) {
+		p.discoverySource = name
+	}
+}
+
+// WithTarget restricts the operation to the plugins of the specified target
+func WithTarget(target configtypes.Target) PluginManagerOptions {
+	return func(p *PluginManagerOpts) {
+		p.target = target
+	}
+}
+
+// WithIncludePrerelease allows pre-release versions to be
+// selected as the latest version of a plugin
+func WithIncludePrerelease(include bool) PluginManagerOptions {
+	return func(p *PluginManagerOpts) {
+		p.includePrerelease = include
+	}
+}
+
+// WithReinstall installs the plugin again, downloading it anew,
+// even if the same version is already installed
+func WithReinstall(reinstall bool) PluginManagerOptions {
+	return func(p *PluginManagerOpts) {
+		p.reinstall = reinstall
+	}
+}
+
+// WithConcurrency allows up to n plugins to be installed at the same time
+func WithConcurrency(n int) PluginManagerOptions {
+	return func(p *PluginManagerOpts) {
+		p.concurrency = n
+	}
+}
+
+// WithStrict makes a plugin sync fail without installing anything
+// if any of its discovery sources cannot be fetched or verified
+func WithStrict(strict bool) PluginManagerOptions {
+	return func(p *PluginManagerOpts) {
+		p.strict = strict
+	}
+}
+
+// WithSkipPostInstall does not run the post-install command
+// of the plugins after installing them
+func WithSkipPostInstall(skip bool) PluginManagerOptions {
+	return func(p *PluginManagerOpts) {
+		p.skipPostInstall = skip
+	}
+}
+
+// WithGroupMemberFilter restricts the installation of all the plugins of a group to the
+// members named in only, if any, that are not named in exclude.  Naming a plugin that is
+// not a member of the group is an error.
+func WithGroupMemberFilter(only, exclude []string) PluginManagerOptions {
+	return func(p *PluginManagerOpts) {
+		p.groupOnly = only
+		p.groupExclude = exclude
+	}
+}
+
+// WithInstallDir installs the binary of the specified plugin to the specified directory
+// instead of the default plugin root.  The plugins it depends on are installed as usual.
+func WithInstallDir(dir string) PluginManagerOptions {
+	return func(p *PluginManagerOpts) {
+		p.installDir = dir
+	}
+}
+
+// WithForce installs the plugin versions that require a more recent version
+// of the CLI, logging a warning instead of failing
+func WithForce(force bool) PluginManagerOptions {
+	return func(p *PluginManagerOpts) {
+		p.force = force
+	}
+}
+
+// WithDigest installs the version of the plugin whose artifact for the platform
+// of the CLI has the specified sha256 digest, instead of selecting it by version
+func WithDigest(digest string) PluginManagerOptions {
+	return func(p *PluginManagerOpts) {
+		p.digest = digest
+	}
+}
+
+// WithPrune makes a plugin sync plan also list the installed standalone
+// plugins that are no longer provided by any discovery source
+func WithPrune(prune bool) PluginManagerOptions {
+	return func(p *PluginManagerOpts) {
+		p.prune = prune
+	}
+}
+
+// WithPruneCache removes, once the plugins are cleaned, the cached plugin inventories
+// no longer used by any installed plugin or configured discovery source
+func WithPruneCache(pruneCache bool) PluginManagerOptions {
+	return func(p *PluginManagerOpts) {
+		p.pruneCache = pruneCache
+	}
+}
+
 // NewPluginManagerOpts creates a new PluginManagerOpts instance with provided options.
 func NewPluginManagerOpts(opts ...PluginManagerOptions) *PluginManagerOpts {
 	// By default logs are enabled
@@ -4,17 +4,25 @@
 package pluginmanager
 
 import (
+	"context"
+	"crypto/sha256"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
+	configlib "github.com/vmware-tanzu/tanzu-plugin-runtime/config"
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 
+	"github.com/vmware-tanzu/tanzu-cli/pkg/artifact"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/buildinfo"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
@@ -177,6 +185,60 @@ func Test_DiscoverStandalonePlugins(t *testing.T) {
 	}
 }
 
+func Test_DiscoverStandalonePluginsStream(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	pluginsCh, errCh := DiscoverStandalonePluginsStream(context.Background())
+	var streamedPlugins []discovery.Discovered
+	for p := range pluginsCh {
+		assertions.Equal(common.PluginScopeStandalone, p.Scope)
+		assertions.Equal(common.PluginStatusNotInstalled, p.Status)
+		streamedPlugins = append(streamedPlugins, p)
+	}
+	for err := range errCh {
+		assertions.Nil(err)
+	}
+
+	// Once merged, the streamed plugins must match the ones returned all at once
+	standalonePlugins := mergeDuplicatePlugins(streamedPlugins)
+	assertions.Equal(len(expectedDiscoveredStandalonePlugins), len(standalonePlugins))
+	for i := range expectedDiscoveredStandalonePlugins {
+		p := findDiscoveredPlugin(standalonePlugins, expectedDiscoveredStandalonePlugins[i].Name, expectedDiscoveredStandalonePlugins[i].Target)
+		assertions.NotNil(p)
+		assertions.Equal(expectedDiscoveredStandalonePlugins[i].RecommendedVersion, p.RecommendedVersion)
+		assertions.Equal(expectedDiscoveredStandalonePlugins[i].SupportedVersions, p.SupportedVersions)
+	}
+
+	// A cancelled context stops the stream and closes the channels
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pluginsCh, errCh = DiscoverStandalonePluginsStream(ctx)
+	count := 0
+	for range pluginsCh {
+		count++
+	}
+	assertions.LessOrEqual(count, len(streamedPlugins))
+	for err := range errCh {
+		assertions.Nil(err)
+	}
+}
+
+func Test_listPluginsFromDiscoveriesSequentially(t *testing.T) {
+	assertions := assert.New(t)
+
+	// Unknown discovery sources fail immediately, in the order of the sources
+	sources := make([]configtypes.PluginDiscovery, 5)
+	index := 0
+	for result := range listPluginsFromDiscoveries(context.Background(), sources, false) {
+		assertions.Equal(index, result.index)
+		assertions.NotNil(result.err)
+		index++
+	}
+	assertions.Equal(len(sources), index)
+}
+
 func Test_DiscoverServerPlugins(t *testing.T) {
 	assertions := assert.New(t)
 
@@ -196,6 +258,19 @@ func Test_DiscoverServerPlugins(t *testing.T) {
 	}
 }
 
+func Test_ValidateActiveContext(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	assertions.Nil(ValidateActiveContext("mgmt"))
+	assertions.Nil(ValidateActiveContext("tmc-fake"))
+
+	err := ValidateActiveContext("prod-cluster")
+	assertions.NotNil(err)
+	assertions.Equal("context 'prod-cluster' not found, the active contexts are: mgmt, tmc-fake", err.Error())
+}
+
 func Test_DiscoverPluginGroups(t *testing.T) {
 	assertions := assert.New(t)
 
@@ -210,6 +285,43 @@ func Test_DiscoverPluginGroups(t *testing.T) {
 	}
 }
 
+func Test_DiffPluginGroupVersions(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	diff, err := DiffPluginGroupVersions("vmware-test/default", "v1.6.0", "v2.1.0")
+	assertions.Nil(err)
+	assertions.Equal([]*PluginGroupDiffEntry{
+		{Change: PluginGroupDiffRemoved, Name: "cluster", Target: configtypes.TargetK8s, FromVersion: "v1.6.0"},
+		{Change: PluginGroupDiffRemoved, Name: "feature", Target: configtypes.TargetK8s, FromVersion: "v0.2.0"},
+		{Change: PluginGroupDiffRemoved, Name: "management-cluster", Target: configtypes.TargetK8s, FromVersion: "v1.6.0"},
+		{Change: PluginGroupDiffRemoved, Name: "myplugin", Target: configtypes.TargetK8s, FromVersion: "v1.6.0"},
+		{Change: PluginGroupDiffChanged, Name: "isolated-cluster", Target: configtypes.TargetGlobal, FromVersion: "v1.2.3", ToVersion: "v1.3"},
+	}, diff)
+
+	// The reverse diff reports the same plugins as added
+	diff, err = DiffPluginGroupVersions("vmware-test/default", "v2.1.0", "v1.6.0")
+	assertions.Nil(err)
+	assertions.Equal(5, len(diff))
+	assertions.Equal(PluginGroupDiffAdded, diff[0].Change)
+	assertions.Equal("cluster", diff[0].Name)
+	assertions.Equal(PluginGroupDiffChanged, diff[4].Change)
+
+	// Comparing a version with itself finds no difference
+	diff, err = DiffPluginGroupVersions("vmware-test/default", "v1.6.0", "v1.6.0")
+	assertions.Nil(err)
+	assertions.Empty(diff)
+
+	_, err = DiffPluginGroupVersions("vmware-test/default", "v1.6.0", "v9.9.9")
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to find plugin group with name 'vmware-test/default' matching version 'v9.9.9'")
+
+	_, err = DiffPluginGroupVersions("vmware-test/default:v1.6.0", "v1.6.0", "v2.1.0")
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "the versions to compare must be specified separately")
+}
+
 func Test_InstallStandalonePlugin(t *testing.T) {
 	assertions := assert.New(t)
 
@@ -375,6 +487,237 @@ func Test_InstallStandalonePlugin(t *testing.T) {
 	}
 }
 
+func Test_InstallStandalonePluginWithResult(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	result, err := InstallStandalonePluginWithResult("not-exists", "v0.2.0", configtypes.TargetUnknown)
+	assertions.NotNil(err)
+	assertions.Nil(result)
+
+	result, err = InstallStandalonePluginWithResult("login", "v0", configtypes.TargetUnknown)
+	assertions.Nil(err)
+	assertions.NotNil(result)
+	assertions.Equal("login", result.Name)
+	assertions.Equal(configtypes.TargetGlobal, result.Target)
+	assertions.Equal("v0.20.0", result.Version)
+	assertions.False(result.AlreadyInstalled)
+
+	// Installing the same version again should report it as already installed
+	result, err = InstallStandalonePluginWithResult("login", "v0.20.0", configtypes.TargetUnknown)
+	assertions.Nil(err)
+	assertions.NotNil(result)
+	assertions.Equal("v0.20.0", result.Version)
+	assertions.True(result.AlreadyInstalled)
+}
+
+func Test_FetchPluginFromArtifactCache(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	binary := []byte("cached plugin binary")
+	digest := fmt.Sprintf("%x", sha256.Sum256(binary))
+	p := &discovery.Discovered{
+		Name:   "cached",
+		Target: configtypes.TargetK8s,
+		Distribution: distribution.Artifacts{
+			"v1.0.0": []distribution.Artifact{{
+				URI:    filepath.Join(common.DefaultCacheDir, "does-not-exist"),
+				Digest: digest,
+				OS:     cli.GOOS,
+				Arch:   cli.GOARCH,
+			}},
+		},
+	}
+
+	// The binary cannot be downloaded and is not cached
+	_, err := fetchAndVerifyPlugin(p, "v1.0.0", true)
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to fetch the plugin metadata for plugin \"cached\"")
+
+	// The cached binary is used instead of downloading it
+	assertions.Nil(artifact.CacheArtifact(digest, binary))
+	b, err := fetchAndVerifyPlugin(p, "v1.0.0", true)
+	assertions.Nil(err)
+	assertions.Equal(binary, b)
+
+	// The cache is bypassed when the binary must be downloaded again
+	_, err = fetchAndVerifyPlugin(p, "v1.0.0", false)
+	assertions.NotNil(err)
+}
+
+func Test_InstallStandalonePluginAlreadyInstalled(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	result, err := InstallStandalonePluginWithResult("login", "v0.20.0", configtypes.TargetGlobal)
+	assertions.Nil(err)
+	assertions.False(result.AlreadyInstalled)
+
+	installedPlugins, err := pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	installed := findPluginInfo(installedPlugins, "login", configtypes.TargetGlobal)
+	assertions.NotNil(installed)
+
+	// Installing the same version again is skipped
+	result, err = InstallStandalonePluginWithResult("login", "v0.20.0", configtypes.TargetGlobal)
+	assertions.Nil(err)
+	assertions.True(result.AlreadyInstalled)
+	assertions.Equal("v0.20.0", result.Version)
+
+	// Unless the binary of the installed plugin is missing
+	assertions.Nil(os.Remove(installed.InstallationPath))
+	result, err = InstallStandalonePluginWithResult("login", "v0.20.0", configtypes.TargetGlobal)
+	assertions.Nil(err)
+	assertions.True(result.AlreadyInstalled)
+	assertions.FileExists(installed.InstallationPath)
+
+	// A different version is installed
+	result, err = InstallStandalonePluginWithResult("login", "v0.2.0", configtypes.TargetGlobal)
+	assertions.Nil(err)
+	assertions.False(result.AlreadyInstalled)
+	assertions.Equal("v0.2.0", result.Version)
+
+	// The plugin is reinstalled when requested
+	result, err = InstallStandalonePluginWithResult("login", "v0.2.0", configtypes.TargetGlobal, WithReinstall(true))
+	assertions.Nil(err)
+	assertions.True(result.AlreadyInstalled)
+	installedPlugins, err = pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	installed = findPluginInfo(installedPlugins, "login", configtypes.TargetGlobal)
+	assertions.NotNil(installed)
+	assertions.Equal("v0.2.0", installed.Version)
+}
+
+func Test_InstallPluginHandshake(t *testing.T) {
+	assertions := assert.New(t)
+
+	pluginRoot, err := os.MkdirTemp("", "test-plugins")
+	assertions.Nil(err)
+	defer os.RemoveAll(pluginRoot)
+	origPluginRoot := common.DefaultPluginRoot
+	common.DefaultPluginRoot = pluginRoot
+	defer func() { common.DefaultPluginRoot = origPluginRoot }()
+
+	p := &discovery.Discovered{Name: "stub", Target: configtypes.TargetK8s}
+	assertNoBinaryLeft := func() {
+		entries, _ := os.ReadDir(filepath.Join(pluginRoot, "stub"))
+		assertions.Empty(entries)
+	}
+
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	// A plugin which does not describe itself properly
+	_, err = installAndDescribePlugin(p, "v1.0.0", []byte("corrupt"))
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "the binary may be corrupt")
+	assertNoBinaryLeft()
+
+	// A plugin whose description is missing the version
+	_, err = installAndDescribePlugin(p, "v1.0.0", []byte(`{"name":"stub"}`))
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "is missing the name or version of the plugin")
+	assertNoBinaryLeft()
+
+	// A valid plugin
+	plugin, err := installAndDescribePlugin(p, "v1.0.0", []byte(`{"name":"stub","version":"v1.0.0"}`))
+	assertions.Nil(err)
+	assertions.FileExists(plugin.InstallationPath)
+	assertions.Nil(os.Remove(plugin.InstallationPath))
+
+	if cli.BuildArch().IsWindows() {
+		return
+	}
+	execCommand = exec.Command
+
+	// A binary which cannot be executed on this platform
+	_, err = installAndDescribePlugin(p, "v1.0.0", []byte{0x00, 0x01, 0x02, 0x03})
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "cannot run on this platform")
+	assertNoBinaryLeft()
+
+	// A plugin which does not answer in time
+	origTimeout := pluginHandshakeTimeout
+	pluginHandshakeTimeout = 100 * time.Millisecond
+	defer func() { pluginHandshakeTimeout = origTimeout }()
+	_, err = installAndDescribePlugin(p, "v1.0.0", []byte("#!/bin/sh\nexec sleep 10\n"))
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "did not describe itself within 100ms")
+	assertNoBinaryLeft()
+}
+
+func Test_getPluginInfoFromBinary(t *testing.T) {
+	assertions := assert.New(t)
+
+	binaryDir, err := os.MkdirTemp("", "plugin-binary")
+	assertions.Nil(err)
+	defer os.RemoveAll(binaryDir)
+
+	// A binary which does not answer in time
+	origTimeout := pluginHandshakeTimeout
+	pluginHandshakeTimeout = 100 * time.Millisecond
+	defer func() { pluginHandshakeTimeout = origTimeout }()
+	binary := filepath.Join(binaryDir, "tanzu-myplugin")
+	assertions.Nil(os.WriteFile(binary, []byte("#!/bin/sh\nexec sleep 10\n"), 0755))
+	_, err = getPluginInfoFromBinary(binary)
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "is not a valid plugin binary")
+	assertions.Contains(err.Error(), "did not describe itself within 100ms")
+}
+
+func Test_InstallPluginFromBinary(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	// The fake "info" command outputs the content of the binary
+	binaryDir, err := os.MkdirTemp("", "plugin-binary")
+	assertions.Nil(err)
+	defer os.RemoveAll(binaryDir)
+
+	invalidBinary := filepath.Join(binaryDir, "invalid")
+	assertions.Nil(os.WriteFile(invalidBinary, []byte("not a plugin"), 0755))
+	_, err = InstallPluginFromBinary(invalidBinary, configtypes.TargetUnknown)
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "is not a valid plugin binary")
+
+	binary := filepath.Join(binaryDir, "tanzu-myplugin")
+	assertions.Nil(os.WriteFile(binary, []byte(`{"name":"myplugin","version":"v9.9.9","target":"kubernetes"}`), 0755))
+
+	_, err = InstallPluginFromBinary(binary, configtypes.TargetTMC)
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "is for target 'kubernetes', not 'mission-control'")
+
+	result, err := InstallPluginFromBinary(binary, configtypes.TargetUnknown)
+	assertions.Nil(err)
+	assertions.Equal("myplugin", result.Name)
+	assertions.Equal(configtypes.TargetK8s, result.Target)
+	assertions.Equal("v9.9.9", result.Version)
+	assertions.NotEmpty(result.Digest)
+	assertions.False(result.AlreadyInstalled)
+
+	installedPlugins, err := pluginsupplier.GetInstalledPlugins()
+	assertions.Nil(err)
+	assertions.Equal(1, len(installedPlugins))
+	assertions.Equal("myplugin", installedPlugins[0].Name)
+	assertions.Equal("v9.9.9", installedPlugins[0].Version)
+	assertions.Equal(configtypes.TargetK8s, installedPlugins[0].Target)
+
+	result, err = InstallPluginFromBinary(binary, configtypes.TargetK8s)
+	assertions.Nil(err)
+	assertions.True(result.AlreadyInstalled)
+}
+
 func Test_InstallPluginsFromGroup(t *testing.T) {
 	assertions := assert.New(t)
 
@@ -462,6 +805,65 @@ func Test_InstallPluginsFromGroup(t *testing.T) {
 	assertions.Equal("v0.2.0", pd.Version)
 }
 
+func Test_GetPluginVersionFromGroup(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	version, target, err := GetPluginVersionFromGroup("management-cluster", configtypes.TargetUnknown, testGroupName+":"+testGroupVersion)
+	assertions.Nil(err)
+	assertions.Equal("v1.6.0", version)
+	assertions.Equal(configtypes.TargetK8s, target)
+
+	// The version specified by the group may be partial
+	version, target, err = GetPluginVersionFromGroup("isolated-cluster", configtypes.TargetGlobal, testGroupName+":v2.1.0")
+	assertions.Nil(err)
+	assertions.Equal("v1.3", version)
+	assertions.Equal(configtypes.TargetGlobal, target)
+
+	_, _, err = GetPluginVersionFromGroup("management-cluster", configtypes.TargetTMC, testGroupName+":"+testGroupVersion)
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "plugin 'management-cluster' for target 'mission-control' is not part of the group 'vmware-test/default:v1.6.0'")
+
+	_, _, err = GetPluginVersionFromGroup("unknown", configtypes.TargetUnknown, testGroupName+":"+testGroupVersion)
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "plugin 'unknown' is not part of the group 'vmware-test/default:v1.6.0'")
+
+	_, _, err = GetPluginVersionFromGroup("management-cluster", configtypes.TargetUnknown, testGroupName+":v9.9.9")
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to find plugin group")
+}
+
+func Test_InstallPluginsFromGroupWithResults(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	groupID := testGroupName + ":" + testGroupVersion
+	fullGroupID, results, err := InstallPluginsFromGroupWithResults(cli.AllPlugins, groupID)
+	assertions.Nil(err)
+	assertions.Equal(groupID, fullGroupID)
+	assertions.Equal(4, len(results))
+
+	installedStandalonePlugins, err := pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	for _, result := range results {
+		pd := findPluginInfo(installedStandalonePlugins, result.Name, result.Target)
+		assertions.NotNil(pd)
+		assertions.Equal(pd.Version, result.Version)
+		assertions.Equal(pd.InstallationPath, result.Path)
+		assertions.NotEmpty(result.Digest)
+		assertions.NotZero(result.Duration)
+	}
+
+	// No result is returned for a plugin that is not part of the group
+	_, results, err = InstallPluginsFromGroupWithResults("unknown", groupID)
+	assertions.NotNil(err)
+	assertions.Empty(results)
+}
+
 func Test_InstallPluginsFromGroupErrors(t *testing.T) {
 	assertions := assert.New(t)
 
@@ -508,6 +910,53 @@ func Test_InstallPluginsFromGroupErrors(t *testing.T) {
 	assertions.Contains(err.Error(), fmt.Sprintf("plugin 'cluster' from group '%s' is not mandatory to install", fullGroupID))
 }
 
+func Test_InstallPluginsFromGroupWithMemberFilter(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	groupID := testGroupName + ":" + testGroupVersion
+
+	// Naming a plugin that is not a member of the group fails without installing anything
+	_, err := InstallPluginsFromGroup(cli.AllPlugins, groupID, WithGroupMemberFilter(nil, []string{"myplugin", "not-a-member"}))
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), fmt.Sprintf("plugin 'not-a-member' is not part of the group '%s'", groupID))
+	installedStandalonePlugins, err := pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	assertions.Equal(0, len(installedStandalonePlugins))
+
+	// The filter only applies when installing all the plugins of the group
+	_, err = InstallPluginsFromGroup("myplugin", groupID, WithGroupMemberFilter([]string{"myplugin"}, nil))
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "can only be filtered when installing all its plugins")
+
+	// Only the selected mandatory plugins can be installed
+	_, err = InstallPluginsFromGroup(cli.AllPlugins, groupID, WithGroupMemberFilter([]string{"cluster"}, nil))
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), fmt.Sprintf("none of the selected plugins of group '%s' are mandatory to install", groupID))
+
+	// Install only the feature plugin
+	_, err = InstallPluginsFromGroup(cli.AllPlugins, groupID, WithGroupMemberFilter([]string{"feature"}, nil))
+	assertions.Nil(err)
+	installedStandalonePlugins, err = pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	assertions.Equal(1, len(installedStandalonePlugins))
+	assertions.NotNil(findPluginInfo(installedStandalonePlugins, "feature", configtypes.TargetK8s))
+
+	// Install all the plugins except isolated-cluster and myplugin
+	_, err = InstallPluginsFromGroup(cli.AllPlugins, groupID, WithGroupMemberFilter(nil, []string{"isolated-cluster", "myplugin"}))
+	assertions.Nil(err)
+	installedStandalonePlugins, err = pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	assertions.Equal(2, len(installedStandalonePlugins))
+	assertions.NotNil(findPluginInfo(installedStandalonePlugins, "management-cluster", configtypes.TargetK8s))
+	assertions.NotNil(findPluginInfo(installedStandalonePlugins, "feature", configtypes.TargetK8s))
+	assertions.Nil(findPluginInfo(installedStandalonePlugins, "isolated-cluster", configtypes.TargetGlobal))
+	assertions.Nil(findPluginInfo(installedStandalonePlugins, "myplugin", configtypes.TargetK8s))
+}
+
 func Test_InstallPlugin_InstalledPlugins_From_LocalSource(t *testing.T) {
 	assertions := assert.New(t)
 
@@ -595,6 +1044,151 @@ func Test_DescribePlugin(t *testing.T) {
 	assertions.Contains(err.Error(), "unable to find plugin 'feature' for target 'mission-control'")
 }
 
+func Test_DescribePluginVersions(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	versions, err := DescribePluginVersions("login", configtypes.TargetGlobal)
+	assertions.Nil(err)
+	assertions.Equal(3, len(versions))
+	assertions.Equal("v0.2.0-beta.1", versions[0].Version)
+	assertions.Equal("v0.20.0", versions[2].Version)
+	assertions.Equal([]string{"darwin/amd64", "darwin/arm64", "linux/amd64", "linux/arm64", "windows/amd64"}, versions[2].Platforms())
+	for _, a := range versions[2].Artifacts {
+		if a.Arch == "arm64" {
+			assertions.Equal(digestForARM64, a.Digest)
+		} else {
+			assertions.Equal(digestForAMD64, a.Digest)
+		}
+	}
+
+	// Plugins not published for ARM64 only list the other platforms
+	versions, err = DescribePluginVersions("pluginnoarm", configtypes.TargetK8s)
+	assertions.Nil(err)
+	assertions.Equal(1, len(versions))
+	assertions.Equal([]string{"darwin/amd64", "linux/amd64", "windows/amd64"}, versions[0].Platforms())
+
+	// The target is respected
+	versions, err = DescribePluginVersions("management-cluster", configtypes.TargetTMC)
+	assertions.Nil(err)
+	assertions.Equal(4, len(versions))
+
+	_, err = DescribePluginVersions("login", configtypes.TargetTMC)
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to find plugin 'login' for target 'mission-control' in the discovery sources")
+}
+
+func Test_DescribeAvailablePluginVersion(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	pd, err := DescribeAvailablePluginVersion("login", configtypes.TargetUnknown, "v0.2.0")
+	assertions.Nil(err)
+	assertions.Equal("login", pd.Name)
+	assertions.Equal("v0.2.0", pd.Version)
+	assertions.Equal(configtypes.TargetGlobal, pd.Target)
+	assertions.Equal(common.PluginStatusNotInstalled, pd.Status)
+	assertions.Equal("vmware", pd.Vendor)
+	assertions.Equal("test", pd.Publisher)
+	assertions.NotEmpty(pd.Artifacts)
+
+	// The status reflects that the version is installed
+	err = InstallStandalonePlugin("login", "v0.2.0", configtypes.TargetUnknown)
+	assertions.Nil(err)
+	pd, err = DescribeAvailablePluginVersion("login", configtypes.TargetGlobal, "v0.2.0")
+	assertions.Nil(err)
+	assertions.Equal(common.PluginStatusInstalled, pd.Status)
+
+	// The vendor and publisher are kept with the installed plugin
+	installed, err := DescribePlugin("login", configtypes.TargetGlobal)
+	assertions.Nil(err)
+	assertions.Equal("vmware", installed.Vendor)
+	assertions.Equal("test", installed.Publisher)
+
+	_, err = DescribeAvailablePluginVersion("login", configtypes.TargetUnknown, "v9.9.9")
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to find version 'v9.9.9' of plugin 'login', the available versions are: v0.2.0-beta.1, v0.2.0, v0.20.0")
+
+	_, err = DescribeAvailablePluginVersion("myplugin", configtypes.TargetUnknown, "v1.6.0")
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), fmt.Sprintf(missingTargetStr, "myplugin"))
+
+	_, err = DescribeAvailablePluginVersion("login", configtypes.TargetTMC, "v0.2.0")
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to find plugin 'login' for target 'mission-control' in the discovery sources")
+}
+
+func Test_DescribePluginForAllTargets(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	setupTestPluginCatalog()
+
+	pds, err := DescribePluginForAllTargets("cluster")
+	assertions.Nil(err)
+	assertions.Equal(2, len(pds))
+	assertions.Equal(configtypes.TargetK8s, pds[0].Target)
+	assertions.Equal(configtypes.TargetTMC, pds[1].Target)
+	for i := range pds {
+		assertions.Equal("cluster", pds[i].Name)
+	}
+
+	pds, err = DescribePluginForAllTargets("secret")
+	assertions.Nil(err)
+	assertions.Equal(1, len(pds))
+	assertions.Equal(configtypes.TargetK8s, pds[0].Target)
+
+	_, err = DescribePluginForAllTargets("invalid")
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to find plugin 'invalid'")
+}
+
+func Test_PlanPluginUpgrade(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	// A plugin that is not installed would be installed
+	entry, err := PlanPluginUpgrade("login", configtypes.TargetUnknown)
+	assertions.Nil(err)
+	assertions.Equal("login", entry.Name)
+	assertions.Empty(entry.InstalledVersion)
+	assertions.NotEmpty(entry.Version)
+	assertions.Equal(UpgradeActionInstall, entry.Action)
+
+	err = InstallStandalonePlugin("login", "v0.2.0", configtypes.TargetUnknown)
+	assertions.Nil(err)
+
+	entry, err = PlanPluginUpgrade("login", configtypes.TargetUnknown)
+	assertions.Nil(err)
+	assertions.Equal("v0.2.0", entry.InstalledVersion)
+	assertions.NotEqual(UpgradeActionInstall, entry.Action)
+	if entry.Version == entry.InstalledVersion {
+		assertions.Equal(UpgradeActionNoChange, entry.Action)
+	}
+
+	// Planning must not change the installed plugins
+	installedPlugins, err := pluginsupplier.GetInstalledPlugins()
+	assertions.Nil(err)
+	assertions.Equal(1, len(installedPlugins))
+	assertions.Equal("v0.2.0", installedPlugins[0].Version)
+
+	plan, err := PlanAllPluginsUpgrade(configtypes.TargetUnknown)
+	assertions.Nil(err)
+	assertions.Equal(1, len(plan))
+	assertions.Equal("login", plan[0].Name)
+
+	_, err = PlanPluginUpgrade("not-exists", configtypes.TargetUnknown)
+	assertions.NotNil(err)
+}
+
 func checkPluginIsInstalled(name string, target configtypes.Target) bool {
 	installedPlugins, err := pluginsupplier.GetInstalledPlugins()
 	if err == nil {
@@ -705,24 +1299,311 @@ func Test_SyncPlugins(t *testing.T) {
 		assertions.Equal(common.PluginStatusNotInstalled, p.Status)
 	}
 
-	// Sync all available plugins
-	err = SyncPlugins()
-	assertions.NotNil(err)
-	// There is an error for the kubernetes discovery since we don't have a cluster
-	// but other server plugins will be found, so we use those
-	assertions.Contains(err.Error(), `Failed to load Kubeconfig file from "config"`)
+	// Sync all available plugins
+	err = SyncPlugins()
+	assertions.NotNil(err)
+	// There is an error for the kubernetes discovery since we don't have a cluster
+	// but other server plugins will be found, so we use those
+	assertions.Contains(err.Error(), `Failed to load Kubeconfig file from "config"`)
+
+	installedServerPlugins, err := pluginsupplier.GetInstalledServerPlugins()
+	assertions.Nil(err)
+	assertions.Equal(len(installedServerPlugins), len(serverPlugins))
+
+	for _, isp := range installedServerPlugins {
+		p := findDiscoveredPlugin(serverPlugins, isp.Name, isp.Target)
+		assertions.NotNil(p)
+	}
+}
+
+func Test_SyncPluginsConcurrently(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	err := SyncPlugins(WithConcurrency(4))
+	assertions.NotNil(err)
+	// There is an error for the kubernetes discovery since we don't have a cluster
+	assertions.Contains(err.Error(), `Failed to load Kubeconfig file from "config"`)
+
+	installedServerPlugins, err := pluginsupplier.GetInstalledServerPlugins()
+	assertions.Nil(err)
+	assertions.Equal(len(expectedDiscoveredContextPlugins), len(installedServerPlugins))
+	for _, edp := range expectedDiscoveredContextPlugins {
+		assertions.NotNil(findPluginInfo(installedServerPlugins, edp.Name, edp.Target))
+	}
+
+	// Installation errors are reported per context
+	err = InstallDiscoveredContextPlugins([]discovery.Discovered{
+		{Name: "not-exists", Target: configtypes.TargetK8s, RecommendedVersion: "v1.0.0", ContextName: "ctx-b"},
+		{Name: "not-exists", Target: configtypes.TargetTMC, RecommendedVersion: "v1.0.0", ContextName: "ctx-a"},
+	}, WithConcurrency(2))
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to install the plugins of context 'ctx-a'")
+	assertions.Contains(err.Error(), "unable to install the plugins of context 'ctx-b'")
+	assertions.Less(strings.Index(err.Error(), "'ctx-a'"), strings.Index(err.Error(), "'ctx-b'"))
+}
+
+func Test_SyncPluginsStrict(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	// The kubernetes discovery of the mgmt context cannot be fetched since we don't have a cluster
+	err := SyncPlugins(WithStrict(true))
+	assertions.NotNil(err)
+	var unreachableErr *UnreachableDiscoverySourcesError
+	assertions.True(errors.As(err, &unreachableErr))
+	assertions.Equal(1, len(unreachableErr.Sources))
+	assertions.Equal("default-mgmt", unreachableErr.Sources[0].Source)
+	assertions.Contains(err.Error(), "1 discovery source(s) could not be fetched or verified")
+	assertions.Contains(err.Error(), `'default-mgmt': unable to list plugins from discovery source 'default-mgmt': Failed to load Kubeconfig file`)
+
+	// Nothing is installed, even from the reachable sources
+	installedServerPlugins, err := pluginsupplier.GetInstalledServerPlugins()
+	assertions.Nil(err)
+	assertions.Empty(installedServerPlugins)
+
+	// All the sources used by the sync are reachable
+	err = SyncPlugins(WithStrict(true), WithDiscoverySource("fake-tmc"))
+	assertions.Nil(err)
+	installedServerPlugins, err = pluginsupplier.GetInstalledServerPlugins()
+	assertions.Nil(err)
+	assertions.NotEmpty(installedServerPlugins)
+}
+
+func Test_SyncPluginsFromSource(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	// An unknown discovery source is reported along with the configured ones
+	err := SyncPlugins(WithDiscoverySource("unknown"))
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "discovery source 'unknown' not found")
+	assertions.Contains(err.Error(), "fake-mgmt")
+	assertions.Contains(err.Error(), "fake-tmc")
+
+	// Only the plugins of the selected source are installed.
+	// The kubernetes discovery of the mgmt context is not used so there is no error.
+	err = SyncPlugins(WithDiscoverySource("fake-tmc"))
+	assertions.Nil(err)
+
+	installedServerPlugins, err := pluginsupplier.GetInstalledServerPlugins()
+	assertions.Nil(err)
+	assertions.NotEmpty(installedServerPlugins)
+	for i := range installedServerPlugins {
+		assertions.Equal(configtypes.TargetTMC, installedServerPlugins[i].Target)
+	}
+	installedCount := len(installedServerPlugins)
+
+	// Syncing another source keeps the plugins already installed
+	err = SyncPlugins(WithDiscoverySource("fake-mgmt"))
+	assertions.Nil(err)
+	installedServerPlugins, err = pluginsupplier.GetInstalledServerPlugins()
+	assertions.Nil(err)
+	assertions.Greater(len(installedServerPlugins), installedCount)
+	assertions.NotNil(findPluginInfo(installedServerPlugins, "cluster", configtypes.TargetTMC))
+}
+
+func Test_SyncPluginsWithPinnedVersions(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	os.Setenv(constants.ConfigVariablePinnedPluginVersions, "management-cluster:tmc=v0.0.2, cluster:k8s=v9.9.9")
+	defer os.Unsetenv(constants.ConfigVariablePinnedPluginVersions)
+
+	serverPlugins, err := DiscoverServerPlugins(WithDiscoverySource("fake-tmc"))
+	assertions.Nil(err)
+	p := findDiscoveredPlugin(serverPlugins, "management-cluster", configtypes.TargetTMC)
+	assertions.NotNil(p)
+	assertions.Equal("v0.0.2", p.RecommendedVersion)
+	// The pin of a plugin for another target does not apply
+	p = findDiscoveredPlugin(serverPlugins, "cluster", configtypes.TargetTMC)
+	assertions.NotNil(p)
+	assertions.Equal("v0.2.0", p.RecommendedVersion)
+
+	// The pinned version is installed instead of the recommended version
+	err = SyncPlugins(WithDiscoverySource("fake-tmc"))
+	assertions.Nil(err)
+	installedServerPlugins, err := pluginsupplier.GetInstalledServerPlugins()
+	assertions.Nil(err)
+	for i := range installedServerPlugins {
+		switch installedServerPlugins[i].Name {
+		case "management-cluster":
+			assertions.Equal("v0.0.2", installedServerPlugins[i].Version)
+		case "cluster":
+			assertions.Equal("v0.2.0", installedServerPlugins[i].Version)
+		}
+	}
+
+	// The pinned version is kept by subsequent syncs
+	plan, err := PlanSyncPlugins(WithDiscoverySource("fake-tmc"))
+	assertions.Nil(err)
+	assertions.Empty(plan.Add)
+	assertions.Empty(plan.Upgrade)
+}
+
+func Test_PlanSyncPlugins(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	_, err := PlanSyncPlugins(WithDiscoverySource("unknown"))
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "discovery source 'unknown' not found")
+
+	// Nothing is installed yet so all plugins of the source would be added
+	plan, err := PlanSyncPlugins(WithDiscoverySource("fake-tmc"))
+	assertions.Nil(err)
+	assertions.NotEmpty(plan.Add)
+	assertions.Empty(plan.Upgrade)
+	assertions.Empty(plan.Remove)
+	for _, entry := range plan.Add {
+		assertions.Equal(configtypes.TargetTMC, entry.Target)
+		assertions.NotEmpty(entry.Context)
+		assertions.NotEmpty(entry.Version)
+	}
+	contextName := plan.Add[0].Context
+
+	// The plan does not install anything
+	installedServerPlugins, err := pluginsupplier.GetInstalledServerPlugins()
+	assertions.Nil(err)
+	assertions.Empty(installedServerPlugins)
+
+	// Once synced, there is nothing left to do
+	err = SyncPlugins(WithDiscoverySource("fake-tmc"))
+	assertions.Nil(err)
+	plan, err = PlanSyncPlugins(WithDiscoverySource("fake-tmc"))
+	assertions.Nil(err)
+	assertions.Empty(plan.Add)
+	assertions.Empty(plan.Upgrade)
+	assertions.Empty(plan.Remove)
+
+	// A context plugin no longer recommended by its context is reported for removal
+	cc, err := catalog.NewContextCatalogUpdater(contextName)
+	assertions.Nil(err)
+	err = cc.Upsert(&cli.PluginInfo{Name: "unmanaged", Target: configtypes.TargetTMC, Version: "v1.0.0", Discovery: "fake-tmc"})
+	assertions.Nil(err)
+	cc.Unlock()
+
+	plan, err = PlanSyncPlugins(WithDiscoverySource("fake-tmc"))
+	assertions.Nil(err)
+	assertions.Equal([]SyncPlanEntry{{
+		Name:             "unmanaged",
+		Target:           configtypes.TargetTMC,
+		Context:          contextName,
+		InstalledVersion: "v1.0.0",
+		InstalledScope:   common.PluginScopeContext,
+	}}, plan.Remove)
+}
+
+func Test_getOrphanedPlugins(t *testing.T) {
+	assertions := assert.New(t)
+
+	discovered := []discovery.Discovered{
+		{Name: "provided", Target: configtypes.TargetK8s},
+		{Name: "other-target", Target: configtypes.TargetTMC},
+	}
+	installed := []cli.PluginInfo{
+		{Name: "provided", Target: configtypes.TargetK8s, Version: "v1.0.0", Discovery: "default"},
+		{Name: "other-target", Target: configtypes.TargetK8s, Version: "v1.0.0", Discovery: "default"},
+		{Name: "removed", Target: configtypes.TargetK8s, Version: "v1.0.0", Discovery: "default"},
+		{Name: "local", Target: configtypes.TargetK8s, Version: "v1.0.0", Discovery: ""},
+	}
+
+	// Plugins installed without a discovery source are never orphaned
+	orphaned := getOrphanedPlugins(installed, discovered)
+	assertions.Equal([]cli.PluginInfo{installed[1], installed[2]}, orphaned)
+
+	assertions.Empty(getOrphanedPlugins(installed[:1], discovered))
+	assertions.Empty(getOrphanedPlugins(nil, discovered))
+}
+
+func Test_findDuplicatePlugins(t *testing.T) {
+	assertions := assert.New(t)
+
+	discovered := []discovery.Discovered{
+		{Name: "unique", Target: configtypes.TargetK8s, Source: "default", SupportedVersions: []string{"v1.0.0"}},
+		{Name: "shared", Target: configtypes.TargetTMC, Source: "default", SupportedVersions: []string{"v1.0.0", "v1.1.0"}},
+		{Name: "legacy", Target: configtypes.TargetUnknown, Source: "default", SupportedVersions: []string{"v0.1.0"}},
+		{Name: "shared", Target: configtypes.TargetK8s, Source: "default", SupportedVersions: []string{"v1.0.0"}},
+		{Name: "shared", Target: configtypes.TargetTMC, Source: "mirror", SupportedVersions: []string{"v1.1.0"}},
+		{Name: "legacy", Target: configtypes.TargetK8s, Source: "mirror", SupportedVersions: []string{"v0.2.0"}},
+		{Name: "repeated", Target: configtypes.TargetK8s, Source: "mirror", SupportedVersions: []string{"v1.0.0"}},
+		{Name: "repeated", Target: configtypes.TargetK8s, Source: "mirror", SupportedVersions: []string{"v1.0.0", "v2.0.0"}},
+	}
+
+	duplicates := findDuplicatePlugins(discovered)
+	assertions.Equal([]DuplicatePlugin{
+		{
+			Name:   "legacy",
+			Target: configtypes.TargetK8s,
+			Sources: []DuplicatePluginSource{
+				{Source: "default", Versions: []string{"v0.1.0"}},
+				{Source: "mirror", Versions: []string{"v0.2.0"}},
+			},
+		},
+		{
+			Name:   "shared",
+			Target: configtypes.TargetTMC,
+			Sources: []DuplicatePluginSource{
+				{Source: "default", Versions: []string{"v1.0.0", "v1.1.0"}},
+				{Source: "mirror", Versions: []string{"v1.1.0"}},
+			},
+		},
+	}, duplicates)
+
+	assertions.Empty(findDuplicatePlugins(discovered[:4]))
+	assertions.Empty(findDuplicatePlugins(nil))
+}
+
+func TestDeleteStandalonePlugins(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	sc, err := catalog.NewContextCatalogUpdater("")
+	assertions.Nil(err)
+	for _, name := range []string{"orphan", "kept"} {
+		err = sc.Upsert(&cli.PluginInfo{Name: name, Target: configtypes.TargetK8s, Version: "v1.0.0", Discovery: "default"})
+		assertions.Nil(err)
+	}
+	sc.Unlock()
+
+	// A context plugin of the same name and target must not be deleted
+	cc, err := catalog.NewContextCatalogUpdater("test-context")
+	assertions.Nil(err)
+	err = cc.Upsert(&cli.PluginInfo{Name: "orphan", Target: configtypes.TargetK8s, Version: "v1.0.0", Discovery: "default"})
+	assertions.Nil(err)
+	cc.Unlock()
+
+	err = DeleteStandalonePlugins([]cli.PluginInfo{{Name: "orphan", Target: configtypes.TargetK8s, Version: "v1.0.0"}})
+	assertions.Nil(err)
 
-	installedServerPlugins, err := pluginsupplier.GetInstalledServerPlugins()
+	standalonePlugins, err := pluginsupplier.GetInstalledStandalonePlugins()
 	assertions.Nil(err)
-	assertions.Equal(len(installedServerPlugins), len(serverPlugins))
+	assertions.Len(standalonePlugins, 1)
+	assertions.Equal("kept", standalonePlugins[0].Name)
 
-	for _, isp := range installedServerPlugins {
-		p := findDiscoveredPlugin(serverPlugins, isp.Name, isp.Target)
-		assertions.NotNil(p)
-	}
+	contextCatalog, err := catalog.NewContextCatalog("test-context")
+	assertions.Nil(err)
+	_, found := contextCatalog.Get(catalog.PluginNameTarget("orphan", configtypes.TargetK8s))
+	assertions.True(found)
 }
 
-func Test_setAvailablePluginsStatus(t *testing.T) {
+func Test_ReconcilePluginsStatus(t *testing.T) {
 	assertions := assert.New(t)
 
 	availablePlugins := []discovery.Discovered{{Name: "fake1", DiscoveryType: "oci", RecommendedVersion: "v1.0.0", Status: common.PluginStatusNotInstalled, Target: configtypes.TargetK8s}}
@@ -731,7 +1612,7 @@ func Test_setAvailablePluginsStatus(t *testing.T) {
 	// If installed plugin is not part of available(discovered) plugins then
 	// installed version == ""
 	// status  == not installed
-	setAvailablePluginsStatus(availablePlugins, installedPlugin)
+	ReconcilePluginsStatus(availablePlugins, installedPlugin)
 	assertions.Equal(len(availablePlugins), 1)
 	assertions.Equal("fake1", availablePlugins[0].Name)
 	assertions.Equal("v1.0.0", availablePlugins[0].RecommendedVersion)
@@ -740,7 +1621,7 @@ func Test_setAvailablePluginsStatus(t *testing.T) {
 
 	// If installed plugin is not part of available(discovered) plugins because of the Target mismatch
 	installedPlugin = []cli.PluginInfo{{Name: "fake1", Version: "v1.0.0", Discovery: "local", DiscoveredRecommendedVersion: "v1.0.0", Target: configtypes.TargetUnknown}}
-	setAvailablePluginsStatus(availablePlugins, installedPlugin)
+	ReconcilePluginsStatus(availablePlugins, installedPlugin)
 	assertions.Equal(len(availablePlugins), 1)
 	assertions.Equal("fake1", availablePlugins[0].Name)
 	assertions.Equal("v1.0.0", availablePlugins[0].RecommendedVersion)
@@ -749,7 +1630,7 @@ func Test_setAvailablePluginsStatus(t *testing.T) {
 
 	// If installed plugin is part of available(discovered) plugins and provided available plugin is already installed
 	installedPlugin = []cli.PluginInfo{{Name: "fake1", Version: "v1.0.0", Discovery: "local", DiscoveredRecommendedVersion: "v1.0.0", Target: configtypes.TargetK8s}}
-	setAvailablePluginsStatus(availablePlugins, installedPlugin)
+	ReconcilePluginsStatus(availablePlugins, installedPlugin)
 	assertions.Equal(len(availablePlugins), 1)
 	assertions.Equal("fake1", availablePlugins[0].Name)
 	assertions.Equal("v1.0.0", availablePlugins[0].RecommendedVersion)
@@ -760,7 +1641,7 @@ func Test_setAvailablePluginsStatus(t *testing.T) {
 	// then available plugin status should show 'update available'
 	availablePlugins = []discovery.Discovered{{Name: "fake1", DiscoveryType: "oci", RecommendedVersion: "v8.0.0-latest", Status: common.PluginStatusNotInstalled}}
 	installedPlugin = []cli.PluginInfo{{Name: "fake1", Version: "v1.0.0", Discovery: "local", DiscoveredRecommendedVersion: "v1.0.0"}}
-	setAvailablePluginsStatus(availablePlugins, installedPlugin)
+	ReconcilePluginsStatus(availablePlugins, installedPlugin)
 	assertions.Equal(len(availablePlugins), 1)
 	assertions.Equal("fake1", availablePlugins[0].Name)
 	assertions.Equal("v8.0.0-latest", availablePlugins[0].RecommendedVersion)
@@ -771,7 +1652,7 @@ func Test_setAvailablePluginsStatus(t *testing.T) {
 	// for the installed plugin(stored as part of catalog cache) then available plugin status should show 'installed'
 	availablePlugins = []discovery.Discovered{{Name: "fake1", DiscoveryType: "oci", RecommendedVersion: "v8.0.0-latest", Status: common.PluginStatusNotInstalled}}
 	installedPlugin = []cli.PluginInfo{{Name: "fake1", Version: "v1.0.0", Discovery: "local", DiscoveredRecommendedVersion: "v8.0.0-latest"}}
-	setAvailablePluginsStatus(availablePlugins, installedPlugin)
+	ReconcilePluginsStatus(availablePlugins, installedPlugin)
 	assertions.Equal(len(availablePlugins), 1)
 	assertions.Equal("fake1", availablePlugins[0].Name)
 	assertions.Equal("v8.0.0-latest", availablePlugins[0].RecommendedVersion)
@@ -782,12 +1663,103 @@ func Test_setAvailablePluginsStatus(t *testing.T) {
 	// it should be reflected in RecommendedVersion as well as InstalledVersion and status should be `update available`
 	availablePlugins[0].Status = common.PluginStatusNotInstalled
 	availablePlugins[0].RecommendedVersion = "v3.0.0"
-	setAvailablePluginsStatus(availablePlugins, installedPlugin)
+	ReconcilePluginsStatus(availablePlugins, installedPlugin)
 	assertions.Equal(len(availablePlugins), 1)
 	assertions.Equal("fake1", availablePlugins[0].Name)
 	assertions.Equal("v3.0.0", availablePlugins[0].RecommendedVersion)
 	assertions.Equal("v1.0.0", availablePlugins[0].InstalledVersion)
 	assertions.Equal(common.PluginStatusUpdateAvailable, availablePlugins[0].Status)
+	assertions.True(availablePlugins[0].IsInstalled())
+	assertions.True(availablePlugins[0].IsUpgradable())
+	assertions.False(availablePlugins[0].IsUpToDate())
+}
+
+func Test_ReconcilePluginsStatusTransitions(t *testing.T) {
+	assertions := assert.New(t)
+
+	newDiscovered := func() []discovery.Discovered {
+		return []discovery.Discovered{{
+			Name:               "fake1",
+			Target:             configtypes.TargetK8s,
+			RecommendedVersion: "v2.0.0",
+			SupportedVersions:  []string{"v1.0.0", "v1.1.0", "v2.0.0"},
+		}}
+	}
+	installedAt := func(version, recommendedVersion string) []cli.PluginInfo {
+		return []cli.PluginInfo{{Name: "fake1", Target: configtypes.TargetK8s, Version: version, DiscoveredRecommendedVersion: recommendedVersion}}
+	}
+
+	tests := []struct {
+		name              string
+		installed         []cli.PluginInfo
+		expectedStatus    string
+		expectedInstalled string
+		isInstalled       bool
+		isUpToDate        bool
+		isUpgradable      bool
+	}{
+		{
+			name:           "not installed",
+			expectedStatus: common.PluginStatusNotInstalled,
+		},
+		{
+			name:              "recommended version installed",
+			installed:         installedAt("v2.0.0", "v2.0.0"),
+			expectedStatus:    common.PluginStatusInstalled,
+			expectedInstalled: "v2.0.0",
+			isInstalled:       true,
+			isUpToDate:        true,
+		},
+		{
+			name:              "recommended version installed but recorded with an older recommended version",
+			installed:         installedAt("v2.0.0", "v1.1.0"),
+			expectedStatus:    common.PluginStatusInstalled,
+			expectedInstalled: "v2.0.0",
+			isInstalled:       true,
+			isUpToDate:        true,
+		},
+		{
+			name:              "older supported version installed",
+			installed:         installedAt("v1.1.0", "v1.1.0"),
+			expectedStatus:    common.PluginStatusUpdateAvailable,
+			expectedInstalled: "v1.1.0",
+			isInstalled:       true,
+			isUpgradable:      true,
+		},
+		{
+			name:              "installed version no longer available",
+			installed:         installedAt("v0.9.0", "v0.9.0"),
+			expectedStatus:    common.PluginStatusOutdated,
+			expectedInstalled: "v0.9.0",
+			isInstalled:       true,
+			isUpgradable:      true,
+		},
+		{
+			name:           "plugin installed for another target",
+			installed:      []cli.PluginInfo{{Name: "fake1", Target: configtypes.TargetTMC, Version: "v2.0.0"}},
+			expectedStatus: common.PluginStatusNotInstalled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discovered := newDiscovered()
+			ReconcilePluginsStatus(discovered, tt.installed)
+			assertions.Equal(tt.expectedStatus, discovered[0].Status)
+			assertions.Equal(tt.expectedInstalled, discovered[0].InstalledVersion)
+			assertions.Equal(tt.isInstalled, discovered[0].IsInstalled())
+			assertions.Equal(tt.isUpToDate, discovered[0].IsUpToDate())
+			assertions.Equal(tt.isUpgradable, discovered[0].IsUpgradable())
+		})
+	}
+
+	// Reconciling again after the plugin is uninstalled resets the status
+	discovered := newDiscovered()
+	ReconcilePluginsStatus(discovered, installedAt("v1.0.0", "v1.0.0"))
+	assertions.Equal(common.PluginStatusUpdateAvailable, discovered[0].Status)
+	ReconcilePluginsStatus(discovered, nil)
+	assertions.Equal(common.PluginStatusNotInstalled, discovered[0].Status)
+	assertions.Empty(discovered[0].InstalledVersion)
 }
 
 func Test_DiscoverPluginsFromLocalSourceBasedOnManifestFile(t *testing.T) {
@@ -1130,6 +2102,115 @@ func TestGetPluginDiscoveries(t *testing.T) {
 	os.Unsetenv(constants.ConfigVariableAdditionalDiscoveryForTesting)
 }
 
+func TestGetPluginDiscoveriesWithProfile(t *testing.T) {
+	assertions := assert.New(t)
+
+	// Setup 2 local discoveries
+	defer setupLocalDistroForTesting()()
+
+	defer os.Unsetenv(constants.ConfigVariablePluginDiscoveryProfile)
+	defer os.Unsetenv(constants.ConfigVariablePluginDiscoveryProfileSourcesPrefix + "STAGING")
+
+	// A profile that is not defined cannot be used
+	os.Setenv(constants.ConfigVariablePluginDiscoveryProfile, "staging")
+	_, err := getPluginDiscoveries()
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "the discovery profile 'staging' is not defined")
+
+	// The discoveries of the profile are used along with the configured discoveries
+	expectedImage := "localhost:9876/staging/discovery/image:v1"
+	os.Setenv(constants.ConfigVariablePluginDiscoveryProfileSourcesPrefix+"STAGING", "staging-disc="+expectedImage)
+	discoveries, err := getPluginDiscoveries()
+	assertions.Nil(err)
+	assertions.Equal(3, len(discoveries))
+	assertions.Equal("default-local", discoveries[0].Local.Name)
+	assertions.Equal("fake", discoveries[1].Local.Name)
+	assertions.Equal("staging-disc", discoveries[2].OCI.Name)
+	assertions.Equal(expectedImage, discoveries[2].OCI.Image)
+
+	// Without a selected profile, only the configured discoveries are used
+	os.Unsetenv(constants.ConfigVariablePluginDiscoveryProfile)
+	discoveries, err = getPluginDiscoveries()
+	assertions.Nil(err)
+	assertions.Equal(2, len(discoveries))
+}
+
+func TestGetPluginDiscoveriesWithPriority(t *testing.T) {
+	assertions := assert.New(t)
+
+	// Setup 2 local discoveries
+	defer setupLocalDistroForTesting()()
+
+	defer os.Unsetenv(constants.ConfigVariablePluginDiscoveryPriorityPrefix + "FAKE")
+	defer os.Unsetenv(constants.ConfigVariablePluginDiscoveryPriorityPrefix + "DEFAULT_LOCAL")
+
+	// The discovery with the highest priority is searched first
+	os.Setenv(constants.ConfigVariablePluginDiscoveryPriorityPrefix+"FAKE", "10")
+	discoveries, err := getPluginDiscoveries()
+	assertions.Nil(err)
+	assertions.Equal(2, len(discoveries))
+	assertions.Equal("fake", discoveries[0].Local.Name)
+	assertions.Equal("default-local", discoveries[1].Local.Name)
+
+	// Discoveries with the same priority keep the configured order
+	os.Setenv(constants.ConfigVariablePluginDiscoveryPriorityPrefix+"DEFAULT_LOCAL", "10")
+	discoveries, err = getPluginDiscoveries()
+	assertions.Nil(err)
+	assertions.Equal("default-local", discoveries[0].Local.Name)
+	assertions.Equal("fake", discoveries[1].Local.Name)
+}
+
+func TestGetRecommendedVersionIncludingPrerelease(t *testing.T) {
+	tcs := []struct {
+		name               string
+		supportedVersions  []string
+		recommendedVersion string
+		expected           string
+	}{
+		{
+			name:               "Keep the recommended version when there is no pre-release version",
+			supportedVersions:  []string{"v1.0.0", "v1.1.0", "v1.2.0"},
+			recommendedVersion: "v1.1.0",
+			expected:           "v1.1.0",
+		},
+		{
+			name:               "Keep the recommended version when it is newer than the pre-release versions",
+			supportedVersions:  []string{"v1.0.0", "v1.1.0-beta.1", "v1.1.0", "v1.2.0"},
+			recommendedVersion: "v1.2.0",
+			expected:           "v1.2.0",
+		},
+		{
+			name:               "Keep the recommended version when a newer stable version follows the pre-release",
+			supportedVersions:  []string{"v1.0.0", "v1.1.0-beta.1", "v1.2.0"},
+			recommendedVersion: "v1.0.0",
+			expected:           "v1.0.0",
+		},
+		{
+			name:               "Use a pre-release version newer than the recommended version",
+			supportedVersions:  []string{"v1.0.0", "v1.1.0", "v1.2.0-beta.1"},
+			recommendedVersion: "v1.1.0",
+			expected:           "v1.2.0-beta.1",
+		},
+		{
+			name:               "Use the pre-release version when there is no recommended version",
+			supportedVersions:  []string{"v1.0.0-alpha.1", "v1.0.0-beta.1"},
+			recommendedVersion: "",
+			expected:           "v1.0.0-beta.1",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &discovery.Discovered{
+				Name:               "plugin",
+				SupportedVersions:  tc.supportedVersions,
+				RecommendedVersion: tc.recommendedVersion,
+			}
+			assert.Equal(t, tc.expected, getRecommendedVersionIncludingPrerelease(p))
+		})
+	}
+}
+
 func TestMergeDuplicatePlugins(t *testing.T) {
 	assertions := assert.New(t)
 
@@ -1470,6 +2551,201 @@ func TestMergeDuplicatePluginsWithReplacedVersion(t *testing.T) {
 	assertions.Equal(expectedPlugin, mergedPlugins[0])
 }
 
+func TestMergeDuplicatePluginsWithDeprecation(t *testing.T) {
+	assertions := assert.New(t)
+
+	artifact := func(version string) []distribution.Artifact {
+		return []distribution.Artifact{{Image: "localhost:9876/my/discovery/linux_amd64:" + version, Digest: "digest", OS: "linux", Arch: "amd64"}}
+	}
+	preMergePlugins := []discovery.Discovered{
+		{
+			Name:               "myplugin",
+			Target:             configtypes.TargetK8s,
+			RecommendedVersion: "v2.0.0",
+			SupportedVersions:  []string{"v1.0.0", "v2.0.0"},
+			Distribution:       distribution.Artifacts{"v1.0.0": artifact("v1.0.0"), "v2.0.0": artifact("v2.0.0")},
+			DeprecatedVersions: map[string]string{"v1.0.0": "please upgrade"},
+			Source:             "discovery1",
+			DiscoveryType:      common.DiscoveryTypeOCI,
+		},
+		{
+			Name:               "myplugin",
+			Target:             configtypes.TargetK8s,
+			RecommendedVersion: "v0.1.0",
+			SupportedVersions:  []string{"v0.1.0"},
+			Distribution:       distribution.Artifacts{"v0.1.0": artifact("v0.1.0")},
+			Deprecated:         true,
+			DeprecationMessage: "no longer maintained",
+			Source:             "discovery2",
+			DiscoveryType:      common.DiscoveryTypeOCI,
+		},
+	}
+
+	mergedPlugins := mergeDuplicatePlugins(preMergePlugins)
+	assertions.Equal(1, len(mergedPlugins))
+	p := mergedPlugins[0]
+
+	// The deprecation of the whole second plugin only applies to the versions it provided
+	assertions.False(p.Deprecated)
+	deprecated, message := p.GetDeprecation("v0.1.0")
+	assertions.True(deprecated)
+	assertions.Equal("no longer maintained", message)
+	deprecated, message = p.GetDeprecation("v1.0.0")
+	assertions.True(deprecated)
+	assertions.Equal("please upgrade", message)
+	deprecated, _ = p.GetDeprecation("v2.0.0")
+	assertions.False(deprecated)
+
+	// The deprecation of the whole first plugin does not apply to the versions of the second one
+	preMergePlugins = []discovery.Discovered{
+		{
+			Name:               "myplugin",
+			Target:             configtypes.TargetK8s,
+			RecommendedVersion: "v0.1.0",
+			SupportedVersions:  []string{"v0.1.0"},
+			Distribution:       distribution.Artifacts{"v0.1.0": artifact("v0.1.0")},
+			Deprecated:         true,
+			DeprecationMessage: "no longer maintained",
+			Source:             "discovery1",
+			DiscoveryType:      common.DiscoveryTypeOCI,
+		},
+		{
+			Name:               "myplugin",
+			Target:             configtypes.TargetK8s,
+			RecommendedVersion: "v2.0.0",
+			SupportedVersions:  []string{"v1.0.0", "v2.0.0"},
+			Distribution:       distribution.Artifacts{"v1.0.0": artifact("v1.0.0"), "v2.0.0": artifact("v2.0.0")},
+			Source:             "discovery2",
+			DiscoveryType:      common.DiscoveryTypeOCI,
+		},
+	}
+	mergedPlugins = mergeDuplicatePlugins(preMergePlugins)
+	assertions.Equal(1, len(mergedPlugins))
+	p = mergedPlugins[0]
+
+	assertions.False(p.Deprecated)
+	deprecated, message = p.GetDeprecation("v0.1.0")
+	assertions.True(deprecated)
+	assertions.Equal("no longer maintained", message)
+	deprecated, _ = p.GetDeprecation("v1.0.0")
+	assertions.False(deprecated)
+	deprecated, _ = p.GetDeprecation("v2.0.0")
+	assertions.False(deprecated)
+}
+
+func Test_checkCLICompatibility(t *testing.T) {
+	assertions := assert.New(t)
+
+	origVersion := buildinfo.Version
+	defer func() { buildinfo.Version = origVersion }()
+	buildinfo.Version = "v1.1.0"
+
+	p := &discovery.Discovered{
+		Name:              "myplugin",
+		Target:            configtypes.TargetK8s,
+		SupportedVersions: []string{"v1.0.0", "v2.0.0", "v3.0.0"},
+		MinCLIVersions:    map[string]string{"v2.0.0": "v1.1.0", "v3.0.0": "v1.2.0"},
+	}
+
+	// Versions without a requirement or requiring an older CLI can be installed
+	assertions.Nil(checkCLICompatibility(p, "v1.0.0", false))
+	assertions.Nil(checkCLICompatibility(p, "v2.0.0", false))
+
+	err := checkCLICompatibility(p, "v3.0.0", false)
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "plugin 'myplugin:v3.0.0' requires version v1.2.0 or later of the Tanzu CLI but the CLI version is v1.1.0")
+
+	// Forcing the installation only warns
+	assertions.Nil(checkCLICompatibility(p, "v3.0.0", true))
+
+	// A CLI whose version is not a valid semantic version is not checked
+	buildinfo.Version = "dev"
+	assertions.Nil(checkCLICompatibility(p, "v3.0.0", false))
+}
+
+func TestMergeDuplicatePluginsWithCLICompatibility(t *testing.T) {
+	assertions := assert.New(t)
+
+	artifact := func(version string) []distribution.Artifact {
+		return []distribution.Artifact{{Image: "localhost:9876/my/discovery/linux_amd64:" + version, Digest: "digest", OS: "linux", Arch: "amd64"}}
+	}
+	preMergePlugins := []discovery.Discovered{
+		{
+			Name:               "myplugin",
+			Target:             configtypes.TargetK8s,
+			RecommendedVersion: "v1.0.0",
+			SupportedVersions:  []string{"v1.0.0"},
+			Distribution:       distribution.Artifacts{"v1.0.0": artifact("v1.0.0")},
+			Source:             "discovery1",
+			DiscoveryType:      common.DiscoveryTypeOCI,
+		},
+		{
+			Name:               "myplugin",
+			Target:             configtypes.TargetK8s,
+			RecommendedVersion: "v2.0.0",
+			SupportedVersions:  []string{"v1.0.0", "v2.0.0"},
+			Distribution:       distribution.Artifacts{"v1.0.0": artifact("v1.0.0"), "v2.0.0": artifact("v2.0.0")},
+			MinCLIVersions:     map[string]string{"v1.0.0": "v1.1.0", "v2.0.0": "v1.2.0"},
+			Source:             "discovery2",
+			DiscoveryType:      common.DiscoveryTypeOCI,
+		},
+	}
+
+	mergedPlugins := mergeDuplicatePlugins(preMergePlugins)
+	assertions.Equal(1, len(mergedPlugins))
+
+	// Only the requirement of the version added by the second plugin is kept
+	assertions.Equal(map[string]string{"v2.0.0": "v1.2.0"}, mergedPlugins[0].MinCLIVersions)
+}
+
+func TestMergeDuplicatePluginsWithPriority(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer os.Unsetenv(constants.ConfigVariablePluginDiscoveryPriorityPrefix + "MIRROR")
+
+	artifact := func(source, version string) []distribution.Artifact {
+		return []distribution.Artifact{{Image: "localhost:9876/" + source + "/linux_amd64:" + version, Digest: "digest", OS: "linux", Arch: "amd64"}}
+	}
+	newPreMergePlugins := func() []discovery.Discovered {
+		return []discovery.Discovered{
+			{
+				Name:               "myplugin",
+				Target:             configtypes.TargetK8s,
+				RecommendedVersion: "v1.1.0",
+				SupportedVersions:  []string{"v1.0.0", "v1.1.0"},
+				Distribution:       distribution.Artifacts{"v1.0.0": artifact("upstream", "v1.0.0"), "v1.1.0": artifact("upstream", "v1.1.0")},
+				Source:             "upstream",
+			},
+			{
+				Name:               "myplugin",
+				Target:             configtypes.TargetK8s,
+				RecommendedVersion: "v1.0.0",
+				SupportedVersions:  []string{"v1.0.0"},
+				Distribution:       distribution.Artifacts{"v1.0.0": artifact("mirror", "v1.0.0")},
+				Source:             "mirror",
+			},
+		}
+	}
+
+	// With the same priority, the first plugin found wins and the highest version is recommended
+	mergedPlugins := mergeDuplicatePlugins(newPreMergePlugins())
+	assertions.Equal(1, len(mergedPlugins))
+	assertions.Equal("v1.1.0", mergedPlugins[0].RecommendedVersion)
+	a, err := mergedPlugins[0].Distribution.DescribeArtifact("v1.0.0", "linux", "amd64")
+	assertions.Nil(err)
+	assertions.Equal("localhost:9876/upstream/linux_amd64:v1.0.0", a.Image)
+
+	// The source with the highest priority wins even if it is found last
+	os.Setenv(constants.ConfigVariablePluginDiscoveryPriorityPrefix+"MIRROR", "10")
+	mergedPlugins = mergeDuplicatePlugins(newPreMergePlugins())
+	assertions.Equal(1, len(mergedPlugins))
+	assertions.Equal("v1.0.0", mergedPlugins[0].RecommendedVersion)
+	assertions.Equal([]string{"v1.0.0", "v1.1.0"}, mergedPlugins[0].SupportedVersions)
+	a, err = mergedPlugins[0].Distribution.DescribeArtifact("v1.0.0", "linux", "amd64")
+	assertions.Nil(err)
+	assertions.Equal("localhost:9876/mirror/linux_amd64:v1.0.0", a.Image)
+}
+
 func TestMergeDuplicateGroups(t *testing.T) {
 	assertions := assert.New(t)
 
@@ -1722,3 +2998,227 @@ func TestClean(t *testing.T) {
 	_, err = os.Stat(common.DefaultPluginRoot)
 	assertions.True(errors.Is(err, os.ErrNotExist))
 }
+
+func TestCleanWithFilters(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	assertions.Nil(InstallStandalonePlugin("login", "v0.2.0", configtypes.TargetUnknown))
+	assertions.Nil(InstallStandalonePlugin("myplugin", "v1.6.0", configtypes.TargetK8s))
+	assertions.Nil(InstallStandalonePlugin("myplugin", cli.VersionLatest, configtypes.TargetTMC))
+
+	// Nothing matches a discovery source without installed plugins
+	err := Clean(WithDiscoverySource("other"))
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to find any installed plugins from discovery source 'other'")
+
+	// Only the plugins of the target are removed
+	err = Clean(WithTarget(configtypes.TargetTMC))
+	assertions.Nil(err)
+	assertions.True(checkPluginIsInstalled("login", configtypes.TargetGlobal))
+	assertions.True(checkPluginIsInstalled("myplugin", configtypes.TargetK8s))
+	assertions.False(checkPluginIsInstalled("myplugin", configtypes.TargetTMC))
+
+	err = Clean(WithTarget(configtypes.TargetTMC))
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to find any installed plugins for target 'mission-control'")
+
+	// Only the plugins installed from the discovery source are removed,
+	// and the cached inventory of the discovery source is kept
+	inventoryDir := discovery.GetInventoryCacheDirs("default")[0]
+	assertions.Nil(os.MkdirAll(inventoryDir, 0755))
+	err = Clean(WithTarget(configtypes.TargetK8s), WithDiscoverySource("default"))
+	assertions.Nil(err)
+	assertions.True(checkPluginIsInstalled("login", configtypes.TargetGlobal))
+	assertions.False(checkPluginIsInstalled("myplugin", configtypes.TargetK8s))
+	_, err = os.Stat(inventoryDir)
+	assertions.Nil(err)
+}
+
+func TestPruneUnusedInventoryCache(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	// Create the cached inventories of a context discovery source and of sources no longer used
+	inventoryDir := filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName)
+	for _, name := range []string{"fake-mgmt", "stale", "stale@PROFILE"} {
+		assertions.Nil(os.MkdirAll(filepath.Join(inventoryDir, name), 0755))
+		assertions.Nil(os.WriteFile(filepath.Join(inventoryDir, name, plugininventory.SQliteDBFileName), []byte("db"), 0644))
+	}
+
+	assertions.Nil(InstallStandalonePlugin("login", "v0.2.0", configtypes.TargetUnknown))
+
+	removed, err := PruneUnusedInventoryCache()
+	assertions.Nil(err)
+	assertions.ElementsMatch([]string{"stale", "stale@PROFILE"}, removed)
+	assertions.DirExists(filepath.Join(inventoryDir, config.DefaultStandaloneDiscoveryName))
+	assertions.DirExists(filepath.Join(inventoryDir, "fake-mgmt"))
+
+	// The cache of a discovery source no longer configured is kept while a plugin installed from it remains
+	assertions.Nil(configlib.DeleteCLIDiscoverySource(config.DefaultStandaloneDiscoveryName))
+	removed, err = PruneUnusedInventoryCache()
+	assertions.Nil(err)
+	assertions.Empty(removed)
+
+	err = DeletePlugin(DeletePluginOptions{PluginName: "login", Target: configtypes.TargetUnknown, ForceDelete: true, PruneCache: true})
+	assertions.Nil(err)
+	assertions.NoDirExists(filepath.Join(inventoryDir, config.DefaultStandaloneDiscoveryName))
+	assertions.DirExists(filepath.Join(inventoryDir, "fake-mgmt"))
+}
+
+func TestPrefetchDiscoverySources(t *testing.T) {
+	assertions := assert.New(t)
+
+	// The test setup only uses the cache so no registry is accessed
+	defer setupPluginSourceForTesting()()
+
+	for _, parallel := range []bool{false, true} {
+		results, err := PrefetchDiscoverySources(parallel)
+		assertions.Nil(err)
+		assertions.Equal(1, len(results))
+		assertions.Equal("default", results[0].Source)
+		assertions.Equal("example.com/plugin-inventory:latest", results[0].Image)
+		assertions.Nil(results[0].Err)
+	}
+
+	// Without OCI discovery sources
+	err := configlib.DeleteCLIDiscoverySource("default")
+	assertions.Nil(err)
+	_, err = PrefetchDiscoverySources(false)
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), errorNoDiscoverySourcesFound)
+}
+
+// fakePostInstallExecCommand returns an execCommand replacement running a post-install
+// command with the specified behavior through TestPostInstallHelperProcess
+func fakePostInstallExecCommand(behavior string) func(command string, args ...string) *exec.Cmd {
+	return func(command string, args ...string) *exec.Cmd {
+		cs := []string{"-test.run=TestPostInstallHelperProcess", "--", command}
+		cs = append(cs, args...)
+		cmd := exec.Command(os.Args[0], cs...) //nolint:gosec
+		cmd.Env = []string{"GO_WANT_POST_INSTALL_HELPER_PROCESS=1", "POST_INSTALL_BEHAVIOR=" + behavior}
+		return cmd
+	}
+}
+
+func TestPostInstallHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_POST_INSTALL_HELPER_PROCESS") != "1" {
+		return
+	}
+	switch os.Getenv("POST_INSTALL_BEHAVIOR") {
+	case "succeed":
+		fmt.Fprint(os.Stdout, "plugin initialized")
+		os.Exit(0)
+	case "fail":
+		fmt.Fprint(os.Stderr, "unable to write the default configuration")
+		os.Exit(1)
+	case "unknown":
+		fmt.Fprint(os.Stderr, `Error: unknown command "post-install" for "tanzu-plugin"`)
+		os.Exit(1)
+	case "hang":
+		time.Sleep(time.Minute)
+	case "sandbox":
+		wd, _ := os.Getwd()
+		fmt.Fprintln(os.Stdout, wd)
+		fmt.Fprint(os.Stdout, strings.Join(os.Environ(), "\n"))
+		os.Exit(0)
+	}
+	os.Exit(2)
+}
+
+func TestRunPostInstallHook(t *testing.T) {
+	assertions := assert.New(t)
+	defer func() { execCommand = exec.Command }()
+	plugin := &cli.PluginInfo{Name: "myplugin", InstallationPath: filepath.Join(t.TempDir(), "myplugin")}
+
+	execCommand = fakePostInstallExecCommand("succeed")
+	output, err := runPostInstallHook(plugin)
+	assertions.Nil(err)
+	assertions.Equal("plugin initialized", output)
+
+	execCommand = fakePostInstallExecCommand("fail")
+	output, err = runPostInstallHook(plugin)
+	assertions.NotNil(err)
+	assertions.Contains(output, "unable to write the default configuration")
+	// A failure is reported but does not fail the installation
+	assertions.Nil(InitializePlugin(plugin))
+
+	execCommand = fakePostInstallExecCommand("unknown")
+	_, err = runPostInstallHook(plugin)
+	assertions.NotNil(err)
+	assertions.Nil(InitializePlugin(plugin))
+
+	origTimeout := pluginPostInstallTimeout
+	defer func() { pluginPostInstallTimeout = origTimeout }()
+	pluginPostInstallTimeout = 200 * time.Millisecond
+
+	execCommand = fakePostInstallExecCommand("hang")
+	start := time.Now()
+	_, err = runPostInstallHook(plugin)
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "the post-install command of plugin \"myplugin\" did not complete within 200ms")
+	assertions.Less(time.Since(start), 30*time.Second)
+}
+
+func TestRunPostInstallHookSandbox(t *testing.T) {
+	assertions := assert.New(t)
+	defer func() { execCommand = exec.Command }()
+
+	installDir, err := filepath.EvalSymlinks(t.TempDir())
+	assertions.Nil(err)
+	plugin := &cli.PluginInfo{Name: "myplugin", InstallationPath: filepath.Join(installDir, "myplugin")}
+
+	t.Setenv("TANZU_CONFIG", "/path/to/config.yaml")
+	t.Setenv(constants.ConfigVariablePluginDiscoveryTokenPrefix+"DEFAULT", "secret-token")
+	t.Setenv("POST_INSTALL_UNRELATED", "unrelated")
+
+	execCommand = fakePostInstallExecCommand("sandbox")
+	output, err := runPostInstallHook(plugin)
+	assertions.Nil(err)
+
+	lines := strings.Split(output, "\n")
+	wd, err := filepath.EvalSymlinks(lines[0])
+	assertions.Nil(err)
+	assertions.Equal(installDir, wd)
+	assertions.Contains(lines, "TANZU_CONFIG=/path/to/config.yaml")
+	assertions.NotContains(output, "secret-token")
+	assertions.NotContains(output, "POST_INSTALL_UNRELATED")
+}
+
+func Test_VerifyPluginSourceSignature(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	plugin := &cli.PluginInfo{Name: "myplugin", Target: configtypes.TargetK8s, Version: "v1.0.0"}
+	verification := VerifyPluginSourceSignature(plugin)
+	assertions.Equal(PluginSignatureStatusUnavailable, verification.Status)
+	assertions.Contains(verification.Reason, "not installed from a discovery source")
+
+	plugin.Discovery = "unknown"
+	verification = VerifyPluginSourceSignature(plugin)
+	assertions.Equal(PluginSignatureStatusUnavailable, verification.Status)
+	assertions.Contains(verification.Reason, "'unknown' is no longer configured")
+
+	// The verification of the signature of the discovery image is skipped
+	// so that no registry is accessed
+	image := "example.com/plugin-inventory:latest"
+	os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, image)
+	os.Setenv(constants.SuppressSkipSignatureVerificationWarning, "true")
+	defer os.Unsetenv(constants.PluginDiscoveryImageSignatureVerificationSkipList)
+	defer os.Unsetenv(constants.SuppressSkipSignatureVerificationWarning)
+
+	plugin.Discovery = "default"
+	verification = VerifyPluginSourceSignature(plugin)
+	assertions.Equal("default", verification.Source)
+	assertions.Equal(image, verification.Image)
+	assertions.Equal(PluginSignatureStatusSkipped, verification.Status)
+	assertions.Contains(verification.Reason, constants.PluginDiscoveryImageSignatureVerificationSkipList)
+	assertions.Empty(verification.Signer)
+}
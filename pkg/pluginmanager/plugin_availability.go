@@ -0,0 +1,154 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/artifact"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/interrupt"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+// The statuses of the availability check of the binary of a plugin
+const (
+	// PluginAvailabilityStatusAvailable means the binary of the plugin can be downloaded
+	PluginAvailabilityStatusAvailable = "available"
+	// PluginAvailabilityStatusUnavailable means the binary of the plugin cannot be downloaded
+	PluginAvailabilityStatusUnavailable = "unavailable"
+)
+
+// artifactAvailabilityTimeout bounds the check of an artifact served over HTTP
+const artifactAvailabilityTimeout = 30 * time.Second
+
+// getArtifactImageDigest resolves the digest of the image of an artifact.
+// It is a variable so that the tests can replace it.
+var getArtifactImageDigest = carvelhelpers.GetImageDigest
+
+// PluginAvailability is the outcome of checking that the binary of a version
+// of a plugin can be downloaded for the current platform
+type PluginAvailability struct {
+	Name    string             `json:"name" yaml:"name"`
+	Version string             `json:"version" yaml:"version"`
+	Target  configtypes.Target `json:"target" yaml:"target"`
+	// Platform is the platform of the binary, in the "os/arch" format
+	Platform string `json:"platform" yaml:"platform"`
+	// Artifact is the image or the URI of the binary, empty if the version has no binary for the platform
+	Artifact string `json:"artifact" yaml:"artifact"`
+	// Status is one of the PluginAvailabilityStatus constants
+	Status string `json:"status" yaml:"status"`
+	// Reason explains why the binary cannot be downloaded
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// CheckPluginAvailability checks, without downloading it, that the binary of the
+// specified version of a plugin can be downloaded for the current platform, e.g.,
+// to find a mirror missing the image of a binary that its inventory refers to.
+// The recommended version of the plugin is checked when no version is specified.
+// An error is only returned if the version of the plugin cannot be found.
+func CheckPluginAvailability(pluginName string, target configtypes.Target, version string) (*PluginAvailability, error) {
+	p, err := findAvailablePlugin(pluginName, target)
+	if err != nil {
+		return nil, err
+	}
+	if version == "" {
+		version = p.RecommendedVersion
+	}
+	if !utils.ContainsString(p.SupportedVersions, version) {
+		return nil, errors.Errorf("unable to find version '%v' of plugin '%v', the available versions are: %s", version, pluginName, strings.Join(p.SupportedVersions, ", "))
+	}
+
+	availability := &PluginAvailability{
+		Name:     p.Name,
+		Version:  version,
+		Target:   p.Target,
+		Platform: cli.GOOS + "/" + cli.GOARCH,
+		Status:   PluginAvailabilityStatusUnavailable,
+	}
+
+	a, err := p.Distribution.DescribeArtifact(version, cli.GOOS, cli.GOARCH)
+	if err != nil && cli.BuildArch() == cli.DarwinARM64 {
+		// The installation falls back to the AMD64 binary run through Rosetta
+		if a, err = p.Distribution.DescribeArtifact(version, cli.GOOS, cli.DarwinAMD64.Arch()); err == nil {
+			availability.Platform = cli.GOOS + "/" + cli.DarwinAMD64.Arch()
+		}
+	}
+	if err != nil {
+		availability.Reason = fmt.Sprintf("the version provides no binary for the %s platform", availability.Platform)
+		return availability, nil
+	}
+
+	availability.Artifact = a.Image
+	if availability.Artifact == "" {
+		availability.Artifact = a.URI
+	}
+	if err := checkArtifactAvailability(&a); err != nil {
+		availability.Reason = err.Error()
+		return availability, nil
+	}
+	availability.Status = PluginAvailabilityStatusAvailable
+	return availability, nil
+}
+
+// checkArtifactAvailability returns an error if the binary of the artifact cannot be
+// downloaded.  The digest of an image is resolved, which confirms that the registry
+// serves it, while the existence of a file is checked and an HTTP URI is requested
+// with the HEAD method.
+func checkArtifactAvailability(a *distribution.Artifact) error {
+	if a.Image != "" {
+		if _, _, err := getArtifactImageDigest(a.Image); err != nil {
+			return errors.Wrapf(err, "unable to resolve the image '%s'", a.Image)
+		}
+		return nil
+	}
+	if a.URI == "" {
+		return errors.New("the artifact has neither an image nor a URI")
+	}
+
+	uriArtifact, err := artifact.NewURIArtifact(a.URI)
+	if err != nil {
+		return errors.Wrapf(err, "invalid URI '%s'", a.URI)
+	}
+	switch u := uriArtifact.(type) {
+	case *artifact.HTTPArtifact:
+		return checkHTTPArtifactAvailability(u.URL)
+	case *artifact.LocalArtifact:
+		if _, err := os.Stat(u.Path); err != nil {
+			return errors.Wrapf(err, "unable to find the file of URI '%s'", a.URI)
+		}
+	}
+	return nil
+}
+
+// checkHTTPArtifactAvailability returns an error if the URL cannot be downloaded
+func checkHTTPArtifactAvailability(artifactURL string) error {
+	ctx, cancel := context.WithTimeout(interrupt.Context(), artifactAvailabilityTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, artifactURL, http.NoBody)
+	if err != nil {
+		return err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "unable to reach '%s'", artifactURL)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("'%s' responded with status code %d", artifactURL, res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+func TestCheckPluginAvailability(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+	defer func() { getArtifactImageDigest = carvelhelpers.GetImageDigest }()
+
+	var resolvedImage string
+	getArtifactImageDigest = func(image string) (string, string, error) {
+		resolvedImage = image
+		return "sha256", "1234", nil
+	}
+
+	// The recommended version is checked by default
+	availability, err := CheckPluginAvailability("login", configtypes.TargetUnknown, "")
+	assertions.Nil(err)
+	assertions.Equal("login", availability.Name)
+	assertions.Equal("v0.20.0", availability.Version)
+	assertions.Equal(configtypes.TargetGlobal, availability.Target)
+	assertions.Equal(PluginAvailabilityStatusAvailable, availability.Status)
+	assertions.Empty(availability.Reason)
+	assertions.NotEmpty(availability.Artifact)
+	assertions.Equal(availability.Artifact, resolvedImage)
+
+	// An image missing from the registry is reported with the reason
+	getArtifactImageDigest = func(image string) (string, string, error) {
+		return "", "", errors.New("MANIFEST_UNKNOWN")
+	}
+	availability, err = CheckPluginAvailability("login", configtypes.TargetGlobal, "v0.2.0")
+	assertions.Nil(err)
+	assertions.Equal("v0.2.0", availability.Version)
+	assertions.Equal(PluginAvailabilityStatusUnavailable, availability.Status)
+	assertions.Contains(availability.Reason, "MANIFEST_UNKNOWN")
+
+	_, err = CheckPluginAvailability("login", configtypes.TargetUnknown, "v9.9.9")
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to find version 'v9.9.9' of plugin 'login'")
+
+	_, err = CheckPluginAvailability("login", configtypes.TargetTMC, "")
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to find plugin 'login' for target 'mission-control' in the discovery sources")
+}
+
+func TestCheckArtifactAvailability(t *testing.T) {
+	assertions := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertions.Equal(http.MethodHead, r.Method)
+		if r.URL.Path != "/tanzu-login" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	assertions.Nil(checkArtifactAvailability(&distribution.Artifact{URI: server.URL + "/tanzu-login"}))
+	err := checkArtifactAvailability(&distribution.Artifact{URI: server.URL + "/missing"})
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "responded with status code 404")
+
+	binary := filepath.Join(t.TempDir(), "tanzu-login")
+	assertions.Nil(os.WriteFile(binary, []byte("binary"), 0755))
+	assertions.Nil(checkArtifactAvailability(&distribution.Artifact{URI: binary}))
+	assertions.Nil(checkArtifactAvailability(&distribution.Artifact{URI: "file://" + binary}))
+	assertions.NotNil(checkArtifactAvailability(&distribution.Artifact{URI: binary + "-missing"}))
+
+	assertions.NotNil(checkArtifactAvailability(&distribution.Artifact{}))
+}
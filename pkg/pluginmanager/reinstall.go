@@ -0,0 +1,216 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/component"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+// ReinstallPluginOptions specifies the standalone plugin to reinstall
+type ReinstallPluginOptions struct {
+	PluginName string
+	Target     configtypes.Target
+	// ForceReinstall reinstalls the plugin without asking for confirmation
+	ForceReinstall bool
+}
+
+// installedPluginBackup is what is needed to restore an uninstalled plugin
+type installedPluginBackup struct {
+	plugin cli.PluginInfo
+	// journal records the reinstallation so that the plugin is restored by the next
+	// CLI run if the CLI is interrupted before the plugin is installed again
+	journal *journaledOperation
+}
+
+// ReinstallPlugin uninstalls the specified standalone plugin, if installed, and installs
+// it again, downloading its binary anew; e.g., to recover a corrupt plugin binary.
+// The installed version of the plugin is reinstalled, to the same installation directory,
+// or the recommended version if the plugin is not installed.  If the installation fails,
+// the uninstalled plugin is restored so that the plugin is never left uninstalled.
+func ReinstallPlugin(options ReinstallPluginOptions, installOptions ...PluginManagerOptions) (*InstallResult, error) {
+	installed, err := findInstalledStandalonePlugin(options.PluginName, options.Target)
+	if err != nil {
+		return nil, err
+	}
+	if installed == nil {
+		clilog.Infof("Plugin '%s' is not installed, installing its recommended version", options.PluginName)
+		return InstallStandalonePluginWithResult(options.PluginName, cli.VersionLatest, options.Target, append(installOptions, WithReinstall(true))...)
+	}
+
+	if !options.ForceReinstall {
+		if err := component.AskForConfirmation(
+			fmt.Sprintf("Reinstalling version '%s' of plugin '%s' for target '%s'. Are you sure?",
+				installed.Version, installed.Name, string(installed.Target))); err != nil {
+			return nil, err
+		}
+	}
+
+	// The plugin is reinstalled to its installation directory
+	installOptions = append(installOptions, WithReinstall(true))
+	if isCustomInstallation(installed.InstallationPath) {
+		installOptions = append(installOptions, WithInstallDir(filepath.Dir(filepath.Dir(installed.InstallationPath))))
+	}
+
+	backup, err := uninstallStandalonePlugin(installed)
+	if err != nil {
+		return nil, err
+	}
+	result, err := InstallStandalonePluginWithResult(installed.Name, installed.Version, installed.Target, installOptions...)
+	if err != nil {
+		if restoreErr := restoreStandalonePlugin(backup); restoreErr != nil {
+			// The journal is kept so that the next CLI run tries to restore the plugin again
+			return nil, errors.Wrapf(err, "unable to reinstall plugin '%s' nor to restore its previous installation (%v)", installed.Name, restoreErr)
+		}
+		backup.journal.complete()
+		return nil, errors.Wrapf(err, "unable to reinstall plugin '%s', its previous installation was restored", installed.Name)
+	}
+	removeReinstallBackup(&backup.plugin)
+	backup.journal.complete()
+	return result, nil
+}
+
+// findInstalledStandalonePlugin returns the installed standalone plugin with the specified
+// name and target, or nil if there is none.  An error is returned if the plugin is installed
+// for more than one target and no target is specified.
+func findInstalledStandalonePlugin(pluginName string, target configtypes.Target) (*cli.PluginInfo, error) {
+	installedPlugins, err := pluginsupplier.GetInstalledStandalonePlugins()
+	if err != nil {
+		return nil, err
+	}
+	var matchedPlugins []*cli.PluginInfo
+	for i := range installedPlugins {
+		if installedPlugins[i].Name == pluginName && (target == configtypes.TargetUnknown || installedPlugins[i].Target == target) {
+			matchedPlugins = append(matchedPlugins, &installedPlugins[i])
+		}
+	}
+	if len(matchedPlugins) > 1 {
+		return nil, errors.Errorf(missingTargetStr, pluginName)
+	}
+	if len(matchedPlugins) == 0 {
+		return nil, nil
+	}
+	return matchedPlugins[0], nil
+}
+
+// getReinstallBackupPath returns the path of the copy of the binary of a plugin
+// which is kept while the plugin is reinstalled
+func getReinstallBackupPath(plugin *cli.PluginInfo) string {
+	return plugin.InstallationPath + ".reinstall-backup"
+}
+
+// uninstallStandalonePlugin removes the standalone plugin from the catalog and returns
+// what is needed to restore it.  The binary of the plugin is copied aside as the
+// installation of the same version of the plugin replaces it.  The uninstallation is
+// journaled so that an interruption of the reinstallation does not leave the plugin
+// uninstalled.
+func uninstallStandalonePlugin(plugin *cli.PluginInfo) (*installedPluginBackup, error) {
+	backup := &installedPluginBackup{plugin: *plugin}
+	backup.journal = startJournaledOperation(journalOperationReinstall, "", plugin)
+	if b, err := os.ReadFile(plugin.InstallationPath); err != nil {
+		log.V(4).Infof("Unable to read the binary of plugin '%s', it cannot be restored: %v", plugin.Name, err)
+	} else if err := os.WriteFile(getReinstallBackupPath(plugin), b, 0755); err != nil {
+		log.V(4).Infof("Unable to back up the binary of plugin '%s', it cannot be restored: %v", plugin.Name, err)
+	}
+
+	c, err := catalog.NewContextCatalogUpdater("")
+	if err != nil {
+		removeReinstallBackup(plugin)
+		backup.journal.complete()
+		return nil, err
+	}
+	err = c.Delete(catalog.PluginNameTarget(plugin.Name, plugin.Target))
+	c.Unlock()
+	if err != nil {
+		removeReinstallBackup(plugin)
+		backup.journal.complete()
+		return nil, errors.Wrapf(err, "unable to uninstall plugin '%s'", plugin.Name)
+	}
+
+	clilog.Infof("Uninstalling plugin '%s' for target '%s'", plugin.Name, plugin.Target)
+	deletePluginFromCommandTreeCache(plugin)
+	removeCustomPluginBinary(plugin.InstallationPath)
+	publishPluginsDeleted([]cli.PluginInfo{*plugin})
+	return backup, nil
+}
+
+// restoreStandalonePlugin installs again the standalone plugin uninstalled
+// by uninstallStandalonePlugin, along with its binary
+func restoreStandalonePlugin(backup *installedPluginBackup) error {
+	plugin := &backup.plugin
+	if err := restoreReinstalledPlugin(plugin); err != nil {
+		return err
+	}
+	clilog.Infof("Restored version '%s' of plugin '%s' for target '%s'", plugin.Version, plugin.Name, plugin.Target)
+	return nil
+}
+
+// restoreReinstalledPlugin puts back the backup of the binary of a plugin being
+// reinstalled, if any, and registers the plugin in the catalog of the standalone plugins
+func restoreReinstalledPlugin(plugin *cli.PluginInfo) error {
+	backupPath := getReinstallBackupPath(plugin)
+	if _, err := os.Stat(backupPath); err == nil {
+		if err := os.MkdirAll(filepath.Dir(plugin.InstallationPath), os.ModePerm); err != nil {
+			return err
+		}
+		if err := os.Rename(backupPath, plugin.InstallationPath); err != nil {
+			return errors.Wrap(err, "could not restore the binary of the plugin")
+		}
+	}
+
+	c, err := catalog.NewContextCatalogUpdater("")
+	if err != nil {
+		return err
+	}
+	err = c.Upsert(plugin)
+	c.Unlock()
+	if err != nil {
+		return err
+	}
+	addPluginToCommandTreeCache(plugin)
+	return nil
+}
+
+// removeReinstallBackup removes the backup of the binary of a reinstalled plugin
+func removeReinstallBackup(plugin *cli.PluginInfo) {
+	if err := os.Remove(getReinstallBackupPath(plugin)); err != nil && !os.IsNotExist(err) {
+		log.V(4).Infof("Unable to remove the backup of the binary of plugin '%s': %v", plugin.Name, err)
+	}
+}
+
+// completeInterruptedReinstallation restores the standalone plugin whose reinstallation
+// was interrupted after the plugin was uninstalled and before it was installed again.
+// The backup of its binary is only removed if the plugin was installed again.
+func completeInterruptedReinstallation(plugin *cli.PluginInfo) error {
+	c, err := catalog.NewContextCatalogUpdater("")
+	if err != nil {
+		return err
+	}
+	_, installed := c.Get(catalog.PluginNameTarget(plugin.Name, plugin.Target))
+	c.Unlock()
+	if installed {
+		removeReinstallBackup(plugin)
+		return nil
+	}
+
+	if _, err := os.Stat(getReinstallBackupPath(plugin)); err != nil {
+		if _, err := os.Stat(plugin.InstallationPath); err != nil {
+			// Neither the binary nor its backup remains, there is nothing to restore
+			return nil
+		}
+	}
+	clilog.Infof("Restoring version '%s' of plugin '%s' for target '%s' whose reinstallation was interrupted", plugin.Version, plugin.Name, plugin.Target)
+	return restoreReinstalledPlugin(plugin)
+}
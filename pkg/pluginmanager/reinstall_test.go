@@ -0,0 +1,138 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+func TestReinstallPlugin(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	// A plugin which is not installed gets its recommended version installed
+	result, err := ReinstallPlugin(ReinstallPluginOptions{PluginName: "login", Target: configtypes.TargetGlobal, ForceReinstall: true})
+	assertions.Nil(err)
+	assertions.Equal("v0.20.0", result.Version)
+	assertions.True(checkPluginIsInstalled("login", configtypes.TargetGlobal))
+
+	// An installed plugin is reinstalled at its installed version
+	err = InstallStandalonePlugin("login", "v0.2.0", configtypes.TargetGlobal)
+	assertions.Nil(err)
+	installedPlugins, err := pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	installed := findPluginInfo(installedPlugins, "login", configtypes.TargetGlobal)
+	assertions.NotNil(installed)
+	assertions.Nil(os.WriteFile(installed.InstallationPath, []byte("corrupt"), 0755))
+
+	result, err = ReinstallPlugin(ReinstallPluginOptions{PluginName: "login", ForceReinstall: true})
+	assertions.Nil(err)
+	assertions.Equal("v0.2.0", result.Version)
+	assertions.Equal(installed.InstallationPath, result.Path)
+	b, err := os.ReadFile(result.Path)
+	assertions.Nil(err)
+	assertions.NotEqual("corrupt", string(b))
+}
+
+func TestReinstallPluginRollback(t *testing.T) {
+	assertions := assert.New(t)
+
+	if cli.BuildArch().IsWindows() {
+		t.Skip("the failure relies on a binary which cannot be executed")
+	}
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	err := InstallStandalonePlugin("login", "v0.2.0", configtypes.TargetGlobal)
+	assertions.Nil(err)
+	installedPlugins, err := pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	installed := findPluginInfo(installedPlugins, "login", configtypes.TargetGlobal)
+	assertions.NotNil(installed)
+	binary, err := os.ReadFile(installed.InstallationPath)
+	assertions.Nil(err)
+
+	// The downloaded binary of the test plugin cannot be executed, which fails the installation
+	execCommand = exec.Command
+	_, err = ReinstallPlugin(ReinstallPluginOptions{PluginName: "login", Target: configtypes.TargetGlobal, ForceReinstall: true})
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "its previous installation was restored")
+
+	// The previous installation is restored, including its binary
+	installedPlugins, err = pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	restored := findPluginInfo(installedPlugins, "login", configtypes.TargetGlobal)
+	assertions.NotNil(restored)
+	assertions.Equal("v0.2.0", restored.Version)
+	assertions.Equal(installed.InstallationPath, restored.InstallationPath)
+	restoredBinary, err := os.ReadFile(restored.InstallationPath)
+	assertions.Nil(err)
+	assertions.Equal(binary, restoredBinary)
+	assertions.NoFileExists(getReinstallBackupPath(installed))
+	assertions.Empty(getJournalEntries(t))
+}
+
+func TestRecoverReinstallationInterruptedAfterUninstall(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	err := InstallStandalonePlugin("login", "v0.2.0", configtypes.TargetGlobal)
+	assertions.Nil(err)
+	installedPlugins, err := pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	installed := findPluginInfo(installedPlugins, "login", configtypes.TargetGlobal)
+	assertions.NotNil(installed)
+	binary, err := os.ReadFile(installed.InstallationPath)
+	assertions.Nil(err)
+
+	// The CLI crashes once the plugin is uninstalled, before it is installed again
+	_, err = uninstallStandalonePlugin(installed)
+	assertions.Nil(err)
+	assertions.Nil(os.Remove(installed.InstallationPath))
+	assertions.False(checkPluginIsInstalled("login", configtypes.TargetGlobal))
+	simulateCrash(t)
+
+	// The plugin is restored, along with its binary
+	assertions.Nil(recoverInterruptedPluginOperations())
+	installedPlugins, err = pluginsupplier.GetInstalledStandalonePlugins()
+	assertions.Nil(err)
+	restored := findPluginInfo(installedPlugins, "login", configtypes.TargetGlobal)
+	assertions.NotNil(restored)
+	assertions.Equal("v0.2.0", restored.Version)
+	assertions.Equal(installed.InstallationPath, restored.InstallationPath)
+	restoredBinary, err := os.ReadFile(restored.InstallationPath)
+	assertions.Nil(err)
+	assertions.Equal(binary, restoredBinary)
+	assertions.NoFileExists(getReinstallBackupPath(installed))
+	assertions.Empty(getJournalEntries(t))
+
+	// The CLI crashes once the plugin is installed again, before the journal is updated
+	_, err = uninstallStandalonePlugin(restored)
+	assertions.Nil(err)
+	err = InstallStandalonePlugin("login", "v0.2.0", configtypes.TargetGlobal, WithReinstall(true))
+	assertions.Nil(err)
+	simulateCrash(t)
+
+	// The reinstalled plugin is kept and the backup of its binary is removed
+	assertions.Nil(recoverInterruptedPluginOperations())
+	assertions.True(checkPluginIsInstalled("login", configtypes.TargetGlobal))
+	assertions.NoFileExists(getReinstallBackupPath(installed))
+	assertions.Empty(getJournalEntries(t))
+}
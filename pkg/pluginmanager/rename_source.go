@@ -0,0 +1,104 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	configlib "github.com/vmware-tanzu/tanzu-plugin-runtime/config"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+// RenameDiscoverySource renames a configured discovery source.  The discovery source keeps
+// its position among the configured ones, its settings and its cached plugin inventories,
+// so that the inventory is not downloaded again, and the installed plugins remain
+// associated with it.  If the configuration cannot be updated, it is restored.
+func RenameDiscoverySource(oldName, newName string) error {
+	if newName == "" {
+		return errors.New("the new name of the discovery source cannot be empty")
+	}
+	if oldName == newName {
+		return errors.Errorf("discovery source %q already has this name", oldName)
+	}
+	discoverySources, err := configlib.GetCLIDiscoverySources()
+	if err != nil {
+		return err
+	}
+
+	index := -1
+	for i := range discoverySources {
+		switch discovery.GetDiscoveryName(discoverySources[i]) {
+		case oldName:
+			index = i
+		case newName:
+			return errors.Errorf("discovery source %q already exists", newName)
+		}
+	}
+	if index == -1 {
+		return errors.Errorf("discovery %q does not exist", oldName)
+	}
+
+	// A new discovery source is added after the existing ones, so the renamed
+	// discovery source and the ones following it are configured again in order
+	renamed := append([]configtypes.PluginDiscovery(nil), discoverySources...)
+	renamed[index] = discovery.SetDiscoveryName(renamed[index], newName)
+	if err := replaceCLIDiscoverySources(discoverySources[index:], renamed[index:]); err != nil {
+		// Some of the discovery sources may already be removed or renamed
+		for i := range renamed[index:] {
+			_ = configlib.DeleteCLIDiscoverySource(discovery.GetDiscoveryName(renamed[index+i]))
+		}
+		if restoreErr := configlib.SetCLIDiscoverySources(discoverySources[index:]); restoreErr != nil {
+			log.V(4).Infof("Unable to restore the discovery sources: %v", restoreErr)
+		}
+		return errors.Wrapf(err, "unable to rename discovery source %q", oldName)
+	}
+
+	if err := config.RenamePluginDiscoverySettings(oldName, newName); err != nil {
+		return errors.Wrapf(err, "unable to rename the settings of discovery source %q", oldName)
+	}
+
+	// The inventory is only downloaded again if its cache cannot be moved
+	if err := discovery.RenameCachedInventories(oldName, newName); err != nil {
+		log.Warningf("The plugin inventory of discovery source %q will be downloaded again: %v", newName, err)
+	}
+
+	return renameInstalledPluginsDiscovery(oldName, newName)
+}
+
+// replaceCLIDiscoverySources removes the specified configured discovery
+// sources and then configures the replacements, in order
+func replaceCLIDiscoverySources(current, replacements []configtypes.PluginDiscovery) error {
+	for i := range current {
+		if err := configlib.DeleteCLIDiscoverySource(discovery.GetDiscoveryName(current[i])); err != nil {
+			return err
+		}
+	}
+	return configlib.SetCLIDiscoverySources(replacements)
+}
+
+// renameInstalledPluginsDiscovery updates the discovery source recorded
+// for the standalone plugins installed from the renamed discovery source
+func renameInstalledPluginsDiscovery(oldName, newName string) error {
+	c, err := catalog.NewContextCatalogUpdater("")
+	if err != nil {
+		return err
+	}
+	defer c.Unlock()
+
+	plugins := c.List()
+	for i := range plugins {
+		if plugins[i].Discovery != oldName {
+			continue
+		}
+		plugins[i].Discovery = newName
+		if err := c.Upsert(&plugins[i]); err != nil {
+			return errors.Wrapf(err, "unable to update the discovery source of plugin %q", plugins[i].Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
+	configlib "github.com/vmware-tanzu/tanzu-plugin-runtime/config"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+func TestRenameDiscoverySource(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	assertions.Nil(InstallStandalonePlugin("login", "v0.2.0", configtypes.TargetUnknown))
+
+	err := RenameDiscoverySource("invalid", "central")
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), `discovery "invalid" does not exist`)
+
+	err = RenameDiscoverySource(config.DefaultStandaloneDiscoveryName, "")
+	assertions.NotNil(err)
+
+	err = RenameDiscoverySource(config.DefaultStandaloneDiscoveryName, "central")
+	assertions.Nil(err)
+
+	discoverySource, err := configlib.GetCLIDiscoverySource("central")
+	assertions.Nil(err)
+	assertions.Equal("example.com/plugin-inventory:latest", discoverySource.OCI.Image)
+	_, err = configlib.GetCLIDiscoverySource(config.DefaultStandaloneDiscoveryName)
+	assertions.NotNil(err)
+
+	// The cached inventory is used under the new name
+	inventoryDir := filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName)
+	assertions.NoDirExists(filepath.Join(inventoryDir, config.DefaultStandaloneDiscoveryName))
+	assertions.DirExists(filepath.Join(inventoryDir, "central"))
+	plugins, err := DiscoverStandalonePlugins()
+	assertions.Nil(err)
+	assertions.NotEmpty(plugins)
+
+	// The installed plugins refer to the new name
+	installedPlugins, err := pluginsupplier.GetInstalledPlugins()
+	assertions.Nil(err)
+	assertions.NotEmpty(installedPlugins)
+	for i := range installedPlugins {
+		assertions.Equal("central", installedPlugins[i].Discovery)
+	}
+}
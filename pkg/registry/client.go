@@ -23,9 +23,39 @@ type registry struct {
 	registry ctlimg.Registry
 }
 
+// Options are the options of the registry client that are not part of the imgpkg options
+type Options struct {
+	// ClientCertPath and ClientKeyPath are the client certificate and its key
+	// presented to the registries requiring mutual TLS
+	ClientCertPath string
+	ClientKeyPath  string
+}
+
+type Option func(o *Options)
+
+// WithClientCertificate presents the specified client certificate to the registry
+func WithClientCertificate(certPath, keyPath string) Option {
+	return func(o *Options) {
+		o.ClientCertPath = certPath
+		o.ClientKeyPath = keyPath
+	}
+}
+
 // New instantiates a new Registry
-func New(opts *ctlimg.Opts) (Registry, error) {
-	reg, err := ctlimg.NewSimpleRegistry(*opts)
+func New(opts *ctlimg.Opts, options ...Option) (Registry, error) {
+	clientOpts := &Options{}
+	for _, option := range options {
+		option(clientOpts)
+	}
+	clientCerts, err := LoadClientCertificates(clientOpts.ClientCertPath, clientOpts.ClientKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := newHTTPTransport(opts, clientCerts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to initialize registry transport")
+	}
+	reg, err := ctlimg.NewSimpleRegistryWithTransport(*opts, transport)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to initialize registry client")
 	}
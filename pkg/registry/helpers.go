@@ -5,6 +5,8 @@ package registry
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"fmt"
 	"log"
@@ -12,6 +14,8 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	regname "github.com/google/go-containerregistry/pkg/name"
@@ -19,6 +23,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/configpaths"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 	configlib "github.com/vmware-tanzu/tanzu-plugin-runtime/config"
@@ -30,6 +35,10 @@ type CertOptions struct {
 	CACertPaths    []string
 	SkipCertVerify bool
 	Insecure       bool
+	// ClientCertPath and ClientKeyPath are the client certificate and its key
+	// presented to the registries requiring mutual TLS
+	ClientCertPath string
+	ClientKeyPath  string
 }
 
 func GetRegistryCertOptions(registryHost string) (*CertOptions, error) {
@@ -46,28 +55,84 @@ func GetRegistryCertOptions(registryHost string) (*CertOptions, error) {
 	}
 
 	// check if the custom cert data is configured for the registry
-	if exists, _ := configlib.CertExists(registryHost); !exists {
-		err := checkForProxyConfigAndUpdateCert(registryCertOpts)
+	if exists, _ := configlib.CertExists(registryHost); exists {
+		cert, err := configlib.GetCert(registryHost)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to check for proxy config and update the cert")
+			return nil, errors.Wrapf(err, "failed to get the custom certificate configuration for host %q", registryHost)
+		}
+
+		err = updateRegistryCertOptions(cert, registryCertOpts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to updated the registry cert options")
 		}
-		return registryCertOpts, nil
 	}
-	cert, err := configlib.GetCert(registryHost)
+
+	err := checkForProxyConfigAndUpdateCert(registryCertOpts)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to get the custom certificate configuration for host %q", registryHost)
+		return nil, errors.Wrap(err, "failed to check for proxy config and update the cert")
 	}
 
-	err = updateRegistryCertOptions(cert, registryCertOpts)
+	err = addRegistryCertOptionsFromEnv(registryCertOpts)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to updated the registry cert options")
+		return nil, err
+	}
+	return registryCertOpts, nil
+}
+
+// skipCertVerifyWarning makes sure the warning about skipping the verification
+// of the registry certificates is only printed once per command
+var skipCertVerifyWarning sync.Once
+
+// addRegistryCertOptionsFromEnv updates the cert options with the CA certificates trusted for all
+// registries, as specified by the TANZU_CLI_REGISTRY_CA_CERT variable, with the client certificate
+// of TANZU_CLI_REGISTRY_CLIENT_CERT and TANZU_CLI_REGISTRY_CLIENT_KEY, and skips the verification
+// of the registry certificates if TANZU_CLI_REGISTRY_SKIP_CERT_VERIFY is enabled
+func addRegistryCertOptionsFromEnv(registryCertOpts *CertOptions) error {
+	if caCertPath := strings.TrimSpace(os.Getenv(constants.ConfigVariableRegistryCACert)); caCertPath != "" {
+		caCertBytes, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return errors.Wrapf(err, "unable to read the registry CA certificate file %q", caCertPath)
+		}
+		if !x509.NewCertPool().AppendCertsFromPEM(caCertBytes) {
+			return errors.Errorf("the registry CA certificate file %q does not contain any PEM-encoded certificate", caCertPath)
+		}
+		registryCertOpts.CACertPaths = append(registryCertOpts.CACertPaths, caCertPath)
 	}
 
-	err = checkForProxyConfigAndUpdateCert(registryCertOpts)
+	clientCertPath := strings.TrimSpace(os.Getenv(constants.ConfigVariableRegistryClientCert))
+	clientKeyPath := strings.TrimSpace(os.Getenv(constants.ConfigVariableRegistryClientKey))
+	if clientCertPath != "" || clientKeyPath != "" {
+		if _, err := LoadClientCertificates(clientCertPath, clientKeyPath); err != nil {
+			return err
+		}
+		registryCertOpts.ClientCertPath = clientCertPath
+		registryCertOpts.ClientKeyPath = clientKeyPath
+	}
+
+	if skip, _ := strconv.ParseBool(os.Getenv(constants.ConfigVariableRegistrySkipCertVerify)); skip {
+		skipCertVerifyWarning.Do(func() {
+			tprlog.Warningf("INSECURE: the certificates of the registries are not verified because %s is enabled. Do not use this setting in production.", constants.ConfigVariableRegistrySkipCertVerify)
+		})
+		registryCertOpts.SkipCertVerify = true
+	}
+	return nil
+}
+
+// LoadClientCertificates loads the client certificate to present to the registries requiring
+// mutual TLS.  No certificate is returned if neither path is specified.  The errors only
+// mention the paths of the files, never their content.
+func LoadClientCertificates(certPath, keyPath string) ([]tls.Certificate, error) {
+	if certPath == "" && keyPath == "" {
+		return nil, nil
+	}
+	if certPath == "" || keyPath == "" {
+		return nil, errors.Errorf("both %s and %s must be set to use a registry client certificate", constants.ConfigVariableRegistryClientCert, constants.ConfigVariableRegistryClientKey)
+	}
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to check for proxy config and update the cert")
+		return nil, errors.Errorf("unable to load the registry client certificate %q and its key %q", certPath, keyPath)
 	}
-	return registryCertOpts, nil
+	return []tls.Certificate{cert}, nil
 }
 
 // updateRegistryCertOptions sets the registry options by taking the custom certificate data configured for registry as input
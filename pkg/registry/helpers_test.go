@@ -243,6 +243,76 @@ var _ = Describe("GetRegistryCertOptions", func() {
 
 })
 
+var _ = Describe("GetRegistryCertOptions with the registry certificate variables", func() {
+	const (
+		fakeCACertPath = "../fakes/certs/fake-ca.crt"
+		testHost       = "test.vmware.com"
+	)
+	var (
+		tanzuConfigFile   *os.File
+		tanzuConfigFileNG *os.File
+		err               error
+	)
+
+	BeforeEach(func() {
+		tanzuConfigFile, err = os.CreateTemp("", "config")
+		Expect(err).To(BeNil())
+		os.Setenv("TANZU_CONFIG", tanzuConfigFile.Name())
+
+		tanzuConfigFileNG, err = os.CreateTemp("", "config_ng")
+		Expect(err).To(BeNil())
+		os.Setenv("TANZU_CONFIG_NEXT_GEN", tanzuConfigFileNG.Name())
+	})
+	AfterEach(func() {
+		os.Unsetenv("TANZU_CONFIG")
+		os.Unsetenv("TANZU_CONFIG_NEXT_GEN")
+		os.Unsetenv(constants.ConfigVariableRegistryCACert)
+		os.Unsetenv(constants.ConfigVariableRegistrySkipCertVerify)
+		os.RemoveAll(tanzuConfigFile.Name())
+		os.RemoveAll(tanzuConfigFileNG.Name())
+	})
+
+	It("should trust the CA certificates of the specified file", func() {
+		os.Setenv(constants.ConfigVariableRegistryCACert, fakeCACertPath)
+		certOptions, err := GetRegistryCertOptions(testHost)
+		Expect(err).To(BeNil())
+		Expect(certOptions.CACertPaths).To(ContainElement(fakeCACertPath))
+		Expect(certOptions.SkipCertVerify).To(BeFalse())
+	})
+	It("should also trust the CA certificate configured for the registry host", func() {
+		err := configlib.SetCert(&configtypes.Cert{
+			Host:       testHost,
+			CACertData: base64.StdEncoding.EncodeToString([]byte("fake ca cert data")),
+		})
+		Expect(err).To(BeNil())
+		os.Setenv(constants.ConfigVariableRegistryCACert, fakeCACertPath)
+
+		certOptions, err := GetRegistryCertOptions(testHost)
+		Expect(err).To(BeNil())
+		regFilePath, err := configpaths.GetRegistryCertFile()
+		Expect(err).To(BeNil())
+		Expect(certOptions.CACertPaths).To(ContainElements(regFilePath, fakeCACertPath))
+	})
+	It("should fail if the CA certificate file does not exist", func() {
+		os.Setenv(constants.ConfigVariableRegistryCACert, "does-not-exist.crt")
+		_, err := GetRegistryCertOptions(testHost)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring(`unable to read the registry CA certificate file "does-not-exist.crt"`))
+	})
+	It("should fail if the CA certificate file does not contain a certificate", func() {
+		os.Setenv(constants.ConfigVariableRegistryCACert, tanzuConfigFile.Name())
+		_, err := GetRegistryCertOptions(testHost)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("does not contain any PEM-encoded certificate"))
+	})
+	It("should skip the verification of the certificates when requested", func() {
+		os.Setenv(constants.ConfigVariableRegistrySkipCertVerify, "true")
+		certOptions, err := GetRegistryCertOptions(testHost)
+		Expect(err).To(BeNil())
+		Expect(certOptions.SkipCertVerify).To(BeTrue())
+	})
+})
+
 var _ = Describe("GetRegistryName() tests", func() {
 	const host = "localhost:9876"
 	It("should return the host name when the image path uses a tag", func() {
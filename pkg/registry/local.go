@@ -0,0 +1,199 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/pkg/errors"
+)
+
+const (
+	// OCILayoutPrefix prefixes the path of an OCI image layout directory used as an image
+	// instead of a registry reference, e.g., oci-layout:/path/to/plugin-inventory
+	OCILayoutPrefix = "oci-layout:"
+	// OCIArchivePrefix prefixes the path of a tar archive of an OCI image layout used as an
+	// image instead of a registry reference, e.g., oci-archive:/path/to/plugin-inventory.tar
+	OCIArchivePrefix = "oci-archive:"
+
+	// cosignKindAnnotation is the annotation used by `cosign save` to identify the
+	// image, signatures and attestations stored in an OCI image layout
+	cosignKindAnnotation = "kind"
+	cosignImageKind      = "dev.cosignproject.cosign/image"
+)
+
+// IsLocalImage returns true if the image is an OCI image layout directory or
+// archive on disk rather than a reference to an image in a registry
+func IsLocalImage(image string) bool {
+	image = strings.TrimSpace(image)
+	return strings.HasPrefix(image, OCILayoutPrefix) || strings.HasPrefix(image, OCIArchivePrefix)
+}
+
+// OpenLocalImageLayout returns the path of the OCI image layout directory of a local image.
+// The archive of a local image is extracted to a temporary directory which is removed
+// by the returned cleanup function.
+func OpenLocalImageLayout(image string) (layoutPath string, cleanup func(), err error) {
+	image = strings.TrimSpace(image)
+	switch {
+	case strings.HasPrefix(image, OCILayoutPrefix):
+		layoutPath = strings.TrimPrefix(image, OCILayoutPrefix)
+		if _, err := os.Stat(filepath.Join(layoutPath, "index.json")); err != nil {
+			return "", nil, errors.Wrapf(err, "%q is not an OCI image layout directory", layoutPath)
+		}
+		return layoutPath, func() {}, nil
+	case strings.HasPrefix(image, OCIArchivePrefix):
+		archivePath := strings.TrimPrefix(image, OCIArchivePrefix)
+		tempDir, err := os.MkdirTemp("", "oci-archive")
+		if err != nil {
+			return "", nil, errors.Wrap(err, "unable to create temp directory")
+		}
+		cleanup = func() { os.RemoveAll(tempDir) }
+		if err := extractTarFile(archivePath, tempDir); err != nil {
+			cleanup()
+			return "", nil, errors.Wrapf(err, "unable to extract the OCI image archive %q", archivePath)
+		}
+		return tempDir, cleanup, nil
+	}
+	return "", nil, errors.Errorf("%q is not a local image, it must start with %q or %q", image, OCILayoutPrefix, OCIArchivePrefix)
+}
+
+// GetLocalImageDigest gets the digest of the image of an OCI image layout directory or archive
+func GetLocalImageDigest(image string) (string, string, error) {
+	layoutPath, cleanup, err := OpenLocalImageLayout(image)
+	if err != nil {
+		return "", "", err
+	}
+	defer cleanup()
+
+	_, hash, err := getLayoutImage(layoutPath)
+	if err != nil {
+		return "", "", err
+	}
+	return hash.Algorithm, hash.Hex, nil
+}
+
+// DownloadLocalImage saves the files of the image of an OCI image layout directory or
+// archive to outputDir, similarly to what DownloadImage does for an image of a registry
+func DownloadLocalImage(image, outputDir string) error {
+	layoutPath, cleanup, err := OpenLocalImageLayout(image)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	img, _, err := getLayoutImage(layoutPath)
+	if err != nil {
+		return err
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return err
+	}
+	for _, imgLayer := range layers {
+		layerStream, err := imgLayer.Uncompressed()
+		if err != nil {
+			return err
+		}
+		err = extractTar(layerStream, outputDir)
+		layerStream.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getLayoutImage returns the image stored in an OCI image layout directory along with its digest.
+// The signatures and attestations stored in the layout by `cosign save` are ignored.
+func getLayoutImage(layoutPath string) (regv1.Image, regv1.Hash, error) {
+	index, err := layout.ImageIndexFromPath(layoutPath)
+	if err != nil {
+		return nil, regv1.Hash{}, errors.Wrapf(err, "unable to read the OCI image layout %q", layoutPath)
+	}
+	indexManifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, regv1.Hash{}, errors.Wrapf(err, "unable to read the index of the OCI image layout %q", layoutPath)
+	}
+
+	var images []regv1.Descriptor
+	for _, desc := range indexManifest.Manifests {
+		if !desc.MediaType.IsImage() {
+			continue
+		}
+		if kind := desc.Annotations[cosignKindAnnotation]; kind != "" && kind != cosignImageKind {
+			continue
+		}
+		images = append(images, desc)
+	}
+	if len(images) == 0 {
+		return nil, regv1.Hash{}, errors.Errorf("no image found in the OCI image layout %q", layoutPath)
+	}
+	if len(images) > 1 {
+		return nil, regv1.Hash{}, errors.Errorf("the OCI image layout %q contains %d images, only one is supported", layoutPath, len(images))
+	}
+
+	img, err := index.Image(images[0].Digest)
+	if err != nil {
+		return nil, regv1.Hash{}, errors.Wrapf(err, "unable to read the image of the OCI image layout %q", layoutPath)
+	}
+	return img, images[0].Digest, nil
+}
+
+// extractTarFile extracts the regular files and directories of a tar file to outputDir
+func extractTarFile(tarFile, outputDir string) error {
+	f, err := os.Open(tarFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return extractTar(f, outputDir)
+}
+
+// extractTar extracts the regular files and directories of a tar stream to outputDir
+func extractTar(r io.Reader, outputDir string) error {
+	tarReader := tar.NewReader(r)
+	for {
+		hdr, err := tarReader.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		// Cleaning the name as an absolute path prevents extracting outside of outputDir
+		target := filepath.Join(outputDir, filepath.Clean(string(filepath.Separator)+hdr.Name))
+		if target == filepath.Clean(outputDir) {
+			continue
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA: //nolint:staticcheck //SA1019: tar.TypeRegA has been deprecated since Go 1.11 and an alternative has been available since Go 1.1: Use TypeReg instead. (staticcheck)
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, tarReader) // #nosec G110
+			if closeErr := out.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// tarOf returns a tar stream of the specified files
+func tarOf(files map[string]string) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		Expect(tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})).To(Succeed())
+		_, err := tw.Write([]byte(content))
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(tw.Close()).To(Succeed())
+	return buf.Bytes()
+}
+
+// imageOf returns an image with a single layer containing the specified files
+func imageOf(files map[string]string) regv1.Image {
+	b := tarOf(files)
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	})
+	Expect(err).ToNot(HaveOccurred())
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	Expect(err).ToNot(HaveOccurred())
+	return img
+}
+
+// archiveOf writes a tar archive of the content of dir to archiveFile
+func archiveOf(dir, archiveFile string) {
+	files := map[string]string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(relPath)] = string(content)
+		return nil
+	})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(os.WriteFile(archiveFile, tarOf(files), 0644)).To(Succeed())
+}
+
+var _ = Describe("Local images", func() {
+	var (
+		tmpDir     string
+		layoutDir  string
+		layoutPath layout.Path
+		img        regv1.Image
+	)
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "local-image")
+		Expect(err).ToNot(HaveOccurred())
+		layoutDir = filepath.Join(tmpDir, "layout")
+		layoutPath, err = layout.Write(layoutDir, empty.Index)
+		Expect(err).ToNot(HaveOccurred())
+
+		img = imageOf(map[string]string{"plugin_inventory.db": "inventory"})
+		Expect(layoutPath.AppendImage(img)).To(Succeed())
+	})
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("should recognize the local images", func() {
+		Expect(IsLocalImage("oci-layout:/path/to/layout")).To(BeTrue())
+		Expect(IsLocalImage(" oci-archive:/path/to/layout.tar")).To(BeTrue())
+		Expect(IsLocalImage("localhost:9876/tanzu-cli/plugins/central:small")).To(BeFalse())
+	})
+
+	It("should read the image of an OCI image layout directory", func() {
+		digest, err := img.Digest()
+		Expect(err).ToNot(HaveOccurred())
+
+		algorithm, hex, err := GetLocalImageDigest(OCILayoutPrefix + layoutDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(algorithm).To(Equal(digest.Algorithm))
+		Expect(hex).To(Equal(digest.Hex))
+
+		outputDir := filepath.Join(tmpDir, "output")
+		Expect(DownloadLocalImage(OCILayoutPrefix+layoutDir, outputDir)).To(Succeed())
+		content, err := os.ReadFile(filepath.Join(outputDir, "plugin_inventory.db"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal("inventory"))
+	})
+
+	It("should read the image of an OCI image layout archive", func() {
+		archiveFile := filepath.Join(tmpDir, "layout.tar")
+		archiveOf(layoutDir, archiveFile)
+
+		digest, err := img.Digest()
+		Expect(err).ToNot(HaveOccurred())
+		_, hex, err := GetLocalImageDigest(OCIArchivePrefix + archiveFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hex).To(Equal(digest.Hex))
+
+		outputDir := filepath.Join(tmpDir, "output")
+		Expect(DownloadLocalImage(OCIArchivePrefix+archiveFile, outputDir)).To(Succeed())
+		Expect(filepath.Join(outputDir, "plugin_inventory.db")).To(BeAnExistingFile())
+	})
+
+	It("should ignore the signatures stored in the layout", func() {
+		signature := imageOf(map[string]string{"signature": "sig"})
+		Expect(layoutPath.AppendImage(signature, layout.WithAnnotations(map[string]string{cosignKindAnnotation: "dev.cosignproject.cosign/sigs"}))).To(Succeed())
+
+		digest, err := img.Digest()
+		Expect(err).ToNot(HaveOccurred())
+		_, hex, err := GetLocalImageDigest(OCILayoutPrefix + layoutDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hex).To(Equal(digest.Hex))
+	})
+
+	It("should refuse a layout with several images", func() {
+		Expect(layoutPath.AppendImage(imageOf(map[string]string{"other": "image"}))).To(Succeed())
+
+		_, _, err := GetLocalImageDigest(OCILayoutPrefix + layoutDir)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("contains 2 images, only one is supported"))
+	})
+
+	It("should refuse a directory that is not an OCI image layout", func() {
+		_, _, err := GetLocalImageDigest(OCILayoutPrefix + tmpDir)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("is not an OCI image layout directory"))
+	})
+
+	It("should not extract files outside of the output directory", func() {
+		outputDir := filepath.Join(tmpDir, "output")
+		Expect(extractTar(bytes.NewReader(tarOf(map[string]string{"../escaped": "content"})), outputDir)).To(Succeed())
+		Expect(filepath.Join(outputDir, "escaped")).To(BeAnExistingFile())
+		Expect(filepath.Join(tmpDir, "escaped")).ToNot(BeAnExistingFile())
+	})
+})
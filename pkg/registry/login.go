@@ -0,0 +1,96 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	regname "github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/pkg/errors"
+
+	ctlimg "github.com/vmware-tanzu/carvel-imgpkg/pkg/imgpkg/registry"
+)
+
+// loginTimeout is the maximum time to validate credentials against a registry
+const loginTimeout = 30 * time.Second
+
+// newHTTPTransportForHost returns the transport to use to access the specified registry
+// host, honoring its certificate configuration, along with that configuration
+func newHTTPTransportForHost(registryHost string) (http.RoundTripper, *CertOptions, error) {
+	certOpts, err := GetRegistryCertOptions(registryHost)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "unable to get the registry certificate configuration")
+	}
+	clientCerts, err := LoadClientCertificates(certOpts.ClientCertPath, certOpts.ClientKeyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	transport, err := newHTTPTransport(&ctlimg.Opts{CACertPaths: certOpts.CACertPaths, VerifyCerts: !certOpts.SkipCertVerify}, clientCerts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return transport, certOpts, nil
+}
+
+// NewHTTPClient returns an HTTP client accessing the specified registry host, or a web
+// server of the same host, the same way the registry client does: with the CA certificates
+// and client certificate configured for the host, skipping the verification of its
+// certificate if so configured, and through the configured proxy.
+func NewHTTPClient(registryHost string) (*http.Client, error) {
+	transport, _, err := newHTTPTransportForHost(registryHost)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// ValidateCredentials checks that the registry accepts the specified username and password
+// by authenticating and accessing its API.  The certificate configuration of the registry is
+// honored.  The errors never include the password.
+func ValidateCredentials(registryHost, username, password string) error {
+	baseTransport, certOpts, err := newHTTPTransportForHost(registryHost)
+	if err != nil {
+		return err
+	}
+
+	var nameOpts []regname.Option
+	if certOpts.Insecure {
+		nameOpts = append(nameOpts, regname.Insecure)
+	}
+	reg, err := regname.NewRegistry(registryHost, nameOpts...)
+	if err != nil {
+		return errors.Wrapf(err, "invalid registry %q", registryHost)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), loginTimeout)
+	defer cancel()
+	auth := &authn.Basic{Username: username, Password: password}
+	authTransport, err := transport.NewWithContext(ctx, reg, auth, baseTransport, []string{})
+	if err != nil {
+		return errors.Wrapf(err, "unable to log in to registry %q as user %q", registryHost, username)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reg.Scheme()+"://"+reg.RegistryStr()+"/v2/", http.NoBody)
+	if err != nil {
+		return err
+	}
+	resp, err := authTransport.RoundTrip(req)
+	if err != nil {
+		return errors.Wrapf(err, "unable to log in to registry %q as user %q", registryHost, username)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errors.Errorf("registry %q rejected the credentials of user %q", registryHost, username)
+	default:
+		return errors.Errorf("unable to log in to registry %q as user %q: unexpected status code %d", registryHost, username, resp.StatusCode)
+	}
+}
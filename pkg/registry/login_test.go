@@ -0,0 +1,127 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+package registry
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+)
+
+var _ = Describe("ValidateCredentials", func() {
+	var (
+		tanzuConfigFile   *os.File
+		tanzuConfigFileNG *os.File
+		server            *httptest.Server
+		registryHost      string
+		err               error
+	)
+
+	BeforeEach(func() {
+		tanzuConfigFile, err = os.CreateTemp("", "config")
+		Expect(err).To(BeNil())
+		os.Setenv("TANZU_CONFIG", tanzuConfigFile.Name())
+
+		tanzuConfigFileNG, err = os.CreateTemp("", "config_ng")
+		Expect(err).To(BeNil())
+		os.Setenv("TANZU_CONFIG_NEXT_GEN", tanzuConfigFileNG.Name())
+
+		// A registry accepting only the "user" user with the "secret" password
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		registryHost = strings.TrimPrefix(server.URL, "http://")
+	})
+	AfterEach(func() {
+		server.Close()
+		os.Unsetenv("TANZU_CONFIG")
+		os.Unsetenv("TANZU_CONFIG_NEXT_GEN")
+		os.RemoveAll(tanzuConfigFile.Name())
+		os.RemoveAll(tanzuConfigFileNG.Name())
+	})
+
+	It("should accept valid credentials", func() {
+		Expect(ValidateCredentials(registryHost, "user", "secret")).To(Succeed())
+	})
+	It("should reject invalid credentials without including the password in the error", func() {
+		err = ValidateCredentials(registryHost, "user", "wrong-password")
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring(`rejected the credentials of user "user"`))
+		Expect(err.Error()).ToNot(ContainSubstring("wrong-password"))
+	})
+	It("should fail for an invalid registry", func() {
+		err = ValidateCredentials("invalid registry", "user", "secret")
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring(`invalid registry "invalid registry"`))
+	})
+})
+
+var _ = Describe("NewHTTPClient", func() {
+	var (
+		tanzuConfigFile   *os.File
+		tanzuConfigFileNG *os.File
+		server            *httptest.Server
+		serverCAPath      string
+		err               error
+	)
+
+	BeforeEach(func() {
+		tanzuConfigFile, err = os.CreateTemp("", "config")
+		Expect(err).To(BeNil())
+		os.Setenv("TANZU_CONFIG", tanzuConfigFile.Name())
+
+		tanzuConfigFileNG, err = os.CreateTemp("", "config_ng")
+		Expect(err).To(BeNil())
+		os.Setenv("TANZU_CONFIG_NEXT_GEN", tanzuConfigFileNG.Name())
+
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		serverCA, err := os.CreateTemp("", "server-ca")
+		Expect(err).To(BeNil())
+		Expect(pem.Encode(serverCA, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})).To(Succeed())
+		Expect(serverCA.Close()).To(Succeed())
+		serverCAPath = serverCA.Name()
+	})
+	AfterEach(func() {
+		server.Close()
+		os.Unsetenv("TANZU_CONFIG")
+		os.Unsetenv("TANZU_CONFIG_NEXT_GEN")
+		os.Unsetenv(constants.ConfigVariableRegistryCACert)
+		os.RemoveAll(tanzuConfigFile.Name())
+		os.RemoveAll(tanzuConfigFileNG.Name())
+		os.RemoveAll(serverCAPath)
+	})
+
+	get := func() error {
+		client, err := NewHTTPClient(strings.TrimPrefix(server.URL, "https://"))
+		Expect(err).To(BeNil())
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		return nil
+	}
+
+	It("should trust the CA certificates configured for the registries", func() {
+		os.Setenv(constants.ConfigVariableRegistryCACert, serverCAPath)
+		Expect(get()).To(Succeed())
+	})
+	It("should not trust a server whose CA certificate is not configured", func() {
+		Expect(get()).ToNot(Succeed())
+	})
+})
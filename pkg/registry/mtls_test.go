@@ -0,0 +1,148 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+package registry
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	gocontainerregistry "github.com/google/go-containerregistry/pkg/registry"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	ctlimg "github.com/vmware-tanzu/carvel-imgpkg/pkg/imgpkg/registry"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+)
+
+// writeClientCertificate writes a self-signed client certificate and its key
+// to the specified directory and returns their paths and the certificate
+func writeClientCertificate(dir string) (string, string, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).To(BeNil())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "tanzu-cli-test-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).To(BeNil())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).To(BeNil())
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	Expect(err).To(BeNil())
+
+	certPath := filepath.Join(dir, "client.crt")
+	keyPath := filepath.Join(dir, "client.key")
+	Expect(os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)).To(Succeed())
+	Expect(os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600)).To(Succeed())
+	return certPath, keyPath, cert
+}
+
+var _ = Describe("Registry requiring mutual TLS", func() {
+	var (
+		dir            string
+		server         *httptest.Server
+		serverCAPath   string
+		clientCertPath string
+		clientKeyPath  string
+	)
+
+	BeforeEach(func() {
+		var err error
+		var clientCert *x509.Certificate
+		dir, err = os.MkdirTemp("", "mtls")
+		Expect(err).To(BeNil())
+		clientCertPath, clientKeyPath, clientCert = writeClientCertificate(dir)
+
+		clientCAs := x509.NewCertPool()
+		clientCAs.AddCert(clientCert)
+		server = httptest.NewUnstartedServer(gocontainerregistry.New())
+		server.TLS = &tls.Config{
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  clientCAs,
+			MinVersion: tls.VersionTLS12,
+		}
+		server.StartTLS()
+
+		serverCAPath = filepath.Join(dir, "server-ca.crt")
+		Expect(os.WriteFile(serverCAPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600)).To(Succeed())
+	})
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+		os.Unsetenv(constants.ConfigVariableRegistryClientCert)
+		os.Unsetenv(constants.ConfigVariableRegistryClientKey)
+	})
+
+	ping := func(clientCerts []tls.Certificate) (*http.Response, error) {
+		transport, err := newHTTPTransport(&ctlimg.Opts{CACertPaths: []string{serverCAPath}, VerifyCerts: true}, clientCerts)
+		Expect(err).To(BeNil())
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/v2/", http.NoBody)
+		Expect(err).To(BeNil())
+		return transport.RoundTrip(req)
+	}
+
+	It("should present the client certificate to the registry", func() {
+		clientCerts, err := LoadClientCertificates(clientCertPath, clientKeyPath)
+		Expect(err).To(BeNil())
+		Expect(clientCerts).To(HaveLen(1))
+
+		resp, err := ping(clientCerts)
+		Expect(err).To(BeNil())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	})
+	It("should be rejected by the registry without a client certificate", func() {
+		resp, err := ping(nil)
+		if err == nil {
+			resp.Body.Close()
+		}
+		Expect(err).ToNot(BeNil())
+	})
+	It("should get the client certificate from the environment variables", func() {
+		os.Setenv(constants.ConfigVariableRegistryClientCert, clientCertPath)
+		os.Setenv(constants.ConfigVariableRegistryClientKey, clientKeyPath)
+		certOptions := &CertOptions{}
+		Expect(addRegistryCertOptionsFromEnv(certOptions)).To(Succeed())
+		Expect(certOptions.ClientCertPath).To(Equal(clientCertPath))
+		Expect(certOptions.ClientKeyPath).To(Equal(clientKeyPath))
+	})
+	It("should fail if only the client certificate is specified", func() {
+		os.Setenv(constants.ConfigVariableRegistryClientCert, clientCertPath)
+		err := addRegistryCertOptionsFromEnv(&CertOptions{})
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("both TANZU_CLI_REGISTRY_CLIENT_CERT and TANZU_CLI_REGISTRY_CLIENT_KEY must be set"))
+	})
+	It("should not include the key in the error when the key does not match", func() {
+		otherDir, err := os.MkdirTemp(dir, "other")
+		Expect(err).To(BeNil())
+		_, otherKeyPath, _ := writeClientCertificate(otherDir)
+		keyBytes, err := os.ReadFile(otherKeyPath)
+		Expect(err).To(BeNil())
+
+		_, err = LoadClientCertificates(clientCertPath, otherKeyPath)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("unable to load the registry client certificate"))
+		Expect(err.Error()).ToNot(ContainSubstring(string(keyBytes)))
+	})
+	It("should not use a client certificate when none is configured", func() {
+		clientCerts, err := LoadClientCertificates("", "")
+		Expect(err).To(BeNil())
+		Expect(clientCerts).To(BeEmpty())
+	})
+})
@@ -0,0 +1,150 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	ctlimg "github.com/vmware-tanzu/carvel-imgpkg/pkg/imgpkg/registry"
+
+	tprlog "github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+const (
+	// maxRateLimitRetries is the number of times a request throttled by the registry is retried
+	maxRateLimitRetries = 3
+	// defaultRateLimitWait is the initial time to wait when the registry does not send a Retry-After header.
+	// It is doubled for each retry.
+	defaultRateLimitWait = 2 * time.Second
+	// maxRateLimitWait caps the time to wait before retrying, whatever the registry asks for
+	maxRateLimitWait = 60 * time.Second
+)
+
+// rateLimitTransport retries the requests for which the registry responded
+// with HTTP 429 (Too Many Requests).  The Retry-After header is honored when present,
+// otherwise the retries are done with an exponential backoff.
+type rateLimitTransport struct {
+	base        http.RoundTripper
+	maxRetries  int
+	defaultWait time.Duration
+	maxWait     time.Duration
+	// after is used to wait before retrying, it is replaced in tests
+	after func(d time.Duration) <-chan time.Time
+}
+
+func newRateLimitTransport(base http.RoundTripper) *rateLimitTransport {
+	return &rateLimitTransport{
+		base:        base,
+		maxRetries:  maxRateLimitRetries,
+		defaultWait: defaultRateLimitWait,
+		maxWait:     maxRateLimitWait,
+		after:       time.After,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	wait := t.defaultWait
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempt >= t.maxRetries {
+			return resp, err
+		}
+		// A request with a body can only be sent again if the body can be obtained again
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, nil
+		}
+
+		delay, found := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !found {
+			delay = wait
+			wait *= 2
+		}
+		if delay > t.maxWait {
+			delay = t.maxWait
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		tprlog.Warningf("The registry %q is rate limiting requests, retrying in %v", req.URL.Host, delay)
+		select {
+		case <-t.after(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either
+// a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// newHTTPTransport returns the transport to use to access a registry
+// with the specified options, presenting the client certificates if any.
+// The transport is set up like the one imgpkg creates by default, so that the
+// registries are accessed the same way, e.g., with the same HTTP protocol and
+// response header timeout.  The requests throttled by the registry are retried
+// and all requests identify the CLI through their User-Agent header.
+func newHTTPTransport(opts *ctlimg.Opts, clientCerts []tls.Certificate) (http.RoundTripper, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed loading the system CA certificates")
+	}
+	for _, path := range opts.CACertPaths {
+		certs, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed reading CA certificates from '%s'", path)
+		}
+		if ok := pool.AppendCertsFromPEM(certs); !ok {
+			return nil, errors.Errorf("failed adding CA certificates from '%s'", path)
+		}
+	}
+
+	clonedDefaultTransport := http.DefaultTransport.(*http.Transport).Clone()
+	clonedDefaultTransport.ForceAttemptHTTP2 = false
+	clonedDefaultTransport.ResponseHeaderTimeout = opts.ResponseHeaderTimeout
+	// #nosec G402
+	clonedDefaultTransport.TLSClientConfig = &tls.Config{
+		RootCAs:            pool,
+		Certificates:       clientCerts,
+		InsecureSkipVerify: !opts.VerifyCerts,
+	}
+	return newUserAgentTransport(newRateLimitTransport(clonedDefaultTransport)), nil
+}
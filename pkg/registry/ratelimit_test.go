@@ -0,0 +1,171 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	ctlimg "github.com/vmware-tanzu/carvel-imgpkg/pkg/imgpkg/registry"
+)
+
+var _ = Describe("Rate limited registry", func() {
+	var (
+		server    *httptest.Server
+		requests  int
+		throttled int
+		header    string
+		waits     []time.Duration
+		transport *rateLimitTransport
+	)
+
+	BeforeEach(func() {
+		requests = 0
+		waits = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests <= throttled {
+				if header != "" {
+					w.Header().Set("Retry-After", header)
+				}
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_, _ = w.Write([]byte("ok"))
+		}))
+		transport = newRateLimitTransport(http.DefaultTransport)
+		transport.after = func(d time.Duration) <-chan time.Time {
+			waits = append(waits, d)
+			ch := make(chan time.Time, 1)
+			ch <- time.Now()
+			return ch
+		}
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	doRequest := func() *http.Response {
+		req, err := http.NewRequest(http.MethodGet, server.URL, http.NoBody)
+		Expect(err).To(BeNil())
+		resp, err := transport.RoundTrip(req)
+		Expect(err).To(BeNil())
+		resp.Body.Close()
+		return resp
+	}
+
+	Context("when the registry sends a Retry-After header in seconds", func() {
+		BeforeEach(func() {
+			throttled = 2
+			header = "3"
+		})
+		It("should wait for the requested duration and retry", func() {
+			resp := doRequest()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(requests).To(Equal(3))
+			Expect(waits).To(Equal([]time.Duration{3 * time.Second, 3 * time.Second}))
+		})
+	})
+	Context("when the registry asks to wait longer than the maximum", func() {
+		BeforeEach(func() {
+			throttled = 1
+			header = "3600"
+		})
+		It("should cap the wait", func() {
+			resp := doRequest()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(waits).To(Equal([]time.Duration{maxRateLimitWait}))
+		})
+	})
+	Context("when the registry does not send a Retry-After header", func() {
+		BeforeEach(func() {
+			throttled = 2
+			header = ""
+		})
+		It("should back off exponentially", func() {
+			resp := doRequest()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(waits).To(Equal([]time.Duration{defaultRateLimitWait, 2 * defaultRateLimitWait}))
+		})
+	})
+	Context("when the registry keeps throttling the requests", func() {
+		BeforeEach(func() {
+			throttled = 100
+			header = "1"
+		})
+		It("should give up after the maximum number of retries", func() {
+			resp := doRequest()
+			Expect(resp.StatusCode).To(Equal(http.StatusTooManyRequests))
+			Expect(requests).To(Equal(maxRateLimitRetries + 1))
+			Expect(len(waits)).To(Equal(maxRateLimitRetries))
+		})
+	})
+	Context("when the request has a body that can be sent again", func() {
+		BeforeEach(func() {
+			throttled = 1
+			header = "1"
+		})
+		It("should retry with the same body", func() {
+			req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader("data"))
+			Expect(err).To(BeNil())
+			resp, err := transport.RoundTrip(req)
+			Expect(err).To(BeNil())
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(requests).To(Equal(2))
+		})
+	})
+})
+
+var _ = Describe("parseRetryAfter", func() {
+	now := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	It("should parse a number of seconds", func() {
+		d, found := parseRetryAfter("120", now)
+		Expect(found).To(BeTrue())
+		Expect(d).To(Equal(2 * time.Minute))
+	})
+	It("should parse an HTTP date", func() {
+		d, found := parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now)
+		Expect(found).To(BeTrue())
+		Expect(d).To(Equal(30 * time.Second))
+	})
+	It("should not wait for a date in the past", func() {
+		d, found := parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now)
+		Expect(found).To(BeTrue())
+		Expect(d).To(Equal(time.Duration(0)))
+	})
+	It("should ignore invalid values", func() {
+		_, found := parseRetryAfter("soon", now)
+		Expect(found).To(BeFalse())
+		_, found = parseRetryAfter("-5", now)
+		Expect(found).To(BeFalse())
+		_, found = parseRetryAfter("", now)
+		Expect(found).To(BeFalse())
+	})
+})
+
+var _ = Describe("Registry HTTP transport", func() {
+	It("should be set up like the default transport of imgpkg", func() {
+		rt, err := newHTTPTransport(&ctlimg.Opts{VerifyCerts: true, ResponseHeaderTimeout: 30 * time.Second}, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		userAgentRT, ok := rt.(*userAgentTransport)
+		Expect(ok).To(BeTrue())
+		rateLimitRT, ok := userAgentRT.base.(*rateLimitTransport)
+		Expect(ok).To(BeTrue())
+		httpTransport, ok := rateLimitRT.base.(*http.Transport)
+		Expect(ok).To(BeTrue())
+
+		Expect(httpTransport.ForceAttemptHTTP2).To(BeFalse())
+		Expect(httpTransport.ResponseHeaderTimeout).To(Equal(30 * time.Second))
+		Expect(httpTransport.Proxy).ToNot(BeNil())
+		Expect(httpTransport.TLSClientConfig.RootCAs).ToNot(BeNil())
+		Expect(httpTransport.TLSClientConfig.InsecureSkipVerify).To(BeFalse())
+	})
+})
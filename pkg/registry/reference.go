@@ -0,0 +1,129 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// dnsLabelRegexp matches a single label of a DNS-compatible host name
+	dnsLabelRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
+	// pathComponentRegexp matches a single component of the repository path
+	pathComponentRegexp = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*$`)
+	// tagRegexp matches a valid image tag
+	tagRegexp = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`)
+	// digestRegexp matches a valid image digest (e.g., sha256:<hex>)
+	digestRegexp = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-fA-F0-9]{32,}$`)
+)
+
+const imageReferenceFormat = "<registry>/<path>[:<tag>|@<digest>]"
+
+// ImageReference is an OCI image reference broken down into its parts
+type ImageReference struct {
+	// Registry is the DNS-compatible registry name, including the port if specified
+	Registry string
+	// Repository is the path of the image within the registry
+	Repository string
+	// Tag is the tag of the image; it is empty if not specified
+	Tag string
+	// Digest is the digest of the image; it is empty if not specified
+	Digest string
+}
+
+// String returns the image reference in the <registry>/<path>[:<tag>][@<digest>] format
+func (r *ImageReference) String() string {
+	ref := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		ref += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		ref += "@" + r.Digest
+	}
+	return ref
+}
+
+// ParseImageReference parses an OCI image reference which must include a
+// DNS-compatible registry name, a valid URI path (which may contain zero or more '/')
+// and optionally a valid tag and/or digest.
+// E.g., harbor.my-domain.local/tanzu-cli/plugins/plugins-inventory:latest
+// The returned error points at the part of the reference that is invalid.
+func ParseImageReference(image string) (*ImageReference, error) {
+	if image == "" {
+		return nil, errors.New("the image reference is empty")
+	}
+	if strings.ContainsAny(image, " \t\n") {
+		return nil, errors.Errorf("invalid image reference %q: it must not contain whitespace", image)
+	}
+
+	ref := &ImageReference{}
+	rest := image
+	if idx := strings.Index(rest, "@"); idx >= 0 {
+		ref.Digest = rest[idx+1:]
+		rest = rest[:idx]
+		if !digestRegexp.MatchString(ref.Digest) {
+			return nil, errors.Errorf("invalid image reference %q: digest %q is not of the form <algorithm>:<hex>", image, ref.Digest)
+		}
+	}
+
+	idx := strings.Index(rest, "/")
+	if idx < 0 {
+		return nil, errors.Errorf("invalid image reference %q: it is missing the registry or the path, it must be of the form %s", image, imageReferenceFormat)
+	}
+	ref.Registry = rest[:idx]
+	rest = rest[idx+1:]
+	if err := validateRegistryName(ref.Registry); err != nil {
+		return nil, errors.Wrapf(err, "invalid image reference %q", image)
+	}
+
+	if idx := strings.LastIndex(rest, ":"); idx >= 0 {
+		ref.Tag = rest[idx+1:]
+		rest = rest[:idx]
+		if !tagRegexp.MatchString(ref.Tag) {
+			return nil, errors.Errorf("invalid image reference %q: tag %q is not valid, it can only contain letters, digits, '_', '.' and '-', must not start with '.' or '-' and must be at most 128 characters", image, ref.Tag)
+		}
+	}
+
+	ref.Repository = rest
+	if ref.Repository == "" {
+		return nil, errors.Errorf("invalid image reference %q: it is missing the path, it must be of the form %s", image, imageReferenceFormat)
+	}
+	for _, component := range strings.Split(ref.Repository, "/") {
+		if !pathComponentRegexp.MatchString(component) {
+			return nil, errors.Errorf("invalid image reference %q: path component %q is not valid, it can only contain lowercase letters, digits and separators ('.', '_', '__' or '-') between them", image, component)
+		}
+	}
+	return ref, nil
+}
+
+// validateRegistryName verifies the registry is a valid host name or IP address
+// optionally followed by a port
+func validateRegistryName(registry string) error {
+	host := registry
+	if idx := strings.LastIndex(registry, ":"); idx >= 0 {
+		host = registry[:idx]
+		port, err := strconv.Atoi(registry[idx+1:])
+		if err != nil || port <= 0 || port > 65535 {
+			return errors.Errorf("registry %q has an invalid port %q", registry, registry[idx+1:])
+		}
+	}
+	if host == "localhost" || net.ParseIP(host) != nil {
+		return nil
+	}
+	// Without a port, a registry must have a domain to be distinguished from a path
+	if host == registry && !strings.Contains(host, ".") {
+		return errors.Errorf("registry %q is not a DNS-compatible registry name, it must contain a '.', a port or be 'localhost'", registry)
+	}
+	for _, label := range strings.Split(host, ".") {
+		if !dnsLabelRegexp.MatchString(label) {
+			return errors.Errorf("registry %q is not a DNS-compatible registry name, label %q is invalid", registry, label)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const testDigest = "sha256:3925a7a0e78ec439529c4bc9e26b4bbe95a01645325a8b2f66334be7e6b37ab6"
+
+var _ = Describe("ParseImageReference", func() {
+	DescribeTable("valid image references",
+		func(image string, expected ImageReference) {
+			ref, err := ParseImageReference(image)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*ref).To(Equal(expected))
+			Expect(ref.String()).To(Equal(image))
+		},
+		Entry("registry, path and tag",
+			"harbor.my-domain.local/tanzu-cli/plugins/plugins-inventory:latest",
+			ImageReference{Registry: "harbor.my-domain.local", Repository: "tanzu-cli/plugins/plugins-inventory", Tag: "latest"}),
+		Entry("registry with a port",
+			"localhost:9876/tanzu-cli/plugins/central:small",
+			ImageReference{Registry: "localhost:9876", Repository: "tanzu-cli/plugins/central", Tag: "small"}),
+		Entry("registry as an IP address",
+			"127.0.0.1:5000/inventory:v1.0.0",
+			ImageReference{Registry: "127.0.0.1:5000", Repository: "inventory", Tag: "v1.0.0"}),
+		Entry("no tag",
+			"projects.registry.vmware.com/tanzu_cli/plugins/plugin-inventory",
+			ImageReference{Registry: "projects.registry.vmware.com", Repository: "tanzu_cli/plugins/plugin-inventory"}),
+		Entry("digest",
+			"localhost:9876/tanzu-cli/plugins/plugin@"+testDigest,
+			ImageReference{Registry: "localhost:9876", Repository: "tanzu-cli/plugins/plugin", Digest: testDigest}),
+		Entry("tag and digest",
+			"localhost:9876/tanzu-cli/plugins/plugin:v1@"+testDigest,
+			ImageReference{Registry: "localhost:9876", Repository: "tanzu-cli/plugins/plugin", Tag: "v1", Digest: testDigest}),
+	)
+
+	DescribeTable("invalid image references",
+		func(image, expectedErr string) {
+			ref, err := ParseImageReference(image)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(expectedErr))
+			Expect(ref).To(BeNil())
+		},
+		Entry("empty", "", "the image reference is empty"),
+		Entry("whitespace", "example.com/my image:v1", "it must not contain whitespace"),
+		Entry("no path", "example.com", "it is missing the registry or the path"),
+		Entry("empty path", "example.com/:v1", "it is missing the path"),
+		Entry("registry without a domain", "tanzu/plugins:v1", `registry "tanzu" is not a DNS-compatible registry name`),
+		Entry("invalid registry label", "my_registry.com/plugins:v1", `label "my_registry" is invalid`),
+		Entry("invalid port", "localhost:port/plugins:v1", `registry "localhost:port" has an invalid port "port"`),
+		Entry("uppercase path", "example.com/Tanzu/plugins:v1", `path component "Tanzu" is not valid`),
+		Entry("empty path component", "example.com/tanzu//plugins:v1", `path component "" is not valid`),
+		Entry("invalid tag", "example.com/tanzu/plugins:-v1", `tag "-v1" is not valid`),
+		Entry("empty tag", "example.com/tanzu/plugins:", `tag "" is not valid`),
+		Entry("invalid digest", "example.com/tanzu/plugins@sha256:xyz", `digest "sha256:xyz" is not of the form <algorithm>:<hex>`),
+	)
+})
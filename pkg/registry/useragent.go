@@ -0,0 +1,49 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/buildinfo"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+)
+
+// userAgentTransport sets the User-Agent header of every request sent to a registry,
+// whether it is the resolution of a digest or the download of a blob
+type userAgentTransport struct {
+	base http.RoundTripper
+}
+
+func newUserAgentTransport(base http.RoundTripper) *userAgentTransport {
+	return &userAgentTransport{base: base}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The request must not be modified, as per the http.RoundTripper contract
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", userAgent(req.Header.Get("User-Agent")))
+	return t.base.RoundTrip(req)
+}
+
+// userAgent returns the User-Agent header of the registry requests.  It is the value
+// of the TANZU_CLI_REGISTRY_USER_AGENT variable when set, otherwise the CLI name and version
+// followed by the user agent set by the registry library, if any.
+func userAgent(libraryUserAgent string) string {
+	if ua := strings.TrimSpace(os.Getenv(constants.ConfigVariableRegistryUserAgent)); ua != "" {
+		return ua
+	}
+	version := buildinfo.Version
+	if version == "" {
+		version = "unknown"
+	}
+	ua := "tanzu-cli/" + version
+	if libraryUserAgent != "" {
+		ua += " " + libraryUserAgent
+	}
+	return ua
+}
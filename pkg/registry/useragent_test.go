@@ -0,0 +1,70 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/buildinfo"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+)
+
+var _ = Describe("User agent of the registry requests", func() {
+	var (
+		server     *httptest.Server
+		userAgents []string
+		transport  http.RoundTripper
+		version    string
+	)
+
+	BeforeEach(func() {
+		userAgents = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userAgents = append(userAgents, r.Header.Get("User-Agent"))
+			_, _ = w.Write([]byte("ok"))
+		}))
+		transport = newUserAgentTransport(http.DefaultTransport)
+		version = buildinfo.Version
+		buildinfo.Version = "v1.2.3"
+	})
+	AfterEach(func() {
+		server.Close()
+		buildinfo.Version = version
+		os.Unsetenv(constants.ConfigVariableRegistryUserAgent)
+	})
+
+	doRequest := func(method, libraryUserAgent string) {
+		req, err := http.NewRequest(method, server.URL, http.NoBody)
+		Expect(err).To(BeNil())
+		if libraryUserAgent != "" {
+			req.Header.Set("User-Agent", libraryUserAgent)
+		}
+		resp, err := transport.RoundTrip(req)
+		Expect(err).To(BeNil())
+		resp.Body.Close()
+		// The original request is not modified
+		Expect(req.Header.Get("User-Agent")).To(Equal(libraryUserAgent))
+	}
+
+	It("should identify the CLI and its version for the HEAD and GET requests", func() {
+		doRequest(http.MethodHead, "")
+		doRequest(http.MethodGet, "go-containerregistry/v0.16.1")
+		Expect(userAgents).To(Equal([]string{"tanzu-cli/v1.2.3", "tanzu-cli/v1.2.3 go-containerregistry/v0.16.1"}))
+	})
+	It("should use the user agent of the environment variable when set", func() {
+		os.Setenv(constants.ConfigVariableRegistryUserAgent, "my-tanzu-cli/1.0")
+		doRequest(http.MethodHead, "go-containerregistry/v0.16.1")
+		doRequest(http.MethodGet, "")
+		Expect(userAgents).To(Equal([]string{"my-tanzu-cli/1.0", "my-tanzu-cli/1.0"}))
+	})
+	It("should report an unknown version when the version is not set", func() {
+		buildinfo.Version = ""
+		doRequest(http.MethodGet, "")
+		Expect(userAgents).To(Equal([]string{"tanzu-cli/unknown"}))
+	})
+})
@@ -3,12 +3,12 @@
 
 package registry
 
-import "github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+import "github.com/vmware-tanzu/tanzu-cli/pkg/clilog"
 
 type writer struct{}
 
-// Writer passes the log received to the log.Info
+// Writer passes the log received to the clilog.Info
 func (w *writer) Write(p []byte) (n int, err error) {
-	log.Info(string(p))
+	clilog.Info(string(p))
 	return len(p), nil
 }
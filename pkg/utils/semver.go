@@ -6,6 +6,7 @@ package utils
 
 import (
 	"sort"
+	"strings"
 
 	"github.com/Masterminds/semver"
 )
@@ -27,6 +28,33 @@ func SortVersions(vStrArr []string) error {
 	return nil
 }
 
+// IsPreRelease returns true if the version is a valid semver pre-release version.
+// E.g., v1.0.0-beta.1
+func IsPreRelease(vStr string) bool {
+	v, err := semver.NewVersion(vStr)
+	if err != nil {
+		return false
+	}
+	return v.Prerelease() != ""
+}
+
+// GetLatestVersion returns the highest of the specified versions, which must be
+// sorted in ascending semver order.  Pre-release versions are only considered if
+// includePrerelease is true or if all the versions are pre-release versions.
+func GetLatestVersion(sortedVersions []string, includePrerelease bool) string {
+	if len(sortedVersions) == 0 {
+		return ""
+	}
+	if !includePrerelease {
+		for i := len(sortedVersions) - 1; i >= 0; i-- {
+			if !IsPreRelease(sortedVersions[i]) {
+				return sortedVersions[i]
+			}
+		}
+	}
+	return sortedVersions[len(sortedVersions)-1]
+}
+
 // IsNewVersion compares the plugin version and the installed version.
 func IsNewVersion(incomingVersionStr, existingVersionStr string) bool {
 	// Parse versions using semver package
@@ -43,3 +71,21 @@ func IsNewVersion(incomingVersionStr, existingVersionStr string) bool {
 	// Compare versions
 	return incomingVersion.Compare(existingVersion) > 0 // Return true if new version is available
 }
+
+// CompareVersions compares two versions in semver 2.0 order and returns
+// -1, 0 or 1 if the first version is respectively lower, equal or greater
+// than the second one.  Versions that are not valid semver are compared
+// as strings and are considered lower than valid versions.
+func CompareVersions(v1Str, v2Str string) int {
+	v1, err1 := semver.NewVersion(v1Str)
+	v2, err2 := semver.NewVersion(v2Str)
+	switch {
+	case err1 == nil && err2 == nil:
+		return v1.Compare(v2)
+	case err1 == nil:
+		return 1
+	case err2 == nil:
+		return -1
+	}
+	return strings.Compare(v1Str, v2Str)
+}
@@ -118,3 +118,68 @@ func TestIsNewVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestGetLatestVersion(t *testing.T) {
+	tcs := []struct {
+		name              string
+		versions          []string
+		includePrerelease bool
+		exp               string
+	}{
+		{
+			name: "No versions",
+			exp:  "",
+		},
+		{
+			name:     "Stable version is preferred",
+			versions: []string{"v1.0.0", "v1.1.0-rc.1", "v1.1.0-rc.2"},
+			exp:      "v1.0.0",
+		},
+		{
+			name:              "Pre-release version included",
+			versions:          []string{"v1.0.0", "v1.1.0-rc.1", "v1.1.0-rc.2"},
+			includePrerelease: true,
+			exp:               "v1.1.0-rc.2",
+		},
+		{
+			name:     "Only pre-release versions",
+			versions: []string{"v0.1.0-alpha.1", "v0.1.0-beta.1"},
+			exp:      "v0.1.0-beta.1",
+		},
+		{
+			name:     "Stable version is the highest",
+			versions: []string{"v0.2.0-beta.1", "v0.2.0", "v0.20.0"},
+			exp:      "v0.20.0",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.exp, GetLatestVersion(tc.versions, tc.includePrerelease))
+		})
+	}
+	assert.True(t, IsPreRelease("v1.0.0-dev"))
+	assert.False(t, IsPreRelease("v1.0.0"))
+	assert.False(t, IsPreRelease("invalid"))
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		v1   string
+		v2   string
+		want int
+	}{
+		{v1: "v1.0.0", v2: "v1.0.0", want: 0},
+		{v1: "v1.10.0", v2: "v1.9.0", want: 1},
+		{v1: "v1.0.0-beta.1", v2: "v1.0.0", want: -1},
+		{v1: "invalid", v2: "v0.0.1", want: -1},
+		{v1: "v0.0.1", v2: "invalid", want: 1},
+		{v1: "abc", v2: "abd", want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.v1+"_"+tt.v2, func(t *testing.T) {
+			assert.Equal(t, tt.want, CompareVersions(tt.v1, tt.v2))
+		})
+	}
+}